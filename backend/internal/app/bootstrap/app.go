@@ -5,23 +5,38 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/authz"
 	"github.com/OpenNSW/nsw/internal/config"
 	"github.com/OpenNSW/nsw/internal/consignment"
 	"github.com/OpenNSW/nsw/internal/database"
+	"github.com/OpenNSW/nsw/internal/document"
+	documentadmin "github.com/OpenNSW/nsw/internal/document/admin"
+	"github.com/OpenNSW/nsw/internal/feeschedule"
+	feescheduleadmin "github.com/OpenNSW/nsw/internal/feeschedule/admin"
+	"github.com/OpenNSW/nsw/internal/form"
 	"github.com/OpenNSW/nsw/internal/hscode"
 	"github.com/OpenNSW/nsw/internal/middleware"
-	"github.com/OpenNSW/nsw/internal/payments"
+	"github.com/OpenNSW/nsw/internal/notifier"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+	"github.com/OpenNSW/nsw/internal/paymentsv2/reconciliation"
 	"github.com/OpenNSW/nsw/internal/profile/cha"
+	"github.com/OpenNSW/nsw/internal/profile/company"
 	"github.com/OpenNSW/nsw/internal/profile/user"
+	"github.com/OpenNSW/nsw/internal/task/deadline"
 	taskmanager "github.com/OpenNSW/nsw/internal/task/manager"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
 	"github.com/OpenNSW/nsw/internal/task/plugin"
 	"github.com/OpenNSW/nsw/internal/temporal"
+	"github.com/OpenNSW/nsw/internal/workflow/router"
 	workflowruntime "github.com/OpenNSW/nsw/internal/workflow/runtime"
 	"github.com/OpenNSW/nsw/internal/workflow/service"
 	"github.com/OpenNSW/nsw/pkg/storage"
+	storageadmin "github.com/OpenNSW/nsw/pkg/storage/admin"
 	"github.com/OpenNSW/nsw/pkg/storage/drivers"
 
 	"github.com/OpenNSW/nsw/pkg/notification"
@@ -71,19 +86,91 @@ func Build(ctx context.Context, cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("database health check failed: %w", err)
 	}
 
-	paymentRepo := payments.NewPaymentRepository(db)
-	paymentService := payments.NewPaymentService(paymentRepo)
+	paymentProviders := map[string]paymentsv2.PaymentProvider{}
+	if cfg.Payments.MockEnabled {
+		slog.Warn("mock payment provider is enabled, traders can settle payments without paying; do not enable it in production")
+		paymentProviders[paymentsv2.MockProviderID] = paymentsv2.NewMockProvider(paymentsv2.MockProviderConfig{
+			CheckoutURL:   cfg.Payments.MockCheckoutURL,
+			WebhookSecret: cfg.Payments.MockWebhookSecret,
+		})
+	}
+	paymentRegistry, err := paymentsv2.NewRegistry(cfg.Payments.MethodsConfigPath, paymentProviders)
+	if err != nil {
+		slog.Warn("failed to load payment methods, online payments are disabled",
+			"path", cfg.Payments.MethodsConfigPath,
+			"error", err)
+		paymentRegistry, _ = paymentsv2.NewRegistryFromConfig(paymentsv2.PaymentMethodsConfig{}, paymentProviders)
+	}
+	paymentRepo := paymentsv2.NewPaymentRepository(db)
+	paymentService := paymentsv2.NewPaymentService(paymentRepo, paymentRegistry)
+
+	pluginRegistry, err := plugin.NewDefaultRegistry()
+	if err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create task plugin registry: %w", err)
+	}
+	// Fee schedules price PAYMENT tasks that reference one, and record every breakdown they resolve.
+	feeScheduleStore, err := feeschedule.NewGormStore(db)
+	if err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create fee schedule store: %w", err)
+	}
+	feeScheduleService := feeschedule.NewService(feeScheduleStore)
 
-	factory := plugin.NewTaskFactory(cfg, db, paymentService)
+	storageDriver, err := storage.NewStorageFromConfig(ctx, cfg.Storage)
+	if err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	// Uploads are quarantined until they have been validated and scanned, within the limits of
+	// the form field they are for.
+	uploadScanner, err := storage.NewScannerFromConfig(cfg.Storage)
+	if err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to initialize upload scanner: %w", err)
+	}
+	// Every stored file is recorded with its uploader and the task or consignment it belongs to.
+	storedFiles, err := storage.NewGormMetadataStore(db)
+	if err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create stored file store: %w", err)
+	}
+	storageService := storage.NewService(storageDriver,
+		storage.WithQuarantine(uploadScanner),
+		storage.WithPolicyResolver(form.NewUploadPolicies(form.NewFormService(db))),
+		storage.WithMetadata(storedFiles))
+
+	// DOCUMENT tasks issue receipts and certificates into storage and record how to verify them.
+	documentStore, err := document.NewGormStore(db)
+	if err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create document store: %w", err)
+	}
+	documentService := document.NewService(documentStore, storageService, cfg.Documents.VerifyURL)
+
+	remoteManager := plugin.NewRemoteManager(cfg)
+	factory, err := plugin.NewTaskFactory(cfg, db, paymentService, feeScheduleService, documentService, remoteManager, pluginRegistry)
+	if err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create task factory: %w", err)
+	}
 	tm, err := taskmanager.NewTaskManager(db, factory)
 	if err != nil {
 		_ = database.Close(db)
 		return nil, fmt.Errorf("failed to create task manager: %w", err)
 	}
+	// Settled payments advance their PAYMENT task from the gateway webhook.
+	paymentService.RegisterOutcomeHandler(taskmanager.PaymentOutcomeHandler(tm))
 
 	templateService := service.NewTemplateService(db)
+	if err := templateService.ValidateNodeTemplateTypes(ctx, pluginRegistry); err != nil {
+		_ = database.Close(db)
+		return nil, fmt.Errorf("invalid workflow node templates: %w", err)
+	}
 	chaService := cha.NewService(db)
 	hsCodeService := hscode.NewService(db)
+	userProfileService := user.NewService(db)
+	companyService := company.NewService(db)
 
 	temporalClient, err := temporal.NewClient(cfg.Temporal)
 	if err != nil {
@@ -91,11 +178,42 @@ func Build(ctx context.Context, cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("failed to create temporal client: %w", err)
 	}
 
+	// Node deadlines run as durable Temporal timers so they survive restarts.
+	deadlineScheduler, err := deadline.NewScheduler(temporalClient, tm)
+	if err != nil {
+		temporalClient.Close()
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create deadline scheduler: %w", err)
+	}
+	if err := deadlineScheduler.Start(); err != nil {
+		temporalClient.Close()
+		_ = database.Close(db)
+		return nil, err
+	}
+	tm.RegisterDeadlineScheduler(deadlineScheduler)
+
 	consignmentService := consignment.NewService(db, templateService, chaService, hsCodeService)
-	consignmentRouter := consignment.NewRouter(consignmentService, chaService)
+	preConsignmentService := service.NewPreConsignmentService(db, templateService, userProfileService, companyService)
 
-	workflowRuntime, err := workflowruntime.NewRuntime(temporalClient, tm, templateService, consignmentService)
+	// Ownership and per-action plugin policies for consignment and task endpoints.
+	taskStore, err := persistence.NewTaskStore(db)
 	if err != nil {
+		deadlineScheduler.Stop()
+		temporalClient.Close()
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create task store: %w", err)
+	}
+	policy := authz.NewPolicy(pluginRegistry, taskStore, remoteManager, chaService, consignmentService, preConsignmentService)
+	// Stored files may be read by their uploader and the parties of their task or consignment.
+	storageService.RegisterAuthorizer(policy)
+	consignmentRouter := consignment.NewRouter(consignmentService, chaService, policy)
+	preConsignmentRouter := router.NewPreConsignmentRouter(preConsignmentService, policy)
+
+	// Consignments and pre-consignments share one workflow runtime; completions are routed by workflow owner.
+	upstreamRouter := workflowruntime.NewUpstreamRouter(consignmentService, preConsignmentService)
+	workflowRuntime, err := workflowruntime.NewRuntime(temporalClient, tm, templateService, upstreamRouter)
+	if err != nil {
+		deadlineScheduler.Stop()
 		temporalClient.Close()
 		_ = database.Close(db)
 		return nil, fmt.Errorf("failed to create workflow runtime: %w", err)
@@ -104,34 +222,30 @@ func Build(ctx context.Context, cfg *config.Config) (*App, error) {
 	registererr := consignmentService.RegisterWorkflowManager(workflowRuntime.Manager())
 	if registererr != nil {
 		_ = workflowRuntime.Close()
+		deadlineScheduler.Stop()
 		temporalClient.Close()
 		_ = database.Close(db)
 		return nil, fmt.Errorf("failed to register workflow manager with consignment service: %w", registererr)
 	}
-	// TODO: Pre-consignment wiring is intentionally disabled until it is migrated to Temporal.
-	// preConsignmentService := service.NewPreConsignmentService(db, templateService, wm)
-	// preConsignmentRouter := router.NewPreConsignmentRouter(preConsignmentService)
-
-	hsCodeRouter := hscode.NewRouter(hsCodeService)
-	chaHandler := cha.NewHandler(chaService)
-
-	storageDriver, err := storage.NewStorageFromConfig(ctx, cfg.Storage)
-	if err != nil {
+	if err := preConsignmentService.RegisterWorkflowManager(workflowRuntime.Manager()); err != nil {
 		_ = workflowRuntime.Close()
+		deadlineScheduler.Stop()
 		temporalClient.Close()
 		_ = database.Close(db)
-		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+		return nil, fmt.Errorf("failed to register workflow manager with pre-consignment service: %w", err)
 	}
-	storageService := storage.NewService(storageDriver)
-	storageHandler := storage.NewHTTPHandler(storageService)
 
-	paymentHandler := payments.NewHTTPHandler(paymentService)
+	hsCodeRouter := hscode.NewRouter(hsCodeService)
+	chaHandler := cha.NewHandler(chaService)
 
-	userProfileService := user.NewService(db)
+	storageHandler := storage.NewHTTPHandler(storageService)
+
+	paymentHandler := paymentsv2.NewHTTPHandler(paymentService)
 
 	authManager, err := auth.NewManager(userProfileService, cfg.Auth)
 	if err != nil {
 		_ = workflowRuntime.Close()
+		deadlineScheduler.Stop()
 		temporalClient.Close()
 		_ = database.Close(db)
 		return nil, fmt.Errorf("failed to create auth manager: %w", err)
@@ -139,6 +253,7 @@ func Build(ctx context.Context, cfg *config.Config) (*App, error) {
 
 	if err := authManager.Health(); err != nil {
 		_ = workflowRuntime.Close()
+		deadlineScheduler.Stop()
 		temporalClient.Close()
 		_ = authManager.Close()
 		_ = database.Close(db)
@@ -157,11 +272,92 @@ func Build(ctx context.Context, cfg *config.Config) (*App, error) {
 	})
 	notificationManager.RegisterEmailChannel(emailChannel)
 
-	// TODO: Add SMS channel if needed
-	// smsChannel := channels.NewSMSChannel(...)
-	// notificationManager.RegisterSMSChannel(smsChannel)
+	if cfg.Notification.GovSMSBaseURL != "" {
+		smsChannel := channels.NewGovSMSChannel(channels.GovSMSConfig{
+			UserName:     cfg.Notification.GovSMSUserName,
+			Password:     cfg.Notification.GovSMSPassword,
+			SIDCode:      cfg.Notification.GovSMSSIDCode,
+			BaseURL:      cfg.Notification.GovSMSBaseURL,
+			TemplateRoot: cfg.Notification.SMSTemplateRoot,
+		})
+		notificationManager.RegisterSMSChannel(smsChannel)
+	} else {
+		slog.Info("GovSMS base URL not configured, SMS notifications are disabled")
+	}
+
+	// Like invalid node templates, an unusable rules file fails startup rather than silently
+	// disabling notifications.
+	notificationRules, err := notifier.LoadRules(cfg.Notification.RulesPath)
+	if err != nil {
+		_ = workflowRuntime.Close()
+		deadlineScheduler.Stop()
+		temporalClient.Close()
+		_ = authManager.Close()
+		_ = database.Close(db)
+		return nil, fmt.Errorf("invalid notification rules %s: %w", cfg.Notification.RulesPath, err)
+	}
+	if len(notificationRules) == 0 {
+		slog.Warn("no notification rules loaded, event notifications are disabled",
+			"path", cfg.Notification.RulesPath)
+	}
+
+	// Notifications are written to a durable outbox and delivered in the background with retries.
+	notificationOutbox, err := notifier.NewOutboxStore(db)
+	if err != nil {
+		_ = workflowRuntime.Close()
+		deadlineScheduler.Stop()
+		temporalClient.Close()
+		_ = authManager.Close()
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create notification outbox: %w", err)
+	}
+	notificationDispatcher := notifier.NewDispatcher(notificationOutbox, notificationManager, notifier.DispatcherConfig{
+		PollInterval: cfg.Notification.OutboxPollInterval,
+		MaxAttempts:  cfg.Notification.OutboxMaxAttempts,
+		BaseBackoff:  cfg.Notification.OutboxBaseBackoff,
+		MaxBackoff:   cfg.Notification.OutboxMaxBackoff,
+	})
+	notificationDispatcher.Start()
 
-	tmHandler := taskmanager.NewHTTPHandler(tm)
+	// Payment reconciliation: settlement files are matched against payment records, unpaid
+	// sessions are expired in the background, and validation responses name the real parties.
+	// Administrators can also read a task's payment ledger and refund its payment.
+	settlementStore, err := reconciliation.NewGormStore(db)
+	if err != nil {
+		notificationDispatcher.Stop()
+		_ = workflowRuntime.Close()
+		deadlineScheduler.Stop()
+		temporalClient.Close()
+		_ = authManager.Close()
+		_ = database.Close(db)
+		return nil, fmt.Errorf("failed to create settlement store: %w", err)
+	}
+	paymentDirectory := reconciliation.NewDirectory(taskStore, userProfileService, companyService, consignmentService, preConsignmentService)
+	paymentService.RegisterNameResolver(paymentDirectory)
+	paymentExpirer := reconciliation.NewExpirer(settlementStore, cfg.Payments.ExpirySweepInterval)
+	paymentExpirer.Start()
+	reconciliationHandler := reconciliation.NewHTTPHandler(reconciliation.NewReconciler(settlementStore, paymentDirectory),
+		paymentService, taskmanager.PaymentRefundRequester(tm), policy)
+	feeScheduleHandler := feescheduleadmin.NewHTTPHandler(feeScheduleService, policy)
+	documentHandler := document.NewHTTPHandler(documentService)
+	documentAdminHandler := documentadmin.NewHTTPHandler(documentService, policy)
+
+	// Stored files are purged in the background once their retention period has passed,
+	// unless an administrator has placed them on legal hold.
+	storageSweeper := storage.NewSweeper(storageService, storedFiles, cfg.Storage.RetentionPolicy(), cfg.Storage.SweepInterval)
+	storageSweeper.Start()
+	storageAdminHandler := storageadmin.NewHTTPHandler(storedFiles, storageSweeper, policy)
+
+	// Notify traders and CHAs of task events and workflow completions according to the configured rules.
+	eventNotifier := notifier.New(notificationOutbox, userProfileService, chaService, notificationRules,
+		notifier.WorkflowSource{Completed: notifier.EventConsignmentFinished, Parties: consignmentService},
+		notifier.WorkflowSource{Completed: notifier.EventPreConsignmentCompleted, Parties: preConsignmentService},
+	)
+	tm.RegisterTransitionListener(eventNotifier.HandleTaskTransition)
+	upstreamRouter.RegisterCompletionListener(eventNotifier.HandleWorkflowCompleted)
+
+	tmHandler := taskmanager.NewHTTPHandler(tm, policy)
+	notificationHandler := notifier.NewHTTPHandler(notificationOutbox, policy)
 
 	// withAuth wraps an individual handler with the authentication middleware.
 	withAuth := authManager.Middleware()
@@ -201,24 +397,57 @@ func Build(ctx context.Context, cfg *config.Config) (*App, error) {
 	// alongside these without restructuring the mux.
 	mux.Handle("POST /api/v1/tasks", withAuth(http.HandlerFunc(tmHandler.HandleExecuteTask)))
 	mux.Handle("GET /api/v1/tasks/{id}", withAuth(http.HandlerFunc(tmHandler.HandleGetTask)))
+	mux.Handle("GET /api/v1/tasks/{id}/history", withAuth(http.HandlerFunc(tmHandler.HandleGetTaskHistory)))
+	mux.Handle("GET /api/v1/notifications", withAuth(http.HandlerFunc(notificationHandler.HandleListNotifications)))
 	mux.Handle("GET /api/v1/hscodes", withAuth(http.HandlerFunc(hsCodeRouter.HandleGetAll)))
 	mux.Handle("GET /api/v1/chas", withAuth(http.HandlerFunc(chaHandler.HandleGetCHAs)))
 	mux.Handle("POST /api/v1/consignments", withAuth(http.HandlerFunc(consignmentRouter.HandleCreateConsignment)))
 	mux.Handle("GET /api/v1/consignments/{id}", withAuth(http.HandlerFunc(consignmentRouter.HandleGetConsignmentByID)))
 	mux.Handle("PUT /api/v1/consignments/{id}", withAuth(http.HandlerFunc(consignmentRouter.HandleInitializeConsignment)))
 	mux.Handle("GET /api/v1/consignments", withAuth(http.HandlerFunc(consignmentRouter.HandleGetConsignments)))
-	// TODO: Add pre-consignment routes once migrated to Temporal.
-	// mux.Handle("POST /api/v1/pre-consignments", withAuth(http.HandlerFunc(preConsignmentRouter.HandleCreatePreConsignment)))
-	// mux.Handle("GET /api/v1/pre-consignments/{preConsignmentId}", withAuth(http.HandlerFunc(preConsignmentRouter.HandleGetPreConsignmentByID)))
-	// mux.Handle("GET /api/v1/pre-consignments", withAuth(http.HandlerFunc(preConsignmentRouter.HandleGetTraderPreConsignments)))
+	mux.Handle("POST /api/v1/pre-consignments", withAuth(http.HandlerFunc(preConsignmentRouter.HandleCreatePreConsignment)))
+	mux.Handle("GET /api/v1/pre-consignments/{preConsignmentId}", withAuth(http.HandlerFunc(preConsignmentRouter.HandleGetPreConsignmentByID)))
+	mux.Handle("GET /api/v1/pre-consignments", withAuth(http.HandlerFunc(preConsignmentRouter.HandleGetTraderPreConsignments)))
 	mux.Handle("POST /api/v1/storage", withAuth(http.HandlerFunc(storageHandler.Upload)))
+	mux.Handle("POST /api/v1/storage/{key}/finalize", withAuth(http.HandlerFunc(storageHandler.Finalize)))
+	mux.Handle("GET /api/v1/payments/methods", withAuth(http.HandlerFunc(paymentHandler.HandleListMethods)))
+	mux.Handle("POST /api/v1/admin/payments/{providerId}/settlements", withAuth(http.HandlerFunc(reconciliationHandler.HandleIngestSettlements)))
+	mux.Handle("GET /api/v1/admin/payments/settlement-reports", withAuth(http.HandlerFunc(reconciliationHandler.HandleGetSettlementReports)))
+	mux.Handle("GET /api/v1/admin/payments/tasks/{taskId}/ledger", withAuth(http.HandlerFunc(reconciliationHandler.HandleGetTaskLedger)))
+	mux.Handle("POST /api/v1/admin/payments/tasks/{taskId}/refunds", withAuth(http.HandlerFunc(reconciliationHandler.HandleRequestRefund)))
+	mux.Handle("POST /api/v1/admin/fee-schedules", withAuth(http.HandlerFunc(feeScheduleHandler.HandlePublishSchedule)))
+	mux.Handle("GET /api/v1/admin/fee-schedules/{scheduleId}", withAuth(http.HandlerFunc(feeScheduleHandler.HandleListScheduleVersions)))
+	mux.Handle("PUT /api/v1/admin/exchange-rates/{currency}", withAuth(http.HandlerFunc(feeScheduleHandler.HandleSetExchangeRate)))
+	mux.Handle("GET /api/v1/admin/fee-resolutions", withAuth(http.HandlerFunc(feeScheduleHandler.HandleListResolutions)))
+	mux.Handle("GET /api/v1/admin/fee-resolutions/{resolutionId}/reproduction", withAuth(http.HandlerFunc(feeScheduleHandler.HandleReproduceResolution)))
+	mux.Handle("GET /api/v1/admin/documents", withAuth(http.HandlerFunc(documentAdminHandler.HandleListDocuments)))
+	mux.Handle("POST /api/v1/admin/documents/{documentId}/revocation", withAuth(http.HandlerFunc(documentAdminHandler.HandleRevokeDocument)))
+	mux.Handle("PUT /api/v1/admin/storage/files/{key}/legal-hold", withAuth(http.HandlerFunc(storageAdminHandler.HandlePlaceLegalHold)))
+	mux.Handle("DELETE /api/v1/admin/storage/files/{key}/legal-hold", withAuth(http.HandlerFunc(storageAdminHandler.HandleReleaseLegalHold)))
+	mux.Handle("GET /api/v1/admin/storage/purges", withAuth(http.HandlerFunc(storageAdminHandler.HandleListPurges)))
+	mux.Handle("POST /api/v1/admin/storage/sweeps", withAuth(http.HandlerFunc(storageAdminHandler.HandleSweep)))
 	mux.Handle("GET /api/v1/storage/{key}", withAuth(http.HandlerFunc(storageHandler.Download)))
 	mux.Handle("DELETE /api/v1/storage/{key}", withAuth(http.HandlerFunc(storageHandler.Delete)))
 
 	// External Webhooks bypass standard JWT auth.
 	// They should use webhook signatures, implemented in the handler directly or via specialized middleware.
-	mux.Handle("POST /api/v1/payments/webhook", http.HandlerFunc(paymentHandler.HandleWebhook))
-	mux.Handle("POST /api/v1/payments/validate", http.HandlerFunc(paymentHandler.HandleValidateReference))
+	mux.Handle("POST /api/v1/payments/{providerId}/webhook", http.HandlerFunc(paymentHandler.HandleWebhook))
+	mux.Handle("POST /api/v1/payments/{providerId}/validate", http.HandlerFunc(paymentHandler.HandleValidateReference))
+
+	// The mock checkout page reports the trader's chosen result here, and the server signs the
+	// webhook itself so the mock secret never reaches the browser.
+	if cfg.Payments.MockEnabled {
+		mockCheckoutHandler := paymentsv2.NewMockCheckoutHandler(paymentService, paymentRepo, cfg.Payments.MockWebhookSecret,
+			func(ctx context.Context, taskID string) error {
+				return policy.AuthorizeTaskAction(ctx, taskID, &plugin.ExecutionRequest{Action: plugin.PaymentActionInitiate})
+			})
+		mux.Handle("POST /api/v1/payments/mock/checkout/{referenceNumber}/complete", withAuth(http.HandlerFunc(mockCheckoutHandler.HandleComplete)))
+	}
+
+	// Document verification is public so that authorities and banks abroad can check certificates;
+	// it is rate limited per client because verification codes must not be guessable by brute force.
+	verifyRateLimit := middleware.RateLimit(cfg.Documents.VerifyRateLimit, time.Minute, cfg.Documents.VerifyTrustForwardedFor)
+	mux.Handle("GET /api/v1/verify/{code}", verifyRateLimit(http.HandlerFunc(documentHandler.HandleVerify)))
 
 	// When using local storage, these endpoints serve as mocks for S3.
 	if _, ok := storageDriver.(*drivers.LocalFSDriver); ok {
@@ -236,9 +465,13 @@ func Build(ctx context.Context, cfg *config.Config) (*App, error) {
 	closeFn := func() error {
 		var closeErrs []error
 
+		storageSweeper.Stop()
+		paymentExpirer.Stop()
+		notificationDispatcher.Stop()
 		if err := workflowRuntime.Close(); err != nil {
 			closeErrs = append(closeErrs, fmt.Errorf("failed to close workflow runtime: %w", err))
 		}
+		deadlineScheduler.Stop()
 		temporalClient.Close()
 		if err := authManager.Close(); err != nil {
 			closeErrs = append(closeErrs, fmt.Errorf("failed to close auth manager: %w", err))
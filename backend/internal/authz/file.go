@@ -0,0 +1,65 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+// AuthorizeFileLink checks that the caller may attach files to a task and consignment: the
+// task must be visible to the caller, and the consignment must be the caller's as trader or CHA.
+func (p *Policy) AuthorizeFileLink(ctx context.Context, link storage.FileLink) error {
+	if link.TaskID != "" {
+		if err := p.AuthorizeTaskRead(ctx, link.TaskID); err != nil {
+			return fileAccessError(err)
+		}
+	}
+	if link.ConsignmentID != "" {
+		if err := p.AuthorizeWorkflow(ctx, link.ConsignmentID, plugin.PartyTrader, plugin.PartyCHA); err != nil {
+			return fileAccessError(err)
+		}
+	}
+	return nil
+}
+
+// AuthorizeFileAccess checks that the caller may access a stored file. Its uploader may read
+// and write it. The parties of the task it belongs to may read it, including the OGA services
+// the task was injected into, as may those who may read its consignment's records.
+func (p *Policy) AuthorizeFileAccess(ctx context.Context, file *storage.StoredFile, access storage.Access) error {
+	authCtx, err := principal(ctx)
+	if err != nil {
+		return fileAccessError(err)
+	}
+	if storage.PrincipalID(authCtx) == file.UploadedBy {
+		return nil
+	}
+	if access != storage.AccessRead {
+		return fileAccessError(ErrForbidden)
+	}
+
+	if file.TaskID != "" {
+		err := p.AuthorizeTaskRead(ctx, file.TaskID)
+		if !errors.Is(err, ErrForbidden) {
+			return fileAccessError(err)
+		}
+	}
+	if file.ConsignmentID != "" {
+		err := p.AuthorizeWorkflowRead(ctx, file.ConsignmentID)
+		if !errors.Is(err, ErrForbidden) {
+			return fileAccessError(err)
+		}
+	}
+	return fileAccessError(ErrForbidden)
+}
+
+// fileAccessError marks denials with storage.ErrAccessDenied, which the storage handlers
+// recognise, leaving other errors as they are.
+func fileAccessError(err error) error {
+	if errors.Is(err, ErrForbidden) || errors.Is(err, ErrUnauthenticated) {
+		return fmt.Errorf("%w: %w", storage.ErrAccessDenied, err)
+	}
+	return err
+}
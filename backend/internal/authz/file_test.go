@@ -0,0 +1,64 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+func TestPolicy_AuthorizeFileAccess(t *testing.T) {
+	p := newTestPolicy(t)
+	trader := asUser("trader-1", "trader@example.com", RoleTrader)
+	uploader := asUser("trader-2", "t2@example.com", RoleTrader)
+	taskFile := &storage.StoredFile{Key: "k1", UploadedBy: "user:trader-2", TaskID: "form-task", ConsignmentID: "c-1"}
+	consignmentFile := &storage.StoredFile{Key: "k2", UploadedBy: "user:trader-2", ConsignmentID: "c-1"}
+	ogaFile := &storage.StoredFile{Key: "k3", UploadedBy: "client:NPQS_TO_NSW", TaskID: "form-task"}
+	issued := &storage.StoredFile{Key: "k4", UploadedBy: storage.SystemPrincipal, TaskID: "form-task", ConsignmentID: "c-1"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		file    *storage.StoredFile
+		access  storage.Access
+		wantErr error
+	}{
+		{"uploader reads", uploader, taskFile, storage.AccessRead, nil},
+		{"uploader deletes", uploader, taskFile, storage.AccessWrite, nil},
+		{"task party reads", trader, taskFile, storage.AccessRead, nil},
+		{"task party cannot delete", trader, taskFile, storage.AccessWrite, storage.ErrAccessDenied},
+		{"consignment party reads", trader, consignmentFile, storage.AccessRead, nil},
+		{"support reads", asUser("s-1", "support@example.com", RoleSupport), consignmentFile, storage.AccessRead, nil},
+		{"injected OGA reads", asClient("NPQS_TO_NSW"), taskFile, storage.AccessRead, nil},
+		{"other OGA is denied", asClient("FCAU_TO_NSW"), taskFile, storage.AccessRead, storage.ErrAccessDenied},
+		{"OGA without a task is denied", asClient("NPQS_TO_NSW"), consignmentFile, storage.AccessRead, storage.ErrAccessDenied},
+		{"OGA uploader deletes", asClient("NPQS_TO_NSW"), ogaFile, storage.AccessWrite, nil},
+		{"trader reads issued document", trader, issued, storage.AccessRead, nil},
+		{"issued document cannot be deleted", trader, issued, storage.AccessWrite, storage.ErrAccessDenied},
+		{"stranger is denied", asUser("trader-3", "t3@example.com", RoleTrader), consignmentFile, storage.AccessRead, storage.ErrAccessDenied},
+		{"no principal", context.Background(), taskFile, storage.AccessRead, ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.AuthorizeFileAccess(tt.ctx, tt.file, tt.access)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_AuthorizeFileLink(t *testing.T) {
+	p := newTestPolicy(t)
+	trader := asUser("trader-1", "trader@example.com", RoleTrader)
+
+	assert.NoError(t, p.AuthorizeFileLink(trader, storage.FileLink{TaskID: "form-task", ConsignmentID: "c-1"}))
+	assert.NoError(t, p.AuthorizeFileLink(asClient("NPQS_TO_NSW"), storage.FileLink{TaskID: "form-task"}))
+	assert.ErrorIs(t, p.AuthorizeFileLink(asClient("NPQS_TO_NSW"), storage.FileLink{ConsignmentID: "c-1"}), storage.ErrAccessDenied)
+	assert.ErrorIs(t, p.AuthorizeFileLink(asUser("trader-2", "t2@example.com", RoleTrader), storage.FileLink{ConsignmentID: "c-1"}), storage.ErrAccessDenied)
+	assert.ErrorIs(t, p.AuthorizeFileLink(asUser("s-1", "support@example.com", RoleSupport), storage.FileLink{ConsignmentID: "c-1"}), storage.ErrAccessDenied)
+}
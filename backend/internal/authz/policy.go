@@ -0,0 +1,288 @@
+// Package authz decides whether an authenticated principal may act on a consignment,
+// pre-consignment or task. Traders act on their own workflows, CHAs on the consignments
+// assigned to them, and OGA clients on the tasks that were injected into their service.
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/profile/cha"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+)
+
+// IdP role names carried in auth.UserContext.Roles.
+const (
+	RoleTrader  = "Trader"
+	RoleCHA     = "CHA"
+	RoleSupport = "Support" // NSW support staff; may inspect any workflow's records but not act on it
+	RoleAdmin   = "Admin"   // NSW administrators; may run back-office operations such as payment reconciliation
+)
+
+var (
+	// ErrUnauthenticated is returned when the request carries no principal.
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden is returned when the principal is not allowed to perform the request.
+	ErrForbidden = errors.New("forbidden")
+	// ErrTaskIDRequired is returned when a task request does not identify a task.
+	ErrTaskIDRequired = errors.New("task_id is required")
+	// ErrTaskNotFound is returned when the task being authorized does not exist.
+	ErrTaskNotFound = errors.New("task not found")
+)
+
+// Parties are the principals a workflow belongs to.
+type Parties struct {
+	TraderID string
+	CHAID    string // Empty for workflows without an assigned CHA, e.g. pre-consignments
+}
+
+// PartyResolver resolves the parties of the workflows owned by one domain.
+// WorkflowParties returns nil if the resolver does not own the workflow.
+type PartyResolver interface {
+	WorkflowParties(ctx context.Context, workflowID string) (*Parties, error)
+}
+
+// TaskLookup loads persisted task records; satisfied by persistence.TaskStore.
+type TaskLookup interface {
+	GetByID(id string) (*persistence.TaskInfo, error)
+}
+
+// ServiceDirectory maps external services to the M2M clients they call back with;
+// satisfied by remote.Manager.
+type ServiceDirectory interface {
+	ResolveServiceID(serviceID, rawURL string) (string, error)
+	CallbackClientIDs(serviceID string) []string
+}
+
+// Policy enforces the per-action policies declared by plugin registrations together with
+// workflow ownership. Anything not explicitly allowed is denied.
+type Policy struct {
+	registry  *plugin.Registry
+	tasks     TaskLookup
+	services  ServiceDirectory
+	chas      cha.Service
+	resolvers []PartyResolver
+}
+
+// NewPolicy creates a Policy. Resolvers are consulted in order to find the parties of a workflow.
+func NewPolicy(registry *plugin.Registry, tasks TaskLookup, services ServiceDirectory, chas cha.Service, resolvers ...PartyResolver) *Policy {
+	return &Policy{
+		registry:  registry,
+		tasks:     tasks,
+		services:  services,
+		chas:      chas,
+		resolvers: resolvers,
+	}
+}
+
+// AuthorizeWorkflow checks that the caller is one of the allowed parties of the workflow.
+// Only PartyTrader and PartyCHA are meaningful here; unknown workflows are forbidden.
+func (p *Policy) AuthorizeWorkflow(ctx context.Context, workflowID string, allow ...plugin.Party) error {
+	authCtx, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	parties, err := p.workflowParties(ctx, workflowID)
+	if err != nil {
+		return err
+	}
+	if parties == nil {
+		return ErrForbidden
+	}
+	ok, err := p.isWorkflowParty(ctx, authCtx, parties, plugin.Allow(allow...))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeWorkflowRead checks that the caller may view records about a workflow, such as its
+// notification deliveries: support staff, or the workflow's trader or CHA.
+func (p *Policy) AuthorizeWorkflowRead(ctx context.Context, workflowID string) error {
+	authCtx, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if authCtx.User != nil && slices.Contains(authCtx.User.Roles, RoleSupport) {
+		return nil
+	}
+	return p.AuthorizeWorkflow(ctx, workflowID, plugin.PartyTrader, plugin.PartyCHA)
+}
+
+// AuthorizeRole checks that the caller is a user holding at least one of the given roles.
+func (p *Policy) AuthorizeRole(ctx context.Context, roles ...string) error {
+	authCtx, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if authCtx.User == nil {
+		return ErrForbidden
+	}
+	for _, role := range roles {
+		if slices.Contains(authCtx.User.Roles, role) {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// AuthorizeTaskAction checks that the caller may execute request on the task, using the
+// policy the task's plugin type declares for the request's action.
+func (p *Policy) AuthorizeTaskAction(ctx context.Context, taskID string, request *plugin.ExecutionRequest) error {
+	authCtx, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	if request == nil {
+		return ErrForbidden
+	}
+	task, reg, err := p.lookupTask(taskID)
+	if err != nil {
+		return err
+	}
+	policy, ok := reg.Policies[request.Action]
+	if !ok {
+		return ErrForbidden
+	}
+	return p.authorizeTask(ctx, authCtx, task, reg, policy, request)
+}
+
+// AuthorizeTaskRead checks that the caller may view the task: the workflow's trader or CHA,
+// or a service the task was injected into.
+func (p *Policy) AuthorizeTaskRead(ctx context.Context, taskID string) error {
+	authCtx, err := principal(ctx)
+	if err != nil {
+		return err
+	}
+	task, reg, err := p.lookupTask(taskID)
+	if err != nil {
+		return err
+	}
+	policy := plugin.Allow(plugin.PartyTrader, plugin.PartyCHA)
+	if reg.CallbackServices != nil {
+		policy.Allow = append(policy.Allow, plugin.PartyInjectedService)
+	}
+	return p.authorizeTask(ctx, authCtx, task, reg, policy, nil)
+}
+
+// principal returns the caller's auth context, or ErrUnauthenticated if there is none.
+func principal(ctx context.Context) (*auth.AuthContext, error) {
+	authCtx := auth.GetAuthContext(ctx)
+	if authCtx == nil || (authCtx.User == nil && authCtx.Client == nil) {
+		return nil, ErrUnauthenticated
+	}
+	return authCtx, nil
+}
+
+func (p *Policy) lookupTask(taskID string) (*persistence.TaskInfo, plugin.Registration, error) {
+	if taskID == "" {
+		return nil, plugin.Registration{}, ErrTaskIDRequired
+	}
+	task, err := p.tasks.GetByID(taskID)
+	if err != nil {
+		return nil, plugin.Registration{}, fmt.Errorf("%w: %s: %w", ErrTaskNotFound, taskID, err)
+	}
+	reg, ok := p.registry.Lookup(task.Type)
+	if !ok {
+		return nil, plugin.Registration{}, ErrForbidden
+	}
+	return task, reg, nil
+}
+
+func (p *Policy) authorizeTask(ctx context.Context, authCtx *auth.AuthContext, task *persistence.TaskInfo, reg plugin.Registration, policy plugin.ActionPolicy, request *plugin.ExecutionRequest) error {
+	if authCtx.Client != nil {
+		if !policy.Allows(plugin.PartyInjectedService) {
+			return ErrForbidden
+		}
+		ok, err := p.isInjectedService(authCtx.Client.ClientID, task, reg, request)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrForbidden
+		}
+		return nil
+	}
+
+	parties, err := p.workflowParties(ctx, task.WorkflowID)
+	if err != nil {
+		return err
+	}
+	if parties == nil {
+		return ErrForbidden
+	}
+	ok, err := p.isWorkflowParty(ctx, authCtx, parties, policy)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// isWorkflowParty reports whether the user is the workflow's trader or CHA, as far as policy allows.
+func (p *Policy) isWorkflowParty(ctx context.Context, authCtx *auth.AuthContext, parties *Parties, policy plugin.ActionPolicy) (bool, error) {
+	user := authCtx.User
+	if user == nil {
+		return false, nil
+	}
+	if policy.Allows(plugin.PartyTrader) && slices.Contains(user.Roles, RoleTrader) &&
+		parties.TraderID != "" && user.ID == parties.TraderID {
+		return true, nil
+	}
+	if policy.Allows(plugin.PartyCHA) && slices.Contains(user.Roles, RoleCHA) && parties.CHAID != "" && p.chas != nil {
+		record, err := p.chas.GetByEmail(ctx, user.Email)
+		if err != nil {
+			if errors.Is(err, cha.ErrCHANotFound) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to resolve CHA profile for %s: %w", user.Email, err)
+		}
+		if record.ID == parties.CHAID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isInjectedService reports whether clientID belongs to a service the task was injected into.
+func (p *Policy) isInjectedService(clientID string, task *persistence.TaskInfo, reg plugin.Registration, request *plugin.ExecutionRequest) (bool, error) {
+	if reg.CallbackServices == nil || p.services == nil {
+		return false, nil
+	}
+	refs, err := reg.CallbackServices(task.Config, request)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve callback services for task %s: %w", task.ID, err)
+	}
+	for _, ref := range refs {
+		serviceID, err := p.services.ResolveServiceID(ref.ServiceID, ref.URL)
+		if err != nil {
+			continue
+		}
+		if slices.Contains(p.services.CallbackClientIDs(serviceID), clientID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *Policy) workflowParties(ctx context.Context, workflowID string) (*Parties, error) {
+	for _, resolver := range p.resolvers {
+		parties, err := resolver.WorkflowParties(ctx, workflowID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve parties of workflow %s: %w", workflowID, err)
+		}
+		if parties != nil {
+			return parties, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,191 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/profile/cha"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+	"github.com/OpenNSW/nsw/pkg/remote"
+)
+
+type fakeTasks map[string]*persistence.TaskInfo
+
+func (f fakeTasks) GetByID(id string) (*persistence.TaskInfo, error) {
+	if task, ok := f[id]; ok {
+		return task, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+type fakeResolver map[string]*Parties
+
+func (f fakeResolver) WorkflowParties(_ context.Context, workflowID string) (*Parties, error) {
+	return f[workflowID], nil
+}
+
+// fakeCHAs resolves CHA records by email.
+type fakeCHAs struct {
+	cha.Service
+	byEmail map[string]string
+}
+
+func (f *fakeCHAs) GetByEmail(_ context.Context, email string) (*cha.Record, error) {
+	if id, ok := f.byEmail[email]; ok {
+		return &cha.Record{ID: id, Email: email}, nil
+	}
+	return nil, cha.ErrCHANotFound
+}
+
+func newTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	registry, err := plugin.NewDefaultRegistry()
+	require.NoError(t, err)
+
+	services := `{"services":[
+		{"id":"npqs","url":"http://npqs.local","callbackClientIds":["NPQS_TO_NSW"]},
+		{"id":"fcau","url":"http://fcau.local","callbackClientIds":["FCAU_TO_NSW"]}
+	]}`
+	path := filepath.Join(t.TempDir(), "services.json")
+	require.NoError(t, os.WriteFile(path, []byte(services), 0o600))
+	rm := remote.NewManager()
+	require.NoError(t, rm.LoadServices(path))
+
+	form := json.RawMessage(`{"formId":"f1","submission":{"serviceId":"npqs","url":"/api/oga/inject"}}`)
+	approval := json.RawMessage(`{"rule":{"type":"ALL"},"approvers":[
+		{"id":"npqs","serviceId":"npqs","url":"/inject","request":{"taskCode":"a"}},
+		{"id":"fcau","url":"http://fcau.local/inject","request":{"taskCode":"b"}}
+	]}`)
+	tasks := fakeTasks{
+		"form-task":     {ID: "form-task", WorkflowID: "c-1", Type: plugin.TaskTypeSimpleForm, Config: form},
+		"approval-task": {ID: "approval-task", WorkflowID: "c-1", Type: plugin.TaskTypeMultiApproval, Config: approval},
+		"pc-task":       {ID: "pc-task", WorkflowID: "pc-1", Type: plugin.TaskTypeSimpleForm, Config: form},
+		"payment-task":  {ID: "payment-task", WorkflowID: "c-1", Type: plugin.TaskTypePayment},
+		"orphan-task":   {ID: "orphan-task", WorkflowID: "unknown", Type: plugin.TaskTypeSimpleForm, Config: form},
+	}
+	resolvers := []PartyResolver{
+		fakeResolver{"c-1": {TraderID: "trader-1", CHAID: "cha-1"}},
+		fakeResolver{"pc-1": {TraderID: "trader-1"}},
+	}
+	chas := &fakeCHAs{byEmail: map[string]string{"cha@example.com": "cha-1", "other-cha@example.com": "cha-2"}}
+	return NewPolicy(registry, tasks, rm, chas, resolvers...)
+}
+
+func asUser(id, email string, roles ...string) context.Context {
+	return context.WithValue(context.Background(), auth.AuthContextKey, &auth.AuthContext{
+		User: &auth.UserContext{ID: id, Email: email, Roles: roles},
+	})
+}
+
+func asClient(clientID string) context.Context {
+	return context.WithValue(context.Background(), auth.AuthContextKey, &auth.AuthContext{
+		Client: &auth.ClientContext{ClientID: clientID},
+	})
+}
+
+func TestPolicy_AuthorizeTaskAction(t *testing.T) {
+	p := newTestPolicy(t)
+	trader := asUser("trader-1", "trader@example.com", RoleTrader)
+	submit := &plugin.ExecutionRequest{Action: plugin.SimpleFormActionSubmit}
+	verify := &plugin.ExecutionRequest{Action: plugin.SimpleFormActionOgaVerify}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		taskID  string
+		request *plugin.ExecutionRequest
+		wantErr error
+	}{
+		{"trader submits own form", trader, "form-task", submit, nil},
+		{"trader submits pre-consignment form", trader, "pc-task", submit, nil},
+		{"other trader is forbidden", asUser("trader-2", "t2@example.com", RoleTrader), "form-task", submit, ErrForbidden},
+		{"owner without trader role is forbidden", asUser("trader-1", "trader@example.com"), "form-task", submit, ErrForbidden},
+		{"assigned CHA submits", asUser("u-9", "cha@example.com", RoleCHA), "form-task", submit, nil},
+		{"other CHA is forbidden", asUser("u-8", "other-cha@example.com", RoleCHA), "form-task", submit, ErrForbidden},
+		{"trader cannot verify", trader, "form-task", verify, ErrForbidden},
+		{"injected OGA verifies", asClient("NPQS_TO_NSW"), "form-task", verify, nil},
+		{"other OGA cannot verify", asClient("FCAU_TO_NSW"), "form-task", verify, ErrForbidden},
+		{"OGA cannot submit", asClient("NPQS_TO_NSW"), "form-task", submit, ErrForbidden},
+		{"undeclared action is denied", trader, "form-task", &plugin.ExecutionRequest{Action: "UNKNOWN"}, ErrForbidden},
+		{"unknown workflow is denied", trader, "orphan-task", submit, ErrForbidden},
+		{"approver answers for itself", asClient("FCAU_TO_NSW"), "approval-task",
+			&plugin.ExecutionRequest{Action: "OGA_VERIFICATION", Source: "fcau"}, nil},
+		{"approver cannot answer for another", asClient("FCAU_TO_NSW"), "approval-task",
+			&plugin.ExecutionRequest{Action: "OGA_VERIFICATION", Source: "npqs"}, ErrForbidden},
+		{"trader initiates payment", trader, "payment-task", &plugin.ExecutionRequest{Action: plugin.PaymentActionInitiate}, nil},
+		{"trader cannot report payment success", trader, "payment-task", &plugin.ExecutionRequest{Action: plugin.PaymentActionSuccess}, ErrForbidden},
+		{"CHA cannot report payment failure", asUser("u-9", "cha@example.com", RoleCHA), "payment-task",
+			&plugin.ExecutionRequest{Action: plugin.PaymentActionFailed}, ErrForbidden},
+		{"no principal", context.Background(), "form-task", submit, ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.AuthorizeTaskAction(tt.ctx, tt.taskID, tt.request)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unknown task", func(t *testing.T) {
+		err := p.AuthorizeTaskAction(trader, "missing", submit)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+		assert.ErrorContains(t, err, "missing")
+	})
+}
+
+func TestPolicy_AuthorizeTaskRead(t *testing.T) {
+	p := newTestPolicy(t)
+
+	assert.NoError(t, p.AuthorizeTaskRead(asUser("trader-1", "trader@example.com", RoleTrader), "form-task"))
+	assert.NoError(t, p.AuthorizeTaskRead(asClient("NPQS_TO_NSW"), "form-task"))
+	assert.NoError(t, p.AuthorizeTaskRead(asClient("NPQS_TO_NSW"), "approval-task"))
+	assert.ErrorIs(t, p.AuthorizeTaskRead(asClient("FCAU_TO_NSW"), "form-task"), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeTaskRead(asUser("trader-2", "t2@example.com", RoleTrader), "form-task"), ErrForbidden)
+}
+
+func TestPolicy_AuthorizeWorkflow(t *testing.T) {
+	p := newTestPolicy(t)
+	chaUser := asUser("u-9", "cha@example.com", RoleCHA)
+	trader := asUser("trader-1", "trader@example.com", RoleTrader)
+
+	assert.NoError(t, p.AuthorizeWorkflow(trader, "c-1", plugin.PartyTrader, plugin.PartyCHA))
+	assert.NoError(t, p.AuthorizeWorkflow(chaUser, "c-1", plugin.PartyCHA))
+	assert.ErrorIs(t, p.AuthorizeWorkflow(trader, "c-1", plugin.PartyCHA), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeWorkflow(chaUser, "pc-1", plugin.PartyTrader, plugin.PartyCHA), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeWorkflow(trader, "unknown", plugin.PartyTrader), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeWorkflow(asClient("NPQS_TO_NSW"), "c-1", plugin.PartyTrader), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeWorkflow(context.Background(), "c-1", plugin.PartyTrader), ErrUnauthenticated)
+}
+
+func TestPolicy_AuthorizeWorkflowRead(t *testing.T) {
+	p := newTestPolicy(t)
+	support := asUser("s-1", "support@example.com", RoleSupport)
+
+	assert.NoError(t, p.AuthorizeWorkflowRead(support, "c-1"))
+	assert.NoError(t, p.AuthorizeWorkflowRead(asUser("u-9", "cha@example.com", RoleCHA), "c-1"))
+	assert.NoError(t, p.AuthorizeWorkflowRead(asUser("trader-1", "trader@example.com", RoleTrader), "pc-1"))
+	assert.ErrorIs(t, p.AuthorizeWorkflowRead(asUser("trader-2", "t2@example.com", RoleTrader), "c-1"), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeWorkflowRead(asClient("NPQS_TO_NSW"), "c-1"), ErrForbidden)
+}
+
+func TestPolicy_AuthorizeRole(t *testing.T) {
+	p := newTestPolicy(t)
+
+	assert.NoError(t, p.AuthorizeRole(asUser("a-1", "admin@example.com", RoleAdmin), RoleAdmin))
+	assert.NoError(t, p.AuthorizeRole(asUser("s-1", "support@example.com", RoleSupport), RoleAdmin, RoleSupport))
+	assert.ErrorIs(t, p.AuthorizeRole(asUser("trader-1", "trader@example.com", RoleTrader), RoleAdmin), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeRole(asClient("NPQS_TO_NSW"), RoleAdmin), ErrForbidden)
+	assert.ErrorIs(t, p.AuthorizeRole(context.Background(), RoleAdmin), ErrUnauthenticated)
+}
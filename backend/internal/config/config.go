@@ -23,6 +23,8 @@ type Config struct {
 	Storage      storage.Config
 	Auth         auth.Config
 	Notification NotificationConfig
+	Payments     PaymentsConfig
+	Documents    DocumentsConfig
 	Temporal     temporal.Config
 }
 
@@ -51,6 +53,50 @@ type NotificationConfig struct {
 	SMTPPassword string
 	SMTPSender   string
 	TemplateRoot string
+
+	// GovSMS channel; SMS is disabled when GovSMSBaseURL is empty
+	GovSMSBaseURL   string
+	GovSMSUserName  string
+	GovSMSPassword  string
+	GovSMSSIDCode   string
+	SMSTemplateRoot string
+
+	// RulesPath is the JSON file mapping workflow and task events to notifications
+	RulesPath string
+
+	// Outbox delivery; failed messages are retried with exponential backoff and
+	// dead-lettered after OutboxMaxAttempts
+	OutboxPollInterval time.Duration
+	OutboxMaxAttempts  int
+	OutboxBaseBackoff  time.Duration
+	OutboxMaxBackoff   time.Duration
+}
+
+// PaymentsConfig holds payment orchestration configuration
+type PaymentsConfig struct {
+	// MethodsConfigPath is the JSON file listing the payment methods offered to traders
+	MethodsConfigPath string
+
+	// Mock provider, for development and sandbox environments. It lets traders settle payments
+	// without paying, so it is only registered when MockEnabled is set.
+	MockEnabled       bool
+	MockCheckoutURL   string
+	MockWebhookSecret string
+
+	// ExpirySweepInterval is how often PENDING payments with an expired session are marked EXPIRED
+	ExpirySweepInterval time.Duration
+}
+
+// DocumentsConfig holds configuration for generated documents such as receipts and certificates
+type DocumentsConfig struct {
+	// VerifyURL is where a document's verification code can be checked; it is printed on each
+	// document followed by the code. Nothing is printed when it is empty.
+	VerifyURL string
+
+	// The public verification endpoint allows each client VerifyRateLimit requests a minute.
+	// Clients are told apart by X-Forwarded-For only when the server runs behind a proxy that sets it.
+	VerifyRateLimit         int
+	VerifyTrustForwardedFor bool
 }
 
 // Load reads configuration from environment variables
@@ -96,6 +142,25 @@ func Load() (*Config, error) {
 			S3PublicURL:    getEnvOrDefault("STORAGE_S3_PUBLIC_URL", ""),
 			LocalPutSecret: getEnvOrDefault("STORAGE_LOCAL_PUT_SECRET", "local-dev-secret"),
 			PresignTTL:     getDurationOrDefault("STORAGE_PRESIGN_TTL", 15*time.Minute),
+
+			AzureEndpoint:    getEnvOrDefault("STORAGE_AZURE_ENDPOINT", ""),
+			AzureAccountName: getEnvOrDefault("STORAGE_AZURE_ACCOUNT_NAME", ""),
+			AzureAccountKey:  getEnvOrDefault("STORAGE_AZURE_ACCOUNT_KEY", ""),
+			AzureContainer:   getEnvOrDefault("STORAGE_AZURE_CONTAINER", "nsw-uploads"),
+
+			GCSEndpoint:        getEnvOrDefault("STORAGE_GCS_ENDPOINT", ""),
+			GCSBucket:          getEnvOrDefault("STORAGE_GCS_BUCKET", "nsw-uploads"),
+			GCSCredentialsFile: getEnvOrDefault("STORAGE_GCS_CREDENTIALS_FILE", ""),
+
+			ScanClamdAddress: getEnvOrDefault("STORAGE_SCAN_CLAMD_ADDRESS", ""),
+			ScanTimeout:      getDurationOrDefault("STORAGE_SCAN_TIMEOUT", 30*time.Second),
+
+			RetentionDraft:     getDurationOrDefault("STORAGE_RETENTION_DRAFT", 0),
+			RetentionUpload:    getDurationOrDefault("STORAGE_RETENTION_UPLOAD", 0),
+			RetentionIssued:    getDurationOrDefault("STORAGE_RETENTION_ISSUED", 0),
+			RetentionAbandoned: getDurationOrDefault("STORAGE_RETENTION_ABANDONED", 24*time.Hour),
+			OrphanGrace:        getDurationOrDefault("STORAGE_ORPHAN_GRACE", 0),
+			SweepInterval:      getDurationOrDefault("STORAGE_SWEEP_INTERVAL", time.Hour),
 		},
 		Auth: auth.Config{
 			JWKSURL:               getEnvOrDefault("AUTH_JWKS_URL", "https://localhost:8090/oauth2/jwks"),
@@ -111,6 +176,32 @@ func Load() (*Config, error) {
 			SMTPPassword: os.Getenv("EMAIL_SMTP_PASSWORD"),
 			SMTPSender:   getEnvOrDefault("EMAIL_SMTP_SENDER", "noreply@nsw.local"),
 			TemplateRoot: getEnvOrDefault("EMAIL_TEMPLATE_ROOT", "./configs/email-templates"),
+
+			GovSMSBaseURL:   getEnvOrDefault("SMS_GOV_BASE_URL", ""),
+			GovSMSUserName:  getEnvOrDefault("SMS_GOV_USERNAME", ""),
+			GovSMSPassword:  os.Getenv("SMS_GOV_PASSWORD"),
+			GovSMSSIDCode:   getEnvOrDefault("SMS_GOV_SID_CODE", ""),
+			SMSTemplateRoot: getEnvOrDefault("SMS_TEMPLATE_ROOT", "./configs/sms-templates"),
+
+			RulesPath: getEnvOrDefault("NOTIFICATION_RULES_PATH", "configs/notification-rules.json"),
+
+			OutboxPollInterval: getDurationOrDefault("NOTIFICATION_OUTBOX_POLL_INTERVAL", 5*time.Second),
+			OutboxMaxAttempts:  getIntEnvOrDefault("NOTIFICATION_OUTBOX_MAX_ATTEMPTS", 8),
+			OutboxBaseBackoff:  getDurationOrDefault("NOTIFICATION_OUTBOX_BASE_BACKOFF", 30*time.Second),
+			OutboxMaxBackoff:   getDurationOrDefault("NOTIFICATION_OUTBOX_MAX_BACKOFF", time.Hour),
+		},
+		Payments: PaymentsConfig{
+			MethodsConfigPath: getEnvOrDefault("PAYMENT_METHODS_CONFIG_PATH", "configs/payment_methods.json"),
+			MockEnabled:       getBoolOrDefault("PAYMENT_MOCK_ENABLED", false),
+			MockCheckoutURL:   getEnvOrDefault("PAYMENT_MOCK_CHECKOUT_URL", "https://sandbox.govpay.lk/checkout"),
+			MockWebhookSecret: os.Getenv("PAYMENT_MOCK_WEBHOOK_SECRET"),
+
+			ExpirySweepInterval: getDurationOrDefault("PAYMENT_EXPIRY_SWEEP_INTERVAL", 5*time.Minute),
+		},
+		Documents: DocumentsConfig{
+			VerifyURL:               getEnvOrDefault("DOCUMENT_VERIFY_URL", getEnvOrDefault("SERVICE_URL", fmt.Sprintf("http://localhost:%d", serverPort))+"/api/v1/verify"),
+			VerifyRateLimit:         getIntEnvOrDefault("DOCUMENT_VERIFY_RATE_LIMIT", 30),
+			VerifyTrustForwardedFor: getBoolOrDefault("DOCUMENT_VERIFY_TRUST_FORWARDED_FOR", false),
 		},
 		Temporal: temporal.Config{
 			Host:      getEnvOrDefault("TEMPORAL_HOST", "localhost"),
@@ -144,9 +235,20 @@ func (c *Config) Validate() error {
 	if err := c.Auth.Validate(); err != nil {
 		return fmt.Errorf("invalid auth configuration: %w", err)
 	}
+	if c.Documents.VerifyURL != "" {
+		if err := validation.HTTPURL("DOCUMENT_VERIFY_URL", c.Documents.VerifyURL); err != nil {
+			return err
+		}
+	}
+	if c.Documents.VerifyRateLimit <= 0 {
+		return fmt.Errorf("DOCUMENT_VERIFY_RATE_LIMIT must be positive")
+	}
 	if err := c.Temporal.Validate(); err != nil {
 		return fmt.Errorf("invalid temporal configuration: %w", err)
 	}
+	if c.Payments.MockEnabled && c.Payments.MockWebhookSecret == "" {
+		return fmt.Errorf("PAYMENT_MOCK_WEBHOOK_SECRET is required when PAYMENT_MOCK_ENABLED is set")
+	}
 	if len(c.CORS.AllowedOrigins) == 0 {
 		return fmt.Errorf("CORS_ALLOWED_ORIGINS is required")
 	}
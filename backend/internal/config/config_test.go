@@ -60,3 +60,53 @@ func TestLoadTemporalInvalidPortFallsBackToDefault(t *testing.T) {
 		t.Fatalf("Port = %d, want default %d", cfg.Temporal.Port, 7233)
 	}
 }
+
+func TestLoadDocumentVerifyURLDefaultsToServiceURL(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "test")
+	t.Setenv("SERVICE_URL", "https://nsw.example")
+	t.Setenv("DOCUMENT_VERIFY_URL", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Documents.VerifyURL != "https://nsw.example/api/v1/verify" {
+		t.Fatalf("VerifyURL default = %q, want %q", cfg.Documents.VerifyURL, "https://nsw.example/api/v1/verify")
+	}
+}
+
+func TestLoadNotificationRulesPathDefaultsToShippedRules(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "test")
+	t.Setenv("NOTIFICATION_RULES_PATH", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Notification.RulesPath != "configs/notification-rules.json" {
+		t.Fatalf("RulesPath default = %q, want %q", cfg.Notification.RulesPath, "configs/notification-rules.json")
+	}
+}
+
+func TestLoadPaymentMockProviderDisabledByDefault(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "test")
+	t.Setenv("PAYMENT_MOCK_ENABLED", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Payments.MockEnabled {
+		t.Fatal("MockEnabled = true, want false by default")
+	}
+}
+
+func TestLoadPaymentMockProviderRequiresWebhookSecret(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "test")
+	t.Setenv("PAYMENT_MOCK_ENABLED", "true")
+	t.Setenv("PAYMENT_MOCK_WEBHOOK_SECRET", "")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() error = nil, want an error for a missing mock webhook secret")
+	}
+}
@@ -1,23 +1,32 @@
 package consignment
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/authz"
 	"github.com/OpenNSW/nsw/internal/profile/cha"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
 	"github.com/OpenNSW/nsw/utils"
 )
 
+// Authorizer checks that the caller is one of the allowed parties of a consignment.
+type Authorizer interface {
+	AuthorizeWorkflow(ctx context.Context, workflowID string, allow ...plugin.Party) error
+}
+
 type Router struct {
-	cs  *Service
-	cha cha.Service
+	cs    *Service
+	cha   cha.Service
+	authz Authorizer
 }
 
-func NewRouter(cs *Service, chaService cha.Service) *Router {
-	return &Router{cs: cs, cha: chaService}
+func NewRouter(cs *Service, chaService cha.Service, authorizer Authorizer) *Router {
+	return &Router{cs: cs, cha: chaService, authz: authorizer}
 }
 
 // HandleCreateConsignment handles POST /api/v1/consignments
@@ -152,8 +161,11 @@ func (c *Router) HandleInitializeConsignment(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	consignmentID := consignmentIDStr
-	// TODO: Need to Call GetConsignmentByID and check whether the Consignment.ChaID and authContext.UserID are equal
-	// Otherwise Forbidden
+	// Only the CHA assigned at Stage 1 may select the HS Codes.
+	if err := c.authz.AuthorizeWorkflow(ctx, consignmentID, plugin.PartyCHA); err != nil {
+		writeAuthorizationError(w, err)
+		return
+	}
 	var req InitializeConsignmentDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
@@ -203,6 +215,11 @@ func (c *Router) HandleGetConsignmentByID(w http.ResponseWriter, r *http.Request
 	// Parse UUID
 	consignmentID := consignmentIDStr
 
+	if err := c.authz.AuthorizeWorkflow(ctx, consignmentID, plugin.PartyTrader, plugin.PartyCHA); err != nil {
+		writeAuthorizationError(w, err)
+		return
+	}
+
 	// Get consignment from service
 	consignment, err := c.cs.GetConsignmentByID(r.Context(), consignmentID)
 	if err != nil {
@@ -220,3 +237,16 @@ func (c *Router) HandleGetConsignmentByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 }
+
+// writeAuthorizationError writes the HTTP error for a failed authorization check.
+func writeAuthorizationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, authz.ErrUnauthenticated):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, authz.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		slog.Error("failed to authorize consignment access", "error", err)
+		http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+	}
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/authz"
 	"github.com/OpenNSW/nsw/internal/profile/cha"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
 )
 
 func withAuthContext(ctx context.Context, userID string) context.Context {
@@ -36,7 +38,7 @@ func TestConsignmentRouter_HandleGetConsignmentByID(t *testing.T) {
 	mockWM := new(MockWMV2)
 	svc := NewService(db, nil, nil, nil)
 	require.NoError(t, svc.RegisterWorkflowManager(mockWM))
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	consignmentID := uuid.NewString()
 	sqlMock.MatchExpectationsInOrder(false)
@@ -58,7 +60,7 @@ func TestConsignmentRouter_HandleGetConsignmentByID(t *testing.T) {
 func TestConsignmentRouter_HandleGetConsignments(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	svc := NewService(db, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	traderID := "trader1"
 	sqlMock.MatchExpectationsInOrder(false)
@@ -78,7 +80,7 @@ func TestConsignmentRouter_HandleGetConsignments_RoleCHA(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	mockCHA := new(MockCHAService)
 	svc := NewService(db, nil, mockCHA, nil)
-	r := NewRouter(svc, mockCHA)
+	r := NewRouter(svc, mockCHA, &stubAuthorizer{})
 
 	email := "cha@example.com"
 	chaID := "cha1"
@@ -96,7 +98,7 @@ func TestConsignmentRouter_HandleGetConsignments_RoleCHA(t *testing.T) {
 func TestConsignmentRouter_HandleCreateConsignment(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	svc := NewService(db, nil, cha.NewService(db), nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	traderID := "trader1"
 	chaID := uuid.NewString()
@@ -133,7 +135,7 @@ func TestConsignmentRouter_HandleInitializeConsignment(t *testing.T) {
 	mockWM := new(MockWMV2)
 	svc := NewService(db, nil, nil, nil)
 	require.NoError(t, svc.RegisterWorkflowManager(mockWM))
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	id := uuid.NewString()
 	hsID := uuid.NewString()
@@ -169,7 +171,7 @@ func TestConsignmentRouter_HandleInitializeConsignment(t *testing.T) {
 
 func TestConsignmentRouter_HandleInitializeConsignment_NoID(t *testing.T) {
 	svc := NewService(nil, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("PUT", "/api/v1/consignments/", bytes.NewReader([]byte{}))
 	req = req.WithContext(withAuthContext(req.Context(), "user1"))
@@ -181,7 +183,7 @@ func TestConsignmentRouter_HandleInitializeConsignment_NoID(t *testing.T) {
 
 func TestConsignmentRouter_HandleInitializeConsignment_InvalidBody(t *testing.T) {
 	svc := NewService(nil, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("PUT", "/api/v1/consignments/id", bytes.NewBufferString("invalid json"))
 	req.SetPathValue("id", "id")
@@ -195,7 +197,7 @@ func TestConsignmentRouter_HandleInitializeConsignment_InvalidBody(t *testing.T)
 func TestConsignmentRouter_HandleGetConsignmentByID_InvalidID(t *testing.T) {
 	db, _ := setupTestDB(t)
 	svc := NewService(db, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("GET", "/api/v1/consignments/invalid-uuid", nil)
 	req.SetPathValue("id", "invalid-uuid")
@@ -208,7 +210,7 @@ func TestConsignmentRouter_HandleGetConsignmentByID_InvalidID(t *testing.T) {
 func TestConsignmentRouter_HandleGetConsignments_PaginationError(t *testing.T) {
 	db, _ := setupTestDB(t)
 	svc := NewService(db, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("GET", "/api/v1/consignments?limit=invalid", nil)
 	req = req.WithContext(withAuthContext(req.Context(), "trader1"))
@@ -222,7 +224,7 @@ func TestConsignmentRouter_HandleGetConsignments_PaginationError(t *testing.T) {
 func TestConsignmentRouter_HandleGetConsignmentByID_ServiceError(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	svc := NewService(db, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	id := uuid.NewString()
 	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"consignments\"").WillReturnError(fmt.Errorf("db error"))
@@ -239,7 +241,7 @@ func TestConsignmentRouter_HandleGetConsignmentByID_ServiceError(t *testing.T) {
 func TestConsignmentRouter_HandleGetConsignments_ServiceError(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	svc := NewService(db, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	sqlMock.ExpectQuery("(?i)SELECT count").WillReturnError(fmt.Errorf("db error"))
 
@@ -253,7 +255,7 @@ func TestConsignmentRouter_HandleGetConsignments_ServiceError(t *testing.T) {
 func TestConsignmentRouter_HandleCreateConsignment_CHANotFound(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	svc := NewService(db, nil, cha.NewService(db), nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	chaID := uuid.NewString()
 	payload := CreateConsignmentDTO{Flow: FlowImport, ChaID: chaID}
@@ -272,7 +274,7 @@ func TestConsignmentRouter_HandleCreateConsignment_CHANotFound(t *testing.T) {
 func TestConsignmentRouter_HandleCreateConsignment_InvalidPayload(t *testing.T) {
 	db, _ := setupTestDB(t)
 	svc := NewService(db, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("POST", "/api/v1/consignments", bytes.NewBufferString("invalid json"))
 	req = req.WithContext(withAuthContext(req.Context(), "trader1"))
@@ -283,7 +285,7 @@ func TestConsignmentRouter_HandleCreateConsignment_InvalidPayload(t *testing.T)
 
 func TestConsignmentRouter_HandleGetConsignments_InvalidRole(t *testing.T) {
 	svc := NewService(nil, nil, nil, nil)
-	r := NewRouter(svc, nil)
+	r := NewRouter(svc, nil, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("GET", "/api/v1/consignments?role=invalid", nil)
 	req = req.WithContext(withAuthContext(req.Context(), "user1"))
@@ -296,7 +298,7 @@ func TestConsignmentRouter_HandleGetConsignments_InvalidRole(t *testing.T) {
 func TestConsignmentRouter_HandleGetConsignments_CHANotFound(t *testing.T) {
 	mockCHA := new(MockCHAService)
 	svc := NewService(nil, nil, mockCHA, nil)
-	r := NewRouter(svc, mockCHA)
+	r := NewRouter(svc, mockCHA, &stubAuthorizer{})
 
 	mockCHA.On("GetByEmail", mock.Anything, "cha@example.com").Return(nil, cha.ErrCHANotFound)
 
@@ -309,7 +311,7 @@ func TestConsignmentRouter_HandleGetConsignments_CHANotFound(t *testing.T) {
 }
 
 func TestConsignmentRouter_HandleGetConsignments_Unauthorized(t *testing.T) {
-	r := NewRouter(nil, nil)
+	r := NewRouter(nil, nil, &stubAuthorizer{})
 	req, _ := http.NewRequest("GET", "/api/v1/consignments", nil)
 	w := httptest.NewRecorder()
 	r.HandleGetConsignments(w, req)
@@ -317,7 +319,7 @@ func TestConsignmentRouter_HandleGetConsignments_Unauthorized(t *testing.T) {
 }
 
 func TestConsignmentRouter_HandleCreateConsignment_Unauthorized(t *testing.T) {
-	r := NewRouter(nil, nil)
+	r := NewRouter(nil, nil, &stubAuthorizer{})
 	req, _ := http.NewRequest("POST", "/api/v1/consignments", bytes.NewBufferString("{}"))
 	w := httptest.NewRecorder()
 	r.HandleCreateConsignment(w, req)
@@ -325,7 +327,7 @@ func TestConsignmentRouter_HandleCreateConsignment_Unauthorized(t *testing.T) {
 }
 
 func TestConsignmentRouter_HandleGetConsignmentByID_Unauthorized(t *testing.T) {
-	r := NewRouter(nil, nil)
+	r := NewRouter(nil, nil, &stubAuthorizer{})
 	req, _ := http.NewRequest("GET", "/api/v1/consignments/id", nil)
 	w := httptest.NewRecorder()
 	r.HandleGetConsignmentByID(w, req)
@@ -333,7 +335,7 @@ func TestConsignmentRouter_HandleGetConsignmentByID_Unauthorized(t *testing.T) {
 }
 
 func TestConsignmentRouter_HandleGetConsignmentByID_MissingID(t *testing.T) {
-	r := NewRouter(nil, nil)
+	r := NewRouter(nil, nil, &stubAuthorizer{})
 	req, _ := http.NewRequest("GET", "/api/v1/consignments/", nil)
 	req = req.WithContext(withAuthContext(req.Context(), "trader1"))
 	w := httptest.NewRecorder()
@@ -342,7 +344,7 @@ func TestConsignmentRouter_HandleGetConsignmentByID_MissingID(t *testing.T) {
 }
 
 func TestConsignmentRouter_HandleInitializeConsignment_Unauthorized(t *testing.T) {
-	r := NewRouter(nil, nil)
+	r := NewRouter(nil, nil, &stubAuthorizer{})
 	req, _ := http.NewRequest("PUT", "/api/v1/consignments/id", bytes.NewBufferString("{}"))
 	w := httptest.NewRecorder()
 	r.HandleInitializeConsignment(w, req)
@@ -350,7 +352,7 @@ func TestConsignmentRouter_HandleInitializeConsignment_Unauthorized(t *testing.T
 }
 
 func TestConsignmentRouter_HandleInitializeConsignment_EmptyHSCodes(t *testing.T) {
-	r := NewRouter(nil, nil)
+	r := NewRouter(nil, nil, &stubAuthorizer{})
 	body, _ := json.Marshal(InitializeConsignmentDTO{HSCodeIDs: []string{}})
 	req, _ := http.NewRequest("PUT", "/api/v1/consignments/id", bytes.NewBuffer(body))
 	req.SetPathValue("id", "id")
@@ -363,7 +365,7 @@ func TestConsignmentRouter_HandleInitializeConsignment_EmptyHSCodes(t *testing.T
 func TestConsignmentRouter_HandleGetConsignments_CHALookupError(t *testing.T) {
 	mockCHA := new(MockCHAService)
 	svc := NewService(nil, nil, mockCHA, nil)
-	r := NewRouter(svc, mockCHA)
+	r := NewRouter(svc, mockCHA, &stubAuthorizer{})
 	mockCHA.On("GetByEmail", mock.Anything, "cha@example.com").Return(nil, fmt.Errorf("db down"))
 
 	req, _ := http.NewRequest("GET", "/api/v1/consignments?role=cha", nil)
@@ -372,3 +374,38 @@ func TestConsignmentRouter_HandleGetConsignments_CHALookupError(t *testing.T) {
 	r.HandleGetConsignments(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestConsignmentRouter_HandleGetConsignmentByID_Forbidden(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	svc := NewService(db, nil, nil, nil)
+	authorizer := &stubAuthorizer{err: authz.ErrForbidden}
+	r := NewRouter(svc, nil, authorizer)
+
+	id := uuid.NewString()
+	req, _ := http.NewRequest("GET", "/api/v1/consignments/"+id, nil)
+	req.SetPathValue("id", id)
+	req = req.WithContext(withAuthContext(req.Context(), "trader2"))
+
+	w := httptest.NewRecorder()
+	r.HandleGetConsignmentByID(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, []plugin.Party{plugin.PartyTrader, plugin.PartyCHA}, authorizer.allowed)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestConsignmentRouter_HandleInitializeConsignment_Forbidden(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil)
+	authorizer := &stubAuthorizer{err: authz.ErrForbidden}
+	r := NewRouter(svc, nil, authorizer)
+
+	id := uuid.NewString()
+	body, _ := json.Marshal(InitializeConsignmentDTO{HSCodeIDs: []string{uuid.NewString()}})
+	req, _ := http.NewRequest("PUT", "/api/v1/consignments/"+id, bytes.NewBuffer(body))
+	req.SetPathValue("id", id)
+	req = req.WithContext(withAuthContext(req.Context(), "trader1"))
+
+	w := httptest.NewRecorder()
+	r.HandleInitializeConsignment(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, []plugin.Party{plugin.PartyCHA}, authorizer.allowed)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,6 +11,7 @@ import (
 
 	workflowmanager "github.com/OpenNSW/go-temporal-workflow"
 
+	"github.com/OpenNSW/nsw/internal/authz"
 	"github.com/OpenNSW/nsw/internal/hscode"
 	"github.com/OpenNSW/nsw/internal/profile/cha"
 	"github.com/OpenNSW/nsw/internal/workflow/model"
@@ -62,6 +62,30 @@ func (s *Service) CompletionHandler(workflowID string, finalContext map[string]a
 	return s.OnWorkflowStatusChanged(context.Background(), s.db, workflowID, model.WorkflowStatusInProgress, model.WorkflowStatusCompleted, nil)
 }
 
+// OwnsWorkflow reports whether the workflow with the given ID belongs to a consignment.
+// Consignment workflows share the ID of the consignment that started them.
+func (s *Service) OwnsWorkflow(ctx context.Context, workflowID string) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&Consignment{}).Where("id = ?", workflowID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to look up consignment %s: %w", workflowID, err)
+	}
+	return count > 0, nil
+}
+
+// WorkflowParties implements authz.PartyResolver for consignment workflows.
+// Returns nil if the workflow does not belong to a consignment.
+func (s *Service) WorkflowParties(ctx context.Context, workflowID string) (*authz.Parties, error) {
+	var consignment Consignment
+	err := s.db.WithContext(ctx).Select("id", "trader_id", "cha_id").Where("id = ?", workflowID).Take(&consignment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up consignment %s: %w", workflowID, err)
+	}
+	return &authz.Parties{TraderID: consignment.TraderID, CHAID: consignment.CHAID}, nil
+}
+
 // --- WorkflowEventHandler implementation ---
 
 // OnWorkflowStatusChanged handles workflow lifecycle state propagation to consignment domain state.
@@ -103,6 +127,7 @@ func (s *Service) CreateConsignmentShell(ctx context.Context, flow Flow, chaID s
 }
 
 // InitializeConsignmentByID runs Stage 2: CHA selects one or more HS Codes; creates workflow and sets state to IN_PROGRESS.
+// When the HS codes map to different workflow templates, the templates are merged into a single workflow.
 // Returns error if consignment is not in INITIALIZED.
 func (s *Service) InitializeConsignmentByID(
 	ctx context.Context,
@@ -145,30 +170,39 @@ func (s *Service) InitializeConsignmentByID(
 		return nil, fmt.Errorf("failed to update consignment: %w", err)
 	}
 
-	// TODO: add support for collapsing multiple HS codes to one workflow.
-	// Currently, assumes that there is only one HS code selected.
-	if len(hsCodeIDs) > 1 {
-		tx.Rollback()
-		return nil, fmt.Errorf("workflow manager currently supports only one HS code")
-	}
+	// Each HS code maps to its own workflow template; the templates are merged so that the
+	// consignment still runs as a single workflow.
+	definitions := make([]workflowmanager.WorkflowDefinition, 0, len(hsCodeIDs))
+	seenHSCodes := make(map[string]bool, len(hsCodeIDs))
+	for _, hsCodeID := range hsCodeIDs {
+		if seenHSCodes[hsCodeID] {
+			continue
+		}
+		seenHSCodes[hsCodeID] = true
 
-	var mapping WorkflowTemplateMap
-	err := tx.Model(&WorkflowTemplateMap{}).
-		Preload("WorkflowTemplate").
-		Where("hs_code_id = ? AND consignment_flow = ?", hsCodeIDs[0], consignment.Flow).
-		First(&mapping).Error
+		var mapping WorkflowTemplateMap
+		err := tx.Model(&WorkflowTemplateMap{}).
+			Preload("WorkflowTemplate").
+			Where("hs_code_id = ? AND consignment_flow = ?", hsCodeID, consignment.Flow).
+			First(&mapping).Error
 
-	if err != nil {
-		tx.Rollback()
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("no workflow template found for HS code %s and flow %s", hsCodeIDs[0], consignment.Flow)
+		if err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("no workflow template found for HS code %s and flow %s", hsCodeID, consignment.Flow)
+			}
+			return nil, fmt.Errorf("failed to get workflow template: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get workflow template: %w", err)
+		definitions = append(definitions, mapping.WorkflowTemplate.WorkflowDefinition)
 	}
 
-	wt := &mapping.WorkflowTemplate
+	definition, err := mergeWorkflowDefinitions(consignment.ID, definitions)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to merge workflow templates: %w", err)
+	}
 
-	if err := s.wm.StartWorkflow(ctx, consignment.ID, wt.WorkflowDefinition, globalContext); err != nil {
+	if err := s.wm.StartWorkflow(ctx, consignment.ID, definition, globalContext); err != nil {
 		tx.Rollback()
 		return nil, fmt.Errorf("failed to register workflow: %w", err)
 	}
@@ -183,9 +217,7 @@ func (s *Service) InitializeConsignmentByID(
 		return nil, fmt.Errorf("failed to reload consignment: %w", err)
 	}
 
-	var workflowInstance *workflowmanager.WorkflowInstance
-
-	workflowInstance, err = s.wm.GetStatus(ctx, consignment.ID)
+	workflowInstance, err := s.wm.GetStatus(ctx, consignment.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workflow details: %w", err)
 	}
@@ -451,71 +483,9 @@ func (s *Service) buildConsignmentDetailDTO(
 		return nil, err
 	}
 
-	nodeResponseDTOs := make([]model.WorkflowNodeResponseDTO, 0)
-	edgeResponseDTOs := make([]model.WorkflowEdgeResponseDTO, 0)
-
-	if workflowV2 != nil {
-		taskTemplateIDs := make([]string, 0, len(workflowV2.NodeInfo))
-		for _, node := range workflowV2.NodeInfo {
-			if node.Type == workflowmanager.NodeTypeTask {
-				taskTemplateIDs = append(taskTemplateIDs, node.TaskTemplateID)
-			}
-		}
-		taskTemplates, err := s.templateProvider.GetWorkflowNodeTemplatesByIDs(ctx, taskTemplateIDs)
-		if err != nil {
-			return nil, fmt.Errorf("failed to retrieve workflow node templates for consignment %s: %w", consignment.ID, err)
-		}
-		taskTemplateMap := make(map[string]model.WorkflowNodeTemplate)
-		for _, taskTemplate := range taskTemplates {
-			taskTemplateMap[taskTemplate.ID] = taskTemplate
-		}
-		for _, node := range workflowV2.NodeInfo {
-			var taskName, taskDescription, taskType string
-			var nodeState model.WorkflowNodeState
-			if node.Type == workflowmanager.NodeTypeTask {
-				taskTemplate, ok := taskTemplateMap[node.TaskTemplateID]
-				if !ok {
-					slog.Error("failed to retrieve workflow node template for", "consignment_id", consignment.ID, "node_id", node.ID, "task_template_id", node.TaskTemplateID)
-					return nil, fmt.Errorf("failed to retrieve workflow node template %s for node %s", node.TaskTemplateID, node.ID)
-				}
-				taskName = taskTemplate.Name
-				taskDescription = taskTemplate.Description
-				taskType = string(taskTemplate.Type)
-			} else {
-				taskType = string(node.Type)
-			}
-			// TODO: clean up translations once the frontend is updated.
-			switch node.Status {
-			case workflowmanager.NodeStatusRunning:
-				nodeState = model.WorkflowNodeStateInProgress
-			case workflowmanager.NodeStatusCompleted:
-				nodeState = model.WorkflowNodeStateCompleted
-			case workflowmanager.NodeStatusFailed:
-				nodeState = model.WorkflowNodeStateFailed
-			case workflowmanager.NodeStatusNotStarted:
-				nodeState = model.WorkflowNodeStateLocked
-			}
-			nodeResponseDTOs = append(nodeResponseDTOs, model.WorkflowNodeResponseDTO{
-				ID:        node.ID,
-				CreatedAt: node.CreatedAt.Format(time.RFC3339),
-				UpdatedAt: node.UpdatedAt.Format(time.RFC3339),
-				WorkflowNodeTemplate: model.WorkflowNodeTemplateResponseDTO{
-					Name:        taskName,
-					Description: taskDescription,
-					Type:        taskType,
-				},
-				State:     nodeState,
-				DependsOn: []string{}, // TODO: should be removed or should be populated based on the workflow definition (not currently stored in DB for v2 workflows)
-			})
-		}
-		for _, edge := range workflowV2.Edges {
-			edgeResponseDTOs = append(edgeResponseDTOs, model.WorkflowEdgeResponseDTO{
-				ID:        edge.ID,
-				SourceID:  edge.SourceID,
-				TargetID:  edge.TargetID,
-				Condition: edge.Condition,
-			})
-		}
+	nodeResponseDTOs, edgeResponseDTOs, err := service.BuildWorkflowGraphDTOs(ctx, s.templateProvider, workflowV2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build workflow graph for consignment %s: %w", consignment.ID, err)
 	}
 
 	return &DetailDTO{
@@ -14,6 +14,7 @@ import (
 	"gorm.io/gorm"
 
 	workflowManagerV2 "github.com/OpenNSW/go-temporal-workflow"
+	"github.com/OpenNSW/nsw/internal/authz"
 	"github.com/OpenNSW/nsw/internal/hscode"
 	"github.com/OpenNSW/nsw/internal/profile/cha"
 	"github.com/OpenNSW/nsw/internal/workflow/model"
@@ -168,20 +169,34 @@ func TestConsignmentService_InitializeConsignmentByID_WrongState(t *testing.T) {
 	assert.Contains(t, err.Error(), "must be in INITIALIZED")
 }
 
-func TestConsignmentService_InitializeConsignmentByID_MultipleHSCodeError(t *testing.T) {
+func TestConsignmentService_InitializeConsignmentByID_MultipleHSCodeMissingTemplate(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	svc := NewService(db, nil, nil, nil)
 	id := uuid.NewString()
+	wtID := uuid.NewString()
 	sqlMock.ExpectQuery(`SELECT \* FROM "consignments" WHERE id = \$1`).
 		WithArgs(id, 1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "state"}).AddRow(id, "INITIALIZED"))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "state", "flow"}).AddRow(id, "INITIALIZED", "EXPORT"))
 
 	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(`UPDATE "consignments"`).WillReturnResult(sqlmock.NewResult(1, 1))
 
+	sqlMock.ExpectQuery(`SELECT \* FROM "workflow_template_map"`).
+		WithArgs("hs1", "EXPORT", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hs_code_id", "consignment_flow", "workflow_template_id"}).
+			AddRow(uuid.NewString(), "hs1", "EXPORT", wtID))
+	sqlMock.ExpectQuery(`SELECT \* FROM "workflow_template_v2"`).
+		WithArgs(wtID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "workflow_definition"}).
+			AddRow(wtID, "tmpl", "v1", []byte(`{"id":"tmpl"}`)))
+	sqlMock.ExpectQuery(`SELECT \* FROM "workflow_template_map"`).
+		WithArgs("hs2", "EXPORT", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	sqlMock.ExpectRollback()
+
 	_, err := svc.InitializeConsignmentByID(context.Background(), id, []string{"hs1", "hs2"}, nil)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "supports only one HS code")
+	assert.Contains(t, err.Error(), "no workflow template found for HS code hs2")
 }
 
 func TestConsignmentService_InitializeConsignmentByID_NoTemplate(t *testing.T) {
@@ -630,3 +645,23 @@ func TestConsignmentService_BuildItemResponseDTOs_MissingHSCode(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "HS code not found")
 }
+
+func TestConsignmentService_WorkflowParties(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	svc := NewService(db, nil, nil, nil)
+	ctx := context.Background()
+
+	sqlMock.ExpectQuery(`SELECT "id","trader_id","cha_id" FROM "consignments" WHERE id = \$1`).
+		WithArgs("c-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id", "cha_id"}).AddRow("c-1", "trader-1", "cha-1"))
+	parties, err := svc.WorkflowParties(ctx, "c-1")
+	require.NoError(t, err)
+	assert.Equal(t, &authz.Parties{TraderID: "trader-1", CHAID: "cha-1"}, parties)
+
+	sqlMock.ExpectQuery(`SELECT "id","trader_id","cha_id" FROM "consignments" WHERE id = \$1`).
+		WithArgs("pc-1", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	parties, err = svc.WorkflowParties(ctx, "pc-1")
+	require.NoError(t, err)
+	assert.Nil(t, parties)
+}
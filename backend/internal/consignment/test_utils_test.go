@@ -10,6 +10,8 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	"github.com/OpenNSW/nsw/internal/task/plugin"
 )
 
 // MockWMV2 implements workflowManagerV2.TemporalManager for testing.
@@ -40,6 +42,17 @@ func (m *MockWMV2) GetStatus(ctx context.Context, workflowID string) (*workflowM
 	return args.Get(0).(*workflowManagerV2.WorkflowInstance), args.Error(1)
 }
 
+// stubAuthorizer returns a fixed authorization result; the zero value allows everything.
+type stubAuthorizer struct {
+	err     error
+	allowed []plugin.Party
+}
+
+func (a *stubAuthorizer) AuthorizeWorkflow(_ context.Context, _ string, allow ...plugin.Party) error {
+	a.allowed = allow
+	return a.err
+}
+
 func setupTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
 	mockDB, sqlMock, err := sqlmock.New()
 	if err != nil {
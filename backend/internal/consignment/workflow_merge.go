@@ -0,0 +1,522 @@
+package consignment
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	workflowmanager "github.com/OpenNSW/go-temporal-workflow"
+)
+
+// Node and gateway types used by the workflow definition JSON schema.
+const (
+	defNodeTypeStart   = "START"
+	defNodeTypeEnd     = "END"
+	defNodeTypeTask    = "TASK"
+	defNodeTypeGateway = "GATEWAY"
+
+	defGatewayParallelSplit = "PARALLEL_SPLIT"
+	defGatewayParallelJoin  = "PARALLEL_JOIN"
+	defGatewayExclusiveJoin = "EXCLUSIVE_JOIN"
+)
+
+// IDs of the synthetic nodes added to a merged workflow definition.
+const (
+	mergedStartNodeID = "merged_start"
+	mergedSplitNodeID = "merged_parallel_split"
+	mergedJoinNodeID  = "merged_parallel_join"
+	mergedEndNodeID   = "merged_end"
+)
+
+// definitionGraph is a schema-agnostic view of a workflow definition. Nodes and edges are kept
+// as raw JSON objects so that mapping fields (input_mapping, output_mapping, etc.) survive the
+// merge without this package having to know every field of the upstream types.
+type definitionGraph struct {
+	id       string
+	nodes    []map[string]any
+	edges    []map[string]any
+	nodeByID map[string]map[string]any
+	outgoing map[string][]map[string]any
+	incoming map[string][]map[string]any
+	startID  string
+	endID    string
+}
+
+// mergeWorkflowDefinitions combines the workflow definitions of several HS codes into a single
+// definition that can be started as one workflow run.
+//
+// Identical definitions are collapsed. Linear runs of task steps that all definitions share at
+// the start (e.g. general information, customs declaration, payment) or at the end are kept once;
+// the remaining per-template bodies run in parallel between a PARALLEL_SPLIT and a PARALLEL_JOIN,
+// and a task step that several bodies share only runs in the first of them (see addBranch).
+func mergeWorkflowDefinitions(workflowID string, definitions []workflowmanager.WorkflowDefinition) (workflowmanager.WorkflowDefinition, error) {
+	if len(definitions) == 0 {
+		return workflowmanager.WorkflowDefinition{}, fmt.Errorf("at least one workflow definition is required")
+	}
+
+	unique := make([]workflowmanager.WorkflowDefinition, 0, len(definitions))
+	for _, def := range definitions {
+		duplicate := false
+		for _, seen := range unique {
+			if reflect.DeepEqual(seen, def) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, def)
+		}
+	}
+	if len(unique) == 1 {
+		return unique[0], nil
+	}
+
+	graphs := make([]*definitionGraph, 0, len(unique))
+	for i, def := range unique {
+		g, err := newDefinitionGraph(def)
+		if err != nil {
+			return workflowmanager.WorkflowDefinition{}, fmt.Errorf("invalid workflow definition at index %d: %w", i, err)
+		}
+		graphs = append(graphs, g)
+	}
+
+	prefixLen := sharedPrefixLength(graphs)
+	prefixes := make([][]string, len(graphs))
+	for i, g := range graphs {
+		prefixes[i] = g.linearPrefix()[:prefixLen]
+	}
+	suffixLen := sharedSuffixLength(graphs, prefixes)
+	suffixes := make([][]string, len(graphs))
+	for i, g := range graphs {
+		suffix := g.linearSuffix(prefixes[i])
+		suffixes[i] = suffix[len(suffix)-suffixLen:]
+	}
+
+	merged := &mergedDefinition{}
+	merged.addNode(map[string]any{"id": mergedStartNodeID, "type": defNodeTypeStart})
+
+	// Shared prefix, taken from the first definition.
+	tail := mergedStartNodeID
+	for _, nodeID := range prefixes[0] {
+		id := sharedNodeID(nodeID)
+		merged.addNode(cloneWithID(graphs[0].nodeByID[nodeID], id))
+		merged.addEdge(tail, id, "")
+		tail = id
+	}
+
+	merged.addNode(map[string]any{"id": mergedSplitNodeID, "type": defNodeTypeGateway, "gateway_type": defGatewayParallelSplit})
+	merged.addEdge(tail, mergedSplitNodeID, "")
+	merged.addNode(map[string]any{"id": mergedJoinNodeID, "type": defNodeTypeGateway, "gateway_type": defGatewayParallelJoin})
+
+	for i, g := range graphs {
+		if err := merged.addBranch(i, g, prefixes[i], suffixes[i]); err != nil {
+			return workflowmanager.WorkflowDefinition{}, fmt.Errorf("failed to merge workflow definition %s: %w", g.id, err)
+		}
+	}
+
+	// Shared suffix, taken from the first definition.
+	tail = mergedJoinNodeID
+	for _, nodeID := range suffixes[0] {
+		id := sharedNodeID(nodeID)
+		merged.addNode(cloneWithID(graphs[0].nodeByID[nodeID], id))
+		merged.addEdge(tail, id, "")
+		tail = id
+	}
+	merged.addNode(map[string]any{"id": mergedEndNodeID, "type": defNodeTypeEnd})
+	merged.addEdge(tail, mergedEndNodeID, "")
+
+	ids := make([]string, 0, len(graphs))
+	for _, g := range graphs {
+		ids = append(ids, g.id)
+	}
+
+	raw, err := json.Marshal(map[string]any{
+		"id":      workflowID,
+		"name":    "Merged workflow (" + strings.Join(ids, ", ") + ")",
+		"version": 1,
+		"nodes":   merged.nodes,
+		"edges":   merged.edges,
+	})
+	if err != nil {
+		return workflowmanager.WorkflowDefinition{}, fmt.Errorf("failed to marshal merged workflow definition: %w", err)
+	}
+
+	var result workflowmanager.WorkflowDefinition
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return workflowmanager.WorkflowDefinition{}, fmt.Errorf("failed to unmarshal merged workflow definition: %w", err)
+	}
+	return result, nil
+}
+
+// newDefinitionGraph indexes a workflow definition by node ID and validates that it has exactly
+// one START and one END node.
+func newDefinitionGraph(def workflowmanager.WorkflowDefinition) (*definitionGraph, error) {
+	raw, err := json.Marshal(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workflow definition: %w", err)
+	}
+	var parsed struct {
+		ID    string           `json:"id"`
+		Nodes []map[string]any `json:"nodes"`
+		Edges []map[string]any `json:"edges"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal workflow definition: %w", err)
+	}
+
+	g := &definitionGraph{
+		id:       parsed.ID,
+		nodes:    parsed.Nodes,
+		edges:    parsed.Edges,
+		nodeByID: make(map[string]map[string]any, len(parsed.Nodes)),
+		outgoing: make(map[string][]map[string]any),
+		incoming: make(map[string][]map[string]any),
+	}
+	for _, node := range parsed.Nodes {
+		id := stringField(node, "id")
+		if id == "" {
+			return nil, fmt.Errorf("node without id")
+		}
+		g.nodeByID[id] = node
+		switch stringField(node, "type") {
+		case defNodeTypeStart:
+			if g.startID != "" {
+				return nil, fmt.Errorf("multiple START nodes")
+			}
+			g.startID = id
+		case defNodeTypeEnd:
+			if g.endID != "" {
+				return nil, fmt.Errorf("multiple END nodes")
+			}
+			g.endID = id
+		}
+	}
+	if g.startID == "" || g.endID == "" {
+		return nil, fmt.Errorf("workflow definition %s must have exactly one START and one END node", g.id)
+	}
+	for _, edge := range parsed.Edges {
+		source, target := stringField(edge, "source_id"), stringField(edge, "target_id")
+		if _, ok := g.nodeByID[source]; !ok {
+			return nil, fmt.Errorf("edge %s references unknown source node %s", stringField(edge, "id"), source)
+		}
+		if _, ok := g.nodeByID[target]; !ok {
+			return nil, fmt.Errorf("edge %s references unknown target node %s", stringField(edge, "id"), target)
+		}
+		g.outgoing[source] = append(g.outgoing[source], edge)
+		g.incoming[target] = append(g.incoming[target], edge)
+	}
+	return g, nil
+}
+
+// isLinearTask reports whether a node is a task with exactly one unconditional edge in and out.
+func (g *definitionGraph) isLinearTask(nodeID string) bool {
+	if stringField(g.nodeByID[nodeID], "type") != defNodeTypeTask {
+		return false
+	}
+	in, out := g.incoming[nodeID], g.outgoing[nodeID]
+	return len(in) == 1 && len(out) == 1 && stringField(in[0], "condition") == "" && stringField(out[0], "condition") == ""
+}
+
+// linearPrefix returns the IDs of the task nodes that form a straight chain right after START.
+func (g *definitionGraph) linearPrefix() []string {
+	return g.linearChain(g.startID, nil)
+}
+
+// linearChain returns the IDs of the task nodes that form a straight chain right after the given
+// node, stopping before any excluded node.
+func (g *definitionGraph) linearChain(from string, excluded map[string]bool) []string {
+	var chain []string
+	current := from
+	for len(g.outgoing[current]) == 1 {
+		next := stringField(g.outgoing[current][0], "target_id")
+		if excluded[next] || !g.isLinearTask(next) {
+			break
+		}
+		chain = append(chain, next)
+		current = next
+	}
+	return chain
+}
+
+// linearSuffix returns the IDs of the task nodes that form a straight chain right before END,
+// in execution order. Nodes that belong to the given prefix are never part of the suffix.
+func (g *definitionGraph) linearSuffix(prefix []string) []string {
+	excluded := make(map[string]bool, len(prefix))
+	for _, id := range prefix {
+		excluded[id] = true
+	}
+	var reversed []string
+	current := g.endID
+	for len(g.incoming[current]) == 1 {
+		prev := stringField(g.incoming[current][0], "source_id")
+		if excluded[prev] || !g.isLinearTask(prev) {
+			break
+		}
+		reversed = append(reversed, prev)
+		current = prev
+	}
+	chain := make([]string, len(reversed))
+	for i, id := range reversed {
+		chain[len(reversed)-1-i] = id
+	}
+	return chain
+}
+
+// sharedPrefixLength returns how many leading linear task nodes are equivalent across all graphs.
+func sharedPrefixLength(graphs []*definitionGraph) int {
+	chains := make([][]string, len(graphs))
+	for i, g := range graphs {
+		chains[i] = g.linearPrefix()
+	}
+	n := 0
+	for {
+		for i, chain := range chains {
+			if n >= len(chain) || !equivalentNodes(graphs[0].nodeByID[chains[0][n]], graphs[i].nodeByID[chain[n]]) {
+				return n
+			}
+		}
+		n++
+	}
+}
+
+// sharedSuffixLength returns how many trailing linear task nodes are equivalent across all graphs.
+func sharedSuffixLength(graphs []*definitionGraph, prefixes [][]string) int {
+	chains := make([][]string, len(graphs))
+	for i, g := range graphs {
+		chains[i] = g.linearSuffix(prefixes[i])
+	}
+	n := 0
+	for {
+		for i, chain := range chains {
+			if n >= len(chain) {
+				return n
+			}
+			first := chains[0][len(chains[0])-1-n]
+			if !equivalentNodes(graphs[0].nodeByID[first], graphs[i].nodeByID[chain[len(chain)-1-n]]) {
+				return n
+			}
+		}
+		n++
+	}
+}
+
+// equivalentNodes reports whether two nodes describe the same step, ignoring their IDs.
+func equivalentNodes(a, b map[string]any) bool {
+	if stringField(a, "type") != defNodeTypeTask || stringField(b, "type") != defNodeTypeTask {
+		return false
+	}
+	return reflect.DeepEqual(withoutID(a), withoutID(b))
+}
+
+// mergedDefinition accumulates the nodes and edges of the merged workflow definition.
+type mergedDefinition struct {
+	nodes []map[string]any
+	edges []map[string]any
+
+	// branchTasks are the task steps that earlier branch bodies always run, i.e. the linear chain
+	// at the start of each body.
+	branchTasks []branchTask
+	// forks are the branch tasks that already fan out to the steps of later branches waiting on them.
+	forks map[string]bool
+}
+
+// branchTask is a task step copied into a branch body.
+type branchTask struct {
+	node map[string]any // as it appears in its source definition
+	id   string         // ID in the merged definition
+}
+
+func (m *mergedDefinition) addNode(node map[string]any) {
+	m.nodes = append(m.nodes, node)
+}
+
+func (m *mergedDefinition) addEdge(source, target, condition string) {
+	m.appendEdge(fmt.Sprintf("merged_e_%s_to_%s", source, target), source, target, condition)
+}
+
+// addBranchEdge adds an edge of the branch with the given index. The index is part of the edge
+// ID, so that branches connecting the same nodes (e.g. several empty bodies) do not collide.
+func (m *mergedDefinition) addBranchEdge(index int, source, target, condition string) {
+	m.appendEdge(fmt.Sprintf("merged_e_b%d_%s_to_%s", index, source, target), source, target, condition)
+}
+
+func (m *mergedDefinition) appendEdge(id, source, target, condition string) {
+	edge := map[string]any{
+		"id":        id,
+		"source_id": source,
+		"target_id": target,
+	}
+	if condition != "" {
+		edge["condition"] = condition
+	}
+	m.edges = append(m.edges, edge)
+}
+
+// earlierInstance returns the merged ID of an equivalent task step that an earlier branch always
+// runs, or "" if there is none.
+func (m *mergedDefinition) earlierInstance(node map[string]any) string {
+	for _, task := range m.branchTasks {
+		if equivalentNodes(task.node, node) {
+			return task.id
+		}
+	}
+	return ""
+}
+
+// waitOn makes the node target of the branch with the given index wait for the task step source
+// of an earlier branch. The first time a step is waited on, its outgoing edges are moved behind a
+// PARALLEL_SPLIT so that its own branch continues alongside the waiting ones.
+func (m *mergedDefinition) waitOn(index int, source, target string) {
+	fork := source + "_fork"
+	if !m.forks[source] {
+		if m.forks == nil {
+			m.forks = make(map[string]bool)
+		}
+		m.forks[source] = true
+		for _, edge := range m.edges {
+			if stringField(edge, "source_id") == source {
+				edge["source_id"] = fork
+			}
+		}
+		m.addNode(map[string]any{"id": fork, "type": defNodeTypeGateway, "gateway_type": defGatewayParallelSplit})
+		m.addEdge(source, fork, "")
+	}
+	m.addBranchEdge(index, fork, target, "")
+}
+
+// addBranch copies the body of a graph (everything between the shared prefix and suffix) into
+// the merged definition as one parallel branch.
+//
+// A task step that an earlier branch always runs (e.g. an NPQS inspection required by several
+// HS codes) is not run again. In its place the branch gets a PARALLEL_JOIN that waits for both
+// the branch's previous step and the earlier branch's instance, so that the steps after it still
+// see its outputs. Only steps in the linear chain at the start of both bodies are shared this
+// way; a step that might not run in the earlier branch is kept in every branch that needs it.
+func (m *mergedDefinition) addBranch(index int, g *definitionGraph, prefix, suffix []string) error {
+	entryID := g.startID
+	if len(prefix) > 0 {
+		entryID = prefix[len(prefix)-1]
+	}
+	exitID := g.endID
+	if len(suffix) > 0 {
+		exitID = suffix[0]
+	}
+
+	skip := map[string]bool{g.startID: true, g.endID: true}
+	for _, id := range prefix {
+		skip[id] = true
+	}
+	for _, id := range suffix {
+		skip[id] = true
+	}
+
+	rename := func(id string) string {
+		return fmt.Sprintf("b%d_%s", index, id)
+	}
+
+	head := make(map[string]bool)
+	for _, id := range g.linearChain(entryID, skip) {
+		head[id] = true
+	}
+	var tasks []branchTask
+	for _, node := range g.nodes {
+		id := stringField(node, "id")
+		if skip[id] {
+			continue
+		}
+		if head[id] {
+			if earlier := m.earlierInstance(node); earlier != "" {
+				m.addNode(map[string]any{"id": rename(id), "type": defNodeTypeGateway, "gateway_type": defGatewayParallelJoin})
+				m.waitOn(index, earlier, rename(id))
+				continue
+			}
+			tasks = append(tasks, branchTask{node: node, id: rename(id)})
+		}
+		m.addNode(cloneWithID(node, rename(id)))
+	}
+	m.branchTasks = append(m.branchTasks, tasks...)
+
+	entryEdges := g.outgoing[entryID]
+	if len(entryEdges) != 1 {
+		return fmt.Errorf("node %s must have exactly one outgoing edge", entryID)
+	}
+	// An empty body connects the split straight to the join.
+	first := stringField(entryEdges[0], "target_id")
+	if first == exitID {
+		m.addBranchEdge(index, mergedSplitNodeID, mergedJoinNodeID, "")
+		return nil
+	}
+	m.addBranchEdge(index, mergedSplitNodeID, rename(first), stringField(entryEdges[0], "condition"))
+
+	type bodyEdge struct {
+		edge           map[string]any
+		source, target string
+	}
+	var body []bodyEdge
+	exits := 0
+	for _, edge := range g.edges {
+		source := stringField(edge, "source_id")
+		if skip[source] {
+			continue
+		}
+		target := stringField(edge, "target_id")
+		if skip[target] && target != exitID {
+			return fmt.Errorf("edge %s leaves the branch body towards %s", stringField(edge, "id"), target)
+		}
+		if target == exitID {
+			exits++
+		}
+		body = append(body, bodyEdge{edge: edge, source: source, target: target})
+	}
+
+	// A branch that reaches its exit along several alternative paths is funnelled through an
+	// exclusive join so that the parallel join sees exactly one incoming edge per branch.
+	branchExit := mergedJoinNodeID
+	if exits > 1 {
+		branchExit = rename("exclusive_join")
+		m.addNode(map[string]any{"id": branchExit, "type": defNodeTypeGateway, "gateway_type": defGatewayExclusiveJoin})
+		m.addBranchEdge(index, branchExit, mergedJoinNodeID, "")
+	}
+
+	for _, e := range body {
+		cloned := cloneWithID(e.edge, rename(stringField(e.edge, "id")))
+		cloned["source_id"] = rename(e.source)
+		if e.target == exitID {
+			cloned["target_id"] = branchExit
+		} else {
+			cloned["target_id"] = rename(e.target)
+		}
+		m.edges = append(m.edges, cloned)
+	}
+	return nil
+}
+
+// sharedNodeID namespaces a node that is shared by all merged definitions.
+func sharedNodeID(id string) string {
+	return "shared_" + id
+}
+
+func cloneWithID(obj map[string]any, id string) map[string]any {
+	cloned := make(map[string]any, len(obj))
+	for k, v := range obj {
+		cloned[k] = v
+	}
+	cloned["id"] = id
+	return cloned
+}
+
+func withoutID(obj map[string]any) map[string]any {
+	cloned := make(map[string]any, len(obj))
+	for k, v := range obj {
+		if k != "id" {
+			cloned[k] = v
+		}
+	}
+	return cloned
+}
+
+func stringField(obj map[string]any, key string) string {
+	s, _ := obj[key].(string)
+	return s
+}
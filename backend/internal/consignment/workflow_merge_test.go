@@ -0,0 +1,341 @@
+package consignment
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	workflowManagerV2 "github.com/OpenNSW/go-temporal-workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseDefinition(t *testing.T, raw string) workflowManagerV2.WorkflowDefinition {
+	t.Helper()
+	var def workflowManagerV2.WorkflowDefinition
+	require.NoError(t, json.Unmarshal([]byte(raw), &def))
+	return def
+}
+
+func definitionGraphOf(t *testing.T, def workflowManagerV2.WorkflowDefinition) *definitionGraph {
+	t.Helper()
+	g, err := newDefinitionGraph(def)
+	require.NoError(t, err)
+	return g
+}
+
+const teaExportDefinition = `{
+	"id": "tea-export",
+	"nodes": [
+		{ "id": "start", "type": "START" },
+		{ "id": "gen_info", "type": "TASK", "task_template_id": "tt-gen-info" },
+		{ "id": "payment", "type": "TASK", "task_template_id": "tt-payment" },
+		{ "id": "tea_board", "type": "TASK", "task_template_id": "tt-tea-board" },
+		{ "id": "final", "type": "TASK", "task_template_id": "tt-final" },
+		{ "id": "end", "type": "END" }
+	],
+	"edges": [
+		{ "id": "e1", "source_id": "start", "target_id": "gen_info" },
+		{ "id": "e2", "source_id": "gen_info", "target_id": "payment" },
+		{ "id": "e3", "source_id": "payment", "target_id": "tea_board" },
+		{ "id": "e4", "source_id": "tea_board", "target_id": "final" },
+		{ "id": "e5", "source_id": "final", "target_id": "end" }
+	]
+}`
+
+const coconutExportDefinition = `{
+	"id": "coconut-export",
+	"nodes": [
+		{ "id": "start", "type": "START" },
+		{ "id": "gen_info", "type": "TASK", "task_template_id": "tt-gen-info" },
+		{ "id": "payment", "type": "TASK", "task_template_id": "tt-payment" },
+		{ "id": "cda", "type": "TASK", "task_template_id": "tt-cda" },
+		{ "id": "npqs", "type": "TASK", "task_template_id": "tt-npqs" },
+		{ "id": "final", "type": "TASK", "task_template_id": "tt-final" },
+		{ "id": "end", "type": "END" }
+	],
+	"edges": [
+		{ "id": "e1", "source_id": "start", "target_id": "gen_info" },
+		{ "id": "e2", "source_id": "gen_info", "target_id": "payment" },
+		{ "id": "e3", "source_id": "payment", "target_id": "cda" },
+		{ "id": "e4", "source_id": "cda", "target_id": "npqs" },
+		{ "id": "e5", "source_id": "npqs", "target_id": "final" },
+		{ "id": "e6", "source_id": "final", "target_id": "end" }
+	]
+}`
+
+func TestMergeWorkflowDefinitions_SingleDefinitionUnchanged(t *testing.T) {
+	def := parseDefinition(t, teaExportDefinition)
+
+	merged, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{def, def})
+	require.NoError(t, err)
+	assert.Equal(t, def, merged)
+}
+
+func TestMergeWorkflowDefinitions_Empty(t *testing.T) {
+	_, err := mergeWorkflowDefinitions("consignment-1", nil)
+	assert.Error(t, err)
+}
+
+func TestMergeWorkflowDefinitions_SharesPrefixAndSuffix(t *testing.T) {
+	merged, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		parseDefinition(t, teaExportDefinition),
+		parseDefinition(t, coconutExportDefinition),
+	})
+	require.NoError(t, err)
+
+	g := definitionGraphOf(t, merged)
+	assert.Equal(t, "consignment-1", g.id)
+
+	templateCounts := make(map[string]int)
+	for _, node := range g.nodes {
+		if id := stringField(node, "task_template_id"); id != "" {
+			templateCounts[id]++
+		}
+	}
+	// Shared steps appear once, per-template steps are kept.
+	assert.Equal(t, map[string]int{
+		"tt-gen-info":  1,
+		"tt-payment":   1,
+		"tt-tea-board": 1,
+		"tt-cda":       1,
+		"tt-npqs":      1,
+		"tt-final":     1,
+	}, templateCounts)
+
+	assert.Equal(t, []string{sharedNodeID("gen_info"), sharedNodeID("payment")}, g.linearPrefix())
+	assert.Len(t, g.outgoing[mergedSplitNodeID], 2)
+	assert.Len(t, g.incoming[mergedJoinNodeID], 2)
+	assert.Equal(t, []string{sharedNodeID("final")}, g.linearSuffix(nil))
+}
+
+// assertUniqueEdgeIDs fails the test if two edges of the definition share an ID.
+func assertUniqueEdgeIDs(t *testing.T, g *definitionGraph) {
+	t.Helper()
+	seen := make(map[string]bool, len(g.edges))
+	for _, edge := range g.edges {
+		id := stringField(edge, "id")
+		assert.False(t, seen[id], "duplicate edge ID %s", id)
+		seen[id] = true
+	}
+}
+
+// sourcesOf returns the IDs of the nodes with an edge into the given node.
+func sourcesOf(g *definitionGraph, nodeID string) []string {
+	var sources []string
+	for _, edge := range g.incoming[nodeID] {
+		sources = append(sources, stringField(edge, "source_id"))
+	}
+	return sources
+}
+
+func TestMergeWorkflowDefinitions_SharedBranchStepsRunOnce(t *testing.T) {
+	teaWithNPQS := parseDefinition(t, `{
+		"id": "tea-export-npqs",
+		"nodes": [
+			{ "id": "start", "type": "START" },
+			{ "id": "gen_info", "type": "TASK", "task_template_id": "tt-gen-info" },
+			{ "id": "payment", "type": "TASK", "task_template_id": "tt-payment" },
+			{ "id": "plant_quarantine", "type": "TASK", "task_template_id": "tt-npqs" },
+			{ "id": "tea_board", "type": "TASK", "task_template_id": "tt-tea-board" },
+			{ "id": "final", "type": "TASK", "task_template_id": "tt-final" },
+			{ "id": "end", "type": "END" }
+		],
+		"edges": [
+			{ "id": "e1", "source_id": "start", "target_id": "gen_info" },
+			{ "id": "e2", "source_id": "gen_info", "target_id": "payment" },
+			{ "id": "e3", "source_id": "payment", "target_id": "plant_quarantine" },
+			{ "id": "e4", "source_id": "plant_quarantine", "target_id": "tea_board" },
+			{ "id": "e5", "source_id": "tea_board", "target_id": "final" },
+			{ "id": "e6", "source_id": "final", "target_id": "end" }
+		]
+	}`)
+
+	merged, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		parseDefinition(t, coconutExportDefinition),
+		teaWithNPQS,
+	})
+	require.NoError(t, err)
+
+	g := definitionGraphOf(t, merged)
+	templateCounts := make(map[string]int)
+	for _, node := range g.nodes {
+		if id := stringField(node, "task_template_id"); id != "" {
+			templateCounts[id]++
+		}
+	}
+	assert.Equal(t, 1, templateCounts["tt-npqs"], "NPQS should only run in the first branch")
+	assert.Equal(t, 1, templateCounts["tt-tea-board"])
+	assert.Equal(t, 1, templateCounts["tt-cda"])
+
+	// The first branch keeps its NPQS step; in the second it is replaced by a join that waits for it.
+	assert.NotNil(t, g.nodeByID["b0_npqs"])
+	assert.Equal(t, defGatewayParallelJoin, stringField(g.nodeByID["b1_plant_quarantine"], "gateway_type"))
+	assert.ElementsMatch(t, []string{mergedSplitNodeID, "b0_npqs_fork"}, sourcesOf(g, "b1_plant_quarantine"))
+	assert.Equal(t, []string{"b1_plant_quarantine"}, sourcesOf(g, "b1_tea_board"))
+	assert.Len(t, g.incoming[mergedJoinNodeID], 2)
+	assertUniqueEdgeIDs(t, g)
+
+	// Steps with the same template but a different configuration are distinct.
+	teaWithNPQS.Nodes[3].OutputMapping = map[string]string{"outcome": "npqs_outcome"}
+	merged, err = mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		parseDefinition(t, coconutExportDefinition),
+		teaWithNPQS,
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, definitionGraphOf(t, merged).nodeByID["b1_plant_quarantine"])
+}
+
+func TestMergeWorkflowDefinitions_SharedStepPrecedesSuccessorsInEveryBranch(t *testing.T) {
+	const withNPQS = `{
+		"id": "%s",
+		"nodes": [
+			{ "id": "start", "type": "START" },
+			{ "id": "info", "type": "TASK", "task_template_id": "%s" },
+			{ "id": "npqs", "type": "TASK", "task_template_id": "tt-npqs", "output_mapping": { "certificate": "npqs_certificate" } },
+			{ "id": "after", "type": "TASK", "task_template_id": "%s" },
+			{ "id": "end", "type": "END" }
+		],
+		"edges": [
+			{ "id": "e1", "source_id": "start", "target_id": "info" },
+			{ "id": "e2", "source_id": "info", "target_id": "npqs" },
+			{ "id": "e3", "source_id": "npqs", "target_id": "after" },
+			{ "id": "e4", "source_id": "after", "target_id": "end" }
+		]
+	}`
+
+	// Each definition starts with its own step, so NPQS is part of both branch bodies.
+	merged, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		parseDefinition(t, fmt.Sprintf(withNPQS, "spices", "tt-spices-info", "tt-spices-board")),
+		parseDefinition(t, fmt.Sprintf(withNPQS, "fruit", "tt-fruit-info", "tt-fruit-board")),
+	})
+	require.NoError(t, err)
+
+	g := definitionGraphOf(t, merged)
+	require.NotNil(t, g.nodeByID["b0_npqs"])
+	assert.Equal(t, defGatewayParallelJoin, stringField(g.nodeByID["b1_npqs"], "gateway_type"))
+
+	// The first branch continues after its NPQS step through the fork.
+	assert.Equal(t, []string{"b0_npqs"}, sourcesOf(g, "b0_npqs_fork"))
+	assert.Equal(t, []string{"b0_npqs_fork"}, sourcesOf(g, "b0_after"))
+	// The second branch's successor waits for both its own predecessor and the first branch's NPQS step.
+	assert.ElementsMatch(t, []string{"b1_info", "b0_npqs_fork"}, sourcesOf(g, "b1_npqs"))
+	assert.Equal(t, []string{"b1_npqs"}, sourcesOf(g, "b1_after"))
+	assert.Len(t, g.incoming[mergedJoinNodeID], 2)
+	assertUniqueEdgeIDs(t, g)
+}
+
+func TestMergeWorkflowDefinitions_ConditionalStepsAreNotShared(t *testing.T) {
+	conditionalNPQS := parseDefinition(t, `{
+		"id": "conditional-npqs",
+		"nodes": [
+			{ "id": "start", "type": "START" },
+			{ "id": "inspect", "type": "TASK", "task_template_id": "tt-inspect", "output_mapping": { "outcome": "inspect_outcome" } },
+			{ "id": "split", "type": "GATEWAY", "gateway_type": "EXCLUSIVE_SPLIT" },
+			{ "id": "npqs", "type": "TASK", "task_template_id": "tt-npqs" },
+			{ "id": "join", "type": "GATEWAY", "gateway_type": "EXCLUSIVE_JOIN" },
+			{ "id": "end", "type": "END" }
+		],
+		"edges": [
+			{ "id": "e1", "source_id": "start", "target_id": "inspect" },
+			{ "id": "e2", "source_id": "inspect", "target_id": "split" },
+			{ "id": "e3", "source_id": "split", "target_id": "npqs", "condition": "inspect_outcome == 'plants'" },
+			{ "id": "e4", "source_id": "split", "target_id": "join", "condition": "inspect_outcome != 'plants'" },
+			{ "id": "e5", "source_id": "npqs", "target_id": "join" },
+			{ "id": "e6", "source_id": "join", "target_id": "end" }
+		]
+	}`)
+
+	merged, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		conditionalNPQS,
+		parseDefinition(t, coconutExportDefinition),
+	})
+	require.NoError(t, err)
+
+	// The first branch may skip its NPQS step, so the second runs its own.
+	g := definitionGraphOf(t, merged)
+	assert.Equal(t, defNodeTypeTask, stringField(g.nodeByID["b0_npqs"], "type"))
+	assert.Equal(t, defNodeTypeTask, stringField(g.nodeByID["b1_npqs"], "type"))
+	assert.Nil(t, g.nodeByID["b0_npqs_fork"])
+}
+
+func TestMergeWorkflowDefinitions_EmptyBranchesHaveDistinctEdges(t *testing.T) {
+	const plain = `{
+		"id": "%s",
+		"nodes": [
+			{ "id": "start", "type": "START" },
+			{ "id": "gen_info", "type": "TASK", "task_template_id": "tt-gen-info" },
+			{ "id": "payment", "type": "TASK", "task_template_id": "tt-payment" },
+			{ "id": "final", "type": "TASK", "task_template_id": "tt-final" },
+			{ "id": "end", "type": "END" }
+		],
+		"edges": [
+			{ "id": "e1", "source_id": "start", "target_id": "gen_info" },
+			{ "id": "e2", "source_id": "gen_info", "target_id": "payment" },
+			{ "id": "e3", "source_id": "payment", "target_id": "final" },
+			{ "id": "e4", "source_id": "final", "target_id": "end" }
+		]
+	}`
+
+	merged, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		parseDefinition(t, teaExportDefinition),
+		parseDefinition(t, fmt.Sprintf(plain, "plain-a")),
+		parseDefinition(t, fmt.Sprintf(plain, "plain-b")),
+	})
+	require.NoError(t, err)
+
+	g := definitionGraphOf(t, merged)
+	assert.Len(t, g.outgoing[mergedSplitNodeID], 3)
+	assert.Len(t, g.incoming[mergedJoinNodeID], 3)
+	assertUniqueEdgeIDs(t, g)
+}
+
+func TestMergeWorkflowDefinitions_ExclusiveExitsAreJoined(t *testing.T) {
+	branching := parseDefinition(t, `{
+		"id": "branching",
+		"nodes": [
+			{ "id": "start", "type": "START" },
+			{ "id": "inspect", "type": "TASK", "task_template_id": "tt-inspect", "output_mapping": { "outcome": "inspect_outcome" } },
+			{ "id": "split", "type": "GATEWAY", "gateway_type": "EXCLUSIVE_SPLIT" },
+			{ "id": "manual", "type": "TASK", "task_template_id": "tt-manual" },
+			{ "id": "end", "type": "END" }
+		],
+		"edges": [
+			{ "id": "e1", "source_id": "start", "target_id": "inspect" },
+			{ "id": "e2", "source_id": "inspect", "target_id": "split" },
+			{ "id": "e3", "source_id": "split", "target_id": "manual", "condition": "inspect_outcome == 'manual'" },
+			{ "id": "e4", "source_id": "split", "target_id": "end", "condition": "inspect_outcome == 'approved'" },
+			{ "id": "e5", "source_id": "manual", "target_id": "end" }
+		]
+	}`)
+
+	merged, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		parseDefinition(t, teaExportDefinition),
+		branching,
+	})
+	require.NoError(t, err)
+
+	g := definitionGraphOf(t, merged)
+	join := g.nodeByID["b1_exclusive_join"]
+	require.NotNil(t, join)
+	assert.Equal(t, defGatewayExclusiveJoin, stringField(join, "gateway_type"))
+	assert.Len(t, g.incoming["b1_exclusive_join"], 2)
+	assert.Len(t, g.incoming[mergedJoinNodeID], 2)
+
+	// Conditions and mappings are carried over.
+	var conditions []string
+	for _, edge := range g.outgoing["b1_split"] {
+		conditions = append(conditions, stringField(edge, "condition"))
+	}
+	assert.ElementsMatch(t, []string{"inspect_outcome == 'manual'", "inspect_outcome == 'approved'"}, conditions)
+	assert.NotNil(t, g.nodeByID["b1_inspect"]["output_mapping"])
+}
+
+func TestMergeWorkflowDefinitions_InvalidDefinition(t *testing.T) {
+	_, err := mergeWorkflowDefinitions("consignment-1", []workflowManagerV2.WorkflowDefinition{
+		parseDefinition(t, teaExportDefinition),
+		parseDefinition(t, `{"id": "broken", "nodes": [{ "id": "start", "type": "START" }], "edges": []}`),
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one START and one END")
+}
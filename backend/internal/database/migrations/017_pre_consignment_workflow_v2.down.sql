@@ -0,0 +1,20 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 017_pre_consignment_workflow_v2.down.sql
+-- Purpose: Unlink pre-consignment templates from workflow_template_v2 definitions
+--          and drop the pre-consignment trader context again.
+-- ============================================================================
+
+ALTER TABLE pre_consignments
+    DROP COLUMN IF EXISTS trader_context;
+
+ALTER TABLE pre_consignment_templates
+    DROP COLUMN IF EXISTS workflow_template_v2_id;
+
+DELETE FROM workflow_template_v2
+WHERE id IN (
+    'pre-consignment-basic-details-v1',
+    'pre-consignment-general-trader-verification-v1'
+);
+
+COMMIT;
@@ -0,0 +1,97 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 017_pre_consignment_workflow_v2.up.sql
+-- Purpose: Run pre-consignment workflows on the Temporal workflow runtime by
+--          linking pre-consignment templates to workflow_template_v2 definitions.
+-- ============================================================================
+
+ALTER TABLE pre_consignment_templates
+    ADD COLUMN IF NOT EXISTS workflow_template_v2_id text
+        CONSTRAINT fk_pre_consignment_templates_workflow_template_v2
+            REFERENCES workflow_template_v2
+                ON UPDATE CASCADE ON DELETE RESTRICT;
+
+COMMENT ON COLUMN pre_consignment_templates.workflow_template_v2_id IS 'Workflow definition started on the Temporal runtime when a trader initiates this pre-consignment';
+
+-- 010_workflow_table moved the trader context onto workflows. Workflows started on the Temporal
+-- runtime have no workflows row, so the pre-consignment keeps its own trader context again.
+ALTER TABLE pre_consignments
+    ADD COLUMN IF NOT EXISTS trader_context jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN pre_consignments.trader_context IS 'JSONB context specific to the trader, accumulated during workflow execution';
+
+-- Task outputs are mapped one-to-one into workflow variables so that the final
+-- workflow context carries the submitted trader details.
+INSERT INTO workflow_template_v2 (id, name, version, workflow_definition)
+VALUES
+    (
+        'pre-consignment-basic-details-v1',
+        'Basic Details Workflow',
+        '1',
+        '{
+            "id": "pre-consignment-basic-details-v1",
+            "name": "Basic Details Workflow",
+            "version": 1,
+            "nodes": [
+                { "id": "start", "type": "START" },
+                {
+                    "id": "node_1:basic_details",
+                    "type": "TASK",
+                    "task_template_id": "d0000002-0001-0001-0001-000000000004",
+                    "output_mapping": {
+                        "bi:email": "bi:email",
+                        "bi:phoneNumber": "bi:phoneNumber",
+                        "bi:businessName": "bi:businessName",
+                        "bi:businessType": "bi:businessType",
+                        "bi:businessAddress": "bi:businessAddress"
+                    }
+                },
+                { "id": "end", "type": "END" }
+            ],
+            "edges": [
+                { "id": "e1", "source_id": "start", "target_id": "node_1:basic_details" },
+                { "id": "e2", "source_id": "node_1:basic_details", "target_id": "end" }
+            ]
+        }'::jsonb
+    ),
+    (
+        'pre-consignment-general-trader-verification-v1',
+        'General Trader Verification Workflow',
+        '1',
+        '{
+            "id": "pre-consignment-general-trader-verification-v1",
+            "name": "General Trader Verification Workflow",
+            "version": 1,
+            "nodes": [
+                { "id": "start", "type": "START" },
+                {
+                    "id": "node_1:general_trader_verification",
+                    "type": "TASK",
+                    "task_template_id": "d0000002-0001-0001-0001-000000000005",
+                    "output_mapping": {
+                        "br:tinNumber": "br:tinNumber",
+                        "br:vatNumber": "br:vatNumber",
+                        "br:tinCertificate": "br:tinCertificate",
+                        "br:vatCertificate": "br:vatCertificate",
+                        "br:registrationNumber": "br:registrationNumber"
+                    }
+                },
+                { "id": "end", "type": "END" }
+            ],
+            "edges": [
+                { "id": "e1", "source_id": "start", "target_id": "node_1:general_trader_verification" },
+                { "id": "e2", "source_id": "node_1:general_trader_verification", "target_id": "end" }
+            ]
+        }'::jsonb
+    )
+ON CONFLICT (id) DO NOTHING;
+
+UPDATE pre_consignment_templates
+SET workflow_template_v2_id = 'pre-consignment-basic-details-v1'
+WHERE id = '0c000004-0001-0001-0001-000000000001';
+
+UPDATE pre_consignment_templates
+SET workflow_template_v2_id = 'pre-consignment-general-trader-verification-v1'
+WHERE id = '0c000004-0001-0001-0001-000000000002';
+
+COMMIT;
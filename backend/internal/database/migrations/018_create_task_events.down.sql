@@ -0,0 +1,12 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 018_create_task_events.down.sql
+-- Purpose: Drop the task audit trail.
+-- ============================================================================
+
+DROP TRIGGER IF EXISTS task_events_append_only ON task_events;
+DROP FUNCTION IF EXISTS task_events_reject_mutation();
+DROP INDEX IF EXISTS idx_task_events_task_id_created_at;
+DROP TABLE IF EXISTS task_events;
+
+COMMIT;
@@ -0,0 +1,38 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 018_create_task_events.up.sql
+-- Purpose: Append-only audit trail of task FSM transitions.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS task_events (
+    id                varchar(100)             NOT NULL PRIMARY KEY,
+    task_id           text                     NOT NULL,
+    workflow_id       text                     NOT NULL,
+    action            varchar(100)             NOT NULL,
+    from_plugin_state varchar(100)             NOT NULL DEFAULT '',
+    to_plugin_state   varchar(100)             NOT NULL,
+    actor_type        varchar(20)              NOT NULL
+        CONSTRAINT task_events_actor_type_check
+            CHECK ((actor_type)::text = ANY ((ARRAY['USER'::character varying, 'CLIENT'::character varying, 'SYSTEM'::character varying])::text[])),
+    actor_id          varchar(255)             NOT NULL DEFAULT '',
+    payload_hash      varchar(64)              NOT NULL DEFAULT '',
+    created_at        timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_events_task_id_created_at ON task_events (task_id, created_at);
+
+COMMENT ON TABLE task_events IS 'Append-only audit trail of task FSM transitions and the principal that caused them';
+COMMENT ON COLUMN task_events.payload_hash IS 'Hex-encoded SHA-256 of the execution request that caused the transition';
+
+-- Reject updates and deletes so the trail stays append-only.
+CREATE OR REPLACE FUNCTION task_events_reject_mutation() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'task_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER task_events_append_only
+    BEFORE UPDATE OR DELETE ON task_events
+    FOR EACH ROW EXECUTE FUNCTION task_events_reject_mutation();
+
+COMMIT;
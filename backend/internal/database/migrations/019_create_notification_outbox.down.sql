@@ -0,0 +1,11 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 019_create_notification_outbox.down.sql
+-- Purpose: Drop the notification outbox.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_notification_outbox_workflow_id;
+DROP INDEX IF EXISTS idx_notification_outbox_due;
+DROP TABLE IF EXISTS notification_outbox;
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 019_create_notification_outbox.up.sql
+-- Purpose: Durable outbox of notifications awaiting delivery, one row per recipient.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id              varchar(100)             NOT NULL PRIMARY KEY,
+    idempotency_key varchar(512)             NOT NULL,
+    event           varchar(50)              NOT NULL,
+    workflow_id     text                     NOT NULL DEFAULT '',
+    channel         varchar(20)              NOT NULL
+        CONSTRAINT notification_outbox_channel_check
+            CHECK ((channel)::text = ANY ((ARRAY['EMAIL'::character varying, 'SMS'::character varying])::text[])),
+    recipient       varchar(255)             NOT NULL,
+    template_id     varchar(100)             NOT NULL,
+    template_data   jsonb                    NOT NULL DEFAULT '{}'::jsonb,
+    status          varchar(20)              NOT NULL DEFAULT 'PENDING'
+        CONSTRAINT notification_outbox_status_check
+            CHECK ((status)::text = ANY ((ARRAY['PENDING'::character varying, 'SENT'::character varying, 'DEAD_LETTER'::character varying])::text[])),
+    attempts        integer                  NOT NULL DEFAULT 0,
+    last_error      text                     NOT NULL DEFAULT '',
+    next_attempt_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at         timestamp with time zone,
+    created_at      timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_notification_outbox_idempotency_key UNIQUE (idempotency_key)
+);
+
+-- The dispatcher polls for due pending messages; delivered and dead-lettered rows are not indexed.
+CREATE INDEX idx_notification_outbox_due ON notification_outbox (next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_notification_outbox_workflow_id ON notification_outbox (workflow_id, created_at);
+
+COMMENT ON TABLE notification_outbox IS 'Notifications awaiting or after delivery; retried with exponential backoff and dead-lettered after the maximum attempts';
+COMMENT ON COLUMN notification_outbox.idempotency_key IS 'Derived from the triggering event, template, channel and recipient so that an event notifies each recipient once';
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 020_add_payment_provider_id.down.sql
+-- Purpose: Drop the payment provider column.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_payment_tx_provider_id;
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS provider_id;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 020_add_payment_provider_id.up.sql
+-- Purpose: Record which payment provider a transaction was created with, so
+--          webhooks can be matched against the provider that sent them.
+-- ============================================================================
+
+ALTER TABLE payment_transactions
+    ADD COLUMN IF NOT EXISTS provider_id varchar(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_payment_tx_provider_id ON payment_transactions (provider_id);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 021_add_payment_task_synced_status.down.sql
+-- Purpose: Drop the reported task outcome column.
+-- ============================================================================
+
+ALTER TABLE payment_transactions DROP COLUMN IF EXISTS task_synced_status;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 021_add_payment_task_synced_status.up.sql
+-- Purpose: Track which gateway outcome has been reported to the PAYMENT task,
+--          so webhook redeliveries advance the task exactly once.
+-- ============================================================================
+
+ALTER TABLE payment_transactions
+    ADD COLUMN IF NOT EXISTS task_synced_status varchar(50) NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 022_create_payment_settlements.down.sql
+-- Purpose: Drop gateway settlement records.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_payment_tx_pending_expiry;
+DROP INDEX IF EXISTS idx_payment_settlements_reference_number;
+DROP INDEX IF EXISTS idx_payment_settlements_settled_at;
+DROP TABLE IF EXISTS payment_settlements;
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 022_create_payment_settlements.up.sql
+-- Purpose: Gateway settlement records and the result of matching each one
+--          against payment_transactions by reference number.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS payment_settlements (
+    id                      varchar(100)             NOT NULL PRIMARY KEY,
+    batch_id                varchar(100)             NOT NULL,
+    provider_id             varchar(100)             NOT NULL,
+    reference_number        varchar(255)             NOT NULL,
+    gateway_transaction_id  varchar(255)             NOT NULL DEFAULT '',
+    amount                  numeric(15, 2)           NOT NULL,
+    currency                varchar(10)              NOT NULL,
+    settled_at              timestamp with time zone NOT NULL,
+    match_status            varchar(50)              NOT NULL
+        CHECK (match_status IN ('MATCHED', 'AMOUNT_MISMATCH', 'STATUS_MISMATCH', 'PROVIDER_MISMATCH', 'UNKNOWN_REFERENCE')),
+    expected_amount         numeric(15, 2),
+    expected_currency       varchar(10),
+    transaction_status      varchar(50),
+    task_id                 varchar(255),
+    org_id                  varchar(100),
+    created_at              timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_payment_settlements_gateway_tx UNIQUE (provider_id, reference_number, gateway_transaction_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_settlements_settled_at ON payment_settlements (settled_at);
+CREATE INDEX IF NOT EXISTS idx_payment_settlements_reference_number ON payment_settlements (reference_number);
+
+-- Lets the expiry sweep find stale pending payments without a full scan.
+CREATE INDEX IF NOT EXISTS idx_payment_tx_pending_expiry ON payment_transactions (expiry_date) WHERE status = 'PENDING';
+
+COMMIT;
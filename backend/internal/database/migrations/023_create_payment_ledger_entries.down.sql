@@ -0,0 +1,10 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 023_create_payment_ledger_entries.down.sql
+-- Purpose: Drop the payment ledger.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_payment_ledger_entries_task_id;
+DROP TABLE IF EXISTS payment_ledger_entries;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 023_create_payment_ledger_entries.up.sql
+-- Purpose: Append-only ledger of payments received and refunds returned, from
+--          which the net amount held for each task is derived.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS payment_ledger_entries (
+    id                varchar(100)             NOT NULL PRIMARY KEY,
+    transaction_id    text                     NOT NULL REFERENCES payment_transactions (id),
+    task_id           varchar(255)             NOT NULL,
+    reference_number  varchar(255)             NOT NULL,
+    entry_type        varchar(20)              NOT NULL CHECK (entry_type IN ('PAYMENT', 'REFUND')),
+    amount            numeric(15, 2)           NOT NULL CHECK (amount > 0),
+    currency          varchar(10)              NOT NULL,
+    external_id       varchar(255)             NOT NULL,
+    created_at        timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_payment_ledger_entries_external UNIQUE (transaction_id, entry_type, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_ledger_entries_task_id ON payment_ledger_entries (task_id);
+
+COMMIT;
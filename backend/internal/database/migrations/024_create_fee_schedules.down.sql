@@ -0,0 +1,12 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 024_create_fee_schedules.down.sql
+-- Purpose: Drop fee schedules, exchange rates and fee resolutions.
+-- ============================================================================
+
+DROP TABLE IF EXISTS fee_resolutions;
+DROP TABLE IF EXISTS exchange_rates;
+DROP INDEX IF EXISTS idx_fee_schedules_effective_from;
+DROP TABLE IF EXISTS fee_schedules;
+
+COMMIT;
@@ -0,0 +1,46 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 024_create_fee_schedules.up.sql
+-- Purpose: Versioned fee schedules, exchange rates to LKR by effective date,
+--          and a record of every fee breakdown resolved from them.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS fee_schedules (
+    id              varchar(100)             NOT NULL,
+    version         integer                  NOT NULL CHECK (version > 0),
+    name            varchar(255)             NOT NULL,
+    currency        varchar(10)              NOT NULL,
+    rules           jsonb                    NOT NULL,
+    effective_from  timestamp with time zone NOT NULL,
+    created_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_schedules_effective_from ON fee_schedules (id, effective_from);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency        varchar(10)              NOT NULL,
+    effective_date  date                     NOT NULL,
+    rate            numeric(20, 8)           NOT NULL CHECK (rate > 0),
+    created_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (currency, effective_date)
+);
+
+CREATE TABLE IF NOT EXISTS fee_resolutions (
+    id                varchar(100)             NOT NULL PRIMARY KEY,
+    task_id           varchar(255)             NOT NULL,
+    schedule_id       varchar(100)             NOT NULL,
+    schedule_version  integer                  NOT NULL,
+    currency          varchar(10)              NOT NULL,
+    inputs            jsonb                    NOT NULL,
+    rates             jsonb                    NOT NULL,
+    lines             jsonb                    NOT NULL,
+    total             numeric(15, 2)           NOT NULL,
+    fingerprint       varchar(64)              NOT NULL,
+    resolved_at       timestamp with time zone NOT NULL,
+    created_at        timestamp with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_fee_resolutions_schedule FOREIGN KEY (schedule_id, schedule_version) REFERENCES fee_schedules (id, version),
+    CONSTRAINT uq_fee_resolutions_fingerprint UNIQUE (task_id, fingerprint)
+);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 025_create_issued_documents.down.sql
+-- Purpose: Drop issued documents.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_issued_documents_task_id;
+DROP INDEX IF EXISTS idx_issued_documents_verification_code;
+DROP TABLE IF EXISTS issued_documents;
+
+COMMIT;
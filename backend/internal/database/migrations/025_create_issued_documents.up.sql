@@ -0,0 +1,25 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 025_create_issued_documents.up.sql
+-- Purpose: Receipts, certificates and other PDFs generated by workflows, with
+--          the verification code and hashes printed on or taken from them.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS issued_documents (
+    id                 varchar(100)             NOT NULL PRIMARY KEY,
+    verification_code  varchar(20)              NOT NULL,
+    document_type      varchar(50)              NOT NULL,
+    title              varchar(255)             NOT NULL,
+    task_id            varchar(255)             NOT NULL,
+    workflow_id        varchar(255)             NOT NULL,
+    storage_key        varchar(255)             NOT NULL,
+    content_hash       varchar(64)              NOT NULL,
+    file_hash          varchar(64)              NOT NULL,
+    issued_at          timestamp with time zone NOT NULL,
+    created_at         timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issued_documents_verification_code ON issued_documents (verification_code);
+CREATE INDEX IF NOT EXISTS idx_issued_documents_task_id ON issued_documents (task_id);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 026_add_document_verification.down.sql
+-- Purpose: Drop the registry columns of issued documents.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_issued_documents_consignment_id;
+
+ALTER TABLE issued_documents
+    DROP COLUMN IF EXISTS revocation_reason,
+    DROP COLUMN IF EXISTS revoked_by,
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS valid_until,
+    DROP COLUMN IF EXISTS issuing_oga,
+    DROP COLUMN IF EXISTS consignment_id;
+
+COMMIT;
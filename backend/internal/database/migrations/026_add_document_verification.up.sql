@@ -0,0 +1,21 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 026_add_document_verification.up.sql
+-- Purpose: Make issued documents a verification registry: link each to its
+--          consignment and issuing OGA, and record validity and revocation.
+-- ============================================================================
+
+ALTER TABLE issued_documents
+    ADD COLUMN IF NOT EXISTS consignment_id     varchar(255)             NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS issuing_oga        varchar(100)             NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS valid_until        timestamp with time zone,
+    ADD COLUMN IF NOT EXISTS revoked_at         timestamp with time zone,
+    ADD COLUMN IF NOT EXISTS revoked_by         varchar(255),
+    ADD COLUMN IF NOT EXISTS revocation_reason  text;
+
+-- Documents issued before this migration belong to consignment workflows, whose ID is the consignment ID.
+UPDATE issued_documents SET consignment_id = workflow_id WHERE consignment_id = '';
+
+CREATE INDEX IF NOT EXISTS idx_issued_documents_consignment_id ON issued_documents (consignment_id);
+
+COMMIT;
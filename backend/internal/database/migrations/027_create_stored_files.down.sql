@@ -0,0 +1,11 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 027_create_stored_files.down.sql
+-- Purpose: Drop stored file records.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_stored_files_consignment_id;
+DROP INDEX IF EXISTS idx_stored_files_task_id;
+DROP TABLE IF EXISTS stored_files;
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 027_create_stored_files.up.sql
+-- Purpose: Record who stored each file, the task or consignment it belongs to,
+--          its checksum and retention class, so access can be authorized.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS stored_files (
+    key              varchar(255)             NOT NULL PRIMARY KEY,
+    uploaded_by      varchar(255)             NOT NULL,
+    task_id          varchar(255)             NOT NULL DEFAULT '',
+    consignment_id   varchar(255)             NOT NULL DEFAULT '',
+    filename         text                     NOT NULL,
+    size             bigint                   NOT NULL,
+    mime_type        varchar(255)             NOT NULL,
+    checksum         varchar(64)              NOT NULL DEFAULT '',
+    retention_class  varchar(50)              NOT NULL,
+    status           varchar(20)              NOT NULL DEFAULT '',
+    created_at       timestamp with time zone NOT NULL DEFAULT NOW(),
+    updated_at       timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stored_files_task_id ON stored_files (task_id);
+CREATE INDEX IF NOT EXISTS idx_stored_files_consignment_id ON stored_files (consignment_id);
+
+-- Documents issued so far were stored by the system; their size was not recorded.
+INSERT INTO stored_files (key, uploaded_by, task_id, consignment_id, filename, size, mime_type,
+                          checksum, retention_class, status, created_at, updated_at)
+SELECT storage_key, 'system', task_id, consignment_id,
+       lower(document_type) || '-' || verification_code || '.pdf', 0, 'application/pdf',
+       file_hash, 'ISSUED', 'CLEAN', created_at, created_at
+FROM issued_documents
+ON CONFLICT (key) DO NOTHING;
+
+COMMIT;
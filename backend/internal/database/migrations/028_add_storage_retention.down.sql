@@ -0,0 +1,19 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 028_add_storage_retention.down.sql
+-- Purpose: Drop the purge log and legal holds of stored files.
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_storage_purges_purged_at;
+DROP TABLE IF EXISTS storage_purges;
+
+DROP INDEX IF EXISTS idx_stored_files_quarantined;
+DROP INDEX IF EXISTS idx_stored_files_retention;
+
+ALTER TABLE stored_files
+    DROP COLUMN IF EXISTS legal_hold_at,
+    DROP COLUMN IF EXISTS legal_hold_by,
+    DROP COLUMN IF EXISTS legal_hold_reason,
+    DROP COLUMN IF EXISTS legal_hold;
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 028_add_storage_retention.up.sql
+-- Purpose: Add legal holds to stored files and log the files purged by the
+--          retention sweeper.
+-- ============================================================================
+
+ALTER TABLE stored_files
+    ADD COLUMN IF NOT EXISTS legal_hold        boolean                  NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS legal_hold_reason text                     NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS legal_hold_by     varchar(255)             NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS legal_hold_at     timestamp with time zone;
+
+-- The sweeper looks files up by class and age, and quarantined uploads by age.
+CREATE INDEX IF NOT EXISTS idx_stored_files_retention ON stored_files (retention_class, created_at) WHERE NOT legal_hold;
+CREATE INDEX IF NOT EXISTS idx_stored_files_quarantined ON stored_files (created_at) WHERE status = 'QUARANTINED' AND NOT legal_hold;
+
+CREATE TABLE IF NOT EXISTS storage_purges (
+    id               uuid                     NOT NULL PRIMARY KEY,
+    key              varchar(255)             NOT NULL,
+    reason           varchar(20)              NOT NULL,
+    retention_class  varchar(50)              NOT NULL DEFAULT '',
+    uploaded_by      varchar(255)             NOT NULL DEFAULT '',
+    task_id          varchar(255)             NOT NULL DEFAULT '',
+    consignment_id   varchar(255)             NOT NULL DEFAULT '',
+    filename         text                     NOT NULL DEFAULT '',
+    size             bigint                   NOT NULL DEFAULT 0,
+    checksum         varchar(64)              NOT NULL DEFAULT '',
+    stored_at        timestamp with time zone NOT NULL,
+    purged_at        timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_purges_purged_at ON storage_purges (purged_at);
+
+COMMIT;
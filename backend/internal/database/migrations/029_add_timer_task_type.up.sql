@@ -0,0 +1,12 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 029_add_timer_task_type.up.sql
+-- Purpose: Allow TIMER tasks, which wait for a duration or a deadline.
+-- ============================================================================
+
+ALTER TABLE task_infos DROP CONSTRAINT IF EXISTS task_infos_type_check;
+ALTER TABLE task_infos
+    ADD CONSTRAINT task_infos_type_check
+        CHECK ((type)::text = ANY ((ARRAY['SIMPLE_FORM'::character varying, 'WAIT_FOR_EVENT'::character varying, 'PAYMENT'::character varying, 'TIMER'::character varying])::text[]));
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 030_add_multi_approval_task_type.up.sql
+-- Purpose: Allow MULTI_APPROVAL tasks, which collect votes from several approvers.
+-- ============================================================================
+
+ALTER TABLE task_infos DROP CONSTRAINT IF EXISTS task_infos_type_check;
+ALTER TABLE task_infos
+    ADD CONSTRAINT task_infos_type_check
+        CHECK ((type)::text = ANY ((ARRAY['SIMPLE_FORM'::character varying, 'WAIT_FOR_EVENT'::character varying, 'PAYMENT'::character varying, 'TIMER'::character varying, 'MULTI_APPROVAL'::character varying])::text[]));
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+-- ============================================================================
+-- Migration: 031_add_document_task_type.down.sql
+-- Purpose: Disallow DOCUMENT tasks again. Existing rows are not re-validated.
+-- ============================================================================
+
+ALTER TABLE task_infos DROP CONSTRAINT IF EXISTS task_infos_type_check;
+ALTER TABLE task_infos
+    ADD CONSTRAINT task_infos_type_check
+        CHECK ((type)::text = ANY ((ARRAY['SIMPLE_FORM'::character varying, 'WAIT_FOR_EVENT'::character varying, 'PAYMENT'::character varying, 'TIMER'::character varying, 'MULTI_APPROVAL'::character varying])::text[])) NOT VALID;
+
+COMMIT;
@@ -11,6 +11,21 @@ MIGRATION_DB_HOST="${MIGRATION_DB_HOST:-$DB_HOST}"
 MIGRATION_DB_HOST="${MIGRATION_DB_HOST//host.docker.internal/localhost}"
 
 DOWNS=(
+  "031_add_document_task_type.down.sql"
+  "030_add_multi_approval_task_type.down.sql"
+  "029_add_timer_task_type.down.sql"
+  "028_add_storage_retention.down.sql"
+  "027_create_stored_files.down.sql"
+  "026_add_document_verification.down.sql"
+  "025_create_issued_documents.down.sql"
+  "024_create_fee_schedules.down.sql"
+  "023_create_payment_ledger_entries.down.sql"
+  "022_create_payment_settlements.down.sql"
+  "021_add_payment_task_synced_status.down.sql"
+  "020_add_payment_provider_id.down.sql"
+  "019_create_notification_outbox.down.sql"
+  "018_create_task_events.down.sql"
+  "017_pre_consignment_workflow_v2.down.sql"
   "016_create_company_records.down.sql"
   "015_fcau_workflow_seed.down.sql"
   "014_fcau_workflow_nodes_seed.down.sql"
@@ -61,6 +61,21 @@ MIGRATIONS=(
     "014_fcau_workflow_nodes_seed.up.sql"
     "015_fcau_workflow_seed.up.sql"
     "016_create_company_records.up.sql"
+    "017_pre_consignment_workflow_v2.up.sql"
+    "018_create_task_events.up.sql"
+    "019_create_notification_outbox.up.sql"
+    "020_add_payment_provider_id.up.sql"
+    "021_add_payment_task_synced_status.up.sql"
+    "022_create_payment_settlements.up.sql"
+    "023_create_payment_ledger_entries.up.sql"
+    "024_create_fee_schedules.up.sql"
+    "025_create_issued_documents.up.sql"
+    "026_add_document_verification.up.sql"
+    "027_create_stored_files.up.sql"
+    "028_add_storage_retention.up.sql"
+    "029_add_timer_task_type.up.sql"
+    "030_add_multi_approval_task_type.up.sql"
+    "031_add_document_task_type.up.sql"
 )
 
 echo "Starting database migrations..."
@@ -0,0 +1,228 @@
+package database_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+	"github.com/OpenNSW/nsw/internal/workflow/model"
+)
+
+const migrationsDir = "migrations"
+
+// migrationFiles returns the up migrations in the order run.sh applies them.
+func migrationFiles(t *testing.T) []string {
+	t.Helper()
+	script, err := os.ReadFile(filepath.Join(migrationsDir, "run.sh"))
+	if err != nil {
+		t.Fatalf("failed to read run.sh: %v", err)
+	}
+	files := regexp.MustCompile(`"(\d{3}_\w+\.up\.sql)"`).FindAllStringSubmatch(string(script), -1)
+	if len(files) == 0 {
+		t.Fatal("run.sh lists no migrations")
+	}
+	names := make([]string, 0, len(files))
+	for _, match := range files {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// sqlStatements splits a migration into statements, dropping comments, string literals and
+// dollar-quoted function bodies so that only the DDL structure is left.
+func sqlStatements(sql string) []string {
+	var statements []string
+	var current strings.Builder
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+			}
+		case sql[i] == '\'':
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			current.WriteString("''")
+		case strings.HasPrefix(sql[i:], "$$"):
+			end := strings.Index(sql[i+2:], "$$")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+		case sql[i] == ';':
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(sql[i])
+		}
+	}
+	return append(statements, strings.TrimSpace(current.String()))
+}
+
+// splitTopLevel splits s on commas that are not nested in parentheses.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\((.*)\)`)
+	dropTablePattern   = regexp.MustCompile(`(?is)^DROP TABLE (?:IF EXISTS )?(\w+)`)
+	alterTablePattern  = regexp.MustCompile(`(?is)^ALTER TABLE (?:IF EXISTS )?(?:ONLY )?(\w+)\s+(.*)`)
+	addColumnPattern   = regexp.MustCompile(`(?is)^ADD (?:COLUMN )?(?:IF NOT EXISTS )?(\w+)`)
+	dropColumnPattern  = regexp.MustCompile(`(?is)^DROP (?:COLUMN )?(?:IF EXISTS )?(\w+)`)
+	renameColumn       = regexp.MustCompile(`(?is)^RENAME (?:COLUMN )?(\w+) TO (\w+)`)
+	tableConstraint    = regexp.MustCompile(`(?i)^(CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK|EXCLUDE)\b`)
+)
+
+// replayMigrations applies the column changes of every up migration in order and returns the
+// resulting columns of each table.
+func replayMigrations(t *testing.T) map[string]map[string]bool {
+	t.Helper()
+	tables := make(map[string]map[string]bool)
+	for _, name := range migrationFiles(t) {
+		sql, err := os.ReadFile(filepath.Join(migrationsDir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		for _, statement := range sqlStatements(string(sql)) {
+			statement = strings.Join(strings.Fields(statement), " ")
+			if m := createTablePattern.FindStringSubmatch(statement); m != nil {
+				if tables[m[1]] != nil {
+					continue // IF NOT EXISTS
+				}
+				columns := make(map[string]bool)
+				for _, definition := range splitTopLevel(m[2]) {
+					definition = strings.TrimSpace(definition)
+					if definition == "" || tableConstraint.MatchString(definition) {
+						continue
+					}
+					columns[strings.Fields(definition)[0]] = true
+				}
+				tables[m[1]] = columns
+				continue
+			}
+			if m := dropTablePattern.FindStringSubmatch(statement); m != nil {
+				delete(tables, m[1])
+				continue
+			}
+			m := alterTablePattern.FindStringSubmatch(statement)
+			if m == nil || tables[m[1]] == nil {
+				continue
+			}
+			for _, action := range splitTopLevel(m[2]) {
+				action = strings.TrimSpace(action)
+				switch {
+				case strings.HasPrefix(strings.ToUpper(action), "ADD CONSTRAINT"),
+					strings.HasPrefix(strings.ToUpper(action), "DROP CONSTRAINT"):
+				case addColumnPattern.MatchString(action):
+					tables[m[1]][addColumnPattern.FindStringSubmatch(action)[1]] = true
+				case dropColumnPattern.MatchString(action):
+					delete(tables[m[1]], dropColumnPattern.FindStringSubmatch(action)[1])
+				case renameColumn.MatchString(action):
+					names := renameColumn.FindStringSubmatch(action)
+					delete(tables[m[1]], names[1])
+					tables[m[1]][names[2]] = true
+				}
+			}
+		}
+	}
+	return tables
+}
+
+// TestMigrations_CoverModelColumns guards against models mapping columns that the migrations
+// drop or never create, which sqlmock-based tests cannot catch.
+func TestMigrations_CoverModelColumns(t *testing.T) {
+	tables := replayMigrations(t)
+
+	for _, m := range []any{
+		&model.PreConsignment{},
+		&model.PreConsignmentTemplate{},
+	} {
+		s, err := schema.Parse(m, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatalf("failed to parse %T: %v", m, err)
+		}
+		t.Run(s.Table, func(t *testing.T) {
+			columns, ok := tables[s.Table]
+			if !ok {
+				t.Fatalf("no migration creates table %s", s.Table)
+			}
+			for _, column := range s.DBNames {
+				if !columns[column] {
+					t.Errorf("%s.%s is mapped by %s but missing after the migrations (have %v)", s.Table, column, s.Name, slices.Sorted(maps.Keys(columns)))
+				}
+			}
+		})
+	}
+}
+
+var (
+	commentPattern   = regexp.MustCompile(`--[^\n]*`)
+	typeCheckPattern = regexp.MustCompile(`(?s)DROP CONSTRAINT (?:IF EXISTS )?task_infos_type_check|CONSTRAINT task_infos_type_check\s+CHECK \(\(type\)::text = ANY \(\(ARRAY\[(.*?)\]`)
+	quotedPattern    = regexp.MustCompile(`'(\w+)'`)
+)
+
+// TestMigrations_TaskTypeCheckCoversPlugins guards against a plugin type that task_infos rejects,
+// and against the check being dropped rather than extended.
+func TestMigrations_TaskTypeCheckCoversPlugins(t *testing.T) {
+	var allowed []string
+	for _, name := range migrationFiles(t) {
+		sql, err := os.ReadFile(filepath.Join(migrationsDir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		for _, m := range typeCheckPattern.FindAllStringSubmatch(commentPattern.ReplaceAllString(string(sql), ""), -1) {
+			allowed = nil
+			for _, value := range quotedPattern.FindAllStringSubmatch(m[1], -1) {
+				allowed = append(allowed, value[1])
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		t.Fatal("no task_infos_type_check after the migrations")
+	}
+
+	registry, err := plugin.NewDefaultRegistry()
+	if err != nil {
+		t.Fatalf("failed to build the plugin registry: %v", err)
+	}
+	for _, taskType := range registry.Types() {
+		if !slices.Contains(allowed, string(taskType)) {
+			t.Errorf("task_infos_type_check does not allow plugin type %s (allows %v)", taskType, allowed)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Package admin exposes the registry of issued documents to administrators over HTTP. It is
+// kept apart from package document, which the task plugins depend on, because authz depends
+// on them.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/document"
+)
+
+// RoleAuthorizer decides whether the caller holds one of a set of roles; satisfied by authz.Policy.
+type RoleAuthorizer interface {
+	AuthorizeRole(ctx context.Context, roles ...string) error
+}
+
+// RevocationRequest is the body of a document revocation.
+type RevocationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// HTTPHandler exposes the registry of issued documents to administrators.
+type HTTPHandler struct {
+	service    *document.Service
+	authorizer RoleAuthorizer
+}
+
+// NewHTTPHandler creates a new HTTPHandler.
+func NewHTTPHandler(service *document.Service, authorizer RoleAuthorizer) *HTTPHandler {
+	return &HTTPHandler{service: service, authorizer: authorizer}
+}
+
+// HandleListDocuments returns the documents issued for a consignment or a task.
+// GET /api/v1/admin/documents?consignmentId=...&taskId=...
+func (h *HTTPHandler) HandleListDocuments(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	filter := document.Filter{
+		ConsignmentID: r.URL.Query().Get("consignmentId"),
+		TaskID:        r.URL.Query().Get("taskId"),
+	}
+	if filter.ConsignmentID == "" && filter.TaskID == "" {
+		http.Error(w, "consignmentId or taskId query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	docs, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list issued documents", "error", err)
+		http.Error(w, "failed to list issued documents", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, docs)
+}
+
+// HandleRevokeDocument revokes an issued document, for example one issued in error or
+// superseded by an amended certificate.
+// POST /api/v1/admin/documents/{documentId}/revocation
+func (h *HTTPHandler) HandleRevokeDocument(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	documentID := r.PathValue("documentId")
+	if documentID == "" {
+		http.Error(w, "document ID is required in URL", http.StatusBadRequest)
+		return
+	}
+	var req RevocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+
+	var revokedBy string
+	if authCtx := auth.GetAuthContext(r.Context()); authCtx != nil && authCtx.User != nil {
+		revokedBy = authCtx.User.ID
+	}
+	doc, err := h.service.Revoke(r.Context(), documentID, revokedBy, req.Reason)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, doc)
+	case errors.Is(err, document.ErrDocumentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, document.ErrAlreadyRevoked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		slog.ErrorContext(r.Context(), "failed to revoke document", "documentID", documentID, "error", err)
+		http.Error(w, "failed to revoke document", http.StatusInternalServerError)
+	}
+}
+
+// authorize admits administrators, writing the error response otherwise.
+func (h *HTTPHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	err := h.authorizer.AuthorizeRole(r.Context(), authz.RoleAdmin)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, authz.ErrUnauthenticated):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, authz.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		slog.ErrorContext(r.Context(), "failed to authorize document registry access", "error", err)
+		http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+	}
+}
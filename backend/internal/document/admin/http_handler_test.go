@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/document"
+)
+
+// stubAuthorizer returns a fixed authorization result; the zero value allows everything.
+type stubAuthorizer struct {
+	err error
+}
+
+func (a stubAuthorizer) AuthorizeRole(context.Context, ...string) error {
+	return a.err
+}
+
+// stubStore implements the parts of document.Store the tests use.
+type stubStore struct {
+	document.Store
+	docs map[string]document.IssuedDocument
+}
+
+func (s *stubStore) Get(_ context.Context, id string) (*document.IssuedDocument, error) {
+	d, ok := s.docs[id]
+	if !ok {
+		return nil, document.ErrDocumentNotFound
+	}
+	return &d, nil
+}
+
+func (s *stubStore) Revoke(_ context.Context, id string, at time.Time, by, reason string) (bool, error) {
+	d := s.docs[id]
+	if d.RevokedAt != nil {
+		return false, nil
+	}
+	d.RevokedAt, d.RevokedBy, d.RevocationReason = &at, by, reason
+	s.docs[id] = d
+	return true, nil
+}
+
+func TestHTTPHandler_HandleRevokeDocument(t *testing.T) {
+	revokedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	newHandler := func(authorizer RoleAuthorizer) *HTTPHandler {
+		store := &stubStore{docs: map[string]document.IssuedDocument{
+			"doc-1": {ID: "doc-1"},
+			"doc-2": {ID: "doc-2", RevokedAt: &revokedAt},
+		}}
+		return NewHTTPHandler(document.NewService(store, nil, ""), authorizer)
+	}
+	newRequest := func(id, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/documents/"+id+"/revocation", strings.NewReader(body))
+		req.SetPathValue("documentId", id)
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		authorizer RoleAuthorizer
+		id, body   string
+		want       int
+	}{
+		{"revokes", stubAuthorizer{}, "doc-1", `{"reason":"Issued in error"}`, http.StatusOK},
+		{"requires a reason", stubAuthorizer{}, "doc-1", `{"reason":" "}`, http.StatusBadRequest},
+		{"already revoked", stubAuthorizer{}, "doc-2", `{"reason":"Issued in error"}`, http.StatusConflict},
+		{"unknown document", stubAuthorizer{}, "missing", `{"reason":"Issued in error"}`, http.StatusNotFound},
+		{"requires the admin role", stubAuthorizer{err: authz.ErrForbidden}, "doc-1", `{"reason":"Issued in error"}`, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newHandler(tt.authorizer).HandleRevokeDocument(rec, newRequest(tt.id, tt.body))
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestHTTPHandler_HandleListDocuments(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHTTPHandler(document.NewService(&stubStore{}, nil, ""), stubAuthorizer{}).
+		HandleListDocuments(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/documents", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,43 @@
+package document
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// HTTPHandler serves the public verification of issued documents.
+type HTTPHandler struct {
+	service *Service
+}
+
+// NewHTTPHandler creates a new HTTPHandler.
+func NewHTTPHandler(service *Service) *HTTPHandler {
+	return &HTTPHandler{service: service}
+}
+
+// HandleVerify returns the attestation for the verification code printed on a document. It is
+// unauthenticated, so that customs administrations and banks abroad can use it, and answers
+// only whether NSW issued the document and whether it still stands.
+// GET /api/v1/verify/{code}
+func (h *HTTPHandler) HandleVerify(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	attestation, err := h.service.Verify(r.Context(), r.PathValue("code"))
+	if err != nil {
+		if errors.Is(err, ErrDocumentNotFound) {
+			http.Error(w, "no document was issued with this verification code", http.StatusNotFound)
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to verify document", "error", err)
+		http.Error(w, "failed to verify document", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(attestation); err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+	}
+}
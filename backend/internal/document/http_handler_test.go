@@ -0,0 +1,35 @@
+package document
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler_HandleVerify(t *testing.T) {
+	store := &memoryStore{docs: []IssuedDocument{{
+		ID: "doc-1", VerificationCode: "ABCD-EFGH-JKMN", DocumentType: "CERTIFICATE", Title: "Certificate for Ceylon Tea Ltd",
+		ConsignmentID: "con-1", TaskID: "task-1", IssuingOGA: "NPQS", StorageKey: "doc.pdf", IssuedAt: time.Now(),
+	}}}
+	handler := NewHTTPHandler(NewService(store, &memoryFiles{}, ""))
+	verify := func(code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/verify/"+code, nil)
+		req.SetPathValue("code", code)
+		rec := httptest.NewRecorder()
+		handler.HandleVerify(rec, req)
+		return rec
+	}
+
+	rec := verify("ABCD-EFGH-JKMN")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"status":"VALID"`)
+	for _, private := range []string{"Ceylon Tea", "con-1", "task-1", "doc.pdf"} {
+		assert.NotContains(t, rec.Body.String(), private)
+	}
+
+	assert.Equal(t, http.StatusNotFound, verify("ABCD-EFGH-JKMP").Code)
+}
@@ -0,0 +1,87 @@
+package document
+
+import "time"
+
+// Status is the verification status of an issued document.
+type Status string
+
+const (
+	StatusValid   Status = "VALID"
+	StatusExpired Status = "EXPIRED"
+	StatusRevoked Status = "REVOKED"
+)
+
+// Field is a labelled value printed on a document, such as "Consignment" and its reference.
+type Field struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Content is what a document says. Issuing renders it to a PDF.
+type Content struct {
+	DocumentType string   `json:"documentType"` // For example RECEIPT or CERTIFICATE
+	Title        string   `json:"title"`
+	Subtitle     string   `json:"subtitle,omitempty"`
+	Fields       []Field  `json:"fields,omitempty"`
+	Paragraphs   []string `json:"paragraphs,omitempty"`
+}
+
+// IssuedDocument is the registry entry of a generated document: what it is, who issued it for
+// which consignment and task, how long it is valid, and how to verify it.
+type IssuedDocument struct {
+	ID               string     `gorm:"type:varchar(100);primaryKey" json:"id"`
+	VerificationCode string     `gorm:"type:varchar(20);not null" json:"verificationCode"` // Printed on the document
+	DocumentType     string     `gorm:"type:varchar(50);not null" json:"documentType"`
+	Title            string     `gorm:"type:varchar(255);not null" json:"title"`
+	ConsignmentID    string     `gorm:"type:varchar(255);not null" json:"consignmentId"`
+	TaskID           string     `gorm:"type:varchar(255);not null" json:"taskId"`
+	WorkflowID       string     `gorm:"type:varchar(255);not null" json:"workflowId"`
+	IssuingOGA       string     `gorm:"column:issuing_oga;type:varchar(100);not null" json:"issuingOga"`
+	StorageKey       string     `gorm:"type:varchar(255);not null" json:"storageKey"`
+	ContentHash      string     `gorm:"type:varchar(64);not null" json:"contentHash"` // SHA-256 of the content, printed on the document
+	FileHash         string     `gorm:"type:varchar(64);not null" json:"fileHash"`    // SHA-256 of the PDF file
+	IssuedAt         time.Time  `gorm:"not null" json:"issuedAt"`
+	ValidUntil       *time.Time `json:"validUntil,omitempty"` // Nil if the document does not expire
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy        string     `gorm:"type:varchar(255)" json:"revokedBy,omitempty"`
+	RevocationReason string     `gorm:"type:text" json:"revocationReason,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// TableName returns the table name for IssuedDocument.
+func (IssuedDocument) TableName() string {
+	return "issued_documents"
+}
+
+// StatusAt returns the document's status at the given time.
+func (d *IssuedDocument) StatusAt(at time.Time) Status {
+	switch {
+	case d.RevokedAt != nil:
+		return StatusRevoked
+	case d.ValidUntil != nil && !at.Before(*d.ValidUntil):
+		return StatusExpired
+	default:
+		return StatusValid
+	}
+}
+
+// Attestation is what anyone holding a verification code may learn about a document. It
+// confirms that NSW issued the document and whether it still stands, and carries the content
+// hash to compare with the one printed on the copy presented. It deliberately leaves out the
+// title, the consignment and anything else that could identify the trader.
+type Attestation struct {
+	VerificationCode string     `json:"verificationCode"`
+	Status           Status     `json:"status"`
+	DocumentType     string     `json:"documentType"`
+	IssuingOGA       string     `json:"issuingOga"`
+	IssuedAt         time.Time  `json:"issuedAt"`
+	ValidUntil       *time.Time `json:"validUntil,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	ContentHash      string     `json:"contentHash"`
+}
+
+// Filter selects registry entries; at least one field must be set.
+type Filter struct {
+	ConsignmentID string
+	TaskID        string
+}
@@ -0,0 +1,246 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Page geometry in points: A4 with a uniform margin.
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	pageMargin = 56.0
+	labelWidth = 160.0
+	columnGap  = 10.0
+)
+
+// Fonts: the standard Helvetica faces, which every PDF reader provides.
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// helveticaWidths holds the Helvetica glyph widths, in thousandths of an em, of the printable
+// ASCII characters from space (32) to tilde (126). They are used to wrap text.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space - /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 - ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ - O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P - _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` - o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p - ~
+}
+
+// pdfDocument is the printable form of a document.
+type pdfDocument struct {
+	Title      string
+	Subtitle   string
+	Fields     []Field
+	Paragraphs []string
+	Footer     []string // Printed at the foot of every page
+}
+
+// pdfLayout lays text out top to bottom, starting a new page when the current one is full.
+type pdfLayout struct {
+	footer []string
+	pages  []*bytes.Buffer
+	page   *bytes.Buffer
+	y      float64 // Baseline of the next line
+}
+
+// renderPDF renders doc as a single-column PDF 1.4 file. The output depends only on doc.
+func renderPDF(doc pdfDocument) []byte {
+	l := &pdfLayout{footer: doc.Footer}
+	l.newPage()
+
+	l.writeWrapped(fontBold, 18, pageMargin, pageWidth-2*pageMargin, 22, doc.Title)
+	if doc.Subtitle != "" {
+		l.writeWrapped(fontRegular, 12, pageMargin, pageWidth-2*pageMargin, 16, doc.Subtitle)
+	}
+	l.y -= 6
+	l.rule(l.y + 8)
+	l.y -= 14
+
+	valueX := pageMargin + labelWidth + columnGap
+	valueWidth := pageWidth - pageMargin - valueX
+	for _, f := range doc.Fields {
+		labelLines := wrapText(f.Label, fontBold, 10, labelWidth)
+		valueLines := wrapText(f.Value, fontRegular, 10, valueWidth)
+		rows := max(len(labelLines), len(valueLines))
+		l.ensure(float64(rows) * 14)
+		top := l.y
+		for i, line := range labelLines {
+			l.text(fontBold, 10, pageMargin, top-float64(i)*14, line)
+		}
+		for i, line := range valueLines {
+			l.text(fontRegular, 10, valueX, top-float64(i)*14, line)
+		}
+		l.y = top - float64(rows)*14 - 4
+	}
+
+	if len(doc.Paragraphs) > 0 {
+		l.y -= 10
+	}
+	for _, p := range doc.Paragraphs {
+		l.writeWrapped(fontRegular, 10, pageMargin, pageWidth-2*pageMargin, 14, p)
+		l.y -= 8
+	}
+
+	return l.assemble(doc.Title)
+}
+
+// newPage starts a page and draws the footer on it.
+func (l *pdfLayout) newPage() {
+	l.page = &bytes.Buffer{}
+	l.pages = append(l.pages, l.page)
+	l.y = pageHeight - pageMargin
+
+	y := pageMargin + float64(len(l.footer)-1)*11
+	for _, line := range l.footer {
+		l.text(fontRegular, 8, pageMargin, y, line)
+		y -= 11
+	}
+	if len(l.footer) > 0 {
+		l.rule(pageMargin + float64(len(l.footer))*11 + 2)
+	}
+}
+
+// ensure starts a new page unless height fits above the footer.
+func (l *pdfLayout) ensure(height float64) {
+	bottom := pageMargin + float64(len(l.footer))*11 + 16
+	if l.y-height < bottom {
+		l.newPage()
+	}
+}
+
+// writeWrapped writes s wrapped to width, one line every leading points.
+func (l *pdfLayout) writeWrapped(font string, size, x, width, leading float64, s string) {
+	for _, line := range wrapText(s, font, size, width) {
+		l.ensure(leading)
+		l.text(font, size, x, l.y, line)
+		l.y -= leading
+	}
+}
+
+func (l *pdfLayout) text(font string, size, x, y float64, s string) {
+	fmt.Fprintf(l.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(y), escapePDFString(s))
+}
+
+// rule draws a thin horizontal line across the text column.
+func (l *pdfLayout) rule(y float64) {
+	fmt.Fprintf(l.page, "0.5 w %s %s m %s %s l S\n", num(pageMargin), num(y), num(pageWidth-pageMargin), num(y))
+}
+
+// assemble writes the PDF file: catalog, page tree, fonts, info and one page and content
+// stream per page, followed by the cross-reference table.
+func (l *pdfLayout) assemble(title string) []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	const firstPage = 6 // Objects 1-5 are fixed; each page adds a page and a content object
+	kids := make([]string, len(l.pages))
+	for i := range l.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(l.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (National Single Window) >>", escapePDFString(title)))
+	for i, page := range l.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			num(pageWidth), num(pageHeight), fontRegular, fontBold, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// wrapText breaks s into lines no wider than width, at spaces where possible and within
+// words that are too long for a line. Newlines in s are kept.
+func wrapText(s, font string, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, font, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for textWidth(word, font, size) > width {
+				cut := len(word) - 1
+				for cut > 1 && textWidth(word[:cut], font, size) > width {
+					cut--
+				}
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth approximates the width of s in points. Bold glyphs are taken to be 8% wider.
+func textWidth(s, font string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += helveticaWidths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	w := float64(total) * size / 1000
+	if font == fontBold {
+		w *= 1.08
+	}
+	return w
+}
+
+// escapePDFString encodes s as the body of a PDF literal string in WinAnsi. Characters
+// outside Latin-1 are replaced with '?'.
+func escapePDFString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 32 || r > 255:
+			b.WriteByte('?')
+		case r > 126:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
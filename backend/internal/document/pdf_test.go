@@ -0,0 +1,73 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPDF(t *testing.T) {
+	doc := pdfDocument{
+		Title:      "Certificate of Origin (Form A)",
+		Fields:     []Field{{Label: "Consignment", Value: "CON-001"}, {Label: "Exporter", Value: "Café Ceylon"}},
+		Paragraphs: []string{"Issued under section 4\\2."},
+		Footer:     []string{"Verification code: ABCD-EFGH-JKMN"},
+	}
+	pdf := renderPDF(doc)
+
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), `(Certificate of Origin \(Form A\)) Tj`)
+	assert.Contains(t, string(pdf), `(Caf\351 Ceylon) Tj`)
+	assert.Contains(t, string(pdf), `(Issued under section 4\\2.) Tj`)
+	assert.Contains(t, string(pdf), "(Verification code: ABCD-EFGH-JKMN) Tj")
+	assert.Equal(t, pdf, renderPDF(doc), "rendering is deterministic")
+
+	t.Run("cross-reference offsets point at their objects", func(t *testing.T) {
+		s := string(pdf)
+		start := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(s)
+		require.Len(t, start, 2)
+		xref, err := strconv.Atoi(start[1])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(s[xref:], "xref\n"))
+
+		entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(s[xref:], -1)
+		require.Len(t, entries, 7)
+		for i, e := range entries {
+			offset, _ := strconv.Atoi(e[1])
+			assert.True(t, strings.HasPrefix(s[offset:], fmt.Sprintf("%d 0 obj\n", i+1)), "object %d", i+1)
+		}
+	})
+
+	t.Run("long content flows onto further pages, each with the footer", func(t *testing.T) {
+		long := doc
+		long.Paragraphs = nil
+		for range 80 {
+			long.Paragraphs = append(long.Paragraphs, "A paragraph long enough to wrap across more than one line of the text column on the page.")
+		}
+		s := string(renderPDF(long))
+		count := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(s)
+		require.Len(t, count, 2)
+		pages, _ := strconv.Atoi(count[1])
+		assert.Greater(t, pages, 1)
+		assert.Equal(t, pages, strings.Count(s, "(Verification code: ABCD-EFGH-JKMN) Tj"))
+	})
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("the quick brown fox jumps over the lazy dog", fontRegular, 10, 100)
+	assert.Equal(t, []string{"the quick brown fox", "jumps over the lazy", "dog"}, lines)
+
+	hash := strings.Repeat("a", 64)
+	lines = wrapText(hash, fontRegular, 10, 100)
+	assert.Greater(t, len(lines), 1)
+	assert.Equal(t, hash, strings.Join(lines, ""))
+
+	assert.Equal(t, []string{"one", "two"}, wrapText("one\ntwo", fontRegular, 10, 100))
+}
@@ -0,0 +1,251 @@
+package document
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+var (
+	ErrInvalidContent   = errors.New("invalid document content")
+	ErrDocumentNotFound = errors.New("document not found")
+	ErrAlreadyRevoked   = errors.New("document already revoked")
+)
+
+// Issuer issues documents; the DOCUMENT plugin depends on it.
+type Issuer interface {
+	// Issue renders req.Content to a PDF, stores it and records it as issued.
+	Issue(ctx context.Context, req IssueRequest) (*IssuedDocument, error)
+}
+
+// IssueRequest asks for a document to be issued for a task.
+type IssueRequest struct {
+	ConsignmentID string
+	TaskID        string
+	WorkflowID    string
+	IssuingOGA    string
+	ValidFor      time.Duration // Zero if the document does not expire
+	Content       Content
+}
+
+// FileStore stores generated files. storage.Service implements it.
+type FileStore interface {
+	Save(ctx context.Context, filename string, content []byte, mime string, link storage.FileLink) (*storage.FileMetadata, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Service issues documents.
+type Service struct {
+	store     Store
+	files     FileStore
+	verifyURL string // Base URL where a verification code can be checked; printed when set
+	now       func() time.Time
+}
+
+// NewService creates a Service. verifyURL may be empty.
+func NewService(store Store, files FileStore, verifyURL string) *Service {
+	return &Service{store: store, files: files, verifyURL: strings.TrimSuffix(verifyURL, "/"), now: time.Now}
+}
+
+// Issue renders req.Content to a PDF carrying a verification code and a hash of the content,
+// stores the file and records the document.
+func (s *Service) Issue(ctx context.Context, req IssueRequest) (*IssuedDocument, error) {
+	if req.Content.DocumentType == "" || req.Content.Title == "" {
+		return nil, fmt.Errorf("%w: documentType and title are required", ErrInvalidContent)
+	}
+	code, err := newVerificationCode()
+	if err != nil {
+		return nil, err
+	}
+	issuedAt := s.now().UTC().Truncate(time.Second)
+	var validUntil *time.Time
+	if req.ValidFor > 0 {
+		until := issuedAt.Add(req.ValidFor)
+		validUntil = &until
+	}
+	contentHash, err := hashContent(req.Content, code, req.IssuingOGA, issuedAt, validUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	footer := []string{fmt.Sprintf("Verification code: %s    Issued: %s", code, issuedAt.Format("2006-01-02 15:04 MST"))}
+	if req.IssuingOGA != "" {
+		footer[0] += " by " + req.IssuingOGA
+	}
+	if validUntil != nil {
+		footer = append(footer, "Valid until: "+validUntil.Format("2006-01-02 15:04 MST"))
+	}
+	footer = append(footer, "Content hash (SHA-256): "+contentHash)
+	if s.verifyURL != "" {
+		footer = append(footer, "Verify this document at "+s.verifyURL+"/"+code)
+	}
+	pdf := renderPDF(pdfDocument{
+		Title:      req.Content.Title,
+		Subtitle:   req.Content.Subtitle,
+		Fields:     req.Content.Fields,
+		Paragraphs: req.Content.Paragraphs,
+		Footer:     footer,
+	})
+	fileHash := sha256.Sum256(pdf)
+
+	filename := fmt.Sprintf("%s-%s.pdf", strings.ToLower(req.Content.DocumentType), code)
+	file, err := s.files.Save(ctx, filename, pdf, "application/pdf",
+		storage.FileLink{TaskID: req.TaskID, ConsignmentID: req.ConsignmentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	doc := &IssuedDocument{
+		ID:               uuid.NewString(),
+		VerificationCode: code,
+		DocumentType:     req.Content.DocumentType,
+		Title:            req.Content.Title,
+		ConsignmentID:    req.ConsignmentID,
+		TaskID:           req.TaskID,
+		WorkflowID:       req.WorkflowID,
+		IssuingOGA:       req.IssuingOGA,
+		StorageKey:       file.Key,
+		ContentHash:      contentHash,
+		FileHash:         hex.EncodeToString(fileHash[:]),
+		IssuedAt:         issuedAt,
+		ValidUntil:       validUntil,
+	}
+	if err := s.store.Create(ctx, doc); err != nil {
+		// An unrecorded file cannot be verified, so do not leave it behind.
+		if delErr := s.files.Delete(ctx, file.Key); delErr != nil {
+			slog.WarnContext(ctx, "failed to delete unrecorded document", "key", file.Key, "error", delErr)
+		}
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "document issued",
+		"documentID", doc.ID,
+		"documentType", doc.DocumentType,
+		"taskID", doc.TaskID,
+		"storageKey", doc.StorageKey)
+	return doc, nil
+}
+
+// Verify returns the attestation for a verification code as typed by a person: letters may be
+// in either case, and the dashes may be left out.
+func (s *Service) Verify(ctx context.Context, code string) (*Attestation, error) {
+	normalized, ok := normalizeVerificationCode(code)
+	if !ok {
+		return nil, ErrDocumentNotFound
+	}
+	doc, err := s.store.GetByVerificationCode(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	return &Attestation{
+		VerificationCode: doc.VerificationCode,
+		Status:           doc.StatusAt(s.now()),
+		DocumentType:     doc.DocumentType,
+		IssuingOGA:       doc.IssuingOGA,
+		IssuedAt:         doc.IssuedAt,
+		ValidUntil:       doc.ValidUntil,
+		RevokedAt:        doc.RevokedAt,
+		ContentHash:      doc.ContentHash,
+	}, nil
+}
+
+// List returns the registry entries matching filter, oldest first.
+func (s *Service) List(ctx context.Context, filter Filter) ([]IssuedDocument, error) {
+	if filter.ConsignmentID == "" && filter.TaskID == "" {
+		return nil, fmt.Errorf("a consignment or task is required")
+	}
+	return s.store.List(ctx, filter)
+}
+
+// Revoke withdraws an issued document; verifying it reports it revoked from then on.
+func (s *Service) Revoke(ctx context.Context, id, by, reason string) (*IssuedDocument, error) {
+	if _, err := s.store.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	revoked, err := s.store.Revoke(ctx, id, s.now().UTC(), by, reason)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyRevoked, id)
+	}
+	doc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "document revoked",
+		"documentID", doc.ID,
+		"documentType", doc.DocumentType,
+		"revokedBy", by)
+	return doc, nil
+}
+
+// hashContent returns the SHA-256 of the document as issued. Anyone holding the content and
+// the details printed with it can recompute it.
+func hashContent(content Content, code, issuingOGA string, issuedAt time.Time, validUntil *time.Time) (string, error) {
+	payload, err := json.Marshal(struct {
+		Content          Content    `json:"content"`
+		VerificationCode string     `json:"verificationCode"`
+		IssuingOGA       string     `json:"issuingOga,omitempty"`
+		IssuedAt         time.Time  `json:"issuedAt"`
+		ValidUntil       *time.Time `json:"validUntil,omitempty"`
+	}{content, code, issuingOGA, issuedAt, validUntil})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode document content: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// verificationAlphabet is Crockford's base32, which leaves out letters easily misread as digits.
+const verificationAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newVerificationCode returns a random code of the form XXXX-XXXX-XXXX (60 bits).
+func newVerificationCode() (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	var b strings.Builder
+	for i, v := range raw {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(verificationAlphabet[v&31])
+	}
+	return b.String(), nil
+}
+
+// normalizeVerificationCode returns code in the issued XXXX-XXXX-XXXX form, reading the
+// letters Crockford's base32 leaves out as the digits they are mistaken for.
+func normalizeVerificationCode(code string) (string, bool) {
+	var raw []byte
+	for _, r := range strings.ToUpper(code) {
+		switch {
+		case r == '-' || r == ' ':
+			continue
+		case r == 'O':
+			r = '0'
+		case r == 'I' || r == 'L':
+			r = '1'
+		case !strings.ContainsRune(verificationAlphabet, r):
+			return "", false
+		}
+		raw = append(raw, byte(r))
+	}
+	if len(raw) != 12 {
+		return "", false
+	}
+	return string(raw[0:4]) + "-" + string(raw[4:8]) + "-" + string(raw[8:12]), true
+}
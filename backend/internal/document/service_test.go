@@ -0,0 +1,182 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+type memoryFiles struct {
+	saved   map[string][]byte
+	deleted []string
+}
+
+func (m *memoryFiles) Save(_ context.Context, filename string, content []byte, mime string, _ storage.FileLink) (*storage.FileMetadata, error) {
+	if m.saved == nil {
+		m.saved = map[string][]byte{}
+	}
+	m.saved[filename] = content
+	return &storage.FileMetadata{Key: filename, Name: filename, MimeType: mime, Size: int64(len(content))}, nil
+}
+
+func (m *memoryFiles) Delete(_ context.Context, key string) error {
+	m.deleted = append(m.deleted, key)
+	return nil
+}
+
+type memoryStore struct {
+	docs []IssuedDocument
+	err  error
+}
+
+func (m *memoryStore) Create(_ context.Context, doc *IssuedDocument) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.docs = append(m.docs, *doc)
+	return nil
+}
+
+func (m *memoryStore) Get(_ context.Context, id string) (*IssuedDocument, error) {
+	for _, d := range m.docs {
+		if d.ID == id {
+			return &d, nil
+		}
+	}
+	return nil, ErrDocumentNotFound
+}
+
+func (m *memoryStore) GetByVerificationCode(_ context.Context, code string) (*IssuedDocument, error) {
+	for _, d := range m.docs {
+		if d.VerificationCode == code {
+			return &d, nil
+		}
+	}
+	return nil, ErrDocumentNotFound
+}
+
+func (m *memoryStore) List(_ context.Context, filter Filter) ([]IssuedDocument, error) {
+	var found []IssuedDocument
+	for _, d := range m.docs {
+		if (filter.ConsignmentID == "" || d.ConsignmentID == filter.ConsignmentID) && (filter.TaskID == "" || d.TaskID == filter.TaskID) {
+			found = append(found, d)
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryStore) Revoke(_ context.Context, id string, at time.Time, by, reason string) (bool, error) {
+	for i, d := range m.docs {
+		if d.ID == id && d.RevokedAt == nil {
+			m.docs[i].RevokedAt, m.docs[i].RevokedBy, m.docs[i].RevocationReason = &at, by, reason
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestService_Issue(t *testing.T) {
+	content := Content{
+		DocumentType: "CERTIFICATE",
+		Title:        "Phytosanitary Certificate",
+		Fields:       []Field{{Label: "Consignment", Value: "CON-001"}},
+	}
+	req := IssueRequest{ConsignmentID: "con-1", TaskID: "task-1", WorkflowID: "wf-1", IssuingOGA: "NPQS", ValidFor: 90 * 24 * time.Hour, Content: content}
+	issuedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	t.Run("stores and records the document", func(t *testing.T) {
+		files, store := &memoryFiles{}, &memoryStore{}
+		svc := NewService(store, files, "https://nsw.example/verify/")
+		svc.now = func() time.Time { return issuedAt }
+
+		doc, err := svc.Issue(context.Background(), req)
+		require.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{4}$`), doc.VerificationCode)
+		assert.Equal(t, "certificate-"+doc.VerificationCode+".pdf", doc.StorageKey)
+		assert.Equal(t, issuedAt, doc.IssuedAt)
+		assert.Equal(t, issuedAt.AddDate(0, 0, 90), *doc.ValidUntil)
+		assert.Equal(t, "con-1", doc.ConsignmentID)
+		require.Len(t, store.docs, 1)
+
+		want, err := hashContent(content, doc.VerificationCode, "NPQS", issuedAt, doc.ValidUntil)
+		require.NoError(t, err)
+		assert.Equal(t, want, doc.ContentHash)
+		pdf := string(files.saved[doc.StorageKey])
+		assert.Contains(t, pdf, "(Content hash \\(SHA-256\\): "+doc.ContentHash+") Tj")
+		assert.Contains(t, pdf, "by NPQS) Tj")
+		assert.Contains(t, pdf, "(Valid until: 2027-01-14 09:00 UTC) Tj")
+		assert.Contains(t, pdf, "(Verify this document at https://nsw.example/verify/"+doc.VerificationCode+") Tj")
+	})
+
+	t.Run("rejects content without a title", func(t *testing.T) {
+		svc := NewService(&memoryStore{}, &memoryFiles{}, "")
+		_, err := svc.Issue(context.Background(), IssueRequest{Content: Content{DocumentType: "RECEIPT"}})
+		assert.ErrorIs(t, err, ErrInvalidContent)
+	})
+
+	t.Run("removes the file when the record cannot be stored", func(t *testing.T) {
+		files := &memoryFiles{}
+		svc := NewService(&memoryStore{err: errors.New("db down")}, files, "")
+		_, err := svc.Issue(context.Background(), req)
+		require.Error(t, err)
+		assert.Len(t, files.deleted, 1)
+	})
+}
+
+func TestService_Verify(t *testing.T) {
+	issuedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	validUntil := issuedAt.AddDate(0, 0, 90)
+	store := &memoryStore{docs: []IssuedDocument{{
+		ID: "doc-1", VerificationCode: "ABCD-EFGH-JK10", DocumentType: "CERTIFICATE", Title: "Phytosanitary Certificate for Ceylon Tea Ltd",
+		ConsignmentID: "con-1", IssuingOGA: "NPQS", ContentHash: "abc", IssuedAt: issuedAt, ValidUntil: &validUntil,
+	}}}
+	svc := NewService(store, &memoryFiles{}, "")
+	svc.now = func() time.Time { return issuedAt.AddDate(0, 0, 1) }
+
+	t.Run("attests a valid document", func(t *testing.T) {
+		attestation, err := svc.Verify(context.Background(), "abcd efgh jklo")
+		require.NoError(t, err)
+		assert.Equal(t, &Attestation{
+			VerificationCode: "ABCD-EFGH-JK10", Status: StatusValid, DocumentType: "CERTIFICATE", IssuingOGA: "NPQS",
+			IssuedAt: issuedAt, ValidUntil: &validUntil, ContentHash: "abc",
+		}, attestation)
+	})
+
+	t.Run("reports an expired document", func(t *testing.T) {
+		expired := NewService(store, &memoryFiles{}, "")
+		expired.now = func() time.Time { return validUntil }
+		attestation, err := expired.Verify(context.Background(), "ABCD-EFGH-JK10")
+		require.NoError(t, err)
+		assert.Equal(t, StatusExpired, attestation.Status)
+	})
+
+	t.Run("unknown or malformed codes are not found", func(t *testing.T) {
+		for _, code := range []string{"ABCD-EFGH-JK11", "ABCD-EFGH", "ABCD-EFGH-JKU0", ""} {
+			_, err := svc.Verify(context.Background(), code)
+			assert.ErrorIs(t, err, ErrDocumentNotFound, code)
+		}
+	})
+
+	t.Run("reports a revoked document", func(t *testing.T) {
+		doc, err := svc.Revoke(context.Background(), "doc-1", "admin-1", "Issued in error")
+		require.NoError(t, err)
+		assert.Equal(t, "admin-1", doc.RevokedBy)
+
+		attestation, err := svc.Verify(context.Background(), "ABCD-EFGH-JK10")
+		require.NoError(t, err)
+		assert.Equal(t, StatusRevoked, attestation.Status)
+		assert.NotNil(t, attestation.RevokedAt)
+
+		_, err = svc.Revoke(context.Background(), "doc-1", "admin-1", "Again")
+		assert.ErrorIs(t, err, ErrAlreadyRevoked)
+		_, err = svc.Revoke(context.Background(), "missing", "admin-1", "Issued in error")
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+	})
+}
@@ -0,0 +1,94 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Store persists the registry of issued documents.
+type Store interface {
+	// Create records an issued document.
+	Create(ctx context.Context, doc *IssuedDocument) error
+	// Get returns an issued document by ID.
+	Get(ctx context.Context, id string) (*IssuedDocument, error)
+	// GetByVerificationCode returns the issued document with the given verification code.
+	GetByVerificationCode(ctx context.Context, code string) (*IssuedDocument, error)
+	// List returns the issued documents matching filter, oldest first.
+	List(ctx context.Context, filter Filter) ([]IssuedDocument, error)
+	// Revoke marks a document revoked unless it already is, and reports whether it did.
+	Revoke(ctx context.Context, id string, at time.Time, by, reason string) (bool, error)
+}
+
+// GormStore implements Store with GORM.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates a GormStore.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection cannot be nil")
+	}
+	return &GormStore{db: db}, nil
+}
+
+// Create inserts doc.
+func (s *GormStore) Create(ctx context.Context, doc *IssuedDocument) error {
+	if err := s.db.WithContext(ctx).Create(doc).Error; err != nil {
+		return fmt.Errorf("failed to record issued document: %w", err)
+	}
+	return nil
+}
+
+// Get returns an issued document by ID.
+func (s *GormStore) Get(ctx context.Context, id string) (*IssuedDocument, error) {
+	return s.first(ctx, "id = ?", id)
+}
+
+// GetByVerificationCode returns the issued document with the given verification code.
+func (s *GormStore) GetByVerificationCode(ctx context.Context, code string) (*IssuedDocument, error) {
+	return s.first(ctx, "verification_code = ?", code)
+}
+
+func (s *GormStore) first(ctx context.Context, query string, arg any) (*IssuedDocument, error) {
+	var doc IssuedDocument
+	err := s.db.WithContext(ctx).Where(query, arg).First(&doc).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issued document: %w", err)
+	}
+	return &doc, nil
+}
+
+// List returns the issued documents matching filter, oldest first.
+func (s *GormStore) List(ctx context.Context, filter Filter) ([]IssuedDocument, error) {
+	query := s.db.WithContext(ctx)
+	if filter.ConsignmentID != "" {
+		query = query.Where("consignment_id = ?", filter.ConsignmentID)
+	}
+	if filter.TaskID != "" {
+		query = query.Where("task_id = ?", filter.TaskID)
+	}
+	var docs []IssuedDocument
+	if err := query.Order("issued_at").Find(&docs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list issued documents: %w", err)
+	}
+	return docs, nil
+}
+
+// Revoke sets the revocation of a document that is not yet revoked.
+func (s *GormStore) Revoke(ctx context.Context, id string, at time.Time, by, reason string) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&IssuedDocument{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"revoked_at": at, "revoked_by": by, "revocation_reason": reason})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke issued document: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
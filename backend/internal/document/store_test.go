@@ -0,0 +1,81 @@
+package document
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupTestStore(t *testing.T) (*GormStore, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+	store, err := NewGormStore(gormDB)
+	require.NoError(t, err)
+	return store, mock
+}
+
+func TestGormStore_Create(t *testing.T) {
+	store, mock := setupTestStore(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "issued_documents"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.Create(context.Background(), &IssuedDocument{
+		ID: "doc-1", VerificationCode: "ABCD-EFGH-JKMN", DocumentType: "RECEIPT", Title: "Payment Receipt",
+		TaskID: "task-1", WorkflowID: "wf-1", StorageKey: "receipt.pdf", IssuedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = NewGormStore(nil)
+	assert.Error(t, err)
+}
+
+func TestGormStore_GetByVerificationCode(t *testing.T) {
+	store, mock := setupTestStore(t)
+	query := regexp.QuoteMeta(`SELECT * FROM "issued_documents" WHERE verification_code = $1`)
+
+	mock.ExpectQuery(query).WithArgs("ABCD-EFGH-JKMN", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "verification_code"}).AddRow("doc-1", "ABCD-EFGH-JKMN"))
+	doc, err := store.GetByVerificationCode(context.Background(), "ABCD-EFGH-JKMN")
+	require.NoError(t, err)
+	assert.Equal(t, "doc-1", doc.ID)
+
+	mock.ExpectQuery(query).WithArgs("ABCD-EFGH-JKMP", 1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = store.GetByVerificationCode(context.Background(), "ABCD-EFGH-JKMP")
+	assert.ErrorIs(t, err, ErrDocumentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGormStore_Revoke(t *testing.T) {
+	store, mock := setupTestStore(t)
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	update := regexp.QuoteMeta(`UPDATE "issued_documents" SET "revocation_reason"=$1,"revoked_at"=$2,"revoked_by"=$3 WHERE id = $4 AND revoked_at IS NULL`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(update).WithArgs("Issued in error", at, "admin-1", "doc-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	revoked, err := store.Revoke(context.Background(), "doc-1", at, "admin-1", "Issued in error")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(update).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	revoked, err = store.Revoke(context.Background(), "doc-1", at, "admin-1", "Issued in error")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
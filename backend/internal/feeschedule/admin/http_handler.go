@@ -0,0 +1,192 @@
+// Package admin exposes fee schedule management to administrators over HTTP. It is kept apart
+// from package feeschedule, which the task plugins depend on, because authz depends on them.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/feeschedule"
+)
+
+// RoleAuthorizer decides whether the caller holds one of a set of roles; satisfied by authz.Policy.
+type RoleAuthorizer interface {
+	AuthorizeRole(ctx context.Context, roles ...string) error
+}
+
+// ExchangeRateRequest is the body of an exchange rate update.
+type ExchangeRateRequest struct {
+	Rate          decimal.Decimal `json:"rate"`          // Value of one unit in feeschedule.BaseCurrency
+	EffectiveDate string          `json:"effectiveDate"` // YYYY-MM-DD
+}
+
+// HTTPHandler exposes fee schedules, exchange rates and recorded resolutions to administrators.
+type HTTPHandler struct {
+	service    *feeschedule.Service
+	authorizer RoleAuthorizer
+}
+
+// NewHTTPHandler creates a new HTTPHandler.
+func NewHTTPHandler(service *feeschedule.Service, authorizer RoleAuthorizer) *HTTPHandler {
+	return &HTTPHandler{service: service, authorizer: authorizer}
+}
+
+// HandlePublishSchedule publishes a new version of the fee schedule in the request body.
+// POST /api/v1/admin/fee-schedules
+func (h *HTTPHandler) HandlePublishSchedule(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	var schedule feeschedule.Schedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.PublishSchedule(r.Context(), &schedule); err != nil {
+		if errors.Is(err, feeschedule.ErrInvalidSchedule) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to publish fee schedule", "scheduleID", schedule.ID, "error", err)
+		http.Error(w, "failed to publish fee schedule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, schedule)
+}
+
+// HandleListScheduleVersions returns every version of a fee schedule, newest first.
+// GET /api/v1/admin/fee-schedules/{scheduleId}
+func (h *HTTPHandler) HandleListScheduleVersions(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	scheduleID := r.PathValue("scheduleId")
+	if scheduleID == "" {
+		http.Error(w, "schedule ID is required in URL", http.StatusBadRequest)
+		return
+	}
+
+	schedules, err := h.service.ListScheduleVersions(r.Context(), scheduleID)
+	if err != nil {
+		if errors.Is(err, feeschedule.ErrScheduleNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to list fee schedule versions", "scheduleID", scheduleID, "error", err)
+		http.Error(w, "failed to list fee schedule versions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, schedules)
+}
+
+// HandleSetExchangeRate sets the rate of a currency from a date on.
+// PUT /api/v1/admin/exchange-rates/{currency}
+func (h *HTTPHandler) HandleSetExchangeRate(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	var req ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse(time.DateOnly, req.EffectiveDate)
+	if err != nil {
+		http.Error(w, "effectiveDate is required in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	rate := &feeschedule.ExchangeRate{Currency: r.PathValue("currency"), Rate: req.Rate, EffectiveDate: date}
+	if err := h.service.SetExchangeRate(r.Context(), rate); err != nil {
+		if errors.Is(err, feeschedule.ErrInvalidRate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to set exchange rate", "currency", rate.Currency, "error", err)
+		http.Error(w, "failed to set exchange rate", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rate)
+}
+
+// HandleListResolutions returns the fee breakdowns recorded for a task.
+// GET /api/v1/admin/fee-resolutions?taskId=...
+func (h *HTTPHandler) HandleListResolutions(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	taskID := r.URL.Query().Get("taskId")
+	if taskID == "" {
+		http.Error(w, "taskId query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	resolutions, err := h.service.ListResolutions(r.Context(), taskID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list fee resolutions", "taskID", taskID, "error", err)
+		http.Error(w, "failed to list fee resolutions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, resolutions)
+}
+
+// HandleReproduceResolution recalculates a recorded fee breakdown from the schedule version,
+// inputs and exchange rates it was resolved with. It responds 409 if the result differs.
+// GET /api/v1/admin/fee-resolutions/{resolutionId}/reproduction
+func (h *HTTPHandler) HandleReproduceResolution(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	resolutionID := r.PathValue("resolutionId")
+	if resolutionID == "" {
+		http.Error(w, "resolution ID is required in URL", http.StatusBadRequest)
+		return
+	}
+
+	reproduced, err := h.service.Reproduce(r.Context(), resolutionID)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, reproduced)
+	case errors.Is(err, feeschedule.ErrResolutionNotFound), errors.Is(err, feeschedule.ErrScheduleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, feeschedule.ErrNotReproducible):
+		slog.WarnContext(r.Context(), "fee resolution not reproducible", "resolutionID", resolutionID, "error", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		slog.ErrorContext(r.Context(), "failed to reproduce fee resolution", "resolutionID", resolutionID, "error", err)
+		http.Error(w, "failed to reproduce fee resolution", http.StatusInternalServerError)
+	}
+}
+
+// authorize admits administrators, writing the error response otherwise.
+func (h *HTTPHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	err := h.authorizer.AuthorizeRole(r.Context(), authz.RoleAdmin)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, authz.ErrUnauthenticated):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, authz.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		slog.ErrorContext(r.Context(), "failed to authorize fee schedule access", "error", err)
+		http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+	}
+}
@@ -0,0 +1,165 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/feeschedule"
+)
+
+// stubAuthorizer returns a fixed authorization result; the zero value allows everything.
+type stubAuthorizer struct {
+	err error
+}
+
+func (a stubAuthorizer) AuthorizeRole(context.Context, ...string) error {
+	return a.err
+}
+
+// stubStore implements the parts of feeschedule.Store the tests use.
+type stubStore struct {
+	feeschedule.Store
+	published   []feeschedule.Schedule
+	rates       []feeschedule.ExchangeRate
+	resolutions map[string]feeschedule.Resolution
+	schedule    *feeschedule.Schedule
+}
+
+func (s *stubStore) PublishSchedule(_ context.Context, schedule *feeschedule.Schedule) error {
+	schedule.Version = len(s.published) + 1
+	s.published = append(s.published, *schedule)
+	return nil
+}
+
+func (s *stubStore) SaveRate(_ context.Context, rate *feeschedule.ExchangeRate) error {
+	s.rates = append(s.rates, *rate)
+	return nil
+}
+
+func (s *stubStore) GetResolution(_ context.Context, id string) (*feeschedule.Resolution, error) {
+	r, ok := s.resolutions[id]
+	if !ok {
+		return nil, feeschedule.ErrResolutionNotFound
+	}
+	return &r, nil
+}
+
+func (s *stubStore) GetSchedule(context.Context, string, int) (*feeschedule.Schedule, error) {
+	return s.schedule, nil
+}
+
+func TestHTTPHandler_HandlePublishSchedule(t *testing.T) {
+	body := `{"id":"customs","name":"Customs fees","currency":"LKR","rules":[{"code":"DOC","category":"ADDITION","basis":"FLAT","rate":"500"}]}`
+
+	t.Run("publishes a version", func(t *testing.T) {
+		store := &stubStore{}
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(feeschedule.NewService(store), stubAuthorizer{}).
+			HandlePublishSchedule(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/fee-schedules", strings.NewReader(body)))
+
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var schedule feeschedule.Schedule
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &schedule))
+		assert.Equal(t, 1, schedule.Version)
+		assert.Len(t, store.published, 1)
+	})
+
+	t.Run("rejects an invalid schedule", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(feeschedule.NewService(&stubStore{}), stubAuthorizer{}).
+			HandlePublishSchedule(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/fee-schedules", strings.NewReader(`{"id":"customs"}`)))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		store := &stubStore{}
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(feeschedule.NewService(store), stubAuthorizer{err: authz.ErrForbidden}).
+			HandlePublishSchedule(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/fee-schedules", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, store.published)
+	})
+}
+
+func TestHTTPHandler_HandleSetExchangeRate(t *testing.T) {
+	newRequest := func(currency, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/exchange-rates/"+currency, strings.NewReader(body))
+		req.SetPathValue("currency", currency)
+		return req
+	}
+
+	t.Run("sets a rate", func(t *testing.T) {
+		store := &stubStore{}
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(feeschedule.NewService(store), stubAuthorizer{}).
+			HandleSetExchangeRate(rec, newRequest("USD", `{"rate":"300.25","effectiveDate":"2026-10-16"}`))
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Len(t, store.rates, 1)
+		assert.Equal(t, "USD", store.rates[0].Currency)
+		assert.True(t, store.rates[0].Rate.Equal(decimal.RequireFromString("300.25")))
+		assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), store.rates[0].EffectiveDate)
+	})
+
+	t.Run("rejects a bad date", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(feeschedule.NewService(&stubStore{}), stubAuthorizer{}).
+			HandleSetExchangeRate(rec, newRequest("USD", `{"rate":"300","effectiveDate":"16/10/2026"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("rejects the base currency", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(feeschedule.NewService(&stubStore{}), stubAuthorizer{}).
+			HandleSetExchangeRate(rec, newRequest("LKR", `{"rate":"1","effectiveDate":"2026-10-16"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHTTPHandler_HandleReproduceResolution(t *testing.T) {
+	schedule := &feeschedule.Schedule{ID: "customs", Version: 1, Currency: "LKR", Rules: []feeschedule.Rule{
+		{Code: "DOC", Category: feeschedule.CategoryAddition, Basis: feeschedule.BasisFlat, Rate: decimal.NewFromInt(500)},
+	}}
+	newRequest := func(id string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/fee-resolutions/"+id+"/reproduction", nil)
+		req.SetPathValue("resolutionId", id)
+		return req
+	}
+	store := &stubStore{schedule: schedule, resolutions: map[string]feeschedule.Resolution{
+		"res-1": {ID: "res-1", ScheduleID: "customs", ScheduleVersion: 1, Total: decimal.NewFromInt(500),
+			Lines: []feeschedule.Line{{Code: "DOC", Amount: decimal.NewFromInt(500)}}},
+		"res-2": {ID: "res-2", ScheduleID: "customs", ScheduleVersion: 1, Total: decimal.NewFromInt(450),
+			Lines: []feeschedule.Line{{Code: "DOC", Amount: decimal.NewFromInt(450)}}},
+	}}
+	handler := NewHTTPHandler(feeschedule.NewService(store), stubAuthorizer{})
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"res-1", http.StatusOK},
+		{"res-2", http.StatusConflict},
+		{"missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.HandleReproduceResolution(rec, newRequest(tt.id))
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+}
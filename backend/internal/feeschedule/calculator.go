@@ -0,0 +1,279 @@
+package feeschedule
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// conversionPrecision is the number of decimal places kept when converting between currencies,
+// before the converted amount is rounded to cents.
+const conversionPrecision = 8
+
+var hundred = decimal.NewFromInt(100)
+
+// Validate checks that a schedule is complete and that its rules are well formed.
+func Validate(s *Schedule) error {
+	if s.ID == "" {
+		return fmt.Errorf("schedule ID is required")
+	}
+	if s.Name == "" {
+		return fmt.Errorf("schedule name is required")
+	}
+	if s.Currency == "" {
+		return fmt.Errorf("schedule currency is required")
+	}
+	if len(s.Rules) == 0 {
+		return fmt.Errorf("schedule must have at least one rule")
+	}
+	codes := make(map[string]bool, len(s.Rules))
+	for i, r := range s.Rules {
+		if r.Code == "" {
+			return fmt.Errorf("rule %d: code is required", i)
+		}
+		if codes[r.Code] {
+			return fmt.Errorf("rule %s: duplicate code", r.Code)
+		}
+		codes[r.Code] = true
+		if err := validateRule(r); err != nil {
+			return fmt.Errorf("rule %s: %w", r.Code, err)
+		}
+	}
+	return nil
+}
+
+func validateRule(r Rule) error {
+	if r.Category != CategoryAddition && r.Category != CategoryDeduction {
+		return fmt.Errorf("invalid category %q", r.Category)
+	}
+	switch r.Basis {
+	case BasisFlat, BasisPerUnit, BasisPercentage:
+	default:
+		return fmt.Errorf("invalid basis %q", r.Basis)
+	}
+	switch r.Measure {
+	case MeasureQuantity, MeasureWeight, MeasureValue:
+	case "":
+		if r.Basis != BasisFlat || len(r.Bands) > 0 {
+			return fmt.Errorf("measure is required")
+		}
+	default:
+		return fmt.Errorf("invalid measure %q", r.Measure)
+	}
+	switch r.BandMode {
+	case "", BandModeBanded:
+	case BandModeTiered:
+		if r.Basis == BasisFlat {
+			return fmt.Errorf("flat rules cannot be tiered")
+		}
+	default:
+		return fmt.Errorf("invalid band mode %q", r.BandMode)
+	}
+	if r.Rate.IsNegative() {
+		return fmt.Errorf("rate must not be negative")
+	}
+	for i, b := range r.Bands {
+		if b.Rate.IsNegative() {
+			return fmt.Errorf("band %d: rate must not be negative", i)
+		}
+		last := i == len(r.Bands)-1
+		switch {
+		case b.UpTo == nil && !last:
+			return fmt.Errorf("band %d: only the last band may be open-ended", i)
+		case b.UpTo != nil && last:
+			return fmt.Errorf("the last band must be open-ended")
+		case b.UpTo != nil && i > 0 && !b.UpTo.GreaterThan(*r.Bands[i-1].UpTo):
+			return fmt.Errorf("band %d: bands must be in ascending order", i)
+		}
+	}
+	if r.Min != nil && r.Max != nil && r.Min.GreaterThan(*r.Max) {
+		return fmt.Errorf("min must not exceed max")
+	}
+	return nil
+}
+
+// Calculate resolves the fee lines of schedule s for in, and their total in the schedule
+// currency. rates gives the value of one unit of each currency involved in BaseCurrency;
+// BaseCurrency itself need not be present. Rules for other HS chapters are skipped.
+func Calculate(s *Schedule, in Inputs, rates map[string]decimal.Decimal) ([]Line, decimal.Decimal, error) {
+	valueCurrency := in.ValueCurrency
+	if valueCurrency == "" {
+		valueCurrency = s.Currency
+	}
+	chapter := HSChapter(in.HSCode)
+
+	lines := make([]Line, 0, len(s.Rules))
+	total := decimal.Zero
+	for _, r := range s.Rules {
+		if len(r.HSChapters) > 0 && !slices.Contains(r.HSChapters, chapter) {
+			continue
+		}
+		ruleCurrency := r.Currency
+		if ruleCurrency == "" {
+			ruleCurrency = s.Currency
+		}
+
+		var measure decimal.Decimal
+		switch r.Measure {
+		case MeasureQuantity:
+			measure = in.Quantity
+		case MeasureWeight:
+			measure = in.Weight
+		case MeasureValue:
+			converted, err := convert(in.Value, valueCurrency, ruleCurrency, rates)
+			if err != nil {
+				return nil, decimal.Zero, fmt.Errorf("rule %s: %w", r.Code, err)
+			}
+			measure = converted
+		}
+
+		ruleAmount, rate := ruleCharge(r, measure)
+		capped := ""
+		if r.Min != nil && ruleAmount.LessThan(*r.Min) {
+			ruleAmount, capped = *r.Min, "MIN"
+		}
+		if r.Max != nil && ruleAmount.GreaterThan(*r.Max) {
+			ruleAmount, capped = *r.Max, "MAX"
+		}
+		ruleAmount = ruleAmount.Round(2)
+
+		amount, err := convert(ruleAmount, ruleCurrency, s.Currency, rates)
+		if err != nil {
+			return nil, decimal.Zero, fmt.Errorf("rule %s: %w", r.Code, err)
+		}
+		amount = amount.Round(2)
+
+		if r.Category == CategoryDeduction {
+			total = total.Sub(amount)
+		} else {
+			total = total.Add(amount)
+		}
+		lines = append(lines, Line{
+			Code:         r.Code,
+			Description:  r.Description,
+			Category:     r.Category,
+			Basis:        r.Basis,
+			Measure:      r.Measure,
+			MeasureValue: measure,
+			Rate:         rate,
+			Currency:     ruleCurrency,
+			RuleAmount:   ruleAmount,
+			Capped:       capped,
+			Amount:       amount,
+		})
+	}
+	return lines, total.Round(2), nil
+}
+
+// ruleCharge returns the amount rule r charges on measure, before caps, and the rate it applied.
+// A tiered rule reports the rate of the band the measure falls in.
+func ruleCharge(r Rule, measure decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	if len(r.Bands) == 0 {
+		return charge(r.Basis, r.Rate, measure), r.Rate
+	}
+	if r.BandMode != BandModeTiered {
+		band := bandFor(r.Bands, measure)
+		return charge(r.Basis, band.Rate, measure), band.Rate
+	}
+
+	amount := decimal.Zero
+	lower := decimal.Zero
+	for _, b := range r.Bands {
+		upper := measure
+		if b.UpTo != nil && b.UpTo.LessThan(measure) {
+			upper = *b.UpTo
+		}
+		if !upper.GreaterThan(lower) {
+			break
+		}
+		amount = amount.Add(charge(r.Basis, b.Rate, upper.Sub(lower)))
+		lower = upper
+	}
+	return amount, bandFor(r.Bands, measure).Rate
+}
+
+// bandFor returns the band that measure falls in. Validate guarantees the last band is open-ended.
+func bandFor(bands []Band, measure decimal.Decimal) Band {
+	for _, b := range bands {
+		if b.UpTo == nil || measure.LessThanOrEqual(*b.UpTo) {
+			return b
+		}
+	}
+	return bands[len(bands)-1]
+}
+
+func charge(basis Basis, rate, measure decimal.Decimal) decimal.Decimal {
+	switch basis {
+	case BasisPerUnit:
+		return rate.Mul(measure)
+	case BasisPercentage:
+		return measure.Mul(rate).Div(hundred)
+	default:
+		return rate
+	}
+}
+
+// convert converts amount from one currency to another through BaseCurrency.
+func convert(amount decimal.Decimal, from, to string, rates map[string]decimal.Decimal) (decimal.Decimal, error) {
+	if from == to {
+		return amount, nil
+	}
+	fromRate, err := rateOf(from, rates)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	toRate, err := rateOf(to, rates)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(fromRate).DivRound(toRate, conversionPrecision), nil
+}
+
+func rateOf(currency string, rates map[string]decimal.Decimal) (decimal.Decimal, error) {
+	if currency == BaseCurrency {
+		return decimal.NewFromInt(1), nil
+	}
+	rate, ok := rates[currency]
+	if !ok || !rate.IsPositive() {
+		return decimal.Zero, fmt.Errorf("%w: %s", ErrRateNotFound, currency)
+	}
+	return rate, nil
+}
+
+// requiredCurrencies returns the currencies other than BaseCurrency that resolving s for in
+// may convert between, in sorted order.
+func requiredCurrencies(s *Schedule, in Inputs) []string {
+	set := map[string]bool{s.Currency: true}
+	for _, r := range s.Rules {
+		if r.Currency != "" {
+			set[r.Currency] = true
+		}
+		if r.Measure == MeasureValue && in.ValueCurrency != "" {
+			set[in.ValueCurrency] = true
+		}
+	}
+	delete(set, BaseCurrency)
+	currencies := make([]string, 0, len(set))
+	for c := range set {
+		currencies = append(currencies, c)
+	}
+	slices.Sort(currencies)
+	return currencies
+}
+
+// HSChapter returns the two-digit chapter of an HS code such as "0902.10" or "090210".
+func HSChapter(hsCode string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, hsCode)
+	if len(digits) < 2 {
+		return ""
+	}
+	return digits[:2]
+}
@@ -0,0 +1,140 @@
+package feeschedule
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func decPtr(s string) *decimal.Decimal {
+	d := dec(s)
+	return &d
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Schedule {
+		return &Schedule{ID: "customs", Name: "Customs fees", Currency: "LKR", Rules: []Rule{
+			{Code: "DOC", Category: CategoryAddition, Basis: BasisFlat, Rate: dec("500")},
+		}}
+	}
+
+	require.NoError(t, Validate(valid()))
+
+	tests := []struct {
+		name    string
+		mutate  func(*Schedule)
+		wantErr string
+	}{
+		{"missing currency", func(s *Schedule) { s.Currency = "" }, "currency is required"},
+		{"no rules", func(s *Schedule) { s.Rules = nil }, "at least one rule"},
+		{"duplicate code", func(s *Schedule) { s.Rules = append(s.Rules, s.Rules[0]) }, "duplicate code"},
+		{"per-unit without measure", func(s *Schedule) { s.Rules[0].Basis = BasisPerUnit }, "measure is required"},
+		{"closed last band", func(s *Schedule) {
+			s.Rules[0].Measure = MeasureWeight
+			s.Rules[0].Bands = []Band{{UpTo: decPtr("100"), Rate: dec("1")}}
+		}, "last band must be open-ended"},
+		{"descending bands", func(s *Schedule) {
+			s.Rules[0].Measure = MeasureWeight
+			s.Rules[0].Bands = []Band{{UpTo: decPtr("100"), Rate: dec("1")}, {UpTo: decPtr("50"), Rate: dec("2")}, {Rate: dec("3")}}
+		}, "ascending order"},
+		{"tiered flat rule", func(s *Schedule) {
+			s.Rules[0].Measure = MeasureWeight
+			s.Rules[0].BandMode = BandModeTiered
+			s.Rules[0].Bands = []Band{{Rate: dec("1")}}
+		}, "cannot be tiered"},
+		{"min above max", func(s *Schedule) { s.Rules[0].Min, s.Rules[0].Max = decPtr("10"), decPtr("5") }, "min must not exceed max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.mutate(s)
+			assert.ErrorContains(t, Validate(s), tt.wantErr)
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	schedule := &Schedule{ID: "customs", Version: 1, Currency: "LKR", Rules: []Rule{
+		{Code: "DOC", Description: "Documentation", Category: CategoryAddition, Basis: BasisFlat, Rate: dec("500")},
+		{
+			Code: "WEIGHT", Description: "Handling by weight", Category: CategoryAddition, Basis: BasisFlat, Measure: MeasureWeight,
+			Bands: []Band{{UpTo: decPtr("100"), Rate: dec("1000")}, {UpTo: decPtr("1000"), Rate: dec("2500")}, {Rate: dec("5000")}},
+		},
+		{
+			Code: "UNITS", Description: "Inspection per unit", Category: CategoryAddition, Basis: BasisPerUnit, Measure: MeasureQuantity,
+			BandMode: BandModeTiered,
+			Bands:    []Band{{UpTo: decPtr("10"), Rate: dec("20")}, {Rate: dec("5")}},
+		},
+		{
+			Code: "CESS", Description: "Tea cess", Category: CategoryAddition, HSChapters: []string{"09"}, Basis: BasisPercentage,
+			Measure: MeasureValue, Rate: dec("1"), Min: decPtr("10"), Max: decPtr("50"), Currency: "USD",
+		},
+		{Code: "REBATE", Description: "Exporter rebate", Category: CategoryDeduction, Basis: BasisFlat, Rate: dec("100")},
+	}}
+	rates := map[string]decimal.Decimal{"USD": dec("300"), "EUR": dec("330")}
+
+	t.Run("applies bands, tiers, caps and conversion", func(t *testing.T) {
+		in := Inputs{HSCode: "0902.10", Quantity: dec("25"), Weight: dec("250"), Value: dec("2000"), ValueCurrency: "EUR"}
+		lines, total, err := Calculate(schedule, in, rates)
+		require.NoError(t, err)
+		require.Len(t, lines, 5)
+
+		assert.Equal(t, "500", lines[0].Amount.String())
+		// 250kg falls in the second band.
+		assert.Equal(t, "2500", lines[1].Amount.String())
+		assert.Equal(t, "2500", lines[1].Rate.String())
+		// 10 units at 20, then 15 at 5.
+		assert.Equal(t, "275", lines[2].Amount.String())
+		assert.Equal(t, "5", lines[2].Rate.String())
+		// EUR 2000 is USD 2200; 1% is USD 22, within the caps, charged as LKR 6600.
+		assert.Equal(t, "2200", lines[3].MeasureValue.String())
+		assert.Equal(t, "22", lines[3].RuleAmount.String())
+		assert.Empty(t, lines[3].Capped)
+		assert.Equal(t, "6600", lines[3].Amount.String())
+		assert.Equal(t, CategoryDeduction, lines[4].Category)
+
+		assert.Equal(t, "9775", total.String())
+	})
+
+	t.Run("caps the amount", func(t *testing.T) {
+		in := Inputs{HSCode: "090210", Weight: dec("10"), Value: dec("100000"), ValueCurrency: "USD"}
+		lines, _, err := Calculate(schedule, in, rates)
+		require.NoError(t, err)
+		assert.Equal(t, "MAX", lines[3].Capped)
+		assert.Equal(t, "50", lines[3].RuleAmount.String())
+		assert.Equal(t, "15000", lines[3].Amount.String())
+	})
+
+	t.Run("skips rules for other chapters", func(t *testing.T) {
+		lines, total, err := Calculate(schedule, Inputs{HSCode: "8471.30", Quantity: dec("1"), Weight: dec("5")}, rates)
+		require.NoError(t, err)
+		require.Len(t, lines, 4)
+		assert.Equal(t, "1420", total.String())
+	})
+
+	t.Run("fails without a needed rate", func(t *testing.T) {
+		_, _, err := Calculate(schedule, Inputs{HSCode: "0902", Value: dec("1"), ValueCurrency: "GBP"}, rates)
+		assert.ErrorIs(t, err, ErrRateNotFound)
+	})
+}
+
+func TestRequiredCurrencies(t *testing.T) {
+	schedule := &Schedule{Currency: "LKR", Rules: []Rule{
+		{Code: "A", Measure: MeasureValue, Currency: "USD"},
+		{Code: "B", Measure: MeasureWeight},
+	}}
+	assert.Equal(t, []string{"EUR", "USD"}, requiredCurrencies(schedule, Inputs{ValueCurrency: "EUR"}))
+	assert.Equal(t, []string{"USD"}, requiredCurrencies(schedule, Inputs{}))
+}
+
+func TestHSChapter(t *testing.T) {
+	assert.Equal(t, "09", HSChapter("0902.10.10"))
+	assert.Equal(t, "84", HSChapter("847130"))
+	assert.Equal(t, "", HSChapter("9"))
+}
@@ -0,0 +1,156 @@
+package feeschedule
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BaseCurrency is the currency exchange rates are quoted against.
+const BaseCurrency = "LKR"
+
+// Category says whether a line adds to or is deducted from the total.
+type Category string
+
+const (
+	CategoryAddition  Category = "ADDITION"
+	CategoryDeduction Category = "DEDUCTION"
+)
+
+// Basis says how a rule turns its rate into an amount.
+type Basis string
+
+const (
+	BasisFlat       Basis = "FLAT"       // The rate is the amount
+	BasisPerUnit    Basis = "PER_UNIT"   // Rate × measure
+	BasisPercentage Basis = "PERCENTAGE" // Rate percent of the measure
+)
+
+// Measure is the declared input a rule is charged on.
+type Measure string
+
+const (
+	MeasureQuantity Measure = "QUANTITY"
+	MeasureWeight   Measure = "WEIGHT" // Kilograms
+	MeasureValue    Measure = "VALUE"  // Declared value, converted to the rule currency
+)
+
+// BandMode says how a rule with bands applies them.
+type BandMode string
+
+const (
+	// BandModeBanded charges the whole measure at the rate of the band it falls in.
+	BandModeBanded BandMode = "BANDED"
+	// BandModeTiered charges each slice of the measure at the rate of its own band.
+	BandModeTiered BandMode = "TIERED"
+)
+
+// Band is one rate band of a rule. Bands are ordered by UpTo; the last band leaves UpTo
+// unset to cover everything above the previous band.
+type Band struct {
+	UpTo *decimal.Decimal `json:"upTo,omitempty"`
+	Rate decimal.Decimal  `json:"rate"`
+}
+
+// Rule is one fee line of a schedule.
+type Rule struct {
+	Code        string   `json:"code"`
+	Description string   `json:"description"`
+	Category    Category `json:"category"`
+	// HSChapters limits the rule to goods in these two-digit HS chapters; empty matches all goods.
+	HSChapters []string `json:"hsChapters,omitempty"`
+	Basis      Basis    `json:"basis"`
+	Measure    Measure  `json:"measure,omitempty"` // Required unless Basis is FLAT without bands
+	// Rate applies when the rule has no bands.
+	Rate     decimal.Decimal `json:"rate"`
+	Bands    []Band          `json:"bands,omitempty"`
+	BandMode BandMode        `json:"bandMode,omitempty"` // Defaults to BANDED
+	// Min and Max cap the amount of the line, in the rule currency.
+	Min *decimal.Decimal `json:"min,omitempty"`
+	Max *decimal.Decimal `json:"max,omitempty"`
+	// Currency of Rate, bands and caps; defaults to the schedule currency.
+	Currency string `json:"currency,omitempty"`
+}
+
+// Schedule is one version of a fee schedule. Versions are immutable once published; the version
+// in force at a time is the latest one whose EffectiveFrom is not after it.
+type Schedule struct {
+	ID            string    `gorm:"type:varchar(100);primaryKey" json:"id"`
+	Version       int       `gorm:"primaryKey" json:"version"`
+	Name          string    `gorm:"type:varchar(255);not null" json:"name"`
+	Currency      string    `gorm:"type:varchar(10);not null" json:"currency"` // Currency fees are charged in
+	Rules         []Rule    `gorm:"type:jsonb;not null;serializer:json" json:"rules"`
+	EffectiveFrom time.Time `gorm:"not null" json:"effectiveFrom"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// TableName returns the table name for Schedule.
+func (Schedule) TableName() string {
+	return "fee_schedules"
+}
+
+// ExchangeRate is the value of one unit of Currency in BaseCurrency from EffectiveDate on.
+type ExchangeRate struct {
+	Currency      string          `gorm:"type:varchar(10);primaryKey" json:"currency"`
+	EffectiveDate time.Time       `gorm:"type:date;primaryKey" json:"effectiveDate"`
+	Rate          decimal.Decimal `gorm:"type:numeric(20,8);not null" json:"rate"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+// TableName returns the table name for ExchangeRate.
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// Inputs are the declared facts of a consignment that fees are charged on.
+type Inputs struct {
+	HSCode        string          `json:"hsCode"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Weight        decimal.Decimal `json:"weight"`
+	Value         decimal.Decimal `json:"value"`
+	ValueCurrency string          `json:"valueCurrency,omitempty"` // Defaults to the schedule currency
+}
+
+// Line is one resolved fee line. Amount is in the schedule currency.
+type Line struct {
+	Code         string          `json:"code"`
+	Description  string          `json:"description"`
+	Category     Category        `json:"category"`
+	Basis        Basis           `json:"basis"`
+	Measure      Measure         `json:"measure,omitempty"`
+	MeasureValue decimal.Decimal `json:"measureValue"`     // In the rule currency when Measure is VALUE
+	Rate         decimal.Decimal `json:"rate"`             // Rate of the band applied; the plain rate without bands
+	Currency     string          `json:"currency"`         // Rule currency
+	RuleAmount   decimal.Decimal `json:"ruleAmount"`       // In the rule currency, after caps
+	Capped       string          `json:"capped,omitempty"` // MIN or MAX when a cap applied
+	Amount       decimal.Decimal `json:"amount"`
+}
+
+// AppliedRate is an exchange rate used by a resolution.
+type AppliedRate struct {
+	Currency      string          `json:"currency"`
+	Rate          decimal.Decimal `json:"rate"`
+	EffectiveDate time.Time       `json:"effectiveDate"`
+}
+
+// Resolution records a fee breakdown resolved from a schedule version, together with the inputs
+// and exchange rates it was resolved with, so that it can be reproduced later.
+type Resolution struct {
+	ID              string          `gorm:"type:varchar(100);primaryKey" json:"id"`
+	TaskID          string          `gorm:"type:varchar(255);not null" json:"taskId"`
+	ScheduleID      string          `gorm:"type:varchar(100);not null" json:"scheduleId"`
+	ScheduleVersion int             `gorm:"not null" json:"scheduleVersion"`
+	Currency        string          `gorm:"type:varchar(10);not null" json:"currency"`
+	Inputs          Inputs          `gorm:"type:jsonb;not null;serializer:json" json:"inputs"`
+	Rates           []AppliedRate   `gorm:"type:jsonb;not null;serializer:json" json:"rates"`
+	Lines           []Line          `gorm:"type:jsonb;not null;serializer:json" json:"lines"`
+	Total           decimal.Decimal `gorm:"type:numeric(15,2);not null" json:"total"`
+	Fingerprint     string          `gorm:"type:varchar(64);not null" json:"fingerprint"` // Identical resolutions of a task are recorded once
+	ResolvedAt      time.Time       `gorm:"not null" json:"resolvedAt"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+// TableName returns the table name for Resolution.
+func (Resolution) TableName() string {
+	return "fee_resolutions"
+}
@@ -0,0 +1,215 @@
+package feeschedule
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidSchedule    = errors.New("invalid fee schedule")
+	ErrInvalidRate        = errors.New("invalid exchange rate")
+	ErrScheduleNotFound   = errors.New("fee schedule not found")
+	ErrResolutionNotFound = errors.New("fee resolution not found")
+	ErrRateNotFound       = errors.New("exchange rate not found")
+	ErrNotReproducible    = errors.New("fee resolution cannot be reproduced")
+)
+
+// Resolver resolves fee schedules into recorded breakdowns; the PAYMENT plugin depends on it.
+type Resolver interface {
+	// Resolve resolves the schedule version in force at req.At for the given inputs and
+	// records the result.
+	Resolve(ctx context.Context, req ResolveRequest) (*Resolution, error)
+	// GetResolution returns a recorded resolution.
+	GetResolution(ctx context.Context, id string) (*Resolution, error)
+}
+
+// ResolveRequest asks for the fees a task owes under a schedule.
+type ResolveRequest struct {
+	ScheduleID string
+	TaskID     string
+	Inputs     Inputs
+	At         time.Time // Defaults to now
+}
+
+// Service manages fee schedules and exchange rates and resolves fees from them.
+type Service struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewService creates a Service.
+func NewService(store Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// PublishSchedule validates s and stores it as the next version of its schedule. A zero
+// EffectiveFrom makes the version effective immediately.
+func (s *Service) PublishSchedule(ctx context.Context, schedule *Schedule) error {
+	if err := Validate(schedule); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+	if schedule.EffectiveFrom.IsZero() {
+		schedule.EffectiveFrom = s.now().UTC()
+	}
+	if err := s.store.PublishSchedule(ctx, schedule); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "fee schedule published",
+		"scheduleID", schedule.ID,
+		"version", schedule.Version,
+		"effectiveFrom", schedule.EffectiveFrom)
+	return nil
+}
+
+// ListScheduleVersions returns every version of a schedule, newest first.
+func (s *Service) ListScheduleVersions(ctx context.Context, id string) ([]Schedule, error) {
+	schedules, err := s.store.ListScheduleVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return schedules, nil
+}
+
+// SetExchangeRate stores the value of one unit of a currency in BaseCurrency from a date on.
+func (s *Service) SetExchangeRate(ctx context.Context, rate *ExchangeRate) error {
+	switch {
+	case rate.Currency == "":
+		return fmt.Errorf("%w: currency is required", ErrInvalidRate)
+	case rate.Currency == BaseCurrency:
+		return fmt.Errorf("%w: %s is the base currency", ErrInvalidRate, BaseCurrency)
+	case !rate.Rate.IsPositive():
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidRate)
+	case rate.EffectiveDate.IsZero():
+		return fmt.Errorf("%w: effective date is required", ErrInvalidRate)
+	}
+	rate.EffectiveDate = rate.EffectiveDate.UTC().Truncate(24 * time.Hour)
+	return s.store.SaveRate(ctx, rate)
+}
+
+// Resolve resolves and records the fees a task owes. Resolving the same task again with the same
+// outcome returns the resolution already recorded.
+func (s *Service) Resolve(ctx context.Context, req ResolveRequest) (*Resolution, error) {
+	at := req.At
+	if at.IsZero() {
+		at = s.now()
+	}
+	schedule, err := s.store.ActiveSchedule(ctx, req.ScheduleID, at)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.store.RatesAt(ctx, requiredCurrencies(schedule, req.Inputs), at)
+	if err != nil {
+		return nil, err
+	}
+	applied := make([]AppliedRate, 0, len(stored))
+	for _, r := range stored {
+		applied = append(applied, AppliedRate{Currency: r.Currency, Rate: r.Rate, EffectiveDate: r.EffectiveDate})
+	}
+
+	lines, total, err := Calculate(schedule, req.Inputs, rateMap(applied))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve fee schedule %s version %d: %w", schedule.ID, schedule.Version, err)
+	}
+	resolution := &Resolution{
+		TaskID:          req.TaskID,
+		ScheduleID:      schedule.ID,
+		ScheduleVersion: schedule.Version,
+		Currency:        schedule.Currency,
+		Inputs:          req.Inputs,
+		Rates:           applied,
+		Lines:           lines,
+		Total:           total,
+		ResolvedAt:      at.UTC(),
+	}
+	resolution.Fingerprint, err = fingerprint(resolution)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.SaveResolution(ctx, resolution)
+}
+
+// GetResolution returns a recorded resolution.
+func (s *Service) GetResolution(ctx context.Context, id string) (*Resolution, error) {
+	return s.store.GetResolution(ctx, id)
+}
+
+// ListResolutions returns the resolutions recorded for a task, oldest first.
+func (s *Service) ListResolutions(ctx context.Context, taskID string) ([]Resolution, error) {
+	return s.store.ListResolutions(ctx, taskID)
+}
+
+// Reproduce recalculates a recorded resolution from the schedule version, inputs and exchange
+// rates it was resolved with, and returns the recalculated resolution. It fails with
+// ErrNotReproducible if the result differs from what was recorded.
+func (s *Service) Reproduce(ctx context.Context, id string) (*Resolution, error) {
+	recorded, err := s.store.GetResolution(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.store.GetSchedule(ctx, recorded.ScheduleID, recorded.ScheduleVersion)
+	if err != nil {
+		return nil, err
+	}
+	lines, total, err := Calculate(schedule, recorded.Inputs, rateMap(recorded.Rates))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNotReproducible, err)
+	}
+
+	reproduced := *recorded
+	reproduced.Lines = lines
+	reproduced.Total = total
+	if !sameLines(recorded.Lines, lines) || !recorded.Total.Equal(total) {
+		return &reproduced, fmt.Errorf("%w: %s recorded %s, recalculated %s", ErrNotReproducible, id, recorded.Total, total)
+	}
+	return &reproduced, nil
+}
+
+func rateMap(rates []AppliedRate) map[string]decimal.Decimal {
+	m := make(map[string]decimal.Decimal, len(rates))
+	for _, r := range rates {
+		m[r.Currency] = r.Rate
+	}
+	return m
+}
+
+func sameLines(a, b []Line) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Code != b[i].Code || !a[i].Amount.Equal(b[i].Amount) {
+			return false
+		}
+	}
+	return true
+}
+
+// fingerprint identifies the outcome of a resolution: the schedule version, inputs, rates and
+// resolved lines, but not when it was resolved.
+func fingerprint(r *Resolution) (string, error) {
+	data, err := json.Marshal(struct {
+		ScheduleID      string          `json:"scheduleId"`
+		ScheduleVersion int             `json:"scheduleVersion"`
+		Currency        string          `json:"currency"`
+		Inputs          Inputs          `json:"inputs"`
+		Rates           []AppliedRate   `json:"rates"`
+		Lines           []Line          `json:"lines"`
+		Total           decimal.Decimal `json:"total"`
+	}{r.ScheduleID, r.ScheduleVersion, r.Currency, r.Inputs, r.Rates, r.Lines, r.Total})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint fee resolution: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
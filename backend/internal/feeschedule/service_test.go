@@ -0,0 +1,225 @@
+package feeschedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory Store.
+type memoryStore struct {
+	schedules   []Schedule
+	rates       []ExchangeRate
+	resolutions []Resolution
+}
+
+func (m *memoryStore) PublishSchedule(_ context.Context, s *Schedule) error {
+	s.Version = 1
+	for _, existing := range m.schedules {
+		if existing.ID == s.ID && existing.Version >= s.Version {
+			s.Version = existing.Version + 1
+		}
+	}
+	m.schedules = append(m.schedules, *s)
+	return nil
+}
+
+func (m *memoryStore) ActiveSchedule(_ context.Context, id string, at time.Time) (*Schedule, error) {
+	var active *Schedule
+	for i, s := range m.schedules {
+		if s.ID == id && !s.EffectiveFrom.After(at) && (active == nil || !s.EffectiveFrom.Before(active.EffectiveFrom)) {
+			active = &m.schedules[i]
+		}
+	}
+	if active == nil {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	copied := *active
+	return &copied, nil
+}
+
+func (m *memoryStore) GetSchedule(_ context.Context, id string, version int) (*Schedule, error) {
+	for _, s := range m.schedules {
+		if s.ID == id && s.Version == version {
+			return &s, nil
+		}
+	}
+	return nil, ErrScheduleNotFound
+}
+
+func (m *memoryStore) ListScheduleVersions(_ context.Context, id string) ([]Schedule, error) {
+	var found []Schedule
+	for _, s := range m.schedules {
+		if s.ID == id {
+			found = append(found, s)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Version > found[j].Version })
+	return found, nil
+}
+
+func (m *memoryStore) SaveRate(_ context.Context, rate *ExchangeRate) error {
+	m.rates = append(m.rates, *rate)
+	return nil
+}
+
+func (m *memoryStore) RatesAt(_ context.Context, currencies []string, at time.Time) ([]ExchangeRate, error) {
+	var found []ExchangeRate
+	for _, c := range currencies {
+		var latest *ExchangeRate
+		for i, r := range m.rates {
+			if r.Currency == c && !r.EffectiveDate.After(at) && (latest == nil || r.EffectiveDate.After(latest.EffectiveDate)) {
+				latest = &m.rates[i]
+			}
+		}
+		if latest != nil {
+			found = append(found, *latest)
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryStore) SaveResolution(_ context.Context, r *Resolution) (*Resolution, error) {
+	for _, existing := range m.resolutions {
+		if existing.TaskID == r.TaskID && existing.Fingerprint == r.Fingerprint {
+			return &existing, nil
+		}
+	}
+	r.ID = fmt.Sprintf("res-%d", len(m.resolutions)+1)
+	m.resolutions = append(m.resolutions, *r)
+	return r, nil
+}
+
+func (m *memoryStore) GetResolution(_ context.Context, id string) (*Resolution, error) {
+	for _, r := range m.resolutions {
+		if r.ID == id {
+			return &r, nil
+		}
+	}
+	return nil, ErrResolutionNotFound
+}
+
+func (m *memoryStore) ListResolutions(_ context.Context, taskID string) ([]Resolution, error) {
+	var found []Resolution
+	for _, r := range m.resolutions {
+		if r.TaskID == taskID {
+			found = append(found, r)
+		}
+	}
+	return found, nil
+}
+
+func newTestService(t *testing.T) (*Service, *memoryStore) {
+	t.Helper()
+	store := &memoryStore{}
+	svc := NewService(store)
+	svc.now = func() time.Time { return time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC) }
+
+	// A USD-denominated value fee, in force from the start of the month.
+	require.NoError(t, svc.PublishSchedule(context.Background(), &Schedule{
+		ID: "customs", Name: "Customs fees", Currency: "LKR", EffectiveFrom: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Rules: []Rule{{Code: "VAL", Category: CategoryAddition, Basis: BasisPercentage, Measure: MeasureValue, Rate: dec("1"), Currency: "USD"}},
+	}))
+	require.NoError(t, svc.SetExchangeRate(context.Background(), &ExchangeRate{Currency: "USD", Rate: dec("300"), EffectiveDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}))
+	return svc, store
+}
+
+func TestService_PublishSchedule(t *testing.T) {
+	svc, store := newTestService(t)
+
+	t.Run("rejects an invalid schedule", func(t *testing.T) {
+		err := svc.PublishSchedule(context.Background(), &Schedule{ID: "customs", Name: "Customs fees"})
+		assert.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+
+	t.Run("publishes the next version, effective now by default", func(t *testing.T) {
+		next := &Schedule{ID: "customs", Name: "Customs fees", Currency: "LKR", Rules: store.schedules[0].Rules}
+		require.NoError(t, svc.PublishSchedule(context.Background(), next))
+		assert.Equal(t, 2, next.Version)
+		assert.Equal(t, svc.now(), next.EffectiveFrom)
+	})
+}
+
+func TestService_SetExchangeRate(t *testing.T) {
+	svc, store := newTestService(t)
+
+	err := svc.SetExchangeRate(context.Background(), &ExchangeRate{Currency: BaseCurrency, Rate: dec("1"), EffectiveDate: svc.now()})
+	assert.ErrorIs(t, err, ErrInvalidRate)
+	err = svc.SetExchangeRate(context.Background(), &ExchangeRate{Currency: "EUR", Rate: dec("0"), EffectiveDate: svc.now()})
+	assert.ErrorIs(t, err, ErrInvalidRate)
+
+	require.NoError(t, svc.SetExchangeRate(context.Background(), &ExchangeRate{Currency: "EUR", Rate: dec("330"), EffectiveDate: svc.now()}))
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), store.rates[len(store.rates)-1].EffectiveDate)
+}
+
+func TestService_Resolve(t *testing.T) {
+	svc, store := newTestService(t)
+	req := ResolveRequest{ScheduleID: "customs", TaskID: "task-1", Inputs: Inputs{HSCode: "0902", Value: dec("1000"), ValueCurrency: "USD"}}
+
+	resolution, err := svc.Resolve(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resolution.ScheduleVersion)
+	assert.Equal(t, "3000", resolution.Total.String())
+	require.Len(t, resolution.Rates, 1)
+	assert.Equal(t, "300", resolution.Rates[0].Rate.String())
+	assert.NotEmpty(t, resolution.Fingerprint)
+
+	t.Run("records an identical resolution once", func(t *testing.T) {
+		again, err := svc.Resolve(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, resolution.ID, again.ID)
+		assert.Len(t, store.resolutions, 1)
+	})
+
+	t.Run("uses the rates in force", func(t *testing.T) {
+		require.NoError(t, svc.SetExchangeRate(context.Background(), &ExchangeRate{Currency: "USD", Rate: dec("310"), EffectiveDate: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)}))
+		later, err := svc.Resolve(context.Background(), req)
+		require.NoError(t, err)
+		assert.NotEqual(t, resolution.ID, later.ID)
+		assert.Equal(t, "3100", later.Total.String())
+
+		earlier := req
+		earlier.At = time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+		before, err := svc.Resolve(context.Background(), earlier)
+		require.NoError(t, err)
+		assert.Equal(t, resolution.ID, before.ID)
+	})
+
+	t.Run("fails before the schedule is in force", func(t *testing.T) {
+		early := req
+		early.At = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		_, err := svc.Resolve(context.Background(), early)
+		assert.ErrorIs(t, err, ErrScheduleNotFound)
+	})
+}
+
+func TestService_Reproduce(t *testing.T) {
+	svc, store := newTestService(t)
+	resolution, err := svc.Resolve(context.Background(), ResolveRequest{
+		ScheduleID: "customs", TaskID: "task-1", Inputs: Inputs{Value: dec("1000"), ValueCurrency: "USD"},
+	})
+	require.NoError(t, err)
+
+	t.Run("reproduces from the recorded rates after they change", func(t *testing.T) {
+		require.NoError(t, svc.SetExchangeRate(context.Background(), &ExchangeRate{Currency: "USD", Rate: dec("400"), EffectiveDate: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)}))
+		reproduced, err := svc.Reproduce(context.Background(), resolution.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "3000", reproduced.Total.String())
+	})
+
+	t.Run("reports a recorded breakdown that no longer matches", func(t *testing.T) {
+		store.resolutions[0].Total = dec("2999")
+		_, err := svc.Reproduce(context.Background(), resolution.ID)
+		assert.ErrorIs(t, err, ErrNotReproducible)
+	})
+
+	t.Run("unknown resolution", func(t *testing.T) {
+		_, err := svc.Reproduce(context.Background(), "missing")
+		assert.ErrorIs(t, err, ErrResolutionNotFound)
+	})
+}
@@ -0,0 +1,185 @@
+package feeschedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Store persists fee schedule versions, exchange rates and resolutions.
+type Store interface {
+	// PublishSchedule stores s as the next version of its schedule and sets s.Version.
+	PublishSchedule(ctx context.Context, s *Schedule) error
+	// ActiveSchedule returns the version of a schedule in force at the given time.
+	ActiveSchedule(ctx context.Context, id string, at time.Time) (*Schedule, error)
+	// GetSchedule returns one version of a schedule.
+	GetSchedule(ctx context.Context, id string, version int) (*Schedule, error)
+	// ListScheduleVersions returns every version of a schedule, newest first.
+	ListScheduleVersions(ctx context.Context, id string) ([]Schedule, error)
+	// SaveRate stores an exchange rate, replacing any rate for the same currency and date.
+	SaveRate(ctx context.Context, rate *ExchangeRate) error
+	// RatesAt returns, for each of the currencies that has one, the rate in force at the given time.
+	RatesAt(ctx context.Context, currencies []string, at time.Time) ([]ExchangeRate, error)
+	// SaveResolution records r unless the task already has a resolution with the same
+	// fingerprint, and returns the recorded resolution.
+	SaveResolution(ctx context.Context, r *Resolution) (*Resolution, error)
+	// GetResolution returns a recorded resolution.
+	GetResolution(ctx context.Context, id string) (*Resolution, error)
+	// ListResolutions returns the resolutions recorded for a task, oldest first.
+	ListResolutions(ctx context.Context, taskID string) ([]Resolution, error)
+}
+
+// GormStore implements Store with GORM.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates a GormStore.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection cannot be nil")
+	}
+	return &GormStore{db: db}, nil
+}
+
+// PublishSchedule inserts s with the version after the latest stored one.
+func (s *GormStore) PublishSchedule(ctx context.Context, schedule *Schedule) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Model(&Schedule{}).
+			Where("id = ?", schedule.ID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return fmt.Errorf("failed to read latest fee schedule version: %w", err)
+		}
+		schedule.Version = latest + 1
+		schedule.CreatedAt = time.Now().UTC()
+		if err := tx.Create(schedule).Error; err != nil {
+			return fmt.Errorf("failed to publish fee schedule: %w", err)
+		}
+		return nil
+	})
+}
+
+// ActiveSchedule returns the latest version effective at the given time.
+func (s *GormStore) ActiveSchedule(ctx context.Context, id string, at time.Time) (*Schedule, error) {
+	var schedule Schedule
+	err := s.db.WithContext(ctx).
+		Where("id = ? AND effective_from <= ?", id, at).
+		Order("effective_from DESC, version DESC").
+		First(&schedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to load fee schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// GetSchedule loads one version of a schedule.
+func (s *GormStore) GetSchedule(ctx context.Context, id string, version int) (*Schedule, error) {
+	var schedule Schedule
+	err := s.db.WithContext(ctx).Where("id = ? AND version = ?", id, version).First(&schedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s version %d", ErrScheduleNotFound, id, version)
+		}
+		return nil, fmt.Errorf("failed to load fee schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// ListScheduleVersions loads every version of a schedule.
+func (s *GormStore) ListScheduleVersions(ctx context.Context, id string) ([]Schedule, error) {
+	var schedules []Schedule
+	if err := s.db.WithContext(ctx).Where("id = ?", id).Order("version DESC").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list fee schedule versions: %w", err)
+	}
+	return schedules, nil
+}
+
+// SaveRate upserts an exchange rate.
+func (s *GormStore) SaveRate(ctx context.Context, rate *ExchangeRate) error {
+	rate.CreatedAt = time.Now().UTC()
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "currency"}, {Name: "effective_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "created_at"}),
+		}).
+		Create(rate).Error
+	if err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+	return nil
+}
+
+// RatesAt loads the latest rate of each currency effective on or before the given time.
+func (s *GormStore) RatesAt(ctx context.Context, currencies []string, at time.Time) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+	if len(currencies) == 0 {
+		return rates, nil
+	}
+	err := s.db.WithContext(ctx).
+		Select("DISTINCT ON (currency) *").
+		Where("currency IN ? AND effective_date <= ?", currencies, at).
+		Order("currency, effective_date DESC").
+		Find(&rates).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// SaveResolution inserts r, or loads the identical resolution already recorded for the task.
+func (s *GormStore) SaveResolution(ctx context.Context, r *Resolution) (*Resolution, error) {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	r.CreatedAt = time.Now().UTC()
+	result := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}, {Name: "fingerprint"}},
+			DoNothing: true,
+		}).
+		Create(r)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to save fee resolution: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return r, nil
+	}
+
+	var existing Resolution
+	if err := s.db.WithContext(ctx).Where("task_id = ? AND fingerprint = ?", r.TaskID, r.Fingerprint).First(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to load fee resolution: %w", err)
+	}
+	return &existing, nil
+}
+
+// GetResolution loads a recorded resolution.
+func (s *GormStore) GetResolution(ctx context.Context, id string) (*Resolution, error) {
+	var r Resolution
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&r).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrResolutionNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to load fee resolution: %w", err)
+	}
+	return &r, nil
+}
+
+// ListResolutions loads the resolutions recorded for a task.
+func (s *GormStore) ListResolutions(ctx context.Context, taskID string) ([]Resolution, error) {
+	var resolutions []Resolution
+	if err := s.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at ASC").Find(&resolutions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list fee resolutions: %w", err)
+	}
+	return resolutions, nil
+}
@@ -0,0 +1,104 @@
+package feeschedule
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB, mock
+}
+
+func TestGormStore_PublishSchedule(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormStore(db)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(version), 0) FROM "fee_schedules" WHERE id = $1`)).
+		WithArgs("customs").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(2))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "fee_schedules"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	schedule := &Schedule{ID: "customs", Name: "Customs fees", Currency: "LKR", EffectiveFrom: time.Now()}
+	require.NoError(t, store.PublishSchedule(context.Background(), schedule))
+	assert.Equal(t, 3, schedule.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGormStore_RatesAt(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormStore(db)
+	require.NoError(t, err)
+
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT ON (currency) * FROM "exchange_rates" WHERE currency IN ($1,$2) AND effective_date <= $3 ORDER BY currency, effective_date DESC`)).
+		WithArgs("EUR", "USD", at).
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "effective_date", "rate"}).
+			AddRow("EUR", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), "330.00000000").
+			AddRow("USD", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), "300.00000000"))
+
+	rates, err := store.RatesAt(context.Background(), []string{"EUR", "USD"}, at)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "300", rates[1].Rate.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGormStore_SaveResolution(t *testing.T) {
+	insert := regexp.QuoteMeta(`INSERT INTO "fee_resolutions"`) + `.*` +
+		regexp.QuoteMeta(`ON CONFLICT ("task_id","fingerprint") DO NOTHING`)
+
+	t.Run("records a new resolution", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		store, err := NewGormStore(db)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		r := &Resolution{TaskID: "task-1", ScheduleID: "customs", ScheduleVersion: 1, Currency: "LKR", Fingerprint: "abc"}
+		saved, err := store.SaveResolution(context.Background(), r)
+		require.NoError(t, err)
+		assert.Same(t, r, saved)
+		assert.NotEmpty(t, saved.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returns the resolution already recorded", func(t *testing.T) {
+		db, mock := setupTestDB(t)
+		store, err := NewGormStore(db)
+		require.NoError(t, err)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "fee_resolutions" WHERE task_id = $1 AND fingerprint = $2`)).
+			WithArgs("task-1", "abc", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "fingerprint", "total"}).AddRow("res-1", "task-1", "abc", "3000.00"))
+
+		saved, err := store.SaveResolution(context.Background(), &Resolution{TaskID: "task-1", Fingerprint: "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, "res-1", saved.ID)
+		assert.Equal(t, "3000", saved.Total.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,61 @@
+package form
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+// UploadPolicies resolves the upload limits of a form's file fields. A file field is a schema
+// property with "format": "file"; it sets its limits with the "x-upload" keyword, e.g.
+//
+//	"x-upload": {"maxSizeBytes": 5242880, "contentTypes": ["application/pdf"]}
+//
+// A file field without the keyword accepts anything the storage service accepts.
+type UploadPolicies struct {
+	forms FormService
+}
+
+// NewUploadPolicies creates a storage.PolicyResolver backed by the form definitions.
+func NewUploadPolicies(forms FormService) *UploadPolicies {
+	return &UploadPolicies{forms: forms}
+}
+
+// schemaProperty is the part of a JSON Schema property that describes file fields.
+type schemaProperty struct {
+	Format     string                    `json:"format"`
+	Properties map[string]schemaProperty `json:"properties"`
+	Upload     *storage.UploadPolicy     `json:"x-upload"`
+}
+
+// UploadPolicy returns the policy of the file field at field, a dot-separated path through
+// nested object properties such as "shipment.invoice".
+func (p *UploadPolicies) UploadPolicy(ctx context.Context, formID, field string) (*storage.UploadPolicy, error) {
+	form, err := p.forms.GetFormByID(ctx, formID)
+	if err != nil {
+		if errors.Is(err, ErrFormNotFound) {
+			return nil, fmt.Errorf("%w: %w", storage.ErrPolicyNotFound, err)
+		}
+		return nil, err
+	}
+
+	var node schemaProperty
+	if err := json.Unmarshal(form.Schema, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse schema of form %s: %w", formID, err)
+	}
+	for _, name := range strings.Split(field, ".") {
+		child, ok := node.Properties[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: form %s has no field %s", storage.ErrPolicyNotFound, formID, field)
+		}
+		node = child
+	}
+	if node.Format != "file" {
+		return nil, fmt.Errorf("%w: field %s of form %s is not a file field", storage.ErrPolicyNotFound, field, formID)
+	}
+	return node.Upload, nil
+}
@@ -0,0 +1,65 @@
+package form
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	formmodel "github.com/OpenNSW/nsw/internal/form/model"
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+type stubFormService struct {
+	forms map[string]*formmodel.FormResponse
+}
+
+func (s stubFormService) GetFormByID(_ context.Context, formID string) (*formmodel.FormResponse, error) {
+	form, ok := s.forms[formID]
+	if !ok {
+		return nil, fmt.Errorf("form with ID %s not found: %w", formID, ErrFormNotFound)
+	}
+	return form, nil
+}
+
+func TestUploadPolicies_UploadPolicy(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"invoice": {"type": "string", "format": "file", "x-upload": {"maxSizeBytes": 1024, "contentTypes": ["application/pdf"]}},
+			"photo": {"type": "string", "format": "file"},
+			"vessel": {"type": "string"},
+			"shipment": {"type": "object", "properties": {
+				"manifest": {"type": "string", "format": "file", "x-upload": {"contentTypes": ["image/png"]}}
+			}}
+		}
+	}`)
+	policies := NewUploadPolicies(stubFormService{forms: map[string]*formmodel.FormResponse{
+		"form-1": {ID: "form-1", Schema: schema},
+	}})
+	ctx := context.Background()
+
+	policy, err := policies.UploadPolicy(ctx, "form-1", "invoice")
+	require.NoError(t, err)
+	assert.Equal(t, &storage.UploadPolicy{MaxSizeBytes: 1024, ContentTypes: []string{"application/pdf"}}, policy)
+
+	policy, err = policies.UploadPolicy(ctx, "form-1", "shipment.manifest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image/png"}, policy.ContentTypes)
+
+	policy, err = policies.UploadPolicy(ctx, "form-1", "photo")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	for _, tt := range []struct{ formID, field string }{
+		{"form-1", "vessel"},
+		{"form-1", "missing"},
+		{"form-2", "invoice"},
+	} {
+		_, err := policies.UploadPolicy(ctx, tt.formID, tt.field)
+		assert.ErrorIs(t, err, storage.ErrPolicyNotFound, "%s/%s", tt.formID, tt.field)
+	}
+}
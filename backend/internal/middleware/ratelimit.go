@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit creates a middleware that allows each client up to limit requests per window and
+// answers 429 Too Many Requests beyond that. Allowance is regained gradually over the window.
+// Clients are told apart by remote address or, behind a trusted proxy, by the first address
+// in X-Forwarded-For.
+func RateLimit(limit int, window time.Duration, trustForwardedFor bool) func(http.Handler) http.Handler {
+	limiter := newRateLimiter(limit, window)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := clientAddress(r, trustForwardedFor)
+			if ok, retryAfter := limiter.allow(client); !ok {
+				slog.WarnContext(r.Context(), "rate limit exceeded",
+					"client", client,
+					"method", r.Method,
+					"path", r.URL.Path)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimiter keeps a token bucket per client. Buckets that have refilled are dropped once
+// per window so that the map does not grow with every client ever seen.
+type rateLimiter struct {
+	mu        sync.Mutex
+	capacity  float64
+	perSecond float64
+	window    time.Duration
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		capacity:  float64(limit),
+		perSecond: float64(limit) / window.Seconds(),
+		window:    window,
+		buckets:   make(map[string]*tokenBucket),
+		now:       time.Now,
+	}
+}
+
+// allow takes a token from the client's bucket. When none is left it returns false and how
+// long until one is.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= l.window {
+		for key, b := range l.buckets {
+			if now.Sub(b.updated) >= l.window {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: l.capacity, updated: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.capacity, b.tokens+now.Sub(b.updated).Seconds()*l.perSecond)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+func clientAddress(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
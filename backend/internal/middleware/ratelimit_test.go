@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	ok, _ := l.allow("a")
+	assert.True(t, ok)
+	ok, _ = l.allow("a")
+	assert.True(t, ok)
+	ok, retryAfter := l.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	ok, _ = l.allow("b")
+	assert.True(t, ok, "clients are limited separately")
+
+	now = now.Add(30 * time.Second)
+	ok, _ = l.allow("a")
+	assert.True(t, ok, "allowance is regained over the window")
+
+	now = now.Add(2 * time.Minute)
+	l.allow("c")
+	assert.NotContains(t, l.buckets, "b", "idle buckets are dropped")
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := RateLimit(1, time.Minute, true)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/verify/ABCD-EFGH-JKMN", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request("203.0.113.7, 10.0.0.1").Code)
+	rec := request("203.0.113.7")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, request("198.51.100.2").Code)
+}
@@ -0,0 +1,178 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/OpenNSW/nsw/pkg/notification"
+)
+
+// Deliverer sends notifications synchronously; satisfied by notification.Manager.
+type Deliverer interface {
+	DeliverEmail(ctx context.Context, payload notification.EmailPayload) (map[string]error, error)
+	DeliverSMS(ctx context.Context, payload notification.SMSPayload) (map[string]error, error)
+}
+
+// DispatcherConfig controls how often the outbox is polled and how failed deliveries are retried.
+type DispatcherConfig struct {
+	PollInterval time.Duration // How often due messages are claimed
+	BatchSize    int           // Maximum messages claimed per poll
+	MaxAttempts  int           // Attempts after which a message is dead-lettered
+	BaseBackoff  time.Duration // Delay before the second attempt; doubled for each further attempt
+	MaxBackoff   time.Duration // Upper bound on the delay between attempts
+	SendTimeout  time.Duration // Timeout for a single delivery attempt
+}
+
+func (c DispatcherConfig) withDefaults() DispatcherConfig {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 5 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 50
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 8
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 30 * time.Second
+	}
+	if c.MaxBackoff < c.BaseBackoff {
+		c.MaxBackoff = max(time.Hour, c.BaseBackoff)
+	}
+	if c.SendTimeout <= 0 {
+		c.SendTimeout = 30 * time.Second
+	}
+	return c
+}
+
+// Dispatcher delivers outbox messages in the background, retrying failures with exponential
+// backoff and dead-lettering messages that exhaust their attempts.
+type Dispatcher struct {
+	outbox    Outbox
+	deliverer Deliverer
+	config    DispatcherConfig
+	now       func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewDispatcher creates a Dispatcher. Zero config values are replaced by defaults.
+func NewDispatcher(outbox Outbox, deliverer Deliverer, config DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		outbox:    outbox,
+		deliverer: deliverer,
+		config:    config.withDefaults(),
+		now:       func() time.Time { return time.Now().UTC() },
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start polls the outbox until Stop is called.
+func (d *Dispatcher) Start() {
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				if _, err := d.DispatchDue(context.Background()); err != nil {
+					slog.Error("notifier: failed to dispatch notifications", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for the in-flight batch to finish. It must only be called after Start.
+func (d *Dispatcher) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+	<-d.done
+}
+
+// DispatchDue claims and delivers one batch of due messages, returning how many were claimed.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	// Claimed messages stay hidden from other dispatchers for longer than a batch can take to send.
+	lease := d.config.SendTimeout*time.Duration(d.config.BatchSize) + d.config.PollInterval
+	messages, err := d.outbox.ClaimDue(ctx, d.now(), lease, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i := range messages {
+		d.deliver(ctx, &messages[i])
+	}
+	return len(messages), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, message *OutboxMessage) {
+	sendCtx, cancel := context.WithTimeout(ctx, d.config.SendTimeout)
+	err := d.send(sendCtx, message)
+	cancel()
+
+	attempts := message.Attempts + 1
+	now := d.now()
+	if err == nil {
+		if err := d.outbox.MarkSent(ctx, message.ID, attempts, now); err != nil {
+			slog.Error("notifier: failed to mark notification sent", "id", message.ID, "error", err)
+		}
+		return
+	}
+
+	// An unregistered channel will not start working by retrying.
+	deadLetter := attempts >= d.config.MaxAttempts || errors.Is(err, notification.ErrChannelNotRegistered)
+	if deadLetter {
+		slog.Error("notifier: notification dead-lettered",
+			"id", message.ID, "event", message.Event, "channel", message.Channel,
+			"attempts", attempts, "error", err)
+	} else {
+		slog.Warn("notifier: notification delivery failed, will retry",
+			"id", message.ID, "channel", message.Channel, "attempts", attempts, "error", err)
+	}
+	if err := d.outbox.MarkFailed(ctx, message.ID, attempts, err.Error(), now.Add(d.backoff(attempts)), deadLetter); err != nil {
+		slog.Error("notifier: failed to record notification failure", "id", message.ID, "error", err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, message *OutboxMessage) error {
+	base := notification.BasePayload{
+		TemplateID:   message.TemplateID,
+		TemplateData: message.TemplateData,
+		Metadata:     map[string]string{"event": string(message.Event), "outboxId": message.ID},
+	}
+	recipients := []string{message.Recipient}
+
+	var results map[string]error
+	var err error
+	switch message.Channel {
+	case ChannelEmail:
+		results, err = d.deliverer.DeliverEmail(ctx, notification.EmailPayload{BasePayload: base, Recipients: recipients})
+	case ChannelSMS:
+		results, err = d.deliverer.DeliverSMS(ctx, notification.SMSPayload{BasePayload: base, Recipients: recipients})
+	default:
+		return notification.ErrChannelNotRegistered
+	}
+	if err != nil {
+		return err
+	}
+	return results[message.Recipient]
+}
+
+// backoff returns the delay before the attempt following the given number of attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return delay
+}
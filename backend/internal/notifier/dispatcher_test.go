@@ -0,0 +1,183 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/pkg/notification"
+)
+
+// memoryOutbox is an in-memory Outbox for dispatcher tests.
+type memoryOutbox struct {
+	messages map[string]*OutboxMessage
+}
+
+func newMemoryOutbox(messages ...OutboxMessage) *memoryOutbox {
+	o := &memoryOutbox{messages: make(map[string]*OutboxMessage)}
+	for i := range messages {
+		message := messages[i]
+		message.Status = DeliveryPending
+		o.messages[message.ID] = &message
+	}
+	return o
+}
+
+func (o *memoryOutbox) Enqueue(_ context.Context, message *OutboxMessage) error {
+	o.messages[message.ID] = message
+	return nil
+}
+
+func (o *memoryOutbox) ClaimDue(_ context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error) {
+	var due []OutboxMessage
+	for _, message := range o.messages {
+		if len(due) < limit && message.Status == DeliveryPending && !message.NextAttemptAt.After(now) {
+			message.NextAttemptAt = now.Add(lease)
+			due = append(due, *message)
+		}
+	}
+	return due, nil
+}
+
+func (o *memoryOutbox) MarkSent(_ context.Context, id string, attempts int, sentAt time.Time) error {
+	message := o.messages[id]
+	message.Status = DeliverySent
+	message.Attempts = attempts
+	message.SentAt = &sentAt
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(_ context.Context, id string, attempts int, lastError string, nextAttemptAt time.Time, deadLetter bool) error {
+	message := o.messages[id]
+	message.Attempts = attempts
+	message.LastError = lastError
+	message.NextAttemptAt = nextAttemptAt
+	if deadLetter {
+		message.Status = DeliveryDeadLetter
+	}
+	return nil
+}
+
+func (o *memoryOutbox) ListByWorkflowID(_ context.Context, _ string) ([]OutboxMessage, error) {
+	return nil, nil
+}
+
+// scriptedDeliverer fails each recipient with the queued errors before succeeding.
+type scriptedDeliverer struct {
+	failures map[string][]error
+	sms      error
+	sent     []string
+}
+
+func (d *scriptedDeliverer) result(recipient string) map[string]error {
+	if queued := d.failures[recipient]; len(queued) > 0 {
+		d.failures[recipient] = queued[1:]
+		return map[string]error{recipient: queued[0]}
+	}
+	d.sent = append(d.sent, recipient)
+	return map[string]error{recipient: nil}
+}
+
+func (d *scriptedDeliverer) DeliverEmail(_ context.Context, payload notification.EmailPayload) (map[string]error, error) {
+	return d.result(payload.Recipients[0]), nil
+}
+
+func (d *scriptedDeliverer) DeliverSMS(_ context.Context, payload notification.SMSPayload) (map[string]error, error) {
+	if d.sms != nil {
+		return nil, d.sms
+	}
+	return d.result(payload.Recipients[0]), nil
+}
+
+func newTestDispatcher(outbox Outbox, deliverer Deliverer, clock *time.Time) *Dispatcher {
+	d := NewDispatcher(outbox, deliverer, DispatcherConfig{
+		MaxAttempts: 3,
+		BaseBackoff: time.Minute,
+		MaxBackoff:  90 * time.Second,
+	})
+	d.now = func() time.Time { return *clock }
+	return d
+}
+
+func TestDispatcher_DispatchDue(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	smtpDown := errors.New("smtp unavailable")
+
+	t.Run("delivers due messages", func(t *testing.T) {
+		clock := start
+		outbox := newMemoryOutbox(OutboxMessage{ID: "m1", Channel: ChannelEmail, Recipient: "trader@example.com", NextAttemptAt: start})
+		deliverer := &scriptedDeliverer{}
+		d := newTestDispatcher(outbox, deliverer, &clock)
+
+		n, err := d.DispatchDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, DeliverySent, outbox.messages["m1"].Status)
+		assert.Equal(t, 1, outbox.messages["m1"].Attempts)
+		assert.Equal(t, []string{"trader@example.com"}, deliverer.sent)
+
+		// Sent messages are not delivered again.
+		n, err = d.DispatchDue(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("retries with exponential backoff then dead-letters", func(t *testing.T) {
+		clock := start
+		outbox := newMemoryOutbox(OutboxMessage{ID: "m1", Channel: ChannelEmail, Recipient: "trader@example.com", NextAttemptAt: start})
+		deliverer := &scriptedDeliverer{failures: map[string][]error{"trader@example.com": {smtpDown, smtpDown, smtpDown}}}
+		d := newTestDispatcher(outbox, deliverer, &clock)
+		message := outbox.messages["m1"]
+
+		_, err := d.DispatchDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, DeliveryPending, message.Status)
+		assert.Equal(t, start.Add(time.Minute), message.NextAttemptAt)
+		assert.Equal(t, "smtp unavailable", message.LastError)
+
+		// Not due yet.
+		n, _ := d.DispatchDue(context.Background())
+		assert.Zero(t, n)
+
+		clock = start.Add(time.Minute)
+		_, err = d.DispatchDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, clock.Add(90*time.Second), message.NextAttemptAt, "backoff is capped at MaxBackoff")
+
+		clock = clock.Add(90 * time.Second)
+		_, err = d.DispatchDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, DeliveryDeadLetter, message.Status)
+		assert.Equal(t, 3, message.Attempts)
+		assert.Empty(t, deliverer.sent)
+	})
+
+	t.Run("recovers after a transient failure", func(t *testing.T) {
+		clock := start
+		outbox := newMemoryOutbox(OutboxMessage{ID: "m1", Channel: ChannelEmail, Recipient: "trader@example.com", NextAttemptAt: start})
+		deliverer := &scriptedDeliverer{failures: map[string][]error{"trader@example.com": {smtpDown}}}
+		d := newTestDispatcher(outbox, deliverer, &clock)
+
+		_, _ = d.DispatchDue(context.Background())
+		clock = start.Add(time.Minute)
+		_, _ = d.DispatchDue(context.Background())
+
+		assert.Equal(t, DeliverySent, outbox.messages["m1"].Status)
+		assert.Equal(t, 2, outbox.messages["m1"].Attempts)
+	})
+
+	t.Run("unregistered channel dead-letters immediately", func(t *testing.T) {
+		clock := start
+		outbox := newMemoryOutbox(OutboxMessage{ID: "m1", Channel: ChannelSMS, Recipient: "+94770000000", NextAttemptAt: start})
+		d := newTestDispatcher(outbox, &scriptedDeliverer{sms: notification.ErrChannelNotRegistered}, &clock)
+
+		_, err := d.DispatchDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, DeliveryDeadLetter, outbox.messages["m1"].Status)
+		assert.Equal(t, 1, outbox.messages["m1"].Attempts)
+	})
+}
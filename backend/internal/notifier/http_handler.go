@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+)
+
+// WorkflowAuthorizer decides whether the caller may view a workflow's notifications.
+type WorkflowAuthorizer interface {
+	AuthorizeWorkflowRead(ctx context.Context, workflowID string) error
+}
+
+// HTTPHandler exposes notification delivery status.
+type HTTPHandler struct {
+	outbox     Outbox
+	authorizer WorkflowAuthorizer
+}
+
+// NewHTTPHandler creates a new HTTPHandler over the outbox.
+func NewHTTPHandler(outbox Outbox, authorizer WorkflowAuthorizer) *HTTPHandler {
+	return &HTTPHandler{outbox: outbox, authorizer: authorizer}
+}
+
+// HandleListNotifications lists the notifications of a workflow with their delivery status.
+// GET /api/v1/notifications?workflowId=...
+func (h *HTTPHandler) HandleListNotifications(w http.ResponseWriter, r *http.Request) {
+	workflowID := r.URL.Query().Get("workflowId")
+	if workflowID == "" {
+		http.Error(w, "workflowId is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authorizer.AuthorizeWorkflowRead(r.Context(), workflowID); err != nil {
+		switch {
+		case errors.Is(err, authz.ErrUnauthenticated):
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		case errors.Is(err, authz.ErrForbidden):
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			slog.ErrorContext(r.Context(), "failed to authorize notification access", "error", err)
+			http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	messages, err := h.outbox.ListByWorkflowID(r.Context(), workflowID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list notifications", "workflowID", workflowID, "error", err)
+		http.Error(w, "failed to list notifications", http.StatusInternalServerError)
+		return
+	}
+	if messages == nil {
+		messages = []OutboxMessage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(messages); err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+	}
+}
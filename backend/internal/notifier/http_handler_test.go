@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+)
+
+type stubAuthorizer struct {
+	err error
+}
+
+func (s stubAuthorizer) AuthorizeWorkflowRead(_ context.Context, _ string) error {
+	return s.err
+}
+
+type listOutbox struct {
+	Outbox
+	messages []OutboxMessage
+}
+
+func (o listOutbox) ListByWorkflowID(_ context.Context, _ string) ([]OutboxMessage, error) {
+	return o.messages, nil
+}
+
+func TestHTTPHandler_HandleListNotifications(t *testing.T) {
+	outbox := listOutbox{messages: []OutboxMessage{
+		{ID: "m1", WorkflowID: "c-1", Event: EventOGAFeedbackRequested, Channel: ChannelEmail, Recipient: "trader@example.com", Status: DeliverySent, Attempts: 1},
+	}}
+
+	t.Run("lists delivery status", func(t *testing.T) {
+		h := NewHTTPHandler(outbox, stubAuthorizer{})
+		rec := httptest.NewRecorder()
+		h.HandleListNotifications(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications?workflowId=c-1", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var got []OutboxMessage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Len(t, got, 1)
+		assert.Equal(t, DeliverySent, got[0].Status)
+	})
+
+	t.Run("missing workflow ID", func(t *testing.T) {
+		h := NewHTTPHandler(outbox, stubAuthorizer{})
+		rec := httptest.NewRecorder()
+		h.HandleListNotifications(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		h := NewHTTPHandler(outbox, stubAuthorizer{err: authz.ErrForbidden})
+		rec := httptest.NewRecorder()
+		h.HandleListNotifications(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications?workflowId=c-1", nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
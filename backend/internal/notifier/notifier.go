@@ -0,0 +1,208 @@
+// Package notifier turns workflow and task events into email and SMS notifications.
+// Which events notify whom, over which channel and with which template is configured
+// by rules; recipients are resolved from the trader and CHA profiles of the workflow.
+// Notifications are written to a durable outbox and delivered by the Dispatcher.
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/profile/cha"
+	"github.com/OpenNSW/nsw/internal/profile/user"
+	taskmanager "github.com/OpenNSW/nsw/internal/task/manager"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+)
+
+// WorkflowSource is a domain that owns workflows, together with the event raised when
+// one of its workflows completes.
+type WorkflowSource struct {
+	Completed EventType
+	Parties   authz.PartyResolver
+}
+
+// Notifier enqueues the notifications configured by its rules.
+type Notifier struct {
+	outbox  Outbox
+	users   user.Service
+	chas    cha.Service
+	rules   map[EventType][]Rule
+	sources []WorkflowSource
+}
+
+// New creates a Notifier. Sources are consulted in order to find the parties of a workflow.
+func New(outbox Outbox, users user.Service, chas cha.Service, rules []Rule, sources ...WorkflowSource) *Notifier {
+	byEvent := make(map[EventType][]Rule)
+	for _, rule := range rules {
+		byEvent[rule.Event] = append(byEvent[rule.Event], rule)
+	}
+	return &Notifier{
+		outbox:  outbox,
+		users:   users,
+		chas:    chas,
+		rules:   byEvent,
+		sources: sources,
+	}
+}
+
+// HandleTaskTransition is a taskmanager.TransitionListener that notifies on task events.
+func (n *Notifier) HandleTaskTransition(ctx context.Context, transition taskmanager.TaskTransition) {
+	event, ok := taskEvent(transition)
+	if !ok || len(n.rules[event]) == 0 {
+		return
+	}
+	workflowID := transition.Event.WorkflowID
+	parties, _, err := n.workflowParties(ctx, workflowID)
+	if err != nil {
+		slog.ErrorContext(ctx, "notifier: failed to resolve workflow parties",
+			"event", event, "workflowID", workflowID, "error", err)
+		return
+	}
+	if parties == nil {
+		slog.WarnContext(ctx, "notifier: no source owns workflow, skipping notification",
+			"event", event, "workflowID", workflowID)
+		return
+	}
+	// Event IDs are assigned when the transition is audited; fall back to the transition itself.
+	eventKey := transition.Event.ID
+	if eventKey == "" {
+		eventKey = fmt.Sprintf("%s:%s:%s", transition.Event.TaskID, transition.Event.Action, transition.Event.ToPluginState)
+	}
+	n.notify(ctx, event, eventKey, workflowID, parties, map[string]interface{}{
+		"Event":       string(event),
+		"WorkflowID":  workflowID,
+		"TaskID":      transition.Event.TaskID,
+		"TaskType":    string(transition.TaskType),
+		"Action":      transition.Event.Action,
+		"PluginState": transition.Event.ToPluginState,
+	})
+}
+
+// HandleWorkflowCompleted is a workflow runtime CompletionListener that notifies the parties
+// of a completed workflow.
+func (n *Notifier) HandleWorkflowCompleted(ctx context.Context, workflowID string) {
+	parties, source, err := n.workflowParties(ctx, workflowID)
+	if err != nil {
+		slog.ErrorContext(ctx, "notifier: failed to resolve workflow parties",
+			"workflowID", workflowID, "error", err)
+		return
+	}
+	if parties == nil {
+		return
+	}
+	// A workflow completes once, so the workflow ID identifies the event.
+	n.notify(ctx, source.Completed, workflowID, workflowID, parties, map[string]interface{}{
+		"Event":      string(source.Completed),
+		"WorkflowID": workflowID,
+	})
+}
+
+// taskEvent classifies a task transition, reporting false if it is not a notifiable event.
+func taskEvent(transition taskmanager.TaskTransition) (EventType, bool) {
+	switch transition.TaskType {
+	case plugin.TaskTypeSimpleForm:
+		if transition.Event.ToPluginState == string(plugin.OGAFeedbackProvided) {
+			return EventOGAFeedbackRequested, true
+		}
+	case plugin.TaskTypePayment:
+		if transition.Event.Action == plugin.PaymentActionSuccess {
+			return EventPaymentSucceeded, true
+		}
+	}
+	return "", false
+}
+
+func (n *Notifier) workflowParties(ctx context.Context, workflowID string) (*authz.Parties, WorkflowSource, error) {
+	for _, source := range n.sources {
+		parties, err := source.Parties.WorkflowParties(ctx, workflowID)
+		if err != nil {
+			return nil, WorkflowSource{}, err
+		}
+		if parties != nil {
+			return parties, source, nil
+		}
+	}
+	return nil, WorkflowSource{}, nil
+}
+
+// contact holds the addresses a party can be reached at; either may be empty.
+type contact struct {
+	email string
+	phone string
+}
+
+// notify enqueues one outbox message per rule, channel and recipient of the event.
+// eventKey identifies the occurrence of the event and makes enqueueing idempotent.
+func (n *Notifier) notify(ctx context.Context, event EventType, eventKey, workflowID string, parties *authz.Parties, data map[string]interface{}) {
+	contacts := make(map[Recipient]contact)
+	for _, rule := range n.rules[event] {
+		var emails, phones []string
+		for _, recipient := range rule.Recipients {
+			c, ok := contacts[recipient]
+			if !ok {
+				c = n.resolveContact(ctx, recipient, parties)
+				contacts[recipient] = c
+			}
+			if c.email != "" {
+				emails = append(emails, c.email)
+			}
+			if c.phone != "" {
+				phones = append(phones, c.phone)
+			}
+		}
+
+		for _, channel := range rule.Channels {
+			addresses := emails
+			if channel == ChannelSMS {
+				addresses = phones
+			}
+			for _, address := range addresses {
+				message := &OutboxMessage{
+					IdempotencyKey: fmt.Sprintf("%s/%s/%s/%s/%s", event, eventKey, rule.Template, channel, address),
+					Event:          event,
+					WorkflowID:     workflowID,
+					Channel:        channel,
+					Recipient:      address,
+					TemplateID:     rule.Template,
+					TemplateData:   data,
+				}
+				if err := n.outbox.Enqueue(ctx, message); err != nil {
+					slog.ErrorContext(ctx, "notifier: failed to enqueue notification",
+						"event", event, "workflowID", workflowID, "channel", channel, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// resolveContact looks up a party's profile. Lookup failures are logged and yield an empty contact
+// so that the remaining recipients are still notified.
+func (n *Notifier) resolveContact(ctx context.Context, recipient Recipient, parties *authz.Parties) contact {
+	switch recipient {
+	case RecipientTrader:
+		if parties.TraderID == "" {
+			return contact{}
+		}
+		record, err := n.users.GetUser(parties.TraderID)
+		if err != nil || record == nil {
+			slog.WarnContext(ctx, "notifier: failed to resolve trader profile",
+				"traderID", parties.TraderID, "error", err)
+			return contact{}
+		}
+		return contact{email: record.Email, phone: record.PhoneNumber}
+	case RecipientCHA:
+		if parties.CHAID == "" {
+			return contact{}
+		}
+		record, err := n.chas.GetByID(ctx, parties.CHAID)
+		if err != nil {
+			slog.WarnContext(ctx, "notifier: failed to resolve CHA profile",
+				"chaID", parties.CHAID, "error", err)
+			return contact{}
+		}
+		return contact{email: record.Email}
+	}
+	return contact{}
+}
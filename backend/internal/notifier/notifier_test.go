@@ -0,0 +1,213 @@
+package notifier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/profile/cha"
+	"github.com/OpenNSW/nsw/internal/profile/user"
+	taskmanager "github.com/OpenNSW/nsw/internal/task/manager"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+)
+
+// recordingOutbox keeps enqueued messages in memory, ignoring duplicate idempotency keys.
+type recordingOutbox struct {
+	Outbox
+	messages []OutboxMessage
+}
+
+func (o *recordingOutbox) Enqueue(_ context.Context, message *OutboxMessage) error {
+	for _, existing := range o.messages {
+		if existing.IdempotencyKey == message.IdempotencyKey {
+			return nil
+		}
+	}
+	o.messages = append(o.messages, *message)
+	return nil
+}
+
+func (o *recordingOutbox) byChannel(channel Channel) []OutboxMessage {
+	var messages []OutboxMessage
+	for _, message := range o.messages {
+		if message.Channel == channel {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func recipients(messages []OutboxMessage) []string {
+	out := make([]string, len(messages))
+	for i, message := range messages {
+		out[i] = message.Recipient
+	}
+	return out
+}
+
+type fakeUsers struct {
+	user.Service
+	records map[string]*user.Record
+}
+
+func (f *fakeUsers) GetUser(id string) (*user.Record, error) {
+	return f.records[id], nil
+}
+
+type fakeCHAs struct {
+	cha.Service
+	records map[string]*cha.Record
+}
+
+func (f *fakeCHAs) GetByID(_ context.Context, id string) (*cha.Record, error) {
+	if record, ok := f.records[id]; ok {
+		return record, nil
+	}
+	return nil, cha.ErrCHANotFound
+}
+
+type fakeResolver map[string]*authz.Parties
+
+func (f fakeResolver) WorkflowParties(_ context.Context, workflowID string) (*authz.Parties, error) {
+	return f[workflowID], nil
+}
+
+func newTestNotifier(t *testing.T, rules []Rule) (*Notifier, *recordingOutbox) {
+	t.Helper()
+	outbox := &recordingOutbox{}
+	users := &fakeUsers{records: map[string]*user.Record{
+		"trader-1": {ID: "trader-1", Email: "trader@example.com", PhoneNumber: "+94770000000"},
+	}}
+	chas := &fakeCHAs{records: map[string]*cha.Record{
+		"cha-1": {ID: "cha-1", Email: "cha@example.com"},
+	}}
+	return New(outbox, users, chas, rules,
+		WorkflowSource{Completed: EventConsignmentFinished, Parties: fakeResolver{"c-1": {TraderID: "trader-1", CHAID: "cha-1"}}},
+		WorkflowSource{Completed: EventPreConsignmentCompleted, Parties: fakeResolver{"pc-1": {TraderID: "trader-1"}}},
+	), outbox
+}
+
+func TestNotifier_HandleTaskTransition(t *testing.T) {
+	rules := []Rule{
+		{Event: EventOGAFeedbackRequested, Recipients: []Recipient{RecipientTrader, RecipientCHA}, Channels: []Channel{ChannelEmail, ChannelSMS}, Template: "oga_feedback_requested"},
+		{Event: EventPaymentSucceeded, Recipients: []Recipient{RecipientTrader}, Channels: []Channel{ChannelEmail}, Template: "payment_succeeded"},
+	}
+
+	feedback := taskmanager.TaskTransition{
+		TaskType: plugin.TaskTypeSimpleForm,
+		Event: persistence.TaskEvent{
+			ID: "event-1", TaskID: "task-1", WorkflowID: "c-1",
+			Action: plugin.SimpleFormActionOgaFeedback, ToPluginState: string(plugin.OGAFeedbackProvided),
+		},
+	}
+
+	t.Run("OGA feedback notifies trader and CHA", func(t *testing.T) {
+		n, outbox := newTestNotifier(t, rules)
+		n.HandleTaskTransition(context.Background(), feedback)
+
+		emails := outbox.byChannel(ChannelEmail)
+		assert.Equal(t, []string{"trader@example.com", "cha@example.com"}, recipients(emails))
+		assert.Equal(t, "oga_feedback_requested", emails[0].TemplateID)
+		assert.Equal(t, "c-1", emails[0].WorkflowID)
+		assert.Equal(t, "task-1", emails[0].TemplateData["TaskID"])
+		assert.Equal(t, []string{"+94770000000"}, recipients(outbox.byChannel(ChannelSMS)))
+	})
+
+	t.Run("redelivered event is enqueued once", func(t *testing.T) {
+		n, outbox := newTestNotifier(t, rules)
+		n.HandleTaskTransition(context.Background(), feedback)
+		n.HandleTaskTransition(context.Background(), feedback)
+
+		assert.Len(t, outbox.messages, 3)
+	})
+
+	t.Run("payment success notifies trader by email", func(t *testing.T) {
+		n, outbox := newTestNotifier(t, rules)
+		n.HandleTaskTransition(context.Background(), taskmanager.TaskTransition{
+			TaskType: plugin.TaskTypePayment,
+			Event:    persistence.TaskEvent{TaskID: "task-2", WorkflowID: "pc-1", Action: plugin.PaymentActionSuccess},
+		})
+
+		assert.Equal(t, []string{"trader@example.com"}, recipients(outbox.byChannel(ChannelEmail)))
+		assert.Empty(t, outbox.byChannel(ChannelSMS))
+	})
+
+	t.Run("other transitions are ignored", func(t *testing.T) {
+		n, outbox := newTestNotifier(t, rules)
+		n.HandleTaskTransition(context.Background(), taskmanager.TaskTransition{
+			TaskType: plugin.TaskTypeSimpleForm,
+			Event:    persistence.TaskEvent{TaskID: "task-1", WorkflowID: "c-1", Action: plugin.SimpleFormActionSubmit},
+		})
+
+		assert.Empty(t, outbox.messages)
+	})
+
+	t.Run("unknown workflow is skipped", func(t *testing.T) {
+		n, outbox := newTestNotifier(t, rules)
+		n.HandleTaskTransition(context.Background(), taskmanager.TaskTransition{
+			TaskType: plugin.TaskTypePayment,
+			Event:    persistence.TaskEvent{TaskID: "task-3", WorkflowID: "unknown", Action: plugin.PaymentActionSuccess},
+		})
+
+		assert.Empty(t, outbox.messages)
+	})
+}
+
+func TestNotifier_HandleWorkflowCompleted(t *testing.T) {
+	rules := []Rule{
+		{Event: EventConsignmentFinished, Recipients: []Recipient{RecipientTrader, RecipientCHA}, Channels: []Channel{ChannelEmail}, Template: "consignment_finished"},
+	}
+	n, outbox := newTestNotifier(t, rules)
+
+	n.HandleWorkflowCompleted(context.Background(), "c-1")
+	n.HandleWorkflowCompleted(context.Background(), "pc-1") // no rule for pre-consignments
+
+	require.Len(t, outbox.messages, 2)
+	assert.Equal(t, "consignment_finished", outbox.messages[0].TemplateID)
+	assert.Equal(t, []string{"trader@example.com", "cha@example.com"}, recipients(outbox.messages))
+	assert.Equal(t, "c-1", outbox.messages[0].TemplateData["WorkflowID"])
+}
+
+func TestLoadRules(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		rules, err := LoadRules(write(t, `{"rules":[
+			{"event":"PAYMENT_SUCCEEDED","recipients":["TRADER"],"channels":["EMAIL","SMS"],"template":"payment_succeeded"}
+		]}`))
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, EventPaymentSucceeded, rules[0].Event)
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		_, err := LoadRules(write(t, `{"rules":[{"event":"NOPE","recipients":["TRADER"],"channels":["EMAIL"],"template":"x"}]}`))
+		assert.ErrorContains(t, err, `unknown event "NOPE"`)
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		_, err := LoadRules(write(t, `{"rules":[{"event":"PAYMENT_SUCCEEDED","recipients":["TRADER"],"channels":["FAX"],"template":"x"}]}`))
+		assert.ErrorContains(t, err, `unknown channel "FAX"`)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadRules(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+
+	t.Run("shipped default rules", func(t *testing.T) {
+		rules, err := LoadRules(filepath.Join("..", "..", "configs", "notification-rules.json"))
+		require.NoError(t, err)
+		assert.NotEmpty(t, rules)
+	})
+}
@@ -0,0 +1,163 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeliveryStatus is the delivery state of an outbox message.
+type DeliveryStatus string
+
+const (
+	DeliveryPending    DeliveryStatus = "PENDING"     // waiting for its first or next attempt
+	DeliverySent       DeliveryStatus = "SENT"        // accepted by the channel provider
+	DeliveryDeadLetter DeliveryStatus = "DEAD_LETTER" // gave up after the maximum attempts or a permanent failure
+)
+
+// OutboxMessage is a notification to a single recipient awaiting or after delivery.
+type OutboxMessage struct {
+	ID             string                 `gorm:"type:varchar(100);column:id;not null;primaryKey" json:"id"`
+	IdempotencyKey string                 `gorm:"type:varchar(512);column:idempotency_key;not null;uniqueIndex" json:"idempotencyKey"`
+	Event          EventType              `gorm:"type:varchar(50);column:event;not null" json:"event"`
+	WorkflowID     string                 `gorm:"type:text;column:workflow_id;not null" json:"workflowId"`
+	Channel        Channel                `gorm:"type:varchar(20);column:channel;not null" json:"channel"`
+	Recipient      string                 `gorm:"type:varchar(255);column:recipient;not null" json:"recipient"`
+	TemplateID     string                 `gorm:"type:varchar(100);column:template_id;not null" json:"templateId"`
+	TemplateData   map[string]interface{} `gorm:"type:jsonb;column:template_data;not null;serializer:json" json:"templateData"`
+	Status         DeliveryStatus         `gorm:"type:varchar(20);column:status;not null" json:"status"`
+	Attempts       int                    `gorm:"column:attempts;not null" json:"attempts"`
+	LastError      string                 `gorm:"type:text;column:last_error;not null" json:"lastError,omitempty"`
+	NextAttemptAt  time.Time              `gorm:"type:timestamptz;column:next_attempt_at;not null" json:"nextAttemptAt"`
+	SentAt         *time.Time             `gorm:"type:timestamptz;column:sent_at" json:"sentAt,omitempty"`
+	CreatedAt      time.Time              `gorm:"type:timestamptz;column:created_at;not null" json:"createdAt"`
+	UpdatedAt      time.Time              `gorm:"type:timestamptz;column:updated_at;not null" json:"updatedAt"`
+}
+
+// TableName returns the table name for OutboxMessage
+func (OutboxMessage) TableName() string {
+	return "notification_outbox"
+}
+
+// Outbox persists notifications for delivery by the Dispatcher.
+type Outbox interface {
+	// Enqueue stores a pending message. A message whose idempotency key was already enqueued is ignored.
+	Enqueue(ctx context.Context, message *OutboxMessage) error
+	// ClaimDue returns up to limit pending messages due at now and hides them from other
+	// claimers until now+lease, so that concurrent dispatchers do not deliver a message twice.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error)
+	// MarkSent records a successful delivery.
+	MarkSent(ctx context.Context, id string, attempts int, sentAt time.Time) error
+	// MarkFailed records a failed attempt and schedules the next one, or dead-letters the message.
+	MarkFailed(ctx context.Context, id string, attempts int, lastError string, nextAttemptAt time.Time, deadLetter bool) error
+	// ListByWorkflowID returns the messages sent for a workflow, oldest first.
+	ListByWorkflowID(ctx context.Context, workflowID string) ([]OutboxMessage, error)
+}
+
+// OutboxStore is the GORM implementation of Outbox.
+type OutboxStore struct {
+	db *gorm.DB
+}
+
+// NewOutboxStore creates a new OutboxStore with the provided database connection
+func NewOutboxStore(db *gorm.DB) (*OutboxStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection cannot be nil")
+	}
+	return &OutboxStore{db: db}, nil
+}
+
+// Enqueue inserts a pending message, assigning its ID and timestamps if unset
+func (s *OutboxStore) Enqueue(ctx context.Context, message *OutboxMessage) error {
+	if message.ID == "" {
+		message.ID = uuid.NewString()
+	}
+	now := time.Now().UTC()
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = now
+	}
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = now
+	}
+	if message.TemplateData == nil {
+		message.TemplateData = map[string]interface{}{}
+	}
+	message.UpdatedAt = now
+	message.Status = DeliveryPending
+
+	return s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "idempotency_key"}}, DoNothing: true}).
+		Create(message).Error
+}
+
+// ClaimDue locks due pending messages with SKIP LOCKED and pushes their next attempt past the lease
+func (s *OutboxStore) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error) {
+	var messages []OutboxMessage
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&messages).Error; err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(messages))
+		for i, message := range messages {
+			ids[i] = message.ID
+		}
+		return tx.Model(&OutboxMessage{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"next_attempt_at": now.Add(lease), "updated_at": now}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due notifications: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkSent records a successful delivery
+func (s *OutboxStore) MarkSent(ctx context.Context, id string, attempts int, sentAt time.Time) error {
+	return s.db.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     DeliverySent,
+			"attempts":   attempts,
+			"last_error": "",
+			"sent_at":    sentAt,
+			"updated_at": sentAt,
+		}).Error
+}
+
+// MarkFailed records a failed attempt
+func (s *OutboxStore) MarkFailed(ctx context.Context, id string, attempts int, lastError string, nextAttemptAt time.Time, deadLetter bool) error {
+	status := DeliveryPending
+	if deadLetter {
+		status = DeliveryDeadLetter
+	}
+	return s.db.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        attempts,
+			"last_error":      lastError,
+			"next_attempt_at": nextAttemptAt,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
+
+// ListByWorkflowID retrieves the messages of a workflow in the order they were enqueued
+func (s *OutboxStore) ListByWorkflowID(ctx context.Context, workflowID string) ([]OutboxMessage, error) {
+	var messages []OutboxMessage
+	if err := s.db.WithContext(ctx).Where("workflow_id = ?", workflowID).Order("created_at ASC, id ASC").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB, mock
+}
+
+func TestOutboxStore_Enqueue(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewOutboxStore(db)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "notification_outbox"`) + `.*` + regexp.QuoteMeta(`ON CONFLICT ("idempotency_key") DO NOTHING`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	message := &OutboxMessage{
+		IdempotencyKey: "PAYMENT_SUCCEEDED/event-1/payment_succeeded/EMAIL/trader@example.com",
+		Event:          EventPaymentSucceeded,
+		WorkflowID:     "c-1",
+		Channel:        ChannelEmail,
+		Recipient:      "trader@example.com",
+		TemplateID:     "payment_succeeded",
+	}
+	require.NoError(t, store.Enqueue(context.Background(), message))
+
+	assert.NotEmpty(t, message.ID)
+	assert.Equal(t, DeliveryPending, message.Status)
+	assert.False(t, message.NextAttemptAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxStore_ListByWorkflowID(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewOutboxStore(db)
+	require.NoError(t, err)
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "notification_outbox" WHERE workflow_id = $1 ORDER BY created_at ASC, id ASC`)).
+		WithArgs("c-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_id", "channel", "recipient", "status", "attempts", "template_data", "created_at"}).
+			AddRow("m1", "c-1", "EMAIL", "trader@example.com", "DEAD_LETTER", 8, `{"WorkflowID":"c-1"}`, now))
+
+	messages, err := store.ListByWorkflowID(context.Background(), "c-1")
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, DeliveryDeadLetter, messages[0].Status)
+	assert.Equal(t, "c-1", messages[0].TemplateData["WorkflowID"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
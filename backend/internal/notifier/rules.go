@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// EventType identifies a workflow or task event that can trigger notifications.
+type EventType string
+
+const (
+	EventOGAFeedbackRequested    EventType = "OGA_FEEDBACK_REQUESTED"    // an OGA sent a submitted form back for changes
+	EventPaymentSucceeded        EventType = "PAYMENT_SUCCEEDED"         // a payment task recorded a successful payment
+	EventConsignmentFinished     EventType = "CONSIGNMENT_FINISHED"      // a consignment workflow completed
+	EventPreConsignmentCompleted EventType = "PRE_CONSIGNMENT_COMPLETED" // a pre-consignment workflow completed
+)
+
+// Recipient names a workflow party that receives a notification.
+type Recipient string
+
+const (
+	RecipientTrader Recipient = "TRADER"
+	RecipientCHA    Recipient = "CHA"
+)
+
+// Channel names a delivery channel registered on the notification manager.
+type Channel string
+
+const (
+	ChannelEmail Channel = "EMAIL"
+	ChannelSMS   Channel = "SMS"
+)
+
+// Rule sends Template to Recipients over Channels whenever Event occurs.
+// Template is the template ID; email and SMS channels resolve it against their own template roots.
+type Rule struct {
+	Event      EventType   `json:"event"`
+	Recipients []Recipient `json:"recipients"`
+	Channels   []Channel   `json:"channels"`
+	Template   string      `json:"template"`
+}
+
+// Rules is the root object of the notification rules file.
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRules reads and validates the notification rules file at filePath.
+func LoadRules(filePath string) ([]Rule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("notifier: failed to read rules file: %w", err)
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("notifier: failed to unmarshal rules: %w", err)
+	}
+	for i, rule := range rules.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("notifier: invalid rule %d: %w", i, err)
+		}
+	}
+	return rules.Rules, nil
+}
+
+func (r Rule) validate() error {
+	switch r.Event {
+	case EventOGAFeedbackRequested, EventPaymentSucceeded, EventConsignmentFinished, EventPreConsignmentCompleted:
+	default:
+		return fmt.Errorf("unknown event %q", r.Event)
+	}
+	if r.Template == "" {
+		return fmt.Errorf("template is required for event %s", r.Event)
+	}
+	if len(r.Recipients) == 0 {
+		return fmt.Errorf("at least one recipient is required for event %s", r.Event)
+	}
+	for _, recipient := range r.Recipients {
+		if recipient != RecipientTrader && recipient != RecipientCHA {
+			return fmt.Errorf("unknown recipient %q", recipient)
+		}
+	}
+	if len(r.Channels) == 0 {
+		return fmt.Errorf("at least one channel is required for event %s", r.Event)
+	}
+	for _, channel := range r.Channels {
+		if channel != ChannelEmail && channel != ChannelSMS {
+			return fmt.Errorf("unknown channel %q", channel)
+		}
+	}
+	return nil
+}
@@ -1,61 +0,0 @@
-package payments
-
-import (
-	"encoding/json"
-	"log/slog"
-	"net/http"
-)
-
-// HTTPHandler handles public HTTP requests for the Payment Service.
-type HTTPHandler struct {
-	service PaymentService
-}
-
-// NewHTTPHandler creates a new handler.
-func NewHTTPHandler(service PaymentService) *HTTPHandler {
-	return &HTTPHandler{service: service}
-}
-
-// HandleValidateReference handles POST /api/v1/payments/validate
-// Called by LankaPay to query if a reference number is valid and payable.
-func (h *HTTPHandler) HandleValidateReference(w http.ResponseWriter, r *http.Request) {
-	var req ValidateReferenceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request payload", http.StatusBadRequest)
-		return
-	}
-
-	resp, err := h.service.ValidateReference(r.Context(), req)
-	if err != nil {
-		// Log the error in reality. Return generic 500 to caller.
-		slog.ErrorContext(r.Context(), "failed to validate reference", "error", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
-	}
-}
-
-// HandleWebhook handles POST /api/v1/payments/webhook
-// Called by LankaPay to notify about payment successes and failures.
-func (h *HTTPHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
-	// In production, verify the webhook signature (e.g., via headers) here
-
-	var payload WebhookPayload
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid webhook payload", http.StatusBadRequest)
-		return
-	}
-
-	err := h.service.ProcessWebhook(r.Context(), payload)
-	if err != nil {
-		http.Error(w, "internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(`{"status": "accepted"}`))
-}
@@ -2,6 +2,7 @@ package paymentsv2
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
@@ -17,10 +18,25 @@ func NewHTTPHandler(service PaymentService) *HTTPHandler {
 	return &HTTPHandler{service: service}
 }
 
+// HandleListMethods handles GET /api/v1/payments/methods
+// Returns the active payment methods in display order.
+func (h *HTTPHandler) HandleListMethods(w http.ResponseWriter, r *http.Request) {
+	methods, err := h.service.ListAvailableMethods(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list payment methods", "error", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(methods); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
 // HandleValidateReference handles POST /api/v1/payments/:providerId/validate
 // Called by gateways to query if a reference number is valid and payable.
 func (h *HTTPHandler) HandleValidateReference(w http.ResponseWriter, r *http.Request) {
-	// TODO: Extract providerID from URL parameters
 	providerID := r.PathValue("providerId")
 	if providerID == "" {
 		http.Error(w, "provider ID is required in URL", http.StatusBadRequest)
@@ -34,6 +50,10 @@ func (h *HTTPHandler) HandleValidateReference(w http.ResponseWriter, r *http.Req
 	}
 
 	resp, err := h.service.ValidateReference(r.Context(), providerID, req)
+	if errors.Is(err, ErrProviderNotFound) {
+		http.Error(w, "unknown payment provider", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		slog.ErrorContext(r.Context(), "failed to validate reference", "provider", providerID, "error", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
@@ -49,7 +69,6 @@ func (h *HTTPHandler) HandleValidateReference(w http.ResponseWriter, r *http.Req
 // HandleWebhook handles POST /api/v1/payments/:providerID/webhook
 // Called by payment gateways to notify about payment successes and failures.
 func (h *HTTPHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
-	// TODO: Extract providerID from URL parameters
 	providerID := r.PathValue("providerId")
 	if providerID == "" {
 		http.Error(w, "provider ID is required in URL", http.StatusBadRequest)
@@ -66,7 +85,16 @@ func (h *HTTPHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	err = h.service.ProcessWebhook(r.Context(), providerID, body, r.Header)
 	if err != nil {
 		slog.ErrorContext(r.Context(), "webhook processing failed", "provider", providerID, "error", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, ErrProviderNotFound):
+			http.Error(w, "unknown payment provider", http.StatusNotFound)
+		case errors.Is(err, ErrInvalidWebhook):
+			http.Error(w, "invalid webhook", http.StatusUnauthorized)
+		case errors.Is(err, ErrTransactionNotFound):
+			http.Error(w, "payment reference not found", http.StatusNotFound)
+		default:
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
 		return
 	}
 
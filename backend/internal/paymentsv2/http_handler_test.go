@@ -0,0 +1,103 @@
+package paymentsv2
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func newTestMux(t *testing.T) (*http.ServeMux, *mockRepository, PaymentService) {
+	t.Helper()
+	service, repo := newTestService(t)
+	handler := NewHTTPHandler(service)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/payments/methods", handler.HandleListMethods)
+	mux.HandleFunc("POST /api/v1/payments/{providerId}/validate", handler.HandleValidateReference)
+	mux.HandleFunc("POST /api/v1/payments/{providerId}/webhook", handler.HandleWebhook)
+	return mux, repo, service
+}
+
+func TestHandleListMethods(t *testing.T) {
+	mux, _, _ := newTestMux(t)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/payments/methods", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var methods []PaymentProviderInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &methods); err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 2 || methods[0].ID != MockProviderID {
+		t.Errorf("methods = %+v", methods)
+	}
+}
+
+func TestHandleWebhook(t *testing.T) {
+	mux, repo, service := newTestMux(t)
+	resp, err := service.CreateCheckoutSession(t.Context(), checkoutRequest(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("bad signature is unauthorized", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/mock/webhook", bytes.NewBufferString(`{"reference_number":"x"}`))
+		req.Header.Set(MockSignatureHeader, "invalid")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want 401", rec.Code)
+		}
+	})
+
+	t.Run("unknown provider is not found", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/payments/govpay/webhook", bytes.NewBufferString(`{}`)))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("signed webhook is accepted", func(t *testing.T) {
+		body, headers := signedWebhook(t, WebhookPayload{
+			ReferenceNumber: resp.ReferenceNumber, Status: PaymentStatusSuccess, Amount: decimal.NewFromInt(1500), Currency: "LKR",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/mock/webhook", bytes.NewReader(body))
+		req.Header = headers
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		if repo.txs[resp.ReferenceNumber].Status != PaymentStatusSuccess {
+			t.Error("transaction was not marked successful")
+		}
+	})
+}
+
+func TestHandleValidateReference(t *testing.T) {
+	mux, _, service := newTestMux(t)
+	resp, err := service.CreateCheckoutSession(t.Context(), checkoutRequest(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(ValidateReferenceRequest{PaymentReference: resp.ReferenceNumber})
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/payments/mock/validate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var got ValidateReferenceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsPayable || !got.Amount.Equal(decimal.NewFromInt(1500)) {
+		t.Errorf("response = %+v, want payable 1500", got)
+	}
+}
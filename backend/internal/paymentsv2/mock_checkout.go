@@ -0,0 +1,104 @@
+package paymentsv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CheckoutAuthorizer decides whether the caller in ctx may pay for a task.
+type CheckoutAuthorizer func(ctx context.Context, taskID string) error
+
+// MockCheckoutHandler stands in for the mock provider's hosted checkout page. The trader portal
+// reports the result the trader picked, and the handler signs it with the mock webhook secret and
+// processes it like a gateway webhook, so the secret never leaves the server.
+//
+// It lets any authorized trader settle their own payments without paying and must only be routed
+// when the mock provider is enabled.
+type MockCheckoutHandler struct {
+	service   PaymentService
+	repo      PaymentRepository
+	secret    string
+	authorize CheckoutAuthorizer
+}
+
+// NewMockCheckoutHandler creates a MockCheckoutHandler that signs results with secret.
+func NewMockCheckoutHandler(service PaymentService, repo PaymentRepository, secret string, authorize CheckoutAuthorizer) *MockCheckoutHandler {
+	return &MockCheckoutHandler{service: service, repo: repo, secret: secret, authorize: authorize}
+}
+
+// MockCheckoutResult is the request body of HandleComplete.
+type MockCheckoutResult struct {
+	Status PaymentStatus `json:"status"` // SUCCESS or FAILED
+}
+
+// HandleComplete handles POST /api/v1/payments/mock/checkout/{referenceNumber}/complete
+// Completes a mock checkout session with the given result for the full amount due.
+func (h *MockCheckoutHandler) HandleComplete(w http.ResponseWriter, r *http.Request) {
+	referenceNumber := r.PathValue("referenceNumber")
+	if referenceNumber == "" {
+		http.Error(w, "reference number is required in URL", http.StatusBadRequest)
+		return
+	}
+
+	var result MockCheckoutResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if result.Status != PaymentStatusSuccess && result.Status != PaymentStatusFailed {
+		http.Error(w, "status must be SUCCESS or FAILED", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.repo.GetByReferenceNumber(r.Context(), referenceNumber)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to retrieve payment by reference", "reference", referenceNumber, "error", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if tx == nil || tx.ProviderID != MockProviderID {
+		http.Error(w, "payment reference not found", http.StatusNotFound)
+		return
+	}
+	if err := h.authorize(r.Context(), tx.TaskID); err != nil {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		ReferenceNumber:      tx.ReferenceNumber,
+		SessionID:            tx.SessionID,
+		GatewayTransactionID: "mock_txn_" + uuid.NewString(),
+		Status:               result.Status,
+		Amount:               tx.Amount,
+		Currency:             tx.Currency,
+		PaymentMethod:        MockProviderID,
+		Timestamp:            time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	headers := map[string][]string{MockSignatureHeader: {SignMockWebhook(h.secret, body)}}
+
+	if err := h.service.ProcessWebhook(r.Context(), MockProviderID, body, headers); err != nil {
+		slog.ErrorContext(r.Context(), "mock checkout failed", "reference", referenceNumber, "error", err)
+		switch {
+		case errors.Is(err, ErrInvalidWebhook):
+			http.Error(w, "mock checkout rejected", http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"status": "accepted"}`))
+}
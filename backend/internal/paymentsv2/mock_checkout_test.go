@@ -0,0 +1,121 @@
+package paymentsv2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newMockCheckoutMux(t *testing.T, authorize CheckoutAuthorizer) (*http.ServeMux, *mockRepository, PaymentService) {
+	t.Helper()
+	service, repo := newTestService(t)
+	handler := NewMockCheckoutHandler(service, repo, testWebhookSecret, authorize)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/payments/mock/checkout/{referenceNumber}/complete", handler.HandleComplete)
+	return mux, repo, service
+}
+
+func allowCheckout(context.Context, string) error { return nil }
+
+func completeMockCheckout(mux *http.ServeMux, reference, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/mock/checkout/"+reference+"/complete", bytes.NewBufferString(body))
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMockCheckoutHandler_HandleComplete(t *testing.T) {
+	t.Run("signs the result server-side and settles the payment", func(t *testing.T) {
+		var authorizedTask string
+		mux, repo, service := newMockCheckoutMux(t, func(_ context.Context, taskID string) error {
+			authorizedTask = taskID
+			return nil
+		})
+		resp, err := service.CreateCheckoutSession(t.Context(), checkoutRequest(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := completeMockCheckout(mux, resp.ReferenceNumber, `{"status":"SUCCESS"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		if repo.txs[resp.ReferenceNumber].Status != PaymentStatusSuccess {
+			t.Error("transaction was not marked successful")
+		}
+		if authorizedTask != "task-1" {
+			t.Errorf("authorized task = %q, want task-1", authorizedTask)
+		}
+	})
+
+	t.Run("records a failed payment", func(t *testing.T) {
+		mux, repo, service := newMockCheckoutMux(t, allowCheckout)
+		resp, err := service.CreateCheckoutSession(t.Context(), checkoutRequest(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := completeMockCheckout(mux, resp.ReferenceNumber, `{"status":"FAILED"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		if repo.txs[resp.ReferenceNumber].Status != PaymentStatusFailed {
+			t.Error("transaction was not marked failed")
+		}
+	})
+
+	t.Run("rejects other statuses", func(t *testing.T) {
+		mux, _, service := newMockCheckoutMux(t, allowCheckout)
+		resp, err := service.CreateCheckoutSession(t.Context(), checkoutRequest(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := completeMockCheckout(mux, resp.ReferenceNumber, `{"status":"REFUNDED"}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+
+	t.Run("unknown reference is not found", func(t *testing.T) {
+		mux, _, _ := newMockCheckoutMux(t, allowCheckout)
+		rec := completeMockCheckout(mux, "PAY-UNKNOWN", `{"status":"SUCCESS"}`)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("payments on other providers are not found", func(t *testing.T) {
+		mux, _, service := newMockCheckoutMux(t, allowCheckout)
+		resp, err := service.CreateCheckoutSession(t.Context(), checkoutRequest("other"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := completeMockCheckout(mux, resp.ReferenceNumber, `{"status":"SUCCESS"}`)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("callers who may not pay for the task are forbidden", func(t *testing.T) {
+		mux, repo, service := newMockCheckoutMux(t, func(context.Context, string) error {
+			return errors.New("not the task owner")
+		})
+		resp, err := service.CreateCheckoutSession(t.Context(), checkoutRequest(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rec := completeMockCheckout(mux, resp.ReferenceNumber, `{"status":"SUCCESS"}`)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", rec.Code)
+		}
+		if repo.txs[resp.ReferenceNumber].Status != PaymentStatusPending {
+			t.Error("transaction changed without authorization")
+		}
+	})
+}
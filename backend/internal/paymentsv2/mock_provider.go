@@ -0,0 +1,110 @@
+package paymentsv2
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MockProviderID is the payment method ID the mock provider is registered under.
+const MockProviderID = "mock"
+
+// MockSignatureHeader carries the hex-encoded HMAC-SHA256 of a mock webhook body.
+const MockSignatureHeader = "X-Mock-Signature"
+
+// MockProviderConfig configures the local mock provider.
+type MockProviderConfig struct {
+	CheckoutURL   string // Base URL of the simulated hosted checkout page
+	WebhookSecret string // Shared secret used to sign webhooks; webhooks are rejected when empty
+}
+
+// MockProvider is a local PaymentProvider for development and tests. It creates sessions without
+// calling out and accepts webhooks signed with SignMockWebhook.
+type MockProvider struct {
+	config MockProviderConfig
+}
+
+// NewMockProvider creates a MockProvider.
+func NewMockProvider(config MockProviderConfig) *MockProvider {
+	return &MockProvider{config: config}
+}
+
+// CreateSession returns a simulated checkout session for the request's reference.
+func (p *MockProvider) CreateSession(_ context.Context, req CreateCheckoutRequest) (*CreateCheckoutResponse, error) {
+	if req.ReferenceNumber == "" {
+		return nil, fmt.Errorf("mock: reference number is required")
+	}
+	sessionID := "mock_sess_" + uuid.NewString()
+	checkoutURL := strings.TrimSuffix(p.config.CheckoutURL, "/") + "/" + sessionID +
+		"?reference=" + url.QueryEscape(req.ReferenceNumber)
+
+	expiresIn := 0
+	if !req.ExpiresAt.IsZero() {
+		expiresIn = int(time.Until(req.ExpiresAt).Seconds())
+	}
+	return &CreateCheckoutResponse{
+		SessionID:   sessionID,
+		CheckoutURL: checkoutURL,
+		ExpiresIn:   expiresIn,
+	}, nil
+}
+
+// ParseWebhook verifies the body's signature and decodes it as a WebhookPayload.
+func (p *MockProvider) ParseWebhook(_ context.Context, body []byte, headers map[string][]string) (*WebhookPayload, error) {
+	if p.config.WebhookSecret == "" {
+		return nil, errors.New("mock: webhook secret is not configured")
+	}
+	signature := http.Header(headers).Get(MockSignatureHeader)
+	if signature == "" {
+		return nil, errors.New("mock: missing webhook signature")
+	}
+	if !hmac.Equal([]byte(signature), []byte(SignMockWebhook(p.config.WebhookSecret, body))) {
+		return nil, errors.New("mock: webhook signature mismatch")
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("mock: invalid webhook payload: %w", err)
+	}
+	if payload.ReferenceNumber == "" {
+		return nil, errors.New("mock: reference_number is required")
+	}
+	return &payload, nil
+}
+
+// HandleValidateReference reports the transaction payable while it is pending and unexpired.
+func (p *MockProvider) HandleValidateReference(_ context.Context, tx *PaymentTransaction) (*ValidateReferenceResponse, error) {
+	return &ValidateReferenceResponse{
+		Amount:     tx.Amount,
+		Currency:   tx.Currency,
+		ExpiryDate: tx.ExpiryDate.Format(time.RFC3339),
+		IsPayable:  tx.Status == PaymentStatusPending && time.Now().Before(tx.ExpiryDate),
+		Remarks:    fmt.Sprintf("Current status: %s", tx.Status),
+	}, nil
+}
+
+// Refund accepts the refund without calling out. It is confirmed by posting a signed REFUNDED
+// webhook that quotes the returned refund ID as its gateway_transaction_id.
+func (p *MockProvider) Refund(_ context.Context, req RefundRequest) (*RefundResponse, error) {
+	if req.ReferenceNumber == "" || !req.Amount.IsPositive() {
+		return nil, fmt.Errorf("mock: a reference number and a positive amount are required")
+	}
+	return &RefundResponse{GatewayRefundID: "mock_refund_" + req.RefundID}, nil
+}
+
+// SignMockWebhook returns the MockSignatureHeader value for body.
+func SignMockWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
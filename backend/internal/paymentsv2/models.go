@@ -9,26 +9,99 @@ import (
 type PaymentStatus string
 
 const (
-	PaymentStatusPending PaymentStatus = "PENDING"
-	PaymentStatusSuccess PaymentStatus = "SUCCESS"
-	PaymentStatusFailed  PaymentStatus = "FAILED"
+	PaymentStatusPending         PaymentStatus = "PENDING"
+	PaymentStatusSuccess         PaymentStatus = "SUCCESS"
+	PaymentStatusFailed          PaymentStatus = "FAILED"
+	PaymentStatusExpired         PaymentStatus = "EXPIRED"          // Still PENDING when its checkout session expired
+	PaymentStatusPartiallyPaid   PaymentStatus = "PARTIALLY_PAID"   // Some, but not all, of the amount has been paid
+	PaymentStatusRefundRequested PaymentStatus = "REFUND_REQUESTED" // A refund was sent to the gateway and awaits confirmation
+	PaymentStatusRefunded        PaymentStatus = "REFUNDED"         // The gateway confirmed a refund
 )
 
+// IsFinal reports whether the gateway has settled the payment one way or the other.
+func (s PaymentStatus) IsFinal() bool {
+	return s == PaymentStatusSuccess || s == PaymentStatusFailed
+}
+
+// reportsToTask reports whether the PAYMENT task is told when the payment reaches the status.
+// REFUND_REQUESTED is not reported because refunds are requested by the task itself.
+func (s PaymentStatus) reportsToTask() bool {
+	return s.IsFinal() || s == PaymentStatusPartiallyPaid || s == PaymentStatusRefunded
+}
+
+// IsPaid reports whether the gateway has received money for the payment.
+func (s PaymentStatus) IsPaid() bool {
+	switch s {
+	case PaymentStatusPartiallyPaid, PaymentStatusSuccess, PaymentStatusRefundRequested, PaymentStatusRefunded:
+		return true
+	}
+	return false
+}
+
 // PaymentTransaction represents the internal state of a payment
 type PaymentTransaction struct {
-	ID              string            `json:"id" gorm:"type:text;not null;primaryKey"`
-	ReferenceNumber string            `json:"reference_number" gorm:"uniqueIndex"` // Generated by Payment Service
-	TaskID          string            `json:"task_id" gorm:"index"`                // Links back to the FSM Task Node
-	ProviderID      string            `json:"provider_id" gorm:"index"`            // e.g., "lankapay"
-	SessionID       string            `json:"session_id"`                          // Gateway-specific session identifier
-	Amount          decimal.Decimal   `json:"amount"`
-	Currency        string            `json:"currency"`       // "LKR" or foreign currency
-	Status          PaymentStatus     `json:"status"`         // PENDING, SUCCESS, FAILED, EXPIRED
-	PaymentMethod   string            `json:"payment_method"` // CC, BANK_TRANSFER (populated on webhook)
-	ExpiryDate      time.Time         `json:"expiry_date"`
-	GatewayMetadata map[string]string `json:"gateway_metadata" gorm:"serializer:json"`
-	CreatedAt       time.Time         `json:"created_at"`
-	UpdatedAt       time.Time         `json:"updated_at"`
+	ID               string            `json:"id" gorm:"type:text;not null;primaryKey"`
+	ReferenceNumber  string            `json:"reference_number" gorm:"uniqueIndex"` // Generated by Payment Service
+	TaskID           string            `json:"task_id" gorm:"index"`                // Links back to the FSM Task Node
+	ProviderID       string            `json:"provider_id" gorm:"index"`            // e.g., "lankapay"
+	SessionID        string            `json:"session_id"`                          // Gateway-specific session identifier
+	Amount           decimal.Decimal   `json:"amount"`
+	Currency         string            `json:"currency"`       // "LKR" or foreign currency
+	Status           PaymentStatus     `json:"status"`         // PENDING, PARTIALLY_PAID, SUCCESS, FAILED, EXPIRED, REFUND_REQUESTED, REFUNDED
+	PaymentMethod    string            `json:"payment_method"` // CC, BANK_TRANSFER (populated on webhook)
+	ExpiryDate       time.Time         `json:"expiry_date"`
+	GatewayMetadata  map[string]string `json:"gateway_metadata" gorm:"serializer:json"`
+	TaskSyncedStatus PaymentStatus     `json:"task_synced_status"` // Last outcome reported to the task; empty until the task has been advanced
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+type LedgerEntryType string
+
+const (
+	LedgerEntryPayment LedgerEntryType = "PAYMENT"
+	LedgerEntryRefund  LedgerEntryType = "REFUND"
+)
+
+// LedgerEntry records money moving for a payment: a payment received or a refund returned.
+// Entries are append-only; the net amount held for a task is derived from them.
+type LedgerEntry struct {
+	ID              string          `json:"id" gorm:"type:text;not null;primaryKey"`
+	TransactionID   string          `json:"transaction_id" gorm:"index"`
+	TaskID          string          `json:"task_id" gorm:"index"`
+	ReferenceNumber string          `json:"reference_number"`
+	EntryType       LedgerEntryType `json:"entry_type"`
+	Amount          decimal.Decimal `json:"amount"` // Always positive; EntryType gives the direction
+	Currency        string          `json:"currency"`
+	ExternalID      string          `json:"external_id"` // Gateway transaction or refund ID; makes redelivered webhooks idempotent
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+func (LedgerEntry) TableName() string {
+	return "payment_ledger_entries"
+}
+
+// TaskLedger is the money recorded for a task across all of its payment transactions.
+type TaskLedger struct {
+	TaskID   string          `json:"task_id"`
+	Currency string          `json:"currency"`
+	Paid     decimal.Decimal `json:"paid"`
+	Refunded decimal.Decimal `json:"refunded"`
+	Net      decimal.Decimal `json:"net"` // Paid less Refunded: what NSW still holds for the task
+	Entries  []LedgerEntry   `json:"entries"`
+}
+
+// ledgerTotals sums the payments and refunds in entries.
+func ledgerTotals(entries []LedgerEntry) (paid, refunded decimal.Decimal) {
+	for _, e := range entries {
+		switch e.EntryType {
+		case LedgerEntryPayment:
+			paid = paid.Add(e.Amount)
+		case LedgerEntryRefund:
+			refunded = refunded.Add(e.Amount)
+		}
+	}
+	return paid, refunded
 }
 
 // --------------------------------------------------------
@@ -37,7 +110,8 @@ type PaymentTransaction struct {
 
 // CreateCheckoutRequest is the payload sent to initialize a session.
 type CreateCheckoutRequest struct {
-	ProviderID         string            `json:"provider_id"`
+	ProviderID         string            `json:"provider_id"`                // Selected payment method; the default method if empty
+	ReferenceNumber    string            `json:"reference_number,omitempty"` // Set by the service before the provider is called
 	Amount             decimal.Decimal   `json:"amount"`
 	Currency           string            `json:"currency"`
 	SuccessRedirectURL string            `json:"success_redirect_url,omitempty"` // Optional: User redirect on success
@@ -48,12 +122,30 @@ type CreateCheckoutRequest struct {
 
 // CreateCheckoutResponse is the expected reply from LankaPay.
 type CreateCheckoutResponse struct {
+	ProviderID      string `json:"provider_id"`      // The provider the session was created with
 	ReferenceNumber string `json:"reference_number"` // The generated NSW reference
 	SessionID       string `json:"session_id"`
 	CheckoutURL     string `json:"checkout_url"` // The hosted URL to redirect the user to
 	ExpiresIn       int    `json:"expires_in_seconds"`
 }
 
+// RefundRequest asks a gateway to return money for a settled payment.
+type RefundRequest struct {
+	RefundID             string          `json:"refund_id"` // NSW-issued; lets the gateway deduplicate retries
+	ReferenceNumber      string          `json:"reference_number"`
+	SessionID            string          `json:"session_id"`
+	GatewayTransactionID string          `json:"gateway_transaction_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	Reason               string          `json:"reason,omitempty"`
+}
+
+// RefundResponse is the gateway's acknowledgement of a refund request. The refund is confirmed
+// later by a REFUNDED webhook quoting GatewayRefundID as its gateway_transaction_id.
+type RefundResponse struct {
+	GatewayRefundID string `json:"gateway_refund_id"`
+}
+
 // --------------------------------------------------------
 // Real-Time Validation API Contracts (Inbound from GovPay)
 // --------------------------------------------------------
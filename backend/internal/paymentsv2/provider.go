@@ -31,6 +31,10 @@ type PaymentProvider interface {
 	// HandleValidateReference handles gateway-specific validation logic.
 	// This is called when a gateway queries if a reference is valid and payable.
 	HandleValidateReference(ctx context.Context, tx *PaymentTransaction) (*ValidateReferenceResponse, error)
+
+	// Refund asks the gateway to return money for a settled payment.
+	// Gateways confirm refunds asynchronously with a REFUNDED webhook.
+	Refund(ctx context.Context, req RefundRequest) (*RefundResponse, error)
 }
 
 // PaymentRegistry manages the discovery and lookup of payment providers.
@@ -0,0 +1,71 @@
+package reconciliation
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Expirer periodically marks PENDING payments whose checkout session has expired as EXPIRED,
+// so that they no longer validate as payable.
+type Expirer struct {
+	store    Store
+	interval time.Duration
+	now      func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewExpirer creates an Expirer that sweeps every interval, defaulting to five minutes.
+func NewExpirer(store Store, interval time.Duration) *Expirer {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &Expirer{
+		store:    store,
+		interval: interval,
+		now:      func() time.Time { return time.Now().UTC() },
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start sweeps until Stop is called.
+func (e *Expirer) Start() {
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				if _, err := e.ExpireStale(context.Background()); err != nil {
+					slog.Error("reconciliation: failed to expire stale payments", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops sweeping and waits for an in-flight sweep to finish. It must only be called after Start.
+func (e *Expirer) Stop() {
+	e.stopOnce.Do(func() { close(e.stop) })
+	<-e.done
+}
+
+// ExpireStale expires the payments whose session has expired and returns how many there were.
+func (e *Expirer) ExpireStale(ctx context.Context) (int64, error) {
+	expired, err := e.store.ExpireStale(ctx, e.now())
+	if err != nil {
+		return 0, err
+	}
+	if expired > 0 {
+		slog.Info("reconciliation: expired stale payments", "count", expired)
+	}
+	return expired, nil
+}
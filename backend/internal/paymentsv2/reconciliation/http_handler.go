@@ -0,0 +1,209 @@
+package reconciliation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+)
+
+// maxSettlementFileSize bounds the size of an uploaded settlement file.
+const maxSettlementFileSize = 10 << 20 // 10MB
+
+// RoleAuthorizer decides whether the caller holds one of a set of roles; satisfied by authz.Policy.
+type RoleAuthorizer interface {
+	AuthorizeRole(ctx context.Context, roles ...string) error
+}
+
+// LedgerReader returns the payment ledger of a task; satisfied by paymentsv2.PaymentService.
+type LedgerReader interface {
+	GetTaskLedger(ctx context.Context, taskID string) (*paymentsv2.TaskLedger, error)
+}
+
+// RefundRequester refunds amount of a task's payment, or everything paid when amount is zero.
+// The server uses manager.PaymentRefundRequester so that the PAYMENT task tracks the refund.
+type RefundRequester func(ctx context.Context, taskID string, amount decimal.Decimal, reason string) error
+
+// RefundRequest is the body of a refund request.
+type RefundRequest struct {
+	Amount decimal.Decimal `json:"amount"` // Zero or omitted refunds everything paid
+	Reason string          `json:"reason"`
+}
+
+// HTTPHandler exposes settlement ingestion and reporting, payment ledgers and refunds to administrators.
+type HTTPHandler struct {
+	reconciler    *Reconciler
+	ledger        LedgerReader
+	requestRefund RefundRequester
+	authorizer    RoleAuthorizer
+}
+
+// NewHTTPHandler creates a new HTTPHandler.
+func NewHTTPHandler(reconciler *Reconciler, ledger LedgerReader, requestRefund RefundRequester, authorizer RoleAuthorizer) *HTTPHandler {
+	return &HTTPHandler{reconciler: reconciler, ledger: ledger, requestRefund: requestRefund, authorizer: authorizer}
+}
+
+// HandleIngestSettlements ingests a gateway settlement file sent as the request body. The format
+// is taken from the format query parameter, or from the Content-Type (text/csv or application/json).
+// POST /api/v1/admin/payments/{providerId}/settlements?format=csv|json
+func (h *HTTPHandler) HandleIngestSettlements(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	providerID := r.PathValue("providerId")
+	if providerID == "" {
+		http.Error(w, "provider ID is required in URL", http.StatusBadRequest)
+		return
+	}
+	format, ok := requestFormat(r)
+	if !ok {
+		http.Error(w, "format must be csv or json", http.StatusBadRequest)
+		return
+	}
+
+	records, err := ParseSettlementFile(format, http.MaxBytesReader(w, r.Body, maxSettlementFileSize))
+	if err != nil {
+		http.Error(w, "invalid settlement file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := h.reconciler.Ingest(r.Context(), providerID, records)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to ingest settlements", "provider", providerID, "error", err)
+		http.Error(w, "failed to ingest settlements", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// HandleGetSettlementReports returns the per-OGA settlement reports of a UTC day.
+// GET /api/v1/admin/payments/settlement-reports?date=YYYY-MM-DD[&orgId=...]
+func (h *HTTPHandler) HandleGetSettlementReports(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	day, err := time.Parse(time.DateOnly, r.URL.Query().Get("date"))
+	if err != nil {
+		http.Error(w, "date is required in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	reports, err := h.reconciler.DailyReports(r.Context(), day, r.URL.Query().Get("orgId"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to build settlement reports", "date", day.Format(time.DateOnly), "error", err)
+		http.Error(w, "failed to build settlement reports", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, reports)
+}
+
+// HandleGetTaskLedger returns the payments and refunds recorded for a task and the net amount held.
+// GET /api/v1/admin/payments/tasks/{taskId}/ledger
+func (h *HTTPHandler) HandleGetTaskLedger(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	taskID := r.PathValue("taskId")
+	if taskID == "" {
+		http.Error(w, "task ID is required in URL", http.StatusBadRequest)
+		return
+	}
+
+	ledger, err := h.ledger.GetTaskLedger(r.Context(), taskID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read payment ledger", "taskID", taskID, "error", err)
+		http.Error(w, "failed to read payment ledger", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, ledger)
+}
+
+// HandleRequestRefund refunds a task's payment, e.g. after the OGA rejected the application or
+// the trader overpaid. The refund completes when the gateway confirms it.
+// POST /api/v1/admin/payments/tasks/{taskId}/refunds
+func (h *HTTPHandler) HandleRequestRefund(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	taskID := r.PathValue("taskId")
+	if taskID == "" {
+		http.Error(w, "task ID is required in URL", http.StatusBadRequest)
+		return
+	}
+	var req RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Amount.IsNegative() {
+		http.Error(w, "amount must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.requestRefund(r.Context(), taskID, req.Amount, req.Reason); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, paymentsv2.ErrRefundNotAllowed):
+			status = http.StatusConflict
+		case errors.Is(err, paymentsv2.ErrTransactionNotFound):
+			status = http.StatusNotFound
+		}
+		slog.WarnContext(r.Context(), "failed to request refund", "taskID", taskID, "error", err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "Refund requested"})
+}
+
+// authorize admits administrators, writing the error response otherwise.
+func (h *HTTPHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	err := h.authorizer.AuthorizeRole(r.Context(), authz.RoleAdmin)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, authz.ErrUnauthenticated):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, authz.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		slog.ErrorContext(r.Context(), "failed to authorize reconciliation access", "error", err)
+		http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+	}
+	return false
+}
+
+// requestFormat determines the settlement file format of the request.
+func requestFormat(r *http.Request) (Format, bool) {
+	switch Format(r.URL.Query().Get("format")) {
+	case FormatCSV:
+		return FormatCSV, true
+	case FormatJSON:
+		return FormatJSON, true
+	case "":
+	default:
+		return "", false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/json":
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+	}
+}
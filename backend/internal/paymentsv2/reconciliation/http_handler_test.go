@@ -0,0 +1,161 @@
+package reconciliation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+)
+
+// stubAuthorizer returns a fixed authorization result; the zero value allows everything.
+type stubAuthorizer struct {
+	err error
+}
+
+func (a stubAuthorizer) AuthorizeRole(context.Context, ...string) error {
+	return a.err
+}
+
+func TestHTTPHandler_HandleIngestSettlements(t *testing.T) {
+	file := "reference_number,gateway_transaction_id,amount,currency,settled_at\nREF-OK,gw-1,1500,LKR,2026-10-15\n"
+	newRequest := func(target, contentType string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(file))
+		req.SetPathValue("providerId", "mock")
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	t.Run("ingests a CSV file", func(t *testing.T) {
+		r, _ := newTestReconciler()
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(r, nil, nil, stubAuthorizer{}).HandleIngestSettlements(rec, newRequest("/api/v1/admin/payments/mock/settlements", "text/csv"))
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var result IngestResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, 1, result.Matched)
+		assert.Empty(t, result.Flagged)
+	})
+
+	t.Run("rejects an unknown format", func(t *testing.T) {
+		r, _ := newTestReconciler()
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(r, nil, nil, stubAuthorizer{}).HandleIngestSettlements(rec, newRequest("/api/v1/admin/payments/mock/settlements?format=xml", "text/csv"))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		r, store := newTestReconciler()
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(r, nil, nil, stubAuthorizer{err: authz.ErrForbidden}).HandleIngestSettlements(rec, newRequest("/api/v1/admin/payments/mock/settlements", "text/csv"))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, store.settlements)
+	})
+}
+
+func TestHTTPHandler_HandleGetSettlementReports(t *testing.T) {
+	r, _ := newTestReconciler()
+	handler := NewHTTPHandler(r, nil, nil, stubAuthorizer{})
+
+	rec := httptest.NewRecorder()
+	handler.HandleGetSettlementReports(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/payments/settlement-reports?date=2026-10-15", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[]`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.HandleGetSettlementReports(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/payments/settlement-reports?date=15-10-2026", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	NewHTTPHandler(r, nil, nil, stubAuthorizer{err: authz.ErrUnauthenticated}).HandleGetSettlementReports(rec,
+		httptest.NewRequest(http.MethodGet, "/api/v1/admin/payments/settlement-reports?date=2026-10-15", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+type staticLedger map[string]*paymentsv2.TaskLedger
+
+func (l staticLedger) GetTaskLedger(_ context.Context, taskID string) (*paymentsv2.TaskLedger, error) {
+	if ledger, ok := l[taskID]; ok {
+		return ledger, nil
+	}
+	return &paymentsv2.TaskLedger{TaskID: taskID}, nil
+}
+
+func TestHTTPHandler_HandleGetTaskLedger(t *testing.T) {
+	r, _ := newTestReconciler()
+	ledger := staticLedger{"task-1": {TaskID: "task-1", Currency: "LKR", Paid: decimal.NewFromInt(1500), Refunded: decimal.NewFromInt(500), Net: decimal.NewFromInt(1000)}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/payments/tasks/task-1/ledger", nil)
+	req.SetPathValue("taskId", "task-1")
+	rec := httptest.NewRecorder()
+	NewHTTPHandler(r, ledger, nil, stubAuthorizer{}).HandleGetTaskLedger(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var got paymentsv2.TaskLedger
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.True(t, got.Net.Equal(decimal.NewFromInt(1000)))
+
+	rec = httptest.NewRecorder()
+	NewHTTPHandler(r, ledger, nil, stubAuthorizer{err: authz.ErrForbidden}).HandleGetTaskLedger(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHTTPHandler_HandleRequestRefund(t *testing.T) {
+	type refundCall struct {
+		taskID string
+		amount decimal.Decimal
+		reason string
+	}
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/payments/tasks/task-1/refunds", strings.NewReader(body))
+		req.SetPathValue("taskId", "task-1")
+		return req
+	}
+	r, _ := newTestReconciler()
+
+	t.Run("requests the refund through the task", func(t *testing.T) {
+		var calls []refundCall
+		requestRefund := func(_ context.Context, taskID string, amount decimal.Decimal, reason string) error {
+			calls = append(calls, refundCall{taskID, amount, reason})
+			return nil
+		}
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(r, nil, requestRefund, stubAuthorizer{}).HandleRequestRefund(rec, newRequest(`{"amount":"500","reason":"application rejected"}`))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		if assert.Len(t, calls, 1) {
+			assert.Equal(t, "task-1", calls[0].taskID)
+			assert.True(t, calls[0].amount.Equal(decimal.NewFromInt(500)))
+			assert.Equal(t, "application rejected", calls[0].reason)
+		}
+	})
+
+	t.Run("maps refusals to conflict", func(t *testing.T) {
+		requestRefund := func(context.Context, string, decimal.Decimal, string) error {
+			return fmt.Errorf("failed to execute task: %w", paymentsv2.ErrRefundNotAllowed)
+		}
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(r, nil, requestRefund, stubAuthorizer{}).HandleRequestRefund(rec, newRequest(`{}`))
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("rejects a negative amount", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewHTTPHandler(r, nil, nil, stubAuthorizer{}).HandleRequestRefund(rec, newRequest(`{"amount":"-1"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,117 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+	"github.com/OpenNSW/nsw/internal/profile/company"
+	"github.com/OpenNSW/nsw/internal/profile/user"
+)
+
+// UserLookup loads user profiles; satisfied by user.Service.
+type UserLookup interface {
+	GetUser(id string) (*user.Record, error)
+}
+
+// CompanyLookup loads company profiles; satisfied by company.Service.
+type CompanyLookup interface {
+	GetCompanyByOUId(ctx context.Context, ouID string) (*company.Record, error)
+	GetCompanyByOUHandle(ctx context.Context, ouHandle string) (*company.Record, error)
+}
+
+// Directory names the parties to a payment. The trader is the company of the user who owns the
+// payment task's consignment or pre-consignment; the OGA is the company whose IdP
+// organisational unit handle matches the payment's org_id. Where no record exists, the
+// underlying ID is used as the name.
+type Directory struct {
+	tasks     authz.TaskLookup
+	users     UserLookup
+	companies CompanyLookup
+	resolvers []authz.PartyResolver
+}
+
+// NewDirectory creates a Directory. Resolvers are consulted in order to find a workflow's trader.
+func NewDirectory(tasks authz.TaskLookup, users UserLookup, companies CompanyLookup, resolvers ...authz.PartyResolver) *Directory {
+	return &Directory{tasks: tasks, users: users, companies: companies, resolvers: resolvers}
+}
+
+// ResolvePartyNames implements paymentsv2.NameResolver.
+func (d *Directory) ResolvePartyNames(ctx context.Context, tx *paymentsv2.PaymentTransaction) (*paymentsv2.PartyNames, error) {
+	traderName, err := d.TraderName(ctx, tx.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	ogaName, err := d.OGAName(ctx, tx.GatewayMetadata["org_id"])
+	if err != nil {
+		return nil, err
+	}
+	return &paymentsv2.PartyNames{TraderName: traderName, OGAName: ogaName}, nil
+}
+
+// TraderName returns the name of the trader who owns the workflow of the given task.
+func (d *Directory) TraderName(ctx context.Context, taskID string) (string, error) {
+	if taskID == "" {
+		return "", fmt.Errorf("task_id is required")
+	}
+	task, err := d.tasks.GetByID(taskID)
+	if err != nil {
+		return "", fmt.Errorf("task %s not found: %w", taskID, err)
+	}
+
+	var parties *authz.Parties
+	for _, resolver := range d.resolvers {
+		parties, err = resolver.WorkflowParties(ctx, task.WorkflowID)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve parties of workflow %s: %w", task.WorkflowID, err)
+		}
+		if parties != nil {
+			break
+		}
+	}
+	if parties == nil || parties.TraderID == "" {
+		return "", fmt.Errorf("workflow %s has no trader", task.WorkflowID)
+	}
+
+	record, err := d.users.GetUser(parties.TraderID)
+	if errors.Is(err, user.ErrUserNotFound) {
+		return parties.TraderID, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load trader %s: %w", parties.TraderID, err)
+	}
+	if record.OUID != "" {
+		org, err := d.companies.GetCompanyByOUId(ctx, record.OUID)
+		if err == nil {
+			return org.Name, nil
+		}
+		if !errors.Is(err, company.ErrCompanyNotFound) {
+			return "", fmt.Errorf("failed to load company of trader %s: %w", parties.TraderID, err)
+		}
+	}
+	if record.Email != "" {
+		return record.Email, nil
+	}
+	return parties.TraderID, nil
+}
+
+// OGAName returns the name of the agency a payment is collected for, given its org_id.
+func (d *Directory) OGAName(ctx context.Context, orgID string) (string, error) {
+	if orgID == "" {
+		return "", nil
+	}
+	// Payment configs conventionally use upper-case org IDs, while OU handles are lower case.
+	for _, handle := range []string{orgID, strings.ToLower(orgID)} {
+		org, err := d.companies.GetCompanyByOUHandle(ctx, handle)
+		if err == nil {
+			return org.Name, nil
+		}
+		if !errors.Is(err, company.ErrCompanyNotFound) {
+			return "", fmt.Errorf("failed to load OGA %s: %w", orgID, err)
+		}
+	}
+	return orgID, nil
+}
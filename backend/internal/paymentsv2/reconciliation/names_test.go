@@ -0,0 +1,117 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+	"github.com/OpenNSW/nsw/internal/profile/company"
+	"github.com/OpenNSW/nsw/internal/profile/user"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
+)
+
+type fakeTasks map[string]*persistence.TaskInfo
+
+func (f fakeTasks) GetByID(id string) (*persistence.TaskInfo, error) {
+	if task, ok := f[id]; ok {
+		return task, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+type fakeResolver map[string]*authz.Parties
+
+func (f fakeResolver) WorkflowParties(_ context.Context, workflowID string) (*authz.Parties, error) {
+	return f[workflowID], nil
+}
+
+type fakeUsers map[string]*user.Record
+
+func (f fakeUsers) GetUser(id string) (*user.Record, error) {
+	if record, ok := f[id]; ok {
+		return record, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+type fakeCompanies []company.Record
+
+func (f fakeCompanies) GetCompanyByOUId(_ context.Context, ouID string) (*company.Record, error) {
+	for i := range f {
+		if f[i].OUID == ouID {
+			return &f[i], nil
+		}
+	}
+	return nil, company.ErrCompanyNotFound
+}
+
+func (f fakeCompanies) GetCompanyByOUHandle(_ context.Context, ouHandle string) (*company.Record, error) {
+	for i := range f {
+		if f[i].OUHandle == ouHandle {
+			return &f[i], nil
+		}
+	}
+	return nil, company.ErrCompanyNotFound
+}
+
+func newTestDirectory() *Directory {
+	tasks := fakeTasks{
+		"task-1": {ID: "task-1", WorkflowID: "c-1"},
+		"task-2": {ID: "task-2", WorkflowID: "pc-1"},
+		"task-3": {ID: "task-3", WorkflowID: "c-2"},
+		"task-4": {ID: "task-4", WorkflowID: "unknown"},
+	}
+	users := fakeUsers{
+		"trader-1": {ID: "trader-1", Email: "trader@abcd.lk", OUID: "abcd-traders-id"},
+		"trader-2": {ID: "trader-2", Email: "solo@example.com"},
+	}
+	companies := fakeCompanies{
+		{ID: "abcd-traders", Name: "ABCD Traders", OUID: "abcd-traders-id", OUHandle: "abcd-traders"},
+		{ID: "customs", Name: "Sri Lanka Customs", OUID: "customs-id", OUHandle: "customs"},
+	}
+	return NewDirectory(tasks, users, companies,
+		fakeResolver{"c-1": {TraderID: "trader-1"}, "c-2": {TraderID: "trader-3"}},
+		fakeResolver{"pc-1": {TraderID: "trader-2"}},
+	)
+}
+
+func TestDirectory_TraderName(t *testing.T) {
+	d := newTestDirectory()
+	tests := []struct {
+		taskID string
+		want   string
+	}{
+		{"task-1", "ABCD Traders"},     // the trader's company
+		{"task-2", "solo@example.com"}, // no company on record
+		{"task-3", "trader-3"},         // no user profile
+	}
+	for _, tt := range tests {
+		name, err := d.TraderName(context.Background(), tt.taskID)
+		require.NoError(t, err, tt.taskID)
+		assert.Equal(t, tt.want, name, tt.taskID)
+	}
+
+	_, err := d.TraderName(context.Background(), "task-4")
+	assert.ErrorContains(t, err, "has no trader")
+	_, err = d.TraderName(context.Background(), "missing")
+	assert.ErrorContains(t, err, "task missing not found")
+}
+
+func TestDirectory_ResolvePartyNames(t *testing.T) {
+	d := newTestDirectory()
+
+	names, err := d.ResolvePartyNames(context.Background(), &paymentsv2.PaymentTransaction{
+		TaskID: "task-1", GatewayMetadata: map[string]string{"org_id": "CUSTOMS"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &paymentsv2.PartyNames{TraderName: "ABCD Traders", OGAName: "Sri Lanka Customs"}, names)
+
+	ogaName, err := d.OGAName(context.Background(), "NPQS")
+	require.NoError(t, err)
+	assert.Equal(t, "NPQS", ogaName, "unknown OGAs fall back to their org ID")
+}
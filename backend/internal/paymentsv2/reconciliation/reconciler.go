@@ -0,0 +1,239 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+)
+
+// PartyNamer names the trader and OGA of a payment; satisfied by Directory.
+type PartyNamer interface {
+	TraderName(ctx context.Context, taskID string) (string, error)
+	OGAName(ctx context.Context, orgID string) (string, error)
+}
+
+// IngestResult summarises an ingested settlement file.
+type IngestResult struct {
+	BatchID  string       `json:"batchId"`
+	Records  int          `json:"records"`  // Settlements in the file
+	Inserted int          `json:"inserted"` // Settlements not ingested before
+	Matched  int          `json:"matched"`
+	Flagged  []Settlement `json:"flagged"` // Settlements that did not match a successful transaction
+}
+
+// SettlementReport summarises the settlements of one OGA in one currency on one UTC day.
+// Settlements whose reference matched no transaction are reported under an empty OrgID.
+type SettlementReport struct {
+	Date          string                 `json:"date"` // YYYY-MM-DD
+	OrgID         string                 `json:"orgId"`
+	OGAName       string                 `json:"ogaName"`
+	Currency      string                 `json:"currency"`
+	Count         int                    `json:"count"`
+	SettledAmount decimal.Decimal        `json:"settledAmount"`
+	Matched       int                    `json:"matched"`
+	Flagged       int                    `json:"flagged"`
+	Items         []SettlementReportItem `json:"items"`
+}
+
+// SettlementReportItem is one settlement in a SettlementReport.
+type SettlementReportItem struct {
+	ReferenceNumber      string          `json:"referenceNumber"`
+	GatewayTransactionID string          `json:"gatewayTransactionId"`
+	ProviderID           string          `json:"providerId"`
+	TraderName           string          `json:"traderName,omitempty"`
+	Amount               decimal.Decimal `json:"amount"`
+	ExpectedAmount       decimal.Decimal `json:"expectedAmount"`
+	MatchStatus          MatchStatus     `json:"matchStatus"`
+	SettledAt            time.Time       `json:"settledAt"`
+}
+
+// Reconciler matches gateway settlements against payment transactions and reports on them.
+type Reconciler struct {
+	store Store
+	names PartyNamer
+}
+
+// NewReconciler creates a Reconciler.
+func NewReconciler(store Store, names PartyNamer) *Reconciler {
+	return &Reconciler{store: store, names: names}
+}
+
+// Ingest matches a provider's settlement records by reference number and stores the results.
+// Ingesting the same records again stores nothing new.
+func (r *Reconciler) Ingest(ctx context.Context, providerID string, records []SettlementRecord) (*IngestResult, error) {
+	if providerID == "" {
+		return nil, fmt.Errorf("provider ID is required")
+	}
+	references := make([]string, 0, len(records))
+	for _, record := range records {
+		references = append(references, record.ReferenceNumber)
+	}
+	txs, err := r.store.FindTransactions(ctx, references)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IngestResult{BatchID: uuid.NewString(), Records: len(records), Flagged: []Settlement{}}
+	settlements := make([]Settlement, 0, len(records))
+	seen := make(map[string]bool, len(records))
+	for _, record := range records {
+		key := record.ReferenceNumber + "\x00" + record.GatewayTransactionID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var tx *paymentsv2.PaymentTransaction
+		if found, ok := txs[record.ReferenceNumber]; ok {
+			tx = &found
+		}
+		settlement := match(result.BatchID, providerID, record, tx)
+		settlements = append(settlements, settlement)
+		if settlement.MatchStatus == MatchStatusMatched {
+			result.Matched++
+			continue
+		}
+		result.Flagged = append(result.Flagged, settlement)
+		slog.Warn("settlement does not match payment records",
+			"provider", providerID,
+			"reference", settlement.ReferenceNumber,
+			"match_status", settlement.MatchStatus,
+			"settled", settlement.Amount.String()+" "+settlement.Currency,
+			"expected", settlement.ExpectedAmount.String()+" "+settlement.ExpectedCurrency)
+	}
+
+	inserted, err := r.store.SaveSettlements(ctx, settlements)
+	if err != nil {
+		return nil, err
+	}
+	result.Inserted = inserted
+
+	slog.Info("ingested payment settlements",
+		"provider", providerID, "batch_id", result.BatchID,
+		"records", result.Records, "inserted", result.Inserted,
+		"matched", result.Matched, "flagged", len(result.Flagged))
+	return result, nil
+}
+
+// match compares a settlement record with the transaction of the same reference, if any.
+func match(batchID, providerID string, record SettlementRecord, tx *paymentsv2.PaymentTransaction) Settlement {
+	settlement := Settlement{
+		BatchID:              batchID,
+		ProviderID:           providerID,
+		ReferenceNumber:      record.ReferenceNumber,
+		GatewayTransactionID: record.GatewayTransactionID,
+		Amount:               record.Amount,
+		Currency:             record.Currency,
+		SettledAt:            record.SettledAt,
+	}
+	if tx == nil {
+		settlement.MatchStatus = MatchStatusUnknownReference
+		return settlement
+	}
+
+	settlement.ExpectedAmount = tx.Amount
+	settlement.ExpectedCurrency = tx.Currency
+	settlement.TransactionStatus = string(tx.Status)
+	settlement.TaskID = tx.TaskID
+	settlement.OrgID = tx.GatewayMetadata["org_id"]
+
+	switch {
+	case tx.ProviderID != providerID:
+		settlement.MatchStatus = MatchStatusProviderMismatch
+	case !record.Amount.Equal(tx.Amount) || record.Currency != tx.Currency:
+		settlement.MatchStatus = MatchStatusAmountMismatch
+	case !tx.Status.IsPaid():
+		settlement.MatchStatus = MatchStatusStatusMismatch
+	default:
+		settlement.MatchStatus = MatchStatusMatched
+	}
+	return settlement
+}
+
+// DailyReports returns the settlement reports of the UTC day containing day, one per OGA and
+// currency, ordered by OGA. If orgID is set, only that OGA's reports are returned.
+func (r *Reconciler) DailyReports(ctx context.Context, day time.Time, orgID string) ([]SettlementReport, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	settlements, err := r.store.ListSettlements(ctx, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	type reportKey struct{ orgID, currency string }
+	reports := make(map[reportKey]*SettlementReport)
+	traderNames := make(map[string]string)
+	for _, settlement := range settlements {
+		if orgID != "" && settlement.OrgID != orgID {
+			continue
+		}
+		key := reportKey{settlement.OrgID, settlement.Currency}
+		report, ok := reports[key]
+		if !ok {
+			report = &SettlementReport{
+				Date:     from.Format(time.DateOnly),
+				OrgID:    settlement.OrgID,
+				Currency: settlement.Currency,
+				Items:    []SettlementReportItem{},
+			}
+			report.OGAName, err = r.names.OGAName(ctx, settlement.OrgID)
+			if err != nil {
+				return nil, err
+			}
+			reports[key] = report
+		}
+
+		report.Count++
+		report.SettledAmount = report.SettledAmount.Add(settlement.Amount)
+		if settlement.MatchStatus == MatchStatusMatched {
+			report.Matched++
+		} else {
+			report.Flagged++
+		}
+		report.Items = append(report.Items, SettlementReportItem{
+			ReferenceNumber:      settlement.ReferenceNumber,
+			GatewayTransactionID: settlement.GatewayTransactionID,
+			ProviderID:           settlement.ProviderID,
+			TraderName:           r.traderName(ctx, traderNames, settlement.TaskID),
+			Amount:               settlement.Amount,
+			ExpectedAmount:       settlement.ExpectedAmount,
+			MatchStatus:          settlement.MatchStatus,
+			SettledAt:            settlement.SettledAt,
+		})
+	}
+
+	result := make([]SettlementReport, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, *report)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].OrgID != result[j].OrgID {
+			return result[i].OrgID < result[j].OrgID
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	return result, nil
+}
+
+// traderName resolves the trader of a task once per report. A trader that cannot be resolved
+// is left blank rather than failing the whole report.
+func (r *Reconciler) traderName(ctx context.Context, cache map[string]string, taskID string) string {
+	if taskID == "" {
+		return ""
+	}
+	if name, ok := cache[taskID]; ok {
+		return name
+	}
+	name, err := r.names.TraderName(ctx, taskID)
+	if err != nil {
+		slog.Warn("failed to resolve trader for settlement report", "task_id", taskID, "error", err)
+	}
+	cache[taskID] = name
+	return name
+}
@@ -0,0 +1,209 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+)
+
+// memoryStore is an in-memory Store.
+type memoryStore struct {
+	txs         map[string]paymentsv2.PaymentTransaction
+	settlements []Settlement
+}
+
+func (s *memoryStore) FindTransactions(_ context.Context, references []string) (map[string]paymentsv2.PaymentTransaction, error) {
+	found := make(map[string]paymentsv2.PaymentTransaction)
+	for _, ref := range references {
+		if tx, ok := s.txs[ref]; ok {
+			found[ref] = tx
+		}
+	}
+	return found, nil
+}
+
+func (s *memoryStore) SaveSettlements(_ context.Context, settlements []Settlement) (int, error) {
+	inserted := 0
+	for _, settlement := range settlements {
+		duplicate := false
+		for _, existing := range s.settlements {
+			if existing.ProviderID == settlement.ProviderID && existing.ReferenceNumber == settlement.ReferenceNumber &&
+				existing.GatewayTransactionID == settlement.GatewayTransactionID {
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			s.settlements = append(s.settlements, settlement)
+			inserted++
+		}
+	}
+	return inserted, nil
+}
+
+func (s *memoryStore) ListSettlements(_ context.Context, from, to time.Time) ([]Settlement, error) {
+	var listed []Settlement
+	for _, settlement := range s.settlements {
+		if !settlement.SettledAt.Before(from) && settlement.SettledAt.Before(to) {
+			listed = append(listed, settlement)
+		}
+	}
+	return listed, nil
+}
+
+func (s *memoryStore) ExpireStale(_ context.Context, now time.Time) (int64, error) {
+	var expired int64
+	for ref, tx := range s.txs {
+		if tx.Status == paymentsv2.PaymentStatusPending && tx.ExpiryDate.Before(now) {
+			tx.Status = paymentsv2.PaymentStatusExpired
+			s.txs[ref] = tx
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// staticNamer names traders and OGAs from maps.
+type staticNamer struct {
+	traders map[string]string // task ID -> trader name
+	ogas    map[string]string // org ID -> OGA name
+}
+
+func (n staticNamer) TraderName(_ context.Context, taskID string) (string, error) {
+	if name, ok := n.traders[taskID]; ok {
+		return name, nil
+	}
+	return "", errors.New("task not found")
+}
+
+func (n staticNamer) OGAName(_ context.Context, orgID string) (string, error) {
+	if name, ok := n.ogas[orgID]; ok {
+		return name, nil
+	}
+	return orgID, nil
+}
+
+func newTestReconciler() (*Reconciler, *memoryStore) {
+	tx := func(ref, taskID, orgID, provider string, amount int64, status paymentsv2.PaymentStatus) paymentsv2.PaymentTransaction {
+		return paymentsv2.PaymentTransaction{
+			ID: "id-" + ref, ReferenceNumber: ref, TaskID: taskID, ProviderID: provider,
+			Amount: decimal.NewFromInt(amount), Currency: "LKR", Status: status,
+			GatewayMetadata: map[string]string{"org_id": orgID},
+		}
+	}
+	store := &memoryStore{txs: map[string]paymentsv2.PaymentTransaction{
+		"REF-OK":       tx("REF-OK", "task-1", "CUSTOMS", "mock", 1500, paymentsv2.PaymentStatusSuccess),
+		"REF-AMOUNT":   tx("REF-AMOUNT", "task-2", "CUSTOMS", "mock", 1500, paymentsv2.PaymentStatusSuccess),
+		"REF-PENDING":  tx("REF-PENDING", "task-3", "NPQS", "mock", 300, paymentsv2.PaymentStatusPending),
+		"REF-PROVIDER": tx("REF-PROVIDER", "task-4", "NPQS", "lankapay", 300, paymentsv2.PaymentStatusSuccess),
+	}}
+	names := staticNamer{
+		traders: map[string]string{"task-1": "ABCD Traders", "task-2": "XYZ Exports", "task-3": "ABCD Traders"},
+		ogas:    map[string]string{"CUSTOMS": "Sri Lanka Customs"},
+	}
+	return NewReconciler(store, names), store
+}
+
+func settled(ref, gatewayID string, amount int64, at time.Time) SettlementRecord {
+	return SettlementRecord{ReferenceNumber: ref, GatewayTransactionID: gatewayID, Amount: decimal.NewFromInt(amount), Currency: "LKR", SettledAt: at}
+}
+
+func TestReconciler_Ingest(t *testing.T) {
+	r, store := newTestReconciler()
+	at := time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)
+	records := []SettlementRecord{
+		settled("REF-OK", "gw-1", 1500, at),
+		settled("REF-OK", "gw-1", 1500, at), // repeated line
+		settled("REF-AMOUNT", "gw-2", 1400, at),
+		settled("REF-PENDING", "gw-3", 300, at),
+		settled("REF-PROVIDER", "gw-4", 300, at),
+		settled("REF-UNKNOWN", "gw-5", 100, at),
+	}
+
+	result, err := r.Ingest(context.Background(), "mock", records)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, result.Records)
+	assert.Equal(t, 5, result.Inserted)
+	assert.Equal(t, 1, result.Matched)
+	statuses := map[string]MatchStatus{}
+	for _, flagged := range result.Flagged {
+		statuses[flagged.ReferenceNumber] = flagged.MatchStatus
+	}
+	assert.Equal(t, map[string]MatchStatus{
+		"REF-AMOUNT":   MatchStatusAmountMismatch,
+		"REF-PENDING":  MatchStatusStatusMismatch,
+		"REF-PROVIDER": MatchStatusProviderMismatch,
+		"REF-UNKNOWN":  MatchStatusUnknownReference,
+	}, statuses)
+
+	amount := store.settlements[1]
+	assert.Equal(t, "REF-AMOUNT", amount.ReferenceNumber)
+	assert.True(t, amount.ExpectedAmount.Equal(decimal.NewFromInt(1500)))
+	assert.Equal(t, "CUSTOMS", amount.OrgID)
+	assert.Equal(t, "task-2", amount.TaskID)
+
+	// Ingesting the same file again stores nothing new.
+	again, err := r.Ingest(context.Background(), "mock", records)
+	require.NoError(t, err)
+	assert.Equal(t, 0, again.Inserted)
+	assert.Len(t, store.settlements, 5)
+}
+
+func TestReconciler_DailyReports(t *testing.T) {
+	r, _ := newTestReconciler()
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	_, err := r.Ingest(context.Background(), "mock", []SettlementRecord{
+		settled("REF-OK", "gw-1", 1500, day.Add(9*time.Hour)),
+		settled("REF-AMOUNT", "gw-2", 1400, day.Add(23*time.Hour)),
+		settled("REF-PENDING", "gw-3", 300, day.Add(12*time.Hour)),
+		settled("REF-UNKNOWN", "gw-5", 100, day.Add(12*time.Hour)),
+		settled("REF-OK", "gw-6", 1500, day.Add(24*time.Hour)), // next day
+	})
+	require.NoError(t, err)
+
+	reports, err := r.DailyReports(context.Background(), day.Add(15*time.Hour), "")
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+
+	assert.Equal(t, "", reports[0].OrgID) // unmatched reference
+	customs := reports[1]
+	assert.Equal(t, "2026-10-15", customs.Date)
+	assert.Equal(t, "CUSTOMS", customs.OrgID)
+	assert.Equal(t, "Sri Lanka Customs", customs.OGAName)
+	assert.Equal(t, 2, customs.Count)
+	assert.True(t, customs.SettledAmount.Equal(decimal.NewFromInt(2900)))
+	assert.Equal(t, 1, customs.Matched)
+	assert.Equal(t, 1, customs.Flagged)
+	assert.Equal(t, "ABCD Traders", customs.Items[0].TraderName)
+	assert.Equal(t, "XYZ Exports", customs.Items[1].TraderName)
+	assert.Equal(t, "NPQS", reports[2].OGAName)
+
+	filtered, err := r.DailyReports(context.Background(), day, "NPQS")
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "NPQS", filtered[0].OrgID)
+}
+
+func TestExpirer_ExpireStale(t *testing.T) {
+	_, store := newTestReconciler()
+	pending := store.txs["REF-PENDING"]
+	pending.ExpiryDate = time.Now().Add(-time.Minute)
+	store.txs["REF-PENDING"] = pending
+	fresh := pending
+	fresh.ReferenceNumber = "REF-FRESH"
+	fresh.ExpiryDate = time.Now().Add(time.Hour)
+	store.txs["REF-FRESH"] = fresh
+
+	expired, err := NewExpirer(store, 0).ExpireStale(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+	assert.Equal(t, paymentsv2.PaymentStatusExpired, store.txs["REF-PENDING"].Status)
+	assert.Equal(t, paymentsv2.PaymentStatusPending, store.txs["REF-FRESH"].Status)
+}
@@ -0,0 +1,153 @@
+// Package reconciliation matches the payments a gateway reports as settled against the
+// payment_transactions NSW recorded, expires checkout sessions that were never paid, and
+// produces per-OGA daily settlement reports.
+package reconciliation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Format is the encoding of a gateway settlement file.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// SettlementRecord is one payment a gateway reports as settled.
+type SettlementRecord struct {
+	ReferenceNumber      string          `json:"reference_number"`
+	GatewayTransactionID string          `json:"gateway_transaction_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	SettledAt            time.Time       `json:"settled_at"`
+}
+
+// csvColumns are the columns a CSV settlement file must have, in any order.
+var csvColumns = []string{"reference_number", "gateway_transaction_id", "amount", "currency", "settled_at"}
+
+// ParseSettlementFile reads a settlement file. CSV files need a header row naming the
+// csvColumns; JSON files hold a {"settlements": [...]} object. Settlement times are RFC 3339
+// timestamps or YYYY-MM-DD dates.
+func ParseSettlementFile(format Format, r io.Reader) ([]SettlementRecord, error) {
+	var records []SettlementRecord
+	var err error
+	switch format {
+	case FormatCSV:
+		records, err = parseCSV(r)
+	case FormatJSON:
+		records, err = parseJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported settlement file format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, record := range records {
+		if record.ReferenceNumber == "" {
+			return nil, fmt.Errorf("settlement %d: reference_number is required", i+1)
+		}
+		if record.Currency == "" {
+			return nil, fmt.Errorf("settlement %d: currency is required", i+1)
+		}
+		if record.SettledAt.IsZero() {
+			return nil, fmt.Errorf("settlement %d: settled_at is required", i+1)
+		}
+	}
+	return records, nil
+}
+
+func parseJSON(r io.Reader) ([]SettlementRecord, error) {
+	var raw struct {
+		Settlements []struct {
+			SettlementRecord
+			SettledAt string `json:"settled_at"`
+		} `json:"settlements"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode settlement file: %w", err)
+	}
+	records := make([]SettlementRecord, 0, len(raw.Settlements))
+	for i, entry := range raw.Settlements {
+		record := entry.SettlementRecord
+		settledAt, err := parseSettledAt(entry.SettledAt)
+		if err != nil {
+			return nil, fmt.Errorf("settlement %d: %w", i+1, err)
+		}
+		record.SettledAt = settledAt
+		record.Currency = strings.ToUpper(record.Currency)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseCSV(r io.Reader) ([]SettlementRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("settlement file is empty")
+		}
+		return nil, fmt.Errorf("failed to read settlement file header: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range csvColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("settlement file is missing column %q", column)
+		}
+	}
+
+	var records []SettlementRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read settlement file line %d: %w", line, err)
+		}
+		field := func(column string) string { return strings.TrimSpace(row[index[column]]) }
+
+		amount, err := decimal.NewFromString(field("amount"))
+		if err != nil {
+			return nil, fmt.Errorf("settlement file line %d: invalid amount %q", line, field("amount"))
+		}
+		settledAt, err := parseSettledAt(field("settled_at"))
+		if err != nil {
+			return nil, fmt.Errorf("settlement file line %d: %w", line, err)
+		}
+		records = append(records, SettlementRecord{
+			ReferenceNumber:      field("reference_number"),
+			GatewayTransactionID: field("gateway_transaction_id"),
+			Amount:               amount,
+			Currency:             strings.ToUpper(field("currency")),
+			SettledAt:            settledAt,
+		})
+	}
+}
+
+func parseSettledAt(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid settled_at %q: want RFC 3339 or YYYY-MM-DD", value)
+}
@@ -0,0 +1,63 @@
+package reconciliation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSettlementFile_CSV(t *testing.T) {
+	file := "settled_at,reference_number,amount,currency,gateway_transaction_id\n" +
+		"2026-10-15T09:30:00+05:30,NSW-PR-2026-AAAAAAAA,1500.00,lkr,gw-1\n" +
+		"2026-10-15,NSW-PR-2026-BBBBBBBB,250,LKR,gw-2\n"
+
+	records, err := ParseSettlementFile(FormatCSV, strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "NSW-PR-2026-AAAAAAAA", records[0].ReferenceNumber)
+	assert.Equal(t, "gw-1", records[0].GatewayTransactionID)
+	assert.True(t, records[0].Amount.Equal(decimal.NewFromInt(1500)))
+	assert.Equal(t, "LKR", records[0].Currency)
+	assert.Equal(t, time.Date(2026, 10, 15, 4, 0, 0, 0, time.UTC), records[0].SettledAt)
+	assert.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), records[1].SettledAt)
+}
+
+func TestParseSettlementFile_JSON(t *testing.T) {
+	file := `{"settlements":[{"reference_number":"NSW-PR-2026-AAAAAAAA","gateway_transaction_id":"gw-1",
+		"amount":"1500.00","currency":"LKR","settled_at":"2026-10-15T04:00:00Z"}]}`
+
+	records, err := ParseSettlementFile(FormatJSON, strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Amount.Equal(decimal.NewFromInt(1500)))
+	assert.Equal(t, time.Date(2026, 10, 15, 4, 0, 0, 0, time.UTC), records[0].SettledAt)
+}
+
+func TestParseSettlementFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		file    string
+		wantErr string
+	}{
+		{"unsupported format", "xml", "", "unsupported settlement file format"},
+		{"empty csv", FormatCSV, "", "empty"},
+		{"missing column", FormatCSV, "reference_number,amount,currency,settled_at\n", `missing column "gateway_transaction_id"`},
+		{"bad amount", FormatCSV, "reference_number,gateway_transaction_id,amount,currency,settled_at\nR1,g1,abc,LKR,2026-10-15\n", "line 2: invalid amount"},
+		{"bad date", FormatCSV, "reference_number,gateway_transaction_id,amount,currency,settled_at\nR1,g1,1,LKR,15/10/2026\n", "invalid settled_at"},
+		{"missing reference", FormatJSON, `{"settlements":[{"amount":"1","currency":"LKR","settled_at":"2026-10-15"}]}`, "reference_number is required"},
+		{"missing settled_at", FormatJSON, `{"settlements":[{"reference_number":"R1","amount":"1","currency":"LKR"}]}`, "settled_at is required"},
+		{"malformed json", FormatJSON, `[`, "failed to decode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSettlementFile(tt.format, strings.NewReader(tt.file))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
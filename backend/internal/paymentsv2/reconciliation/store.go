@@ -0,0 +1,140 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+)
+
+// MatchStatus is the outcome of matching a settlement against NSW's payment records.
+type MatchStatus string
+
+const (
+	MatchStatusMatched          MatchStatus = "MATCHED"           // Settled as recorded
+	MatchStatusAmountMismatch   MatchStatus = "AMOUNT_MISMATCH"   // Settled amount or currency differs from the transaction
+	MatchStatusStatusMismatch   MatchStatus = "STATUS_MISMATCH"   // Settled, but NSW never recorded the payment as paid
+	MatchStatusProviderMismatch MatchStatus = "PROVIDER_MISMATCH" // Settled by a different gateway than the transaction was created with
+	MatchStatusUnknownReference MatchStatus = "UNKNOWN_REFERENCE" // No transaction has the reference
+)
+
+// Settlement is an ingested settlement record together with the result of matching it.
+// Expected* and the payment fields are copied from the matched transaction, if any.
+type Settlement struct {
+	ID                   string          `gorm:"type:varchar(100);primaryKey" json:"id"`
+	BatchID              string          `gorm:"type:varchar(100);not null" json:"batchId"`
+	ProviderID           string          `gorm:"type:varchar(100);not null" json:"providerId"`
+	ReferenceNumber      string          `gorm:"type:varchar(255);not null" json:"referenceNumber"`
+	GatewayTransactionID string          `gorm:"type:varchar(255);not null" json:"gatewayTransactionId"`
+	Amount               decimal.Decimal `gorm:"type:numeric(15,2);not null" json:"amount"`
+	Currency             string          `gorm:"type:varchar(10);not null" json:"currency"`
+	SettledAt            time.Time       `gorm:"not null" json:"settledAt"`
+	MatchStatus          MatchStatus     `gorm:"type:varchar(50);not null" json:"matchStatus"`
+	ExpectedAmount       decimal.Decimal `gorm:"type:numeric(15,2)" json:"expectedAmount"`
+	ExpectedCurrency     string          `gorm:"type:varchar(10)" json:"expectedCurrency,omitempty"`
+	TransactionStatus    string          `gorm:"type:varchar(50)" json:"transactionStatus,omitempty"`
+	TaskID               string          `gorm:"type:varchar(255)" json:"taskId,omitempty"`
+	OrgID                string          `gorm:"type:varchar(100)" json:"orgId,omitempty"`
+	CreatedAt            time.Time       `json:"createdAt"`
+}
+
+// TableName returns the table name for Settlement.
+func (Settlement) TableName() string {
+	return "payment_settlements"
+}
+
+// Store persists settlements and the payment transactions they are matched against.
+type Store interface {
+	// FindTransactions returns the transactions with the given references, keyed by reference.
+	FindTransactions(ctx context.Context, references []string) (map[string]paymentsv2.PaymentTransaction, error)
+	// SaveSettlements inserts settlements, skipping any already ingested for the same provider,
+	// reference and gateway transaction, and returns how many were inserted.
+	SaveSettlements(ctx context.Context, settlements []Settlement) (int, error)
+	// ListSettlements returns the settlements with from <= SettledAt < to, oldest first.
+	ListSettlements(ctx context.Context, from, to time.Time) ([]Settlement, error)
+	// ExpireStale marks PENDING transactions whose session expired before now as EXPIRED.
+	ExpireStale(ctx context.Context, now time.Time) (int64, error)
+}
+
+// GormStore implements Store with GORM.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates a GormStore.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection cannot be nil")
+	}
+	return &GormStore{db: db}, nil
+}
+
+// FindTransactions loads the transactions with the given references.
+func (s *GormStore) FindTransactions(ctx context.Context, references []string) (map[string]paymentsv2.PaymentTransaction, error) {
+	found := make(map[string]paymentsv2.PaymentTransaction, len(references))
+	if len(references) == 0 {
+		return found, nil
+	}
+	var txs []paymentsv2.PaymentTransaction
+	if err := s.db.WithContext(ctx).Where("reference_number IN ?", references).Find(&txs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payment transactions: %w", err)
+	}
+	for _, tx := range txs {
+		found[tx.ReferenceNumber] = tx
+	}
+	return found, nil
+}
+
+// SaveSettlements inserts settlements, ignoring ones that were already ingested.
+func (s *GormStore) SaveSettlements(ctx context.Context, settlements []Settlement) (int, error) {
+	if len(settlements) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UTC()
+	for i := range settlements {
+		if settlements[i].ID == "" {
+			settlements[i].ID = uuid.NewString()
+		}
+		settlements[i].CreatedAt = now
+	}
+	result := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider_id"}, {Name: "reference_number"}, {Name: "gateway_transaction_id"}},
+			DoNothing: true,
+		}).
+		Create(&settlements)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to save settlements: %w", result.Error)
+	}
+	return int(result.RowsAffected), nil
+}
+
+// ListSettlements returns the settlements settled in [from, to).
+func (s *GormStore) ListSettlements(ctx context.Context, from, to time.Time) ([]Settlement, error) {
+	var settlements []Settlement
+	err := s.db.WithContext(ctx).
+		Where("settled_at >= ? AND settled_at < ?", from, to).
+		Order("settled_at ASC, reference_number ASC").
+		Find(&settlements).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlements: %w", err)
+	}
+	return settlements, nil
+}
+
+// ExpireStale expires pending transactions whose expiry date has passed.
+func (s *GormStore) ExpireStale(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Model(&paymentsv2.PaymentTransaction{}).
+		Where("status = ? AND expiry_date < ?", paymentsv2.PaymentStatusPending, now).
+		Updates(map[string]interface{}{"status": paymentsv2.PaymentStatusExpired, "updated_at": now})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to expire stale payments: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,66 @@
+package reconciliation
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB, mock
+}
+
+func TestGormStore_SaveSettlements(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormStore(db)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "payment_settlements"`) + `.*` +
+		regexp.QuoteMeta(`ON CONFLICT ("provider_id","reference_number","gateway_transaction_id") DO NOTHING`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	settlements := []Settlement{
+		{ProviderID: "mock", ReferenceNumber: "REF-1", GatewayTransactionID: "gw-1", Amount: decimal.NewFromInt(1), Currency: "LKR", MatchStatus: MatchStatusMatched},
+		{ProviderID: "mock", ReferenceNumber: "REF-2", GatewayTransactionID: "gw-2", Amount: decimal.NewFromInt(2), Currency: "LKR", MatchStatus: MatchStatusMatched},
+	}
+	inserted, err := store.SaveSettlements(context.Background(), settlements)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inserted)
+	assert.NotEmpty(t, settlements[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGormStore_ExpireStale(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormStore(db)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "payment_transactions" SET "status"=$1,"updated_at"=$2 WHERE status = $3 AND expiry_date < $4`)).
+		WithArgs("EXPIRED", now, "PENDING", now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	expired, err := store.ExpireStale(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), expired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
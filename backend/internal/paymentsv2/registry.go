@@ -0,0 +1,113 @@
+package paymentsv2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+)
+
+// ErrProviderNotFound is returned when a payment method is unknown, inactive or has no implementation.
+var ErrProviderNotFound = errors.New("payment provider not found")
+
+// PaymentMethodConfig is a single entry of payment_methods.json.
+type PaymentMethodConfig struct {
+	ID         string            `json:"id"`
+	IsActive   bool              `json:"is_active"`
+	RenderInfo PaymentRenderInfo `json:"render_info"`
+	Type       string            `json:"type"`                  // e.g., REDIRECT
+	GatewayURL string            `json:"gateway_url,omitempty"` // Informational; providers are configured with their own endpoints
+}
+
+// PaymentMethodsConfig is the root object of payment_methods.json.
+type PaymentMethodsConfig struct {
+	Version string                `json:"version"`
+	Methods []PaymentMethodConfig `json:"methods"`
+}
+
+// LoadPaymentMethods reads the payment methods configuration file.
+func LoadPaymentMethods(configPath string) (*PaymentMethodsConfig, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("paymentsv2: failed to read payment methods file: %w", err)
+	}
+	var cfg PaymentMethodsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("paymentsv2: failed to unmarshal payment methods: %w", err)
+	}
+	return &cfg, nil
+}
+
+type paymentRegistry struct {
+	providers map[string]PaymentProvider
+	infos     []PaymentProviderInfo // Active methods with an implementation, in display order
+}
+
+// NewRegistry loads payment_methods.json and maps each configured method to its provider implementation.
+func NewRegistry(configPath string, providers map[string]PaymentProvider) (PaymentRegistry, error) {
+	cfg, err := LoadPaymentMethods(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistryFromConfig(*cfg, providers)
+}
+
+// NewRegistryFromConfig builds a registry from an already loaded configuration. Active methods
+// without a provider implementation are skipped with a warning.
+func NewRegistryFromConfig(cfg PaymentMethodsConfig, providers map[string]PaymentProvider) (PaymentRegistry, error) {
+	r := &paymentRegistry{providers: make(map[string]PaymentProvider)}
+	seen := make(map[string]bool, len(cfg.Methods))
+	for _, method := range cfg.Methods {
+		if method.ID == "" {
+			return nil, fmt.Errorf("paymentsv2: payment method id is required")
+		}
+		if seen[method.ID] {
+			return nil, fmt.Errorf("paymentsv2: duplicate payment method %q", method.ID)
+		}
+		seen[method.ID] = true
+		if !method.IsActive {
+			continue
+		}
+		provider, ok := providers[method.ID]
+		if !ok || provider == nil {
+			slog.Warn("paymentsv2: payment method has no provider implementation, skipping", "method", method.ID)
+			continue
+		}
+		r.providers[method.ID] = provider
+		r.infos = append(r.infos, PaymentProviderInfo{
+			ID:         method.ID,
+			IsActive:   method.IsActive,
+			RenderInfo: method.RenderInfo,
+		})
+	}
+	sort.SliceStable(r.infos, func(i, j int) bool {
+		return r.infos[i].RenderInfo.DisplayOrder < r.infos[j].RenderInfo.DisplayOrder
+	})
+	return r, nil
+}
+
+// Get retrieves an active provider by its method ID.
+func (r *paymentRegistry) Get(id string) (PaymentProvider, error) {
+	provider, ok := r.providers[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, id)
+	}
+	return provider, nil
+}
+
+// ListInfo returns the active methods in display order.
+func (r *paymentRegistry) ListInfo() []PaymentProviderInfo {
+	infos := make([]PaymentProviderInfo, len(r.infos))
+	copy(infos, r.infos)
+	return infos
+}
+
+// GetDefault returns the provider of the first active method in display order.
+func (r *paymentRegistry) GetDefault() (PaymentProvider, error) {
+	if len(r.infos) == 0 {
+		return nil, fmt.Errorf("%w: no active payment methods", ErrProviderNotFound)
+	}
+	return r.providers[r.infos[0].ID], nil
+}
@@ -0,0 +1,69 @@
+package paymentsv2
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewRegistry(t *testing.T) {
+	config := `{
+		"version": "1.0",
+		"methods": [
+			{"id": "lankapay", "is_active": true, "render_info": {"display_name": "LankaPay", "display_order": 2}},
+			{"id": "mock", "is_active": true, "render_info": {"display_name": "Mock", "display_order": 1}},
+			{"id": "govpay", "is_active": false, "render_info": {"display_name": "GovPay", "display_order": 3}},
+			{"id": "unimplemented", "is_active": true, "render_info": {"display_name": "Soon", "display_order": 0}}
+		]
+	}`
+	path := filepath.Join(t.TempDir(), "payment_methods.json")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	providers := map[string]PaymentProvider{
+		"lankapay": NewMockProvider(MockProviderConfig{}),
+		"mock":     NewMockProvider(MockProviderConfig{}),
+		"govpay":   NewMockProvider(MockProviderConfig{}),
+	}
+
+	registry, err := NewRegistry(path, providers)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	infos := registry.ListInfo()
+	if len(infos) != 2 || infos[0].ID != "mock" || infos[1].ID != "lankapay" {
+		t.Errorf("ListInfo() = %+v, want active implemented methods in display order", infos)
+	}
+	if _, err := registry.Get("lankapay"); err != nil {
+		t.Errorf("Get(lankapay) error = %v", err)
+	}
+	for _, id := range []string{"govpay", "unimplemented", "missing"} {
+		if _, err := registry.Get(id); !errors.Is(err, ErrProviderNotFound) {
+			t.Errorf("Get(%s) error = %v, want ErrProviderNotFound", id, err)
+		}
+	}
+	if provider, err := registry.GetDefault(); err != nil || provider != providers["mock"] {
+		t.Errorf("GetDefault() = %v, %v, want the mock provider", provider, err)
+	}
+}
+
+func TestNewRegistry_Errors(t *testing.T) {
+	if _, err := NewRegistry(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	_, err := NewRegistryFromConfig(PaymentMethodsConfig{Methods: []PaymentMethodConfig{{ID: "a"}, {ID: "a"}}}, nil)
+	if err == nil {
+		t.Error("expected error for duplicate method")
+	}
+
+	empty, err := NewRegistryFromConfig(PaymentMethodsConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := empty.GetDefault(); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("GetDefault() error = %v, want ErrProviderNotFound", err)
+	}
+}
@@ -3,8 +3,10 @@ package paymentsv2
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PaymentRepository defines the interface for managing PaymentTransactions.
@@ -14,6 +16,16 @@ type PaymentRepository interface {
 	GetByTaskID(ctx context.Context, taskID string) (*PaymentTransaction, error)
 	Update(ctx context.Context, tx *PaymentTransaction) error
 	UpdateStatus(ctx context.Context, referenceNumber string, status PaymentStatus) error
+	// TransitionStatus saves the gateway outcome recorded on tx only if the stored status is still
+	// from, and reports whether it did. It lets concurrent webhook deliveries race safely.
+	TransitionStatus(ctx context.Context, tx *PaymentTransaction, from PaymentStatus) (bool, error)
+	// MarkTaskSynced records that the task has been advanced with the given outcome.
+	MarkTaskSynced(ctx context.Context, id string, status PaymentStatus) error
+	// AddLedgerEntry appends entry unless one with the same transaction, type and external ID
+	// exists, and reports whether it was added.
+	AddLedgerEntry(ctx context.Context, entry *LedgerEntry) (bool, error)
+	// ListLedgerEntries returns the ledger entries of a task, oldest first.
+	ListLedgerEntries(ctx context.Context, taskID string) ([]LedgerEntry, error)
 	WithTx(tx *gorm.DB) PaymentRepository
 }
 
@@ -69,3 +81,43 @@ func (r *paymentRepository) Update(ctx context.Context, ptx *PaymentTransaction)
 func (r *paymentRepository) UpdateStatus(ctx context.Context, referenceNumber string, status PaymentStatus) error {
 	return r.db.WithContext(ctx).Model(&PaymentTransaction{}).Where("reference_number = ?", referenceNumber).Updates(map[string]interface{}{"status": status}).Error
 }
+
+// TransitionStatus updates the gateway outcome fields of a PaymentTransaction if its status is still from.
+func (r *paymentRepository) TransitionStatus(ctx context.Context, ptx *PaymentTransaction, from PaymentStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(ptx).
+		Where("status = ?", from).
+		Select("status", "payment_method", "gateway_metadata", "updated_at").
+		Updates(ptx)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkTaskSynced stores the outcome last reported to the transaction's task.
+func (r *paymentRepository) MarkTaskSynced(ctx context.Context, id string, status PaymentStatus) error {
+	return r.db.WithContext(ctx).Model(&PaymentTransaction{}).Where("id = ?", id).Updates(map[string]interface{}{"task_synced_status": status, "updated_at": time.Now()}).Error
+}
+
+// AddLedgerEntry inserts a LedgerEntry, ignoring one that has already been recorded.
+func (r *paymentRepository) AddLedgerEntry(ctx context.Context, entry *LedgerEntry) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "transaction_id"}, {Name: "entry_type"}, {Name: "external_id"}},
+			DoNothing: true,
+		}).
+		Create(entry)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ListLedgerEntries retrieves the LedgerEntries of a task in the order they were recorded.
+func (r *paymentRepository) ListLedgerEntries(ctx context.Context, taskID string) ([]LedgerEntry, error) {
+	var entries []LedgerEntry
+	if err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
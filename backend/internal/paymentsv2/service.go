@@ -2,10 +2,44 @@ package paymentsv2
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidWebhook is returned when a provider rejects a webhook, e.g. because its signature does not verify.
+	ErrInvalidWebhook = errors.New("invalid payment webhook")
+	// ErrTransactionNotFound is returned when a webhook references an unknown payment.
+	ErrTransactionNotFound = errors.New("payment transaction not found")
+	// ErrRefundNotAllowed is returned when a refund is requested for more than a payment holds,
+	// or while another refund for it is pending.
+	ErrRefundNotAllowed = errors.New("refund not allowed")
 )
 
+// OutcomeHandler reports a payment outcome to the task it belongs to, e.g. by executing
+// PAYMENT_SUCCESS on the PAYMENT task. It must be idempotent: when it fails the webhook is
+// rejected, and the handler runs again when the gateway redelivers it.
+type OutcomeHandler func(ctx context.Context, tx PaymentTransaction) error
+
+// PartyNames are the display names of the parties to a payment.
+type PartyNames struct {
+	TraderName string
+	OGAName    string
+}
+
+// NameResolver resolves who is paying and which agency is being paid for a transaction.
+type NameResolver interface {
+	ResolvePartyNames(ctx context.Context, tx *PaymentTransaction) (*PartyNames, error)
+}
+
 // PaymentService defines the high-level orchestration for payments.
 type PaymentService interface {
 	// ListAvailableMethods returns the rendering information for all active payment gateways.
@@ -19,11 +53,28 @@ type PaymentService interface {
 
 	// ProcessWebhook handles asynchronous notifications from payment gateways.
 	ProcessWebhook(ctx context.Context, providerID string, body []byte, headers map[string][]string) error
+
+	// RequestRefund asks the payment's gateway to return amount, or everything the payment still
+	// holds when amount is zero. The transaction stays REFUND_REQUESTED until the gateway confirms.
+	RequestRefund(ctx context.Context, referenceNumber string, amount decimal.Decimal, reason string) (*PaymentTransaction, error)
+
+	// GetTaskLedger returns the payments and refunds recorded for a task and the net amount held.
+	GetTaskLedger(ctx context.Context, taskID string) (*TaskLedger, error)
+
+	// RegisterOutcomeHandler registers the handler that advances tasks once their payment settles.
+	RegisterOutcomeHandler(handler OutcomeHandler)
+
+	// RegisterNameResolver registers the resolver used to name the parties in validation responses.
+	RegisterNameResolver(resolver NameResolver)
 }
 
 type paymentService struct {
 	repo     PaymentRepository
 	registry PaymentRegistry
+
+	handlerMu      sync.RWMutex   // Protects outcomeHandler and names
+	outcomeHandler OutcomeHandler // Advances the task of a settled payment
+	names          NameResolver   // Names the trader and OGA of a payment
 }
 
 // NewPaymentService initializes a new payment service.
@@ -34,18 +85,82 @@ func NewPaymentService(repo PaymentRepository, registry PaymentRegistry) Payment
 	}
 }
 
+func (s *paymentService) RegisterOutcomeHandler(handler OutcomeHandler) {
+	s.handlerMu.Lock()
+	defer s.handlerMu.Unlock()
+	s.outcomeHandler = handler
+}
+
+func (s *paymentService) RegisterNameResolver(resolver NameResolver) {
+	s.handlerMu.Lock()
+	defer s.handlerMu.Unlock()
+	s.names = resolver
+}
+
 func (s *paymentService) ListAvailableMethods(ctx context.Context) ([]PaymentProviderInfo, error) {
 	return s.registry.ListInfo(), nil
 }
 
 func (s *paymentService) CreateCheckoutSession(ctx context.Context, req CreateCheckoutRequest) (*CreateCheckoutResponse, error) {
-	// TODO: Implement multi-provider orchestration logic:
-	// 1. Select provider from registry
-	// 2. Generate a unique NSW ReferenceNumber (e.g., NSW-PR-YYYY-XXXXX)
-	// 3. Call provider.CreateSession(ctx, req, generatedRef)
-	// 4. Persist transaction via repo including the generatedRef
-	// 5. Return CreateCheckoutResponse containing the generatedRef
-	return nil, fmt.Errorf("multi-provider checkout orchestration not yet implemented")
+	taskID, ok := req.Metadata["task_id"]
+	if !ok || taskID == "" {
+		return nil, fmt.Errorf("task_id is required in metadata")
+	}
+
+	// 1. Select the provider, falling back to the first active method in display order
+	providerID := req.ProviderID
+	if providerID == "" {
+		methods := s.registry.ListInfo()
+		if len(methods) == 0 {
+			return nil, fmt.Errorf("%w: no active payment methods", ErrProviderNotFound)
+		}
+		providerID = methods[0].ID
+	}
+	provider, err := s.registry.Get(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Generate the NSW reference the trader quotes to the gateway or bank
+	referenceNumber, err := generateReferenceNumber(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate payment reference: %w", err)
+	}
+	req.ProviderID = providerID
+	req.ReferenceNumber = referenceNumber
+
+	// 3. Initialize the session with the gateway
+	resp, err := provider.CreateSession(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("provider %s failed to create checkout session: %w", providerID, err)
+	}
+
+	// 4. Persist the transaction so that validation and webhooks can find it
+	tx := &PaymentTransaction{
+		ID:              uuid.NewString(),
+		ReferenceNumber: referenceNumber,
+		TaskID:          taskID,
+		ProviderID:      providerID,
+		SessionID:       resp.SessionID,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		Status:          PaymentStatusPending,
+		ExpiryDate:      req.ExpiresAt,
+		GatewayMetadata: req.Metadata,
+	}
+	if err := s.repo.Create(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to create payment transaction: %w", err)
+	}
+
+	slog.Info("created checkout session", "provider", providerID, "reference_number", referenceNumber, "session_id", resp.SessionID)
+
+	// 5. Return the gateway session together with the NSW reference
+	resp.ProviderID = providerID
+	resp.ReferenceNumber = referenceNumber
+	if resp.ExpiresIn == 0 && !req.ExpiresAt.IsZero() {
+		resp.ExpiresIn = int(time.Until(req.ExpiresAt).Seconds())
+	}
+	return resp, nil
 }
 
 func (s *paymentService) ValidateReference(ctx context.Context, providerID string, req ValidateReferenceRequest) (*ValidateReferenceResponse, error) {
@@ -54,7 +169,7 @@ func (s *paymentService) ValidateReference(ctx context.Context, providerID strin
 	// 1. Get the provider from the registry using the ID from the URL
 	provider, err := s.registry.Get(providerID)
 	if err != nil {
-		return nil, fmt.Errorf("provider %s not found: %w", providerID, err)
+		return nil, err
 	}
 
 	// 2. Look up the transaction metadata from the DB
@@ -73,10 +188,368 @@ func (s *paymentService) ValidateReference(ctx context.Context, providerID strin
 	}
 
 	// 4. Delegate final validation response to the provider, injecting the transaction metadata
-	return provider.HandleValidateReference(ctx, tx)
+	resp, err := provider.HandleValidateReference(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Name the parties for the trader's bank app unless the provider already did
+	s.handlerMu.RLock()
+	names := s.names
+	s.handlerMu.RUnlock()
+	if names != nil && (resp.TraderName == "" || resp.OGAName == "") {
+		parties, err := names.ResolvePartyNames(ctx, tx)
+		if err != nil {
+			slog.Warn("failed to resolve payment party names", "reference", tx.ReferenceNumber, "error", err)
+		} else {
+			if resp.TraderName == "" {
+				resp.TraderName = parties.TraderName
+			}
+			if resp.OGAName == "" {
+				resp.OGAName = parties.OGAName
+			}
+		}
+	}
+	return resp, nil
 }
 
 func (s *paymentService) ProcessWebhook(ctx context.Context, providerID string, body []byte, headers map[string][]string) error {
-	// TODO: Implement provider-based webhook processing
-	return fmt.Errorf("webhook processing not yet implemented for provider: %s", providerID)
+	provider, err := s.registry.Get(providerID)
+	if err != nil {
+		return err
+	}
+
+	// Signature verification and payload decoding are gateway-specific
+	payload, err := provider.ParseWebhook(ctx, body, headers)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhook, err)
+	}
+
+	slog.Info("processing payment webhook", "provider", providerID, "reference_number", payload.ReferenceNumber, "status", payload.Status)
+
+	tx, err := s.repo.GetByReferenceNumber(ctx, payload.ReferenceNumber)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve payment by reference: %w", err)
+	}
+	if tx == nil {
+		return fmt.Errorf("%w: %s", ErrTransactionNotFound, payload.ReferenceNumber)
+	}
+	if tx.ProviderID != providerID {
+		slog.Warn("provider mismatch during webhook", "expected", tx.ProviderID, "received", providerID, "reference", tx.ReferenceNumber)
+		return fmt.Errorf("%w: reference %s does not belong to provider %s", ErrInvalidWebhook, tx.ReferenceNumber, providerID)
+	}
+
+	switch payload.Status {
+	case PaymentStatusSuccess, PaymentStatusPartiallyPaid:
+		return s.recordPayment(ctx, tx, payload)
+	case PaymentStatusRefunded:
+		return s.recordRefund(ctx, tx, payload)
+	case PaymentStatusFailed:
+		return s.recordFailure(ctx, tx, payload)
+	default:
+		// Other statuses (PENDING, REFUND_REQUESTED, or anything the gateway adds later) are not
+		// outcomes this service acts on; acknowledge them so the gateway stops redelivering.
+		slog.Warn("ignoring payment webhook with unhandled status", "provider", providerID,
+			"reference", tx.ReferenceNumber, "status", payload.Status, "current_status", tx.Status)
+		return nil
+	}
+}
+
+// recordFailure marks the transaction FAILED as reported by the gateway.
+func (s *paymentService) recordFailure(ctx context.Context, tx *PaymentTransaction, payload *WebhookPayload) error {
+	// Idempotency: a redelivered webhook, or a failure arriving after money was received, does not
+	// change the recorded outcome but still gets the task in step with it below.
+	if tx.Status == payload.Status || tx.Status.IsPaid() {
+		slog.Info("webhook status already recorded", "reference", tx.ReferenceNumber, "current_status", tx.Status)
+		return s.syncTask(ctx, tx)
+	}
+	return s.applyOutcome(ctx, tx, payload, PaymentStatusFailed, "gateway_transaction_id")
+}
+
+// recordPayment adds a payment reported by the gateway to the ledger and moves the transaction to
+// PARTIALLY_PAID or SUCCESS depending on how much has been paid in total. A gateway reporting
+// SUCCESS must have collected the full amount; instalments are reported as PARTIALLY_PAID.
+func (s *paymentService) recordPayment(ctx context.Context, tx *PaymentTransaction, payload *WebhookPayload) error {
+	if payload.Currency != tx.Currency || !payload.Amount.IsPositive() {
+		return fmt.Errorf("%w: paid %s %s does not match expected %s %s", ErrInvalidWebhook,
+			payload.Amount, payload.Currency, tx.Amount, tx.Currency)
+	}
+
+	entry := newLedgerEntry(tx, LedgerEntryPayment, payload)
+	entries, err := s.transactionEntries(ctx, tx)
+	if err != nil {
+		return err
+	}
+	paid, _ := ledgerTotals(entries)
+	if !containsEntry(entries, entry) {
+		paid = paid.Add(entry.Amount)
+	}
+	if payload.Status == PaymentStatusSuccess && paid.LessThan(tx.Amount) {
+		return fmt.Errorf("%w: paid %s %s does not match expected %s %s", ErrInvalidWebhook,
+			paid, payload.Currency, tx.Amount, tx.Currency)
+	}
+
+	if _, err := s.repo.AddLedgerEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record payment in ledger: %w", err)
+	}
+
+	status := PaymentStatusPartiallyPaid
+	if paid.GreaterThanOrEqual(tx.Amount) {
+		status = PaymentStatusSuccess
+	}
+	if paid.GreaterThan(tx.Amount) {
+		slog.Warn("payment overpaid, excess is due for refund", "reference", tx.ReferenceNumber,
+			"amount", tx.Amount, "paid", paid, "currency", tx.Currency)
+	}
+	// A payment arriving once a refund is under way is kept in the ledger only.
+	if tx.Status == PaymentStatusRefundRequested || tx.Status == PaymentStatusRefunded {
+		status = tx.Status
+	}
+	return s.applyOutcome(ctx, tx, payload, status, "gateway_transaction_id")
+}
+
+// recordRefund adds a refund confirmed by the gateway to the ledger and marks the transaction REFUNDED.
+func (s *paymentService) recordRefund(ctx context.Context, tx *PaymentTransaction, payload *WebhookPayload) error {
+	if payload.Currency != tx.Currency || !payload.Amount.IsPositive() {
+		return fmt.Errorf("%w: refunded %s %s does not match payment currency %s", ErrInvalidWebhook,
+			payload.Amount, payload.Currency, tx.Currency)
+	}
+	if !tx.Status.IsPaid() {
+		return fmt.Errorf("%w: refund for unpaid payment %s", ErrInvalidWebhook, tx.ReferenceNumber)
+	}
+
+	entry := newLedgerEntry(tx, LedgerEntryRefund, payload)
+	entries, err := s.transactionEntries(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if !containsEntry(entries, entry) {
+		paid, refunded := ledgerTotals(entries)
+		if refunded.Add(entry.Amount).GreaterThan(paid) {
+			return fmt.Errorf("%w: refunds for %s would exceed the %s %s paid", ErrInvalidWebhook,
+				tx.ReferenceNumber, paid, tx.Currency)
+		}
+		if _, err := s.repo.AddLedgerEntry(ctx, entry); err != nil {
+			return fmt.Errorf("failed to record refund in ledger: %w", err)
+		}
+	}
+	return s.applyOutcome(ctx, tx, payload, PaymentStatusRefunded, "gateway_refund_id")
+}
+
+// applyOutcome moves tx to status, keeping the gateway's transaction ID under idKey, and reports
+// the outcome to the task. Concurrent deliveries race on the status update and the loser carries
+// on from what the winner stored.
+func (s *paymentService) applyOutcome(ctx context.Context, tx *PaymentTransaction, payload *WebhookPayload, status PaymentStatus, idKey string) error {
+	if status != tx.Status {
+		from := tx.Status
+		tx.Status = status
+		if payload.PaymentMethod != "" {
+			tx.PaymentMethod = payload.PaymentMethod
+		}
+		if tx.GatewayMetadata == nil {
+			tx.GatewayMetadata = make(map[string]string)
+		}
+		tx.GatewayMetadata[idKey] = payload.GatewayTransactionID
+		tx.GatewayMetadata["webhook_timestamp"] = payload.Timestamp
+
+		updated, err := s.repo.TransitionStatus(ctx, tx, from)
+		if err != nil {
+			return fmt.Errorf("failed to update payment transaction status: %w", err)
+		}
+		if !updated {
+			tx, err = s.repo.GetByReferenceNumber(ctx, tx.ReferenceNumber)
+			if err != nil {
+				return fmt.Errorf("failed to reload payment by reference: %w", err)
+			}
+			if tx == nil {
+				return fmt.Errorf("%w: %s", ErrTransactionNotFound, payload.ReferenceNumber)
+			}
+		} else {
+			slog.Info("payment transaction updated successfully", "reference", tx.ReferenceNumber, "status", tx.Status)
+		}
+	}
+
+	return s.syncTask(ctx, tx)
+}
+
+func (s *paymentService) RequestRefund(ctx context.Context, referenceNumber string, amount decimal.Decimal, reason string) (*PaymentTransaction, error) {
+	tx, err := s.repo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve payment by reference: %w", err)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, referenceNumber)
+	}
+	if tx.Status == PaymentStatusRefundRequested {
+		return nil, fmt.Errorf("%w: a refund for %s is already pending", ErrRefundNotAllowed, referenceNumber)
+	}
+	if !tx.Status.IsPaid() {
+		return nil, fmt.Errorf("%w: payment %s is %s", ErrRefundNotAllowed, referenceNumber, tx.Status)
+	}
+
+	// 1. Only what the payment still holds can be returned
+	entries, err := s.transactionEntries(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	paid, refunded := ledgerTotals(entries)
+	held := paid.Sub(refunded)
+	if amount.IsZero() {
+		amount = held
+	}
+	if !amount.IsPositive() || amount.GreaterThan(held) {
+		return nil, fmt.Errorf("%w: cannot refund %s %s, %s %s held", ErrRefundNotAllowed, amount, tx.Currency, held, tx.Currency)
+	}
+
+	provider, err := s.registry.Get(tx.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Claim the transaction so that concurrent refund requests cannot both be sent
+	from := tx.Status
+	refundID := uuid.NewString()
+	tx.Status = PaymentStatusRefundRequested
+	if tx.GatewayMetadata == nil {
+		tx.GatewayMetadata = make(map[string]string)
+	}
+	tx.GatewayMetadata["refund_id"] = refundID
+	updated, err := s.repo.TransitionStatus(ctx, tx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update payment transaction status: %w", err)
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: payment %s changed while requesting a refund", ErrRefundNotAllowed, referenceNumber)
+	}
+
+	// 3. Send the refund; put the transaction back if the gateway refuses so it can be retried
+	resp, err := provider.Refund(ctx, RefundRequest{
+		RefundID:             refundID,
+		ReferenceNumber:      tx.ReferenceNumber,
+		SessionID:            tx.SessionID,
+		GatewayTransactionID: tx.GatewayMetadata["gateway_transaction_id"],
+		Amount:               amount,
+		Currency:             tx.Currency,
+		Reason:               reason,
+	})
+	if err != nil {
+		tx.Status = from
+		if _, revertErr := s.repo.TransitionStatus(ctx, tx, PaymentStatusRefundRequested); revertErr != nil {
+			slog.Error("failed to revert payment after refund error", "reference", referenceNumber, "error", revertErr)
+		}
+		return nil, fmt.Errorf("provider %s failed to refund %s: %w", tx.ProviderID, referenceNumber, err)
+	}
+
+	// 4. The task asked for the refund, so it is already in step with REFUND_REQUESTED
+	if err := s.repo.MarkTaskSynced(ctx, tx.ID, PaymentStatusRefundRequested); err != nil {
+		return nil, fmt.Errorf("failed to mark payment %s as reported: %w", referenceNumber, err)
+	}
+	tx.TaskSyncedStatus = PaymentStatusRefundRequested
+
+	slog.Info("refund requested", "reference", referenceNumber, "amount", amount, "currency", tx.Currency,
+		"refund_id", refundID, "gateway_refund_id", resp.GatewayRefundID)
+	return tx, nil
+}
+
+func (s *paymentService) GetTaskLedger(ctx context.Context, taskID string) (*TaskLedger, error) {
+	entries, err := s.repo.ListLedgerEntries(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries for task %s: %w", taskID, err)
+	}
+	paid, refunded := ledgerTotals(entries)
+	ledger := &TaskLedger{
+		TaskID:   taskID,
+		Paid:     paid,
+		Refunded: refunded,
+		Net:      paid.Sub(refunded),
+		Entries:  entries,
+	}
+	if len(entries) > 0 {
+		ledger.Currency = entries[0].Currency
+	}
+	return ledger, nil
+}
+
+// transactionEntries returns the ledger entries recorded against tx.
+func (s *paymentService) transactionEntries(ctx context.Context, tx *PaymentTransaction) ([]LedgerEntry, error) {
+	entries, err := s.repo.ListLedgerEntries(ctx, tx.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries for %s: %w", tx.ReferenceNumber, err)
+	}
+	var own []LedgerEntry
+	for _, e := range entries {
+		if e.TransactionID == tx.ID {
+			own = append(own, e)
+		}
+	}
+	return own, nil
+}
+
+// newLedgerEntry builds the ledger entry for money moved by a webhook. Webhooks without a gateway
+// transaction ID are keyed by the payment reference, so a gateway that omits it can only report
+// one payment and one refund per reference.
+func newLedgerEntry(tx *PaymentTransaction, entryType LedgerEntryType, payload *WebhookPayload) *LedgerEntry {
+	externalID := payload.GatewayTransactionID
+	if externalID == "" {
+		externalID = tx.ReferenceNumber
+	}
+	return &LedgerEntry{
+		ID:              uuid.NewString(),
+		TransactionID:   tx.ID,
+		TaskID:          tx.TaskID,
+		ReferenceNumber: tx.ReferenceNumber,
+		EntryType:       entryType,
+		Amount:          payload.Amount,
+		Currency:        payload.Currency,
+		ExternalID:      externalID,
+	}
+}
+
+// containsEntry reports whether entry has already been recorded in entries.
+func containsEntry(entries []LedgerEntry, entry *LedgerEntry) bool {
+	for _, e := range entries {
+		if e.EntryType == entry.EntryType && e.ExternalID == entry.ExternalID {
+			return true
+		}
+	}
+	return false
+}
+
+// syncTask reports a payment outcome to its task unless that outcome has already been reported.
+// The outcome is only marked as reported once the handler succeeds, so a failure is retried on
+// the gateway's next delivery.
+func (s *paymentService) syncTask(ctx context.Context, tx *PaymentTransaction) error {
+	if !tx.Status.reportsToTask() || tx.TaskSyncedStatus == tx.Status {
+		return nil
+	}
+
+	s.handlerMu.RLock()
+	handler := s.outcomeHandler
+	s.handlerMu.RUnlock()
+	if handler == nil {
+		slog.Warn("no payment outcome handler registered, task not advanced", "reference", tx.ReferenceNumber, "task_id", tx.TaskID)
+		return nil
+	}
+
+	if err := handler(ctx, *tx); err != nil {
+		return fmt.Errorf("failed to report payment %s to task %s: %w", tx.ReferenceNumber, tx.TaskID, err)
+	}
+	if err := s.repo.MarkTaskSynced(ctx, tx.ID, tx.Status); err != nil {
+		return fmt.Errorf("failed to mark payment %s as reported: %w", tx.ReferenceNumber, err)
+	}
+
+	slog.Info("payment outcome reported to task", "reference", tx.ReferenceNumber, "task_id", tx.TaskID, "status", tx.Status)
+	return nil
+}
+
+// referenceEncoding renders reference suffixes without padding or easily confused lower-case letters.
+var referenceEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateReferenceNumber returns a reference of the form NSW-PR-YYYY-XXXXXXXX.
+func generateReferenceNumber(now time.Time) (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("NSW-PR-%d-%s", now.Year(), referenceEncoding.EncodeToString(b)), nil
 }
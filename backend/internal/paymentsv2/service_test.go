@@ -0,0 +1,682 @@
+package paymentsv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type mockRepository struct {
+	txs       map[string]*PaymentTransaction
+	ledger    []LedgerEntry
+	createErr error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{txs: make(map[string]*PaymentTransaction)}
+}
+
+func (m *mockRepository) Create(ctx context.Context, tx *PaymentTransaction) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.txs[tx.ReferenceNumber] = tx
+	return nil
+}
+
+func (m *mockRepository) GetByReferenceNumber(ctx context.Context, ref string) (*PaymentTransaction, error) {
+	if tx, ok := m.txs[ref]; ok {
+		copied := *tx
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) GetByTaskID(ctx context.Context, taskID string) (*PaymentTransaction, error) {
+	for _, tx := range m.txs {
+		if tx.TaskID == taskID {
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) Update(ctx context.Context, tx *PaymentTransaction) error {
+	m.txs[tx.ReferenceNumber] = tx
+	return nil
+}
+
+func (m *mockRepository) UpdateStatus(ctx context.Context, ref string, status PaymentStatus) error {
+	if tx, ok := m.txs[ref]; ok {
+		tx.Status = status
+	}
+	return nil
+}
+
+func (m *mockRepository) TransitionStatus(ctx context.Context, tx *PaymentTransaction, from PaymentStatus) (bool, error) {
+	stored, ok := m.txs[tx.ReferenceNumber]
+	if !ok || stored.Status != from {
+		return false, nil
+	}
+	copied := *tx
+	m.txs[tx.ReferenceNumber] = &copied
+	return true, nil
+}
+
+func (m *mockRepository) MarkTaskSynced(ctx context.Context, id string, status PaymentStatus) error {
+	for _, tx := range m.txs {
+		if tx.ID == id {
+			tx.TaskSyncedStatus = status
+		}
+	}
+	return nil
+}
+
+func (m *mockRepository) AddLedgerEntry(ctx context.Context, entry *LedgerEntry) (bool, error) {
+	for _, e := range m.ledger {
+		if e.TransactionID == entry.TransactionID && e.EntryType == entry.EntryType && e.ExternalID == entry.ExternalID {
+			return false, nil
+		}
+	}
+	m.ledger = append(m.ledger, *entry)
+	return true, nil
+}
+
+func (m *mockRepository) ListLedgerEntries(ctx context.Context, taskID string) ([]LedgerEntry, error) {
+	var entries []LedgerEntry
+	for _, e := range m.ledger {
+		if e.TaskID == taskID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockRepository) WithTx(tx *gorm.DB) PaymentRepository {
+	return m
+}
+
+const testWebhookSecret = "test-secret"
+
+func newTestService(t *testing.T) (PaymentService, *mockRepository) {
+	t.Helper()
+	registry, err := NewRegistryFromConfig(PaymentMethodsConfig{Methods: []PaymentMethodConfig{
+		{ID: "other", IsActive: true, RenderInfo: PaymentRenderInfo{DisplayName: "Other", DisplayOrder: 2}},
+		{ID: MockProviderID, IsActive: true, RenderInfo: PaymentRenderInfo{DisplayName: "Mock", DisplayOrder: 1}},
+	}}, map[string]PaymentProvider{
+		MockProviderID: NewMockProvider(MockProviderConfig{CheckoutURL: "https://pay.example.com/checkout", WebhookSecret: testWebhookSecret}),
+		"other":        NewMockProvider(MockProviderConfig{CheckoutURL: "https://other.example.com", WebhookSecret: testWebhookSecret}),
+	})
+	if err != nil {
+		t.Fatalf("NewRegistryFromConfig() error = %v", err)
+	}
+	repo := newMockRepository()
+	return NewPaymentService(repo, registry), repo
+}
+
+func checkoutRequest(providerID string) CreateCheckoutRequest {
+	return CreateCheckoutRequest{
+		ProviderID: providerID,
+		Amount:     decimal.NewFromInt(1500),
+		Currency:   "LKR",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		Metadata:   map[string]string{"task_id": "task-1"},
+	}
+}
+
+func signedWebhook(t *testing.T, payload WebhookPayload) ([]byte, map[string][]string) {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal webhook: %v", err)
+	}
+	headers := http.Header{}
+	headers.Set(MockSignatureHeader, SignMockWebhook(testWebhookSecret, body))
+	return body, headers
+}
+
+func TestCreateCheckoutSession(t *testing.T) {
+	t.Run("uses the default provider and persists the transaction", func(t *testing.T) {
+		service, repo := newTestService(t)
+
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest(""))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+		if resp.ProviderID != MockProviderID {
+			t.Errorf("ProviderID = %q, want %q", resp.ProviderID, MockProviderID)
+		}
+		if !strings.HasPrefix(resp.ReferenceNumber, "NSW-PR-") {
+			t.Errorf("ReferenceNumber = %q, want NSW-PR- prefix", resp.ReferenceNumber)
+		}
+		if !strings.HasPrefix(resp.CheckoutURL, "https://pay.example.com/checkout/") {
+			t.Errorf("CheckoutURL = %q", resp.CheckoutURL)
+		}
+
+		tx := repo.txs[resp.ReferenceNumber]
+		if tx == nil {
+			t.Fatal("transaction was not persisted")
+		}
+		if tx.ProviderID != MockProviderID || tx.TaskID != "task-1" || tx.SessionID != resp.SessionID || tx.Status != PaymentStatusPending {
+			t.Errorf("unexpected transaction: %+v", tx)
+		}
+	})
+
+	t.Run("selects the requested provider", func(t *testing.T) {
+		service, _ := newTestService(t)
+
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest("other"))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+		if resp.ProviderID != "other" || !strings.HasPrefix(resp.CheckoutURL, "https://other.example.com/") {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		service, _ := newTestService(t)
+
+		_, err := service.CreateCheckoutSession(context.Background(), checkoutRequest("govpay"))
+		if !errors.Is(err, ErrProviderNotFound) {
+			t.Errorf("error = %v, want ErrProviderNotFound", err)
+		}
+	})
+
+	t.Run("task_id is required", func(t *testing.T) {
+		service, _ := newTestService(t)
+		req := checkoutRequest("")
+		req.Metadata = nil
+
+		if _, err := service.CreateCheckoutSession(context.Background(), req); err == nil {
+			t.Error("expected error for missing task_id")
+		}
+	})
+}
+
+func TestProcessWebhook(t *testing.T) {
+	newPending := func(t *testing.T) (PaymentService, *mockRepository, string) {
+		service, repo := newTestService(t)
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest(""))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+		return service, repo, resp.ReferenceNumber
+	}
+
+	t.Run("records a successful payment", func(t *testing.T) {
+		service, repo, ref := newPending(t)
+		body, headers := signedWebhook(t, WebhookPayload{
+			ReferenceNumber: ref, Status: PaymentStatusSuccess, Amount: decimal.NewFromInt(1500), Currency: "LKR",
+			GatewayTransactionID: "gw-1", PaymentMethod: "CC",
+		})
+
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+			t.Fatalf("ProcessWebhook() error = %v", err)
+		}
+		tx := repo.txs[ref]
+		if tx.Status != PaymentStatusSuccess || tx.GatewayMetadata["gateway_transaction_id"] != "gw-1" {
+			t.Errorf("unexpected transaction: %+v", tx)
+		}
+
+		// Redelivery is a no-op.
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+			t.Errorf("redelivered ProcessWebhook() error = %v", err)
+		}
+	})
+
+	t.Run("rejects a bad signature", func(t *testing.T) {
+		service, repo, ref := newPending(t)
+		body, _ := signedWebhook(t, WebhookPayload{ReferenceNumber: ref, Status: PaymentStatusSuccess})
+		headers := map[string][]string{MockSignatureHeader: {"deadbeef"}}
+
+		err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers)
+		if !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("error = %v, want ErrInvalidWebhook", err)
+		}
+		if repo.txs[ref].Status != PaymentStatusPending {
+			t.Error("transaction must not change on a rejected webhook")
+		}
+	})
+
+	t.Run("rejects a webhook routed to another provider", func(t *testing.T) {
+		service, _, ref := newPending(t)
+		body, headers := signedWebhook(t, WebhookPayload{ReferenceNumber: ref, Status: PaymentStatusSuccess, Amount: decimal.NewFromInt(1500), Currency: "LKR"})
+
+		err := service.ProcessWebhook(context.Background(), "other", body, headers)
+		if !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("error = %v, want ErrInvalidWebhook", err)
+		}
+	})
+
+	t.Run("rejects an amount mismatch", func(t *testing.T) {
+		service, _, ref := newPending(t)
+		body, headers := signedWebhook(t, WebhookPayload{ReferenceNumber: ref, Status: PaymentStatusSuccess, Amount: decimal.NewFromInt(1), Currency: "LKR"})
+
+		err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers)
+		if !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("error = %v, want ErrInvalidWebhook", err)
+		}
+	})
+
+	t.Run("acknowledges unhandled statuses without changing state", func(t *testing.T) {
+		for _, status := range []PaymentStatus{PaymentStatusPending, PaymentStatusRefundRequested, "CHARGEBACK"} {
+			service, repo, ref := newPending(t)
+			outcomes := 0
+			service.RegisterOutcomeHandler(func(context.Context, PaymentTransaction) error {
+				outcomes++
+				return nil
+			})
+			body, headers := signedWebhook(t, WebhookPayload{
+				ReferenceNumber: ref, Status: status, Amount: decimal.NewFromInt(1500), Currency: "LKR",
+				GatewayTransactionID: "gw-1",
+			})
+
+			if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+				t.Errorf("%s: ProcessWebhook() error = %v", status, err)
+			}
+			if repo.txs[ref].Status != PaymentStatusPending {
+				t.Errorf("%s: status = %s, want PENDING", status, repo.txs[ref].Status)
+			}
+			if len(repo.ledger) != 0 || outcomes != 0 {
+				t.Errorf("%s: ledger entries = %d, outcomes = %d, want none", status, len(repo.ledger), outcomes)
+			}
+		}
+	})
+
+	t.Run("unknown reference", func(t *testing.T) {
+		service, _ := newTestService(t)
+		body, headers := signedWebhook(t, WebhookPayload{ReferenceNumber: "NSW-PR-2026-NOPE", Status: PaymentStatusSuccess})
+
+		err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers)
+		if !errors.Is(err, ErrTransactionNotFound) {
+			t.Errorf("error = %v, want ErrTransactionNotFound", err)
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		service, _ := newTestService(t)
+
+		err := service.ProcessWebhook(context.Background(), "govpay", []byte(`{}`), nil)
+		if !errors.Is(err, ErrProviderNotFound) {
+			t.Errorf("error = %v, want ErrProviderNotFound", err)
+		}
+	})
+}
+
+func TestProcessWebhook_AdvancesTask(t *testing.T) {
+	type call struct {
+		taskID string
+		status PaymentStatus
+	}
+	setup := func(t *testing.T) (PaymentService, *mockRepository, string, *[]call, *error) {
+		service, repo := newTestService(t)
+		var calls []call
+		var handlerErr error
+		service.RegisterOutcomeHandler(func(ctx context.Context, tx PaymentTransaction) error {
+			calls = append(calls, call{taskID: tx.TaskID, status: tx.Status})
+			return handlerErr
+		})
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest(""))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+		return service, repo, resp.ReferenceNumber, &calls, &handlerErr
+	}
+	success := func(ref string) WebhookPayload {
+		return WebhookPayload{ReferenceNumber: ref, Status: PaymentStatusSuccess, Amount: decimal.NewFromInt(1500), Currency: "LKR"}
+	}
+
+	t.Run("reports the outcome exactly once", func(t *testing.T) {
+		service, repo, ref, calls, _ := setup(t)
+		body, headers := signedWebhook(t, success(ref))
+
+		for i := 0; i < 3; i++ {
+			if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+				t.Fatalf("delivery %d: ProcessWebhook() error = %v", i, err)
+			}
+		}
+		if len(*calls) != 1 || (*calls)[0] != (call{taskID: "task-1", status: PaymentStatusSuccess}) {
+			t.Errorf("handler calls = %+v, want one SUCCESS for task-1", *calls)
+		}
+		if repo.txs[ref].TaskSyncedStatus != PaymentStatusSuccess {
+			t.Errorf("TaskSyncedStatus = %q, want SUCCESS", repo.txs[ref].TaskSyncedStatus)
+		}
+	})
+
+	t.Run("retries a failed report on redelivery", func(t *testing.T) {
+		service, repo, ref, calls, handlerErr := setup(t)
+		body, headers := signedWebhook(t, success(ref))
+
+		*handlerErr = errors.New("task busy")
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err == nil {
+			t.Fatal("expected the handler error to reject the webhook")
+		}
+		if repo.txs[ref].Status != PaymentStatusSuccess || repo.txs[ref].TaskSyncedStatus != "" {
+			t.Fatalf("payment must be recorded but not marked as reported: %+v", repo.txs[ref])
+		}
+
+		*handlerErr = nil
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+			t.Fatalf("redelivered ProcessWebhook() error = %v", err)
+		}
+		if len(*calls) != 2 || repo.txs[ref].TaskSyncedStatus != PaymentStatusSuccess {
+			t.Errorf("calls = %d, TaskSyncedStatus = %q", len(*calls), repo.txs[ref].TaskSyncedStatus)
+		}
+	})
+
+	t.Run("reports failure and a later success", func(t *testing.T) {
+		service, _, ref, calls, _ := setup(t)
+		failed, failedHeaders := signedWebhook(t, WebhookPayload{ReferenceNumber: ref, Status: PaymentStatusFailed})
+		succeeded, succeededHeaders := signedWebhook(t, success(ref))
+
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, failed, failedHeaders); err != nil {
+			t.Fatalf("ProcessWebhook(FAILED) error = %v", err)
+		}
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, succeeded, succeededHeaders); err != nil {
+			t.Fatalf("ProcessWebhook(SUCCESS) error = %v", err)
+		}
+		want := []call{{"task-1", PaymentStatusFailed}, {"task-1", PaymentStatusSuccess}}
+		if len(*calls) != 2 || (*calls)[0] != want[0] || (*calls)[1] != want[1] {
+			t.Errorf("handler calls = %+v, want %+v", *calls, want)
+		}
+	})
+
+	t.Run("does not report a rejected amount", func(t *testing.T) {
+		service, _, ref, calls, _ := setup(t)
+		payload := success(ref)
+		payload.Amount = decimal.NewFromInt(1499)
+		body, headers := signedWebhook(t, payload)
+
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("error = %v, want ErrInvalidWebhook", err)
+		}
+		if len(*calls) != 0 {
+			t.Errorf("handler calls = %+v, want none", *calls)
+		}
+	})
+}
+
+type staticNames struct {
+	names *PartyNames
+	err   error
+}
+
+func (s staticNames) ResolvePartyNames(context.Context, *PaymentTransaction) (*PartyNames, error) {
+	return s.names, s.err
+}
+
+func TestValidateReference(t *testing.T) {
+	newPending := func(t *testing.T) (PaymentService, string) {
+		service, _ := newTestService(t)
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest(""))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+		return service, resp.ReferenceNumber
+	}
+
+	t.Run("names the trader and OGA", func(t *testing.T) {
+		service, ref := newPending(t)
+		service.RegisterNameResolver(staticNames{names: &PartyNames{TraderName: "ABCD Traders", OGAName: "Sri Lanka Customs"}})
+
+		resp, err := service.ValidateReference(context.Background(), MockProviderID, ValidateReferenceRequest{PaymentReference: ref})
+		if err != nil {
+			t.Fatalf("ValidateReference() error = %v", err)
+		}
+		if !resp.IsPayable || resp.TraderName != "ABCD Traders" || resp.OGAName != "Sri Lanka Customs" {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("still validates when names cannot be resolved", func(t *testing.T) {
+		service, ref := newPending(t)
+		service.RegisterNameResolver(staticNames{err: errors.New("task not found")})
+
+		resp, err := service.ValidateReference(context.Background(), MockProviderID, ValidateReferenceRequest{PaymentReference: ref})
+		if err != nil {
+			t.Fatalf("ValidateReference() error = %v", err)
+		}
+		if !resp.IsPayable || resp.TraderName != "" {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("unknown reference is not payable", func(t *testing.T) {
+		service, _ := newTestService(t)
+
+		resp, err := service.ValidateReference(context.Background(), MockProviderID, ValidateReferenceRequest{PaymentReference: "NSW-PR-2026-NOPE"})
+		if err != nil || resp.IsPayable {
+			t.Errorf("ValidateReference() = %+v, %v", resp, err)
+		}
+	})
+}
+
+func TestProcessWebhook_PartialPayments(t *testing.T) {
+	setup := func(t *testing.T) (PaymentService, *mockRepository, string, *[]PaymentStatus) {
+		service, repo := newTestService(t)
+		var reported []PaymentStatus
+		service.RegisterOutcomeHandler(func(ctx context.Context, tx PaymentTransaction) error {
+			reported = append(reported, tx.Status)
+			return nil
+		})
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest(""))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+		return service, repo, resp.ReferenceNumber, &reported
+	}
+	deliver := func(t *testing.T, service PaymentService, payload WebhookPayload) error {
+		t.Helper()
+		body, headers := signedWebhook(t, payload)
+		return service.ProcessWebhook(context.Background(), MockProviderID, body, headers)
+	}
+	payment := func(ref string, status PaymentStatus, gatewayID string, amount int64) WebhookPayload {
+		return WebhookPayload{ReferenceNumber: ref, Status: status, GatewayTransactionID: gatewayID, Amount: decimal.NewFromInt(amount), Currency: "LKR"}
+	}
+
+	t.Run("instalments complete the payment", func(t *testing.T) {
+		service, repo, ref, reported := setup(t)
+
+		for _, p := range []WebhookPayload{
+			payment(ref, PaymentStatusPartiallyPaid, "gw-1", 500),
+			payment(ref, PaymentStatusPartiallyPaid, "gw-1", 500), // redelivered
+			payment(ref, PaymentStatusPartiallyPaid, "gw-2", 500),
+			payment(ref, PaymentStatusSuccess, "gw-3", 500),
+		} {
+			if err := deliver(t, service, p); err != nil {
+				t.Fatalf("ProcessWebhook(%s) error = %v", p.GatewayTransactionID, err)
+			}
+		}
+
+		if repo.txs[ref].Status != PaymentStatusSuccess {
+			t.Errorf("status = %s, want SUCCESS", repo.txs[ref].Status)
+		}
+		if want := []PaymentStatus{PaymentStatusPartiallyPaid, PaymentStatusSuccess}; len(*reported) != 2 || (*reported)[0] != want[0] || (*reported)[1] != want[1] {
+			t.Errorf("reported = %v, want %v", *reported, want)
+		}
+		ledger, err := service.GetTaskLedger(context.Background(), "task-1")
+		if err != nil {
+			t.Fatalf("GetTaskLedger() error = %v", err)
+		}
+		if len(ledger.Entries) != 3 || !ledger.Net.Equal(decimal.NewFromInt(1500)) || ledger.Currency != "LKR" {
+			t.Errorf("unexpected ledger: %+v", ledger)
+		}
+	})
+
+	t.Run("rejects success before the full amount is paid", func(t *testing.T) {
+		service, repo, ref, _ := setup(t)
+		if err := deliver(t, service, payment(ref, PaymentStatusPartiallyPaid, "gw-1", 500)); err != nil {
+			t.Fatalf("ProcessWebhook() error = %v", err)
+		}
+
+		err := deliver(t, service, payment(ref, PaymentStatusSuccess, "gw-2", 500))
+		if !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("error = %v, want ErrInvalidWebhook", err)
+		}
+		if len(repo.ledger) != 1 || repo.txs[ref].Status != PaymentStatusPartiallyPaid {
+			t.Errorf("rejected payment must not be recorded: %+v", repo.ledger)
+		}
+	})
+
+	t.Run("records an overpayment", func(t *testing.T) {
+		service, repo, ref, _ := setup(t)
+
+		if err := deliver(t, service, payment(ref, PaymentStatusSuccess, "gw-1", 1600)); err != nil {
+			t.Fatalf("ProcessWebhook() error = %v", err)
+		}
+		ledger, _ := service.GetTaskLedger(context.Background(), "task-1")
+		if repo.txs[ref].Status != PaymentStatusSuccess || !ledger.Paid.Equal(decimal.NewFromInt(1600)) {
+			t.Errorf("status = %s, paid = %s", repo.txs[ref].Status, ledger.Paid)
+		}
+	})
+
+	t.Run("ignores a failure once money was received", func(t *testing.T) {
+		service, repo, ref, _ := setup(t)
+		if err := deliver(t, service, payment(ref, PaymentStatusPartiallyPaid, "gw-1", 500)); err != nil {
+			t.Fatalf("ProcessWebhook() error = %v", err)
+		}
+
+		if err := deliver(t, service, WebhookPayload{ReferenceNumber: ref, Status: PaymentStatusFailed}); err != nil {
+			t.Fatalf("ProcessWebhook(FAILED) error = %v", err)
+		}
+		if repo.txs[ref].Status != PaymentStatusPartiallyPaid {
+			t.Errorf("status = %s, want PARTIALLY_PAID", repo.txs[ref].Status)
+		}
+	})
+}
+
+func TestRequestRefund(t *testing.T) {
+	setup := func(t *testing.T) (PaymentService, *mockRepository, string, *[]PaymentStatus) {
+		service, repo := newTestService(t)
+		var reported []PaymentStatus
+		service.RegisterOutcomeHandler(func(ctx context.Context, tx PaymentTransaction) error {
+			reported = append(reported, tx.Status)
+			return nil
+		})
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest(""))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+		body, headers := signedWebhook(t, WebhookPayload{
+			ReferenceNumber: resp.ReferenceNumber, Status: PaymentStatusSuccess, GatewayTransactionID: "gw-1",
+			Amount: decimal.NewFromInt(1500), Currency: "LKR",
+		})
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+			t.Fatalf("ProcessWebhook() error = %v", err)
+		}
+		return service, repo, resp.ReferenceNumber, &reported
+	}
+	refunded := func(t *testing.T, ref, refundID string, amount int64) ([]byte, map[string][]string) {
+		return signedWebhook(t, WebhookPayload{
+			ReferenceNumber: ref, Status: PaymentStatusRefunded, GatewayTransactionID: refundID,
+			Amount: decimal.NewFromInt(amount), Currency: "LKR",
+		})
+	}
+
+	t.Run("refunds part of a payment once the gateway confirms", func(t *testing.T) {
+		service, repo, ref, reported := setup(t)
+
+		tx, err := service.RequestRefund(context.Background(), ref, decimal.NewFromInt(500), "overpaid")
+		if err != nil {
+			t.Fatalf("RequestRefund() error = %v", err)
+		}
+		if tx.Status != PaymentStatusRefundRequested || repo.txs[ref].Status != PaymentStatusRefundRequested {
+			t.Fatalf("status = %s, want REFUND_REQUESTED", repo.txs[ref].Status)
+		}
+		if _, err := service.RequestRefund(context.Background(), ref, decimal.NewFromInt(500), "again"); !errors.Is(err, ErrRefundNotAllowed) {
+			t.Errorf("second RequestRefund() error = %v, want ErrRefundNotAllowed", err)
+		}
+
+		body, headers := refunded(t, ref, "mock_refund_1", 500)
+		for i := 0; i < 2; i++ {
+			if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+				t.Fatalf("delivery %d: ProcessWebhook(REFUNDED) error = %v", i, err)
+			}
+		}
+
+		ledger, _ := service.GetTaskLedger(context.Background(), "task-1")
+		if !ledger.Refunded.Equal(decimal.NewFromInt(500)) || !ledger.Net.Equal(decimal.NewFromInt(1000)) {
+			t.Errorf("refunded = %s, net = %s", ledger.Refunded, ledger.Net)
+		}
+		if repo.txs[ref].Status != PaymentStatusRefunded {
+			t.Errorf("status = %s, want REFUNDED", repo.txs[ref].Status)
+		}
+		if want := []PaymentStatus{PaymentStatusSuccess, PaymentStatusRefunded}; len(*reported) != 2 || (*reported)[1] != want[1] {
+			t.Errorf("reported = %v, want %v", *reported, want)
+		}
+	})
+
+	t.Run("a later refund is reported again", func(t *testing.T) {
+		service, _, ref, reported := setup(t)
+
+		for i, refundID := range []string{"mock_refund_1", "mock_refund_2"} {
+			if _, err := service.RequestRefund(context.Background(), ref, decimal.NewFromInt(500), ""); err != nil {
+				t.Fatalf("refund %d: RequestRefund() error = %v", i, err)
+			}
+			body, headers := refunded(t, ref, refundID, 500)
+			if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+				t.Fatalf("refund %d: ProcessWebhook() error = %v", i, err)
+			}
+		}
+		if len(*reported) != 3 || (*reported)[2] != PaymentStatusRefunded {
+			t.Errorf("reported = %v, want SUCCESS then two REFUNDED", *reported)
+		}
+	})
+
+	t.Run("refunds everything held by default", func(t *testing.T) {
+		service, _, ref, _ := setup(t)
+
+		if _, err := service.RequestRefund(context.Background(), ref, decimal.Zero, ""); err != nil {
+			t.Fatalf("RequestRefund() error = %v", err)
+		}
+		body, headers := refunded(t, ref, "mock_refund_1", 1500)
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); err != nil {
+			t.Fatalf("ProcessWebhook() error = %v", err)
+		}
+		ledger, _ := service.GetTaskLedger(context.Background(), "task-1")
+		if !ledger.Net.IsZero() {
+			t.Errorf("net = %s, want 0", ledger.Net)
+		}
+		if _, err := service.RequestRefund(context.Background(), ref, decimal.Zero, ""); !errors.Is(err, ErrRefundNotAllowed) {
+			t.Errorf("RequestRefund() with nothing held error = %v, want ErrRefundNotAllowed", err)
+		}
+	})
+
+	t.Run("rejects refunds beyond what was paid", func(t *testing.T) {
+		service, repo, ref, _ := setup(t)
+
+		if _, err := service.RequestRefund(context.Background(), ref, decimal.NewFromInt(1501), ""); !errors.Is(err, ErrRefundNotAllowed) {
+			t.Errorf("RequestRefund() error = %v, want ErrRefundNotAllowed", err)
+		}
+		body, headers := refunded(t, ref, "gw-refund", 1501)
+		if err := service.ProcessWebhook(context.Background(), MockProviderID, body, headers); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("ProcessWebhook() error = %v, want ErrInvalidWebhook", err)
+		}
+		if repo.txs[ref].Status != PaymentStatusSuccess {
+			t.Errorf("status = %s, want SUCCESS", repo.txs[ref].Status)
+		}
+	})
+
+	t.Run("rejects unpaid payments", func(t *testing.T) {
+		service, _ := newTestService(t)
+		resp, err := service.CreateCheckoutSession(context.Background(), checkoutRequest(""))
+		if err != nil {
+			t.Fatalf("CreateCheckoutSession() error = %v", err)
+		}
+
+		if _, err := service.RequestRefund(context.Background(), resp.ReferenceNumber, decimal.Zero, ""); !errors.Is(err, ErrRefundNotAllowed) {
+			t.Errorf("RequestRefund() error = %v, want ErrRefundNotAllowed", err)
+		}
+		if _, err := service.RequestRefund(context.Background(), "NSW-PR-2026-NOPE", decimal.Zero, ""); !errors.Is(err, ErrTransactionNotFound) {
+			t.Errorf("RequestRefund() error = %v, want ErrTransactionNotFound", err)
+		}
+	})
+}
@@ -30,6 +30,10 @@ type Service interface {
 	// Returns ErrCompanyNotFound if the company does not exist.
 	UpdateCompany(ctx context.Context, id string, data map[string]any) error
 
+	// UpdateCompanyInTx is UpdateCompany run within the caller's transaction, so that the
+	// merge is rolled back together with it.
+	UpdateCompanyInTx(ctx context.Context, tx *gorm.DB, id string, data map[string]any) error
+
 	// Health checks if the service can access the database.
 	Health(ctx context.Context) error
 }
@@ -73,6 +77,10 @@ func (s *service) GetCompanyByOUId(ctx context.Context, ouId string) (*Record, e
 }
 
 func (s *service) UpdateCompany(ctx context.Context, id string, data map[string]any) error {
+	return s.UpdateCompanyInTx(ctx, s.db, id, data)
+}
+
+func (s *service) UpdateCompanyInTx(ctx context.Context, tx *gorm.DB, id string, data map[string]any) error {
 	if id == "" {
 		return ErrInvalidCompanyID
 	}
@@ -88,7 +96,7 @@ func (s *service) UpdateCompany(ctx context.Context, id string, data map[string]
 
 	// Use PostgreSQL's JSONB concatenation operator (||) for an atomic merge.
 	// This avoids race conditions inherent in a read-modify-write cycle.
-	result := s.db.WithContext(ctx).Model(&Record{}).
+	result := tx.WithContext(ctx).Model(&Record{}).
 		Where("id = ?", id).
 		Update("data", gorm.Expr("data || ?::jsonb", string(jsonBytes)))
 
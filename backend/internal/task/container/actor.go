@@ -0,0 +1,64 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+)
+
+// Actor identifies the principal behind a task transition.
+type Actor struct {
+	Type persistence.ActorType
+	ID   string
+}
+
+type systemActorKey struct{}
+
+// WithSystemActor marks ctx as an internal caller, e.g. "workflow" or "deadline", so that
+// transitions made without an authenticated principal are still attributed.
+func WithSystemActor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, systemActorKey{}, name)
+}
+
+// ActorFromContext resolves the principal in ctx: the authenticated user or M2M client if
+// present, otherwise a system actor.
+func ActorFromContext(ctx context.Context) Actor {
+	if authCtx := auth.GetAuthContext(ctx); authCtx != nil {
+		if authCtx.User != nil {
+			return Actor{Type: persistence.ActorTypeUser, ID: authCtx.User.ID}
+		}
+		if authCtx.Client != nil {
+			return Actor{Type: persistence.ActorTypeClient, ID: authCtx.Client.ClientID}
+		}
+	}
+	name, _ := ctx.Value(systemActorKey{}).(string)
+	return Actor{Type: persistence.ActorTypeSystem, ID: name}
+}
+
+// invocation is the caller of the Start, Execute or Fire call that is currently running.
+type invocation struct {
+	actor       Actor
+	payloadHash string
+}
+
+func newInvocation(ctx context.Context, request *plugin.ExecutionRequest) *invocation {
+	return &invocation{actor: ActorFromContext(ctx), payloadHash: hashPayload(request)}
+}
+
+// hashPayload returns the hex SHA-256 of the request as sent, or "" if there is none.
+func hashPayload(request *plugin.ExecutionRequest) string {
+	if request == nil {
+		return ""
+	}
+	b, err := json.Marshal(request)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
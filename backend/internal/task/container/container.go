@@ -2,12 +2,19 @@ package container
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 
+	"gorm.io/gorm"
+
 	"github.com/OpenNSW/nsw/internal/task/persistence"
 	"github.com/OpenNSW/nsw/internal/task/plugin"
 )
 
+// ErrActionNotAllowed is returned by Fire when the task no longer accepts the action.
+var ErrActionNotAllowed = errors.New("action not allowed in current task state")
+
 type Container struct {
 	TaskID                 string
 	WorkflowID             string
@@ -17,9 +24,15 @@ type Container struct {
 	globalState            map[string]any
 	localState             persistence.Manager
 	taskStore              persistence.TaskStoreInterface
-	pluginState            string // Cache for plugin-level business state
+	eventStore             persistence.TaskEventStoreInterface // Audit trail of transitions; optional
+	pluginState            string                              // Cache for plugin-level business state
 	fsm                    *plugin.PluginFSM
+	onTransition           TransitionHook // Called after each persisted transition; optional
 	mu                     sync.RWMutex
+
+	// execMu serializes Start, Execute and Fire so that transitions are attributed to the invocation that caused them.
+	execMu  sync.Mutex
+	current *invocation
 }
 
 func (c *Container) GetTaskState() plugin.State {
@@ -40,32 +53,78 @@ func (c *Container) CanTransition(action string) bool {
 	return c.fsm.CanTransition(c.GetPluginState(), action)
 }
 
-// Transition applies the FSM transition for action, updating in-memory state and
-// persisting both plugin state and task state to the store.
+// Transition applies the FSM transition for action, persisting the plugin state, the task state
+// and the audit event in one transaction before updating the in-memory state. If any of them
+// cannot be written the transition fails and the task stays in its previous state.
 func (c *Container) Transition(action string) error {
 	if c.fsm == nil {
 		return nil
 	}
-	outcome, err := c.fsm.Transition(c.GetPluginState(), action)
+	prev := c.GetPluginState()
+	outcome, err := c.fsm.Transition(prev, action)
 	if err != nil {
 		return err
 	}
-	c.mu.Lock()
-	c.pluginState = outcome.NextPluginState
+	var status *plugin.State
 	if outcome.NextTaskState != "" {
-		c.State = outcome.NextTaskState
+		status = &outcome.NextTaskState
 	}
-	c.mu.Unlock()
-	if err := c.taskStore.UpdatePluginState(c.TaskID, outcome.NextPluginState); err != nil {
+
+	event := c.newEvent(action, prev, outcome.NextPluginState)
+	var recordEvent func(tx *gorm.DB) error
+	if c.eventStore != nil {
+		recordEvent = func(tx *gorm.DB) error {
+			if err := c.eventStore.AppendInTx(tx, event); err != nil {
+				return fmt.Errorf("failed to record task event: %w", err)
+			}
+			return nil
+		}
+	}
+	if err := c.taskStore.UpdateTransition(c.TaskID, outcome.NextPluginState, status, recordEvent); err != nil {
 		return err
 	}
-	if outcome.NextTaskState != "" {
-		return c.taskStore.UpdateStatus(c.TaskID, &outcome.NextTaskState)
+
+	c.mu.Lock()
+	c.pluginState = outcome.NextPluginState
+	if status != nil {
+		c.State = *status
+	}
+	taskState := c.State
+	c.mu.Unlock()
+	if c.onTransition != nil {
+		c.onTransition(*event, taskState)
 	}
 	return nil
 }
 
+// Fire applies an FSM action on behalf of the caller in ctx rather than through the plugin,
+// e.g. when a node deadline passes. It returns ErrActionNotAllowed if the task has finished or
+// its plugin state does not permit action. The check is made under execMu, so a concurrent
+// Start or Execute cannot move the task in between. beforeTransition, if non-nil, runs once the
+// action has been accepted and before it is applied; an error from it aborts the transition.
+func (c *Container) Fire(ctx context.Context, action string, beforeTransition func() error) error {
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+	c.current = newInvocation(ctx, nil)
+	defer func() { c.current = nil }()
+
+	if state := c.GetTaskState(); state == plugin.Completed || state == plugin.Failed || !c.CanTransition(action) {
+		return fmt.Errorf("%w: %q from %q", ErrActionNotAllowed, action, c.GetPluginState())
+	}
+	if beforeTransition != nil {
+		if err := beforeTransition(); err != nil {
+			return err
+		}
+	}
+	return c.Transition(action)
+}
+
 func (c *Container) Start(ctx context.Context) (*plugin.ExecutionResponse, error) {
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+	c.current = newInvocation(ctx, nil)
+	defer func() { c.current = nil }()
+
 	prev := c.GetPluginState()
 	resp, err := c.Executable.Start(ctx)
 	if err != nil {
@@ -89,6 +148,11 @@ func (c *Container) GetRenderInfo(ctx context.Context) (*plugin.ApiResponse, err
 }
 
 func (c *Container) Execute(ctx context.Context, request *plugin.ExecutionRequest) (*plugin.ExecutionResponse, error) {
+	c.execMu.Lock()
+	defer c.execMu.Unlock()
+	c.current = newInvocation(ctx, request)
+	defer func() { c.current = nil }()
+
 	prev := c.GetPluginState()
 	resp, err := c.Executable.Execute(ctx, request)
 	if err != nil {
@@ -140,10 +204,39 @@ func (c *Container) GetPluginState() string {
 	return c.pluginState
 }
 
+// TransitionHook is called after a transition has been persisted, with the audit event
+// describing it and the resulting task-level state.
+type TransitionHook func(event persistence.TaskEvent, taskState plugin.State)
+
+// SetTransitionHook registers the hook called after each transition. It must be set before
+// the container is shared.
+func (c *Container) SetTransitionHook(hook TransitionHook) {
+	c.onTransition = hook
+}
+
+// newEvent describes a transition for the audit trail, attributed to the current invocation.
+func (c *Container) newEvent(action, from, to string) *persistence.TaskEvent {
+	inv := c.current
+	if inv == nil {
+		inv = newInvocation(context.Background(), nil)
+	}
+	return &persistence.TaskEvent{
+		TaskID:          c.TaskID,
+		WorkflowID:      c.WorkflowID,
+		Action:          action,
+		FromPluginState: from,
+		ToPluginState:   to,
+		ActorType:       inv.actor.Type,
+		ActorID:         inv.actor.ID,
+		PayloadHash:     inv.payloadHash,
+	}
+}
+
 // NewContainer creates a new container for a task with a given Executable plugin and FSM.
 // initialState is the task-level state to restore (InProgress for new tasks, or the
 // persisted state when rebuilding from the store after a cache miss).
-func NewContainer(taskId string, workflowId string, workflowNodeTemplateId string, initialState plugin.State, globalStore map[string]any, localStore persistence.Manager, taskStore persistence.TaskStoreInterface, executable plugin.Plugin, fsm *plugin.PluginFSM) *Container {
+// eventStore may be nil, in which case transitions are not audited.
+func NewContainer(taskId string, workflowId string, workflowNodeTemplateId string, initialState plugin.State, globalStore map[string]any, localStore persistence.Manager, taskStore persistence.TaskStoreInterface, eventStore persistence.TaskEventStoreInterface, executable plugin.Plugin, fsm *plugin.PluginFSM) *Container {
 	c := &Container{
 		TaskID:                 taskId,
 		WorkflowID:             workflowId,
@@ -153,6 +246,7 @@ func NewContainer(taskId string, workflowId string, workflowNodeTemplateId strin
 		globalState:            globalStore,
 		localState:             localStore,
 		taskStore:              taskStore,
+		eventStore:             eventStore,
 		fsm:                    fsm,
 	}
 
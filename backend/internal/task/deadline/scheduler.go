@@ -0,0 +1,131 @@
+// Package deadline schedules per-node task deadlines on Temporal.
+//
+// Each deadline runs as its own Temporal workflow that sleeps on a durable timer until the
+// deadline passes and then fires the deadline action through the task manager. Because the
+// timer lives in Temporal, scheduled deadlines survive process restarts.
+package deadline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+)
+
+const (
+	taskQueue        = "TASK_DEADLINE_TASK_QUEUE"
+	workflowName     = "TaskDeadlineWorkflow"
+	fireActivityName = "FireTaskDeadline"
+	workflowIDPrefix = "task-deadline-"
+	fireTimeout      = 30 * time.Second
+	// fireMaxAttempts bounds how often a failing deadline is retried before the workflow gives up.
+	fireMaxAttempts = 10
+)
+
+// Firer applies a deadline once it has passed. It is implemented by the task manager.
+type Firer interface {
+	FireDeadline(ctx context.Context, deadline plugin.Deadline) error
+}
+
+// Scheduler schedules task deadlines as Temporal workflows and runs the worker that fires them.
+type Scheduler struct {
+	client client.Client
+	worker worker.Worker
+}
+
+// NewScheduler creates a Scheduler and registers the deadline workflow and activity on its worker.
+func NewScheduler(temporalClient client.Client, firer Firer) (*Scheduler, error) {
+	if temporalClient == nil {
+		return nil, fmt.Errorf("temporal client is required")
+	}
+	if firer == nil {
+		return nil, fmt.Errorf("deadline firer is required")
+	}
+
+	w := worker.New(temporalClient, taskQueue, worker.Options{})
+	w.RegisterWorkflowWithOptions(Workflow, workflow.RegisterOptions{Name: workflowName})
+	w.RegisterActivityWithOptions((&activities{firer: firer}).Fire, activity.RegisterOptions{Name: fireActivityName})
+
+	return &Scheduler{client: temporalClient, worker: w}, nil
+}
+
+// Start starts the deadline worker.
+func (s *Scheduler) Start() error {
+	if err := s.worker.Start(); err != nil {
+		return fmt.Errorf("failed to start deadline worker: %w", err)
+	}
+	return nil
+}
+
+// Stop stops the deadline worker. Scheduled deadlines stay in Temporal and fire once a worker is running again.
+func (s *Scheduler) Stop() {
+	s.worker.Stop()
+}
+
+// ScheduleDeadline starts the deadline workflow for a task. Scheduling is idempotent per task:
+// a deadline that is already scheduled or has already fired is left as is.
+func (s *Scheduler) ScheduleDeadline(ctx context.Context, deadline plugin.Deadline) error {
+	options := client.StartWorkflowOptions{
+		ID:                       WorkflowID(deadline.TaskID),
+		TaskQueue:                taskQueue,
+		WorkflowIDReusePolicy:    enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+		WorkflowIDConflictPolicy: enumspb.WORKFLOW_ID_CONFLICT_POLICY_USE_EXISTING,
+	}
+	if _, err := s.client.ExecuteWorkflow(ctx, options, workflowName, deadline); err != nil {
+		var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+		if errors.As(err, &alreadyStarted) {
+			return nil
+		}
+		return fmt.Errorf("failed to schedule deadline for task %s: %w", deadline.TaskID, err)
+	}
+	return nil
+}
+
+// WorkflowID returns the Temporal workflow ID used for a task's deadline.
+func WorkflowID(taskID string) string {
+	return workflowIDPrefix + taskID
+}
+
+// Workflow sleeps until the deadline passes and then fires it.
+func Workflow(ctx workflow.Context, deadline plugin.Deadline) error {
+	if wait := deadline.At.Sub(workflow.Now(ctx)); wait > 0 {
+		if err := workflow.Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: fireTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    fireMaxAttempts,
+		},
+	})
+	if err := workflow.ExecuteActivity(ctx, fireActivityName, deadline).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("task deadline could not be fired, giving up",
+			"taskId", deadline.TaskID, "action", deadline.Action, "attempts", fireMaxAttempts, "error", err)
+		return fmt.Errorf("failed to fire deadline for task %s after %d attempts: %w", deadline.TaskID, fireMaxAttempts, err)
+	}
+	return nil
+}
+
+type activities struct {
+	firer Firer
+}
+
+// Fire applies the deadline through the task manager.
+func (a *activities) Fire(ctx context.Context, deadline plugin.Deadline) error {
+	return a.firer.FireDeadline(ctx, deadline)
+}
@@ -0,0 +1,95 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+)
+
+type fakeFirer struct {
+	fired []plugin.Deadline
+}
+
+func (f *fakeFirer) FireDeadline(_ context.Context, d plugin.Deadline) error {
+	f.fired = append(f.fired, d)
+	return nil
+}
+
+type failingFirer struct {
+	attempts int
+}
+
+func (f *failingFirer) FireDeadline(context.Context, plugin.Deadline) error {
+	f.attempts++
+	return errors.New("task manager unavailable")
+}
+
+func newTestEnv(t *testing.T, firer Firer) *testsuite.TestWorkflowEnvironment {
+	t.Helper()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(Workflow, workflow.RegisterOptions{Name: workflowName})
+	env.RegisterActivityWithOptions((&activities{firer: firer}).Fire, activity.RegisterOptions{Name: fireActivityName})
+	return env
+}
+
+func TestWorkflow_FiresAfterDeadline(t *testing.T) {
+	firer := &fakeFirer{}
+	env := newTestEnv(t, firer)
+
+	start := env.Now()
+	d := plugin.Deadline{TaskID: "task-1", At: start.Add(72 * time.Hour), Action: plugin.DeadlineActionEscalate}
+	env.ExecuteWorkflow(workflowName, d)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Len(t, firer.fired, 1)
+	assert.Equal(t, "task-1", firer.fired[0].TaskID)
+	assert.Equal(t, plugin.DeadlineActionEscalate, firer.fired[0].Action)
+	assert.False(t, env.Now().Before(d.At), "deadline fired before it passed")
+}
+
+func TestWorkflow_PastDeadlineFiresImmediately(t *testing.T) {
+	firer := &fakeFirer{}
+	env := newTestEnv(t, firer)
+
+	start := env.Now()
+	d := plugin.Deadline{TaskID: "task-1", At: start.Add(-time.Hour), Action: plugin.DeadlineActionTimeout}
+	env.ExecuteWorkflow(workflowName, d)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Len(t, firer.fired, 1)
+	assert.Equal(t, start, env.Now())
+}
+
+func TestWorkflow_StopsRetryingAfterMaxAttempts(t *testing.T) {
+	firer := &failingFirer{}
+	env := newTestEnv(t, firer)
+
+	d := plugin.Deadline{TaskID: "task-1", At: env.Now(), Action: plugin.DeadlineActionTimeout}
+	env.ExecuteWorkflow(workflowName, d)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.Error(t, env.GetWorkflowError())
+	assert.Contains(t, env.GetWorkflowError().Error(), "task-1")
+	assert.Equal(t, fireMaxAttempts, firer.attempts)
+}
+
+func TestWorkflowID(t *testing.T) {
+	assert.Equal(t, "task-deadline-abc", WorkflowID("abc"))
+}
+
+func TestNewScheduler_RequiresDependencies(t *testing.T) {
+	_, err := NewScheduler(nil, &fakeFirer{})
+	assert.Error(t, err)
+}
@@ -1,20 +1,31 @@
 package manager
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strings"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
 )
 
+// TaskAuthorizer decides whether the caller may read or act on a task.
+type TaskAuthorizer interface {
+	AuthorizeTaskAction(ctx context.Context, taskID string, request *plugin.ExecutionRequest) error
+	AuthorizeTaskRead(ctx context.Context, taskID string) error
+}
+
 // HTTPHandler encapsulates the HTTP transport logic for TaskManager
 type HTTPHandler struct {
-	manager TaskManager
+	manager    TaskManager
+	authorizer TaskAuthorizer
 }
 
 // NewHTTPHandler creates a new HTTPHandler for the task manager
-func NewHTTPHandler(manager TaskManager) *HTTPHandler {
-	return &HTTPHandler{manager: manager}
+func NewHTTPHandler(manager TaskManager, authorizer TaskAuthorizer) *HTTPHandler {
+	return &HTTPHandler{manager: manager, authorizer: authorizer}
 }
 
 // HandleGetTask is an HTTP handler for fetching task information via GET request
@@ -25,22 +36,42 @@ func (h *HTTPHandler) HandleGetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.authorizer.AuthorizeTaskRead(r.Context(), taskId); err != nil {
+		writeJSONError(w, authorizationStatus(err), err.Error())
+		return
+	}
+
 	result, err := h.manager.GetTaskRenderInfo(r.Context(), taskId)
 	if err != nil {
-		// Differentiate between invalid ID/NotFound and internal errors, if necessary.
-		status := http.StatusInternalServerError
-		if strings.HasPrefix(err.Error(), "taskID is invalid") {
-			status = http.StatusBadRequest
-		} else if strings.HasPrefix(err.Error(), "task ") {
-			status = http.StatusNotFound
-		}
-		writeJSONError(w, status, err.Error())
+		writeJSONError(w, taskErrorStatus(err), err.Error())
 		return
 	}
 
 	writeJSONResponse(w, http.StatusOK, result)
 }
 
+// HandleGetTaskHistory is an HTTP handler for fetching a task's audit trail via GET request
+func (h *HTTPHandler) HandleGetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	taskId := r.PathValue("id")
+	if taskId == "" {
+		writeJSONError(w, http.StatusBadRequest, "taskId is required")
+		return
+	}
+
+	if err := h.authorizer.AuthorizeTaskRead(r.Context(), taskId); err != nil {
+		writeJSONError(w, authorizationStatus(err), err.Error())
+		return
+	}
+
+	events, err := h.manager.GetTaskHistory(r.Context(), taskId)
+	if err != nil {
+		writeJSONError(w, taskErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, events)
+}
+
 // HandleExecuteTask is an HTTP handler for executing a task via POST request
 func (h *HTTPHandler) HandleExecuteTask(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -53,16 +84,18 @@ func (h *HTTPHandler) HandleExecuteTask(w http.ResponseWriter, r *http.Request)
 		writeJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
+	if req.Payload != nil {
+		req.Payload.Source = r.URL.Query().Get(plugin.CallbackSourceQueryParam)
+	}
+
+	if err := h.authorizer.AuthorizeTaskAction(r.Context(), req.TaskID, req.Payload); err != nil {
+		writeJSONError(w, authorizationStatus(err), err.Error())
+		return
+	}
 
 	result, err := h.manager.ExecuteTask(r.Context(), req)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if string(err.Error()) == "task_id is required" {
-			status = http.StatusBadRequest
-		} else if len(err.Error()) >= 5 && string(err.Error()[:5]) == "task " {
-			status = http.StatusNotFound
-		}
-		writeJSONError(w, status, err.Error())
+		writeJSONError(w, taskErrorStatus(err), err.Error())
 		return
 	}
 
@@ -70,6 +103,34 @@ func (h *HTTPHandler) HandleExecuteTask(w http.ResponseWriter, r *http.Request)
 	writeJSONResponse(w, http.StatusOK, result.ApiResponse)
 }
 
+// authorizationStatus maps an authorization failure to its HTTP status.
+func authorizationStatus(err error) int {
+	switch {
+	case errors.Is(err, authz.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, authz.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, authz.ErrTaskIDRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, authz.ErrTaskNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// taskErrorStatus maps a task manager error to its HTTP status.
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrTaskIDRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTaskNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func writeJSONResponse(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
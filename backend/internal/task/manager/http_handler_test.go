@@ -2,18 +2,56 @@ package manager
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
 )
 
+// recordingTaskManager captures the ExecuteTaskRequest passed by the handler.
+type recordingTaskManager struct {
+	TaskManager
+	lastExecute ExecuteTaskRequest
+	history     []persistence.TaskEvent
+	historyErr  error
+}
+
+func (m *recordingTaskManager) GetTaskHistory(_ context.Context, _ string) ([]persistence.TaskEvent, error) {
+	return m.history, m.historyErr
+}
+
+func (m *recordingTaskManager) ExecuteTask(_ context.Context, req ExecuteTaskRequest) (*plugin.ExecutionResponse, error) {
+	m.lastExecute = req
+	return &plugin.ExecutionResponse{ApiResponse: &plugin.ApiResponse{Success: true}}, nil
+}
+
+// stubAuthorizer returns fixed authorization results; the zero value allows everything.
+type stubAuthorizer struct {
+	actionErr error
+	readErr   error
+}
+
+func (a *stubAuthorizer) AuthorizeTaskAction(_ context.Context, _ string, _ *plugin.ExecutionRequest) error {
+	return a.actionErr
+}
+
+func (a *stubAuthorizer) AuthorizeTaskRead(_ context.Context, _ string) error {
+	return a.readErr
+}
+
 func TestHTTPHandler_HandleExecuteTask(t *testing.T) {
 	t.Run("Invalid Method", func(t *testing.T) {
 		tm := &taskManager{}
-		handler := NewHTTPHandler(tm)
+		handler := NewHTTPHandler(tm, &stubAuthorizer{})
 		req := httptest.NewRequest(http.MethodGet, "/execute", nil)
 		w := httptest.NewRecorder()
 
@@ -25,7 +63,7 @@ func TestHTTPHandler_HandleExecuteTask(t *testing.T) {
 
 	t.Run("Invalid Body", func(t *testing.T) {
 		tm := &taskManager{}
-		handler := NewHTTPHandler(tm)
+		handler := NewHTTPHandler(tm, &stubAuthorizer{})
 		req := httptest.NewRequest(http.MethodPost, "/execute", bytes.NewBufferString("invalid json"))
 		w := httptest.NewRecorder()
 
@@ -34,12 +72,51 @@ func TestHTTPHandler_HandleExecuteTask(t *testing.T) {
 		resp := w.Result()
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
+
+	t.Run("Callback source from query", func(t *testing.T) {
+		tm := &recordingTaskManager{}
+		handler := NewHTTPHandler(tm, &stubAuthorizer{})
+		body := `{"task_id":"t1","payload":{"action":"OGA_VERIFICATION","source":"spoofed"}}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks?source=npqs", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleExecuteTask(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		require.NotNil(t, tm.lastExecute.Payload)
+		assert.Equal(t, "npqs", tm.lastExecute.Payload.Source)
+	})
+
+	t.Run("Authorization failures", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{authz.ErrUnauthenticated, http.StatusUnauthorized},
+			{authz.ErrForbidden, http.StatusForbidden},
+			{authz.ErrTaskIDRequired, http.StatusBadRequest},
+			{fmt.Errorf("%w: t1: record not found", authz.ErrTaskNotFound), http.StatusNotFound},
+			{errors.New("database unavailable"), http.StatusInternalServerError},
+		}
+		for _, tt := range tests {
+			tm := &recordingTaskManager{}
+			handler := NewHTTPHandler(tm, &stubAuthorizer{actionErr: tt.err})
+			body := `{"task_id":"t1","payload":{"action":"SUBMIT_FORM"}}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+
+			handler.HandleExecuteTask(w, req)
+
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+			assert.Empty(t, tm.lastExecute.TaskID, "task must not be executed")
+		}
+	})
 }
 
 func TestHTTPHandler_HandleGetTask(t *testing.T) {
 	t.Run("Missing TaskID", func(t *testing.T) {
 		tm, _, _, _ := setupTest(t)
-		handler := NewHTTPHandler(tm)
+		handler := NewHTTPHandler(tm, &stubAuthorizer{})
 		req := httptest.NewRequest(http.MethodGet, "/tasks/", nil)
 		// No path value set
 		w := httptest.NewRecorder()
@@ -52,7 +129,7 @@ func TestHTTPHandler_HandleGetTask(t *testing.T) {
 
 	t.Run("Invalid TaskID string", func(t *testing.T) {
 		tm, _, mockStore, _ := setupTest(t)
-		handler := NewHTTPHandler(tm)
+		handler := NewHTTPHandler(tm, &stubAuthorizer{})
 		req := httptest.NewRequest(http.MethodGet, "/tasks/invalid", nil)
 		req.SetPathValue("id", "invalid")
 		w := httptest.NewRecorder()
@@ -65,3 +142,43 @@ func TestHTTPHandler_HandleGetTask(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
 }
+
+func TestHTTPHandler_HandleGetTaskHistory(t *testing.T) {
+	t.Run("Returns events", func(t *testing.T) {
+		tm := &recordingTaskManager{history: []persistence.TaskEvent{{TaskID: "t1", Action: "START"}}}
+		handler := NewHTTPHandler(tm, &stubAuthorizer{})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/t1/history", nil)
+		req.SetPathValue("id", "t1")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetTaskHistory(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Contains(t, w.Body.String(), `"action":"START"`)
+	})
+
+	t.Run("Task not found", func(t *testing.T) {
+		tm := &recordingTaskManager{historyErr: fmt.Errorf("%w: t1: record not found", ErrTaskNotFound)}
+		handler := NewHTTPHandler(tm, &stubAuthorizer{})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/t1/history", nil)
+		req.SetPathValue("id", "t1")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetTaskHistory(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		tm := &recordingTaskManager{history: []persistence.TaskEvent{{TaskID: "t1", Action: "START"}}}
+		handler := NewHTTPHandler(tm, &stubAuthorizer{readErr: authz.ErrForbidden})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/t1/history", nil)
+		req.SetPathValue("id", "t1")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetTaskHistory(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+		assert.NotContains(t, w.Body.String(), `"action":"START"`)
+	})
+}
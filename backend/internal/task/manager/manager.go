@@ -3,9 +3,11 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -14,6 +16,13 @@ import (
 	"github.com/OpenNSW/nsw/internal/task/plugin"
 )
 
+var (
+	// ErrTaskIDRequired is returned when a request does not identify a task.
+	ErrTaskIDRequired = errors.New("task_id is required")
+	// ErrTaskNotFound is returned when a task does not exist or cannot be loaded.
+	ErrTaskNotFound = errors.New("task not found")
+)
+
 type InitTaskRequest struct {
 	// Task ID is the unique identifier for this task instance.
 	TaskID string `json:"task_id"`
@@ -49,6 +58,23 @@ type WorkflowUpdateHandler func(ctx context.Context, taskID string, state *plugi
 // TODO: these functions should return an error?
 type WorkflowDoneHandler func(ctx context.Context, workflowID, taskID string, outputs map[string]any)
 
+// TaskTransition describes a plugin state change made by a task.
+type TaskTransition struct {
+	Event     persistence.TaskEvent // Audit record of the transition, including the actor
+	TaskType  plugin.Type
+	TaskState plugin.State // Task-level state after the transition
+}
+
+// TransitionListener is notified after a task transition has been persisted. It runs on the
+// goroutine that made the transition and must not call back into the same task.
+type TransitionListener func(ctx context.Context, transition TaskTransition)
+
+// DeadlineScheduler schedules durable per-node deadlines. Implementations must be idempotent
+// per task so that re-initialising a task does not schedule its deadline twice.
+type DeadlineScheduler interface {
+	ScheduleDeadline(ctx context.Context, deadline plugin.Deadline) error
+}
+
 // TaskManager handles task execution and status management
 // Architecture: Trader Portal → Workflow Engine → Task Manager
 // - Workflow Manager triggers Task Manager to get task info (e.g., form schema)
@@ -66,6 +92,16 @@ type TaskManager interface {
 	RegisterUpstreamDoneCallback(callback WorkflowDoneHandler)
 	// RegisterUpstreamUpdateCallback registers the callback used when task state changes.
 	RegisterUpstreamUpdateCallback(callback WorkflowUpdateHandler)
+
+	// GetTaskHistory returns the audit trail of a task's transitions, oldest first.
+	GetTaskHistory(ctx context.Context, taskID string) ([]persistence.TaskEvent, error)
+
+	// FireDeadline applies a task's deadline action if the task can still take it.
+	FireDeadline(ctx context.Context, deadline plugin.Deadline) error
+	// RegisterDeadlineScheduler registers the scheduler used for per-node deadlines.
+	RegisterDeadlineScheduler(scheduler DeadlineScheduler)
+	// RegisterTransitionListener registers a listener for task transitions.
+	RegisterTransitionListener(listener TransitionListener)
 }
 
 // ExecuteTaskRequest represents the request body for task execution
@@ -77,11 +113,15 @@ type ExecuteTaskRequest struct {
 
 type taskManager struct {
 	factory               plugin.TaskFactory
-	store                 persistence.TaskStoreInterface // Storage for task executions
-	workflowUpdateHandler WorkflowUpdateHandler          // Handler used to notify Workflow Manager of task updates
-	workflowDoneHandler   WorkflowDoneHandler            // Handler used to notify Workflow Manager of task completions
-	containerCache        *containerCache                // LRU cache for active containers
-	containerBuildMu      sync.Mutex                     // Protects container creation to prevent duplicates
+	store                 persistence.TaskStoreInterface      // Storage for task executions
+	events                persistence.TaskEventStoreInterface // Append-only audit trail of task transitions
+	workflowUpdateHandler WorkflowUpdateHandler               // Handler used to notify Workflow Manager of task updates
+	workflowDoneHandler   WorkflowDoneHandler                 // Handler used to notify Workflow Manager of task completions
+	containerCache        *containerCache                     // LRU cache for active containers
+	containerBuildMu      sync.Mutex                          // Protects container creation to prevent duplicates
+	deadlineScheduler     DeadlineScheduler                   // Scheduler used for per-node deadlines
+	listenersMu           sync.RWMutex                        // Protects transitionListeners
+	transitionListeners   []TransitionListener                // Listeners notified of task transitions
 }
 
 // NewTaskManager creates a new TaskManager instance with persistence data store.
@@ -90,6 +130,10 @@ func NewTaskManager(db *gorm.DB, factory plugin.TaskFactory) (TaskManager, error
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task store: %w", err)
 	}
+	events, err := persistence.NewTaskEventStore(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task event store: %w", err)
+	}
 
 	// Initialize container cache with capacity of 100 active containers
 	cache := newContainerCache(100)
@@ -97,6 +141,7 @@ func NewTaskManager(db *gorm.DB, factory plugin.TaskFactory) (TaskManager, error
 	return &taskManager{
 		factory:        factory,
 		store:          store,
+		events:         events,
 		containerCache: cache,
 	}, nil
 }
@@ -111,15 +156,41 @@ func (tm *taskManager) RegisterUpstreamDoneCallback(callback WorkflowDoneHandler
 	tm.workflowDoneHandler = callback
 }
 
+// RegisterDeadlineScheduler registers the scheduler used for per-node deadlines.
+func (tm *taskManager) RegisterDeadlineScheduler(scheduler DeadlineScheduler) {
+	tm.deadlineScheduler = scheduler
+}
+
+// RegisterTransitionListener registers a listener for task transitions.
+func (tm *taskManager) RegisterTransitionListener(listener TransitionListener) {
+	tm.listenersMu.Lock()
+	defer tm.listenersMu.Unlock()
+	tm.transitionListeners = append(tm.transitionListeners, listener)
+}
+
+// transitionHook returns the container hook that forwards a task's transitions to the registered listeners.
+func (tm *taskManager) transitionHook(taskType plugin.Type) container.TransitionHook {
+	return func(event persistence.TaskEvent, taskState plugin.State) {
+		tm.listenersMu.RLock()
+		listeners := tm.transitionListeners
+		tm.listenersMu.RUnlock()
+
+		transition := TaskTransition{Event: event, TaskType: taskType, TaskState: taskState}
+		for _, listener := range listeners {
+			listener(context.Background(), transition)
+		}
+	}
+}
+
 // GetTaskRenderInfo retrieves task rendering info (core logic)
 func (tm *taskManager) GetTaskRenderInfo(ctx context.Context, taskID string) (*plugin.ApiResponse, error) {
 	if taskID == "" {
-		return nil, fmt.Errorf("taskID is required")
+		return nil, ErrTaskIDRequired
 	}
 
 	activeTask, err := tm.getTask(ctx, taskID)
 	if err != nil {
-		return nil, fmt.Errorf("task %s not found: %w", taskID, err)
+		return nil, fmt.Errorf("%w: %s: %w", ErrTaskNotFound, taskID, err)
 	}
 
 	result, err := activeTask.GetRenderInfo(ctx)
@@ -133,12 +204,12 @@ func (tm *taskManager) GetTaskRenderInfo(ctx context.Context, taskID string) (*p
 // ExecuteTask is the core logic for executing a task
 func (tm *taskManager) ExecuteTask(ctx context.Context, req ExecuteTaskRequest) (*plugin.ExecutionResponse, error) {
 	if req.TaskID == "" {
-		return nil, fmt.Errorf("task_id is required")
+		return nil, ErrTaskIDRequired
 	}
 
 	activeTask, err := tm.getTask(ctx, req.TaskID)
 	if err != nil {
-		return nil, fmt.Errorf("task %s not found: %w", req.TaskID, err)
+		return nil, fmt.Errorf("%w: %s: %w", ErrTaskNotFound, req.TaskID, err)
 	}
 
 	result, err := tm.execute(ctx, activeTask, req.Payload)
@@ -152,13 +223,31 @@ func (tm *taskManager) ExecuteTask(ctx context.Context, req ExecuteTaskRequest)
 	return result, nil
 }
 
+// GetTaskHistory returns the audit trail of a task's transitions, oldest first.
+func (tm *taskManager) GetTaskHistory(ctx context.Context, taskID string) ([]persistence.TaskEvent, error) {
+	if taskID == "" {
+		return nil, ErrTaskIDRequired
+	}
+	if _, err := tm.store.GetByID(taskID); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrTaskNotFound, taskID, err)
+	}
+	if tm.events == nil {
+		return []persistence.TaskEvent{}, nil
+	}
+	events, err := tm.events.ListByTaskID(taskID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list task events", "taskID", taskID, "error", err)
+		return nil, fmt.Errorf("failed to get history for task %s: %w", taskID, err)
+	}
+	return events, nil
+}
+
 // InitTask initializes a new task container, creates its execution record,
 // and starts the task. It builds the plugin executor, sets up local state management,
 // creates a container with the executor and state managers, persists the task record
 // to the database, and invokes the plugin's Start method.
 // Returns InitTaskResponse on success, or an error if initialization or start fails.
 func (tm *taskManager) InitTask(ctx context.Context, request InitTaskRequest) (*InitTaskResponse, error) {
-
 	// Check if container already exists in cache
 	if existing, found := tm.containerCache.Get(request.TaskID); found {
 		slog.WarnContext(ctx, "task already initialized, reusing existing container",
@@ -188,7 +277,8 @@ func (tm *taskManager) InitTask(ctx context.Context, request InitTaskRequest) (*
 		globalStateCopy[k] = v
 	}
 
-	activeTask := container.NewContainer(request.TaskID, request.WorkflowID, request.WorkflowNodeTemplateID, plugin.Initialized, globalStateCopy, localStateManager, tm.store, exec.Plugin, exec.FSM)
+	activeTask := container.NewContainer(request.TaskID, request.WorkflowID, request.WorkflowNodeTemplateID, plugin.Initialized, globalStateCopy, localStateManager, tm.store, tm.events, exec.Plugin, exec.FSM)
+	activeTask.SetTransitionHook(tm.transitionHook(request.Type))
 
 	// Convert request.Config to json.RawMessage
 	configBytes, err := json.Marshal(request.Config)
@@ -225,7 +315,150 @@ func (tm *taskManager) InitTask(ctx context.Context, request InitTaskRequest) (*
 		"cacheSize", tm.containerCache.Len())
 
 	// Execute a task and return a result to Workflow Manager
-	return tm.start(ctx, activeTask)
+	resp, err := tm.start(ctx, activeTask)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tm.scheduleDeadline(ctx, activeTask, exec, request.Config); err != nil {
+		return nil, fmt.Errorf("failed to schedule deadline: %w", err)
+	}
+	return resp, nil
+}
+
+// scheduleDeadline schedules the task's deadline, if it has one, and records it in the local store.
+// A deadline already recorded for the task is reused so that retries keep the original deadline.
+func (tm *taskManager) scheduleDeadline(ctx context.Context, activeTask *container.Container, exec plugin.Executor, config json.RawMessage) error {
+	deadlineConfig, err := plugin.ResolveDeadline(exec.Plugin, config)
+	if err != nil || deadlineConfig == nil {
+		return err
+	}
+	if exec.FSM != nil && !exec.FSM.HasAction(deadlineConfig.Action) {
+		return fmt.Errorf("deadline action %q is not defined for task type of task %s", deadlineConfig.Action, activeTask.TaskID)
+	}
+	if tm.deadlineScheduler == nil {
+		slog.WarnContext(ctx, "deadline scheduler not configured, skipping deadline",
+			"taskID", activeTask.TaskID,
+			"action", deadlineConfig.Action)
+		return nil
+	}
+
+	record, err := readDeadlineRecord(activeTask)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		after, _ := time.ParseDuration(deadlineConfig.After) // validated by ResolveDeadline
+		record = &plugin.DeadlineRecord{
+			At:     time.Now().UTC().Add(after),
+			Action: deadlineConfig.Action,
+			Status: plugin.DeadlineStatusScheduled,
+		}
+		if err := activeTask.WriteToLocalStore(plugin.DeadlineLocalStoreKey, record); err != nil {
+			return fmt.Errorf("failed to record deadline: %w", err)
+		}
+	}
+
+	return tm.deadlineScheduler.ScheduleDeadline(ctx, plugin.Deadline{
+		TaskID:  activeTask.TaskID,
+		At:      record.At,
+		Action:  record.Action,
+		Outputs: deadlineConfig.Outputs,
+	})
+}
+
+// FireDeadline applies a task's deadline action. Deadlines of tasks that have already finished or
+// moved to a state where the action is not permitted are recorded as skipped. The record is marked
+// FIRING before the action is applied, so that a retry after a partial failure resumes notifying
+// the workflow instead of skipping a deadline that has already been applied.
+func (tm *taskManager) FireDeadline(ctx context.Context, deadline plugin.Deadline) error {
+	activeTask, err := tm.getTask(ctx, deadline.TaskID)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrTaskNotFound, deadline.TaskID, err)
+	}
+
+	record, err := readDeadlineRecord(activeTask)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		record = &plugin.DeadlineRecord{At: deadline.At, Action: deadline.Action}
+	}
+	if record.Status == plugin.DeadlineStatusFired || record.Status == plugin.DeadlineStatusSkipped {
+		return nil
+	}
+	resuming := record.Status == plugin.DeadlineStatusFiring
+
+	now := time.Now().UTC()
+	err = activeTask.Fire(container.WithSystemActor(ctx, "deadline"), deadline.Action, func() error {
+		record.Status = plugin.DeadlineStatusFiring
+		record.FiredAt = &now
+		if err := activeTask.WriteToLocalStore(plugin.DeadlineLocalStoreKey, record); err != nil {
+			return fmt.Errorf("failed to record firing deadline: %w", err)
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, container.ErrActionNotAllowed) && resuming:
+		slog.InfoContext(ctx, "deadline action already applied by an earlier attempt, resuming notification",
+			"taskID", deadline.TaskID,
+			"action", deadline.Action)
+	case errors.Is(err, container.ErrActionNotAllowed):
+		slog.InfoContext(ctx, "deadline passed but task no longer accepts its action, skipping",
+			"taskID", deadline.TaskID,
+			"action", deadline.Action,
+			"state", activeTask.GetTaskState(),
+			"pluginState", activeTask.GetPluginState())
+		record.Status = plugin.DeadlineStatusSkipped
+		record.FiredAt = &now
+		return activeTask.WriteToLocalStore(plugin.DeadlineLocalStoreKey, record)
+	case err != nil:
+		if record.Status == plugin.DeadlineStatusFiring {
+			// The action was not applied; forget the attempt so a retry is not mistaken for a resume.
+			record.Status = plugin.DeadlineStatusScheduled
+			record.FiredAt = nil
+			if resetErr := activeTask.WriteToLocalStore(plugin.DeadlineLocalStoreKey, record); resetErr != nil {
+				slog.ErrorContext(ctx, "failed to reset deadline record after failed attempt",
+					"taskID", deadline.TaskID,
+					"error", resetErr)
+			}
+		}
+		return fmt.Errorf("failed to apply deadline action %q to task %s: %w", deadline.Action, deadline.TaskID, err)
+	}
+
+	newState := activeTask.GetTaskState()
+	pluginState := activeTask.GetPluginState()
+	if newState == plugin.Completed || newState == plugin.Failed {
+		tm.notifyWorkflowDoneHandler(ctx, activeTask.WorkflowID, activeTask.TaskID, deadline.Outputs)
+	} else {
+		tm.notifyWorkflowUpdateHandler(ctx, activeTask.TaskID, &newState, &pluginState, deadline.Outputs, nil)
+	}
+
+	record.Status = plugin.DeadlineStatusFired
+	if err := activeTask.WriteToLocalStore(plugin.DeadlineLocalStoreKey, record); err != nil {
+		return fmt.Errorf("failed to record fired deadline: %w", err)
+	}
+	return nil
+}
+
+// readDeadlineRecord reads the deadline recorded in the task's local store, if any.
+func readDeadlineRecord(activeTask *container.Container) (*plugin.DeadlineRecord, error) {
+	value, err := activeTask.ReadFromLocalStore(plugin.DeadlineLocalStoreKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deadline record: %w", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deadline record: %w", err)
+	}
+	var record plugin.DeadlineRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, fmt.Errorf("failed to read deadline record: %w", err)
+	}
+	return &record, nil
 }
 
 func (tm *taskManager) start(ctx context.Context, activeTask *container.Container) (*InitTaskResponse, error) {
@@ -235,6 +468,12 @@ func (tm *taskManager) start(ctx context.Context, activeTask *container.Containe
 		return nil, fmt.Errorf("failed to start task: %w", err)
 	}
 
+	// A task that finishes as soon as it starts (e.g., one that issues a document) is reported done.
+	if result.NewState != nil && (*result.NewState == plugin.Completed || *result.NewState == plugin.Failed) {
+		tm.notifyWorkflowDoneHandler(ctx, activeTask.WorkflowID, activeTask.TaskID, result.Outputs)
+		return &InitTaskResponse{Success: true}, nil
+	}
+
 	// Notify the workflow manager of the initial state after starting the task (e.g., InProgress). This ensures that
 	//the workflow manager is aware of the task's state change immediately after initialization.
 	tm.notifyWorkflowUpdateHandler(ctx, activeTask.TaskID, result.NewState, result.ExtendedState, result.Outputs, result.EmittedOutcome)
@@ -328,7 +567,8 @@ func (tm *taskManager) getTask(ctx context.Context, taskID string) (*container.C
 	}
 
 	activeContainer := container.NewContainer(
-		execution.ID, execution.WorkflowID, execution.WorkflowNodeTemplateID, execution.State, globalContext, localState, tm.store, exec.Plugin, exec.FSM)
+		execution.ID, execution.WorkflowID, execution.WorkflowNodeTemplateID, execution.State, globalContext, localState, tm.store, tm.events, exec.Plugin, exec.FSM)
+	activeContainer.SetTransitionHook(tm.transitionHook(execution.Type))
 
 	// Cache the rebuilt container
 	tm.containerCache.Set(taskID, activeContainer)
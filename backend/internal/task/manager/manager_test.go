@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -13,6 +15,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"github.com/OpenNSW/nsw/internal/auth"
 	"github.com/OpenNSW/nsw/internal/task/container"
 	"github.com/OpenNSW/nsw/internal/task/persistence"
 	"github.com/OpenNSW/nsw/internal/task/plugin"
@@ -99,6 +102,36 @@ func (m *MockTaskStore) GetPluginState(id string) (string, error) {
 	return args.Get(0).(string), args.Error(1)
 }
 
+// UpdateTransition runs inTx with a nil transaction unless the expectation returns an error.
+func (m *MockTaskStore) UpdateTransition(id string, pluginState string, status *plugin.State, inTx func(tx *gorm.DB) error) error {
+	args := m.Called(id, pluginState, status)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	if inTx != nil {
+		return inTx(nil)
+	}
+	return nil
+}
+
+// MockTaskEventStore
+type MockTaskEventStore struct {
+	mock.Mock
+}
+
+func (m *MockTaskEventStore) AppendInTx(tx *gorm.DB, event *persistence.TaskEvent) error {
+	args := m.Called(tx, event)
+	return args.Error(0)
+}
+
+func (m *MockTaskEventStore) ListByTaskID(taskID string) ([]persistence.TaskEvent, error) {
+	args := m.Called(taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]persistence.TaskEvent), args.Error(1)
+}
+
 // MockPlugin
 type MockPlugin struct {
 	mock.Mock
@@ -165,7 +198,7 @@ func TestInitTask(t *testing.T) {
 		// Pre-populate cache
 		mockPlugin.On("Init", mock.Anything).Return().Once()
 
-		newContainer := container.NewContainer(taskID, uuid.NewString(), uuid.NewString(), plugin.InProgress, nil, nil, nil, mockPlugin, nil)
+		newContainer := container.NewContainer(taskID, uuid.NewString(), uuid.NewString(), plugin.InProgress, nil, nil, nil, nil, mockPlugin, nil)
 		tm.containerCache.Set(taskID, newContainer)
 
 		// Expect Start to be called on the *existing* container's plugin
@@ -215,6 +248,35 @@ func TestInitTask(t *testing.T) {
 		assert.True(t, result.Success)
 	})
 
+	t.Run("Completes On Start", func(t *testing.T) {
+		tm, _, _, mockPlugin := setupTest(t)
+		ctx := context.Background()
+		taskID := uuid.NewString()
+		workflowID := uuid.NewString()
+
+		var doneOutputs map[string]any
+		tm.workflowDoneHandler = func(_ context.Context, gotWorkflowID, gotTaskID string, outputs map[string]any) {
+			assert.Equal(t, workflowID, gotWorkflowID)
+			assert.Equal(t, taskID, gotTaskID)
+			doneOutputs = outputs
+		}
+		tm.workflowUpdateHandler = func(context.Context, string, *plugin.State, *string, map[string]any, *string) {
+			t.Error("a task completed on start must not be reported as updated")
+		}
+
+		mockPlugin.On("Init", mock.Anything).Return().Once()
+		tm.containerCache.Set(taskID, container.NewContainer(taskID, workflowID, uuid.NewString(), plugin.Initialized, nil, nil, nil, nil, mockPlugin, nil))
+
+		state := plugin.Completed
+		outputs := map[string]any{"document": map[string]any{"verificationCode": "ABCD-EFGH-JKMN"}}
+		mockPlugin.On("Start", ctx).Return(&plugin.ExecutionResponse{NewState: &state, Outputs: outputs}, nil).Once()
+
+		result, err := tm.InitTask(ctx, InitTaskRequest{TaskID: taskID, WorkflowID: workflowID})
+		assert.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, outputs, doneOutputs)
+	})
+
 	t.Run("BuildExecutor Error", func(t *testing.T) {
 		tm, mockFactory, _, _ := setupTest(t)
 		ctx := context.Background()
@@ -365,7 +427,7 @@ func TestExecuteTask(t *testing.T) {
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
-		assert.Contains(t, err.Error(), "task_id is required")
+		assert.ErrorIs(t, err, ErrTaskIDRequired)
 	})
 }
 
@@ -409,7 +471,7 @@ func TestGetTask_CacheRebuild(t *testing.T) {
 		mockPlugin.On("Init", mock.Anything).Return().Once()
 
 		// Pre-populate cache
-		newContainer := container.NewContainer(taskID, uuid.NewString(), uuid.NewString(), plugin.InProgress, nil, nil, nil, mockPlugin, nil)
+		newContainer := container.NewContainer(taskID, uuid.NewString(), uuid.NewString(), plugin.InProgress, nil, nil, nil, nil, mockPlugin, nil)
 		tm.containerCache.Set(taskID, newContainer)
 
 		// Act
@@ -537,3 +599,258 @@ func TestContainerCache(t *testing.T) {
 		assert.Equal(t, 0, cache.Len())
 	})
 }
+
+// fakeDeadlineScheduler records scheduled deadlines.
+type fakeDeadlineScheduler struct {
+	scheduled []plugin.Deadline
+}
+
+func (s *fakeDeadlineScheduler) ScheduleDeadline(_ context.Context, deadline plugin.Deadline) error {
+	s.scheduled = append(s.scheduled, deadline)
+	return nil
+}
+
+func TestInitTask_SchedulesDeadline(t *testing.T) {
+	tm, mockFactory, mockStore, mockPlugin := setupTest(t)
+	scheduler := &fakeDeadlineScheduler{}
+	tm.RegisterDeadlineScheduler(scheduler)
+
+	ctx := context.Background()
+	req := InitTaskRequest{
+		TaskID:     uuid.NewString(),
+		WorkflowID: uuid.NewString(),
+		Type:       plugin.TaskTypeWaitForEvent,
+		Config:     json.RawMessage(`{"deadline": {"after": "72h", "action": "DEADLINE_ESCALATE"}}`),
+	}
+
+	mockFactory.On("BuildExecutor", ctx, req.Type, req.Config).Return(plugin.Executor{Plugin: mockPlugin, FSM: plugin.NewWaitForEventFSM()}, nil).Once()
+	mockStore.On("GetLocalState", req.TaskID).Return(json.RawMessage(`{}`), nil).Once()
+	mockStore.On("GetPluginState", req.TaskID).Return("", nil).Once()
+	mockStore.On("Create", mock.AnythingOfType("*persistence.TaskInfo")).Return(nil).Once()
+	mockStore.On("UpdateLocalState", req.TaskID, mock.Anything).Return(nil).Once()
+	mockPlugin.On("Init", mock.Anything).Return().Once()
+	mockPlugin.On("Start", ctx).Return(&plugin.ExecutionResponse{}, nil).Once()
+
+	before := time.Now().UTC()
+	_, err := tm.InitTask(ctx, req)
+	assert.NoError(t, err)
+
+	if assert.Len(t, scheduler.scheduled, 1) {
+		deadline := scheduler.scheduled[0]
+		assert.Equal(t, req.TaskID, deadline.TaskID)
+		assert.Equal(t, plugin.DeadlineActionEscalate, deadline.Action)
+		assert.WithinDuration(t, before.Add(72*time.Hour), deadline.At, time.Minute)
+	}
+	mockStore.AssertExpectations(t)
+}
+
+func TestInitTask_RejectsUnknownDeadlineAction(t *testing.T) {
+	tm, mockFactory, mockStore, mockPlugin := setupTest(t)
+	tm.RegisterDeadlineScheduler(&fakeDeadlineScheduler{})
+
+	ctx := context.Background()
+	req := InitTaskRequest{
+		TaskID: uuid.NewString(),
+		Type:   plugin.TaskTypeWaitForEvent,
+		Config: json.RawMessage(`{"deadline": {"after": "1h", "action": "NOT_AN_ACTION"}}`),
+	}
+
+	mockFactory.On("BuildExecutor", ctx, req.Type, req.Config).Return(plugin.Executor{Plugin: mockPlugin, FSM: plugin.NewWaitForEventFSM()}, nil).Once()
+	mockStore.On("GetLocalState", req.TaskID).Return(json.RawMessage(`{}`), nil).Once()
+	mockStore.On("GetPluginState", req.TaskID).Return("", nil).Once()
+	mockStore.On("Create", mock.AnythingOfType("*persistence.TaskInfo")).Return(nil).Once()
+	mockPlugin.On("Init", mock.Anything).Return().Once()
+	mockPlugin.On("Start", ctx).Return(&plugin.ExecutionResponse{}, nil).Once()
+
+	_, err := tm.InitTask(ctx, req)
+	assert.ErrorContains(t, err, `deadline action "NOT_AN_ACTION" is not defined`)
+}
+
+func TestFireDeadline(t *testing.T) {
+	setupTask := func(t *testing.T, pluginState string, taskState plugin.State, localState string) (*taskManager, *MockTaskStore, plugin.Deadline) {
+		tm, mockFactory, mockStore, mockPlugin := setupTest(t)
+		taskID := uuid.NewString()
+		taskInfo := &persistence.TaskInfo{
+			ID:         taskID,
+			WorkflowID: "wf-1",
+			Type:       plugin.TaskTypeWaitForEvent,
+			State:      taskState,
+			Config:     json.RawMessage(`{}`),
+			LocalState: json.RawMessage(localState),
+		}
+		mockStore.On("GetByID", taskID).Return(taskInfo, nil).Once()
+		mockFactory.On("BuildExecutor", mock.Anything, taskInfo.Type, taskInfo.Config).Return(plugin.Executor{Plugin: mockPlugin, FSM: plugin.NewWaitForEventFSM()}, nil).Once()
+		mockStore.On("GetPluginState", taskID).Return(pluginState, nil).Once()
+		mockPlugin.On("Init", mock.Anything).Return().Once()
+
+		return tm, mockStore, plugin.Deadline{
+			TaskID:  taskID,
+			At:      time.Now().UTC(),
+			Action:  plugin.DeadlineActionTimeout,
+			Outputs: map[string]any{"timed_out": true},
+		}
+	}
+
+	t.Run("Applies action and notifies workflow", func(t *testing.T) {
+		tm, mockStore, deadline := setupTask(t, "NOTIFIED_SERVICE", plugin.InProgress, `{}`)
+		mockEvents := new(MockTaskEventStore)
+		tm.events = mockEvents
+		mockEvents.On("AppendInTx", (*gorm.DB)(nil), mock.MatchedBy(func(e *persistence.TaskEvent) bool {
+			return e.TaskID == deadline.TaskID && e.Action == plugin.DeadlineActionTimeout &&
+				e.FromPluginState == "NOTIFIED_SERVICE" && e.ToPluginState == "TIMED_OUT" &&
+				e.ActorType == persistence.ActorTypeSystem && e.ActorID == "deadline" && e.PayloadHash == ""
+		})).Return(nil).Once()
+		failed := plugin.Failed
+		mockStore.On("UpdateTransition", deadline.TaskID, "TIMED_OUT", &failed).Return(nil).Once()
+		mockStore.On("UpdateLocalState", deadline.TaskID, mock.MatchedBy(func(raw json.RawMessage) bool {
+			return strings.Contains(string(raw), plugin.DeadlineStatusFiring)
+		})).Return(nil).Once()
+		mockStore.On("UpdateLocalState", deadline.TaskID, mock.MatchedBy(func(raw json.RawMessage) bool {
+			return strings.Contains(string(raw), plugin.DeadlineStatusFired)
+		})).Return(nil).Once()
+
+		var doneOutputs map[string]any
+		tm.RegisterUpstreamDoneCallback(func(_ context.Context, workflowID, taskID string, outputs map[string]any) {
+			doneOutputs = outputs
+		})
+
+		err := tm.FireDeadline(context.Background(), deadline)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"timed_out": true}, doneOutputs)
+		mockStore.AssertExpectations(t)
+		mockEvents.AssertExpectations(t)
+	})
+
+	t.Run("Resumes notification when an earlier attempt applied the action", func(t *testing.T) {
+		tm, mockStore, deadline := setupTask(t, "TIMED_OUT", plugin.Failed,
+			`{"deadline": {"action": "DEADLINE_TIMEOUT", "status": "FIRING"}}`)
+		mockStore.On("UpdateLocalState", deadline.TaskID, mock.MatchedBy(func(raw json.RawMessage) bool {
+			return strings.Contains(string(raw), plugin.DeadlineStatusFired)
+		})).Return(nil).Once()
+
+		var doneOutputs map[string]any
+		tm.RegisterUpstreamDoneCallback(func(_ context.Context, workflowID, taskID string, outputs map[string]any) {
+			doneOutputs = outputs
+		})
+
+		err := tm.FireDeadline(context.Background(), deadline)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"timed_out": true}, doneOutputs)
+		mockStore.AssertNotCalled(t, "UpdateTransition", mock.Anything, mock.Anything, mock.Anything)
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("Skips when task has moved on", func(t *testing.T) {
+		tm, mockStore, deadline := setupTask(t, "RECEIVED_CALLBACK", plugin.Completed, `{}`)
+		mockStore.On("UpdateLocalState", deadline.TaskID, mock.MatchedBy(func(raw json.RawMessage) bool {
+			return strings.Contains(string(raw), plugin.DeadlineStatusSkipped)
+		})).Return(nil).Once()
+
+		doneCalled := false
+		tm.RegisterUpstreamDoneCallback(func(context.Context, string, string, map[string]any) {
+			doneCalled = true
+		})
+
+		err := tm.FireDeadline(context.Background(), deadline)
+		assert.NoError(t, err)
+		assert.False(t, doneCalled)
+		mockStore.AssertNotCalled(t, "UpdateTransition", mock.Anything, mock.Anything, mock.Anything)
+		mockStore.AssertExpectations(t)
+	})
+}
+
+func TestExecuteTask_RecordsAuditEvent(t *testing.T) {
+	tm, _, mockStore, mockPlugin := setupTest(t)
+	mockEvents := new(MockTaskEventStore)
+	tm.events = mockEvents
+
+	taskID := uuid.NewString()
+	var api plugin.API
+	mockStore.On("GetPluginState", taskID).Return("NOTIFIED_SERVICE", nil).Once()
+	mockPlugin.On("Init", mock.Anything).Run(func(args mock.Arguments) {
+		api = args.Get(0).(plugin.API)
+	}).Return().Once()
+	activeTask := container.NewContainer(taskID, "wf-1", "", plugin.InProgress, nil, nil, mockStore, tm.events, mockPlugin, plugin.NewWaitForEventFSM())
+	tm.containerCache.Set(taskID, activeTask)
+
+	payload := &plugin.ExecutionRequest{Action: "OGA_VERIFICATION", Content: map[string]any{"decision": "APPROVED"}}
+	completed := plugin.Completed
+	mockPlugin.On("Execute", mock.Anything, payload).Run(func(mock.Arguments) {
+		assert.NoError(t, api.Transition("OGA_VERIFICATION"))
+	}).Return(&plugin.ExecutionResponse{}, nil).Once()
+	mockStore.On("UpdateTransition", taskID, "RECEIVED_CALLBACK", &completed).Return(nil).Once()
+
+	var recorded *persistence.TaskEvent
+	mockEvents.On("AppendInTx", (*gorm.DB)(nil), mock.AnythingOfType("*persistence.TaskEvent")).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*persistence.TaskEvent)
+	}).Return(nil).Once()
+
+	ctx := context.WithValue(context.Background(), auth.AuthContextKey, &auth.AuthContext{
+		Client: &auth.ClientContext{ClientID: "npqs-oga"},
+	})
+	_, err := tm.ExecuteTask(ctx, ExecuteTaskRequest{TaskID: taskID, Payload: payload})
+	assert.NoError(t, err)
+
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, "wf-1", recorded.WorkflowID)
+		assert.Equal(t, "OGA_VERIFICATION", recorded.Action)
+		assert.Equal(t, "NOTIFIED_SERVICE", recorded.FromPluginState)
+		assert.Equal(t, "RECEIVED_CALLBACK", recorded.ToPluginState)
+		assert.Equal(t, persistence.ActorTypeClient, recorded.ActorType)
+		assert.Equal(t, "npqs-oga", recorded.ActorID)
+		assert.Len(t, recorded.PayloadHash, 64)
+	}
+	mockStore.AssertExpectations(t)
+}
+
+func TestTransition_FailsWhenAuditEventIsNotRecorded(t *testing.T) {
+	_, _, mockStore, mockPlugin := setupTest(t)
+	mockEvents := new(MockTaskEventStore)
+
+	taskID := uuid.NewString()
+	var api plugin.API
+	mockStore.On("GetPluginState", taskID).Return("NOTIFIED_SERVICE", nil).Once()
+	mockPlugin.On("Init", mock.Anything).Run(func(args mock.Arguments) {
+		api = args.Get(0).(plugin.API)
+	}).Return().Once()
+	activeTask := container.NewContainer(taskID, "wf-1", "", plugin.InProgress, nil, nil, mockStore, mockEvents, mockPlugin, plugin.NewWaitForEventFSM())
+
+	completed := plugin.Completed
+	mockStore.On("UpdateTransition", taskID, "RECEIVED_CALLBACK", &completed).Return(nil).Once()
+	mockEvents.On("AppendInTx", (*gorm.DB)(nil), mock.Anything).Return(errors.New("insert failed")).Once()
+
+	err := api.Transition("OGA_VERIFICATION")
+	assert.ErrorContains(t, err, "failed to record task event")
+	assert.Equal(t, "NOTIFIED_SERVICE", activeTask.GetPluginState())
+	assert.Equal(t, plugin.InProgress, activeTask.GetTaskState())
+	mockEvents.AssertExpectations(t)
+}
+
+func TestGetTaskHistory(t *testing.T) {
+	t.Run("Returns events", func(t *testing.T) {
+		tm, _, mockStore, _ := setupTest(t)
+		mockEvents := new(MockTaskEventStore)
+		tm.events = mockEvents
+		taskID := uuid.NewString()
+		events := []persistence.TaskEvent{
+			{TaskID: taskID, Action: "START", ToPluginState: "NOTIFIED_SERVICE", ActorType: persistence.ActorTypeSystem, ActorID: "workflow"},
+			{TaskID: taskID, Action: "OGA_VERIFICATION", FromPluginState: "NOTIFIED_SERVICE", ToPluginState: "RECEIVED_CALLBACK", ActorType: persistence.ActorTypeClient, ActorID: "npqs-oga"},
+		}
+		mockStore.On("GetByID", taskID).Return(&persistence.TaskInfo{ID: taskID}, nil).Once()
+		mockEvents.On("ListByTaskID", taskID).Return(events, nil).Once()
+
+		result, err := tm.GetTaskHistory(context.Background(), taskID)
+		assert.NoError(t, err)
+		assert.Equal(t, events, result)
+	})
+
+	t.Run("Task not found", func(t *testing.T) {
+		tm, _, mockStore, _ := setupTest(t)
+		taskID := uuid.NewString()
+		mockStore.On("GetByID", taskID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := tm.GetTaskHistory(context.Background(), taskID)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+		assert.ErrorContains(t, err, taskID)
+	})
+}
@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+	"github.com/OpenNSW/nsw/internal/task/container"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+	"github.com/shopspring/decimal"
+)
+
+// PaymentOutcomeHandler returns a paymentsv2.OutcomeHandler that advances the PAYMENT task a
+// transaction belongs to with PAYMENT_SUCCESS, PAYMENT_FAILED, PAYMENT_PARTIAL or PAYMENT_REFUNDED.
+// The gateway's amount, currency and reference are passed along so the task can reconcile them
+// against its session.
+func PaymentOutcomeHandler(tm TaskManager) paymentsv2.OutcomeHandler {
+	return func(ctx context.Context, tx paymentsv2.PaymentTransaction) error {
+		var action string
+		switch tx.Status {
+		case paymentsv2.PaymentStatusSuccess:
+			action = plugin.PaymentActionSuccess
+		case paymentsv2.PaymentStatusFailed:
+			action = plugin.PaymentActionFailed
+		case paymentsv2.PaymentStatusPartiallyPaid:
+			action = plugin.PaymentActionPartial
+		case paymentsv2.PaymentStatusRefunded:
+			action = plugin.PaymentActionRefunded
+		default:
+			return fmt.Errorf("payment %s has no task outcome for status %s", tx.ReferenceNumber, tx.Status)
+		}
+
+		_, err := tm.ExecuteTask(container.WithSystemActor(ctx, "payment"), ExecuteTaskRequest{
+			TaskID: tx.TaskID,
+			Payload: &plugin.ExecutionRequest{
+				Action: action,
+				Content: plugin.PaymentGatewayResult{
+					ReferenceNumber: tx.ReferenceNumber,
+					Amount:          tx.Amount,
+					Currency:        tx.Currency,
+				},
+			},
+		})
+		return err
+	}
+}
+
+// PaymentRefundRequester returns a function that refunds the payment of a PAYMENT task by
+// executing REQUEST_REFUND on it, so that the task records the refund it is waiting for.
+// A zero amount refunds everything paid.
+func PaymentRefundRequester(tm TaskManager) func(ctx context.Context, taskID string, amount decimal.Decimal, reason string) error {
+	return func(ctx context.Context, taskID string, amount decimal.Decimal, reason string) error {
+		_, err := tm.ExecuteTask(container.WithSystemActor(ctx, "refund"), ExecuteTaskRequest{
+			TaskID: taskID,
+			Payload: &plugin.ExecutionRequest{
+				Action:  plugin.PaymentActionRequestRefund,
+				Content: plugin.PaymentRefundRequest{Amount: amount, Reason: reason},
+			},
+		})
+		return err
+	}
+}
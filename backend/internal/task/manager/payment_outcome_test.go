@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+	"github.com/OpenNSW/nsw/internal/task/container"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
+)
+
+// executingTaskManager records every ExecuteTask call and the actor it ran as.
+type executingTaskManager struct {
+	TaskManager
+	requests []ExecuteTaskRequest
+	actors   []container.Actor
+	err      error
+}
+
+func (m *executingTaskManager) ExecuteTask(ctx context.Context, req ExecuteTaskRequest) (*plugin.ExecutionResponse, error) {
+	m.requests = append(m.requests, req)
+	m.actors = append(m.actors, container.ActorFromContext(ctx))
+	return &plugin.ExecutionResponse{}, m.err
+}
+
+func TestPaymentOutcomeHandler(t *testing.T) {
+	tx := paymentsv2.PaymentTransaction{
+		ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+		TaskID:          "task-1",
+		Amount:          decimal.NewFromInt(1500),
+		Currency:        "LKR",
+	}
+
+	t.Run("maps settled statuses to payment actions", func(t *testing.T) {
+		tm := &executingTaskManager{}
+		handle := PaymentOutcomeHandler(tm)
+
+		tx.Status = paymentsv2.PaymentStatusSuccess
+		assert.NoError(t, handle(context.Background(), tx))
+		tx.Status = paymentsv2.PaymentStatusFailed
+		assert.NoError(t, handle(context.Background(), tx))
+		tx.Status = paymentsv2.PaymentStatusPartiallyPaid
+		assert.NoError(t, handle(context.Background(), tx))
+		tx.Status = paymentsv2.PaymentStatusRefunded
+		assert.NoError(t, handle(context.Background(), tx))
+
+		if assert.Len(t, tm.requests, 4) {
+			assert.Equal(t, "task-1", tm.requests[0].TaskID)
+			assert.Equal(t, plugin.PaymentActionSuccess, tm.requests[0].Payload.Action)
+			assert.Equal(t, plugin.PaymentGatewayResult{
+				ReferenceNumber: "NSW-PR-2026-ABCDEFGH", Amount: decimal.NewFromInt(1500), Currency: "LKR",
+			}, tm.requests[0].Payload.Content)
+			assert.Equal(t, plugin.PaymentActionFailed, tm.requests[1].Payload.Action)
+			assert.Equal(t, container.Actor{Type: persistence.ActorTypeSystem, ID: "payment"}, tm.actors[0])
+			assert.Equal(t, plugin.PaymentActionPartial, tm.requests[2].Payload.Action)
+			assert.Equal(t, plugin.PaymentActionRefunded, tm.requests[3].Payload.Action)
+		}
+	})
+
+	t.Run("returns execution errors", func(t *testing.T) {
+		tm := &executingTaskManager{err: errors.New("task busy")}
+		tx.Status = paymentsv2.PaymentStatusSuccess
+
+		assert.ErrorContains(t, PaymentOutcomeHandler(tm)(context.Background(), tx), "task busy")
+	})
+
+	t.Run("rejects unsettled payments", func(t *testing.T) {
+		tm := &executingTaskManager{}
+		tx.Status = paymentsv2.PaymentStatusPending
+
+		assert.Error(t, PaymentOutcomeHandler(tm)(context.Background(), tx))
+		assert.Empty(t, tm.requests)
+	})
+}
+
+func TestPaymentRefundRequester(t *testing.T) {
+	tm := &executingTaskManager{}
+
+	err := PaymentRefundRequester(tm)(context.Background(), "task-1", decimal.NewFromInt(500), "application rejected")
+	assert.NoError(t, err)
+	if assert.Len(t, tm.requests, 1) {
+		assert.Equal(t, "task-1", tm.requests[0].TaskID)
+		assert.Equal(t, plugin.PaymentActionRequestRefund, tm.requests[0].Payload.Action)
+		assert.Equal(t, plugin.PaymentRefundRequest{Amount: decimal.NewFromInt(500), Reason: "application rejected"}, tm.requests[0].Payload.Content)
+		assert.Equal(t, container.Actor{Type: persistence.ActorTypeSystem, ID: "refund"}, tm.actors[0])
+	}
+}
@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ActorType classifies the principal that caused a task event.
+type ActorType string
+
+const (
+	ActorTypeUser   ActorType = "USER"   // Authenticated user, e.g. a trader or OGA officer
+	ActorTypeClient ActorType = "CLIENT" // Authenticated M2M client, e.g. an OGA callback
+	ActorTypeSystem ActorType = "SYSTEM" // Internal caller such as the workflow runtime or a deadline
+)
+
+// TaskEvent is an append-only audit record of a single task FSM transition.
+type TaskEvent struct {
+	ID              string    `gorm:"type:varchar(100);column:id;not null;primaryKey" json:"id"`
+	TaskID          string    `gorm:"type:text;column:task_id;not null;index" json:"taskId"`
+	WorkflowID      string    `gorm:"type:text;column:workflow_id;not null" json:"workflowId"`
+	Action          string    `gorm:"type:varchar(100);column:action;not null" json:"action"`
+	FromPluginState string    `gorm:"type:varchar(100);column:from_plugin_state;not null" json:"fromPluginState"`
+	ToPluginState   string    `gorm:"type:varchar(100);column:to_plugin_state;not null" json:"toPluginState"`
+	ActorType       ActorType `gorm:"type:varchar(20);column:actor_type;not null" json:"actorType"`
+	ActorID         string    `gorm:"type:varchar(255);column:actor_id;not null" json:"actorId"`
+	PayloadHash     string    `gorm:"type:varchar(64);column:payload_hash;not null" json:"payloadHash"` // Hex SHA-256 of the triggering request, empty if none
+	CreatedAt       time.Time `gorm:"type:timestamptz;column:created_at;not null" json:"createdAt"`
+}
+
+// TableName returns the table name for TaskEvent
+func (TaskEvent) TableName() string {
+	return "task_events"
+}
+
+// TaskEventStoreInterface appends and lists task events. It deliberately has no update or delete.
+type TaskEventStoreInterface interface {
+	AppendInTx(*gorm.DB, *TaskEvent) error
+	ListByTaskID(string) ([]TaskEvent, error)
+}
+
+// TaskEventStore handles database operations for task events
+type TaskEventStore struct {
+	db *gorm.DB
+}
+
+// NewTaskEventStore creates a new TaskEventStore with the provided database connection
+func NewTaskEventStore(db *gorm.DB) (*TaskEventStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection cannot be nil")
+	}
+
+	return &TaskEventStore{db: db}, nil
+}
+
+// AppendInTx inserts a task event within tx, so that it commits or rolls back with the
+// transition it records. It assigns the event's ID and timestamp if unset.
+func (s *TaskEventStore) AppendInTx(tx *gorm.DB, event *TaskEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	return tx.Create(event).Error
+}
+
+// ListByTaskID retrieves the events of a task in the order they occurred
+func (s *TaskEventStore) ListByTaskID(taskID string) ([]TaskEvent, error) {
+	var events []TaskEvent
+	if err := s.db.Where("task_id = ?", taskID).Order("created_at ASC, id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
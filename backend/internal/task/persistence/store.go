@@ -47,6 +47,7 @@ type TaskStoreInterface interface {
 	GetLocalState(string) (json.RawMessage, error)
 	UpdatePluginState(string, string) error
 	GetPluginState(string) (string, error)
+	UpdateTransition(string, string, *plugin.State, func(tx *gorm.DB) error) error
 }
 
 // NewTaskStore creates a new TaskStore with the provided database connection
@@ -124,6 +125,25 @@ func (s *TaskStore) UpdatePluginState(id string, pluginState string) error {
 	return s.db.Model(&TaskInfo{}).Where("id = ?", id).Update("plugin_state", pluginState).Error
 }
 
+// UpdateTransition persists the outcome of an FSM transition in one transaction: the plugin
+// state, the task state unless status is nil, and whatever inTx writes, such as the audit event.
+// inTx may be nil.
+func (s *TaskStore) UpdateTransition(id string, pluginState string, status *plugin.State, inTx func(tx *gorm.DB) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{"plugin_state": pluginState}
+		if status != nil {
+			updates["state"] = *status
+		}
+		if err := tx.Model(&TaskInfo{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		if inTx != nil {
+			return inTx(tx)
+		}
+		return nil
+	})
+}
+
 // GetPluginState retrieves the plugin state of a task execution
 func (s *TaskStore) GetPluginState(id string) (string, error) {
 	var taskInfo TaskInfo
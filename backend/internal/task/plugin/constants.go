@@ -4,9 +4,12 @@ package plugin
 type Type string
 
 const (
-	TaskTypeSimpleForm   Type = "SIMPLE_FORM"
-	TaskTypeWaitForEvent Type = "WAIT_FOR_EVENT"
-	TaskTypePayment      Type = "PAYMENT"
+	TaskTypeSimpleForm    Type = "SIMPLE_FORM"
+	TaskTypeWaitForEvent  Type = "WAIT_FOR_EVENT"
+	TaskTypePayment       Type = "PAYMENT"
+	TaskTypeTimer         Type = "TIMER"
+	TaskTypeMultiApproval Type = "MULTI_APPROVAL"
+	TaskTypeDocument      Type = "DOCUMENT"
 )
 
 type State string
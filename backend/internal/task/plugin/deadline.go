@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Deadline actions understood by the built-in plugin FSMs. A node's deadline may also
+// fire any other action its plugin FSM defines, e.g. an approval to auto-approve.
+const (
+	DeadlineActionTimeout  = "DEADLINE_TIMEOUT"
+	DeadlineActionEscalate = "DEADLINE_ESCALATE"
+)
+
+// DeadlineLocalStoreKey is the local store key under which a task's deadline is recorded.
+const DeadlineLocalStoreKey = "deadline"
+
+// Deadline record statuses stored under DeadlineLocalStoreKey.
+const (
+	DeadlineStatusScheduled = "SCHEDULED"
+	DeadlineStatusFiring    = "FIRING" // action accepted, workflow not yet notified
+	DeadlineStatusFired     = "FIRED"
+	DeadlineStatusSkipped   = "SKIPPED"
+)
+
+// DeadlineConfig is the per-node deadline configuration, read from the "deadline" key of any task config.
+//
+// Example:
+//
+//	"deadline": { "after": "72h", "action": "DEADLINE_ESCALATE" }
+type DeadlineConfig struct {
+	After   string         `json:"after"`             // Go duration measured from task start, e.g. "72h"
+	Action  string         `json:"action"`            // FSM action fired when the deadline passes
+	Outputs map[string]any `json:"outputs,omitempty"` // Outputs reported to the workflow when the action finishes the task
+}
+
+// Validate checks that the deadline has a positive duration and an action.
+func (c DeadlineConfig) Validate() error {
+	d, err := time.ParseDuration(c.After)
+	if err != nil {
+		return fmt.Errorf("deadline: invalid duration %q: %w", c.After, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("deadline: duration must be positive, got %q", c.After)
+	}
+	if c.Action == "" {
+		return fmt.Errorf("deadline: action is required")
+	}
+	return nil
+}
+
+// Deadline is a DeadlineConfig resolved to an absolute time for a specific task.
+type Deadline struct {
+	TaskID  string         `json:"taskId"`
+	At      time.Time      `json:"at"`
+	Action  string         `json:"action"`
+	Outputs map[string]any `json:"outputs,omitempty"`
+}
+
+// DeadlineRecord is the deadline bookkeeping written to the task's local store.
+type DeadlineRecord struct {
+	At      time.Time  `json:"at"`
+	Action  string     `json:"action"`
+	Status  string     `json:"status"`
+	FiredAt *time.Time `json:"firedAt,omitempty"`
+}
+
+// DeadlineProvider is implemented by plugins that define their own deadline instead of
+// relying on the generic "deadline" config key, e.g. TIMER.
+type DeadlineProvider interface {
+	DeadlineConfig() *DeadlineConfig
+}
+
+// ParseDeadlineConfig reads the generic "deadline" key from a raw task config.
+// Returns nil if the config has no deadline.
+func ParseDeadlineConfig(config json.RawMessage) (*DeadlineConfig, error) {
+	if len(config) == 0 {
+		return nil, nil
+	}
+	var wrapper struct {
+		Deadline *DeadlineConfig `json:"deadline"`
+	}
+	if err := json.Unmarshal(config, &wrapper); err != nil {
+		return nil, fmt.Errorf("deadline: failed to parse task config: %w", err)
+	}
+	if wrapper.Deadline == nil {
+		return nil, nil
+	}
+	if err := wrapper.Deadline.Validate(); err != nil {
+		return nil, err
+	}
+	return wrapper.Deadline, nil
+}
+
+// ResolveDeadline returns the deadline configuration for a task, preferring the plugin's own
+// deadline over the generic "deadline" config key. Returns nil if the task has no deadline.
+func ResolveDeadline(p Plugin, config json.RawMessage) (*DeadlineConfig, error) {
+	if provider, ok := p.(DeadlineProvider); ok {
+		if cfg := provider.DeadlineConfig(); cfg != nil {
+			if err := cfg.Validate(); err != nil {
+				return nil, err
+			}
+			return cfg, nil
+		}
+	}
+	return ParseDeadlineConfig(config)
+}
@@ -0,0 +1,236 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/OpenNSW/nsw/internal/document"
+)
+
+// ── Plugin States ─────────────────────────────────────────────────────────────
+
+type documentState string
+
+const documentIssued documentState = "ISSUED"
+
+// documentStoreIssued is the local store key of the issued document record.
+const documentStoreIssued = "document:issued"
+
+// defaultDocumentOutputKey is the workflow output key used when the config does not name one.
+const defaultDocumentOutputKey = "document"
+
+// ── Config ────────────────────────────────────────────────────────────────────
+
+// DocumentConfig represents the configuration for a DOCUMENT task. The title, subtitle, field
+// values, paragraphs and consignment may contain {path} or {path:default} placeholders,
+// resolved from the workflow's global store like the values of payment breakdown items.
+//
+// Example:
+//
+//	{
+//	  "documentType": "RECEIPT",
+//	  "title": "Payment Receipt",
+//	  "issuingOga": "CUSTOMS",
+//	  "fields": [
+//	    { "label": "Reference", "value": "{payment.referenceNumber}" },
+//	    { "label": "Amount", "value": "{payment.currency} {payment.amount}" }
+//	  ],
+//	  "outputKey": "receipt"
+//	}
+type DocumentConfig struct {
+	DocumentType string           `json:"documentType"`
+	Title        string           `json:"title"`
+	Subtitle     string           `json:"subtitle,omitempty"`
+	Fields       []document.Field `json:"fields,omitempty"`
+	Paragraphs   []string         `json:"paragraphs,omitempty"`
+	OutputKey    string           `json:"outputKey,omitempty"` // Workflow output key of the issued document; defaults to "document"
+
+	// Registry details, reported when the document is verified.
+	IssuingOGA    string `json:"issuingOga,omitempty"`    // Agency on whose behalf the document is issued
+	ValidFor      string `json:"validFor,omitempty"`      // Go duration from issue; the document does not expire if empty
+	ConsignmentID string `json:"consignmentId,omitempty"` // Defaults to the workflow ID, which is the consignment ID for consignment workflows
+}
+
+// ── FSM ───────────────────────────────────────────────────────────────────────
+
+// NewDocumentFSM returns the state graph for DocumentTask. The document is issued as the task
+// starts, so the task completes straight away.
+//
+// State graph:
+//
+//	"" ──START──► ISSUED [COMPLETED]
+func NewDocumentFSM() *PluginFSM {
+	return NewPluginFSM(map[TransitionKey]TransitionOutcome{
+		{"", FSMActionStart}: {string(documentIssued), Completed},
+	})
+}
+
+// ── Plugin ────────────────────────────────────────────────────────────────────
+
+// documentRegistration registers the DOCUMENT plugin type.
+var documentRegistration = Registration{
+	Type: TaskTypeDocument,
+	New: func(config json.RawMessage, deps Dependencies) (Plugin, error) {
+		p, err := NewDocumentTask(config, deps.DocumentIssuer)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	},
+	NewFSM:   NewDocumentFSM,
+	Requires: []Dependency{DependencyDocumentIssuer},
+}
+
+// DocumentTask implements Plugin for the DOCUMENT task type. It issues a PDF, such as a receipt
+// or a certificate, and reports where it is stored and how to verify it to the workflow.
+type DocumentTask struct {
+	api      API
+	config   DocumentConfig
+	issuer   document.Issuer
+	validFor time.Duration
+}
+
+// NewDocumentTask creates a DocumentTask from the raw JSON configuration.
+func NewDocumentTask(raw json.RawMessage, issuer document.Issuer) (*DocumentTask, error) {
+	var cfg DocumentConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("document: invalid config: %w", err)
+	}
+	if cfg.DocumentType == "" || cfg.Title == "" {
+		return nil, fmt.Errorf("document: documentType and title are required")
+	}
+	if cfg.OutputKey == "" {
+		cfg.OutputKey = defaultDocumentOutputKey
+	}
+	t := &DocumentTask{config: cfg, issuer: issuer}
+	if cfg.ValidFor != "" {
+		validFor, err := time.ParseDuration(cfg.ValidFor)
+		if err != nil || validFor <= 0 {
+			return nil, fmt.Errorf("document: validFor must be a positive duration, got %q", cfg.ValidFor)
+		}
+		t.validFor = validFor
+	}
+	return t, nil
+}
+
+func (t *DocumentTask) Init(api API) {
+	t.api = api
+}
+
+// Start issues the document. A document issued by an earlier attempt that failed to complete
+// the task is reused rather than issued twice.
+func (t *DocumentTask) Start(ctx context.Context) (*ExecutionResponse, error) {
+	if !t.api.CanTransition(FSMActionStart) {
+		return &ExecutionResponse{Message: "Document already issued"}, nil
+	}
+
+	issued, err := t.readIssued()
+	if err != nil {
+		return nil, err
+	}
+	if issued == nil {
+		consignmentID := resolvePlaceholders(t.api, t.config.ConsignmentID)
+		if consignmentID == "" {
+			consignmentID = t.api.GetWorkflowID()
+		}
+		issued, err = t.issuer.Issue(ctx, document.IssueRequest{
+			ConsignmentID: consignmentID,
+			TaskID:        t.api.GetTaskID(),
+			WorkflowID:    t.api.GetWorkflowID(),
+			IssuingOGA:    t.config.IssuingOGA,
+			ValidFor:      t.validFor,
+			Content:       t.content(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("document: failed to issue %s: %w", t.config.DocumentType, err)
+		}
+		if err := t.api.WriteToLocalStore(documentStoreIssued, *issued); err != nil {
+			return nil, fmt.Errorf("document: failed to record issued document: %w", err)
+		}
+	}
+
+	if err := t.api.Transition(FSMActionStart); err != nil {
+		return nil, err
+	}
+	return &ExecutionResponse{
+		Message: fmt.Sprintf("%s issued", t.config.Title),
+		Outputs: map[string]any{
+			t.config.OutputKey: map[string]any{
+				"documentId":       issued.ID,
+				"verificationCode": issued.VerificationCode,
+				"storageKey":       issued.StorageKey,
+				"contentHash":      issued.ContentHash,
+				"validUntil":       issued.ValidUntil,
+			},
+		},
+	}, nil
+}
+
+func (t *DocumentTask) Execute(_ context.Context, request *ExecutionRequest) (*ExecutionResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("execution request is required")
+	}
+	return nil, fmt.Errorf("unsupported action %q for DocumentTask", request.Action)
+}
+
+func (t *DocumentTask) GetRenderInfo(_ context.Context) (*ApiResponse, error) {
+	content := map[string]any{
+		"documentType": t.config.DocumentType,
+		"title":        t.config.Title,
+	}
+	if issued, err := t.readIssued(); err == nil && issued != nil {
+		content["document"] = issued
+	}
+	return &ApiResponse{
+		Success: true,
+		Data: GetRenderInfoResponse{
+			Type:        TaskTypeDocument,
+			PluginState: t.api.GetPluginState(),
+			State:       t.api.GetTaskState(),
+			Content:     content,
+		},
+	}, nil
+}
+
+// content resolves the placeholders of the configured document.
+func (t *DocumentTask) content() document.Content {
+	c := document.Content{
+		DocumentType: t.config.DocumentType,
+		Title:        resolvePlaceholders(t.api, t.config.Title),
+		Subtitle:     resolvePlaceholders(t.api, t.config.Subtitle),
+	}
+	for _, f := range t.config.Fields {
+		c.Fields = append(c.Fields, document.Field{Label: f.Label, Value: resolvePlaceholders(t.api, f.Value)})
+	}
+	for _, p := range t.config.Paragraphs {
+		c.Paragraphs = append(c.Paragraphs, resolvePlaceholders(t.api, p))
+	}
+	return c
+}
+
+// readIssued reads the issued document record from local store, or nil if none was issued.
+func (t *DocumentTask) readIssued() (*document.IssuedDocument, error) {
+	raw, err := t.api.ReadFromLocalStore(documentStoreIssued)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	if d, ok := raw.(document.IssuedDocument); ok {
+		return &d, nil
+	}
+
+	// JSON round-trip after cache miss / persistence reload.
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("document: failed to marshal stored record: %w", err)
+	}
+	var d document.IssuedDocument
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, fmt.Errorf("document: failed to unmarshal stored record: %w", err)
+	}
+	return &d, nil
+}
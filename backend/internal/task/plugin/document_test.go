@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/document"
+)
+
+// MockDocumentIssuer
+type MockDocumentIssuer struct {
+	mock.Mock
+}
+
+func (m *MockDocumentIssuer) Issue(ctx context.Context, req document.IssueRequest) (*document.IssuedDocument, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*document.IssuedDocument), args.Error(1)
+}
+
+func TestNewDocumentTask(t *testing.T) {
+	task, err := NewDocumentTask(json.RawMessage(`{"documentType":"RECEIPT","title":"Payment Receipt"}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "document", task.config.OutputKey)
+
+	_, err = NewDocumentTask(json.RawMessage(`{"documentType":"RECEIPT"}`), nil)
+	assert.ErrorContains(t, err, "title are required")
+
+	task, err = NewDocumentTask(json.RawMessage(`{"documentType":"CERTIFICATE","title":"Certificate","validFor":"2160h"}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 90*24*time.Hour, task.validFor)
+
+	_, err = NewDocumentTask(json.RawMessage(`{"documentType":"CERTIFICATE","title":"Certificate","validFor":"90 days"}`), nil)
+	assert.ErrorContains(t, err, "validFor must be a positive duration")
+}
+
+func TestNewDocumentFSM(t *testing.T) {
+	fsm := NewDocumentFSM()
+	assert.True(t, fsm.CanTransition("", FSMActionStart))
+	assert.False(t, fsm.CanTransition(string(documentIssued), FSMActionStart))
+}
+
+func TestDocumentTask_Start(t *testing.T) {
+	config := json.RawMessage(`{
+		"documentType": "RECEIPT",
+		"title": "Payment Receipt",
+		"fields": [
+			{"label": "Reference", "value": "{payment.referenceNumber}"},
+			{"label": "Amount", "value": "{payment.currency} {payment.amount}"},
+			{"label": "Consignment", "value": "{consignmentId:N/A}"}
+		],
+		"outputKey": "receipt",
+		"issuingOga": "CUSTOMS",
+		"validFor": "24h"
+	}`)
+	validUntil := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	issued := &document.IssuedDocument{ID: "doc-1", VerificationCode: "ABCD-EFGH-JKMN", StorageKey: "receipt.pdf", ContentHash: "abc", ValidUntil: &validUntil}
+	wantOutputs := map[string]any{"receipt": map[string]any{
+		"documentId": "doc-1", "verificationCode": "ABCD-EFGH-JKMN", "storageKey": "receipt.pdf", "contentHash": "abc", "validUntil": &validUntil,
+	}}
+
+	t.Run("issues the document from the global store", func(t *testing.T) {
+		issuer := new(MockDocumentIssuer)
+		api := new(MockAPI)
+		task, err := NewDocumentTask(config, issuer)
+		require.NoError(t, err)
+		task.Init(api)
+
+		api.On("CanTransition", FSMActionStart).Return(true)
+		api.On("ReadFromLocalStore", documentStoreIssued).Return(nil, nil)
+		api.On("GetTaskID").Return("task-1")
+		api.On("GetWorkflowID").Return("wf-1")
+		api.On("ReadFromGlobalStore", "payment").Return(map[string]any{"referenceNumber": "REF-1", "amount": "1500.00", "currency": "LKR"}, true)
+		api.On("ReadFromGlobalStore", "consignmentId").Return(nil, false)
+		api.On("WriteToLocalStore", documentStoreIssued, *issued).Return(nil)
+		api.On("Transition", FSMActionStart).Return(nil)
+		issuer.On("Issue", mock.Anything, document.IssueRequest{
+			ConsignmentID: "wf-1",
+			TaskID:        "task-1",
+			WorkflowID:    "wf-1",
+			IssuingOGA:    "CUSTOMS",
+			ValidFor:      24 * time.Hour,
+			Content: document.Content{
+				DocumentType: "RECEIPT",
+				Title:        "Payment Receipt",
+				Fields: []document.Field{
+					{Label: "Reference", Value: "REF-1"},
+					{Label: "Amount", Value: "LKR 1500.00"},
+					{Label: "Consignment", Value: "N/A"},
+				},
+			},
+		}).Return(issued, nil).Once()
+
+		resp, err := task.Start(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, wantOutputs, resp.Outputs)
+		issuer.AssertExpectations(t)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("reuses a document issued by an earlier attempt", func(t *testing.T) {
+		issuer := new(MockDocumentIssuer)
+		api := new(MockAPI)
+		task, err := NewDocumentTask(config, issuer)
+		require.NoError(t, err)
+		task.Init(api)
+
+		api.On("CanTransition", FSMActionStart).Return(true)
+		api.On("ReadFromLocalStore", documentStoreIssued).Return(map[string]any{
+			"id": "doc-1", "verificationCode": "ABCD-EFGH-JKMN", "storageKey": "receipt.pdf", "contentHash": "abc", "validUntil": "2026-10-17T09:00:00Z",
+		}, nil)
+		api.On("Transition", FSMActionStart).Return(nil)
+
+		resp, err := task.Start(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, wantOutputs, resp.Outputs)
+		issuer.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("does nothing once issued", func(t *testing.T) {
+		api := new(MockAPI)
+		task, err := NewDocumentTask(config, new(MockDocumentIssuer))
+		require.NoError(t, err)
+		task.Init(api)
+		api.On("CanTransition", FSMActionStart).Return(false)
+
+		resp, err := task.Start(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, resp.Outputs)
+	})
+}
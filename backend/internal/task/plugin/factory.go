@@ -7,8 +7,10 @@ import (
 	"log/slog"
 
 	"github.com/OpenNSW/nsw/internal/config"
+	"github.com/OpenNSW/nsw/internal/document"
+	"github.com/OpenNSW/nsw/internal/feeschedule"
 	"github.com/OpenNSW/nsw/internal/form"
-	"github.com/OpenNSW/nsw/internal/payments"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
 	"github.com/OpenNSW/nsw/pkg/remote"
 	"gorm.io/gorm"
 )
@@ -24,16 +26,52 @@ type TaskFactory interface {
 	BuildExecutor(ctx context.Context, taskType Type, config json.RawMessage) (Executor, error)
 }
 
-// taskFactory implements TaskFactory interface
+// taskFactory implements TaskFactory by looking up plugin types in a Registry.
 type taskFactory struct {
-	config         *config.Config
-	formService    form.FormService
-	paymentService payments.PaymentService
-	remoteManager  *remote.Manager
+	registry *Registry
+	deps     Dependencies
 }
 
-// NewTaskFactory creates a new TaskFactory instance and initializes the remote services manager.
-func NewTaskFactory(cfg *config.Config, db *gorm.DB, paymentService payments.PaymentService) TaskFactory {
+// NewTaskFactory creates a new TaskFactory instance backed by the given plugin registry.
+// Returns an error if a registered plugin type requires a dependency that is not available.
+func NewTaskFactory(cfg *config.Config, db *gorm.DB, paymentService paymentsv2.PaymentService, feeSchedules feeschedule.Resolver, documents document.Issuer, rm *remote.Manager, registry *Registry) (TaskFactory, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("plugin registry cannot be nil")
+	}
+
+	deps := Dependencies{
+		Config:         cfg,
+		DocumentIssuer: documents,
+		FeeSchedules:   feeSchedules,
+		FormService:    form.NewFormService(db),
+		PaymentService: paymentService,
+		RemoteManager:  rm,
+	}
+	if err := registry.CheckDependencies(deps); err != nil {
+		return nil, err
+	}
+
+	return &taskFactory{
+		registry: registry,
+		deps:     deps,
+	}, nil
+}
+
+func (f *taskFactory) BuildExecutor(_ context.Context, taskType Type, config json.RawMessage) (Executor, error) {
+	reg, ok := f.registry.Lookup(taskType)
+	if !ok {
+		return Executor{}, fmt.Errorf("unknown task type: %s", taskType)
+	}
+	p, err := reg.New(config, f.deps)
+	if err != nil {
+		return Executor{}, err
+	}
+	return Executor{Plugin: p, FSM: reg.NewFSM()}, nil
+}
+
+// NewRemoteManager creates the remote services manager from the configured services file.
+// A missing or invalid file is logged and leaves the manager without services.
+func NewRemoteManager(cfg *config.Config) *remote.Manager {
 	rm := remote.NewManager()
 	if err := rm.LoadServices(cfg.Server.ServicesConfigPath); err != nil {
 		slog.Warn("factory: failed to load external services configuration",
@@ -43,27 +81,5 @@ func NewTaskFactory(cfg *config.Config, db *gorm.DB, paymentService payments.Pay
 		slog.Info("factory: external services configuration loaded",
 			"services", rm.ListServices())
 	}
-
-	return &taskFactory{
-		config:         cfg,
-		remoteManager:  rm,
-		formService:    form.NewFormService(db),
-		paymentService: paymentService,
-	}
-}
-
-func (f *taskFactory) BuildExecutor(ctx context.Context, taskType Type, config json.RawMessage) (Executor, error) {
-	switch taskType {
-	case TaskTypeSimpleForm:
-		p, err := NewSimpleForm(config, f.config, f.formService, f.remoteManager)
-		return Executor{Plugin: p, FSM: NewSimpleFormFSM()}, err
-	case TaskTypeWaitForEvent:
-		p, err := NewWaitForEventTask(config, f.config.Server.ServiceURL, f.remoteManager, f.formService)
-		return Executor{Plugin: p, FSM: NewWaitForEventFSM()}, err
-	case TaskTypePayment:
-		p, err := NewPaymentTask(config, f.paymentService)
-		return Executor{Plugin: p, FSM: NewPaymentFSM()}, err
-	default:
-		return Executor{}, fmt.Errorf("unknown task type: %s", taskType)
-	}
+	return rm
 }
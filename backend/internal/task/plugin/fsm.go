@@ -62,3 +62,13 @@ func (f *PluginFSM) CanTransition(currentState, action string) bool {
 	_, ok := f.transitions[TransitionKey{FromState: currentState, Action: action}]
 	return ok
 }
+
+// HasAction reports whether action is a legal transition from any plugin state.
+func (f *PluginFSM) HasAction(action string) bool {
+	for key := range f.transitions {
+		if key.Action == action {
+			return true
+		}
+	}
+	return false
+}
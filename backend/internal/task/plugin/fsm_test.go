@@ -378,6 +378,33 @@ func TestNewWaitForEventFSM(t *testing.T) {
 			wantNextState: string(notifiedService),
 			wantTaskState: InProgress,
 		},
+		{
+			name:          "deadline escalation keeps task in progress",
+			currentState:  string(notifiedService),
+			action:        DeadlineActionEscalate,
+			wantNextState: string(escalated),
+			wantTaskState: InProgress,
+		},
+		{
+			name:          "complete from escalated",
+			currentState:  string(escalated),
+			action:        waitForEventFSMComplete,
+			wantNextState: string(receivedCallback),
+			wantTaskState: Completed,
+		},
+		{
+			name:          "deadline timeout fails the task",
+			currentState:  string(notifiedService),
+			action:        DeadlineActionTimeout,
+			wantNextState: string(timedOut),
+			wantTaskState: Failed,
+		},
+		{
+			name:         "deadline timeout not permitted after completion",
+			currentState: string(receivedCallback),
+			action:       DeadlineActionTimeout,
+			wantErr:      true,
+		},
 		{
 			name:         "complete not permitted before start",
 			currentState: "",
@@ -413,3 +440,14 @@ func TestNewWaitForEventFSM(t *testing.T) {
 		})
 	}
 }
+
+func TestPluginFSM_HasAction(t *testing.T) {
+	fsm := NewWaitForEventFSM()
+
+	if !fsm.HasAction(DeadlineActionTimeout) {
+		t.Errorf("expected %q to be a known action", DeadlineActionTimeout)
+	}
+	if fsm.HasAction("UNKNOWN") {
+		t.Errorf("expected UNKNOWN to be an unknown action")
+	}
+}
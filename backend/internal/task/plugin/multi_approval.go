@@ -0,0 +1,651 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/pkg/jsonutils"
+	"github.com/OpenNSW/nsw/pkg/remote"
+)
+
+// CallbackSourceQueryParam is the query parameter on a task callback URL that names the
+// approver a callback answers for. MULTI_APPROVAL hands each approver its own callback URL so
+// that approvers served by the same service can be told apart; the agency itself is always
+// taken from the authenticated M2M client.
+const CallbackSourceQueryParam = "source"
+
+type multiApprovalState string
+
+const (
+	awaitingApprovals     multiApprovalState = "AWAITING_APPROVALS"
+	approvalsNotifyFailed multiApprovalState = "NOTIFY_FAILED"
+	approvalsEscalated    multiApprovalState = "ESCALATED"
+	quorumApproved        multiApprovalState = "APPROVED"
+	quorumRejected        multiApprovalState = "REJECTED"
+	approvalsTimedOut     multiApprovalState = "TIMED_OUT"
+)
+
+// Internal FSM actions for MultiApprovalTask.
+const (
+	multiApprovalFSMStartFailed  = "START_FAILED"
+	multiApprovalFSMRetry        = "RETRY"
+	multiApprovalFSMCallback     = "OGA_VERIFICATION"
+	multiApprovalFSMQuorumMet    = "QUORUM_MET"
+	multiApprovalFSMQuorumFailed = "QUORUM_FAILED"
+)
+
+// Local store key prefixes for per-approver bookkeeping. Each approver gets its own key so that
+// concurrent callbacks from different agencies never overwrite each other's results.
+const (
+	approvalNotifiedKeyPrefix = "approvalNotified:"
+	approvalResultKeyPrefix   = "approvalResult:"
+)
+
+// QuorumRuleType selects how many approvers must approve for a MULTI_APPROVAL task to pass.
+type QuorumRuleType string
+
+const (
+	QuorumAll  QuorumRuleType = "ALL"    // every approver must approve
+	QuorumAny  QuorumRuleType = "ANY"    // a single approval is enough
+	QuorumNOfM QuorumRuleType = "N_OF_M" // at least Required approvers must approve
+)
+
+const (
+	defaultApprovalDecisionField = "decision"
+	defaultApprovalApproveValue  = "APPROVED"
+	defaultApprovalOutputKey     = "approvals"
+)
+
+// QuorumRule is the completion rule for a MULTI_APPROVAL task.
+type QuorumRule struct {
+	Type     QuorumRuleType `json:"type"`
+	Required int            `json:"required,omitempty"` // Only used by N_OF_M
+}
+
+// ApproverConfig describes one agency that is asked to approve.
+type ApproverConfig struct {
+	ID        string   `json:"id"`        // Stable key for the approver, used in callback URLs and outputs
+	ServiceID string   `json:"serviceId"` // remote.Manager service ID
+	Url       string   `json:"url"`       // Path the approval request is posted to
+	Request   *Request `json:"request,omitempty"`
+}
+
+// ApprovalDecisionConfig tells the plugin how to read an approver's decision from its callback content.
+type ApprovalDecisionConfig struct {
+	Field         string   `json:"field,omitempty"`         // Defaults to "decision"
+	ApproveValues []string `json:"approveValues,omitempty"` // Defaults to ["APPROVED"]; any other value is a rejection
+}
+
+// MultiApprovalConfig represents the configuration for a MULTI_APPROVAL task.
+//
+// Example:
+//
+//	{
+//	  "approvers": [
+//	    { "id": "tea-board", "serviceId": "tea-board", "url": "/api/oga/inject", "request": { "taskCode": "tea-export" } },
+//	    { "id": "npqs", "serviceId": "npqs", "url": "/api/oga/inject", "request": { "taskCode": "phyto" } }
+//	  ],
+//	  "rule": { "type": "ALL" }
+//	}
+type MultiApprovalConfig struct {
+	Display   *WaitForEventDisplay    `json:"display,omitempty"`
+	Approvers []ApproverConfig        `json:"approvers"`
+	Rule      QuorumRule              `json:"rule"`
+	Decision  *ApprovalDecisionConfig `json:"decision,omitempty"`
+	OutputKey string                  `json:"outputKey,omitempty"` // Task output key for the approval summary; defaults to "approvals"
+}
+
+// ApprovalResult is a single approver's recorded decision.
+type ApprovalResult struct {
+	Decision string `json:"decision"`
+	Approved bool   `json:"approved"`
+	Response any    `json:"response,omitempty"`
+}
+
+// ApprovalSummary is reported in the task outputs once the quorum is decided.
+type ApprovalSummary struct {
+	Approved      bool                      `json:"approved"`
+	Rule          QuorumRuleType            `json:"rule"`
+	Required      int                       `json:"required"`
+	ApprovedCount int                       `json:"approvedCount"`
+	Results       map[string]ApprovalResult `json:"results"`
+}
+
+// NewMultiApprovalFSM returns the state graph for MultiApprovalTask.
+//
+// State graph:
+//
+//	""                 ──START─────────────► AWAITING_APPROVALS [IN_PROGRESS]
+//	""                 ──START_FAILED──────► NOTIFY_FAILED      [IN_PROGRESS]
+//	NOTIFY_FAILED      ──RETRY─────────────► AWAITING_APPROVALS [IN_PROGRESS]
+//	AWAITING_APPROVALS ──QUORUM_MET────────► APPROVED           [COMPLETED]
+//	AWAITING_APPROVALS ──QUORUM_FAILED─────► REJECTED           [COMPLETED]
+//	AWAITING_APPROVALS ──DEADLINE_ESCALATE─► ESCALATED          [IN_PROGRESS]
+//	NOTIFY_FAILED      ──QUORUM_MET────────► APPROVED           [COMPLETED]
+//	NOTIFY_FAILED      ──QUORUM_FAILED─────► REJECTED           [COMPLETED]
+//	ESCALATED          ──QUORUM_MET────────► APPROVED           [COMPLETED]
+//	ESCALATED          ──QUORUM_FAILED─────► REJECTED           [COMPLETED]
+//	AWAITING_APPROVALS ──DEADLINE_TIMEOUT──► TIMED_OUT          [FAILED]
+//	NOTIFY_FAILED      ──DEADLINE_TIMEOUT──► TIMED_OUT          [FAILED]
+//	ESCALATED          ──DEADLINE_TIMEOUT──► TIMED_OUT          [FAILED]
+//
+// Callbacks can arrive in NOTIFY_FAILED because only some approvers may have failed to be notified.
+// A deadline may fire QUORUM_MET to auto-approve.
+//
+// A failed quorum completes the task (REJECTED is a COMPLETED state, not FAILED): the rejection is
+// a business outcome, reported in the approval summary with approved=false, which the workflow
+// routes on like any other task output. FAILED is reserved for tasks that could not be decided,
+// such as TIMED_OUT.
+func NewMultiApprovalFSM() *PluginFSM {
+	transitions := map[TransitionKey]TransitionOutcome{
+		{"", FSMActionStart}:                                   {string(awaitingApprovals), InProgress},
+		{"", multiApprovalFSMStartFailed}:                      {string(approvalsNotifyFailed), InProgress},
+		{string(approvalsNotifyFailed), multiApprovalFSMRetry}: {string(awaitingApprovals), InProgress},
+		{string(awaitingApprovals), DeadlineActionEscalate}:    {string(approvalsEscalated), InProgress},
+	}
+	for _, waiting := range []multiApprovalState{awaitingApprovals, approvalsNotifyFailed, approvalsEscalated} {
+		transitions[TransitionKey{string(waiting), multiApprovalFSMQuorumMet}] = TransitionOutcome{string(quorumApproved), Completed}
+		transitions[TransitionKey{string(waiting), multiApprovalFSMQuorumFailed}] = TransitionOutcome{string(quorumRejected), Completed}
+		transitions[TransitionKey{string(waiting), DeadlineActionTimeout}] = TransitionOutcome{string(approvalsTimedOut), Failed}
+	}
+	return NewPluginFSM(transitions)
+}
+
+// multiApprovalRegistration registers the MULTI_APPROVAL plugin type.
+var multiApprovalRegistration = Registration{
+	Type: TaskTypeMultiApproval,
+	New: func(config json.RawMessage, deps Dependencies) (Plugin, error) {
+		var serviceURL string
+		if deps.Config != nil {
+			serviceURL = deps.Config.Server.ServiceURL
+		}
+		p, err := NewMultiApprovalTask(config, serviceURL, deps.RemoteManager)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	},
+	NewFSM:   NewMultiApprovalFSM,
+	Requires: []Dependency{DependencyRemoteManager},
+	Policies: map[string]ActionPolicy{
+		multiApprovalFSMRetry:    Allow(PartyTrader, PartyCHA),
+		multiApprovalFSMCallback: Allow(PartyInjectedService),
+	},
+	CallbackServices: multiApprovalCallbackServices,
+}
+
+// MultiApprovalTask implements Plugin for the MULTI_APPROVAL task type. It fans a single node out
+// to several agencies and completes once their OGA_VERIFICATION callbacks satisfy the quorum rule.
+type MultiApprovalTask struct {
+	api            API
+	config         MultiApprovalConfig
+	serviceBaseURL string
+	remoteManager  *remote.Manager
+
+	// mu serializes callback handling so that tallying and the quorum transition see a consistent set of results.
+	mu sync.Mutex
+}
+
+// NewMultiApprovalTask creates a MultiApprovalTask from the raw JSON configuration.
+func NewMultiApprovalTask(raw json.RawMessage, serviceBaseURL string, remoteManager *remote.Manager) (*MultiApprovalTask, error) {
+	var cfg MultiApprovalConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("multi_approval: invalid config: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("multi_approval: %w", err)
+	}
+	return &MultiApprovalTask{
+		config:         cfg,
+		serviceBaseURL: serviceBaseURL,
+		remoteManager:  remoteManager,
+	}, nil
+}
+
+func (c *MultiApprovalConfig) validate() error {
+	if len(c.Approvers) == 0 {
+		return fmt.Errorf("at least one approver is required")
+	}
+	seen := make(map[string]bool, len(c.Approvers))
+	for i := range c.Approvers {
+		approver := &c.Approvers[i]
+		if approver.ID == "" {
+			approver.ID = approver.ServiceID
+		}
+		if approver.ID == "" {
+			return fmt.Errorf("approver %d: id or serviceId is required", i)
+		}
+		if seen[approver.ID] {
+			return fmt.Errorf("duplicate approver id %q", approver.ID)
+		}
+		seen[approver.ID] = true
+		if approver.Url == "" {
+			return fmt.Errorf("approver %q: url is required", approver.ID)
+		}
+		if approver.Request == nil || approver.Request.TaskCode == "" {
+			return fmt.Errorf("approver %q: request.taskCode is required", approver.ID)
+		}
+	}
+	switch c.Rule.Type {
+	case QuorumAll, QuorumAny:
+	case QuorumNOfM:
+		if c.Rule.Required < 1 || c.Rule.Required > len(c.Approvers) {
+			return fmt.Errorf("rule N_OF_M requires between 1 and %d approvals, got %d", len(c.Approvers), c.Rule.Required)
+		}
+	default:
+		return fmt.Errorf("unsupported quorum rule %q", c.Rule.Type)
+	}
+	return nil
+}
+
+// multiApprovalCallbackServices returns the approvers' services, narrowed to the approver named by
+// a callback's source when it has one. The handler still matches the caller against the approver
+// it votes for (see callbackApprover).
+func multiApprovalCallbackServices(raw json.RawMessage, request *ExecutionRequest) ([]ServiceRef, error) {
+	var cfg MultiApprovalConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("multi_approval: invalid config: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("multi_approval: %w", err)
+	}
+	var refs []ServiceRef
+	for _, approver := range cfg.Approvers {
+		if request != nil && request.Source != "" && approver.ID != request.Source {
+			continue
+		}
+		refs = append(refs, ServiceRef{ServiceID: approver.ServiceID, URL: approver.Url})
+	}
+	return refs, nil
+}
+
+func (t *MultiApprovalTask) Init(api API) {
+	t.api = api
+}
+
+// required returns the number of approvals needed to meet the quorum.
+func (t *MultiApprovalTask) required() int {
+	switch t.config.Rule.Type {
+	case QuorumAny:
+		return 1
+	case QuorumNOfM:
+		return t.config.Rule.Required
+	default:
+		return len(t.config.Approvers)
+	}
+}
+
+func (t *MultiApprovalTask) Start(ctx context.Context) (*ExecutionResponse, error) {
+	if !t.api.CanTransition(FSMActionStart) {
+		return &ExecutionResponse{Message: "MultiApproval already started"}, nil
+	}
+	if failed := t.notifyPendingApprovers(ctx); len(failed) > 0 {
+		if err := t.api.Transition(multiApprovalFSMStartFailed); err != nil {
+			slog.ErrorContext(ctx, "failed to transition to NOTIFY_FAILED after notification error",
+				"taskId", t.api.GetTaskID(),
+				"workflowId", t.api.GetWorkflowID(),
+				"error", err)
+			return nil, fmt.Errorf("failed to notify approvers and transition to NOTIFY_FAILED: %w", err)
+		}
+		return &ExecutionResponse{Message: fmt.Sprintf("Failed to notify approvers: %s", strings.Join(failed, ", "))}, nil
+	}
+	if err := t.api.Transition(FSMActionStart); err != nil {
+		return nil, err
+	}
+	return &ExecutionResponse{Message: "Notified approvers, waiting for callbacks"}, nil
+}
+
+func (t *MultiApprovalTask) Execute(ctx context.Context, request *ExecutionRequest) (*ExecutionResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("execution request is required")
+	}
+	switch request.Action {
+	case multiApprovalFSMRetry:
+		if failed := t.notifyPendingApprovers(ctx); len(failed) > 0 {
+			// error is nil since the problem is not on system side.
+			return &ExecutionResponse{
+				Message: "Failed to notify approvers",
+				ApiResponse: &ApiResponse{
+					Success: false,
+					Error: &ApiError{
+						Code:    "EXTERNAL_SERVICE_NOTIFICATION_FAILED",
+						Message: "Failed to notify approvers",
+						Details: failed,
+					},
+				},
+			}, nil
+		}
+		if err := t.api.Transition(multiApprovalFSMRetry); err != nil {
+			return nil, err
+		}
+		return &ExecutionResponse{
+			Message:     "Notified approvers, waiting for callbacks",
+			ApiResponse: &ApiResponse{Success: true},
+		}, nil
+	case multiApprovalFSMCallback:
+		return t.handleCallback(ctx, request)
+	default:
+		return nil, fmt.Errorf("unsupported action %q for MultiApprovalTask", request.Action)
+	}
+}
+
+// handleCallback records an approver's decision and applies the quorum rule.
+//
+// The decision is recorded before the quorum transition, so a transition that fails leaves the
+// vote in place. A repeated callback from the same approver therefore re-applies the quorum rule
+// to the recorded decisions instead of being dropped, which lets the retry complete the task.
+func (t *MultiApprovalTask) handleCallback(ctx context.Context, request *ExecutionRequest) (*ExecutionResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	approver, ok := t.callbackApprover(ctx, request.Source)
+	if !ok {
+		return &ExecutionResponse{
+			Message: "Callback from unknown approver",
+			ApiResponse: &ApiResponse{
+				Success: false,
+				Error: &ApiError{
+					Code:    "UNKNOWN_APPROVER",
+					Message: fmt.Sprintf("caller is not approver %q of this task", request.Source),
+				},
+			},
+		}, nil
+	}
+
+	results, err := t.readResults()
+	if err != nil {
+		return nil, err
+	}
+	if _, recorded := results[approver.ID]; recorded {
+		summary, action := t.tally(results)
+		if action == "" || !t.api.CanTransition(action) {
+			return &ExecutionResponse{
+				Message:     fmt.Sprintf("Decision from %s already recorded", approver.ID),
+				ApiResponse: &ApiResponse{Success: true},
+			}, nil
+		}
+		slog.InfoContext(ctx, "repeated approval, retrying pending quorum transition",
+			"taskId", t.api.GetTaskID(), "approver", approver.ID, "action", action)
+		return t.applyQuorum(summary, action)
+	}
+
+	result := t.decide(request.Content)
+	if err := t.api.WriteToLocalStore(approvalResultKeyPrefix+approver.ID, result); err != nil {
+		return nil, fmt.Errorf("failed to record decision from %s: %w", approver.ID, err)
+	}
+	results[approver.ID] = result
+
+	summary, action := t.tally(results)
+	if action == "" || !t.api.CanTransition(action) {
+		slog.InfoContext(ctx, "recorded approval, quorum not yet decided",
+			"taskId", t.api.GetTaskID(), "approver", approver.ID, "approved", result.Approved)
+		return &ExecutionResponse{
+			Message:     fmt.Sprintf("Decision from %s recorded", approver.ID),
+			ApiResponse: &ApiResponse{Success: true},
+		}, nil
+	}
+	return t.applyQuorum(summary, action)
+}
+
+// tally summarizes the recorded results and returns the quorum action they call for, or "" if
+// the quorum cannot be decided yet.
+func (t *MultiApprovalTask) tally(results map[string]ApprovalResult) (ApprovalSummary, string) {
+	summary := t.summarize(results)
+	switch {
+	case summary.ApprovedCount >= summary.Required:
+		return summary, multiApprovalFSMQuorumMet
+	case summary.ApprovedCount+len(t.config.Approvers)-len(results) < summary.Required:
+		return summary, multiApprovalFSMQuorumFailed
+	}
+	return summary, ""
+}
+
+// applyQuorum applies the quorum action and reports the summary as the task output.
+func (t *MultiApprovalTask) applyQuorum(summary ApprovalSummary, action string) (*ExecutionResponse, error) {
+	if err := t.api.Transition(action); err != nil {
+		return nil, err
+	}
+	return &ExecutionResponse{
+		Outputs:     map[string]any{t.outputKey(): summary},
+		Message:     fmt.Sprintf("Quorum decided: approved=%t", summary.Approved),
+		ApiResponse: &ApiResponse{Success: true},
+	}, nil
+}
+
+// callbackApprover resolves the approver a callback answers for from the authenticated M2M
+// client: only approvers whose service calls back with that client are candidates. The source
+// from the callback URL picks between candidates that share a service and must match a sole
+// candidate, so a caller can never vote on another agency's behalf.
+func (t *MultiApprovalTask) callbackApprover(ctx context.Context, source string) (ApproverConfig, bool) {
+	authCtx := auth.GetAuthContext(ctx)
+	if authCtx == nil || authCtx.Client == nil || t.remoteManager == nil {
+		return ApproverConfig{}, false
+	}
+	var candidates []ApproverConfig
+	for _, approver := range t.config.Approvers {
+		if slices.Contains(t.remoteManager.CallbackClientIDs(approver.ServiceID), authCtx.Client.ClientID) {
+			candidates = append(candidates, approver)
+		}
+	}
+	if len(candidates) == 1 && source == "" {
+		return candidates[0], true
+	}
+	for _, approver := range candidates {
+		if approver.ID == source {
+			return approver, true
+		}
+	}
+	return ApproverConfig{}, false
+}
+
+// decide reads the decision field from a callback's content.
+func (t *MultiApprovalTask) decide(content any) ApprovalResult {
+	field := defaultApprovalDecisionField
+	approveValues := []string{defaultApprovalApproveValue}
+	if t.config.Decision != nil {
+		if t.config.Decision.Field != "" {
+			field = t.config.Decision.Field
+		}
+		if len(t.config.Decision.ApproveValues) > 0 {
+			approveValues = t.config.Decision.ApproveValues
+		}
+	}
+
+	result := ApprovalResult{Response: content}
+	if fields, ok := content.(map[string]any); ok {
+		if decision, ok := fields[field].(string); ok {
+			result.Decision = decision
+			result.Approved = slices.Contains(approveValues, decision)
+		}
+	}
+	return result
+}
+
+// summarize tallies the recorded results against the quorum rule.
+func (t *MultiApprovalTask) summarize(results map[string]ApprovalResult) ApprovalSummary {
+	summary := ApprovalSummary{
+		Rule:     t.config.Rule.Type,
+		Required: t.required(),
+		Results:  results,
+	}
+	for _, result := range results {
+		if result.Approved {
+			summary.ApprovedCount++
+		}
+	}
+	summary.Approved = summary.ApprovedCount >= summary.Required
+	return summary
+}
+
+// readResults loads the decisions recorded so far, keyed by approver ID.
+func (t *MultiApprovalTask) readResults() (map[string]ApprovalResult, error) {
+	results := make(map[string]ApprovalResult, len(t.config.Approvers))
+	for _, approver := range t.config.Approvers {
+		raw, err := t.api.ReadFromLocalStore(approvalResultKeyPrefix + approver.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read decision from %s: %w", approver.ID, err)
+		}
+		if raw == nil {
+			continue
+		}
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal decision from %s: %w", approver.ID, err)
+		}
+		var result ApprovalResult
+		if err := json.Unmarshal(b, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal decision from %s: %w", approver.ID, err)
+		}
+		results[approver.ID] = result
+	}
+	return results, nil
+}
+
+func (t *MultiApprovalTask) outputKey() string {
+	if t.config.OutputKey != "" {
+		return t.config.OutputKey
+	}
+	return defaultApprovalOutputKey
+}
+
+// notifyPendingApprovers notifies every approver that has not been notified yet and returns the IDs that failed.
+func (t *MultiApprovalTask) notifyPendingApprovers(ctx context.Context) []string {
+	var failed []string
+	for _, approver := range t.config.Approvers {
+		if notified, _ := t.api.ReadFromLocalStore(approvalNotifiedKeyPrefix + approver.ID); notified == true {
+			continue
+		}
+		if err := t.notifyApprover(ctx, approver); err != nil {
+			slog.WarnContext(ctx, "failed to notify approver",
+				"taskId", t.api.GetTaskID(), "approver", approver.ID, "error", err)
+			failed = append(failed, approver.ID)
+			continue
+		}
+		if err := t.api.WriteToLocalStore(approvalNotifiedKeyPrefix+approver.ID, true); err != nil {
+			slog.WarnContext(ctx, "failed to record approver notification",
+				"taskId", t.api.GetTaskID(), "approver", approver.ID, "error", err)
+		}
+	}
+	return failed
+}
+
+// notifyApprover posts the approval request to a single approver with retry logic.
+func (t *MultiApprovalTask) notifyApprover(ctx context.Context, approver ApproverConfig) error {
+	if t.remoteManager == nil {
+		return fmt.Errorf("remote manager not initialized")
+	}
+
+	callbackURL := strings.TrimRight(t.serviceBaseURL, "/") + TasksAPIPath +
+		"?" + url.Values{CallbackSourceQueryParam: {approver.ID}}.Encode()
+	extReq := WaitForEventExternalServiceRequest{
+		TaskCode:   approver.Request.TaskCode,
+		TaskID:     t.api.GetTaskID(),
+		WorkflowID: t.api.GetWorkflowID(),
+		ServiceURL: callbackURL,
+		Data:       t.resolveInputData(ctx, approver.Request),
+	}
+
+	req := remote.Request{
+		Method: "POST",
+		Path:   approver.Url,
+		Body:   extReq,
+		Retry:  &remote.DefaultRetryConfig,
+	}
+	if err := t.remoteManager.Call(ctx, approver.ServiceID, req, nil); err != nil {
+		return fmt.Errorf("failed to notify approver %q: %w", approver.ID, err)
+	}
+	return nil
+}
+
+// resolveInputData builds an approver's request data by looking up template values from the global store.
+func (t *MultiApprovalTask) resolveInputData(ctx context.Context, request *Request) any {
+	if request == nil || len(request.Template) == 0 {
+		return nil
+	}
+
+	var template any
+	if err := json.Unmarshal(request.Template, &template); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal approver request template", "error", err)
+		return nil
+	}
+
+	return jsonutils.ResolveTemplate(template, func(path string) any {
+		if path == "" {
+			return nil
+		}
+		value, _ := t.api.ReadFromGlobalStore(path)
+		return value
+	})
+}
+
+func (t *MultiApprovalTask) GetRenderInfo(ctx context.Context) (*ApiResponse, error) {
+	content := map[string]any{
+		"rule":     t.config.Rule.Type,
+		"required": t.required(),
+	}
+	if t.config.Display != nil {
+		display, err := t.getDisplay(multiApprovalState(t.api.GetPluginState()))
+		if err != nil {
+			slog.Warn("failed to get display for multi_approval task, using empty display", "taskId", t.api.GetTaskID(), "error", err)
+			display = &WaitForEventDisplay{}
+		}
+		content["display"] = display
+	}
+
+	approvers := make([]map[string]any, 0, len(t.config.Approvers))
+	results, err := t.readResults()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read approval results", "taskId", t.api.GetTaskID(), "error", err)
+	}
+	for _, approver := range t.config.Approvers {
+		entry := map[string]any{"id": approver.ID}
+		if result, ok := results[approver.ID]; ok {
+			entry["decision"] = result.Decision
+			entry["approved"] = result.Approved
+		}
+		approvers = append(approvers, entry)
+	}
+	content["approvers"] = approvers
+
+	return &ApiResponse{
+		Success: true,
+		Data: GetRenderInfoResponse{
+			Type:        TaskTypeMultiApproval,
+			PluginState: t.api.GetPluginState(),
+			State:       t.api.GetTaskState(),
+			Content:     content,
+		},
+	}, nil
+}
+
+// getDisplay resolves the display text for the current plugin state.
+func (t *MultiApprovalTask) getDisplay(state multiApprovalState) (*WaitForEventDisplay, error) {
+	var resolvedState DisplayState
+	switch state {
+	case awaitingApprovals, approvalsEscalated:
+		resolvedState = DisplayStateWaiting
+	case approvalsNotifyFailed, approvalsTimedOut:
+		resolvedState = DisplayStateFailed
+	case quorumApproved, quorumRejected:
+		resolvedState = DisplayStateCompleted
+	default:
+		return nil, fmt.Errorf("unsupported multi_approval plugin state %q", state)
+	}
+
+	title, err := resolveDisplayField(t.config.Display.Title, resolvedState, "title")
+	if err != nil {
+		return nil, err
+	}
+	description, err := resolveDisplayField(t.config.Display.Description, resolvedState, "description")
+	if err != nil {
+		return nil, err
+	}
+	return &WaitForEventDisplay{Title: title, Description: description}, nil
+}
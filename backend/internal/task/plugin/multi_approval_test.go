@@ -0,0 +1,396 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/pkg/remote"
+)
+
+// maAPI is an API stub with an in-memory local store and a real FSM for MultiApprovalTask tests.
+type maAPI struct {
+	mu          sync.Mutex
+	fsm         *PluginFSM
+	pluginState string
+	taskState   State
+	local       map[string]any
+
+	// transitionErr, if set, fails the next Transition.
+	transitionErr error
+}
+
+func newMAAPI() *maAPI {
+	return &maAPI{fsm: NewMultiApprovalFSM(), taskState: Initialized, local: map[string]any{}}
+}
+
+func (a *maAPI) GetTaskID() string                        { return "task-1" }
+func (a *maAPI) GetWorkflowID() string                    { return "wf-1" }
+func (a *maAPI) GetTaskState() State                      { return a.taskState }
+func (a *maAPI) GetPluginState() string                   { return a.pluginState }
+func (a *maAPI) ReadFromGlobalStore(_ string) (any, bool) { return nil, false }
+func (a *maAPI) WriteToLocalStore(key string, value any) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Round-trip through JSON like the persisted local store does.
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	a.local[key] = decoded
+	return nil
+}
+func (a *maAPI) ReadFromLocalStore(key string) (any, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.local[key], nil
+}
+func (a *maAPI) CanTransition(action string) bool { return a.fsm.CanTransition(a.pluginState, action) }
+func (a *maAPI) Transition(action string) error {
+	if err := a.transitionErr; err != nil {
+		a.transitionErr = nil
+		return err
+	}
+	outcome, err := a.fsm.Transition(a.pluginState, action)
+	if err != nil {
+		return err
+	}
+	a.pluginState = outcome.NextPluginState
+	a.taskState = outcome.NextTaskState
+	return nil
+}
+
+// approverClients maps each test approver to the M2M client its service calls back with. The
+// tea board and NPQS share the OGA service; customs has a service of its own.
+var approverClients = map[string]string{
+	"tea-board": "oga-client",
+	"npqs":      "oga-client",
+	"customs":   "customs-client",
+	"a":         "oga-client",
+}
+
+// newMAManager registers the "oga" and "customs" services at serverURL with their callback clients.
+func newMAManager(t *testing.T, serverURL string) *remote.Manager {
+	t.Helper()
+	if serverURL == "" {
+		serverURL = "http://localhost:9999"
+	}
+	data, err := json.Marshal(remote.Registry{
+		Version: "1.0",
+		Services: []remote.ServiceConfig{
+			{ID: "oga", URL: serverURL, CallbackClientIDs: []string{"oga-client"}},
+			{ID: "customs", URL: serverURL, CallbackClientIDs: []string{"customs-client"}},
+		},
+	})
+	require.NoError(t, err)
+	tmp := t.TempDir() + "/services.json"
+	require.NoError(t, os.WriteFile(tmp, data, 0644))
+	mgr := remote.NewManager()
+	require.NoError(t, mgr.LoadServices(tmp))
+	return mgr
+}
+
+// newMultiApprovalTask builds a task with three approvers whose remote services are at serverURL.
+func newMultiApprovalTask(t *testing.T, rule QuorumRule, serverURL string) (*MultiApprovalTask, *maAPI) {
+	t.Helper()
+	cfg := MultiApprovalConfig{
+		Approvers: []ApproverConfig{
+			{ID: "tea-board", ServiceID: "oga", Url: "/tea", Request: &Request{TaskCode: "tea"}},
+			{ID: "npqs", ServiceID: "oga", Url: "/npqs", Request: &Request{TaskCode: "phyto"}},
+			{ID: "customs", ServiceID: "customs", Url: "/customs", Request: &Request{TaskCode: "cus"}},
+		},
+		Rule: rule,
+	}
+	raw, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	task, err := NewMultiApprovalTask(raw, "http://localhost:8080", newMAManager(t, serverURL))
+	require.NoError(t, err)
+	api := newMAAPI()
+	task.Init(api)
+	return task, api
+}
+
+// asClient returns a context authenticated as the given M2M client.
+func asClient(clientID string) context.Context {
+	return context.WithValue(context.Background(), auth.AuthContextKey, &auth.AuthContext{
+		Client: &auth.ClientContext{ClientID: clientID},
+	})
+}
+
+// asApprover returns a context authenticated as the client of the approver's service.
+func asApprover(source string) context.Context {
+	return asClient(approverClients[source])
+}
+
+func callback(source, decision string) *ExecutionRequest {
+	return &ExecutionRequest{
+		Action:  multiApprovalFSMCallback,
+		Source:  source,
+		Content: map[string]any{"decision": decision, "remarks": "ok"},
+	}
+}
+
+func TestNewMultiApprovalTask_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{"no approvers", `{"rule":{"type":"ALL"}}`, "at least one approver"},
+		{"missing task code", `{"approvers":[{"id":"a","url":"/x"}],"rule":{"type":"ALL"}}`, "taskCode is required"},
+		{"duplicate ids", `{"approvers":[{"id":"a","url":"/x","request":{"taskCode":"c"}},{"serviceId":"a","url":"/y","request":{"taskCode":"c"}}],"rule":{"type":"ANY"}}`, "duplicate approver id"},
+		{"unknown rule", `{"approvers":[{"id":"a","url":"/x","request":{"taskCode":"c"}}],"rule":{"type":"MOST"}}`, "unsupported quorum rule"},
+		{"n of m out of range", `{"approvers":[{"id":"a","url":"/x","request":{"taskCode":"c"}}],"rule":{"type":"N_OF_M","required":2}}`, "between 1 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMultiApprovalTask(json.RawMessage(tt.cfg), "", nil)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestMultiApprovalTask_Start_NotifiesEachApproverWithOwnCallbackURL(t *testing.T) {
+	var mu sync.Mutex
+	callbackURLs := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body WaitForEventExternalServiceRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		callbackURLs[body.TaskCode] = body.ServiceURL
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	task, api := newMultiApprovalTask(t, QuorumRule{Type: QuorumAll}, srv.URL)
+	resp, err := task.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Notified approvers, waiting for callbacks", resp.Message)
+	assert.Equal(t, string(awaitingApprovals), api.pluginState)
+	assert.Equal(t, map[string]string{
+		"tea":   "http://localhost:8080/api/v1/tasks?source=tea-board",
+		"phyto": "http://localhost:8080/api/v1/tasks?source=npqs",
+		"cus":   "http://localhost:8080/api/v1/tasks?source=customs",
+	}, callbackURLs)
+}
+
+func TestMultiApprovalTask_Start_NotifyFailure_RetryOnlyPending(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body WaitForEventExternalServiceRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls[body.TaskCode]++
+		if fail && body.TaskCode == "phyto" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	task, api := newMultiApprovalTask(t, QuorumRule{Type: QuorumAll}, srv.URL)
+	_, err := task.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, string(approvalsNotifyFailed), api.pluginState)
+
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+	resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: multiApprovalFSMRetry})
+	require.NoError(t, err)
+	assert.True(t, resp.ApiResponse.Success)
+	assert.Equal(t, string(awaitingApprovals), api.pluginState)
+	assert.Equal(t, map[string]int{"tea": 1, "phyto": 2, "cus": 1}, calls)
+}
+
+func TestMultiApprovalTask_QuorumRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      QuorumRule
+		callbacks [][2]string
+		decidedAt int // index of the callback that decides the quorum
+		approved  bool
+	}{
+		{"all approve", QuorumRule{Type: QuorumAll}, [][2]string{{"tea-board", "APPROVED"}, {"npqs", "APPROVED"}, {"customs", "APPROVED"}}, 2, true},
+		{"all with one rejection", QuorumRule{Type: QuorumAll}, [][2]string{{"tea-board", "APPROVED"}, {"npqs", "REJECTED"}}, 1, false},
+		{"any approves", QuorumRule{Type: QuorumAny}, [][2]string{{"npqs", "REJECTED"}, {"customs", "APPROVED"}}, 1, true},
+		{"any all reject", QuorumRule{Type: QuorumAny}, [][2]string{{"npqs", "REJECTED"}, {"customs", "REJECTED"}, {"tea-board", "REJECTED"}}, 2, false},
+		{"two of three", QuorumRule{Type: QuorumNOfM, Required: 2}, [][2]string{{"npqs", "REJECTED"}, {"customs", "APPROVED"}, {"tea-board", "APPROVED"}}, 2, true},
+		{"two of three impossible", QuorumRule{Type: QuorumNOfM, Required: 2}, [][2]string{{"npqs", "REJECTED"}, {"customs", "REJECTED"}}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, api := newMultiApprovalTask(t, tt.rule, "")
+			api.pluginState = string(awaitingApprovals)
+			api.taskState = InProgress
+
+			for i, cb := range tt.callbacks {
+				resp, err := task.Execute(asApprover(cb[0]), callback(cb[0], cb[1]))
+				require.NoError(t, err)
+				require.True(t, resp.ApiResponse.Success)
+				if i < tt.decidedAt {
+					assert.Nil(t, resp.Outputs)
+					assert.Equal(t, InProgress, api.taskState)
+					continue
+				}
+				require.Contains(t, resp.Outputs, defaultApprovalOutputKey)
+				summary := resp.Outputs[defaultApprovalOutputKey].(ApprovalSummary)
+				assert.Equal(t, tt.approved, summary.Approved)
+				assert.Len(t, summary.Results, len(tt.callbacks))
+				assert.Equal(t, cb[1], summary.Results[cb[0]].Decision)
+				assert.Equal(t, Completed, api.taskState)
+				if tt.approved {
+					assert.Equal(t, string(quorumApproved), api.pluginState)
+				} else {
+					assert.Equal(t, string(quorumRejected), api.pluginState)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiApprovalTask_Callback_UnknownAndDuplicateSource(t *testing.T) {
+	task, api := newMultiApprovalTask(t, QuorumRule{Type: QuorumAll}, "")
+	api.pluginState = string(awaitingApprovals)
+
+	resp, err := task.Execute(asClient("oga-client"), callback("unknown", "APPROVED"))
+	require.NoError(t, err)
+	assert.False(t, resp.ApiResponse.Success)
+	assert.Equal(t, "UNKNOWN_APPROVER", resp.ApiResponse.Error.Code)
+
+	_, err = task.Execute(asApprover("npqs"), callback("npqs", "APPROVED"))
+	require.NoError(t, err)
+	resp, err = task.Execute(asApprover("npqs"), callback("npqs", "REJECTED"))
+	require.NoError(t, err)
+	assert.True(t, resp.ApiResponse.Success)
+	assert.Equal(t, "Decision from npqs already recorded", resp.Message)
+
+	results, err := task.readResults()
+	require.NoError(t, err)
+	assert.Equal(t, "APPROVED", results["npqs"].Decision)
+}
+
+func TestMultiApprovalTask_Callback_DuplicateRetriesFailedTransition(t *testing.T) {
+	task, api := newMultiApprovalTask(t, QuorumRule{Type: QuorumAny}, "")
+	api.pluginState = string(awaitingApprovals)
+	api.taskState = InProgress
+	api.transitionErr = errors.New("db down")
+
+	_, err := task.Execute(asApprover("npqs"), callback("npqs", "APPROVED"))
+	require.Error(t, err)
+	assert.Equal(t, string(awaitingApprovals), api.pluginState)
+
+	resp, err := task.Execute(asApprover("npqs"), callback("npqs", "APPROVED"))
+	require.NoError(t, err)
+	assert.True(t, resp.ApiResponse.Success)
+	require.Contains(t, resp.Outputs, defaultApprovalOutputKey)
+	assert.True(t, resp.Outputs[defaultApprovalOutputKey].(ApprovalSummary).Approved)
+	assert.Equal(t, string(quorumApproved), api.pluginState)
+	assert.Equal(t, Completed, api.taskState)
+}
+
+func TestMultiApprovalTask_Callback_ApproverFromClient(t *testing.T) {
+	task, api := newMultiApprovalTask(t, QuorumRule{Type: QuorumAny}, "")
+	api.pluginState = string(awaitingApprovals)
+
+	// The OGA client cannot vote as customs by naming it in the callback URL.
+	for name, ctx := range map[string]context.Context{
+		"other agency": asClient("oga-client"),
+		"unknown":      asClient("intruder"),
+		"no principal": context.Background(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := task.Execute(ctx, callback("customs", "APPROVED"))
+			require.NoError(t, err)
+			assert.False(t, resp.ApiResponse.Success)
+			assert.Equal(t, "UNKNOWN_APPROVER", resp.ApiResponse.Error.Code)
+		})
+	}
+
+	// A sole approver needs no source; a shared service does.
+	resp, err := task.Execute(asClient("oga-client"), callback("", "APPROVED"))
+	require.NoError(t, err)
+	assert.Equal(t, "UNKNOWN_APPROVER", resp.ApiResponse.Error.Code)
+	resp, err = task.Execute(asClient("customs-client"), callback("", "REJECTED"))
+	require.NoError(t, err)
+	require.True(t, resp.ApiResponse.Success)
+
+	results, err := task.readResults()
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "REJECTED", results["customs"].Decision)
+}
+
+func TestMultiApprovalTask_CustomDecisionAndOutputKey(t *testing.T) {
+	raw := json.RawMessage(`{
+		"approvers": [{"id": "a", "serviceId": "oga", "url": "/x", "request": {"taskCode": "c"}}],
+		"rule": {"type": "ANY"},
+		"decision": {"field": "review_outcome", "approveValues": ["approve", "conditional"]},
+		"outputKey": "agency_signoff"
+	}`)
+	task, err := NewMultiApprovalTask(raw, "", newMAManager(t, ""))
+	require.NoError(t, err)
+	api := newMAAPI()
+	api.pluginState = string(awaitingApprovals)
+	task.Init(api)
+
+	resp, err := task.Execute(asApprover("a"), &ExecutionRequest{
+		Action:  multiApprovalFSMCallback,
+		Source:  "a",
+		Content: map[string]any{"review_outcome": "conditional"},
+	})
+	require.NoError(t, err)
+	summary := resp.Outputs["agency_signoff"].(ApprovalSummary)
+	assert.True(t, summary.Approved)
+	assert.Equal(t, 1, summary.ApprovedCount)
+}
+
+func TestMultiApprovalFSM_DeadlineActions(t *testing.T) {
+	fsm := NewMultiApprovalFSM()
+	for _, state := range []multiApprovalState{awaitingApprovals, approvalsNotifyFailed, approvalsEscalated} {
+		outcome, err := fsm.Transition(string(state), DeadlineActionTimeout)
+		require.NoError(t, err)
+		assert.Equal(t, Failed, outcome.NextTaskState)
+	}
+	outcome, err := fsm.Transition(string(awaitingApprovals), DeadlineActionEscalate)
+	require.NoError(t, err)
+	assert.Equal(t, string(approvalsEscalated), outcome.NextPluginState)
+	assert.True(t, fsm.HasAction(multiApprovalFSMQuorumMet))
+	assert.False(t, fsm.CanTransition(string(quorumApproved), multiApprovalFSMQuorumFailed))
+}
+
+func TestMultiApprovalTask_GetRenderInfo(t *testing.T) {
+	task, api := newMultiApprovalTask(t, QuorumRule{Type: QuorumNOfM, Required: 2}, "")
+	api.pluginState = string(awaitingApprovals)
+	_, err := task.Execute(asApprover("npqs"), callback("npqs", "APPROVED"))
+	require.NoError(t, err)
+
+	resp, err := task.GetRenderInfo(context.Background())
+	require.NoError(t, err)
+	data := resp.Data.(GetRenderInfoResponse)
+	assert.Equal(t, TaskTypeMultiApproval, data.Type)
+	content := data.Content.(map[string]any)
+	assert.Equal(t, 2, content["required"])
+	approvers := content["approvers"].([]map[string]any)
+	require.Len(t, approvers, 3)
+	assert.Equal(t, map[string]any{"id": "npqs", "decision": "APPROVED", "approved": true}, approvers[1])
+	assert.Equal(t, map[string]any{"id": "tea-board"}, approvers[0])
+}
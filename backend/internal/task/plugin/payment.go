@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
-	"github.com/OpenNSW/nsw/internal/payments"
+	"github.com/OpenNSW/nsw/internal/feeschedule"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -18,6 +20,11 @@ const (
 	PaymentActionInitiate = "INITIATE_PAYMENT"
 	PaymentActionSuccess  = "PAYMENT_SUCCESS"
 	PaymentActionFailed   = "PAYMENT_FAILED"
+
+	// Reported by the payment service rather than by a user.
+	PaymentActionPartial       = "PAYMENT_PARTIAL"
+	PaymentActionRequestRefund = "REQUEST_REFUND"
+	PaymentActionRefunded      = "PAYMENT_REFUNDED"
 )
 
 // paymentFSMTimeout is an internal FSM action triggered by the lazy TTL+Threshold
@@ -36,6 +43,10 @@ const (
 	paymentIdle       paymentState = "IDLE"
 	paymentInProgress paymentState = "IN_PROGRESS"
 	paymentCompleted  paymentState = "COMPLETED"
+
+	paymentPartiallyPaid   paymentState = "PARTIALLY_PAID"
+	paymentRefundRequested paymentState = "REFUND_REQUESTED"
+	paymentRefunded        paymentState = "REFUNDED"
 )
 
 // ── Local Store Keys ──────────────────────────────────────────────────────────
@@ -59,6 +70,7 @@ type BreakdownType string
 const (
 	TypeFixed      BreakdownType = "FIXED"
 	TypePercentage BreakdownType = "PERCENTAGE"
+	TypeSchedule   BreakdownType = "SCHEDULE" // Resolved from a fee schedule; never configured directly
 )
 
 type ApplyOn string
@@ -90,6 +102,24 @@ type PaymentConfig struct {
 	OrgID       string          `json:"orgId"`    // Organization ID
 	ServiceType string          `json:"serviceType,omitempty"`
 	Breakdown   []BreakdownItem `json:"breakdown"`
+
+	// FeeSchedule optionally charges the lines of a fee schedule ahead of the FIXED items.
+	FeeSchedule *FeeScheduleConfig `json:"feeSchedule,omitempty"`
+
+	// OutputKey, when set, reports the completed payment to the workflow under this key, so
+	// that later nodes, such as a receipt, can refer to it.
+	OutputKey string `json:"outputKey,omitempty"`
+}
+
+// FeeScheduleConfig references a fee schedule by ID and says where its inputs come from. Inputs
+// are placeholders or fixed values, resolved like the values of breakdown items.
+type FeeScheduleConfig struct {
+	ID            string `json:"id"`
+	HSCode        string `json:"hsCode,omitempty"`
+	Quantity      string `json:"quantity,omitempty"`
+	Weight        string `json:"weight,omitempty"`
+	Value         string `json:"value,omitempty"`
+	ValueCurrency string `json:"valueCurrency,omitempty"`
 }
 
 // PaymentSession is the current active payment session persisted in local store.
@@ -101,6 +131,8 @@ type PaymentSession struct {
 	SelectedMethodID string     `json:"selectedMethodId,omitempty"`
 	GeneratedAt      time.Time  `json:"generatedAt"`
 	InitiatedAt      *time.Time `json:"initiatedAt,omitempty"` // set when INITIATE_PAYMENT is received
+	// FeeResolutionID pins the fee schedule resolution charged once the payment is initiated.
+	FeeResolutionID string `json:"feeResolutionId,omitempty"`
 }
 
 // PaymentTransaction is an append-only history entry for completed (failed/timed-out)
@@ -114,8 +146,21 @@ type PaymentTransaction struct {
 	Round           int       `json:"round"`
 }
 
-// PaymentRenderContent is the payload returned inside GetRenderInfoResponse.Content
-// when the plugin is in IDLE or IN_PROGRESS.
+// PaymentGatewayResult is the content of PAYMENT_SUCCESS, PAYMENT_FAILED, PAYMENT_PARTIAL and
+// PAYMENT_REFUNDED, as reported by the payment service from a gateway webhook.
+type PaymentGatewayResult struct {
+	ReferenceNumber string          `json:"referenceNumber"`
+	Amount          decimal.Decimal `json:"amount"`
+	Currency        string          `json:"currency"`
+}
+
+// PaymentRefundRequest is the content of REQUEST_REFUND. A zero Amount refunds everything paid.
+type PaymentRefundRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+	Reason string          `json:"reason"`
+}
+
+// PaymentRenderContent is the payload returned inside GetRenderInfoResponse.Content.
 type PaymentRenderContent struct {
 	GatewayURL       string                  `json:"gatewayUrl,omitempty"`
 	TotalAmount      decimal.Decimal         `json:"totalAmount"`
@@ -125,6 +170,12 @@ type PaymentRenderContent struct {
 	OrgID            string                  `json:"orgId,omitempty"`
 	Service          any                     `json:"service,omitempty"`
 	SelectedMethodID string                  `json:"selectedMethodId,omitempty"`
+	FeeResolutionID  string                  `json:"feeResolutionId,omitempty"`
+
+	// From the payment ledger once money has been received.
+	AmountPaid     *decimal.Decimal `json:"amountPaid,omitempty"`
+	AmountRefunded *decimal.Decimal `json:"amountRefunded,omitempty"`
+	NetAmount      *decimal.Decimal `json:"netAmount,omitempty"`
 }
 
 // ── FSM ───────────────────────────────────────────────────────────────────────
@@ -134,12 +185,18 @@ type PaymentRenderContent struct {
 //
 // State graph:
 //
-//	""              ──START────────────────► IDLE          [no task state change]
-//	IDLE            ──INITIATE_PAYMENT─────► IN_PROGRESS   [IN_PROGRESS]
-//	IN_PROGRESS     ──INITIATE_PAYMENT─────► IN_PROGRESS   [IN_PROGRESS]
-//	IN_PROGRESS     ──PAYMENT_SUCCESS──────► COMPLETED     [COMPLETED]
-//	IN_PROGRESS     ──PAYMENT_FAILED───────► IDLE          [IN_PROGRESS]
-//	IN_PROGRESS     ──PAYMENT_TIMEOUT──────► IDLE          [IN_PROGRESS]
+//	""                ──START────────────────► IDLE              [no task state change]
+//	IDLE              ──INITIATE_PAYMENT─────► IN_PROGRESS       [IN_PROGRESS]
+//	IN_PROGRESS       ──INITIATE_PAYMENT─────► IN_PROGRESS       [IN_PROGRESS]
+//	IN_PROGRESS       ──PAYMENT_SUCCESS──────► COMPLETED         [COMPLETED]
+//	IN_PROGRESS       ──PAYMENT_FAILED───────► IDLE              [IN_PROGRESS]
+//	IN_PROGRESS       ──PAYMENT_TIMEOUT──────► IDLE              [IN_PROGRESS]
+//	IN_PROGRESS       ──PAYMENT_PARTIAL──────► PARTIALLY_PAID    [IN_PROGRESS]
+//	PARTIALLY_PAID    ──PAYMENT_SUCCESS──────► COMPLETED         [COMPLETED]
+//	COMPLETED         ──REQUEST_REFUND───────► REFUND_REQUESTED  [no task state change]
+//	REFUND_REQUESTED  ──PAYMENT_REFUNDED─────► REFUNDED          [no task state change]
+//	COMPLETED         ──PAYMENT_REFUNDED─────► REFUNDED          [no task state change]
+//	REFUNDED          ──REQUEST_REFUND───────► REFUND_REQUESTED  [no task state change]
 func NewPaymentFSM() *PluginFSM {
 	return NewPluginFSM(map[TransitionKey]TransitionOutcome{
 		{"", FSMActionStart}:                               {string(paymentIdle), ""},
@@ -148,6 +205,13 @@ func NewPaymentFSM() *PluginFSM {
 		{string(paymentInProgress), PaymentActionSuccess}:  {string(paymentCompleted), Completed},
 		{string(paymentInProgress), PaymentActionFailed}:   {string(paymentIdle), Initialized},
 		{string(paymentInProgress), paymentFSMTimeout}:     {string(paymentIdle), Initialized},
+
+		{string(paymentInProgress), PaymentActionPartial}:       {string(paymentPartiallyPaid), InProgress},
+		{string(paymentPartiallyPaid), PaymentActionSuccess}:    {string(paymentCompleted), Completed},
+		{string(paymentCompleted), PaymentActionRequestRefund}:  {string(paymentRefundRequested), ""},
+		{string(paymentRefundRequested), PaymentActionRefunded}: {string(paymentRefunded), ""},
+		{string(paymentCompleted), PaymentActionRefunded}:       {string(paymentRefunded), ""}, // Refunded at the gateway
+		{string(paymentRefunded), PaymentActionRequestRefund}:   {string(paymentRefundRequested), ""},
 	})
 }
 
@@ -157,18 +221,49 @@ func NewPaymentFSM() *PluginFSM {
 type PaymentTask struct {
 	api            API
 	config         PaymentConfig
-	paymentService payments.PaymentService
+	paymentService paymentsv2.PaymentService
+	feeSchedules   feeschedule.Resolver
+}
+
+// paymentRegistration registers the PAYMENT plugin type.
+var paymentRegistration = Registration{
+	Type: TaskTypePayment,
+	New: func(config json.RawMessage, deps Dependencies) (Plugin, error) {
+		p, err := NewPaymentTask(config, deps.PaymentService, deps.FeeSchedules)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	},
+	NewFSM:   NewPaymentFSM,
+	Requires: []Dependency{DependencyPaymentService, DependencyFeeSchedules},
+	// Only initiating is up to the trader. The other actions have no policy: only the payment
+	// service, reporting gateway webhooks, and back-office refunds, acting as the system, may
+	// execute them.
+	Policies: map[string]ActionPolicy{
+		PaymentActionInitiate: Allow(PartyTrader, PartyCHA),
+	},
 }
 
 // NewPaymentTask creates a PaymentTask from the raw JSON configuration.
-func NewPaymentTask(raw json.RawMessage, paymentService payments.PaymentService) (*PaymentTask, error) {
+// feeSchedules may be nil unless the configuration references a fee schedule.
+func NewPaymentTask(raw json.RawMessage, paymentService paymentsv2.PaymentService, feeSchedules feeschedule.Resolver) (*PaymentTask, error) {
 	var cfg PaymentConfig
 	if err := json.Unmarshal(raw, &cfg); err != nil {
 		return nil, fmt.Errorf("payment: invalid config: %w", err)
 	}
+	if cfg.FeeSchedule != nil {
+		if cfg.FeeSchedule.ID == "" {
+			return nil, fmt.Errorf("payment: invalid config: fee schedule ID is required")
+		}
+		if feeSchedules == nil {
+			return nil, fmt.Errorf("payment: fee schedule %s configured but no fee schedule resolver is available", cfg.FeeSchedule.ID)
+		}
+	}
 	return &PaymentTask{
 		config:         cfg,
 		paymentService: paymentService,
+		feeSchedules:   feeSchedules,
 	}, nil
 }
 
@@ -200,26 +295,30 @@ func (t *PaymentTask) Start(_ context.Context) (*ExecutionResponse, error) {
 func (t *PaymentTask) GetRenderInfo(ctx context.Context) (*ApiResponse, error) {
 	pluginState := t.api.GetPluginState()
 
-	resolvedBreakdown, totalAmount, err := t.calculateBreakdown(ctx)
+	resolvedBreakdown, totalAmount, feeResolutionID, err := t.calculateBreakdown(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("payment: failed to calculate breakdown: %w", err)
 	}
 
-	// Terminal state — nothing actionable to render.
-	if pluginState == string(paymentCompleted) {
+	// Paid — nothing actionable to render beyond what has been paid and refunded.
+	switch paymentState(pluginState) {
+	case paymentCompleted, paymentRefundRequested, paymentRefunded:
+		content := PaymentRenderContent{
+			TotalAmount:     totalAmount,
+			Currency:        t.config.Currency,
+			Breakdown:       resolvedBreakdown,
+			OrgID:           t.config.OrgID,
+			Service:         t.config.ServiceType,
+			FeeResolutionID: feeResolutionID,
+		}
+		t.addLedger(ctx, &content)
 		return &ApiResponse{
 			Success: true,
 			Data: GetRenderInfoResponse{
 				Type:        TaskTypePayment,
 				PluginState: pluginState,
 				State:       t.api.GetTaskState(),
-				Content: PaymentRenderContent{
-					TotalAmount: totalAmount,
-					Currency:    t.config.Currency,
-					Breakdown:   resolvedBreakdown,
-					OrgID:       t.config.OrgID,
-					Service:     t.config.ServiceType,
-				},
+				Content:     content,
 			},
 		}, nil
 	}
@@ -245,8 +344,9 @@ func (t *PaymentTask) GetRenderInfo(ctx context.Context) (*ApiResponse, error) {
 		}
 	}
 
-	// Rotate session if TTL has elapsed (applies to both IDLE and refreshed-from-timeout).
-	if time.Now().After(session.GeneratedAt.Add(t.ttlDuration())) {
+	// Rotate session if TTL has elapsed (applies to both IDLE and refreshed-from-timeout). A
+	// partially paid session keeps its reference so the rest can be paid against it.
+	if pluginState != string(paymentPartiallyPaid) && time.Now().After(session.GeneratedAt.Add(t.ttlDuration())) {
 		newSess := t.newSession()
 		session = &newSess
 		if err := t.api.WriteToLocalStore(paymentStoreSession, session); err != nil {
@@ -254,23 +354,27 @@ func (t *PaymentTask) GetRenderInfo(ctx context.Context) (*ApiResponse, error) {
 		}
 	}
 
-	gatewayURL := session.CheckoutURL
+	content := PaymentRenderContent{
+		GatewayURL:       session.CheckoutURL,
+		TotalAmount:      totalAmount,
+		Currency:         t.config.Currency,
+		ReferenceNumber:  session.ReferenceNumber,
+		Breakdown:        resolvedBreakdown,
+		OrgID:            t.config.OrgID,
+		Service:          t.config.ServiceType,
+		SelectedMethodID: session.SelectedMethodID,
+		FeeResolutionID:  feeResolutionID,
+	}
+	if pluginState == string(paymentPartiallyPaid) {
+		t.addLedger(ctx, &content)
+	}
 	return &ApiResponse{
 		Success: true,
 		Data: GetRenderInfoResponse{
 			Type:        TaskTypePayment,
 			PluginState: pluginState,
 			State:       t.api.GetTaskState(),
-			Content: PaymentRenderContent{
-				GatewayURL:       gatewayURL,
-				TotalAmount:      totalAmount,
-				Currency:         t.config.Currency,
-				ReferenceNumber:  session.ReferenceNumber,
-				Breakdown:        resolvedBreakdown,
-				OrgID:            t.config.OrgID,
-				Service:          t.config.ServiceType,
-				SelectedMethodID: session.SelectedMethodID,
-			},
+			Content:     content,
 		},
 	}, nil
 }
@@ -286,9 +390,15 @@ func (t *PaymentTask) Execute(ctx context.Context, request *ExecutionRequest) (*
 	case PaymentActionInitiate:
 		return t.initiateHandler(ctx, request.Content)
 	case PaymentActionSuccess:
-		return t.successHandler(ctx)
+		return t.successHandler(ctx, request.Content)
 	case PaymentActionFailed:
-		return t.failedHandler(ctx)
+		return t.failedHandler(ctx, request.Content)
+	case PaymentActionPartial:
+		return t.partialHandler(ctx, request.Content)
+	case PaymentActionRequestRefund:
+		return t.requestRefundHandler(ctx, request.Content)
+	case PaymentActionRefunded:
+		return t.refundedHandler(ctx, request.Content)
 	default:
 		return nil, fmt.Errorf("payment: unknown action %q", request.Action)
 	}
@@ -322,27 +432,26 @@ func (t *PaymentTask) initiateHandler(ctx context.Context, content any) (*Execut
 		}, fmt.Errorf("payment: session expired, cannot initiate payment")
 	}
 
-	// Extract methodId from content
-	methodID := "lankapay" // Default
+	// Extract methodId from content; the payment service falls back to the default method when empty.
+	methodID := ""
 	if contentMap, ok := content.(map[string]any); ok {
 		if id, ok := contentMap["methodId"].(string); ok {
 			methodID = id
 		}
 	}
 
-	_, totalAmount, err := t.calculateBreakdown(ctx)
+	_, totalAmount, feeResolutionID, err := t.calculateBreakdown(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("payment: failed to calculate total amount: %w", err)
 	}
 
-	// Create real checkout session via PaymentService
-	resp, err := t.paymentService.CreateCheckoutSession(ctx, payments.CreateCheckoutRequest{
-		Amount:          totalAmount,
-		Currency:        t.config.Currency,
-		ReferenceNumber: session.ReferenceNumber,
+	// Create real checkout session with the selected provider via PaymentService
+	resp, err := t.paymentService.CreateCheckoutSession(ctx, paymentsv2.CreateCheckoutRequest{
+		ProviderID: methodID,
+		Amount:     totalAmount,
+		Currency:   t.config.Currency,
 		Metadata: map[string]string{
 			"task_id":      t.api.GetTaskID(),
-			"method_id":    methodID,
 			"org_id":       t.config.OrgID,
 			"service_type": t.config.ServiceType,
 		},
@@ -363,9 +472,14 @@ func (t *PaymentTask) initiateHandler(ctx context.Context, content any) (*Execut
 		}
 	}
 
+	// The payment service issues the reference the gateway and webhooks will quote.
 	session.InitiatedAt = &now
 	session.CheckoutURL = resp.CheckoutURL
-	session.SelectedMethodID = methodID
+	session.SelectedMethodID = resp.ProviderID
+	session.FeeResolutionID = feeResolutionID
+	if resp.ReferenceNumber != "" {
+		session.ReferenceNumber = resp.ReferenceNumber
+	}
 	if err := t.api.WriteToLocalStore(paymentStoreSession, session); err != nil {
 		return nil, fmt.Errorf("payment: failed to persist initiated session: %w", err)
 	}
@@ -382,9 +496,10 @@ func (t *PaymentTask) initiateHandler(ctx context.Context, content any) (*Execut
 		ApiResponse: &ApiResponse{
 			Success: true,
 			Data: map[string]any{
-				"message":     "Payment initiated",
-				"checkoutUrl": resp.CheckoutURL,
-				"methodId":    methodID,
+				"message":         "Payment initiated",
+				"checkoutUrl":     resp.CheckoutURL,
+				"methodId":        resp.ProviderID,
+				"referenceNumber": session.ReferenceNumber,
 			},
 		},
 	}, nil
@@ -392,10 +507,39 @@ func (t *PaymentTask) initiateHandler(ctx context.Context, content any) (*Execut
 
 // ── Helpers ───────────────────────────────────────────────────────────────────
 
-func (t *PaymentTask) calculateBreakdown(ctx context.Context) ([]ResolvedBreakdownItem, decimal.Decimal, error) {
-	var resolved []ResolvedBreakdownItem
+// calculateBreakdown resolves the configured breakdown and its total. When the config references a
+// fee schedule, the schedule's lines come first and feeResolutionID identifies their resolution.
+func (t *PaymentTask) calculateBreakdown(ctx context.Context) (resolved []ResolvedBreakdownItem, total decimal.Decimal, feeResolutionID string, err error) {
 	subtotal := decimal.Zero
 
+	// Phase 0: Fee schedule lines
+	if t.config.FeeSchedule != nil {
+		resolution, err := t.resolveFeeSchedule(ctx)
+		if err != nil {
+			return nil, decimal.Zero, "", err
+		}
+		if resolution.Currency != t.config.Currency {
+			return nil, decimal.Zero, "", fmt.Errorf("payment: fee schedule %s charges in %s, task charges in %s",
+				resolution.ScheduleID, resolution.Currency, t.config.Currency)
+		}
+		for _, line := range resolution.Lines {
+			if line.Category == feeschedule.CategoryDeduction {
+				subtotal = subtotal.Sub(line.Amount)
+			} else {
+				subtotal = subtotal.Add(line.Amount)
+			}
+			resolved = append(resolved, ResolvedBreakdownItem{
+				Description: line.Description,
+				Category:    BreakdownCategory(line.Category),
+				Type:        TypeSchedule,
+				Quantity:    line.MeasureValue,
+				UnitPrice:   line.Rate,
+				Amount:      line.Amount,
+			})
+		}
+		feeResolutionID = resolution.ID
+	}
+
 	var finalTotal decimal.Decimal
 	// Phase 1: Fixed Items
 	for _, item := range t.config.Breakdown {
@@ -448,7 +592,37 @@ func (t *PaymentTask) calculateBreakdown(ctx context.Context) ([]ResolvedBreakdo
 		})
 	}
 
-	return resolved, finalTotal.Round(2), nil
+	return resolved, finalTotal.Round(2), feeResolutionID, nil
+}
+
+// resolveFeeSchedule returns the fee schedule resolution pinned to the current session, or
+// resolves the schedule afresh if payment has not been initiated in this session.
+func (t *PaymentTask) resolveFeeSchedule(ctx context.Context) (*feeschedule.Resolution, error) {
+	// Before Start there is no session, and nothing is pinned.
+	if session, err := t.readSession(ctx); err == nil && session.FeeResolutionID != "" {
+		resolution, err := t.feeSchedules.GetResolution(ctx, session.FeeResolutionID)
+		if err != nil {
+			return nil, fmt.Errorf("payment: failed to load fee resolution: %w", err)
+		}
+		return resolution, nil
+	}
+
+	fs := t.config.FeeSchedule
+	resolution, err := t.feeSchedules.Resolve(ctx, feeschedule.ResolveRequest{
+		ScheduleID: fs.ID,
+		TaskID:     t.api.GetTaskID(),
+		Inputs: feeschedule.Inputs{
+			HSCode:        t.resolveString(fs.HSCode),
+			Quantity:      t.resolveValue(fs.Quantity, decimal.Zero),
+			Weight:        t.resolveValue(fs.Weight, decimal.Zero),
+			Value:         t.resolveValue(fs.Value, decimal.Zero),
+			ValueCurrency: t.resolveString(fs.ValueCurrency),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("payment: failed to resolve fee schedule %s: %w", fs.ID, err)
+	}
+	return resolution, nil
 }
 
 func (t *PaymentTask) resolveValue(val string, fallback decimal.Decimal) decimal.Decimal {
@@ -496,65 +670,57 @@ func (t *PaymentTask) resolveValue(val string, fallback decimal.Decimal) decimal
 }
 
 func (t *PaymentTask) resolveString(val string) string {
-	// Simple regex-free placeholder replacement
-	for {
-		start := strings.Index(val, "{")
-		end := strings.Index(val, "}")
-		if start == -1 || end == -1 || end < start {
-			break
-		}
-
-		placeholder := val[start : end+1]
-		inner := val[start+1 : end]
-		parts := strings.Split(inner, ":")
-		path := parts[0]
-
-		resolved := t.lookupGlobal(path)
-		replacement := ""
-		if resolved != nil {
-			replacement = fmt.Sprintf("%v", resolved)
-		} else if len(parts) > 1 {
-			replacement = parts[1]
-		}
-
-		val = strings.Replace(val, placeholder, replacement, 1)
-	}
-	return val
+	return resolvePlaceholders(t.api, val)
 }
 
 func (t *PaymentTask) lookupGlobal(path string) any {
-	keys := strings.Split(path, ".")
-	val, ok := t.api.ReadFromGlobalStore(keys[0])
-	if !ok {
-		return nil
-	}
-
-	current := val
-	for i := 1; i < len(keys); i++ {
-		if m, ok := current.(map[string]any); ok {
-			current, ok = m[keys[i]]
-			if !ok {
-				return nil
-			}
-		} else {
-			return nil
-		}
-	}
-	return current
+	return lookupGlobal(t.api, path)
 }
 
-// successHandler processes PAYMENT_SUCCESS: transitions to COMPLETED.
-func (t *PaymentTask) successHandler(_ context.Context) (*ExecutionResponse, error) {
+// successHandler processes PAYMENT_SUCCESS: transitions to COMPLETED. The gateway result's paid
+// amount and currency must match what the task charges, and a result redelivered after
+// completion is acknowledged without changing anything.
+func (t *PaymentTask) successHandler(ctx context.Context, content any) (*ExecutionResponse, error) {
+	result, err := parseGatewayResult(content)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("payment: %s requires a gateway result", PaymentActionSuccess)
+	}
+	if t.api.GetPluginState() == string(paymentCompleted) {
+		return gatewayResultIgnored("Payment already completed"), nil
+	}
+
 	if !t.api.CanTransition(PaymentActionSuccess) {
 		return nil, fmt.Errorf("payment: action %q not permitted in state %q",
 			PaymentActionSuccess, t.api.GetPluginState())
 	}
 
+	_, totalAmount, _, err := t.calculateBreakdown(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("payment: failed to calculate total amount: %w", err)
+	}
+	if !result.Amount.Equal(totalAmount) || result.Currency != t.config.Currency {
+		return nil, fmt.Errorf("payment: gateway reported %s %s for %s, expected %s %s",
+			result.Amount, result.Currency, result.ReferenceNumber, totalAmount, t.config.Currency)
+	}
+
+	var outputs map[string]any
+	if t.config.OutputKey != "" {
+		paid, err := t.paidOutput(ctx)
+		if err != nil {
+			return nil, err
+		}
+		outputs = map[string]any{t.config.OutputKey: paid}
+	}
+
 	if err := t.api.Transition(PaymentActionSuccess); err != nil {
 		return nil, err
 	}
 
 	return &ExecutionResponse{
+		Outputs: outputs,
 		Message: "Payment completed successfully",
 		ApiResponse: &ApiResponse{
 			Success: true,
@@ -563,18 +729,47 @@ func (t *PaymentTask) successHandler(_ context.Context) (*ExecutionResponse, err
 	}, nil
 }
 
-// failedHandler processes PAYMENT_FAILED: records the failed transaction,
-// generates a new session, and transitions back to IDLE.
-func (t *PaymentTask) failedHandler(ctx context.Context) (*ExecutionResponse, error) {
-	if !t.api.CanTransition(PaymentActionFailed) {
-		return nil, fmt.Errorf("payment: action %q not permitted in state %q",
-			PaymentActionFailed, t.api.GetPluginState())
+// paidOutput describes the completed payment for the workflow.
+func (t *PaymentTask) paidOutput(ctx context.Context) (map[string]any, error) {
+	session, err := t.readSession(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("payment: failed to read session: %w", err)
 	}
+	_, totalAmount, _, err := t.calculateBreakdown(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("payment: failed to calculate total amount: %w", err)
+	}
+	return map[string]any{
+		"referenceNumber": session.ReferenceNumber,
+		"amount":          totalAmount.StringFixed(2),
+		"currency":        t.config.Currency,
+		"paidAt":          time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
 
+// failedHandler processes PAYMENT_FAILED: records the failed transaction,
+// generates a new session, and transitions back to IDLE. A gateway result only fails the
+// current session; one for an earlier or already failed session is acknowledged and ignored.
+func (t *PaymentTask) failedHandler(ctx context.Context, content any) (*ExecutionResponse, error) {
+	result, err := parseGatewayResult(content)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("payment: %s requires a gateway result", PaymentActionFailed)
+	}
 	session, err := t.readSession(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("payment: failed to read session: %w", err)
 	}
+	if session.ReferenceNumber != result.ReferenceNumber {
+		return gatewayResultIgnored("Payment session is no longer active"), nil
+	}
+
+	if !t.api.CanTransition(PaymentActionFailed) {
+		return nil, fmt.Errorf("payment: action %q not permitted in state %q",
+			PaymentActionFailed, t.api.GetPluginState())
+	}
 
 	// Record the failed transaction in history.
 	initiatedAt := time.Now()
@@ -604,8 +799,163 @@ func (t *PaymentTask) failedHandler(ctx context.Context) (*ExecutionResponse, er
 	}, nil
 }
 
+// partialHandler processes PAYMENT_PARTIAL: the gateway received part of the amount for the
+// current session. Later instalments are paid against the same reference.
+func (t *PaymentTask) partialHandler(ctx context.Context, content any) (*ExecutionResponse, error) {
+	result, err := parseGatewayResult(content)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("payment: %s requires a gateway result", PaymentActionPartial)
+	}
+	if t.api.GetPluginState() == string(paymentPartiallyPaid) {
+		return gatewayResultIgnored("Partial payment already recorded"), nil
+	}
+	session, err := t.readSession(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("payment: failed to read session: %w", err)
+	}
+	if session.ReferenceNumber != result.ReferenceNumber {
+		return gatewayResultIgnored("Payment session is no longer active"), nil
+	}
+
+	if !t.api.CanTransition(PaymentActionPartial) {
+		return nil, fmt.Errorf("payment: action %q not permitted in state %q",
+			PaymentActionPartial, t.api.GetPluginState())
+	}
+	if err := t.api.Transition(PaymentActionPartial); err != nil {
+		return nil, err
+	}
+
+	return &ExecutionResponse{
+		Message: "Payment partially received",
+		ApiResponse: &ApiResponse{
+			Success: true,
+			Data:    map[string]any{"message": "Payment partially received. The balance can be paid with the same reference."},
+		},
+	}, nil
+}
+
+// requestRefundHandler processes REQUEST_REFUND: asks the payment service to refund the paid
+// session and waits in REFUND_REQUESTED for the gateway to confirm.
+func (t *PaymentTask) requestRefundHandler(ctx context.Context, content any) (*ExecutionResponse, error) {
+	if !t.api.CanTransition(PaymentActionRequestRefund) {
+		return nil, fmt.Errorf("payment: action %q not permitted in state %q",
+			PaymentActionRequestRefund, t.api.GetPluginState())
+	}
+
+	var req PaymentRefundRequest
+	if content != nil {
+		b, err := json.Marshal(content)
+		if err != nil {
+			return nil, fmt.Errorf("payment: failed to marshal refund request: %w", err)
+		}
+		if err := json.Unmarshal(b, &req); err != nil {
+			return nil, fmt.Errorf("payment: invalid refund request: %w", err)
+		}
+	}
+
+	session, err := t.readSession(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("payment: failed to read session: %w", err)
+	}
+	if _, err := t.paymentService.RequestRefund(ctx, session.ReferenceNumber, req.Amount, req.Reason); err != nil {
+		return nil, fmt.Errorf("payment: failed to request refund: %w", err)
+	}
+
+	if err := t.api.Transition(PaymentActionRequestRefund); err != nil {
+		return nil, err
+	}
+
+	return &ExecutionResponse{
+		Message: "Refund requested",
+		ApiResponse: &ApiResponse{
+			Success: true,
+			Data:    map[string]any{"message": "Refund requested", "referenceNumber": session.ReferenceNumber},
+		},
+	}, nil
+}
+
+// refundedHandler processes PAYMENT_REFUNDED: the gateway confirmed a refund, either the one the
+// task requested or one issued directly at the gateway.
+func (t *PaymentTask) refundedHandler(_ context.Context, content any) (*ExecutionResponse, error) {
+	result, err := parseGatewayResult(content)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil && t.api.GetPluginState() == string(paymentRefunded) {
+		return gatewayResultIgnored("Refund already recorded"), nil
+	}
+
+	if !t.api.CanTransition(PaymentActionRefunded) {
+		return nil, fmt.Errorf("payment: action %q not permitted in state %q",
+			PaymentActionRefunded, t.api.GetPluginState())
+	}
+	if err := t.api.Transition(PaymentActionRefunded); err != nil {
+		return nil, err
+	}
+
+	return &ExecutionResponse{
+		Message: "Payment refunded",
+		ApiResponse: &ApiResponse{
+			Success: true,
+			Data:    map[string]any{"message": "Payment refunded"},
+		},
+	}, nil
+}
+
 // ── Helpers ───────────────────────────────────────────────────────────────────
 
+// addLedger fills in what has been paid and refunded for the task. The ledger is informational,
+// so a failure to read it is logged rather than failing the render.
+func (t *PaymentTask) addLedger(ctx context.Context, content *PaymentRenderContent) {
+	ledger, err := t.paymentService.GetTaskLedger(ctx, t.api.GetTaskID())
+	if err != nil {
+		slog.WarnContext(ctx, "payment: failed to read payment ledger", "taskID", t.api.GetTaskID(), "error", err)
+		return
+	}
+	content.AmountPaid = &ledger.Paid
+	content.AmountRefunded = &ledger.Refunded
+	content.NetAmount = &ledger.Net
+}
+
+// parseGatewayResult decodes the gateway result carried by content. It returns nil when the
+// action was sent without one.
+func parseGatewayResult(content any) (*PaymentGatewayResult, error) {
+	switch c := content.(type) {
+	case nil:
+		return nil, nil
+	case PaymentGatewayResult:
+		return &c, nil
+	case *PaymentGatewayResult:
+		return c, nil
+	}
+	b, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("payment: failed to marshal gateway result: %w", err)
+	}
+	var result PaymentGatewayResult
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("payment: failed to unmarshal gateway result: %w", err)
+	}
+	if result.ReferenceNumber == "" {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+// gatewayResultIgnored acknowledges a gateway result that does not change the task.
+func gatewayResultIgnored(message string) *ExecutionResponse {
+	return &ExecutionResponse{
+		Message: message,
+		ApiResponse: &ApiResponse{
+			Success: true,
+			Data:    map[string]any{"message": message},
+		},
+	}
+}
+
 // newSession creates a fresh PaymentSession with a new UUID and the current timestamp.
 func (t *PaymentTask) newSession() PaymentSession {
 	return PaymentSession{
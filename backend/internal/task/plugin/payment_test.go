@@ -7,38 +7,92 @@ import (
 	"testing"
 	"time"
 
-	"github.com/OpenNSW/nsw/internal/payments"
+	"github.com/OpenNSW/nsw/internal/feeschedule"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-// MockPaymentService is a mock implementation of the payments.PaymentService interface
+// MockPaymentService is a mock implementation of the paymentsv2.PaymentService interface
 type MockPaymentService struct {
 	mock.Mock
 }
 
-func (m *MockPaymentService) CreateCheckoutSession(ctx context.Context, req payments.CreateCheckoutRequest) (*payments.CreateCheckoutResponse, error) {
-	args := m.Called(ctx, req)
+func (m *MockPaymentService) ListAvailableMethods(ctx context.Context) ([]paymentsv2.PaymentProviderInfo, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*payments.CreateCheckoutResponse), args.Error(1)
+	return args.Get(0).([]paymentsv2.PaymentProviderInfo), args.Error(1)
 }
 
-func (m *MockPaymentService) ValidateReference(ctx context.Context, req payments.ValidateReferenceRequest) (*payments.ValidateReferenceResponse, error) {
+func (m *MockPaymentService) CreateCheckoutSession(ctx context.Context, req paymentsv2.CreateCheckoutRequest) (*paymentsv2.CreateCheckoutResponse, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*payments.ValidateReferenceResponse), args.Error(1)
+	return args.Get(0).(*paymentsv2.CreateCheckoutResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) ValidateReference(ctx context.Context, providerID string, req paymentsv2.ValidateReferenceRequest) (*paymentsv2.ValidateReferenceResponse, error) {
+	args := m.Called(ctx, providerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*paymentsv2.ValidateReferenceResponse), args.Error(1)
 }
 
-func (m *MockPaymentService) ProcessWebhook(ctx context.Context, payload payments.WebhookPayload) error {
-	args := m.Called(ctx, payload)
+func (m *MockPaymentService) ProcessWebhook(ctx context.Context, providerID string, body []byte, headers map[string][]string) error {
+	args := m.Called(ctx, providerID, body, headers)
 	return args.Error(0)
 }
 
+func (m *MockPaymentService) RequestRefund(ctx context.Context, referenceNumber string, amount decimal.Decimal, reason string) (*paymentsv2.PaymentTransaction, error) {
+	args := m.Called(ctx, referenceNumber, amount, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*paymentsv2.PaymentTransaction), args.Error(1)
+}
+
+func (m *MockPaymentService) GetTaskLedger(ctx context.Context, taskID string) (*paymentsv2.TaskLedger, error) {
+	args := m.Called(ctx, taskID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*paymentsv2.TaskLedger), args.Error(1)
+}
+
+func (m *MockPaymentService) RegisterOutcomeHandler(handler paymentsv2.OutcomeHandler) {
+	m.Called(handler)
+}
+
+func (m *MockPaymentService) RegisterNameResolver(resolver paymentsv2.NameResolver) {
+	m.Called(resolver)
+}
+
+// MockFeeSchedules is a mock implementation of the feeschedule.Resolver interface
+type MockFeeSchedules struct {
+	mock.Mock
+}
+
+func (m *MockFeeSchedules) Resolve(ctx context.Context, req feeschedule.ResolveRequest) (*feeschedule.Resolution, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*feeschedule.Resolution), args.Error(1)
+}
+
+func (m *MockFeeSchedules) GetResolution(ctx context.Context, id string) (*feeschedule.Resolution, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*feeschedule.Resolution), args.Error(1)
+}
+
 // ── FSM Tests ─────────────────────────────────────────────────────────────────
 
 func TestNewPaymentFSM(t *testing.T) {
@@ -57,12 +111,21 @@ func TestNewPaymentFSM(t *testing.T) {
 		{"SUCCESS from IN_PROGRESS", "IN_PROGRESS", PaymentActionSuccess, "COMPLETED", Completed, true},
 		{"FAILED from IN_PROGRESS", "IN_PROGRESS", PaymentActionFailed, "IDLE", Initialized, true},
 		{"TIMEOUT from IN_PROGRESS", "IN_PROGRESS", paymentFSMTimeout, "IDLE", Initialized, true},
+		{"PARTIAL from IN_PROGRESS", "IN_PROGRESS", PaymentActionPartial, "PARTIALLY_PAID", InProgress, true},
+		{"SUCCESS from PARTIALLY_PAID", "PARTIALLY_PAID", PaymentActionSuccess, "COMPLETED", Completed, true},
+		{"REQUEST_REFUND from COMPLETED", "COMPLETED", PaymentActionRequestRefund, "REFUND_REQUESTED", "", true},
+		{"REFUNDED from REFUND_REQUESTED", "REFUND_REQUESTED", PaymentActionRefunded, "REFUNDED", "", true},
+		{"REQUEST_REFUND from REFUNDED", "REFUNDED", PaymentActionRequestRefund, "REFUND_REQUESTED", "", true},
+		{"REFUNDED from COMPLETED", "COMPLETED", PaymentActionRefunded, "REFUNDED", "", true},
 
 		// Invalid transitions
 		{"INITIATE from empty", "", PaymentActionInitiate, "", "", false},
 		{"SUCCESS from IDLE", "IDLE", PaymentActionSuccess, "", "", false},
 		{"FAILED from IDLE", "IDLE", PaymentActionFailed, "", "", false},
 		{"INITIATE from COMPLETED", "COMPLETED", PaymentActionInitiate, "", "", false},
+		{"INITIATE from PARTIALLY_PAID", "PARTIALLY_PAID", PaymentActionInitiate, "", "", false},
+		{"REQUEST_REFUND from IN_PROGRESS", "IN_PROGRESS", PaymentActionRequestRefund, "", "", false},
+		{"REQUEST_REFUND from REFUND_REQUESTED", "REFUND_REQUESTED", PaymentActionRequestRefund, "", "", false},
 	}
 
 	for _, tt := range tests {
@@ -86,7 +149,7 @@ func TestNewPaymentTask(t *testing.T) {
 	mockSvc := new(MockPaymentService)
 	t.Run("ValidConfig", func(t *testing.T) {
 		cfg := `{"currency": "USD", "ttl": 300, "orgId": "CUSTOMS", "breakdown": [{"description": "Fee", "category": "ADDITION", "type": "FIXED", "unitPrice": "100.50"}]}`
-		task, err := NewPaymentTask(json.RawMessage(cfg), mockSvc)
+		task, err := NewPaymentTask(json.RawMessage(cfg), mockSvc, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, "USD", task.config.Currency)
 		assert.Equal(t, 300, task.config.TTL)
@@ -96,10 +159,23 @@ func TestNewPaymentTask(t *testing.T) {
 	})
 
 	t.Run("InvalidJSON", func(t *testing.T) {
-		_, err := NewPaymentTask(json.RawMessage(`{invalid`), mockSvc)
+		_, err := NewPaymentTask(json.RawMessage(`{invalid`), mockSvc, nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid config")
 	})
+
+	t.Run("FeeSchedule", func(t *testing.T) {
+		cfg := `{"currency": "LKR", "feeSchedule": {"id": "customs", "hsCode": "{consignment.hsCode}"}}`
+		task, err := NewPaymentTask(json.RawMessage(cfg), mockSvc, new(MockFeeSchedules))
+		assert.NoError(t, err)
+		assert.Equal(t, "customs", task.config.FeeSchedule.ID)
+
+		_, err = NewPaymentTask(json.RawMessage(cfg), mockSvc, nil)
+		assert.ErrorContains(t, err, "no fee schedule resolver")
+
+		_, err = NewPaymentTask(json.RawMessage(`{"feeSchedule": {}}`), mockSvc, new(MockFeeSchedules))
+		assert.ErrorContains(t, err, "fee schedule ID is required")
+	})
 }
 
 // ── Start Tests ───────────────────────────────────────────────────────────────
@@ -226,6 +302,11 @@ func TestPaymentGetRenderInfo_Completed(t *testing.T) {
 
 	mockAPI.On("GetPluginState").Return("COMPLETED")
 	mockAPI.On("GetTaskState").Return(Completed)
+	mockAPI.On("GetTaskID").Return("task-123")
+	mockSvc.On("GetTaskLedger", mock.Anything, "task-123").Return(&paymentsv2.TaskLedger{
+		TaskID: "task-123", Currency: "USD",
+		Paid: decimal.NewFromInt(120), Refunded: decimal.NewFromInt(20), Net: decimal.NewFromInt(100),
+	}, nil)
 
 	resp, err := task.GetRenderInfo(context.Background())
 
@@ -237,8 +318,30 @@ func TestPaymentGetRenderInfo_Completed(t *testing.T) {
 	assert.True(t, decimal.NewFromFloat(100.0).Equal(content.TotalAmount))
 	assert.Equal(t, "USD", content.Currency)
 	assert.Equal(t, "COMPLETED", data.PluginState)
+	assert.True(t, decimal.NewFromInt(120).Equal(*content.AmountPaid))
+	assert.True(t, decimal.NewFromInt(100).Equal(*content.NetAmount))
 
 	mockAPI.AssertExpectations(t)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestPaymentGetRenderInfo_LedgerUnavailable(t *testing.T) {
+	mockAPI := new(MockAPI)
+	mockSvc := new(MockPaymentService)
+	task := newTestPaymentTask(mockSvc)
+	task.Init(mockAPI)
+
+	mockAPI.On("GetPluginState").Return("REFUND_REQUESTED")
+	mockAPI.On("GetTaskState").Return(Completed)
+	mockAPI.On("GetTaskID").Return("task-123")
+	mockSvc.On("GetTaskLedger", mock.Anything, "task-123").Return(nil, errors.New("db down"))
+
+	resp, err := task.GetRenderInfo(context.Background())
+
+	assert.NoError(t, err)
+	content := resp.Data.(GetRenderInfoResponse).Content.(PaymentRenderContent)
+	assert.Nil(t, content.NetAmount)
+	mockSvc.AssertExpectations(t)
 }
 
 func TestPaymentGetRenderInfo_SessionRotation(t *testing.T) {
@@ -385,11 +488,14 @@ func TestPaymentExecute_InitiatePayment(t *testing.T) {
 		mockAPI.On("GetTaskID").Return("task-123").Once()
 		mockAPI.On("GetPluginState").Return("IDLE").Once()
 
-		mockSvc.On("CreateCheckoutSession", mock.Anything, mock.MatchedBy(func(req payments.CreateCheckoutRequest) bool {
-			return req.Amount.Equal(decimal.NewFromFloat(100.0)) && req.Currency == "USD"
-		})).Return(&payments.CreateCheckoutResponse{
-			SessionID:   "sess-123",
-			CheckoutURL: "https://pay.example.com/sess-123",
+		mockSvc.On("CreateCheckoutSession", mock.Anything, mock.MatchedBy(func(req paymentsv2.CreateCheckoutRequest) bool {
+			return req.Amount.Equal(decimal.NewFromFloat(100.0)) && req.Currency == "USD" &&
+				req.ProviderID == "" && req.Metadata["task_id"] == "task-123"
+		})).Return(&paymentsv2.CreateCheckoutResponse{
+			ProviderID:      "mock",
+			ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+			SessionID:       "sess-123",
+			CheckoutURL:     "https://pay.example.com/sess-123",
 		}, nil).Once()
 
 		var capturedSession *PaymentSession
@@ -415,11 +521,46 @@ func TestPaymentExecute_InitiatePayment(t *testing.T) {
 		// Verify InitiatedAt was set on the session.
 		assert.NotNil(t, capturedSession.InitiatedAt)
 		assert.Equal(t, "https://pay.example.com/sess-123", capturedSession.CheckoutURL)
+		// The session adopts the provider and reference issued by the payment service.
+		assert.Equal(t, "mock", capturedSession.SelectedMethodID)
+		assert.Equal(t, "NSW-PR-2026-ABCDEFGH", capturedSession.ReferenceNumber)
 
 		mockAPI.AssertExpectations(t)
 		mockSvc.AssertExpectations(t)
 	})
 
+	t.Run("SelectedMethod", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		mockSvc := new(MockPaymentService)
+		task := newTestPaymentTask(mockSvc)
+		task.Init(mockAPI)
+
+		session := PaymentSession{TransactionID: "txn-789", GeneratedAt: time.Now()}
+
+		mockAPI.On("CanTransition", PaymentActionInitiate).Return(true).Once()
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(session, nil).Once()
+		mockAPI.On("GetTaskID").Return("task-123").Once()
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS").Once()
+		mockSvc.On("CreateCheckoutSession", mock.Anything, mock.MatchedBy(func(req paymentsv2.CreateCheckoutRequest) bool {
+			return req.ProviderID == "lankapay"
+		})).Return(&paymentsv2.CreateCheckoutResponse{
+			ProviderID:      "lankapay",
+			ReferenceNumber: "NSW-PR-2026-LANKAPAY",
+			CheckoutURL:     "https://lankapay.example.com/sess",
+		}, nil).Once()
+		mockAPI.On("WriteToLocalStore", paymentStoreSession, mock.AnythingOfType("*plugin.PaymentSession")).Return(nil).Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{
+			Action:  PaymentActionInitiate,
+			Content: map[string]any{"methodId": "lankapay"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "lankapay", resp.ApiResponse.Data.(map[string]any)["methodId"])
+		mockAPI.AssertExpectations(t)
+		mockSvc.AssertExpectations(t)
+	})
+
 	t.Run("ExpiredSession", func(t *testing.T) {
 		mockAPI := new(MockAPI)
 		mockSvc := new(MockPaymentService)
@@ -475,24 +616,20 @@ func TestPaymentExecute_InitiatePayment(t *testing.T) {
 }
 
 func TestPaymentExecute_PaymentSuccess(t *testing.T) {
-	t.Run("Success", func(t *testing.T) {
+	paid := PaymentGatewayResult{ReferenceNumber: "NSW-PR-2026-ABCDEFGH", Amount: decimal.NewFromInt(100), Currency: "USD"}
+
+	t.Run("RequiresGatewayResult", func(t *testing.T) {
 		mockAPI := new(MockAPI)
-		mockSvc := new(MockPaymentService)
-		task := newTestPaymentTask(mockSvc)
+		task := newTestPaymentTask(new(MockPaymentService))
 		task.Init(mockAPI)
 
-		mockAPI.On("CanTransition", PaymentActionSuccess).Return(true).Once()
-		mockAPI.On("Transition", PaymentActionSuccess).Return(nil).Once()
-
-		req := &ExecutionRequest{Action: PaymentActionSuccess}
-		resp, err := task.Execute(context.Background(), req)
-
-		assert.NoError(t, err)
-		assert.NotNil(t, resp)
-		assert.Equal(t, "Payment completed successfully", resp.Message)
-		assert.True(t, resp.ApiResponse.Success)
+		// Only the payment service, reporting a gateway webhook, may complete a payment.
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionSuccess})
 
-		mockAPI.AssertExpectations(t)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "requires a gateway result")
+		assert.Nil(t, resp)
+		mockAPI.AssertNotCalled(t, "Transition", PaymentActionSuccess)
 	})
 
 	t.Run("InvalidTransition", func(t *testing.T) {
@@ -501,10 +638,10 @@ func TestPaymentExecute_PaymentSuccess(t *testing.T) {
 		task := newTestPaymentTask(mockSvc)
 		task.Init(mockAPI)
 
+		mockAPI.On("GetPluginState").Return("IDLE").Twice()
 		mockAPI.On("CanTransition", PaymentActionSuccess).Return(false).Once()
-		mockAPI.On("GetPluginState").Return("IDLE").Once()
 
-		req := &ExecutionRequest{Action: PaymentActionSuccess}
+		req := &ExecutionRequest{Action: PaymentActionSuccess, Content: paid}
 		resp, err := task.Execute(context.Background(), req)
 
 		assert.Error(t, err)
@@ -520,10 +657,11 @@ func TestPaymentExecute_PaymentSuccess(t *testing.T) {
 		task := newTestPaymentTask(mockSvc)
 		task.Init(mockAPI)
 
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS").Once()
 		mockAPI.On("CanTransition", PaymentActionSuccess).Return(true).Once()
 		mockAPI.On("Transition", PaymentActionSuccess).Return(errors.New("transition failed")).Once()
 
-		req := &ExecutionRequest{Action: PaymentActionSuccess}
+		req := &ExecutionRequest{Action: PaymentActionSuccess, Content: paid}
 		resp, err := task.Execute(context.Background(), req)
 
 		assert.Error(t, err)
@@ -532,9 +670,130 @@ func TestPaymentExecute_PaymentSuccess(t *testing.T) {
 
 		mockAPI.AssertExpectations(t)
 	})
+
+	t.Run("GatewayResult", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS").Once()
+		mockAPI.On("CanTransition", PaymentActionSuccess).Return(true).Once()
+		mockAPI.On("Transition", PaymentActionSuccess).Return(nil).Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{
+			Action: PaymentActionSuccess,
+			Content: PaymentGatewayResult{
+				ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+				Amount:          decimal.NewFromInt(100),
+				Currency:        "USD",
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Payment completed successfully", resp.Message)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("GatewayAmountMismatch", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS").Once()
+		mockAPI.On("CanTransition", PaymentActionSuccess).Return(true).Once()
+
+		// Content decoded from JSON arrives as a map.
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{
+			Action:  PaymentActionSuccess,
+			Content: map[string]any{"referenceNumber": "NSW-PR-2026-ABCDEFGH", "amount": "90", "currency": "USD"},
+		})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected 100 USD")
+		assert.Nil(t, resp)
+		mockAPI.AssertNotCalled(t, "Transition", PaymentActionSuccess)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("GatewayRedeliveryAfterCompletion", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("COMPLETED").Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{
+			Action:  PaymentActionSuccess,
+			Content: PaymentGatewayResult{ReferenceNumber: "NSW-PR-2026-ABCDEFGH", Amount: decimal.NewFromInt(100), Currency: "USD"},
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.ApiResponse.Success)
+		assert.Equal(t, "Payment already completed", resp.Message)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("OutputKey", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.config.OutputKey = "payment"
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS").Once()
+		mockAPI.On("CanTransition", PaymentActionSuccess).Return(true).Once()
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(PaymentSession{ReferenceNumber: "NSW-PR-2026-ABCDEFGH"}, nil).Once()
+		mockAPI.On("Transition", PaymentActionSuccess).Return(nil).Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionSuccess, Content: paid})
+
+		assert.NoError(t, err)
+		paid, ok := resp.Outputs["payment"].(map[string]any)
+		assert.True(t, ok)
+		assert.Equal(t, "NSW-PR-2026-ABCDEFGH", paid["referenceNumber"])
+		assert.Equal(t, "100.00", paid["amount"])
+		assert.Equal(t, "USD", paid["currency"])
+		assert.NotEmpty(t, paid["paidAt"])
+		mockAPI.AssertExpectations(t)
+	})
 }
 
 func TestPaymentExecute_PaymentFailed(t *testing.T) {
+	failed := PaymentGatewayResult{ReferenceNumber: "NSW-PR-2026-ABCDEFGH"}
+
+	t.Run("RequiresGatewayResult", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionFailed})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "requires a gateway result")
+		assert.Nil(t, resp)
+		mockAPI.AssertNotCalled(t, "Transition", PaymentActionFailed)
+	})
+
+	t.Run("GatewayResultForInactiveSession", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		// The session was rotated by an earlier failure, so this result is a redelivery.
+		session := PaymentSession{TransactionID: "txn-790", ReferenceNumber: "NSW-PAY-abcd1234", GeneratedAt: time.Now()}
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(session, nil).Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{
+			Action:  PaymentActionFailed,
+			Content: PaymentGatewayResult{ReferenceNumber: "NSW-PR-2026-ABCDEFGH"},
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.ApiResponse.Success)
+		assert.Equal(t, "Payment session is no longer active", resp.Message)
+		mockAPI.AssertNotCalled(t, "CanTransition", PaymentActionFailed)
+		mockAPI.AssertExpectations(t)
+	})
+
 	t.Run("Success", func(t *testing.T) {
 		mockAPI := new(MockAPI)
 		mockSvc := new(MockPaymentService)
@@ -543,9 +802,10 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 
 		initiatedAt := time.Now().Add(-2 * time.Minute)
 		session := PaymentSession{
-			TransactionID: "txn-789",
-			GeneratedAt:   time.Now().Add(-3 * time.Minute),
-			InitiatedAt:   &initiatedAt,
+			TransactionID:   "txn-789",
+			ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+			GeneratedAt:     time.Now().Add(-3 * time.Minute),
+			InitiatedAt:     &initiatedAt,
 		}
 
 		mockAPI.On("CanTransition", PaymentActionFailed).Return(true).Once()
@@ -563,7 +823,7 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 		mockAPI.On("WriteToLocalStore", paymentStoreSession, mock.AnythingOfType("*plugin.PaymentSession")).Return(nil).Once()
 		mockAPI.On("Transition", PaymentActionFailed).Return(nil).Once()
 
-		req := &ExecutionRequest{Action: PaymentActionFailed}
+		req := &ExecutionRequest{Action: PaymentActionFailed, Content: failed}
 		resp, err := task.Execute(context.Background(), req)
 
 		assert.NoError(t, err)
@@ -588,16 +848,17 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 
 		initiatedAt := time.Now().Add(-2 * time.Minute)
 		session := PaymentSession{
-			TransactionID: "txn-789",
-			GeneratedAt:   time.Now().Add(-3 * time.Minute),
-			InitiatedAt:   &initiatedAt,
+			TransactionID:   "txn-789",
+			ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+			GeneratedAt:     time.Now().Add(-3 * time.Minute),
+			InitiatedAt:     &initiatedAt,
 		}
 
 		mockAPI.On("CanTransition", PaymentActionFailed).Return(true).Once()
 		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(session, nil).Once()
 		mockAPI.On("ReadFromLocalStore", paymentStoreTransactions).Return(nil, errors.New("read history failed")).Once()
 
-		req := &ExecutionRequest{Action: PaymentActionFailed}
+		req := &ExecutionRequest{Action: PaymentActionFailed, Content: failed}
 		resp, err := task.Execute(context.Background(), req)
 
 		assert.Error(t, err)
@@ -615,9 +876,10 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 
 		initiatedAt := time.Now().Add(-2 * time.Minute)
 		session := PaymentSession{
-			TransactionID: "txn-789",
-			GeneratedAt:   time.Now().Add(-3 * time.Minute),
-			InitiatedAt:   &initiatedAt,
+			TransactionID:   "txn-789",
+			ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+			GeneratedAt:     time.Now().Add(-3 * time.Minute),
+			InitiatedAt:     &initiatedAt,
 		}
 
 		mockAPI.On("CanTransition", PaymentActionFailed).Return(true).Once()
@@ -625,7 +887,7 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 		mockAPI.On("ReadFromLocalStore", paymentStoreTransactions).Return(nil, nil).Once()
 		mockAPI.On("WriteToLocalStore", paymentStoreTransactions, mock.AnythingOfType("[]plugin.PaymentTransaction")).Return(errors.New("write history failed")).Once()
 
-		req := &ExecutionRequest{Action: PaymentActionFailed}
+		req := &ExecutionRequest{Action: PaymentActionFailed, Content: failed}
 		resp, err := task.Execute(context.Background(), req)
 
 		assert.Error(t, err)
@@ -643,9 +905,10 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 
 		initiatedAt := time.Now().Add(-2 * time.Minute)
 		session := PaymentSession{
-			TransactionID: "txn-789",
-			GeneratedAt:   time.Now().Add(-3 * time.Minute),
-			InitiatedAt:   &initiatedAt,
+			TransactionID:   "txn-789",
+			ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+			GeneratedAt:     time.Now().Add(-3 * time.Minute),
+			InitiatedAt:     &initiatedAt,
 		}
 
 		mockAPI.On("CanTransition", PaymentActionFailed).Return(true).Once()
@@ -654,7 +917,7 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 		mockAPI.On("WriteToLocalStore", paymentStoreTransactions, mock.AnythingOfType("[]plugin.PaymentTransaction")).Return(nil).Once()
 		mockAPI.On("WriteToLocalStore", paymentStoreSession, mock.AnythingOfType("*plugin.PaymentSession")).Return(errors.New("persist session failed")).Once()
 
-		req := &ExecutionRequest{Action: PaymentActionFailed}
+		req := &ExecutionRequest{Action: PaymentActionFailed, Content: failed}
 		resp, err := task.Execute(context.Background(), req)
 
 		assert.Error(t, err)
@@ -672,9 +935,10 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 
 		initiatedAt := time.Now().Add(-2 * time.Minute)
 		session := PaymentSession{
-			TransactionID: "txn-789",
-			GeneratedAt:   time.Now().Add(-3 * time.Minute),
-			InitiatedAt:   &initiatedAt,
+			TransactionID:   "txn-789",
+			ReferenceNumber: "NSW-PR-2026-ABCDEFGH",
+			GeneratedAt:     time.Now().Add(-3 * time.Minute),
+			InitiatedAt:     &initiatedAt,
 		}
 
 		mockAPI.On("CanTransition", PaymentActionFailed).Return(true).Once()
@@ -684,7 +948,7 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 		mockAPI.On("WriteToLocalStore", paymentStoreSession, mock.AnythingOfType("*plugin.PaymentSession")).Return(nil).Once()
 		mockAPI.On("Transition", PaymentActionFailed).Return(errors.New("transition failed")).Once()
 
-		req := &ExecutionRequest{Action: PaymentActionFailed}
+		req := &ExecutionRequest{Action: PaymentActionFailed, Content: failed}
 		resp, err := task.Execute(context.Background(), req)
 
 		assert.Error(t, err)
@@ -695,6 +959,155 @@ func TestPaymentExecute_PaymentFailed(t *testing.T) {
 	})
 }
 
+func TestPaymentExecute_PaymentPartial(t *testing.T) {
+	session := PaymentSession{TransactionID: "txn-1", ReferenceNumber: "NSW-PR-2026-ABCDEFGH", GeneratedAt: time.Now()}
+	partial := PaymentGatewayResult{ReferenceNumber: "NSW-PR-2026-ABCDEFGH", Amount: decimal.NewFromInt(100), Currency: "USD"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS").Once()
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(session, nil).Once()
+		mockAPI.On("CanTransition", PaymentActionPartial).Return(true).Once()
+		mockAPI.On("Transition", PaymentActionPartial).Return(nil).Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionPartial, Content: partial})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Payment partially received", resp.Message)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyPartiallyPaid", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("PARTIALLY_PAID").Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionPartial, Content: partial})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Partial payment already recorded", resp.Message)
+		mockAPI.AssertNotCalled(t, "Transition", PaymentActionPartial)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("RequiresGatewayResult", func(t *testing.T) {
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(new(MockAPI))
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionPartial})
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestPaymentExecute_RequestRefund(t *testing.T) {
+	session := PaymentSession{TransactionID: "txn-1", ReferenceNumber: "NSW-PR-2026-ABCDEFGH", GeneratedAt: time.Now()}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		mockSvc := new(MockPaymentService)
+		task := newTestPaymentTask(mockSvc)
+		task.Init(mockAPI)
+
+		mockAPI.On("CanTransition", PaymentActionRequestRefund).Return(true).Once()
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(session, nil).Once()
+		mockSvc.On("RequestRefund", mock.Anything, "NSW-PR-2026-ABCDEFGH", decimalArg(decimal.NewFromInt(40)), "application rejected").
+			Return(&paymentsv2.PaymentTransaction{Status: paymentsv2.PaymentStatusRefundRequested}, nil).Once()
+		mockAPI.On("Transition", PaymentActionRequestRefund).Return(nil).Once()
+
+		// Content decoded from JSON arrives as a map.
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{
+			Action:  PaymentActionRequestRefund,
+			Content: map[string]any{"amount": "40", "reason": "application rejected"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Refund requested", resp.Message)
+		mockAPI.AssertExpectations(t)
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("RefundRejected", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		mockSvc := new(MockPaymentService)
+		task := newTestPaymentTask(mockSvc)
+		task.Init(mockAPI)
+
+		mockAPI.On("CanTransition", PaymentActionRequestRefund).Return(true).Once()
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(session, nil).Once()
+		mockSvc.On("RequestRefund", mock.Anything, "NSW-PR-2026-ABCDEFGH", decimalArg(decimal.Zero), "").
+			Return(nil, paymentsv2.ErrRefundNotAllowed).Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionRequestRefund})
+
+		assert.ErrorIs(t, err, paymentsv2.ErrRefundNotAllowed)
+		assert.Nil(t, resp)
+		mockAPI.AssertNotCalled(t, "Transition", PaymentActionRequestRefund)
+	})
+
+	t.Run("InvalidTransition", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		mockSvc := new(MockPaymentService)
+		task := newTestPaymentTask(mockSvc)
+		task.Init(mockAPI)
+
+		mockAPI.On("CanTransition", PaymentActionRequestRefund).Return(false).Once()
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS").Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionRequestRefund})
+
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+		mockSvc.AssertNotCalled(t, "RequestRefund", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// decimalArg matches a decimal argument by value rather than by representation.
+func decimalArg(want decimal.Decimal) any {
+	return mock.MatchedBy(func(got decimal.Decimal) bool { return got.Equal(want) })
+}
+
+func TestPaymentExecute_PaymentRefunded(t *testing.T) {
+	refund := PaymentGatewayResult{ReferenceNumber: "NSW-PR-2026-ABCDEFGH", Amount: decimal.NewFromInt(100), Currency: "USD"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("REFUND_REQUESTED").Once()
+		mockAPI.On("CanTransition", PaymentActionRefunded).Return(true).Once()
+		mockAPI.On("Transition", PaymentActionRefunded).Return(nil).Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionRefunded, Content: refund})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Payment refunded", resp.Message)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("GatewayRedelivery", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		task := newTestPaymentTask(new(MockPaymentService))
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("REFUNDED").Once()
+
+		resp, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionRefunded, Content: refund})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Refund already recorded", resp.Message)
+		mockAPI.AssertNotCalled(t, "Transition", PaymentActionRefunded)
+		mockAPI.AssertExpectations(t)
+	})
+}
+
 func TestPaymentHelpers_readSession(t *testing.T) {
 	t.Run("ReadError", func(t *testing.T) {
 		mockAPI := new(MockAPI)
@@ -894,10 +1307,122 @@ func TestPaymentExecute_UnknownAction(t *testing.T) {
 	assert.Nil(t, resp)
 }
 
+// ── Fee Schedule Tests ────────────────────────────────────────────────────────
+
+func TestPaymentFeeSchedule(t *testing.T) {
+	resolution := &feeschedule.Resolution{
+		ID:         "res-1",
+		ScheduleID: "customs",
+		Currency:   "LKR",
+		Lines: []feeschedule.Line{
+			{Code: "WEIGHT", Description: "Handling by weight", Category: feeschedule.CategoryAddition, MeasureValue: decimal.NewFromInt(250), Rate: decimal.NewFromInt(2500), Amount: decimal.NewFromInt(2500)},
+			{Code: "REBATE", Description: "Exporter rebate", Category: feeschedule.CategoryDeduction, Rate: decimal.NewFromInt(100), Amount: decimal.NewFromInt(100)},
+		},
+		Total: decimal.NewFromInt(2400),
+	}
+	consignment := map[string]any{"hsCode": "0902.10", "weight": 250.0}
+
+	t.Run("ResolvesScheduleBeforeBreakdown", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		fees := new(MockFeeSchedules)
+		task := newTestFeeSchedulePaymentTask(new(MockPaymentService), fees)
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("IDLE")
+		mockAPI.On("GetTaskState").Return(InProgress)
+		mockAPI.On("GetTaskID").Return("task-123")
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(PaymentSession{TransactionID: "txn-1", GeneratedAt: time.Now()}, nil)
+		mockAPI.On("ReadFromGlobalStore", "consignment").Return(consignment, true)
+		fees.On("Resolve", mock.Anything, mock.MatchedBy(func(req feeschedule.ResolveRequest) bool {
+			return req.ScheduleID == "customs" && req.TaskID == "task-123" && req.Inputs.HSCode == "0902.10" &&
+				req.Inputs.Weight.Equal(decimal.NewFromInt(250)) && req.Inputs.Quantity.IsZero()
+		})).Return(resolution, nil).Once()
+
+		resp, err := task.GetRenderInfo(context.Background())
+		assert.NoError(t, err)
+
+		content := resp.Data.(GetRenderInfoResponse).Content.(PaymentRenderContent)
+		// (2500 - 100 + 100) plus 10%.
+		assert.True(t, decimal.NewFromInt(2750).Equal(content.TotalAmount), content.TotalAmount.String())
+		assert.Equal(t, "res-1", content.FeeResolutionID)
+		assert.Len(t, content.Breakdown, 4)
+		assert.Equal(t, TypeSchedule, content.Breakdown[0].Type)
+		assert.Equal(t, CategoryDeduction, content.Breakdown[1].Category)
+		fees.AssertExpectations(t)
+	})
+
+	t.Run("InitiatePinsResolution", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		mockSvc := new(MockPaymentService)
+		fees := new(MockFeeSchedules)
+		task := newTestFeeSchedulePaymentTask(mockSvc, fees)
+		task.Init(mockAPI)
+
+		mockAPI.On("CanTransition", PaymentActionInitiate).Return(true)
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(PaymentSession{TransactionID: "txn-1", GeneratedAt: time.Now()}, nil)
+		mockAPI.On("ReadFromGlobalStore", "consignment").Return(consignment, true)
+		mockAPI.On("GetTaskID").Return("task-123")
+		mockAPI.On("GetPluginState").Return("IDLE")
+		fees.On("Resolve", mock.Anything, mock.Anything).Return(resolution, nil).Once()
+		mockSvc.On("CreateCheckoutSession", mock.Anything, mock.MatchedBy(func(req paymentsv2.CreateCheckoutRequest) bool {
+			return req.Amount.Equal(decimal.NewFromInt(2750)) && req.Currency == "LKR"
+		})).Return(&paymentsv2.CreateCheckoutResponse{ProviderID: "mock", ReferenceNumber: "NSW-PR-1"}, nil).Once()
+
+		var captured *PaymentSession
+		mockAPI.On("WriteToLocalStore", paymentStoreSession, mock.AnythingOfType("*plugin.PaymentSession")).
+			Run(func(args mock.Arguments) { captured = args.Get(1).(*PaymentSession) }).Return(nil).Once()
+		mockAPI.On("Transition", PaymentActionInitiate).Return(nil).Once()
+
+		_, err := task.Execute(context.Background(), &ExecutionRequest{Action: PaymentActionInitiate})
+		assert.NoError(t, err)
+		assert.Equal(t, "res-1", captured.FeeResolutionID)
+		mockSvc.AssertExpectations(t)
+		fees.AssertExpectations(t)
+	})
+
+	t.Run("UsesPinnedResolution", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		fees := new(MockFeeSchedules)
+		task := newTestFeeSchedulePaymentTask(new(MockPaymentService), fees)
+		task.Init(mockAPI)
+
+		mockAPI.On("CanTransition", PaymentActionSuccess).Return(true)
+		mockAPI.On("GetPluginState").Return("IN_PROGRESS")
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(PaymentSession{TransactionID: "txn-1", FeeResolutionID: "res-1", GeneratedAt: time.Now()}, nil)
+		mockAPI.On("Transition", PaymentActionSuccess).Return(nil).Once()
+		fees.On("GetResolution", mock.Anything, "res-1").Return(resolution, nil).Once()
+
+		_, err := task.Execute(context.Background(), &ExecutionRequest{
+			Action:  PaymentActionSuccess,
+			Content: map[string]any{"referenceNumber": "NSW-PR-1", "amount": "2750", "currency": "LKR"},
+		})
+		assert.NoError(t, err)
+		fees.AssertExpectations(t)
+		fees.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CurrencyMismatch", func(t *testing.T) {
+		mockAPI := new(MockAPI)
+		fees := new(MockFeeSchedules)
+		task := newTestFeeSchedulePaymentTask(new(MockPaymentService), fees)
+		task.config.Currency = "USD"
+		task.Init(mockAPI)
+
+		mockAPI.On("GetPluginState").Return("IDLE")
+		mockAPI.On("GetTaskID").Return("task-123")
+		mockAPI.On("ReadFromLocalStore", paymentStoreSession).Return(PaymentSession{TransactionID: "txn-1", GeneratedAt: time.Now()}, nil)
+		mockAPI.On("ReadFromGlobalStore", "consignment").Return(consignment, true)
+		fees.On("Resolve", mock.Anything, mock.Anything).Return(resolution, nil).Once()
+
+		_, err := task.GetRenderInfo(context.Background())
+		assert.ErrorContains(t, err, "fee schedule customs charges in LKR, task charges in USD")
+	})
+}
+
 // ── Helper ────────────────────────────────────────────────────────────────────
 
 // newTestPaymentTask creates a PaymentTask with a standard test configuration.
-func newTestPaymentTask(mockSvc payments.PaymentService) *PaymentTask {
+func newTestPaymentTask(mockSvc paymentsv2.PaymentService) *PaymentTask {
 	return &PaymentTask{
 		config: PaymentConfig{
 			Currency: "USD",
@@ -915,3 +1440,26 @@ func newTestPaymentTask(mockSvc payments.PaymentService) *PaymentTask {
 		paymentService: mockSvc,
 	}
 }
+
+// newTestFeeSchedulePaymentTask creates a PaymentTask charging the "customs" fee schedule, then a fixed fee and 10%.
+func newTestFeeSchedulePaymentTask(mockSvc paymentsv2.PaymentService, fees feeschedule.Resolver) *PaymentTask {
+	return &PaymentTask{
+		config: PaymentConfig{
+			Currency: "LKR",
+			TTL:      300,
+			OrgID:    "CUSTOMS",
+			Breakdown: []BreakdownItem{
+				{Description: "Service Fee", Category: CategoryAddition, Type: TypeFixed, UnitPrice: "100"},
+				{Description: "VAT", Category: CategoryAddition, Type: TypePercentage, Value: "10"},
+			},
+			FeeSchedule: &FeeScheduleConfig{
+				ID:       "customs",
+				HSCode:   "{consignment.hsCode}",
+				Quantity: "{consignment.quantity}",
+				Weight:   "{consignment.weight}",
+			},
+		},
+		paymentService: mockSvc,
+		feeSchedules:   fees,
+	}
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolvePlaceholders replaces each {path} or {path:default} in val with the value at the
+// dot-separated path in the task's global store, or with the default when there is none.
+func resolvePlaceholders(api API, val string) string {
+	// Simple regex-free placeholder replacement
+	for {
+		start := strings.Index(val, "{")
+		end := strings.Index(val, "}")
+		if start == -1 || end == -1 || end < start {
+			break
+		}
+
+		placeholder := val[start : end+1]
+		inner := val[start+1 : end]
+		parts := strings.Split(inner, ":")
+		path := parts[0]
+
+		resolved := lookupGlobal(api, path)
+		replacement := ""
+		if resolved != nil {
+			replacement = fmt.Sprintf("%v", resolved)
+		} else if len(parts) > 1 {
+			replacement = parts[1]
+		}
+
+		val = strings.Replace(val, placeholder, replacement, 1)
+	}
+	return val
+}
+
+// lookupGlobal returns the value at a dot-separated path in the task's global store, or nil.
+func lookupGlobal(api API, path string) any {
+	keys := strings.Split(path, ".")
+	val, ok := api.ReadFromGlobalStore(keys[0])
+	if !ok {
+		return nil
+	}
+
+	current := val
+	for i := 1; i < len(keys); i++ {
+		if m, ok := current.(map[string]any); ok {
+			current, ok = m[keys[i]]
+			if !ok {
+				return nil
+			}
+		} else {
+			return nil
+		}
+	}
+	return current
+}
@@ -31,6 +31,10 @@ type API interface {
 type ExecutionRequest struct {
 	Action  string      `json:"action"`
 	Content interface{} `json:"content,omitempty"`
+	// Source names the approver a callback answers for when several share a service. It is
+	// taken from the callback URL (see CallbackSourceQueryParam), never from the request body,
+	// and does not identify the caller: that is the authenticated client.
+	Source string `json:"-"`
 }
 
 type ApiError struct {
@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Party names a principal that may act on a task, relative to the workflow the task belongs to.
+type Party string
+
+const (
+	PartyTrader          Party = "TRADER"           // the trader who owns the consignment or pre-consignment
+	PartyCHA             Party = "CHA"              // the CHA the consignment is assigned to
+	PartyInjectedService Party = "INJECTED_SERVICE" // an external service (OGA) the task was injected into
+)
+
+// ActionPolicy lists the parties allowed to execute a plugin action.
+type ActionPolicy struct {
+	Allow []Party
+}
+
+// Allow returns an ActionPolicy that admits the given parties.
+func Allow(parties ...Party) ActionPolicy {
+	return ActionPolicy{Allow: parties}
+}
+
+// Allows reports whether the policy admits the given party.
+func (p ActionPolicy) Allows(party Party) bool {
+	return slices.Contains(p.Allow, party)
+}
+
+// ServiceRef identifies an external service a task was injected into, either by its
+// remote.Manager service ID or by the URL the task was posted to.
+type ServiceRef struct {
+	ServiceID string
+	URL       string
+}
+
+// CallbackServicesFunc returns the external services a task built from config was injected into.
+// When request is non-nil only the services allowed to send that request are returned;
+// when it is nil every service the task was injected into is returned.
+type CallbackServicesFunc func(config json.RawMessage, request *ExecutionRequest) ([]ServiceRef, error)
+
+// submissionServices returns the service a submission config posts to, if any.
+func submissionServices(submission *SubmissionConfig, legacyURL string) []ServiceRef {
+	if submission != nil && (submission.ServiceID != "" || submission.Url != "") {
+		return []ServiceRef{{ServiceID: submission.ServiceID, URL: submission.Url}}
+	}
+	if legacyURL != "" {
+		return []ServiceRef{{URL: legacyURL}}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/OpenNSW/nsw/internal/config"
+	"github.com/OpenNSW/nsw/internal/document"
+	"github.com/OpenNSW/nsw/internal/feeschedule"
+	"github.com/OpenNSW/nsw/internal/form"
+	"github.com/OpenNSW/nsw/internal/paymentsv2"
+	"github.com/OpenNSW/nsw/pkg/remote"
+)
+
+// Dependency names a shared service that a plugin type needs in order to be constructed.
+type Dependency string
+
+const (
+	DependencyDocumentIssuer Dependency = "DOCUMENT_ISSUER"
+	DependencyFeeSchedules   Dependency = "FEE_SCHEDULES"
+	DependencyFormService    Dependency = "FORM_SERVICE"
+	DependencyPaymentService Dependency = "PAYMENT_SERVICE"
+	DependencyRemoteManager  Dependency = "REMOTE_MANAGER"
+)
+
+// Dependencies carries the shared services handed to plugin constructors.
+// A plugin type declares which of them it needs through Registration.Requires.
+type Dependencies struct {
+	Config         *config.Config
+	DocumentIssuer document.Issuer
+	FeeSchedules   feeschedule.Resolver
+	FormService    form.FormService
+	PaymentService paymentsv2.PaymentService
+	RemoteManager  *remote.Manager
+}
+
+// has reports whether the given dependency is available.
+func (d Dependencies) has(dep Dependency) bool {
+	switch dep {
+	case DependencyDocumentIssuer:
+		return d.DocumentIssuer != nil
+	case DependencyFeeSchedules:
+		return d.FeeSchedules != nil
+	case DependencyFormService:
+		return d.FormService != nil
+	case DependencyPaymentService:
+		return d.PaymentService != nil
+	case DependencyRemoteManager:
+		return d.RemoteManager != nil
+	default:
+		return false
+	}
+}
+
+// Constructor builds a plugin instance from the raw node template configuration.
+type Constructor func(config json.RawMessage, deps Dependencies) (Plugin, error)
+
+// Registration describes a plugin type: how to construct it, its FSM, the
+// dependencies it needs, and who may execute each of its public actions.
+type Registration struct {
+	Type     Type
+	New      Constructor
+	NewFSM   func() *PluginFSM
+	Requires []Dependency
+
+	// Policies maps each public action to the parties allowed to execute it.
+	// Actions without a policy are denied.
+	Policies map[string]ActionPolicy
+	// CallbackServices resolves the external services a task was injected into.
+	// Required if any policy admits PartyInjectedService.
+	CallbackServices CallbackServicesFunc
+}
+
+// Registry holds the plugin types known to the task system.
+// It is safe for concurrent use.
+type Registry struct {
+	mu            sync.RWMutex
+	registrations map[Type]Registration
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{registrations: make(map[Type]Registration)}
+}
+
+// NewDefaultRegistry returns a Registry with the built-in plugin types registered.
+func NewDefaultRegistry() (*Registry, error) {
+	r := NewRegistry()
+	for _, reg := range builtinRegistrations() {
+		if err := r.Register(reg); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a plugin type to the registry.
+// Returns an error if the registration is incomplete or the type is already registered.
+func (r *Registry) Register(reg Registration) error {
+	if reg.Type == "" {
+		return fmt.Errorf("plugin registration: type is required")
+	}
+	if reg.New == nil {
+		return fmt.Errorf("plugin registration %s: constructor is required", reg.Type)
+	}
+	if reg.NewFSM == nil {
+		return fmt.Errorf("plugin registration %s: FSM constructor is required", reg.Type)
+	}
+	if reg.CallbackServices == nil {
+		for action, policy := range reg.Policies {
+			if policy.Allows(PartyInjectedService) {
+				return fmt.Errorf("plugin registration %s: action %s admits injected services but no callback service resolver is set", reg.Type, action)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.registrations[reg.Type]; exists {
+		return fmt.Errorf("plugin registration %s: type already registered", reg.Type)
+	}
+	r.registrations[reg.Type] = reg
+	return nil
+}
+
+// Lookup returns the registration for the given plugin type.
+func (r *Registry) Lookup(taskType Type) (Registration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reg, ok := r.registrations[taskType]
+	return reg, ok
+}
+
+// IsRegistered reports whether the given plugin type is registered.
+func (r *Registry) IsRegistered(taskType Type) bool {
+	_, ok := r.Lookup(taskType)
+	return ok
+}
+
+// Types returns the registered plugin types in sorted order.
+func (r *Registry) Types() []Type {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]Type, 0, len(r.registrations))
+	for t := range r.registrations {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// CheckDependencies verifies that every registered plugin type has the dependencies it requires.
+func (r *Registry) CheckDependencies(deps Dependencies) error {
+	for _, t := range r.Types() {
+		reg, _ := r.Lookup(t)
+		for _, dep := range reg.Requires {
+			if !deps.has(dep) {
+				return fmt.Errorf("plugin %s requires %s, which is not configured", t, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// builtinRegistrations lists the plugin types that ship with the task system.
+func builtinRegistrations() []Registration {
+	return []Registration{
+		simpleFormRegistration,
+		waitForEventRegistration,
+		paymentRegistration,
+		timerRegistration,
+		multiApprovalRegistration,
+		documentRegistration,
+	}
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDefaultRegistry(t *testing.T) {
+	r, err := NewDefaultRegistry()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Type{TaskTypeDocument, TaskTypeMultiApproval, TaskTypePayment, TaskTypeSimpleForm, TaskTypeTimer, TaskTypeWaitForEvent}, r.Types())
+	assert.True(t, r.IsRegistered(TaskTypeSimpleForm))
+	assert.False(t, r.IsRegistered("UNKNOWN"))
+}
+
+func TestRegistry_Register(t *testing.T) {
+	newPlugin := func(json.RawMessage, Dependencies) (Plugin, error) { return nil, nil }
+	newFSM := func() *PluginFSM { return NewPluginFSM(nil) }
+
+	t.Run("Rejects incomplete registrations", func(t *testing.T) {
+		r := NewRegistry()
+		assert.Error(t, r.Register(Registration{New: newPlugin, NewFSM: newFSM}))
+		assert.Error(t, r.Register(Registration{Type: "CUSTOM", NewFSM: newFSM}))
+		assert.Error(t, r.Register(Registration{Type: "CUSTOM", New: newPlugin}))
+	})
+
+	t.Run("Rejects duplicate types", func(t *testing.T) {
+		r := NewRegistry()
+		require.NoError(t, r.Register(Registration{Type: "CUSTOM", New: newPlugin, NewFSM: newFSM}))
+		err := r.Register(Registration{Type: "CUSTOM", New: newPlugin, NewFSM: newFSM})
+		assert.ErrorContains(t, err, "already registered")
+	})
+
+	t.Run("Requires callback services for injected service policies", func(t *testing.T) {
+		r := NewRegistry()
+		err := r.Register(Registration{
+			Type: "CUSTOM", New: newPlugin, NewFSM: newFSM,
+			Policies: map[string]ActionPolicy{"CALLBACK": Allow(PartyInjectedService)},
+		})
+		assert.ErrorContains(t, err, "no callback service resolver")
+	})
+}
+
+func TestBuiltinRegistrations_CallbackServices(t *testing.T) {
+	r, err := NewDefaultRegistry()
+	require.NoError(t, err)
+
+	form, _ := r.Lookup(TaskTypeSimpleForm)
+	refs, err := form.CallbackServices(json.RawMessage(`{"submissionUrl":"http://npqs.local/inject"}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceRef{{URL: "http://npqs.local/inject"}}, refs)
+
+	approval, _ := r.Lookup(TaskTypeMultiApproval)
+	config := json.RawMessage(`{"rule":{"type":"ANY"},"approvers":[
+		{"serviceId":"npqs","url":"/inject","request":{"taskCode":"a"}},
+		{"serviceId":"fcau","url":"/inject","request":{"taskCode":"b"}}
+	]}`)
+	refs, err = approval.CallbackServices(config, nil)
+	require.NoError(t, err)
+	assert.Len(t, refs, 2)
+	refs, err = approval.CallbackServices(config, &ExecutionRequest{Action: multiApprovalFSMCallback, Source: "fcau"})
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceRef{{ServiceID: "fcau", URL: "/inject"}}, refs)
+
+	timer, _ := r.Lookup(TaskTypeTimer)
+	assert.Empty(t, timer.Policies)
+}
+
+func TestRegistry_CheckDependencies(t *testing.T) {
+	r, err := NewDefaultRegistry()
+	require.NoError(t, err)
+
+	err = r.CheckDependencies(Dependencies{})
+	assert.ErrorContains(t, err, "DOCUMENT requires DOCUMENT_ISSUER")
+
+	err = r.CheckDependencies(Dependencies{DocumentIssuer: new(MockDocumentIssuer)})
+	assert.ErrorContains(t, err, "MULTI_APPROVAL requires REMOTE_MANAGER")
+}
+
+func TestTaskFactory_BuildExecutor(t *testing.T) {
+	r, err := NewDefaultRegistry()
+	require.NoError(t, err)
+	f := &taskFactory{registry: r, deps: Dependencies{PaymentService: new(MockPaymentService)}}
+
+	t.Run("Registered type", func(t *testing.T) {
+		exec, err := f.BuildExecutor(context.Background(), TaskTypePayment, json.RawMessage(`{}`))
+		require.NoError(t, err)
+		assert.IsType(t, &PaymentTask{}, exec.Plugin)
+		assert.True(t, exec.FSM.CanTransition("", FSMActionStart))
+	})
+
+	t.Run("Unknown type", func(t *testing.T) {
+		_, err := f.BuildExecutor(context.Background(), "UNKNOWN", json.RawMessage(`{}`))
+		assert.ErrorContains(t, err, "unknown task type: UNKNOWN")
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		_, err := f.BuildExecutor(context.Background(), TaskTypePayment, json.RawMessage(`not-json`))
+		assert.Error(t, err)
+	})
+}
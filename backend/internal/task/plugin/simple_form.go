@@ -188,6 +188,33 @@ func NewSimpleFormFSM() *PluginFSM {
 	})
 }
 
+// simpleFormRegistration registers the SIMPLE_FORM plugin type.
+var simpleFormRegistration = Registration{
+	Type: TaskTypeSimpleForm,
+	New: func(config json.RawMessage, deps Dependencies) (Plugin, error) {
+		p, err := NewSimpleForm(config, deps.Config, deps.FormService, deps.RemoteManager)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	},
+	NewFSM:   NewSimpleFormFSM,
+	Requires: []Dependency{DependencyFormService, DependencyRemoteManager},
+	Policies: map[string]ActionPolicy{
+		SimpleFormActionDraft:       Allow(PartyTrader, PartyCHA),
+		SimpleFormActionSubmit:      Allow(PartyTrader, PartyCHA),
+		SimpleFormActionOgaVerify:   Allow(PartyInjectedService),
+		SimpleFormActionOgaFeedback: Allow(PartyInjectedService),
+	},
+	CallbackServices: func(config json.RawMessage, _ *ExecutionRequest) ([]ServiceRef, error) {
+		var formConfig Config
+		if err := json.Unmarshal(config, &formConfig); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return submissionServices(formConfig.Submission, formConfig.SubmissionURL), nil
+	},
+}
+
 func NewSimpleForm(configJSON json.RawMessage, cfg *config.Config, formService form.FormService, remoteManager *remote.Manager) (*SimpleForm, error) {
 	var formConfig Config
 	if err := json.Unmarshal(configJSON, &formConfig); err != nil {
@@ -0,0 +1,127 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// timerFSMElapsed is the FSM action fired by the timer's deadline once the duration has passed.
+const timerFSMElapsed = "ELAPSED"
+
+// ── Plugin States ─────────────────────────────────────────────────────────────
+
+type timerState string
+
+const (
+	timerWaiting timerState = "WAITING"
+	timerElapsed timerState = "ELAPSED"
+)
+
+// ── Config ────────────────────────────────────────────────────────────────────
+
+// TimerConfig represents the configuration for a TIMER task.
+//
+// Example:
+//
+//	{ "duration": "24h", "outputs": { "cooling_off_done": true } }
+type TimerConfig struct {
+	Duration string         `json:"duration"`          // Go duration measured from task start
+	Outputs  map[string]any `json:"outputs,omitempty"` // Outputs reported to the workflow once the timer elapses
+}
+
+// ── FSM ───────────────────────────────────────────────────────────────────────
+
+// NewTimerFSM returns the state graph for TimerTask.
+//
+// State graph:
+//
+//	""      ──START───► WAITING [IN_PROGRESS]
+//	WAITING ──ELAPSED─► ELAPSED [COMPLETED]
+func NewTimerFSM() *PluginFSM {
+	return NewPluginFSM(map[TransitionKey]TransitionOutcome{
+		{"", FSMActionStart}:                    {string(timerWaiting), InProgress},
+		{string(timerWaiting), timerFSMElapsed}: {string(timerElapsed), Completed},
+	})
+}
+
+// ── Plugin ────────────────────────────────────────────────────────────────────
+
+// timerRegistration registers the TIMER plugin type.
+var timerRegistration = Registration{
+	Type: TaskTypeTimer,
+	New: func(config json.RawMessage, _ Dependencies) (Plugin, error) {
+		p, err := NewTimerTask(config)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	},
+	NewFSM: NewTimerFSM,
+}
+
+// TimerTask implements Plugin for the TIMER task type. It waits for a fixed duration;
+// the wait is backed by the node deadline so it survives process restarts.
+type TimerTask struct {
+	api    API
+	config TimerConfig
+}
+
+// NewTimerTask creates a TimerTask from the raw JSON configuration.
+func NewTimerTask(raw json.RawMessage) (*TimerTask, error) {
+	var cfg TimerConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("timer: invalid config: %w", err)
+	}
+	t := &TimerTask{config: cfg}
+	if err := t.DeadlineConfig().Validate(); err != nil {
+		return nil, fmt.Errorf("timer: %w", err)
+	}
+	return t, nil
+}
+
+func (t *TimerTask) Init(api API) {
+	t.api = api
+}
+
+// DeadlineConfig implements DeadlineProvider; the timer elapses through its deadline.
+func (t *TimerTask) DeadlineConfig() *DeadlineConfig {
+	return &DeadlineConfig{
+		After:   t.config.Duration,
+		Action:  timerFSMElapsed,
+		Outputs: t.config.Outputs,
+	}
+}
+
+func (t *TimerTask) Start(_ context.Context) (*ExecutionResponse, error) {
+	if !t.api.CanTransition(FSMActionStart) {
+		return &ExecutionResponse{Message: "Timer already started"}, nil
+	}
+	if err := t.api.Transition(FSMActionStart); err != nil {
+		return nil, err
+	}
+	return &ExecutionResponse{Message: fmt.Sprintf("Timer started for %s", t.config.Duration)}, nil
+}
+
+func (t *TimerTask) Execute(_ context.Context, request *ExecutionRequest) (*ExecutionResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("execution request is required")
+	}
+	return nil, fmt.Errorf("unsupported action %q for TimerTask", request.Action)
+}
+
+func (t *TimerTask) GetRenderInfo(_ context.Context) (*ApiResponse, error) {
+	content := map[string]any{"duration": t.config.Duration}
+	if record, err := t.api.ReadFromLocalStore(DeadlineLocalStoreKey); err == nil && record != nil {
+		content["deadline"] = record
+	}
+	return &ApiResponse{
+		Success: true,
+		Data: GetRenderInfoResponse{
+			Type:        TaskTypeTimer,
+			PluginState: t.api.GetPluginState(),
+			State:       t.api.GetTaskState(),
+			Content:     content,
+		},
+	}, nil
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTimerTask(t *testing.T) {
+	t.Run("Valid duration", func(t *testing.T) {
+		task, err := NewTimerTask(json.RawMessage(`{"duration": "24h", "outputs": {"cooled": true}}`))
+		require.NoError(t, err)
+
+		deadline := task.DeadlineConfig()
+		assert.Equal(t, "24h", deadline.After)
+		assert.Equal(t, timerFSMElapsed, deadline.Action)
+		assert.Equal(t, map[string]any{"cooled": true}, deadline.Outputs)
+	})
+
+	t.Run("Missing duration", func(t *testing.T) {
+		_, err := NewTimerTask(json.RawMessage(`{}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		_, err := NewTimerTask(json.RawMessage(`not-json`))
+		assert.Error(t, err)
+	})
+}
+
+func TestTimerTask_Start(t *testing.T) {
+	task, err := NewTimerTask(json.RawMessage(`{"duration": "1h"}`))
+	require.NoError(t, err)
+	mockAPI := new(MockAPI)
+	task.Init(mockAPI)
+
+	mockAPI.On("CanTransition", FSMActionStart).Return(true).Once()
+	mockAPI.On("Transition", FSMActionStart).Return(nil).Once()
+
+	resp, err := task.Start(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, resp.Message, "1h")
+	mockAPI.AssertExpectations(t)
+}
+
+func TestTimerTask_Execute_Unsupported(t *testing.T) {
+	task, err := NewTimerTask(json.RawMessage(`{"duration": "1h"}`))
+	require.NoError(t, err)
+
+	_, err = task.Execute(context.Background(), &ExecutionRequest{Action: timerFSMElapsed})
+	assert.Error(t, err)
+}
+
+func TestNewTimerFSM(t *testing.T) {
+	fsm := NewTimerFSM()
+
+	outcome, err := fsm.Transition("", FSMActionStart)
+	require.NoError(t, err)
+	assert.Equal(t, string(timerWaiting), outcome.NextPluginState)
+	assert.Equal(t, InProgress, outcome.NextTaskState)
+
+	outcome, err = fsm.Transition(string(timerWaiting), timerFSMElapsed)
+	require.NoError(t, err)
+	assert.Equal(t, Completed, outcome.NextTaskState)
+
+	_, err = fsm.Transition(string(timerElapsed), timerFSMElapsed)
+	assert.Error(t, err)
+}
+
+func TestResolveDeadline(t *testing.T) {
+	t.Run("Generic deadline config", func(t *testing.T) {
+		cfg, err := ResolveDeadline(&WaitForEventTask{}, json.RawMessage(`{"deadline": {"after": "72h", "action": "DEADLINE_ESCALATE"}}`))
+		require.NoError(t, err)
+		require.NotNil(t, cfg)
+		assert.Equal(t, DeadlineActionEscalate, cfg.Action)
+	})
+
+	t.Run("No deadline", func(t *testing.T) {
+		cfg, err := ResolveDeadline(&WaitForEventTask{}, json.RawMessage(`{"submission": {}}`))
+		require.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("Invalid deadline", func(t *testing.T) {
+		_, err := ResolveDeadline(&WaitForEventTask{}, json.RawMessage(`{"deadline": {"after": "soon", "action": "DEADLINE_TIMEOUT"}}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Plugin provided deadline", func(t *testing.T) {
+		task, err := NewTimerTask(json.RawMessage(`{"duration": "10m"}`))
+		require.NoError(t, err)
+		cfg, err := ResolveDeadline(task, json.RawMessage(`{"duration": "10m"}`))
+		require.NoError(t, err)
+		assert.Equal(t, timerFSMElapsed, cfg.Action)
+	})
+}
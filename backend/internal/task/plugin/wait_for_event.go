@@ -19,6 +19,8 @@ const (
 	notifiedService  waitForEventState = "NOTIFIED_SERVICE"
 	notifyFailed     waitForEventState = "NOTIFY_FAILED"
 	receivedCallback waitForEventState = "RECEIVED_CALLBACK"
+	escalated        waitForEventState = "ESCALATED"
+	timedOut         waitForEventState = "TIMED_OUT"
 )
 
 type DisplayState string
@@ -97,12 +99,25 @@ type WaitForEventExternalServiceRequest struct {
 //	""               ──START_FAILED─► NOTIFY_FAILED     [IN_PROGRESS]
 //	NOTIFY_FAILED    ──RETRY────────► NOTIFIED_SERVICE  [IN_PROGRESS]
 //	NOTIFIED_SERVICE ──COMPLETE─────► RECEIVED_CALLBACK [COMPLETED]
+//	NOTIFIED_SERVICE ──DEADLINE_ESCALATE─► ESCALATED    [IN_PROGRESS]
+//	NOTIFIED_SERVICE ──DEADLINE_TIMEOUT──► TIMED_OUT    [FAILED]
+//	NOTIFY_FAILED    ──DEADLINE_TIMEOUT──► TIMED_OUT    [FAILED]
+//	ESCALATED        ──COMPLETE─────► RECEIVED_CALLBACK [COMPLETED]
+//	ESCALATED        ──DEADLINE_TIMEOUT──► TIMED_OUT    [FAILED]
+//
+// The DEADLINE_* actions are fired by the node's deadline; a deadline may also fire COMPLETE to auto-approve.
 func NewWaitForEventFSM() *PluginFSM {
 	return NewPluginFSM(map[TransitionKey]TransitionOutcome{
 		{"", FSMActionStart}:                               {string(notifiedService), InProgress},
 		{"", waitForEventFSMStartFailed}:                   {string(notifyFailed), InProgress},
 		{string(notifyFailed), waitForEventFSMRetry}:       {string(notifiedService), InProgress},
 		{string(notifiedService), waitForEventFSMComplete}: {string(receivedCallback), Completed},
+
+		{string(notifiedService), DeadlineActionEscalate}: {string(escalated), InProgress},
+		{string(notifiedService), DeadlineActionTimeout}:  {string(timedOut), Failed},
+		{string(notifyFailed), DeadlineActionTimeout}:     {string(timedOut), Failed},
+		{string(escalated), waitForEventFSMComplete}:      {string(receivedCallback), Completed},
+		{string(escalated), DeadlineActionTimeout}:        {string(timedOut), Failed},
 	})
 }
 
@@ -125,6 +140,35 @@ func (t *WaitForEventTask) renderContent(ctx context.Context) map[string]any {
 	return content
 }
 
+// waitForEventRegistration registers the WAIT_FOR_EVENT plugin type.
+var waitForEventRegistration = Registration{
+	Type: TaskTypeWaitForEvent,
+	New: func(config json.RawMessage, deps Dependencies) (Plugin, error) {
+		var serviceURL string
+		if deps.Config != nil {
+			serviceURL = deps.Config.Server.ServiceURL
+		}
+		p, err := NewWaitForEventTask(config, serviceURL, deps.RemoteManager, deps.FormService)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	},
+	NewFSM:   NewWaitForEventFSM,
+	Requires: []Dependency{DependencyFormService, DependencyRemoteManager},
+	Policies: map[string]ActionPolicy{
+		waitForEventFSMRetry:    Allow(PartyTrader, PartyCHA),
+		waitForEventFSMComplete: Allow(PartyInjectedService),
+	},
+	CallbackServices: func(config json.RawMessage, _ *ExecutionRequest) ([]ServiceRef, error) {
+		var cfg WaitForEventConfig
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return submissionServices(cfg.Submission, ""), nil
+	},
+}
+
 func NewWaitForEventTask(raw json.RawMessage, serviceBaseURL string, remoteManager *remote.Manager, formService form.FormService) (*WaitForEventTask, error) {
 	var cfg WaitForEventConfig
 	if err := json.Unmarshal(raw, &cfg); err != nil {
@@ -269,9 +313,9 @@ func (t *WaitForEventTask) getDisplay(state waitForEventState) (*WaitForEventDis
 
 	var resolvedState DisplayState
 	switch state {
-	case notifiedService:
+	case notifiedService, escalated:
 		resolvedState = DisplayStateWaiting
-	case notifyFailed:
+	case notifyFailed, timedOut:
 		resolvedState = DisplayStateFailed
 	case receivedCallback:
 		resolvedState = DisplayStateCompleted
@@ -11,10 +11,11 @@ const (
 
 type PreConsignmentTemplate struct {
 	BaseModel
-	Name               string   `gorm:"type:varchar(255);column:name;not null" json:"name"`            // Human-readable name of the pre-consignment template
-	Description        string   `gorm:"type:text;column:description" json:"description"`               // Optional description of the pre-consignment template
-	WorkflowTemplateID string   `json:"workflowTemplateId"`                                            // ID of the workflow template to use for this pre-consignment
-	DependsOn          []string `gorm:"type:jsonb;column:depends_on;serializer:json" json:"dependsOn"` // List of pre-consignment template IDs that this pre-consignment template depends on
+	Name                 string   `gorm:"type:varchar(255);column:name;not null" json:"name"`                   // Human-readable name of the pre-consignment template
+	Description          string   `gorm:"type:text;column:description" json:"description"`                      // Optional description of the pre-consignment template
+	WorkflowTemplateID   string   `json:"workflowTemplateId"`                                                   // ID of the (v1) workflow template to use for this pre-consignment
+	WorkflowTemplateV2ID string   `gorm:"type:text;column:workflow_template_v2_id" json:"workflowTemplateV2Id"` // ID of the workflow template (v2) run on the Temporal runtime
+	DependsOn            []string `gorm:"type:jsonb;column:depends_on;serializer:json" json:"dependsOn"`        // List of pre-consignment template IDs that this pre-consignment template depends on
 }
 
 func (pct *PreConsignmentTemplate) TableName() string {
@@ -26,6 +27,7 @@ type PreConsignment struct {
 	TraderID                 string              `gorm:"type:varchar(255);not null" json:"traderId"`
 	PreConsignmentTemplateID string              `gorm:"type:text;not null" json:"preConsignmentTemplateId"`
 	State                    PreConsignmentState `gorm:"type:varchar(50);not null" json:"state"`
+	TraderContext            map[string]any      `gorm:"type:jsonb;column:trader_context;serializer:json;not null" json:"traderContext"` // Trader context accumulated during workflow execution
 
 	// Relationships
 	PreConsignmentTemplate PreConsignmentTemplate `gorm:"foreignKey:PreConsignmentTemplateID;references:ID" json:"-"` // Associated PreConsignmentTemplate
@@ -86,4 +88,5 @@ type PreConsignmentResponseDTO struct {
 	UpdatedAt              string                            `json:"updatedAt"`              // Timestamp of last update
 	PreConsignmentTemplate PreConsignmentTemplateResponseDTO `json:"preConsignmentTemplate"` // Template details
 	WorkflowNodes          []WorkflowNodeResponseDTO         `json:"workflowNodes"`          // Associated workflow nodes
+	Edges                  []WorkflowEdgeResponseDTO         `json:"edges"`                  // Edges between workflow nodes
 }
@@ -1,25 +1,36 @@
 package router
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
 	"github.com/OpenNSW/nsw/internal/workflow/model"
 	"github.com/OpenNSW/nsw/internal/workflow/service"
 	"github.com/OpenNSW/nsw/utils"
 )
 
+// Authorizer checks that the caller is one of the allowed parties of a pre-consignment.
+type Authorizer interface {
+	AuthorizeWorkflow(ctx context.Context, workflowID string, allow ...plugin.Party) error
+}
+
 // PreConsignmentRouter handles HTTP routing for pre-consignment endpoints.
 type PreConsignmentRouter struct {
-	pcs *service.PreConsignmentService
+	pcs   *service.PreConsignmentService
+	authz Authorizer
 }
 
 // NewPreConsignmentRouter creates a new PreConsignmentRouter.
-func NewPreConsignmentRouter(pcs *service.PreConsignmentService) *PreConsignmentRouter {
+func NewPreConsignmentRouter(pcs *service.PreConsignmentService, authorizer Authorizer) *PreConsignmentRouter {
 	return &PreConsignmentRouter{
-		pcs: pcs,
+		pcs:   pcs,
+		authz: authorizer,
 	}
 }
 
@@ -135,6 +146,11 @@ func (r *PreConsignmentRouter) HandleGetPreConsignmentByID(w http.ResponseWriter
 	}
 
 	preConsignmentID := preConsignmentIDStr
+	// The trader context carries the trader's TIN and VAT numbers; only its owner may read it.
+	if err := r.authz.AuthorizeWorkflow(ctx, preConsignmentID, plugin.PartyTrader); err != nil {
+		writeAuthorizationError(w, err)
+		return
+	}
 
 	preConsignment, err := r.pcs.GetPreConsignmentByID(req.Context(), preConsignmentID)
 	if err != nil {
@@ -151,3 +167,16 @@ func (r *PreConsignmentRouter) HandleGetPreConsignmentByID(w http.ResponseWriter
 		return
 	}
 }
+
+// writeAuthorizationError writes the HTTP error for a failed authorization check.
+func writeAuthorizationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, authz.ErrUnauthenticated):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, authz.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		slog.Error("failed to authorize pre-consignment access", "error", err)
+		http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+	}
+}
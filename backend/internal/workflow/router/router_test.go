@@ -19,8 +19,8 @@ import (
 	"gorm.io/gorm/logger"
 
 	"github.com/OpenNSW/nsw/internal/auth"
-	taskManager "github.com/OpenNSW/nsw/internal/task/manager"
-	workflowManagerV1 "github.com/OpenNSW/nsw/internal/workflow/manager"
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/task/plugin"
 	"github.com/OpenNSW/nsw/internal/workflow/model"
 	"github.com/OpenNSW/nsw/internal/workflow/service"
 )
@@ -66,32 +66,6 @@ func (m *MockTemplateProvider) GetEndNodeTemplate(ctx context.Context) (*model.W
 	return args.Get(0).(*model.WorkflowNodeTemplate), args.Error(1)
 }
 
-// MockWorkflowManager implements manager.Manager for testing.
-type MockWorkflowManager struct {
-	mock.Mock
-}
-
-func (m *MockWorkflowManager) StartWorkflowInstance(ctx context.Context, tx *gorm.DB, workflowID string, workflowTemplates []model.WorkflowTemplate, globalContext map[string]any, handler workflowManagerV1.WorkflowEventHandler) error {
-	args := m.Called(ctx, tx, workflowID, workflowTemplates, globalContext, handler)
-	return args.Error(0)
-}
-
-func (m *MockWorkflowManager) GetWorkflowInstance(ctx context.Context, workflowID string) (*model.Workflow, error) {
-	args := m.Called(ctx, workflowID)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*model.Workflow), args.Error(1)
-}
-
-func (m *MockWorkflowManager) RegisterTaskHandler(_ workflowManagerV1.TaskInitHandler) error {
-	return nil
-}
-
-func (m *MockWorkflowManager) HandleTaskUpdate(_ context.Context, _ taskManager.WorkflowManagerNotification) error {
-	return nil
-}
-
 // MockWMV2 implements workflowManagerV2.TemporalManager for testing.
 type MockWMV2 struct {
 	mock.Mock
@@ -141,6 +115,14 @@ func setupRouterTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
 	return db, sqlMock
 }
 
+type stubAuthorizer struct {
+	err error
+}
+
+func (a *stubAuthorizer) AuthorizeWorkflow(_ context.Context, _ string, _ ...plugin.Party) error {
+	return a.err
+}
+
 func withAuthContext(ctx context.Context, userID string) context.Context {
 	authCtx := &auth.AuthContext{
 		User: &auth.UserContext{
@@ -152,33 +134,29 @@ func withAuthContext(ctx context.Context, userID string) context.Context {
 
 func TestPreConsignmentRouter_HandleGetPreConsignmentByID(t *testing.T) {
 	db, sqlMock := setupRouterTestDB(t)
-	mockWM := new(MockWorkflowManager)
-	svc := service.NewPreConsignmentService(db, nil, mockWM)
-	r := NewPreConsignmentRouter(svc)
+	tp := new(MockTemplateProvider)
+	mockWM := new(MockWMV2)
+	svc := service.NewPreConsignmentService(db, tp, nil, nil)
+	assert.NoError(t, svc.RegisterWorkflowManager(mockWM))
+	r := NewPreConsignmentRouter(svc, &stubAuthorizer{})
 
 	id := uuid.NewString()
 	templateID := uuid.NewString()
 	nodeTemplateID := uuid.NewString()
 
 	sqlMock.MatchExpectationsInOrder(false)
-	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignments\"").WillReturnRows(sqlmock.NewRows([]string{"id", "pre_consignment_template_id"}).AddRow(id, templateID))
+	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignments\"").WillReturnRows(sqlmock.NewRows([]string{"id", "state", "pre_consignment_template_id"}).AddRow(id, "IN_PROGRESS", templateID))
 	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignment_templates\"").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(templateID, "Template"))
 
-	mockWM.On("GetWorkflowInstance", mock.Anything, id).Return(&model.Workflow{
-		BaseModel: model.BaseModel{ID: id},
-		Status:    model.WorkflowStatusInProgress,
-		WorkflowNodes: []model.WorkflowNode{
-			{
-				BaseModel:              model.BaseModel{ID: uuid.NewString()},
-				WorkflowNodeTemplateID: nodeTemplateID,
-				State:                  model.WorkflowNodeStateReady,
-				WorkflowNodeTemplate: model.WorkflowNodeTemplate{
-					BaseModel: model.BaseModel{ID: nodeTemplateID},
-					Type:      "TEST",
-				},
-			},
+	mockWM.On("GetStatus", mock.Anything, id).Return(&workflowManagerV2.WorkflowInstance{
+		ID: id,
+		NodeInfo: map[string]*workflowManagerV2.NodeInfo{
+			"task": {ID: "task", Type: workflowManagerV2.NodeTypeTask, TaskTemplateID: nodeTemplateID, Status: workflowManagerV2.NodeStatusRunning},
 		},
 	}, nil)
+	tp.On("GetWorkflowNodeTemplatesByIDs", mock.Anything, []string{nodeTemplateID}).Return([]model.WorkflowNodeTemplate{
+		{BaseModel: model.BaseModel{ID: nodeTemplateID}, Type: "TEST"},
+	}, nil)
 
 	req, _ := http.NewRequest("GET", "/api/v1/pre-consignments/"+id, nil)
 	req.SetPathValue("preConsignmentId", id)
@@ -188,10 +166,45 @@ func TestPreConsignmentRouter_HandleGetPreConsignmentByID(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestPreConsignmentRouter_HandleGetPreConsignmentByID_OtherTrader(t *testing.T) {
+	db, sqlMock := setupRouterTestDB(t)
+	svc := service.NewPreConsignmentService(db, nil, nil, nil)
+	r := NewPreConsignmentRouter(svc, authz.NewPolicy(nil, nil, nil, nil, svc))
+
+	id := uuid.NewString()
+	expectOwner := func() {
+		sqlMock.ExpectQuery("(?i)SELECT \"id\",\"trader_id\" FROM \"pre_consignments\"").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id"}).AddRow(id, "trader1"))
+	}
+	get := func(traderID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/pre-consignments/"+id, nil)
+		req.SetPathValue("preConsignmentId", id)
+		req = req.WithContext(context.WithValue(req.Context(), auth.AuthContextKey, &auth.AuthContext{
+			User: &auth.UserContext{ID: traderID, Roles: []string{authz.RoleTrader}},
+		}))
+		w := httptest.NewRecorder()
+		r.HandleGetPreConsignmentByID(w, req)
+		return w
+	}
+
+	// Another trader is turned away before the pre-consignment is loaded.
+	expectOwner()
+	assert.Equal(t, http.StatusForbidden, get("trader2").Code)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+
+	// The owner gets past authorization to the lookup.
+	expectOwner()
+	sqlMock.ExpectQuery("(?i)SELECT \\* FROM \"pre_consignments\"").WillReturnError(fmt.Errorf("db error"))
+	w := get("trader1")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "failed to retrieve pre-consignment")
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
 func TestPreConsignmentRouter_HandleGetTraderPreConsignments(t *testing.T) {
 	db, sqlMock := setupRouterTestDB(t)
-	svc := service.NewPreConsignmentService(db, nil, nil)
-	r := NewPreConsignmentRouter(svc)
+	svc := service.NewPreConsignmentService(db, nil, nil, nil)
+	r := NewPreConsignmentRouter(svc, &stubAuthorizer{})
 
 	traderID := "trader1"
 	sqlMock.MatchExpectationsInOrder(false)
@@ -210,9 +223,10 @@ func TestPreConsignmentRouter_HandleGetTraderPreConsignments(t *testing.T) {
 func TestPreConsignmentRouter_HandleCreatePreConsignment(t *testing.T) {
 	db, sqlMock := setupRouterTestDB(t)
 	tp := new(MockTemplateProvider)
-	mockWM := new(MockWorkflowManager)
-	svc := service.NewPreConsignmentService(db, tp, mockWM)
-	r := NewPreConsignmentRouter(svc)
+	mockWM := new(MockWMV2)
+	svc := service.NewPreConsignmentService(db, tp, nil, nil)
+	assert.NoError(t, svc.RegisterWorkflowManager(mockWM))
+	r := NewPreConsignmentRouter(svc, &stubAuthorizer{})
 
 	traderID := "trader1"
 	templateID := uuid.NewString()
@@ -224,37 +238,31 @@ func TestPreConsignmentRouter_HandleCreatePreConsignment(t *testing.T) {
 	}
 	body, _ := json.Marshal(payload)
 
-	tp.On("GetWorkflowTemplateByID", mock.Anything, mock.Anything).Return(&model.WorkflowTemplate{BaseModel: model.BaseModel{ID: templateID}, NodeTemplates: []string{nodeTemplateID}}, nil)
+	tp.On("GetWorkflowTemplateByIDV2", mock.Anything, mock.Anything).Return(&model.WorkflowTemplateV2{BaseModel: model.BaseModel{ID: templateID}}, nil)
 
 	sqlMock.MatchExpectationsInOrder(false)
-	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignment_templates\"").WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_template_id", "depends_on"}).AddRow(templateID, uuid.NewString(), []byte("[]")))
+	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignment_templates\"").WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_template_v2_id", "depends_on"}).AddRow(templateID, uuid.NewString(), []byte("[]")))
 
 	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec("(?i)INSERT INTO \"pre_consignments\"").WillReturnResult(sqlmock.NewResult(1, 1))
 
-	mockWM.On("StartWorkflowInstance", mock.Anything, mock.Anything, mock.AnythingOfType("string"), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockWM.On("StartWorkflow", mock.Anything, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return(nil)
 
 	sqlMock.ExpectCommit()
 
 	// Post-commit reloads
-	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignments\"").WillReturnRows(sqlmock.NewRows([]string{"id", "pre_consignment_template_id"}).AddRow(preConsignmentID, templateID))
+	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignments\"").WillReturnRows(sqlmock.NewRows([]string{"id", "state", "pre_consignment_template_id"}).AddRow(preConsignmentID, "IN_PROGRESS", templateID))
 	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignment_templates\"").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(templateID, "Template"))
 
-	mockWM.On("GetWorkflowInstance", mock.Anything, mock.AnythingOfType("string")).Return(&model.Workflow{
-		BaseModel: model.BaseModel{ID: preConsignmentID},
-		Status:    model.WorkflowStatusInProgress,
-		WorkflowNodes: []model.WorkflowNode{
-			{
-				BaseModel:              model.BaseModel{ID: uuid.NewString()},
-				WorkflowNodeTemplateID: nodeTemplateID,
-				State:                  model.WorkflowNodeStateReady,
-				WorkflowNodeTemplate: model.WorkflowNodeTemplate{
-					BaseModel: model.BaseModel{ID: nodeTemplateID},
-					Type:      "TEST",
-				},
-			},
+	mockWM.On("GetStatus", mock.Anything, preConsignmentID).Return(&workflowManagerV2.WorkflowInstance{
+		ID: preConsignmentID,
+		NodeInfo: map[string]*workflowManagerV2.NodeInfo{
+			"task": {ID: "task", Type: workflowManagerV2.NodeTypeTask, TaskTemplateID: nodeTemplateID, Status: workflowManagerV2.NodeStatusRunning},
 		},
 	}, nil)
+	tp.On("GetWorkflowNodeTemplatesByIDs", mock.Anything, []string{nodeTemplateID}).Return([]model.WorkflowNodeTemplate{
+		{BaseModel: model.BaseModel{ID: nodeTemplateID}, Type: "TEST"},
+	}, nil)
 
 	req, _ := http.NewRequest("POST", "/api/v1/pre-consignments", bytes.NewBuffer(body))
 	req = req.WithContext(withAuthContext(req.Context(), traderID))
@@ -265,8 +273,8 @@ func TestPreConsignmentRouter_HandleCreatePreConsignment(t *testing.T) {
 
 func TestPreConsignmentRouter_HandleCreatePreConsignment_InvalidPayload(t *testing.T) {
 	db, _ := setupRouterTestDB(t)
-	svc := service.NewPreConsignmentService(db, nil, nil)
-	r := NewPreConsignmentRouter(svc)
+	svc := service.NewPreConsignmentService(db, nil, nil, nil)
+	r := NewPreConsignmentRouter(svc, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("POST", "/api/v1/pre-consignments", bytes.NewBufferString("invalid json"))
 	req = req.WithContext(withAuthContext(req.Context(), "trader1"))
@@ -277,8 +285,8 @@ func TestPreConsignmentRouter_HandleCreatePreConsignment_InvalidPayload(t *testi
 
 func TestPreConsignmentRouter_HandleGetTraderPreConsignments_PaginationError(t *testing.T) {
 	db, _ := setupRouterTestDB(t)
-	svc := service.NewPreConsignmentService(db, nil, nil)
-	r := NewPreConsignmentRouter(svc)
+	svc := service.NewPreConsignmentService(db, nil, nil, nil)
+	r := NewPreConsignmentRouter(svc, &stubAuthorizer{})
 
 	req, _ := http.NewRequest("GET", "/api/v1/pre-consignments/templates?limit=invalid", nil)
 	req = req.WithContext(withAuthContext(req.Context(), "trader1"))
@@ -291,8 +299,8 @@ func TestPreConsignmentRouter_HandleGetTraderPreConsignments_PaginationError(t *
 
 func TestPreConsignmentRouter_HandleGetTraderPreConsignments_ServiceError(t *testing.T) {
 	db, sqlMock := setupRouterTestDB(t)
-	svc := service.NewPreConsignmentService(db, nil, nil)
-	r := NewPreConsignmentRouter(svc)
+	svc := service.NewPreConsignmentService(db, nil, nil, nil)
+	r := NewPreConsignmentRouter(svc, &stubAuthorizer{})
 
 	sqlMock.ExpectQuery("(?i)SELECT count").WillReturnError(fmt.Errorf("db error"))
 
@@ -305,7 +313,7 @@ func TestPreConsignmentRouter_HandleGetTraderPreConsignments_ServiceError(t *tes
 
 func TestPreConsignmentRouter_HandleGetPreConsignmentByID_InvalidID(t *testing.T) {
 	db, _ := setupRouterTestDB(t)
-	r := NewPreConsignmentRouter(service.NewPreConsignmentService(db, nil, nil))
+	r := NewPreConsignmentRouter(service.NewPreConsignmentService(db, nil, nil, nil), &stubAuthorizer{})
 
 	req, _ := http.NewRequest("GET", "/api/v1/pre-consignments/invalid-uuid", nil)
 	req.SetPathValue("preConsignmentId", "invalid-uuid")
@@ -317,7 +325,7 @@ func TestPreConsignmentRouter_HandleGetPreConsignmentByID_InvalidID(t *testing.T
 
 func TestPreConsignmentRouter_HandleGetPreConsignmentByID_ServiceError(t *testing.T) {
 	db, sqlMock := setupRouterTestDB(t)
-	r := NewPreConsignmentRouter(service.NewPreConsignmentService(db, nil, nil))
+	r := NewPreConsignmentRouter(service.NewPreConsignmentService(db, nil, nil, nil), &stubAuthorizer{})
 
 	id := uuid.NewString()
 	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignments\"").WillReturnError(fmt.Errorf("db error"))
@@ -333,7 +341,7 @@ func TestPreConsignmentRouter_HandleGetPreConsignmentByID_ServiceError(t *testin
 func TestPreConsignmentRouter_HandleCreatePreConsignment_ServiceError(t *testing.T) {
 	db, sqlMock := setupRouterTestDB(t)
 	tp := new(MockTemplateProvider)
-	r := NewPreConsignmentRouter(service.NewPreConsignmentService(db, tp, nil))
+	r := NewPreConsignmentRouter(service.NewPreConsignmentService(db, tp, nil, nil), &stubAuthorizer{})
 
 	templateID := uuid.NewString()
 	sqlMock.ExpectQuery("(?i)SELECT .* FROM \"pre_consignment_templates\"").WillReturnError(fmt.Errorf("db error"))
@@ -8,6 +8,7 @@ import (
 
 	workflowmanager "github.com/OpenNSW/go-temporal-workflow"
 
+	taskcontainer "github.com/OpenNSW/nsw/internal/task/container"
 	taskmanager "github.com/OpenNSW/nsw/internal/task/manager"
 	"github.com/OpenNSW/nsw/internal/workflow/service"
 
@@ -75,7 +76,8 @@ func newRuntimeWithFactory(tm taskmanager.TaskManager, templateProvider service.
 			Config:                 template.Config,
 		}
 
-		if _, err := tm.InitTask(activationCtx, tmRequest); err != nil {
+		// Task start transitions are attributed to the workflow runtime in the task audit trail.
+		if _, err := tm.InitTask(taskcontainer.WithSystemActor(activationCtx, "workflow"), tmRequest); err != nil {
 			return fmt.Errorf("error initializing task manager: %w", err)
 		}
 
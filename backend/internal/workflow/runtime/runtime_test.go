@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	taskManager "github.com/OpenNSW/nsw/internal/task/manager"
+	"github.com/OpenNSW/nsw/internal/task/persistence"
 	"github.com/OpenNSW/nsw/internal/task/plugin"
 	"github.com/OpenNSW/nsw/internal/workflow/model"
 )
@@ -135,6 +136,18 @@ func (m *fakeTaskManager) RegisterUpstreamDoneCallback(callback taskManager.Work
 
 func (m *fakeTaskManager) RegisterUpstreamUpdateCallback(_ taskManager.WorkflowUpdateHandler) {}
 
+func (m *fakeTaskManager) GetTaskHistory(_ context.Context, _ string) ([]persistence.TaskEvent, error) {
+	return nil, nil
+}
+
+func (m *fakeTaskManager) FireDeadline(_ context.Context, _ plugin.Deadline) error {
+	return nil
+}
+
+func (m *fakeTaskManager) RegisterDeadlineScheduler(_ taskManager.DeadlineScheduler) {}
+
+func (m *fakeTaskManager) RegisterTransitionListener(_ taskManager.TransitionListener) {}
+
 func TestNewRuntime_StartWorkerFailureReturnsError(t *testing.T) {
 	fakeManager := &fakeTemporalManager{startErr: errors.New("start failed")}
 	taskMgr := &fakeTaskManager{}
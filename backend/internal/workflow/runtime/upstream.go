@@ -1,5 +1,70 @@
 package runtime
 
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
 type UpstreamService interface {
 	CompletionHandler(workflowID string, finalContext map[string]any) error
 }
+
+// OwnedUpstreamService is an UpstreamService that can tell whether a workflow belongs to it.
+type OwnedUpstreamService interface {
+	UpstreamService
+	OwnsWorkflow(ctx context.Context, workflowID string) (bool, error)
+}
+
+// CompletionListener is notified after the owning upstream service has handled a workflow completion.
+type CompletionListener func(ctx context.Context, workflowID string)
+
+// UpstreamRouter dispatches workflow completions to the upstream service that owns the workflow.
+// It lets several domains (e.g. consignments and pre-consignments) share one workflow runtime.
+type UpstreamRouter struct {
+	services []OwnedUpstreamService
+
+	mu        sync.RWMutex
+	listeners []CompletionListener
+}
+
+// NewUpstreamRouter creates an UpstreamRouter over the given services. Services are consulted in order.
+func NewUpstreamRouter(services ...OwnedUpstreamService) *UpstreamRouter {
+	return &UpstreamRouter{services: services}
+}
+
+// RegisterCompletionListener registers a listener for handled workflow completions.
+func (r *UpstreamRouter) RegisterCompletionListener(listener CompletionListener) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// CompletionHandler forwards the completion to the first service that owns the workflow,
+// then notifies the registered completion listeners.
+func (r *UpstreamRouter) CompletionHandler(workflowID string, finalContext map[string]any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), activationTimeout)
+	defer cancel()
+
+	for _, service := range r.services {
+		owns, err := service.OwnsWorkflow(ctx, workflowID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve owner of workflow %s: %w", workflowID, err)
+		}
+		if owns {
+			if err := service.CompletionHandler(workflowID, finalContext); err != nil {
+				return err
+			}
+			r.mu.RLock()
+			listeners := r.listeners
+			r.mu.RUnlock()
+			for _, listener := range listeners {
+				listener(ctx, workflowID)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no upstream service owns workflow %s", workflowID)
+}
+
+var _ UpstreamService = (*UpstreamRouter)(nil)
@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOwnedUpstreamService struct {
+	fakeUpstreamService
+	owned    map[string]bool
+	ownerErr error
+}
+
+func (s *fakeOwnedUpstreamService) OwnsWorkflow(_ context.Context, workflowID string) (bool, error) {
+	if s.ownerErr != nil {
+		return false, s.ownerErr
+	}
+	return s.owned[workflowID], nil
+}
+
+func TestUpstreamRouter_DispatchesToOwner(t *testing.T) {
+	consignments := &fakeOwnedUpstreamService{owned: map[string]bool{"c-1": true}}
+	preConsignments := &fakeOwnedUpstreamService{owned: map[string]bool{"pc-1": true}}
+	router := NewUpstreamRouter(consignments, preConsignments)
+
+	require.NoError(t, router.CompletionHandler("pc-1", map[string]any{"k": "v"}))
+	assert.False(t, consignments.completionCalled)
+	assert.True(t, preConsignments.completionCalled)
+	assert.Equal(t, "pc-1", preConsignments.workflowID)
+	assert.Equal(t, map[string]any{"k": "v"}, preConsignments.finalContext)
+}
+
+func TestUpstreamRouter_NoOwner(t *testing.T) {
+	router := NewUpstreamRouter(&fakeOwnedUpstreamService{})
+
+	err := router.CompletionHandler("unknown", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no upstream service owns workflow unknown")
+}
+
+func TestUpstreamRouter_OwnerLookupError(t *testing.T) {
+	failing := &fakeOwnedUpstreamService{ownerErr: errors.New("db down")}
+	fallback := &fakeOwnedUpstreamService{owned: map[string]bool{"wf-1": true}}
+	router := NewUpstreamRouter(failing, fallback)
+
+	err := router.CompletionHandler("wf-1", nil)
+	require.Error(t, err)
+	assert.False(t, fallback.completionCalled)
+}
+
+func TestUpstreamRouter_NotifiesCompletionListeners(t *testing.T) {
+	owner := &fakeOwnedUpstreamService{owned: map[string]bool{"c-1": true}}
+	router := NewUpstreamRouter(owner)
+	var completed []string
+	router.RegisterCompletionListener(func(_ context.Context, workflowID string) {
+		completed = append(completed, workflowID)
+	})
+
+	require.NoError(t, router.CompletionHandler("c-1", nil))
+	assert.Equal(t, []string{"c-1"}, completed)
+
+	require.Error(t, router.CompletionHandler("unknown", nil))
+	assert.Equal(t, []string{"c-1"}, completed)
+}
@@ -25,5 +25,10 @@ type TemplateProvider interface {
 	GetEndNodeTemplate(ctx context.Context) (*model.WorkflowNodeTemplate, error)
 }
 
+// TaskTypeRegistry reports which task plugin types are available to workflow nodes.
+type TaskTypeRegistry interface {
+	IsRegistered(taskType model.WorkflowNodeTemplateType) bool
+}
+
 // Compile-time interface compliance checks
 var _ TemplateProvider = (*TemplateService)(nil)
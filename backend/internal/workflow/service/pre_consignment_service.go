@@ -2,57 +2,106 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"time"
 
 	"gorm.io/gorm"
 
-	workflowmanager "github.com/OpenNSW/nsw/internal/workflow/manager"
+	workflowmanager "github.com/OpenNSW/go-temporal-workflow"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/profile/company"
+	"github.com/OpenNSW/nsw/internal/profile/user"
 	"github.com/OpenNSW/nsw/internal/workflow/model"
 	"github.com/OpenNSW/nsw/utils"
 )
 
 // PreConsignmentService provides operations related to pre-consignments.
-// It also implements WorkflowEventHandler for domain-specific lifecycle callbacks.
+// Pre-consignment workflows run on the Temporal workflow runtime; the service receives their
+// completion through CompletionHandler.
 type PreConsignmentService struct {
 	db               *gorm.DB
 	templateProvider TemplateProvider
-	workflowManager  workflowmanager.Manager
+	wm               workflowmanager.Manager
+	userService      user.Service
+	companyService   company.Service
 }
 
 // NewPreConsignmentService creates a new instance of PreConsignmentService with the provided dependencies.
-func NewPreConsignmentService(db *gorm.DB, templateProvider TemplateProvider, workflowManager workflowmanager.Manager) *PreConsignmentService {
+// The user and company services are used to write the trader context back to the trader's company
+// profile on completion; either may be nil, in which case that step is skipped.
+func NewPreConsignmentService(db *gorm.DB, templateProvider TemplateProvider, userService user.Service, companyService company.Service) *PreConsignmentService {
 	return &PreConsignmentService{
 		db:               db,
 		templateProvider: templateProvider,
-		workflowManager:  workflowManager,
+		userService:      userService,
+		companyService:   companyService,
+	}
+}
+
+// RegisterWorkflowManager registers the workflow manager
+func (s *PreConsignmentService) RegisterWorkflowManager(wm workflowmanager.Manager) error {
+	if s.wm != nil {
+		return fmt.Errorf("workflow manager already registered for PreConsignmentService")
+	}
+	if wm == nil {
+		return fmt.Errorf("workflow manager cannot be nil")
 	}
+	s.wm = wm
+	return nil
 }
 
-// --- WorkflowEventHandler implementation ---
+// OwnsWorkflow reports whether the workflow with the given ID belongs to a pre-consignment.
+// Pre-consignment workflows share the ID of the pre-consignment that started them.
+func (s *PreConsignmentService) OwnsWorkflow(ctx context.Context, workflowID string) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.PreConsignment{}).Where("id = ?", workflowID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to look up pre-consignment %s: %w", workflowID, err)
+	}
+	return count > 0, nil
+}
 
-// OnWorkflowStatusChanged handles workflow lifecycle state propagation to pre-consignment domain state.
-func (s *PreConsignmentService) OnWorkflowStatusChanged(_ context.Context, tx *gorm.DB, workflowID string, _ model.WorkflowStatus, toStatus model.WorkflowStatus, workflow *model.Workflow) error {
+// WorkflowParties implements authz.PartyResolver for pre-consignment workflows.
+// Returns nil if the workflow does not belong to a pre-consignment.
+func (s *PreConsignmentService) WorkflowParties(ctx context.Context, workflowID string) (*authz.Parties, error) {
 	var preConsignment model.PreConsignment
-	if err := tx.First(&preConsignment, "id = ?", workflowID).Error; err != nil {
-		return fmt.Errorf("failed to retrieve pre-consignment %s: %w", workflowID, err)
+	err := s.db.WithContext(ctx).Select("id", "trader_id").Where("id = ?", workflowID).Take(&preConsignment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up pre-consignment %s: %w", workflowID, err)
 	}
+	return &authz.Parties{TraderID: preConsignment.TraderID}, nil
+}
+
+// CompletionHandler is called by the workflow runtime when a pre-consignment workflow completes.
+// It marks the pre-consignment as COMPLETED and writes the final workflow context back as trader context.
+func (s *PreConsignmentService) CompletionHandler(workflowID string, finalContext map[string]any) error {
+	ctx := context.Background()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var preConsignment model.PreConsignment
+		if err := tx.First(&preConsignment, "id = ?", workflowID).Error; err != nil {
+			return fmt.Errorf("failed to retrieve pre-consignment %s: %w", workflowID, err)
+		}
+
+		traderContext := make(map[string]any, len(preConsignment.TraderContext)+len(finalContext))
+		maps.Copy(traderContext, preConsignment.TraderContext)
+		maps.Copy(traderContext, finalContext)
 
-	switch toStatus {
-	case model.WorkflowStatusCompleted:
 		preConsignment.State = model.PreConsignmentStateCompleted
+		preConsignment.TraderContext = traderContext
 		if err := tx.Save(&preConsignment).Error; err != nil {
 			return fmt.Errorf("failed to update pre-consignment %s state to COMPLETED: %w", workflowID, err)
 		}
-		if workflow == nil {
-			return fmt.Errorf("workflow payload cannot be nil for completed state")
-		}
-		if err := s.syncTraderContextToAuth(tx, &preConsignment, workflow.GlobalContext); err != nil {
-			return fmt.Errorf("failed to sync trader context to auth: %w", err)
+		if err := s.syncTraderContextToCompany(ctx, tx, &preConsignment, finalContext); err != nil {
+			return fmt.Errorf("failed to sync trader context to company profile: %w", err)
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // GetTraderPreConsignments retrieves a paginated list of pre-consignment templates and computes their state
@@ -196,9 +245,12 @@ func (s *PreConsignmentService) initializePreConsignmentInTx(
 	}
 
 	// Fetch the workflow template referenced by the pre-consignment template
-	workflowTemplate, err := s.templateProvider.GetWorkflowTemplateByID(ctx, pcTemplate.WorkflowTemplateID)
+	if pcTemplate.WorkflowTemplateV2ID == "" {
+		return nil, fmt.Errorf("pre-consignment template %s has no workflow template configured", pcTemplate.ID)
+	}
+	workflowTemplate, err := s.templateProvider.GetWorkflowTemplateByIDV2(ctx, pcTemplate.WorkflowTemplateV2ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow template %s: %w", pcTemplate.WorkflowTemplateID, err)
+		return nil, fmt.Errorf("failed to get workflow template %s: %w", pcTemplate.WorkflowTemplateV2ID, err)
 	}
 
 	// Begin transaction
@@ -214,14 +266,15 @@ func (s *PreConsignmentService) initializePreConsignmentInTx(
 		TraderID:                 traderId,
 		PreConsignmentTemplateID: createReq.PreConsignmentTemplateID,
 		State:                    model.PreConsignmentStateInProgress,
+		TraderContext:            initialTraderContext,
 	}
 	if err := tx.Create(preConsignment).Error; err != nil {
 		tx.Rollback()
 		return nil, fmt.Errorf("failed to create pre-consignment: %w", err)
 	}
 
-	// Register workflow with the manager (creates Workflow entity + nodes + registers with TM)
-	if err := s.workflowManager.StartWorkflowInstance(ctx, tx, preConsignment.ID, []model.WorkflowTemplate{*workflowTemplate}, initialTraderContext, s); err != nil {
+	// Start the workflow run; the pre-consignment ID doubles as the workflow ID.
+	if err := s.wm.StartWorkflow(ctx, preConsignment.ID, workflowTemplate.WorkflowDefinition, initialTraderContext); err != nil {
 		tx.Rollback()
 		return nil, fmt.Errorf("failed to register workflow: %w", err)
 	}
@@ -239,14 +292,7 @@ func (s *PreConsignmentService) initializePreConsignmentInTx(
 		return nil, fmt.Errorf("failed to reload pre-consignment: %w", err)
 	}
 
-	// Get workflow details for response
-	wf, err := s.workflowManager.GetWorkflowInstance(ctx, preConsignment.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow details: %w", err)
-	}
-
-	responseDTO := s.buildPreConsignmentResponseDTO(preConsignment, wf)
-	return responseDTO, nil
+	return s.buildPreConsignmentResponseDTO(ctx, preConsignment)
 }
 
 // GetPreConsignmentsByTraderID retrieves all pre-consignments for a trader (excluding LOCKED state).
@@ -266,12 +312,10 @@ func (s *PreConsignmentService) GetPreConsignmentsByTraderID(ctx context.Context
 
 	responseDTOs := make([]model.PreConsignmentResponseDTO, 0, len(preConsignments))
 	for i := range preConsignments {
-		// Get workflow details for each pre-consignment
-		wf, err := s.workflowManager.GetWorkflowInstance(ctx, preConsignments[i].ID)
+		responseDTO, err := s.buildPreConsignmentResponseDTO(ctx, &preConsignments[i])
 		if err != nil {
-			return nil, fmt.Errorf("failed to get workflow details for pre-consignment %s: %w", preConsignments[i].ID, err)
+			return nil, fmt.Errorf("failed to build response for pre-consignment %s: %w", preConsignments[i].ID, err)
 		}
-		responseDTO := s.buildPreConsignmentResponseDTO(&preConsignments[i], wf)
 		responseDTOs = append(responseDTOs, *responseDTO)
 	}
 
@@ -288,55 +332,62 @@ func (s *PreConsignmentService) GetPreConsignmentByID(ctx context.Context, preCo
 		return nil, fmt.Errorf("failed to retrieve pre-consignment with ID %s: %w", preConsignmentID, result.Error)
 	}
 
-	wf, err := s.workflowManager.GetWorkflowInstance(ctx, preConsignment.ID)
+	return s.buildPreConsignmentResponseDTO(ctx, &preConsignment)
+}
+
+// syncTraderContextToCompany merges the trader context accumulated by a completed pre-consignment into
+// the company profile of the trader's organisation within tx, so that it is rolled back together with
+// the completion. Traders without a resolvable company are skipped.
+func (s *PreConsignmentService) syncTraderContextToCompany(ctx context.Context, tx *gorm.DB, preConsignment *model.PreConsignment, traderContext map[string]any) error {
+	if s.userService == nil || s.companyService == nil || len(traderContext) == 0 {
+		return nil
+	}
+
+	trader, err := s.userService.GetUser(preConsignment.TraderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow details: %w", err)
+		if errors.Is(err, user.ErrUserNotFound) {
+			slog.WarnContext(ctx, "trader not found, skipping company profile sync", "pre_consignment_id", preConsignment.ID, "trader_id", preConsignment.TraderID)
+			return nil
+		}
+		return fmt.Errorf("failed to retrieve trader %s: %w", preConsignment.TraderID, err)
+	}
+	if trader == nil || trader.OUID == "" {
+		slog.WarnContext(ctx, "trader has no organisation, skipping company profile sync", "pre_consignment_id", preConsignment.ID, "trader_id", preConsignment.TraderID)
+		return nil
 	}
 
-	responseDTO := s.buildPreConsignmentResponseDTO(&preConsignment, wf)
-	return responseDTO, nil
-}
+	companyRecord, err := s.companyService.GetCompanyByOUId(ctx, trader.OUID)
+	if err != nil {
+		if errors.Is(err, company.ErrCompanyNotFound) {
+			slog.WarnContext(ctx, "company not found, skipping company profile sync", "pre_consignment_id", preConsignment.ID, "ou_id", trader.OUID)
+			return nil
+		}
+		return fmt.Errorf("failed to retrieve company for organisation %s: %w", trader.OUID, err)
+	}
 
-// syncTraderContextToAuth synchronizes the trader context (from the workflow's global context) to the user profile.
-// This is called when a pre-consignment is completed to persist accumulated context.
-// It updates the Data field on the company profile Record.
-// TODO: Once the Company Profile Service is implemented, we should merge the pre-consignment results with the existing company profile data instead of overwriting it.
-// TODO: This function name and signature may need to be refactored as well once we have a clearer picture of the data flow and ownership between pre-consignment, company profile, and user profile.
-func (s *PreConsignmentService) syncTraderContextToAuth(_ *gorm.DB, _ *model.PreConsignment, _ map[string]any) error {
-	return nil
+	return s.companyService.UpdateCompanyInTx(ctx, tx, companyRecord.ID, traderContext)
 }
 
 // buildPreConsignmentResponseDTO builds a PreConsignmentResponseDTO from a PreConsignment.
-// The workflow parameter provides the workflow nodes and global context (trader context).
-func (s *PreConsignmentService) buildPreConsignmentResponseDTO(preConsignment *model.PreConsignment, workflow *model.Workflow) *model.PreConsignmentResponseDTO {
-	var nodeResponseDTOs []model.WorkflowNodeResponseDTO
-	if workflow != nil {
-		nodeResponseDTOs = make([]model.WorkflowNodeResponseDTO, 0, len(workflow.WorkflowNodes))
-		for _, node := range workflow.WorkflowNodes {
-			nodeResponseDTOs = append(nodeResponseDTOs, model.WorkflowNodeResponseDTO{
-				ID:        node.ID,
-				CreatedAt: node.CreatedAt.Format(time.RFC3339),
-				UpdatedAt: node.UpdatedAt.Format(time.RFC3339),
-				WorkflowNodeTemplate: model.WorkflowNodeTemplateResponseDTO{
-					Name:        node.WorkflowNodeTemplate.Name,
-					Description: node.WorkflowNodeTemplate.Description,
-					Type:        string(node.WorkflowNodeTemplate.Type),
-				},
-				State:         node.State,
-				ExtendedState: node.ExtendedState,
-				Outcome:       node.Outcome,
-				DependsOn:     node.DependsOn,
-			})
+// Workflow nodes are only loaded for pre-consignments that have a running or finished workflow.
+func (s *PreConsignmentService) buildPreConsignmentResponseDTO(ctx context.Context, preConsignment *model.PreConsignment) (*model.PreConsignmentResponseDTO, error) {
+	var workflowInstance *workflowmanager.WorkflowInstance
+	if preConsignment.State == model.PreConsignmentStateInProgress || preConsignment.State == model.PreConsignmentStateCompleted {
+		instance, err := s.wm.GetStatus(ctx, preConsignment.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workflow details: %w", err)
 		}
+		workflowInstance = instance
 	}
-	if nodeResponseDTOs == nil {
-		nodeResponseDTOs = []model.WorkflowNodeResponseDTO{}
+
+	nodeResponseDTOs, edgeResponseDTOs, err := BuildWorkflowGraphDTOs(ctx, s.templateProvider, workflowInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build workflow graph for pre-consignment %s: %w", preConsignment.ID, err)
 	}
 
-	// Populate TraderContext from the Workflow's GlobalContext for backward compatibility
-	var traderContext map[string]any
-	if workflow != nil {
-		traderContext = workflow.GlobalContext
+	traderContext := preConsignment.TraderContext
+	if traderContext == nil {
+		traderContext = map[string]any{}
 	}
 
 	dependsOn := preConsignment.PreConsignmentTemplate.DependsOn
@@ -358,5 +409,6 @@ func (s *PreConsignmentService) buildPreConsignmentResponseDTO(preConsignment *m
 			DependsOn:   dependsOn,
 		},
 		WorkflowNodes: nodeResponseDTOs,
-	}
+		Edges:         edgeResponseDTOs,
+	}, nil
 }
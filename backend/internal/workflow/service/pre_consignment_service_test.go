@@ -7,47 +7,145 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	workflowManagerV2 "github.com/OpenNSW/go-temporal-workflow"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
-	taskManager "github.com/OpenNSW/nsw/internal/task/manager"
-	workflowManagerV1 "github.com/OpenNSW/nsw/internal/workflow/manager"
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/internal/profile/company"
+	"github.com/OpenNSW/nsw/internal/profile/user"
 	"github.com/OpenNSW/nsw/internal/workflow/model"
 )
 
-// MockWorkflowManager implements v1 workflow manager for pre-consignment tests.
-type MockWorkflowManager struct {
+// MockWMV2 implements workflowManagerV2.Manager for pre-consignment tests.
+type MockWMV2 struct {
 	mock.Mock
 }
 
-func (m *MockWorkflowManager) StartWorkflowInstance(ctx context.Context, tx *gorm.DB, workflowID string, workflowTemplates []model.WorkflowTemplate, globalContext map[string]any, handler workflowManagerV1.WorkflowEventHandler) error {
-	args := m.Called(ctx, tx, workflowID, workflowTemplates, globalContext, handler)
+func (m *MockWMV2) StartWorkflow(ctx context.Context, ID string, workflowDefinition workflowManagerV2.WorkflowDefinition, initialWorkflowVariables map[string]any) error {
+	args := m.Called(ctx, ID, workflowDefinition, initialWorkflowVariables)
 	return args.Error(0)
 }
 
-func (m *MockWorkflowManager) GetWorkflowInstance(ctx context.Context, workflowID string) (*model.Workflow, error) {
+func (m *MockWMV2) TaskDone(ctx context.Context, workflowID, runID, nodeID string, output map[string]any) error {
+	args := m.Called(ctx, workflowID, runID, nodeID, output)
+	return args.Error(0)
+}
+
+func (m *MockWMV2) TaskUpdate(ctx context.Context, workflowID, runID string, update workflowManagerV2.UpdateEvent) error {
+	args := m.Called(ctx, workflowID, runID, update)
+	return args.Error(0)
+}
+
+func (m *MockWMV2) GetStatus(ctx context.Context, workflowID string) (*workflowManagerV2.WorkflowInstance, error) {
 	args := m.Called(ctx, workflowID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*model.Workflow), args.Error(1)
+	return args.Get(0).(*workflowManagerV2.WorkflowInstance), args.Error(1)
+}
+
+// MockUserService implements user.Service for testing.
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) GetUser(id string) (*user.Record, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.Record), args.Error(1)
+}
+
+func (m *MockUserService) GetOrCreateUser(idpUserID, email, phone, ouID string) (*string, error) {
+	args := m.Called(idpUserID, email, phone, ouID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*string), args.Error(1)
+}
+
+func (m *MockUserService) UpdateUserData(id string, data []byte) error {
+	return m.Called(id, data).Error(0)
+}
+
+func (m *MockUserService) Health() error {
+	return m.Called().Error(0)
+}
+
+// MockCompanyService implements company.Service for testing.
+type MockCompanyService struct {
+	mock.Mock
 }
 
-func (m *MockWorkflowManager) RegisterTaskHandler(_ workflowManagerV1.TaskInitHandler) error {
-	return nil
+func (m *MockCompanyService) GetCompanyByID(ctx context.Context, id string) (*company.Record, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*company.Record), args.Error(1)
 }
 
-func (m *MockWorkflowManager) HandleTaskUpdate(_ context.Context, _ taskManager.WorkflowManagerNotification) error {
-	return nil
+func (m *MockCompanyService) GetCompanyByOUHandle(ctx context.Context, ouHandle string) (*company.Record, error) {
+	args := m.Called(ctx, ouHandle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*company.Record), args.Error(1)
+}
+
+func (m *MockCompanyService) GetCompanyByOUId(ctx context.Context, ouId string) (*company.Record, error) {
+	args := m.Called(ctx, ouId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*company.Record), args.Error(1)
+}
+
+func (m *MockCompanyService) UpdateCompany(ctx context.Context, id string, data map[string]any) error {
+	return m.Called(ctx, id, data).Error(0)
+}
+
+func (m *MockCompanyService) UpdateCompanyInTx(ctx context.Context, tx *gorm.DB, id string, data map[string]any) error {
+	return m.Called(ctx, tx, id, data).Error(0)
+}
+
+func (m *MockCompanyService) Health(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func newTestPreConsignmentService(t *testing.T, db *gorm.DB, tp TemplateProvider, wm workflowManagerV2.Manager) *PreConsignmentService {
+	t.Helper()
+	svc := NewPreConsignmentService(db, tp, nil, nil)
+	if wm != nil {
+		if err := svc.RegisterWorkflowManager(wm); err != nil {
+			t.Fatalf("failed to register workflow manager: %v", err)
+		}
+	}
+	return svc
+}
+
+func testWorkflowInstance(workflowID, taskTemplateID string) *workflowManagerV2.WorkflowInstance {
+	now := time.Now()
+	return &workflowManagerV2.WorkflowInstance{
+		ID: workflowID,
+		NodeInfo: map[string]*workflowManagerV2.NodeInfo{
+			"start": {ID: "start", Type: "START", Status: workflowManagerV2.NodeStatusCompleted, CreatedAt: now, UpdatedAt: now},
+			"task":  {ID: "task", Type: workflowManagerV2.NodeTypeTask, TaskTemplateID: taskTemplateID, Status: workflowManagerV2.NodeStatusRunning, CreatedAt: now.Add(time.Second), UpdatedAt: now},
+		},
+		Edges: []workflowManagerV2.Edge{{ID: "e1", SourceID: "start", TargetID: "task"}},
+	}
 }
 
 func TestPreConsignmentService_InitializePreConsignment(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	mockTP := new(MockTemplateProvider)
-	mockWM := new(MockWorkflowManager)
-	svc := NewPreConsignmentService(db, mockTP, mockWM)
+	mockWM := new(MockWMV2)
+	svc := newTestPreConsignmentService(t, db, mockTP, mockWM)
 
 	ctx := context.Background()
 	traderID := "trader1"
@@ -58,62 +156,54 @@ func TestPreConsignmentService_InitializePreConsignment(t *testing.T) {
 	initialContext := map[string]any{"key": "value"}
 
 	// Get PreConsignmentTemplate
-	workflowTemplateID := uuid.NewString()
+	workflowTemplateID := "pre-consignment-basic-details-v1"
 	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignment_templates" WHERE id = \$1`).
 		WithArgs(templateID, 1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_template_id", "depends_on"}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_template_v2_id", "depends_on"}).
 			AddRow(templateID, workflowTemplateID, []byte("[]")))
 
 	// Get Workflow Template
-	workflowTemplate := &model.WorkflowTemplate{
-		BaseModel:     model.BaseModel{ID: workflowTemplateID},
-		Name:          "Test WF Template",
-		NodeTemplates: model.StringArray{},
+	workflowTemplate := &model.WorkflowTemplateV2{
+		BaseModel:          model.BaseModel{ID: workflowTemplateID},
+		Name:               "Test WF Template",
+		WorkflowDefinition: workflowManagerV2.WorkflowDefinition{ID: workflowTemplateID},
 	}
-	mockTP.On("GetWorkflowTemplateByID", ctx, workflowTemplateID).Return(workflowTemplate, nil)
+	mockTP.On("GetWorkflowTemplateByIDV2", ctx, workflowTemplateID).Return(workflowTemplate, nil)
 
 	sqlMock.ExpectBegin()
 	sqlMock.ExpectExec(`INSERT INTO "pre_consignments"`).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	mockWM.On("StartWorkflowInstance", ctx, mock.Anything, mock.AnythingOfType("string"), mock.Anything, initialContext, mock.Anything).Return(nil)
+	mockWM.On("StartWorkflow", ctx, mock.AnythingOfType("string"), workflowTemplate.WorkflowDefinition, initialContext).Return(nil)
 	sqlMock.ExpectCommit()
 
 	// Reload pre-consignment with template
 	pcID := uuid.NewString()
 	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignments"`).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id", "state", "created_at", "updated_at", "pre_consignment_template_id"}).
-			AddRow(pcID, traderID, "IN_PROGRESS", time.Now(), time.Now(), templateID))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id", "state", "trader_context", "created_at", "updated_at", "pre_consignment_template_id"}).
+			AddRow(pcID, traderID, "IN_PROGRESS", []byte(`{"key":"value"}`), time.Now(), time.Now(), templateID))
 
 	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignment_templates" WHERE "pre_consignment_templates"."id" = \$1`).
 		WithArgs(templateID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(templateID, "Test PC Template"))
 
-	// GetWorkflowInstance for building response DTO
+	// GetStatus for building response DTO
 	nodeTemplateID := uuid.NewString()
-	mockWM.On("GetWorkflowInstance", ctx, mock.AnythingOfType("string")).Return(&model.Workflow{
-		BaseModel:     model.BaseModel{ID: pcID},
-		Status:        model.WorkflowStatusInProgress,
-		GlobalContext: map[string]any{"key": "value"},
-		WorkflowNodes: []model.WorkflowNode{
-			{
-				BaseModel:              model.BaseModel{ID: uuid.NewString()},
-				WorkflowNodeTemplateID: nodeTemplateID,
-				State:                  model.WorkflowNodeStateReady,
-				WorkflowNodeTemplate: model.WorkflowNodeTemplate{
-					BaseModel: model.BaseModel{ID: nodeTemplateID},
-					Name:      "Test Node",
-					Type:      "SIMPLE_FORM",
-				},
-			},
-		},
+	mockWM.On("GetStatus", ctx, pcID).Return(testWorkflowInstance(pcID, nodeTemplateID), nil)
+	mockTP.On("GetWorkflowNodeTemplatesByIDs", ctx, []string{nodeTemplateID}).Return([]model.WorkflowNodeTemplate{
+		{BaseModel: model.BaseModel{ID: nodeTemplateID}, Name: "Test Node", Type: "SIMPLE_FORM"},
 	}, nil)
 
 	resp, err := svc.InitializePreConsignment(ctx, createReq, traderID, initialContext)
 	assert.NoError(t, err)
-	assert.NotNil(t, resp)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, map[string]any{"key": "value"}, resp.TraderContext)
+		assert.Len(t, resp.WorkflowNodes, 2)
+		assert.Equal(t, "Test Node", resp.WorkflowNodes[1].WorkflowNodeTemplate.Name)
+		assert.Len(t, resp.Edges, 1)
+	}
 	mockTP.AssertExpectations(t)
 	mockWM.AssertExpectations(t)
 }
@@ -121,8 +211,8 @@ func TestPreConsignmentService_InitializePreConsignment(t *testing.T) {
 func TestPreConsignmentService_InitializePreConsignment_TemplateNotFound(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	mockTP := new(MockTemplateProvider)
-	mockWM := new(MockWorkflowManager)
-	svc := NewPreConsignmentService(db, mockTP, mockWM)
+	mockWM := new(MockWMV2)
+	svc := newTestPreConsignmentService(t, db, mockTP, mockWM)
 
 	ctx := context.Background()
 	templateID := uuid.NewString()
@@ -143,8 +233,8 @@ func TestPreConsignmentService_InitializePreConsignment_TemplateNotFound(t *test
 func TestPreConsignmentService_InitializePreConsignment_WorkflowTemplateFetchError(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	mockTP := new(MockTemplateProvider)
-	mockWM := new(MockWorkflowManager)
-	svc := NewPreConsignmentService(db, mockTP, mockWM)
+	mockWM := new(MockWMV2)
+	svc := newTestPreConsignmentService(t, db, mockTP, mockWM)
 
 	ctx := context.Background()
 	templateID := uuid.NewString()
@@ -155,21 +245,40 @@ func TestPreConsignmentService_InitializePreConsignment_WorkflowTemplateFetchErr
 
 	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignment_templates" WHERE id = \$1`).
 		WithArgs(templateID, 1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_template_id", "depends_on"}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "workflow_template_v2_id", "depends_on"}).
 			AddRow(templateID, workflowTemplateID, []byte("[]")))
 
-	mockTP.On("GetWorkflowTemplateByID", ctx, workflowTemplateID).Return(nil, errors.New("wf error"))
+	mockTP.On("GetWorkflowTemplateByIDV2", ctx, workflowTemplateID).Return(nil, errors.New("wf error"))
 
 	resp, err := svc.InitializePreConsignment(ctx, createReq, "trader1", nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get workflow template")
 	assert.Nil(t, resp)
+	mockWM.AssertNotCalled(t, "StartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPreConsignmentService_InitializePreConsignment_MissingWorkflowTemplate(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	svc := newTestPreConsignmentService(t, db, new(MockTemplateProvider), new(MockWMV2))
+
+	ctx := context.Background()
+	templateID := uuid.NewString()
+
+	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignment_templates" WHERE id = \$1`).
+		WithArgs(templateID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "depends_on"}).AddRow(templateID, []byte("[]")))
+
+	resp, err := svc.InitializePreConsignment(ctx, &model.CreatePreConsignmentDTO{PreConsignmentTemplateID: templateID}, "trader1", nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no workflow template configured")
+	assert.Nil(t, resp)
 }
 
 func TestPreConsignmentService_GetPreConsignmentByID(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
-	mockWM := new(MockWorkflowManager)
-	svc := NewPreConsignmentService(db, nil, mockWM)
+	mockTP := new(MockTemplateProvider)
+	mockWM := new(MockWMV2)
+	svc := newTestPreConsignmentService(t, db, mockTP, mockWM)
 
 	ctx := context.Background()
 	pcID := uuid.NewString()
@@ -186,27 +295,16 @@ func TestPreConsignmentService_GetPreConsignmentByID(t *testing.T) {
 			WithArgs(templateID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(templateID, "Template"))
 
-		mockWM.On("GetWorkflowInstance", ctx, pcID).Return(&model.Workflow{
-			BaseModel: model.BaseModel{ID: pcID},
-			Status:    model.WorkflowStatusInProgress,
-			WorkflowNodes: []model.WorkflowNode{
-				{
-					BaseModel:              model.BaseModel{ID: uuid.NewString()},
-					WorkflowNodeTemplateID: nodeTemplateID,
-					State:                  model.WorkflowNodeStateReady,
-					WorkflowNodeTemplate: model.WorkflowNodeTemplate{
-						BaseModel: model.BaseModel{ID: nodeTemplateID},
-						Name:      "Node Template",
-						Type:      "SIMPLE_FORM",
-					},
-				},
-			},
+		mockWM.On("GetStatus", ctx, pcID).Return(testWorkflowInstance(pcID, nodeTemplateID), nil).Once()
+		mockTP.On("GetWorkflowNodeTemplatesByIDs", ctx, []string{nodeTemplateID}).Return([]model.WorkflowNodeTemplate{
+			{BaseModel: model.BaseModel{ID: nodeTemplateID}, Name: "Node Template", Type: "SIMPLE_FORM"},
 		}, nil).Once()
 
 		resp, err := svc.GetPreConsignmentByID(ctx, pcID)
 		assert.NoError(t, err)
 		if assert.NotNil(t, resp) {
 			assert.Equal(t, pcID, resp.ID)
+			assert.Equal(t, map[string]any{}, resp.TraderContext)
 		}
 		mockWM.AssertExpectations(t)
 	})
@@ -224,8 +322,9 @@ func TestPreConsignmentService_GetPreConsignmentByID(t *testing.T) {
 
 func TestPreConsignmentService_GetPreConsignmentsByTraderID(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
-	mockWM := new(MockWorkflowManager)
-	svc := NewPreConsignmentService(db, nil, mockWM)
+	mockTP := new(MockTemplateProvider)
+	mockWM := new(MockWMV2)
+	svc := newTestPreConsignmentService(t, db, mockTP, mockWM)
 
 	ctx := context.Background()
 	traderID := "trader1"
@@ -244,21 +343,9 @@ func TestPreConsignmentService_GetPreConsignmentsByTraderID(t *testing.T) {
 			WithArgs(templateID).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(templateID, "Test PC Template"))
 
-		mockWM.On("GetWorkflowInstance", ctx, pcID).Return(&model.Workflow{
-			BaseModel: model.BaseModel{ID: pcID},
-			Status:    model.WorkflowStatusInProgress,
-			WorkflowNodes: []model.WorkflowNode{
-				{
-					BaseModel:              model.BaseModel{ID: uuid.NewString()},
-					WorkflowNodeTemplateID: nodeTemplateID,
-					State:                  model.WorkflowNodeStateReady,
-					WorkflowNodeTemplate: model.WorkflowNodeTemplate{
-						BaseModel: model.BaseModel{ID: nodeTemplateID},
-						Name:      "Node",
-						Type:      "SIMPLE_FORM",
-					},
-				},
-			},
+		mockWM.On("GetStatus", ctx, pcID).Return(testWorkflowInstance(pcID, nodeTemplateID), nil).Once()
+		mockTP.On("GetWorkflowNodeTemplatesByIDs", ctx, []string{nodeTemplateID}).Return([]model.WorkflowNodeTemplate{
+			{BaseModel: model.BaseModel{ID: nodeTemplateID}, Name: "Node", Type: "SIMPLE_FORM"},
 		}, nil).Once()
 
 		results, err := svc.GetPreConsignmentsByTraderID(ctx, traderID)
@@ -279,11 +366,156 @@ func TestPreConsignmentService_GetPreConsignmentsByTraderID(t *testing.T) {
 	})
 }
 
+func TestPreConsignmentService_RegisterWorkflowManager(t *testing.T) {
+	db, _ := setupTestDB(t)
+	svc := NewPreConsignmentService(db, nil, nil, nil)
+
+	assert.Error(t, svc.RegisterWorkflowManager(nil))
+	assert.NoError(t, svc.RegisterWorkflowManager(new(MockWMV2)))
+	assert.Error(t, svc.RegisterWorkflowManager(new(MockWMV2)))
+}
+
+func TestPreConsignmentService_OwnsWorkflow(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	svc := NewPreConsignmentService(db, nil, nil, nil)
+	ctx := context.Background()
+
+	sqlMock.ExpectQuery(`SELECT count\(\*\) FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs("pc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	owns, err := svc.OwnsWorkflow(ctx, "pc-1")
+	assert.NoError(t, err)
+	assert.True(t, owns)
+
+	sqlMock.ExpectQuery(`SELECT count\(\*\) FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs("consignment-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	owns, err = svc.OwnsWorkflow(ctx, "consignment-1")
+	assert.NoError(t, err)
+	assert.False(t, owns)
+}
+
+func TestPreConsignmentService_WorkflowParties(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	svc := NewPreConsignmentService(db, nil, nil, nil)
+	ctx := context.Background()
+
+	sqlMock.ExpectQuery(`SELECT "id","trader_id" FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs("pc-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id"}).AddRow("pc-1", "trader-1"))
+	parties, err := svc.WorkflowParties(ctx, "pc-1")
+	require.NoError(t, err)
+	assert.Equal(t, &authz.Parties{TraderID: "trader-1"}, parties)
+
+	sqlMock.ExpectQuery(`SELECT "id","trader_id" FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs("consignment-1", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	parties, err = svc.WorkflowParties(ctx, "consignment-1")
+	require.NoError(t, err)
+	assert.Nil(t, parties)
+}
+
+func TestPreConsignmentService_CompletionHandler(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	mockUsers := new(MockUserService)
+	mockCompanies := new(MockCompanyService)
+	svc := NewPreConsignmentService(db, nil, mockUsers, mockCompanies)
+
+	pcID := uuid.NewString()
+	finalContext := map[string]any{"tin": "123456789"}
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs(pcID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id", "state", "trader_context", "pre_consignment_template_id"}).
+			AddRow(pcID, "trader1", "IN_PROGRESS", []byte(`{"name":"ACME"}`), uuid.NewString()))
+	sqlMock.ExpectExec(`UPDATE "pre_consignments" SET`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockUsers.On("GetUser", "trader1").Return(&user.Record{ID: "trader1", OUID: "OU-001"}, nil)
+	mockCompanies.On("GetCompanyByOUId", mock.Anything, "OU-001").Return(&company.Record{ID: "company-1"}, nil)
+	// The company profile is merged within the completion's transaction.
+	mockCompanies.On("UpdateCompanyInTx", mock.Anything, mock.MatchedBy(func(tx *gorm.DB) bool { return tx != nil && tx != db }), "company-1", finalContext).Return(nil)
+	sqlMock.ExpectCommit()
+
+	err := svc.CompletionHandler(pcID, finalContext)
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockUsers.AssertExpectations(t)
+	mockCompanies.AssertExpectations(t)
+}
+
+func TestPreConsignmentService_CompletionHandler_CompanySyncFails(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	mockUsers := new(MockUserService)
+	mockCompanies := new(MockCompanyService)
+	svc := NewPreConsignmentService(db, nil, mockUsers, mockCompanies)
+
+	pcID := uuid.NewString()
+	finalContext := map[string]any{"tin": "123456789"}
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs(pcID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id", "state", "pre_consignment_template_id"}).
+			AddRow(pcID, "trader1", "IN_PROGRESS", uuid.NewString()))
+	sqlMock.ExpectExec(`UPDATE "pre_consignments" SET`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockUsers.On("GetUser", "trader1").Return(&user.Record{ID: "trader1", OUID: "OU-001"}, nil)
+	mockCompanies.On("GetCompanyByOUId", mock.Anything, "OU-001").Return(&company.Record{ID: "company-1"}, nil)
+	mockCompanies.On("UpdateCompanyInTx", mock.Anything, mock.Anything, "company-1", finalContext).Return(errors.New("db error"))
+	sqlMock.ExpectRollback()
+
+	err := svc.CompletionHandler(pcID, finalContext)
+	assert.Error(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestPreConsignmentService_CompletionHandler_CompanyNotFound(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	mockUsers := new(MockUserService)
+	mockCompanies := new(MockCompanyService)
+	svc := NewPreConsignmentService(db, nil, mockUsers, mockCompanies)
+
+	pcID := uuid.NewString()
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs(pcID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "trader_id", "state", "pre_consignment_template_id"}).
+			AddRow(pcID, "trader1", "IN_PROGRESS", uuid.NewString()))
+	sqlMock.ExpectExec(`UPDATE "pre_consignments" SET`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockUsers.On("GetUser", "trader1").Return(&user.Record{ID: "trader1", OUID: "OU-001"}, nil)
+	mockCompanies.On("GetCompanyByOUId", mock.Anything, "OU-001").Return(nil, company.ErrCompanyNotFound)
+	sqlMock.ExpectCommit()
+
+	err := svc.CompletionHandler(pcID, map[string]any{"tin": "123456789"})
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockCompanies.AssertNotCalled(t, "UpdateCompanyInTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPreConsignmentService_CompletionHandler_NotFound(t *testing.T) {
+	db, sqlMock := setupTestDB(t)
+	svc := NewPreConsignmentService(db, nil, nil, nil)
+	pcID := uuid.NewString()
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(`SELECT \* FROM "pre_consignments" WHERE id = \$1`).
+		WithArgs(pcID, 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	sqlMock.ExpectRollback()
+
+	err := svc.CompletionHandler(pcID, nil)
+	assert.Error(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
 func TestPreConsignmentService_GetTraderPreConsignments(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
 	mockTP := new(MockTemplateProvider)
-	mockWM := new(MockWorkflowManager)
-	svc := NewPreConsignmentService(db, mockTP, mockWM)
+	mockWM := new(MockWMV2)
+	svc := newTestPreConsignmentService(t, db, mockTP, mockWM)
 
 	ctx := context.Background()
 	traderID := "trader1"
@@ -314,7 +546,7 @@ func TestPreConsignmentService_GetTraderPreConsignments(t *testing.T) {
 
 func TestPreConsignmentService_GetTraderPreConsignments_CountError(t *testing.T) {
 	db, sqlMock := setupTestDB(t)
-	svc := NewPreConsignmentService(db, nil, nil)
+	svc := NewPreConsignmentService(db, nil, nil, nil)
 	ctx := context.Background()
 	traderID := "trader1"
 
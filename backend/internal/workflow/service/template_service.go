@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 
@@ -69,3 +71,23 @@ func (s *TemplateService) GetEndNodeTemplate(ctx context.Context) (*model.Workfl
 	}
 	return &template, nil
 }
+
+// ValidateNodeTemplateTypes checks that every workflow node template refers to a registered task plugin type,
+// so that templates with an unknown type are rejected when they are loaded rather than when a node activates.
+func (s *TemplateService) ValidateNodeTemplateTypes(ctx context.Context, registry TaskTypeRegistry) error {
+	var templates []model.WorkflowNodeTemplate
+	if err := s.db.WithContext(ctx).Select("id", "type").Find(&templates).Error; err != nil {
+		return fmt.Errorf("failed to load workflow node templates: %w", err)
+	}
+
+	var errs []error
+	for _, template := range templates {
+		if template.Type == model.WorkFlowNodeTypeEndNode {
+			continue
+		}
+		if !registry.IsRegistered(template.Type) {
+			errs = append(errs, fmt.Errorf("workflow node template %s has unknown type %q", template.ID, template.Type))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/OpenNSW/nsw/internal/workflow/model"
 )
 
 func TestTemplateService_GetWorkflowNodeTemplatesByIDs(t *testing.T) {
@@ -86,3 +88,40 @@ func TestTemplateService_GetWorkflowNodeTemplateByID(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Equal(t, id, result.ID)
 }
+
+type fakeTaskTypeRegistry map[model.WorkflowNodeTemplateType]bool
+
+func (r fakeTaskTypeRegistry) IsRegistered(taskType model.WorkflowNodeTemplateType) bool {
+	return r[taskType]
+}
+
+func TestTemplateService_ValidateNodeTemplateTypes(t *testing.T) {
+	registry := fakeTaskTypeRegistry{"SIMPLE_FORM": true, "PAYMENT": true}
+
+	t.Run("All Registered", func(t *testing.T) {
+		db, sqlMock := setupTestDB(t)
+		service := NewTemplateService(db)
+
+		sqlMock.ExpectQuery(`SELECT "id","type" FROM "workflow_node_templates"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).
+				AddRow("t1", "SIMPLE_FORM").
+				AddRow("t2", "PAYMENT").
+				AddRow("t3", string(model.WorkFlowNodeTypeEndNode)))
+
+		assert.NoError(t, service.ValidateNodeTemplateTypes(context.Background(), registry))
+	})
+
+	t.Run("Unknown Type", func(t *testing.T) {
+		db, sqlMock := setupTestDB(t)
+		service := NewTemplateService(db)
+
+		sqlMock.ExpectQuery(`SELECT "id","type" FROM "workflow_node_templates"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "type"}).
+				AddRow("t1", "SIMPLE_FORM").
+				AddRow("t2", "SIMPLE_FROM"))
+
+		err := service.ValidateNodeTemplateTypes(context.Background(), registry)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `workflow node template t2 has unknown type "SIMPLE_FROM"`)
+	})
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	workflowmanager "github.com/OpenNSW/go-temporal-workflow"
+
+	"github.com/OpenNSW/nsw/internal/workflow/model"
+)
+
+// BuildWorkflowGraphDTOs converts a Temporal workflow instance into node and edge response DTOs.
+// Task node names, descriptions and types are resolved from their workflow node templates.
+// Nodes are ordered by creation time so that responses are stable across calls.
+func BuildWorkflowGraphDTOs(
+	ctx context.Context,
+	templateProvider TemplateProvider,
+	instance *workflowmanager.WorkflowInstance,
+) ([]model.WorkflowNodeResponseDTO, []model.WorkflowEdgeResponseDTO, error) {
+	nodeResponseDTOs := make([]model.WorkflowNodeResponseDTO, 0)
+	edgeResponseDTOs := make([]model.WorkflowEdgeResponseDTO, 0)
+	if instance == nil {
+		return nodeResponseDTOs, edgeResponseDTOs, nil
+	}
+
+	nodes := make([]*workflowmanager.NodeInfo, 0, len(instance.NodeInfo))
+	taskTemplateIDs := make([]string, 0, len(instance.NodeInfo))
+	for _, node := range instance.NodeInfo {
+		nodes = append(nodes, node)
+		if node.Type == workflowmanager.NodeTypeTask {
+			taskTemplateIDs = append(taskTemplateIDs, node.TaskTemplateID)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if !nodes[i].CreatedAt.Equal(nodes[j].CreatedAt) {
+			return nodes[i].CreatedAt.Before(nodes[j].CreatedAt)
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	taskTemplates, err := templateProvider.GetWorkflowNodeTemplatesByIDs(ctx, taskTemplateIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve workflow node templates: %w", err)
+	}
+	taskTemplateMap := make(map[string]model.WorkflowNodeTemplate, len(taskTemplates))
+	for _, taskTemplate := range taskTemplates {
+		taskTemplateMap[taskTemplate.ID] = taskTemplate
+	}
+
+	for _, node := range nodes {
+		var taskName, taskDescription, taskType string
+		var nodeState model.WorkflowNodeState
+		if node.Type == workflowmanager.NodeTypeTask {
+			taskTemplate, ok := taskTemplateMap[node.TaskTemplateID]
+			if !ok {
+				slog.ErrorContext(ctx, "failed to retrieve workflow node template", "workflow_id", instance.ID, "node_id", node.ID, "task_template_id", node.TaskTemplateID)
+				return nil, nil, fmt.Errorf("failed to retrieve workflow node template %s for node %s", node.TaskTemplateID, node.ID)
+			}
+			taskName = taskTemplate.Name
+			taskDescription = taskTemplate.Description
+			taskType = string(taskTemplate.Type)
+		} else {
+			taskType = string(node.Type)
+		}
+		// TODO: clean up translations once the frontend is updated.
+		switch node.Status {
+		case workflowmanager.NodeStatusRunning:
+			nodeState = model.WorkflowNodeStateInProgress
+		case workflowmanager.NodeStatusCompleted:
+			nodeState = model.WorkflowNodeStateCompleted
+		case workflowmanager.NodeStatusFailed:
+			nodeState = model.WorkflowNodeStateFailed
+		case workflowmanager.NodeStatusNotStarted:
+			nodeState = model.WorkflowNodeStateLocked
+		}
+		nodeResponseDTOs = append(nodeResponseDTOs, model.WorkflowNodeResponseDTO{
+			ID:        node.ID,
+			CreatedAt: node.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: node.UpdatedAt.Format(time.RFC3339),
+			WorkflowNodeTemplate: model.WorkflowNodeTemplateResponseDTO{
+				Name:        taskName,
+				Description: taskDescription,
+				Type:        taskType,
+			},
+			State:     nodeState,
+			DependsOn: []string{}, // TODO: should be removed or should be populated based on the workflow definition (not currently stored in DB for v2 workflows)
+		})
+	}
+	for _, edge := range instance.Edges {
+		edgeResponseDTOs = append(edgeResponseDTOs, model.WorkflowEdgeResponseDTO{
+			ID:        edge.ID,
+			SourceID:  edge.SourceID,
+			TargetID:  edge.TargetID,
+			Condition: edge.Condition,
+		})
+	}
+
+	return nodeResponseDTOs, edgeResponseDTOs, nil
+}
@@ -2,10 +2,14 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 )
 
+// ErrChannelNotRegistered is returned by the Deliver methods when no provider is registered for the channel.
+var ErrChannelNotRegistered = errors.New("notification channel not registered")
+
 // Manager is responsible for handling notification channels and dispatching messages.
 type Manager struct {
 	mu           sync.RWMutex
@@ -33,6 +37,7 @@ func (m *Manager) RegisterSMSChannel(channel SMSChannel) {
 }
 
 // SendEmail dispatches an email notification asynchronously using the registered provider.
+// The send is detached from ctx cancellation so that it outlives the calling request.
 func (m *Manager) SendEmail(ctx context.Context, payload EmailPayload) {
 	m.mu.RLock()
 	channel := m.emailChannel
@@ -43,6 +48,7 @@ func (m *Manager) SendEmail(ctx context.Context, payload EmailPayload) {
 		return
 	}
 
+	ctx = context.WithoutCancel(ctx)
 	go func() {
 		results := channel.Send(ctx, payload)
 		m.logErrors(ctx, "EMAIL", results)
@@ -50,6 +56,7 @@ func (m *Manager) SendEmail(ctx context.Context, payload EmailPayload) {
 }
 
 // SendSMS dispatches an SMS/WhatsApp notification asynchronously using the registered provider.
+// The send is detached from ctx cancellation so that it outlives the calling request.
 func (m *Manager) SendSMS(ctx context.Context, payload SMSPayload) {
 	m.mu.RLock()
 	channel := m.smsChannel
@@ -60,12 +67,39 @@ func (m *Manager) SendSMS(ctx context.Context, payload SMSPayload) {
 		return
 	}
 
+	ctx = context.WithoutCancel(ctx)
 	go func() {
 		results := channel.Send(ctx, payload)
 		m.logErrors(ctx, "SMS", results)
 	}()
 }
 
+// DeliverEmail sends an email notification synchronously and returns the per-recipient results.
+// It returns ErrChannelNotRegistered if no email provider is registered.
+func (m *Manager) DeliverEmail(ctx context.Context, payload EmailPayload) (map[string]error, error) {
+	m.mu.RLock()
+	channel := m.emailChannel
+	m.mu.RUnlock()
+
+	if channel == nil {
+		return nil, ErrChannelNotRegistered
+	}
+	return channel.Send(ctx, payload), nil
+}
+
+// DeliverSMS sends an SMS/WhatsApp notification synchronously and returns the per-recipient results.
+// It returns ErrChannelNotRegistered if no SMS provider is registered.
+func (m *Manager) DeliverSMS(ctx context.Context, payload SMSPayload) (map[string]error, error) {
+	m.mu.RLock()
+	channel := m.smsChannel
+	m.mu.RUnlock()
+
+	if channel == nil {
+		return nil, ErrChannelNotRegistered
+	}
+	return channel.Send(ctx, payload), nil
+}
+
 func (m *Manager) logErrors(ctx context.Context, cType string, results map[string]error) {
 	for recipient, err := range results {
 		if err != nil {
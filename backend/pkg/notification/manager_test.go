@@ -59,3 +59,23 @@ func TestNotificationManager(t *testing.T) {
 		time.Sleep(10 * time.Millisecond)
 	})
 }
+
+type stubSMSChannel struct {
+	results map[string]error
+}
+
+func (s *stubSMSChannel) Send(_ context.Context, _ notification.SMSPayload) map[string]error {
+	return s.results
+}
+
+func TestManager_Deliver(t *testing.T) {
+	manager := notification.NewManager()
+
+	_, err := manager.DeliverEmail(context.Background(), notification.EmailPayload{Recipients: []string{"a@example.com"}})
+	assert.ErrorIs(t, err, notification.ErrChannelNotRegistered)
+
+	manager.RegisterSMSChannel(&stubSMSChannel{results: map[string]error{"+94770000000": nil}})
+	results, err := manager.DeliverSMS(context.Background(), notification.SMSPayload{Recipients: []string{"+94770000000"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]error{"+94770000000": nil}, results)
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -17,6 +18,9 @@ type OAuth2Config struct {
 	ClientID     string   `json:"client_id"`
 	ClientSecret string   `json:"client_secret"`
 	Scopes       []string `json:"scopes,omitempty"`
+	// InsecureSkipVerify skips TLS verification of the token endpoint. DEV-only, for IdPs
+	// with self-signed certificates.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
 }
 
 type OAuth2 struct {
@@ -75,6 +79,11 @@ func (a *OAuth2) getToken(ctx context.Context) (string, error) {
 func (a *OAuth2) refreshToken(ctx context.Context) (string, time.Time, error) {
 	if a.httpClient == nil {
 		a.httpClient = &http.Client{Timeout: 10 * time.Second}
+		if a.cfg.InsecureSkipVerify {
+			a.httpClient.Transport = &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}
+		}
 	}
 
 	data := url.Values{}
@@ -175,3 +175,18 @@ func TestOAuth2_ContextCancel(t *testing.T) {
 	_, err := auth.getToken(ctx)
 	assert.Error(t, err)
 }
+
+func TestOAuth2_InsecureSkipVerify(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"access_token": "self-signed-token", "expires_in": 3600}`))
+	}))
+	defer ts.Close()
+
+	_, err := NewOAuth2(OAuth2Config{TokenURL: ts.URL}).getToken(context.Background())
+	assert.Error(t, err, "self-signed certificate should be rejected by default")
+
+	token, err := NewOAuth2(OAuth2Config{TokenURL: ts.URL, InsecureSkipVerify: true}).getToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "self-signed-token", token)
+}
@@ -23,6 +23,8 @@ type ServiceConfig struct {
 	URL     string      `json:"url"`
 	Timeout string      `json:"timeout"`
 	Auth    *AuthConfig `json:"auth,omitempty"`
+	// CallbackClientIDs are the M2M client IDs the service uses when calling back into NSW.
+	CallbackClientIDs []string `json:"callbackClientIds,omitempty"`
 }
 
 type Registry struct {
@@ -97,17 +99,54 @@ func (m *Manager) Call(ctx context.Context, serviceID string, req Request, respo
 }
 
 func (m *Manager) GetClientByURL(rawURL string) (*Client, string, error) {
+	id, err := m.matchServiceByURL(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := m.GetClient(id)
+	if err != nil {
+		// If a service matches but fails to initialize, it's a configuration error.
+		// We should return this error instead of continuing the search.
+		return nil, "", fmt.Errorf("remote: failed to create client for matched service %q: %w", id, err)
+	}
+	return client, id, nil
+}
+
+// ResolveServiceID returns the ID of the registered service identified by serviceID or,
+// when serviceID is empty, by the base URL that rawURL falls under.
+func (m *Manager) ResolveServiceID(serviceID, rawURL string) (string, error) {
+	if serviceID == "" {
+		return m.matchServiceByURL(rawURL)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.configs[serviceID]; !ok {
+		return "", fmt.Errorf("remote: service %q not found in registry", serviceID)
+	}
+	return serviceID, nil
+}
+
+// CallbackClientIDs returns the M2M client IDs the given service authenticates with when it
+// calls back into NSW. Returns nil for unknown services.
+func (m *Manager) CallbackClientIDs(serviceID string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.configs[serviceID].CallbackClientIDs
+}
+
+// matchServiceByURL returns the ID of the registered service whose base URL rawURL falls under.
+func (m *Manager) matchServiceByURL(rawURL string) (string, error) {
 	if !strings.HasPrefix(rawURL, "http") {
-		return nil, "", fmt.Errorf("remote: cannot resolve service from relative path: %s", rawURL)
+		return "", fmt.Errorf("remote: cannot resolve service from relative path: %s", rawURL)
 	}
 
 	parsedReq, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, "", fmt.Errorf("remote: invalid URL: %w", err)
+		return "", fmt.Errorf("remote: invalid URL: %w", err)
 	}
 
 	m.mu.RLock()
-	// No defer here because we need to release for GetClient call
+	defer m.mu.RUnlock()
 
 	for id, cfg := range m.configs {
 		parsedBase, err := url.Parse(cfg.URL)
@@ -119,20 +158,12 @@ func (m *Manager) GetClientByURL(rawURL string) (*Client, string, error) {
 		if parsedReq.Scheme == parsedBase.Scheme && parsedReq.Host == parsedBase.Host {
 			// Also ensure the path matches the base path if provided
 			if strings.HasPrefix(parsedReq.Path, parsedBase.Path) {
-				m.mu.RUnlock()
-				client, err := m.GetClient(id)
-				if err != nil {
-					// If a service matches but fails to initialize, it's a configuration error.
-					// We should return this error instead of continuing the search.
-					return nil, "", fmt.Errorf("remote: failed to create client for matched service %q: %w", id, err)
-				}
-				return client, id, nil
+				return id, nil
 			}
 		}
 	}
-	m.mu.RUnlock()
 
-	return nil, "", fmt.Errorf("remote: no registered service found for URL: %s", rawURL)
+	return "", fmt.Errorf("remote: no registered service found for URL: %s", rawURL)
 }
 
 func (m *Manager) GetClient(id string) (*Client, error) {
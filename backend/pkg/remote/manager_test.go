@@ -211,3 +211,25 @@ func TestManager_Call_Integration(t *testing.T) {
 	err := manager.Call(context.Background(), "test", Request{Method: "GET", Path: "/"}, nil)
 	assert.NoError(t, err)
 }
+
+func TestManager_ResolveServiceID(t *testing.T) {
+	manager := NewManager()
+	manager.configs["npqs"] = ServiceConfig{ID: "npqs", URL: "http://npqs.local/api", CallbackClientIDs: []string{"NPQS_TO_NSW"}}
+
+	id, err := manager.ResolveServiceID("npqs", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "npqs", id)
+
+	id, err = manager.ResolveServiceID("", "http://npqs.local/api/oga/inject")
+	assert.NoError(t, err)
+	assert.Equal(t, "npqs", id)
+
+	_, err = manager.ResolveServiceID("unknown", "")
+	assert.Error(t, err)
+
+	_, err = manager.ResolveServiceID("", "http://other.local/inject")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"NPQS_TO_NSW"}, manager.CallbackClientIDs("npqs"))
+	assert.Nil(t, manager.CallbackClientIDs("unknown"))
+}
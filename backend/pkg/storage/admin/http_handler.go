@@ -0,0 +1,186 @@
+// Package admin exposes the retention of stored files to administrators over HTTP: legal
+// holds, the purge log and on-demand sweeps. It is kept apart from package storage, which
+// authz depends on.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+const (
+	defaultPurgeLimit = 100
+	maxPurgeLimit     = 1000
+)
+
+// RoleAuthorizer decides whether the caller holds one of a set of roles; satisfied by authz.Policy.
+type RoleAuthorizer interface {
+	AuthorizeRole(ctx context.Context, roles ...string) error
+}
+
+// LegalHoldRequest is the body of a request placing a file on legal hold.
+type LegalHoldRequest struct {
+	Reason string `json:"reason"`
+}
+
+// HTTPHandler exposes the retention of stored files to administrators.
+type HTTPHandler struct {
+	store      storage.RetentionStore
+	sweeper    *storage.Sweeper
+	authorizer RoleAuthorizer
+	now        func() time.Time
+}
+
+// NewHTTPHandler creates a new HTTPHandler.
+func NewHTTPHandler(store storage.RetentionStore, sweeper *storage.Sweeper, authorizer RoleAuthorizer) *HTTPHandler {
+	return &HTTPHandler{
+		store:      store,
+		sweeper:    sweeper,
+		authorizer: authorizer,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// HandlePlaceLegalHold places a stored file on legal hold, which keeps it past its retention
+// period and blocks its deletion until the hold is released.
+// PUT /api/v1/admin/storage/files/{key}/legal-hold
+func (h *HTTPHandler) HandlePlaceLegalHold(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	key := r.PathValue("key")
+	if key == "" {
+		http.Error(w, "key is required in URL", http.StatusBadRequest)
+		return
+	}
+	var req LegalHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+
+	var heldBy string
+	if authCtx := auth.GetAuthContext(r.Context()); authCtx != nil && authCtx.User != nil {
+		heldBy = authCtx.User.ID
+	}
+	h.updateLegalHold(w, r, key, h.store.PlaceLegalHold(r.Context(), key, heldBy, req.Reason, h.now()))
+}
+
+// HandleReleaseLegalHold releases a stored file from legal hold.
+// DELETE /api/v1/admin/storage/files/{key}/legal-hold
+func (h *HTTPHandler) HandleReleaseLegalHold(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	key := r.PathValue("key")
+	if key == "" {
+		http.Error(w, "key is required in URL", http.StatusBadRequest)
+		return
+	}
+	h.updateLegalHold(w, r, key, h.store.ReleaseLegalHold(r.Context(), key))
+}
+
+// updateLegalHold writes the file's record after its legal hold was updated with result err.
+func (h *HTTPHandler) updateLegalHold(w http.ResponseWriter, r *http.Request, key string, err error) {
+	var file *storage.StoredFile
+	if err == nil {
+		file, err = h.store.Get(r.Context(), key)
+	}
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, file)
+	case errors.Is(err, storage.ErrFileNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		slog.ErrorContext(r.Context(), "failed to update legal hold", "key", key, "error", err)
+		http.Error(w, "failed to update legal hold", http.StatusInternalServerError)
+	}
+}
+
+// HandleListPurges returns the files purged by the retention sweeper, most recent first.
+// GET /api/v1/admin/storage/purges?since=<RFC 3339 time>&limit=...
+func (h *HTTPHandler) HandleListPurges(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			http.Error(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	limit := defaultPurgeLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxPurgeLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxPurgeLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	purges, err := h.store.ListPurges(r.Context(), since, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list purged files", "error", err)
+		http.Error(w, "failed to list purged files", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, purges)
+}
+
+// HandleSweep runs a retention sweep now and reports what it purged.
+// POST /api/v1/admin/storage/sweeps
+func (h *HTTPHandler) HandleSweep(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	report, err := h.sweeper.Sweep(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to sweep stored files", "error", err)
+		http.Error(w, "failed to sweep stored files", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// authorize admits administrators, writing the error response otherwise.
+func (h *HTTPHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	err := h.authorizer.AuthorizeRole(r.Context(), authz.RoleAdmin)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, authz.ErrUnauthenticated):
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, authz.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		slog.ErrorContext(r.Context(), "failed to authorize storage retention access", "error", err)
+		http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/OpenNSW/nsw/internal/authz"
+	"github.com/OpenNSW/nsw/pkg/storage"
+)
+
+// stubAuthorizer returns a fixed authorization result; the zero value allows everything.
+type stubAuthorizer struct {
+	err error
+}
+
+func (a stubAuthorizer) AuthorizeRole(context.Context, ...string) error {
+	return a.err
+}
+
+// stubStore implements the parts of storage.RetentionStore the tests use.
+type stubStore struct {
+	storage.RetentionStore
+	files  map[string]storage.StoredFile
+	purges []storage.Purge
+}
+
+func (s *stubStore) Get(_ context.Context, key string) (*storage.StoredFile, error) {
+	file, ok := s.files[key]
+	if !ok {
+		return nil, storage.ErrFileNotFound
+	}
+	return &file, nil
+}
+
+func (s *stubStore) PlaceLegalHold(_ context.Context, key, by, reason string, at time.Time) error {
+	file, ok := s.files[key]
+	if !ok {
+		return storage.ErrFileNotFound
+	}
+	file.LegalHold, file.LegalHoldBy, file.LegalHoldReason, file.LegalHoldAt = true, by, reason, &at
+	s.files[key] = file
+	return nil
+}
+
+func (s *stubStore) ListPurges(_ context.Context, since time.Time, limit int) ([]storage.Purge, error) {
+	var purges []storage.Purge
+	for _, purge := range s.purges {
+		if !purge.PurgedAt.Before(since) && len(purges) < limit {
+			purges = append(purges, purge)
+		}
+	}
+	return purges, nil
+}
+
+func TestHTTPHandler_HandlePlaceLegalHold(t *testing.T) {
+	newRequest := func(key, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/storage/files/"+key+"/legal-hold", strings.NewReader(body))
+		req.SetPathValue("key", key)
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		authorizer RoleAuthorizer
+		key, body  string
+		want       int
+	}{
+		{"places the hold", stubAuthorizer{}, "k1", `{"reason":"Customs investigation"}`, http.StatusOK},
+		{"requires a reason", stubAuthorizer{}, "k1", `{"reason":""}`, http.StatusBadRequest},
+		{"unknown file", stubAuthorizer{}, "missing", `{"reason":"Customs investigation"}`, http.StatusNotFound},
+		{"requires the admin role", stubAuthorizer{err: authz.ErrForbidden}, "k1", `{"reason":"Customs investigation"}`, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &stubStore{files: map[string]storage.StoredFile{"k1": {Key: "k1"}}}
+			rec := httptest.NewRecorder()
+			NewHTTPHandler(store, nil, tt.authorizer).HandlePlaceLegalHold(rec, newRequest(tt.key, tt.body))
+			require.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if tt.want == http.StatusOK {
+				var file storage.StoredFile
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&file))
+				assert.True(t, file.LegalHold)
+				assert.Equal(t, "Customs investigation", file.LegalHoldReason)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_HandleListPurges(t *testing.T) {
+	purgedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	store := &stubStore{purges: []storage.Purge{
+		{Key: "k2", Reason: storage.PurgeExpired, PurgedAt: purgedAt},
+		{Key: "k1", Reason: storage.PurgeAbandoned, PurgedAt: purgedAt.Add(-48 * time.Hour)},
+	}}
+	handler := NewHTTPHandler(store, nil, stubAuthorizer{})
+
+	rec := httptest.NewRecorder()
+	handler.HandleListPurges(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/storage/purges?since=2026-09-30T00:00:00Z", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var purges []storage.Purge
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&purges))
+	require.Len(t, purges, 1)
+	assert.Equal(t, "k2", purges[0].Key)
+
+	rec = httptest.NewRecorder()
+	handler.HandleListPurges(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/storage/purges?since=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -1,14 +1,16 @@
 package storage
 
 import (
+	"encoding/base64"
 	"fmt"
 	"time"
 
 	"github.com/OpenNSW/nsw/internal/validation"
+	"github.com/OpenNSW/nsw/pkg/storage/drivers"
 )
 
 type Config struct {
-	Type           string // "local" or "s3"
+	Type           string // "local", "s3", "azure" or "gcs"
 	LocalBaseDir   string
 	LocalPublicURL string
 	S3Endpoint     string
@@ -20,6 +22,39 @@ type Config struct {
 	S3PublicURL    string
 	LocalPutSecret string
 	PresignTTL     time.Duration
+
+	AzureEndpoint    string // Defaults to the account's public endpoint; set for sovereign clouds and Azurite
+	AzureAccountName string
+	AzureAccountKey  string // Base64-encoded storage account key
+	AzureContainer   string
+
+	GCSEndpoint        string // Defaults to drivers.DefaultGCSEndpoint
+	GCSBucket          string
+	GCSCredentialsFile string // Path to the JSON key of a service account with access to GCSBucket
+
+	// ScanClamdAddress is the ClamAV daemon uploads are scanned with, as tcp://host:port or
+	// unix:///path. When empty, uploads are only checked against their declared type and size.
+	ScanClamdAddress string
+	ScanTimeout      time.Duration
+
+	// Retention periods of stored files, see RetentionPolicy. Zero keeps files indefinitely.
+	RetentionDraft     time.Duration
+	RetentionUpload    time.Duration
+	RetentionIssued    time.Duration
+	RetentionAbandoned time.Duration
+	OrphanGrace        time.Duration
+	SweepInterval      time.Duration
+}
+
+// RetentionPolicy returns the retention periods configured for stored files.
+func (c Config) RetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		Draft:       c.RetentionDraft,
+		Upload:      c.RetentionUpload,
+		Issued:      c.RetentionIssued,
+		Abandoned:   c.RetentionAbandoned,
+		OrphanGrace: c.OrphanGrace,
+	}
 }
 
 func (c Config) Validate() error {
@@ -58,6 +93,39 @@ func (c Config) Validate() error {
 				return err
 			}
 		}
+	case "azure":
+		if c.AzureAccountName == "" {
+			return fmt.Errorf("STORAGE_AZURE_ACCOUNT_NAME is required when STORAGE_TYPE=azure")
+		}
+		if c.AzureAccountKey == "" {
+			return fmt.Errorf("STORAGE_AZURE_ACCOUNT_KEY is required when STORAGE_TYPE=azure")
+		}
+		if _, err := base64.StdEncoding.DecodeString(c.AzureAccountKey); err != nil {
+			return fmt.Errorf("STORAGE_AZURE_ACCOUNT_KEY must be base64-encoded: %w", err)
+		}
+		if c.AzureContainer == "" {
+			return fmt.Errorf("STORAGE_AZURE_CONTAINER is required when STORAGE_TYPE=azure")
+		}
+		if c.AzureEndpoint != "" {
+			if err := validation.HTTPURL("STORAGE_AZURE_ENDPOINT", c.AzureEndpoint); err != nil {
+				return err
+			}
+		}
+	case "gcs":
+		if c.GCSBucket == "" {
+			return fmt.Errorf("STORAGE_GCS_BUCKET is required when STORAGE_TYPE=gcs")
+		}
+		if c.GCSCredentialsFile == "" {
+			return fmt.Errorf("STORAGE_GCS_CREDENTIALS_FILE is required when STORAGE_TYPE=gcs")
+		}
+		if c.GCSEndpoint != "" {
+			if err := validation.HTTPURL("STORAGE_GCS_ENDPOINT", c.GCSEndpoint); err != nil {
+				return err
+			}
+		}
+		if c.PresignTTL > drivers.MaxGCSPresignTTL {
+			return fmt.Errorf("STORAGE_PRESIGN_TTL must not exceed %s when STORAGE_TYPE=gcs", drivers.MaxGCSPresignTTL)
+		}
 	default:
 		return fmt.Errorf("unsupported STORAGE_TYPE: %s", c.Type)
 	}
@@ -66,5 +134,29 @@ func (c Config) Validate() error {
 		return fmt.Errorf("STORAGE_PRESIGN_TTL must be greater than zero")
 	}
 
+	if c.ScanClamdAddress != "" {
+		if _, err := NewClamdScanner(c.ScanClamdAddress, c.ScanTimeout); err != nil {
+			return fmt.Errorf("STORAGE_SCAN_CLAMD_ADDRESS: %w", err)
+		}
+		if c.ScanTimeout <= 0 {
+			return fmt.Errorf("STORAGE_SCAN_TIMEOUT must be greater than zero")
+		}
+	}
+
+	for name, d := range map[string]time.Duration{
+		"STORAGE_RETENTION_DRAFT":     c.RetentionDraft,
+		"STORAGE_RETENTION_UPLOAD":    c.RetentionUpload,
+		"STORAGE_RETENTION_ISSUED":    c.RetentionIssued,
+		"STORAGE_RETENTION_ABANDONED": c.RetentionAbandoned,
+		"STORAGE_ORPHAN_GRACE":        c.OrphanGrace,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if c.SweepInterval <= 0 {
+		return fmt.Errorf("STORAGE_SWEEP_INTERVAL must be greater than zero")
+	}
+
 	return nil
 }
@@ -0,0 +1,254 @@
+package drivers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureSASVersion is the storage service version service SAS tokens are signed for.
+const azureSASVersion = "2020-12-06"
+
+// AzureBlobDriver implements StorageDriver for Azure Blob Storage, storing objects as block
+// blobs in a single container. Every request, the driver's own included, is authorized with a
+// service SAS signed with the account key, so no Azure SDK or token exchange is needed.
+//
+// Clients uploading to a URL from GetUploadURL must send the x-ms-blob-type: BlockBlob header.
+// A SAS cannot limit the size of an upload; quarantined uploads are size-checked by Finalize.
+type AzureBlobDriver struct {
+	Endpoint    string // Blob service endpoint, e.g. https://<account>.blob.core.windows.net
+	AccountName string
+	Container   string
+	accountKey  []byte
+	presignTTL  time.Duration
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewAzureBlobDriver creates an AzureBlobDriver. accountKey is the base64-encoded storage
+// account key. endpoint defaults to the public Azure endpoint of the account; set it for
+// sovereign clouds and Azurite.
+func NewAzureBlobDriver(endpoint, accountName, accountKey, container string, presignTTL time.Duration) (*AzureBlobDriver, error) {
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Azure account key: %w", err)
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", accountName)
+	}
+	if presignTTL == 0 {
+		presignTTL = DefaultPresignTTL
+	}
+	return &AzureBlobDriver{
+		Endpoint:    strings.TrimSuffix(endpoint, "/"),
+		AccountName: accountName,
+		Container:   container,
+		accountKey:  key,
+		presignTTL:  presignTTL,
+		client:      &http.Client{Timeout: 60 * time.Second},
+		now:         time.Now,
+	}, nil
+}
+
+// signedURL returns a SAS URL granting permissions on the blob stored under key for ttl. An
+// empty key signs the container itself, with extra added to the query.
+func (d *AzureBlobDriver) signedURL(key, permissions string, ttl time.Duration, extra url.Values) string {
+	resource, signedResource := "/blob/"+d.AccountName+"/"+d.Container, "c"
+	target := d.Endpoint + "/" + url.PathEscape(d.Container)
+	if key != "" {
+		resource += "/" + key
+		signedResource = "b"
+		target += "/" + url.PathEscape(key)
+	}
+	expiry := d.now().UTC().Add(ttl).Format(time.RFC3339)
+
+	query := url.Values{}
+	for name, values := range extra {
+		query[name] = values
+	}
+	query.Set("sv", azureSASVersion)
+	query.Set("sr", signedResource)
+	query.Set("sp", permissions)
+	query.Set("se", expiry)
+	query.Set("sig", d.sasSignature(permissions, expiry, resource, signedResource))
+	return target + "?" + query.Encode()
+}
+
+// sasSignature signs the fields of a service SAS that the driver sets; the others are empty.
+func (d *AzureBlobDriver) sasSignature(permissions, expiry, resource, signedResource string) string {
+	stringToSign := strings.Join([]string{
+		permissions,
+		"", // signed start
+		expiry,
+		resource,
+		"", // signed identifier
+		"", // signed IP
+		"", // signed protocol
+		azureSASVersion,
+		signedResource,
+		"",                 // signed snapshot time
+		"",                 // signed encryption scope
+		"", "", "", "", "", // response header overrides
+	}, "\n")
+	mac := hmac.New(sha256.New, d.accountKey)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// do sends a request to a SAS URL and returns the response if it has status want.
+func (d *AzureBlobDriver) do(req *http.Request, want int) (*http.Response, error) {
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != want {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusNotFound {
+			err = errors.Join(ErrNotFound, err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (d *AzureBlobDriver) Save(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if err := validateObjectKey(key); err != nil {
+		return err
+	}
+	// Put Blob needs the length up front; uploads are capped at 32MB by the HTTP handler.
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.signedURL(key, "cw", time.Minute, nil), bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create Azure request: %w", err)
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", azureSASVersion)
+	req.Header.Set("Content-Type", contentType)
+	resp, err := d.do(req, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("failed to upload to Azure: %w", err)
+	}
+	return resp.Body.Close()
+}
+
+func (d *AzureBlobDriver) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.signedURL(key, "r", time.Minute, nil), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create Azure request: %w", err)
+	}
+	req.Header.Set("x-ms-version", azureSASVersion)
+	resp, err := d.do(req, http.StatusOK)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get from Azure: %w", err)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = DefaultMime
+	}
+	return resp.Body, contentType, nil
+}
+
+func (d *AzureBlobDriver) Delete(ctx context.Context, key string) error {
+	if err := validateObjectKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, d.signedURL(key, "d", time.Minute, nil), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Azure request: %w", err)
+	}
+	req.Header.Set("x-ms-version", azureSASVersion)
+	resp, err := d.do(req, http.StatusAccepted)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete from Azure: %w", err)
+	}
+	return resp.Body.Close()
+}
+
+// azureBlobList is the response of the List Blobs operation.
+type azureBlobList struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			LastModified  string `xml:"Last-Modified"`
+			ContentLength int64  `xml:"Content-Length"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List calls fn for every blob in the container.
+func (d *AzureBlobDriver) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	marker := ""
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.signedURL("", "l", time.Minute, query), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create Azure request: %w", err)
+		}
+		req.Header.Set("x-ms-version", azureSASVersion)
+		resp, err := d.do(req, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("failed to list Azure blobs: %w", err)
+		}
+		var page azureBlobList
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode Azure blob list: %w", err)
+		}
+
+		for _, blob := range page.Blobs {
+			info := ObjectInfo{Key: blob.Name, Size: blob.Properties.ContentLength}
+			if t, err := time.Parse(time.RFC1123, blob.Properties.LastModified); err == nil {
+				info.ModTime = t
+			}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+		if page.NextMarker == "" {
+			return nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+func (d *AzureBlobDriver) GetDownloadURL(_ context.Context, key string) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
+	return d.signedURL(key, "r", d.presignTTL, nil), nil
+}
+
+// GetUploadURL returns a SAS URL that creates or overwrites the blob stored under key. The
+// client must send the x-ms-blob-type: BlockBlob header with its PUT.
+func (d *AzureBlobDriver) GetUploadURL(_ context.Context, key string, contentType string, maxSizeBytes int64) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
+	return d.signedURL(key, "cw", d.presignTTL, nil), nil
+}
@@ -0,0 +1,189 @@
+package drivers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	fakeAzureAccount   = "devstoreaccount1"
+	fakeAzureContainer = "nsw-uploads"
+)
+
+// newFakeAzure starts a fake Blob service that checks the SAS of every request and returns a
+// driver for it.
+func newFakeAzure(t *testing.T) (*AzureBlobDriver, *httptest.Server) {
+	t.Helper()
+	bucket := newFakeBucket()
+	var driver *AzureBlobDriver
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		container, blob, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		query := r.URL.Query()
+		if container != fakeAzureContainer {
+			http.Error(w, "ContainerNotFound", http.StatusNotFound)
+			return
+		}
+
+		resource := "/blob/" + fakeAzureAccount + "/" + container
+		if blob != "" {
+			resource += "/" + blob
+		}
+		expiry, err := time.Parse(time.RFC3339, query.Get("se"))
+		sig := driver.sasSignature(query.Get("sp"), query.Get("se"), resource, query.Get("sr"))
+		if err != nil || time.Now().After(expiry) || query.Get("sig") != sig {
+			http.Error(w, "AuthenticationFailed", http.StatusForbidden)
+			return
+		}
+		permitted := func(permission string) bool {
+			if !strings.ContainsAny(query.Get("sp"), permission) {
+				http.Error(w, "AuthorizationPermissionMismatch", http.StatusForbidden)
+				return false
+			}
+			return true
+		}
+
+		switch {
+		case blob == "" && r.Method == http.MethodGet && query.Get("comp") == "list":
+			if !permitted("l") {
+				return
+			}
+			keys, next := bucket.page(query.Get("marker"))
+			var b strings.Builder
+			b.WriteString("<EnumerationResults><Blobs>")
+			for _, key := range keys {
+				object, _ := bucket.get(key)
+				fmt.Fprintf(&b, "<Blob><Name>%s</Name><Properties><Last-Modified>%s</Last-Modified><Content-Length>%d</Content-Length></Properties></Blob>",
+					key, object.modTime.Format(time.RFC1123), len(object.content))
+			}
+			fmt.Fprintf(&b, "</Blobs><NextMarker>%s</NextMarker></EnumerationResults>", next)
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = io.WriteString(w, b.String())
+		case r.Method == http.MethodPut:
+			if !permitted("cw") {
+				return
+			}
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				http.Error(w, "MissingRequiredHeader", http.StatusBadRequest)
+				return
+			}
+			if err := bucket.put(blob, r); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet:
+			if !permitted("r") {
+				return
+			}
+			object, ok := bucket.get(blob)
+			if !ok {
+				http.Error(w, "BlobNotFound", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", object.contentType)
+			_, _ = w.Write(object.content)
+		case r.Method == http.MethodDelete:
+			if !permitted("d") {
+				return
+			}
+			if !bucket.delete(blob) {
+				http.Error(w, "BlobNotFound", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "UnsupportedHttpVerb", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	accountKey := base64.StdEncoding.EncodeToString([]byte("fake-azure-account-key"))
+	driver, err := NewAzureBlobDriver(server.URL, fakeAzureAccount, accountKey, fakeAzureContainer, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver, server
+}
+
+func TestAzureBlobDriver_PresignedURLs(t *testing.T) {
+	driver, _ := newFakeAzure(t)
+	ctx := context.Background()
+	const key = "550e8400-e29b-41d4-a716-446655440000.pdf"
+
+	uploadURL, err := driver.GetUploadURL(ctx, key, "application/pdf", 1024)
+	if err != nil {
+		t.Fatalf("GetUploadURL failed: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, uploadURL, bytes.NewReader(pdfContent))
+	req.Header.Set("Content-Type", "application/pdf")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 for the upload, got %d", resp.StatusCode)
+	}
+
+	downloadURL, err := driver.GetDownloadURL(ctx, key)
+	if err != nil {
+		t.Fatalf("GetDownloadURL failed: %v", err)
+	}
+	resp, err = http.Get(downloadURL)
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	content, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(content, pdfContent) {
+		t.Fatalf("unexpected download: %d %q", resp.StatusCode, content)
+	}
+
+	// The download URL grants read access only.
+	req, _ = http.NewRequest(http.MethodDelete, downloadURL, nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status 403 deleting with a download URL, got %d", resp.StatusCode)
+	}
+
+	// A tampered URL is rejected.
+	resp, err = http.Get(strings.Replace(downloadURL, "sp=r", "sp=rd", 1))
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status 403 for a tampered URL, got %d", resp.StatusCode)
+	}
+}
+
+func TestAzureBlobList_Decode(t *testing.T) {
+	const body = `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults ServiceEndpoint="https://acct.blob.core.windows.net/" ContainerName="nsw-uploads">
+  <Blobs>
+    <Blob><Name>a.pdf</Name><Properties><Last-Modified>Thu, 01 Oct 2026 12:00:00 GMT</Last-Modified><Content-Length>42</Content-Length></Properties></Blob>
+  </Blobs>
+  <NextMarker>2!76!MDAwMDA</NextMarker>
+</EnumerationResults>`
+	var page azureBlobList
+	if err := xml.Unmarshal([]byte(body), &page); err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if len(page.Blobs) != 1 || page.Blobs[0].Name != "a.pdf" || page.Blobs[0].Properties.ContentLength != 42 || page.NextMarker != "2!76!MDAwMDA" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+}
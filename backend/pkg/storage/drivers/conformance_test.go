@@ -0,0 +1,275 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var pdfContent = []byte("%PDF-1.4\n%%EOF\n")
+
+// conformanceDriver is storage.StorageDriver together with storage.Lister, which every driver
+// implements.
+type conformanceDriver interface {
+	Save(ctx context.Context, key string, body io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, string, error)
+	Delete(ctx context.Context, key string) error
+	GetDownloadURL(ctx context.Context, key string) (string, error)
+	GetUploadURL(ctx context.Context, key string, contentType string, maxSizeBytes int64) (string, error)
+	List(ctx context.Context, fn func(ObjectInfo) error) error
+}
+
+// runConformance checks the behaviour the storage service relies on from every driver.
+// newDriver must return a driver with an empty bucket.
+func runConformance(t *testing.T, newDriver func(t *testing.T) conformanceDriver) {
+	ctx := context.Background()
+	const key = "550e8400-e29b-41d4-a716-446655440000.pdf"
+
+	read := func(t *testing.T, d conformanceDriver, key string) (string, string) {
+		t.Helper()
+		body, contentType, err := d.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", key, err)
+		}
+		defer body.Close()
+		content, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", key, err)
+		}
+		return string(content), contentType
+	}
+	list := func(t *testing.T, d conformanceDriver) map[string]ObjectInfo {
+		t.Helper()
+		listed := map[string]ObjectInfo{}
+		if err := d.List(ctx, func(info ObjectInfo) error {
+			listed[info.Key] = info
+			return nil
+		}); err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		return listed
+	}
+
+	t.Run("saves and gets content with its type", func(t *testing.T) {
+		d := newDriver(t)
+		if err := d.Save(ctx, key, strings.NewReader("%PDF-1.4"), "application/pdf"); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		content, contentType := read(t, d, key)
+		if content != "%PDF-1.4" || contentType != "application/pdf" {
+			t.Errorf("got %q (%s)", content, contentType)
+		}
+	})
+
+	t.Run("overwrites on save", func(t *testing.T) {
+		d := newDriver(t)
+		for _, content := range []string{"first", "second"} {
+			if err := d.Save(ctx, key, strings.NewReader(content), "text/plain"); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+		}
+		if content, _ := read(t, d, key); content != "second" {
+			t.Errorf("expected the second save to win, got %q", content)
+		}
+	})
+
+	t.Run("reports missing objects as ErrNotFound", func(t *testing.T) {
+		d := newDriver(t)
+		if _, _, err := d.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("deletes idempotently", func(t *testing.T) {
+		d := newDriver(t)
+		if err := d.Save(ctx, key, strings.NewReader("content"), "text/plain"); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := d.Delete(ctx, key); err != nil {
+				t.Fatalf("Delete %d failed: %v", i+1, err)
+			}
+		}
+		if _, _, err := d.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("lists every object", func(t *testing.T) {
+		d := newDriver(t)
+		keys := []string{"a1b2c3d4.pdf", "b1b2c3d4.png", "c1b2c3d4.pdf.pending", "d1b2c3d4.gif", "e1b2c3d4.webp"}
+		before := time.Now().Add(-time.Minute)
+		for _, k := range keys {
+			if err := d.Save(ctx, k, strings.NewReader(k), "application/octet-stream"); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+		}
+		if err := d.Delete(ctx, keys[0]); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+
+		listed := list(t, d)
+		var got []string
+		for k, info := range listed {
+			got = append(got, k)
+			if info.Size != int64(len(k)) || info.ModTime.Before(before) {
+				t.Errorf("unexpected listing for %s: %+v", k, info)
+			}
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(keys[1:], ",") {
+			t.Errorf("expected %v, got %v", keys[1:], got)
+		}
+	})
+
+	t.Run("stops listing at the first error", func(t *testing.T) {
+		d := newDriver(t)
+		for _, k := range []string{"a1b2c3d4.pdf", "b1b2c3d4.pdf"} {
+			if err := d.Save(ctx, k, strings.NewReader(k), "application/pdf"); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+		}
+		stop, calls := errors.New("stop"), 0
+		err := d.List(ctx, func(ObjectInfo) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("expected List to stop after one call with the error, got %v after %d calls", err, calls)
+		}
+	})
+
+	t.Run("presigns upload and download URLs", func(t *testing.T) {
+		d := newDriver(t)
+		uploadURL, err := d.GetUploadURL(ctx, key, "application/pdf", 1024)
+		if err != nil {
+			t.Fatalf("GetUploadURL failed: %v", err)
+		}
+		downloadURL, err := d.GetDownloadURL(ctx, key)
+		if err != nil {
+			t.Fatalf("GetDownloadURL failed: %v", err)
+		}
+		if !strings.Contains(uploadURL, key) || !strings.Contains(downloadURL, key) || uploadURL == downloadURL {
+			t.Errorf("unexpected URLs: upload %q, download %q", uploadURL, downloadURL)
+		}
+	})
+
+	t.Run("rejects keys outside the bucket", func(t *testing.T) {
+		d := newDriver(t)
+		const bad = "../escape.pdf"
+		if err := d.Save(ctx, bad, strings.NewReader("x"), "application/pdf"); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Save: expected ErrInvalidPath, got %v", err)
+		}
+		if _, _, err := d.Get(ctx, bad); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Get: expected ErrInvalidPath, got %v", err)
+		}
+		if err := d.Delete(ctx, bad); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Delete: expected ErrInvalidPath, got %v", err)
+		}
+	})
+}
+
+func TestConformance_Memory(t *testing.T) {
+	runConformance(t, func(t *testing.T) conformanceDriver {
+		return NewMemoryDriver()
+	})
+}
+
+func TestConformance_LocalFS(t *testing.T) {
+	runConformance(t, func(t *testing.T) conformanceDriver {
+		d, err := NewLocalFSDriver(t.TempDir(), "http://localhost:8080", "local-dev-secret", 15*time.Minute)
+		if err != nil {
+			t.Fatalf("failed to create driver: %v", err)
+		}
+		return d
+	})
+}
+
+func TestConformance_S3(t *testing.T) {
+	runConformance(t, func(t *testing.T) conformanceDriver {
+		return newFakeS3(t)
+	})
+}
+
+func TestConformance_AzureBlob(t *testing.T) {
+	runConformance(t, func(t *testing.T) conformanceDriver {
+		d, _ := newFakeAzure(t)
+		return d
+	})
+}
+
+func TestConformance_GCS(t *testing.T) {
+	runConformance(t, func(t *testing.T) conformanceDriver {
+		d, _ := newFakeGCS(t)
+		return d
+	})
+}
+
+// fakeBucket holds the objects of the fake object store servers the cloud drivers are tested
+// against. Listings are paged two objects at a time to exercise pagination.
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	content     []byte
+	contentType string
+	modTime     time.Time
+}
+
+const fakePageSize = 2
+
+func newFakeBucket() *fakeBucket {
+	return &fakeBucket{objects: map[string]fakeObject{}}
+}
+
+func (b *fakeBucket) put(key string, r *http.Request) error {
+	content, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key] = fakeObject{content: content, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC()}
+	return nil
+}
+
+func (b *fakeBucket) get(key string) (fakeObject, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	object, ok := b.objects[key]
+	return object, ok
+}
+
+func (b *fakeBucket) delete(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.objects[key]
+	delete(b.objects, key)
+	return ok
+}
+
+// page returns the keys from the one after marker, at most fakePageSize of them, and the
+// marker of the next page, if there is one.
+func (b *fakeBucket) page(marker string) ([]string, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var keys []string
+	for key := range b.objects {
+		if key > marker {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > fakePageSize {
+		return keys[:fakePageSize], keys[fakePageSize-1]
+	}
+	return keys, ""
+}
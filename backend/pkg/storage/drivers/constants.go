@@ -1,9 +1,34 @@
 package drivers
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // DefaultPresignTTL is the default time-to-live for presigned upload and download URLs.
 const DefaultPresignTTL = 15 * time.Minute
 
 // DefaultMime is the fallback MIME type when none is provided.
 const DefaultMime = "application/octet-stream"
+
+// ErrNotFound is returned by Get when no object is stored under the key.
+// Callers can use errors.Is(err, drivers.ErrNotFound) to tell a missing object from a failed read.
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes an object found by listing a bucket or directory.
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// validateObjectKey rejects keys that are empty or could address anything but a single object
+// at the root of the bucket or container, as LocalFSDriver does.
+func validateObjectKey(key string) error {
+	if key == "" || strings.Contains(key, "..") || strings.ContainsAny(key, "/\\") {
+		return fmt.Errorf("invalid key %q: %w", key, ErrInvalidPath)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package drivers
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeGCSBucket = "nsw-uploads"
+
+// newFakeGCS starts a fake Cloud Storage XML API that verifies the V4 signature of every
+// request and returns a driver for it.
+func newFakeGCS(t *testing.T) (*GCSDriver, *httptest.Server) {
+	t.Helper()
+	credentials, privateKey := newGCSCredentials(t)
+	bucket := newFakeBucket()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !verifyGCSSignature(r, &privateKey.PublicKey) {
+			http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+			return
+		}
+		name, object, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if name != fakeGCSBucket {
+			http.Error(w, "NoSuchBucket", http.StatusNotFound)
+			return
+		}
+
+		switch {
+		case object == "" && r.Method == http.MethodGet:
+			keys, next := bucket.page(r.URL.Query().Get("continuation-token"))
+			var b strings.Builder
+			b.WriteString("<ListBucketResult>")
+			for _, key := range keys {
+				o, _ := bucket.get(key)
+				fmt.Fprintf(&b, "<Contents><Key>%s</Key><LastModified>%s</LastModified><Size>%d</Size></Contents>",
+					key, o.modTime.Format(time.RFC3339), len(o.content))
+			}
+			if next != "" {
+				fmt.Fprintf(&b, "<NextContinuationToken>%s</NextContinuationToken>", next)
+			}
+			b.WriteString("</ListBucketResult>")
+			_, _ = io.WriteString(w, b.String())
+		case r.Method == http.MethodPut:
+			if err := bucket.put(object, r); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		case r.Method == http.MethodGet:
+			o, ok := bucket.get(object)
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", o.contentType)
+			_, _ = w.Write(o.content)
+		case r.Method == http.MethodDelete:
+			if !bucket.delete(object) {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	driver, err := NewGCSDriver(server.URL, fakeGCSBucket, credentials, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return driver, server
+}
+
+// newGCSCredentials returns the JSON key file of a service account with a new private key.
+func newGCSCredentials(t *testing.T) ([]byte, *rsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	credentials, err := json.Marshal(gcsServiceAccount{
+		ClientEmail: "nsw-storage@nsw-test.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal credentials: %v", err)
+	}
+	return credentials, privateKey
+}
+
+// verifyGCSSignature checks the V4 signature and expiry of a signed URL request.
+func verifyGCSSignature(r *http.Request, publicKey *rsa.PublicKey) bool {
+	query := r.URL.Query()
+	signature, err := hex.DecodeString(query.Get("X-Goog-Signature"))
+	if err != nil {
+		return false
+	}
+	query.Del("X-Goog-Signature")
+
+	signedAt, err := time.Parse("20060102T150405Z", query.Get("X-Goog-Date"))
+	if err != nil {
+		return false
+	}
+	expires, err := strconv.Atoi(query.Get("X-Goog-Expires"))
+	if err != nil || time.Now().After(signedAt.Add(time.Duration(expires)*time.Second)) {
+		return false
+	}
+
+	headers := map[string]string{}
+	for _, name := range strings.Split(query.Get("X-Goog-SignedHeaders"), ";") {
+		if name == "host" {
+			headers[name] = r.Host
+		} else {
+			headers[name] = r.Header.Get(name)
+		}
+	}
+	digest := sha256.Sum256([]byte(gcsStringToSign(r.Method, r.URL.EscapedPath(), query, headers)))
+	return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil
+}
+
+func TestGCSDriver_PresignedURLs(t *testing.T) {
+	driver, _ := newFakeGCS(t)
+	ctx := context.Background()
+	const key = "550e8400-e29b-41d4-a716-446655440000.pdf"
+
+	uploadURL, err := driver.GetUploadURL(ctx, key, "application/pdf", 1024)
+	if err != nil {
+		t.Fatalf("GetUploadURL failed: %v", err)
+	}
+	put := func(contentType string) int {
+		req, _ := http.NewRequest(http.MethodPut, uploadURL, bytes.NewReader(pdfContent))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("upload failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := put("image/png"); status != http.StatusForbidden {
+		t.Errorf("expected status 403 uploading another content type, got %d", status)
+	}
+	if status := put("application/pdf"); status != http.StatusOK {
+		t.Fatalf("expected status 200 for the upload, got %d", status)
+	}
+
+	downloadURL, err := driver.GetDownloadURL(ctx, key)
+	if err != nil {
+		t.Fatalf("GetDownloadURL failed: %v", err)
+	}
+	resp, err := http.Get(downloadURL)
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	content, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(content, pdfContent) {
+		t.Fatalf("unexpected download: %d %q", resp.StatusCode, content)
+	}
+
+	// The download URL is only valid for GET.
+	req, _ := http.NewRequest(http.MethodDelete, downloadURL, nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status 403 deleting with a download URL, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewGCSDriver_Validation(t *testing.T) {
+	credentials, _ := newGCSCredentials(t)
+	tests := []struct {
+		name        string
+		credentials []byte
+		ttl         time.Duration
+	}{
+		{"not JSON", []byte("not json"), time.Hour},
+		{"no client email", []byte(`{"private_key":""}`), time.Hour},
+		{"no private key", []byte(`{"client_email":"a@b.iam.gserviceaccount.com","private_key":""}`), time.Hour},
+		{"presign TTL longer than seven days", credentials, 8 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGCSDriver("", fakeGCSBucket, tt.credentials, tt.ttl); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if _, err := NewGCSDriver("", fakeGCSBucket, credentials, MaxGCSPresignTTL); err != nil {
+		t.Errorf("expected valid credentials to be accepted, got %v", err)
+	}
+}
+
+func TestGCSEscape(t *testing.T) {
+	tests := map[string]string{
+		"550e8400.pdf":    "550e8400.pdf",
+		"a b/c~d":         "a%20b%2Fc~d",
+		"user@x.iam/2026": "user%40x.iam%2F2026",
+	}
+	for in, want := range tests {
+		if got := gcsEscape(in); got != want {
+			t.Errorf("gcsEscape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -120,6 +121,9 @@ func (d *LocalFSDriver) Get(ctx context.Context, key string) (io.ReadCloser, str
 	}
 	f, err := os.Open(fullAbs)
 	if err != nil {
+		if os.IsNotExist(err) {
+			err = errors.Join(ErrNotFound, err)
+		}
 		return nil, "", fmt.Errorf("failed to get file: %w", err)
 	}
 
@@ -144,6 +148,30 @@ func (d *LocalFSDriver) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// List calls fn for every object stored under BaseDir, skipping the content type sidecar files.
+func (d *LocalFSDriver) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	err := filepath.WalkDir(d.BaseDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".meta") {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(ObjectInfo{Key: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	return nil
+}
+
 func (d *LocalFSDriver) GetDownloadURL(_ context.Context, key string) (string, error) {
 	if d.PublicURL == "" {
 		return key, nil
@@ -274,3 +274,35 @@ func TestLocalFSDriver_LinkVerification(t *testing.T) {
 		t.Error("invalid token signature was accepted")
 	}
 }
+
+func TestLocalFSDriver_List(t *testing.T) {
+	driver, err := NewLocalFSDriver(t.TempDir(), "/uploads", "local-dev-secret", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	ctx := context.Background()
+	keys := []string{"abcdef123456.pdf", "abcdef123456.pdf.pending", "ffff0000.png"}
+	for _, key := range keys {
+		if err := driver.Save(ctx, key, strings.NewReader(key), "application/pdf"); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	listed := map[string]ObjectInfo{}
+	err = driver.List(ctx, func(info ObjectInfo) error {
+		listed[info.Key] = info
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(listed) != len(keys) {
+		t.Fatalf("expected %d objects without the .meta files, got %v", len(keys), listed)
+	}
+	for _, key := range keys {
+		info, ok := listed[key]
+		if !ok || info.Size != int64(len(key)) || info.ModTime.IsZero() {
+			t.Errorf("unexpected listing for %s: %+v", key, info)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package drivers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryDriver implements StorageDriver in memory, for unit tests. Its upload and download
+// URLs name the object but cannot be fetched; tests write and read objects through the driver.
+type MemoryDriver struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	now     func() time.Time
+}
+
+type memoryObject struct {
+	content     []byte
+	contentType string
+	modTime     time.Time
+}
+
+// NewMemoryDriver creates an empty MemoryDriver.
+func NewMemoryDriver() *MemoryDriver {
+	return &MemoryDriver{objects: map[string]memoryObject{}, now: time.Now}
+}
+
+func (d *MemoryDriver) Save(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if err := validateObjectKey(key); err != nil {
+		return err
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.objects[key] = memoryObject{content: content, contentType: contentType, modTime: d.now()}
+	return nil
+}
+
+func (d *MemoryDriver) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return nil, "", err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	object, ok := d.objects[key]
+	if !ok {
+		return nil, "", fmt.Errorf("failed to get %s: %w", key, ErrNotFound)
+	}
+	contentType := object.contentType
+	if contentType == "" {
+		contentType = DefaultMime
+	}
+	return io.NopCloser(bytes.NewReader(object.content)), contentType, nil
+}
+
+func (d *MemoryDriver) Delete(ctx context.Context, key string) error {
+	if err := validateObjectKey(key); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.objects, key)
+	return nil
+}
+
+// List calls fn for every stored object, in key order.
+func (d *MemoryDriver) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	d.mu.RLock()
+	infos := make([]ObjectInfo, 0, len(d.objects))
+	for key, object := range d.objects {
+		infos = append(infos, ObjectInfo{Key: key, Size: int64(len(object.content)), ModTime: object.modTime})
+	}
+	d.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetModTime backdates the object stored under key, for testing age-based behaviour.
+func (d *MemoryDriver) SetModTime(key string, modTime time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if object, ok := d.objects[key]; ok {
+		object.modTime = modTime
+		d.objects[key] = object
+	}
+}
+
+func (d *MemoryDriver) GetDownloadURL(_ context.Context, key string) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
+	return "memory://download/" + key, nil
+}
+
+func (d *MemoryDriver) GetUploadURL(_ context.Context, key string, contentType string, maxSizeBytes int64) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
+	return "memory://upload/" + key, nil
+}
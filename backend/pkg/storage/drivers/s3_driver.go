@@ -2,12 +2,14 @@ package drivers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // S3Driver implements StorageDriver for S3-compatible storage.
@@ -34,6 +36,9 @@ func NewS3Driver(client *s3.Client, bucket string, publicURL string, presignTTL
 }
 
 func (d *S3Driver) Save(ctx context.Context, key string, content io.Reader, contentType string) error {
+	if err := validateObjectKey(key); err != nil {
+		return err
+	}
 	_, err := d.Client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(d.Bucket),
 		Key:         aws.String(key),
@@ -47,11 +52,18 @@ func (d *S3Driver) Save(ctx context.Context, key string, content io.Reader, cont
 }
 
 func (d *S3Driver) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return nil, "", err
+	}
 	resp, err := d.Client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(d.Bucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			err = errors.Join(ErrNotFound, err)
+		}
 		return nil, "", fmt.Errorf("failed to get from S3: %w", err)
 	}
 
@@ -64,6 +76,9 @@ func (d *S3Driver) Get(ctx context.Context, key string) (io.ReadCloser, string,
 }
 
 func (d *S3Driver) Delete(ctx context.Context, key string) error {
+	if err := validateObjectKey(key); err != nil {
+		return err
+	}
 	_, err := d.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(d.Bucket),
 		Key:    aws.String(key),
@@ -74,6 +89,29 @@ func (d *S3Driver) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// List calls fn for every object in the bucket.
+func (d *S3Driver) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(d.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(d.Bucket),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list S3 objects: %w", err)
+		}
+		for _, object := range page.Contents {
+			info := ObjectInfo{Key: aws.ToString(object.Key), Size: aws.ToInt64(object.Size)}
+			if object.LastModified != nil {
+				info.ModTime = *object.LastModified
+			}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // presignGet returns a presigned GET URL for the key; used by both GenerateURL and GetDownloadURL.
 func (d *S3Driver) presignGet(ctx context.Context, key string) (string, error) {
 	ttl := d.presignTTL
@@ -88,6 +126,9 @@ func (d *S3Driver) presignGet(ctx context.Context, key string) (string, error) {
 }
 
 func (d *S3Driver) GetDownloadURL(ctx context.Context, key string) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
 	return d.presignGet(ctx, key)
 }
 
@@ -108,5 +149,8 @@ func (d *S3Driver) presignPut(ctx context.Context, key, contentType string, maxS
 }
 
 func (d *S3Driver) GetUploadURL(ctx context.Context, key string, contentType string, maxSizeBytes int64) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
 	return d.presignPut(ctx, key, contentType, maxSizeBytes)
 }
@@ -0,0 +1,84 @@
+package drivers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const fakeS3Bucket = "nsw-uploads"
+
+// newFakeS3 starts a fake S3 API with path-style addressing and returns a driver for it. The
+// fake does not check request signatures.
+func newFakeS3(t *testing.T) *S3Driver {
+	t.Helper()
+	bucket := newFakeBucket()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if name != fakeS3Bucket {
+			writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+			return
+		}
+
+		switch {
+		case key == "" && r.Method == http.MethodGet:
+			keys, next := bucket.page(r.URL.Query().Get("continuation-token"))
+			var b strings.Builder
+			b.WriteString(`<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+			fmt.Fprintf(&b, "<Name>%s</Name><KeyCount>%d</KeyCount><IsTruncated>%t</IsTruncated>", fakeS3Bucket, len(keys), next != "")
+			for _, k := range keys {
+				o, _ := bucket.get(k)
+				fmt.Fprintf(&b, "<Contents><Key>%s</Key><LastModified>%s</LastModified><Size>%d</Size></Contents>",
+					k, o.modTime.Format("2006-01-02T15:04:05.000Z"), len(o.content))
+			}
+			if next != "" {
+				fmt.Fprintf(&b, "<NextContinuationToken>%s</NextContinuationToken>", next)
+			}
+			b.WriteString("</ListBucketResult>")
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = io.WriteString(w, b.String())
+		case r.Method == http.MethodPut:
+			if err := bucket.put(key, r); err != nil {
+				writeS3Error(w, http.StatusInternalServerError, "InternalError")
+			}
+		case r.Method == http.MethodGet:
+			o, ok := bucket.get(key)
+			if !ok {
+				writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+				return
+			}
+			w.Header().Set("Content-Type", o.contentType)
+			_, _ = w.Write(o.content)
+		case r.Method == http.MethodDelete:
+			bucket.delete(key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:                     "us-east-1",
+		BaseEndpoint:               aws.String(server.URL),
+		UsePathStyle:               true,
+		Credentials:                credentials.NewStaticCredentialsProvider("test", "test", ""),
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+	return NewS3Driver(client, fakeS3Bucket, "", 15*time.Minute)
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
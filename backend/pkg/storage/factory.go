@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -49,7 +50,27 @@ func NewStorageFromConfig(ctx context.Context, cfg Config) (StorageDriver, error
 		})
 
 		return drivers.NewS3Driver(client, cfg.S3Bucket, cfg.S3PublicURL, cfg.PresignTTL), nil
+	case "azure":
+		slog.Info("Initializing Azure Blob storage", "account", cfg.AzureAccountName, "container", cfg.AzureContainer)
+		return drivers.NewAzureBlobDriver(cfg.AzureEndpoint, cfg.AzureAccountName, cfg.AzureAccountKey, cfg.AzureContainer, cfg.PresignTTL)
+	case "gcs":
+		slog.Info("Initializing Google Cloud Storage", "bucket", cfg.GCSBucket)
+		credentials, err := os.ReadFile(cfg.GCSCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GCS credentials: %w", err)
+		}
+		return drivers.NewGCSDriver(cfg.GCSEndpoint, cfg.GCSBucket, credentials, cfg.PresignTTL)
 	default:
 		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
 	}
 }
+
+// NewScannerFromConfig creates the scanner uploads are checked with before they leave quarantine.
+func NewScannerFromConfig(cfg Config) (Scanner, error) {
+	if cfg.ScanClamdAddress == "" {
+		slog.Warn("No malware scanner configured, uploads are only checked against their declared type and size")
+		return NoopScanner{}, nil
+	}
+	slog.Info("Initializing clamd malware scanner", "address", cfg.ScanClamdAddress)
+	return NewClamdScanner(cfg.ScanClamdAddress, cfg.ScanTimeout)
+}
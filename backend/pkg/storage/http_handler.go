@@ -9,6 +9,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/OpenNSW/nsw/internal/auth"
@@ -22,6 +23,9 @@ func validStorageKey(key string) bool {
 	return len(key) >= 36 && storageKeyRx.MatchString(key)
 }
 
+// maxUploadSize is the largest file a client may upload.
+const maxUploadSize = 32 << 20
+
 var allowedContentTypes = map[string]struct{}{
 	"application/pdf": {},
 	"image/jpeg":      {},
@@ -50,6 +54,22 @@ func writeJSONError(w http.ResponseWriter, status int, message string) {
 	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
+// authorize checks that the caller may access the file stored under key, writing the error
+// response and returning false if not.
+func (h *HTTPHandler) authorize(w http.ResponseWriter, r *http.Request, key string, access Access) bool {
+	err := h.Service.AuthorizeAccess(r.Context(), key, access)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrAccessDenied):
+		writeJSONError(w, http.StatusForbidden, "Forbidden")
+	default:
+		slog.ErrorContext(r.Context(), "Failed to authorize file access", "key", key, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to authorize request")
+	}
+	return false
+}
+
 func (h *HTTPHandler) Upload(w http.ResponseWriter, r *http.Request) {
 	if auth.GetAuthContext(r.Context()) == nil {
 		slog.WarnContext(r.Context(), "authentication required but not provided for upload")
@@ -62,6 +82,11 @@ func (h *HTTPHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		Filename string `json:"filename"`
 		MimeType string `json:"mime_type"`
 		Size     int64  `json:"size"`
+		FormID   string `json:"form_id"`
+		Field    string `json:"field"`
+		// The task or consignment the file is for; its parties may then read the file
+		TaskID        string `json:"task_id"`
+		ConsignmentID string `json:"consignment_id"`
 	}
 
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
@@ -83,7 +108,7 @@ func (h *HTTPHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Size > 32<<20 {
+	if req.Size > maxUploadSize {
 		writeJSONError(w, http.StatusBadRequest, "file size exceeds 32MB limit")
 		return
 	}
@@ -92,11 +117,32 @@ func (h *HTTPHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusUnsupportedMediaType, "invalid or prohibited file type")
 		return
 	}
+	if req.FormID != "" && req.Field == "" {
+		writeJSONError(w, http.StatusBadRequest, "field is required with form_id")
+		return
+	}
 
-	metadata, err := h.Service.Upload(r.Context(), req.Filename, req.Size, req.MimeType)
+	metadata, err := h.Service.PrepareUpload(r.Context(), UploadRequest{
+		Filename:   req.Filename,
+		Size:       req.Size,
+		MimeType:   req.MimeType,
+		FormID:     req.FormID,
+		Field:      req.Field,
+		UploadedBy: PrincipalID(auth.GetAuthContext(r.Context())),
+		Link:       FileLink{TaskID: req.TaskID, ConsignmentID: req.ConsignmentID},
+	})
 	if err != nil {
-		slog.ErrorContext(r.Context(), "Upload preparation failed", "error", err)
-		writeJSONError(w, http.StatusInternalServerError, "failed to prepare upload")
+		switch {
+		case errors.Is(err, ErrAccessDenied):
+			writeJSONError(w, http.StatusForbidden, "Forbidden")
+		case errors.Is(err, ErrPolicyNotFound), errors.Is(err, ErrFileTooLarge):
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrContentTypeNotAllowed):
+			writeJSONError(w, http.StatusUnsupportedMediaType, err.Error())
+		default:
+			slog.ErrorContext(r.Context(), "Upload preparation failed", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "failed to prepare upload")
+		}
 		return
 	}
 
@@ -121,12 +167,13 @@ func (h *HTTPHandler) UploadContentLocal(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Quarantined uploads are written to the pending object of their key.
 	key := r.PathValue("key")
 	if key == "" {
 		writeJSONError(w, http.StatusBadRequest, "key is required")
 		return
 	}
-	if !validStorageKey(key) {
+	if !validStorageKey(strings.TrimSuffix(key, pendingSuffix)) {
 		writeJSONError(w, http.StatusBadRequest, "invalid key format")
 		return
 	}
@@ -198,12 +245,11 @@ func (h *HTTPHandler) UploadContentLocal(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *HTTPHandler) Download(w http.ResponseWriter, r *http.Request) {
-	// TODO: Uncomment when M2M AUTH Implemented.
-	//if auth.GetAuthContext(r.Context()) == nil {
-	//	slog.WarnContext(r.Context(), "authentication required but not provided for download")
-	//	writeJSONError(w, http.StatusUnauthorized, "Unauthorized")
-	//	return
-	//}
+	if auth.GetAuthContext(r.Context()) == nil {
+		slog.WarnContext(r.Context(), "authentication required but not provided for download")
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
 
 	key := r.PathValue("key")
 	if key == "" {
@@ -215,7 +261,15 @@ func (h *HTTPHandler) Download(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorize(w, r, key, AccessRead) {
+		return
+	}
+
 	url, err := h.Service.GetDownloadURL(r.Context(), key)
+	if errors.Is(err, ErrQuarantined) {
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		slog.ErrorContext(r.Context(), "Failed to generate download URL", "key", key, "error", err)
 		writeJSONError(w, http.StatusInternalServerError, "failed to generate access")
@@ -281,6 +335,10 @@ func (h *HTTPHandler) DownloadContent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	body, contentType, err := h.Service.Download(r.Context(), key)
+	if errors.Is(err, ErrQuarantined) {
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		slog.ErrorContext(r.Context(), "Download content failed", "key", key, "error", err)
 		writeJSONError(w, http.StatusInternalServerError, "failed to get file")
@@ -307,6 +365,52 @@ func (h *HTTPHandler) DownloadContent(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Finalize validates and scans an upload once its content has been written to the upload URL,
+// and releases it for download. Rejected content is deleted and reported with 422; the client
+// must upload the file again under a new key.
+func (h *HTTPHandler) Finalize(w http.ResponseWriter, r *http.Request) {
+	if auth.GetAuthContext(r.Context()) == nil {
+		slog.WarnContext(r.Context(), "authentication required but not provided for finalize")
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	key := r.PathValue("key")
+	if key == "" {
+		writeJSONError(w, http.StatusBadRequest, "key is required")
+		return
+	}
+	if !validStorageKey(key) {
+		writeJSONError(w, http.StatusBadRequest, "invalid key format")
+		return
+	}
+
+	if !h.authorize(w, r, key, AccessWrite) {
+		return
+	}
+
+	metadata, err := h.Service.Finalize(r.Context(), key)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotPending):
+			writeJSONError(w, http.StatusNotFound, "no uploaded content for this key")
+		case errors.Is(err, ErrUploadRejected):
+			writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, ErrScanFailed):
+			slog.ErrorContext(r.Context(), "Upload scan failed", "key", key, "error", err)
+			writeJSONError(w, http.StatusServiceUnavailable, "malware scan unavailable, try again later")
+		default:
+			slog.ErrorContext(r.Context(), "Finalize failed", "key", key, "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "failed to finalize upload")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(metadata); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+	}
+}
+
 func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	if auth.GetAuthContext(r.Context()) == nil {
 		slog.WarnContext(r.Context(), "authentication required but not provided for delete")
@@ -323,7 +427,15 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorize(w, r, key, AccessWrite) {
+		return
+	}
+
 	if err := h.Service.Delete(r.Context(), key); err != nil {
+		if errors.Is(err, ErrLegalHold) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
 		slog.ErrorContext(r.Context(), "Delete failed", "error", err, "key", key)
 		writeJSONError(w, http.StatusInternalServerError, "failed to delete file")
 		return
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+)
+
+var (
+	ErrFileNotFound = errors.New("stored file not found")
+	// ErrAccessDenied is returned when the caller may not access a file. Authorizer
+	// implementations wrap it in the errors they return for denied requests.
+	ErrAccessDenied = errors.New("access to file denied")
+)
+
+// MetadataStore persists the records of stored files.
+type MetadataStore interface {
+	// Create records a stored file.
+	Create(ctx context.Context, file *StoredFile) error
+	// Get returns the record of the file stored under key.
+	Get(ctx context.Context, key string) (*StoredFile, error)
+	// MarkClean records that the file's content passed validation, with its size and checksum.
+	MarkClean(ctx context.Context, key string, size int64, checksum string) error
+	// Delete removes the record of the file stored under key, if there is one.
+	Delete(ctx context.Context, key string) error
+}
+
+// Authorizer decides whether the caller may attach files to a task or consignment and
+// whether it may access a stored file.
+type Authorizer interface {
+	AuthorizeFileLink(ctx context.Context, link FileLink) error
+	AuthorizeFileAccess(ctx context.Context, file *StoredFile, access Access) error
+}
+
+// PrincipalID identifies the principal of an auth context in StoredFile.UploadedBy:
+// "user:<user ID>" for users and "client:<client ID>" for machine clients. It returns ""
+// when there is no principal.
+func PrincipalID(authCtx *auth.AuthContext) string {
+	switch {
+	case authCtx == nil:
+		return ""
+	case authCtx.User != nil:
+		return "user:" + authCtx.User.ID
+	case authCtx.Client != nil:
+		return "client:" + authCtx.Client.ClientID
+	default:
+		return ""
+	}
+}
+
+// AuthorizeAccess checks that the caller may access the file stored under key. Files stored
+// before their metadata was recorded have no record; they may be read, as before, but only
+// deleted by removing them from storage directly.
+func (s *Service) AuthorizeAccess(ctx context.Context, key string, access Access) error {
+	if s.files == nil || s.authorizer == nil {
+		return nil
+	}
+	file, err := s.files.Get(ctx, key)
+	if errors.Is(err, ErrFileNotFound) {
+		if access == AccessRead {
+			slog.WarnContext(ctx, "Reading file without a metadata record", "key", key)
+			return nil
+		}
+		return fmt.Errorf("%w: file %s has no owner on record", ErrAccessDenied, key)
+	}
+	if err != nil {
+		return err
+	}
+	return s.authorizer.AuthorizeFileAccess(ctx, file, access)
+}
+
+// GormMetadataStore implements MetadataStore with GORM.
+type GormMetadataStore struct {
+	db *gorm.DB
+}
+
+// NewGormMetadataStore creates a GormMetadataStore.
+func NewGormMetadataStore(db *gorm.DB) (*GormMetadataStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database connection cannot be nil")
+	}
+	return &GormMetadataStore{db: db}, nil
+}
+
+// Create inserts file.
+func (s *GormMetadataStore) Create(ctx context.Context, file *StoredFile) error {
+	if err := s.db.WithContext(ctx).Create(file).Error; err != nil {
+		return fmt.Errorf("failed to record stored file: %w", err)
+	}
+	return nil
+}
+
+// Get returns the record of the file stored under key.
+func (s *GormMetadataStore) Get(ctx context.Context, key string) (*StoredFile, error) {
+	var file StoredFile
+	err := s.db.WithContext(ctx).Where("key = ?", key).First(&file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored file: %w", err)
+	}
+	return &file, nil
+}
+
+// MarkClean records that the file's content passed validation.
+func (s *GormMetadataStore) MarkClean(ctx context.Context, key string, size int64, checksum string) error {
+	err := s.db.WithContext(ctx).Model(&StoredFile{}).Where("key = ?", key).Updates(map[string]any{
+		"status":   FileStatusClean,
+		"size":     size,
+		"checksum": checksum,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update stored file: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the record of the file stored under key.
+func (s *GormMetadataStore) Delete(ctx context.Context, key string) error {
+	if err := s.db.WithContext(ctx).Where("key = ?", key).Delete(&StoredFile{}).Error; err != nil {
+		return fmt.Errorf("failed to delete stored file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+)
+
+// memoryFiles is an in-memory MetadataStore.
+type memoryFiles map[string]StoredFile
+
+func (m memoryFiles) Create(_ context.Context, file *StoredFile) error {
+	m[file.Key] = *file
+	return nil
+}
+
+func (m memoryFiles) Get(_ context.Context, key string) (*StoredFile, error) {
+	file, ok := m[key]
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+	return &file, nil
+}
+
+func (m memoryFiles) MarkClean(_ context.Context, key string, size int64, checksum string) error {
+	file := m[key]
+	file.Status, file.Size, file.Checksum = FileStatusClean, size, checksum
+	m[key] = file
+	return nil
+}
+
+func (m memoryFiles) Delete(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+// uploaderOnly lets only a file's uploader access it and allows any link.
+type uploaderOnly struct{}
+
+func (uploaderOnly) AuthorizeFileLink(context.Context, FileLink) error {
+	return nil
+}
+
+func (uploaderOnly) AuthorizeFileAccess(ctx context.Context, file *StoredFile, _ Access) error {
+	if PrincipalID(auth.GetAuthContext(ctx)) != file.UploadedBy {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+func TestService_RecordsFiles(t *testing.T) {
+	files := memoryFiles{}
+	service := NewService(&MockDriver{}, WithMetadata(files))
+	ctx := context.Background()
+
+	upload, err := service.PrepareUpload(ctx, UploadRequest{
+		Filename: "invoice.pdf", Size: 1024, MimeType: "application/pdf", UploadedBy: "user:trader-1",
+		Link: FileLink{TaskID: "task-1", ConsignmentID: "c-1"},
+	})
+	if err != nil {
+		t.Fatalf("PrepareUpload failed: %v", err)
+	}
+	recorded := files[upload.Key]
+	if recorded.UploadedBy != "user:trader-1" || recorded.TaskID != "task-1" || recorded.ConsignmentID != "c-1" ||
+		recorded.Filename != "invoice.pdf" || recorded.RetentionClass != RetentionUpload {
+		t.Errorf("unexpected upload record: %+v", recorded)
+	}
+
+	saved, err := service.Save(ctx, "receipt.pdf", []byte("%PDF-1.4"), "application/pdf", FileLink{TaskID: "task-2"})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	recorded = files[saved.Key]
+	if recorded.UploadedBy != SystemPrincipal || recorded.RetentionClass != RetentionIssued ||
+		recorded.Checksum != saved.Checksum || len(recorded.Checksum) != 64 {
+		t.Errorf("unexpected saved record: %+v", recorded)
+	}
+
+	if err := service.Delete(ctx, saved.Key); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := files[saved.Key]; ok {
+		t.Error("expected the record to be deleted with the file")
+	}
+}
+
+func TestHTTPHandler_AuthorizesFileAccess(t *testing.T) {
+	files := memoryFiles{
+		"550e8400-e29b-41d4-a716-446655440000.pdf": {Key: "550e8400-e29b-41d4-a716-446655440000.pdf", UploadedBy: "user:trader-1"},
+	}
+	service := NewService(&MockDriver{}, WithMetadata(files))
+	service.RegisterAuthorizer(uploaderOnly{})
+	handler := NewHTTPHandler(service)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /files/{key}", handler.Download)
+	mux.HandleFunc("DELETE /files/{key}", handler.Delete)
+	send := func(method, key, userID string) int {
+		req := httptest.NewRequest(method, "/files/"+key, nil)
+		req = req.WithContext(withAuthContext(req.Context(), &auth.AuthContext{User: &auth.UserContext{ID: userID}}))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	const owned, legacy = "550e8400-e29b-41d4-a716-446655440000.pdf", "660e8400-e29b-41d4-a716-446655440000.pdf"
+	tests := []struct {
+		name   string
+		method string
+		key    string
+		userID string
+		want   int
+	}{
+		{"owner downloads", http.MethodGet, owned, "trader-1", http.StatusOK},
+		{"other user cannot download", http.MethodGet, owned, "trader-2", http.StatusForbidden},
+		{"other user cannot delete", http.MethodDelete, owned, "trader-2", http.StatusForbidden},
+		{"file without a record can be downloaded", http.MethodGet, legacy, "trader-2", http.StatusOK},
+		{"file without a record cannot be deleted", http.MethodDelete, legacy, "trader-2", http.StatusForbidden},
+		{"owner deletes", http.MethodDelete, owned, "trader-1", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := send(tt.method, tt.key, tt.userID); got != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func setupTestDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	return gormDB, mock
+}
+
+func TestGormMetadataStore_Get(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormMetadataStore(db)
+	if err != nil {
+		t.Fatalf("NewGormMetadataStore failed: %v", err)
+	}
+	query := regexp.QuoteMeta(`SELECT * FROM "stored_files" WHERE key = $1`)
+
+	mock.ExpectQuery(query).WithArgs("k1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "uploaded_by", "task_id"}).AddRow("k1", "user:trader-1", "task-1"))
+	file, err := store.Get(context.Background(), "k1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if file.UploadedBy != "user:trader-1" || file.TaskID != "task-1" {
+		t.Errorf("unexpected file: %+v", file)
+	}
+
+	mock.ExpectQuery(query).WithArgs("missing", 1).WillReturnRows(sqlmock.NewRows([]string{"key"}))
+	if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGormMetadataStore_MarkClean(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormMetadataStore(db)
+	if err != nil {
+		t.Fatalf("NewGormMetadataStore failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "stored_files" SET "checksum"=$1,"size"=$2,"status"=$3,"updated_at"=$4 WHERE key = $5`)).
+		WithArgs("abc", int64(42), FileStatusClean, sqlmock.AnyArg(), "k1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := store.MarkClean(context.Background(), "k1", 42, "abc"); err != nil {
+		t.Fatalf("MarkClean failed: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
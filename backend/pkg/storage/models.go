@@ -1,5 +1,7 @@
 package storage
 
+import "time"
+
 // FileMetadata represents the metadata of an uploaded file
 type FileMetadata struct {
 	ID        string `json:"id"`
@@ -9,4 +11,113 @@ type FileMetadata struct {
 	UploadURL string `json:"upload_url,omitempty"`
 	Size      int64  `json:"size"`
 	MimeType  string `json:"mime_type"`
+	Status    string `json:"status,omitempty"`   // FileStatusQuarantined or FileStatusClean when quarantine is enabled
+	Checksum  string `json:"checksum,omitempty"` // SHA-256 of the content, once it is known
+}
+
+// File statuses reported when uploads are quarantined.
+const (
+	FileStatusQuarantined = "QUARANTINED" // Uploaded content has not yet been validated and scanned
+	FileStatusClean       = "CLEAN"       // Content passed validation and scanning and can be downloaded
+)
+
+// Retention classes of stored files. How long each class is kept is configured in
+// RetentionPolicy.
+const (
+	RetentionDraft  = "DRAFT"  // Uploads not yet attached to a task or consignment
+	RetentionUpload = "UPLOAD" // Files uploaded by traders, CHAs and OGAs for a task or consignment
+	RetentionIssued = "ISSUED" // Documents NSW generated and issued itself
+)
+
+// Reasons a file was purged by the retention sweeper.
+const (
+	PurgeExpired   = "EXPIRED"   // The retention period of the file's class has passed
+	PurgeAbandoned = "ABANDONED" // The upload was never finalized and left quarantine
+	PurgeOrphaned  = "ORPHANED"  // The object had no metadata record
+)
+
+// SystemPrincipal is recorded as the uploader of files the server generates itself.
+const SystemPrincipal = "system"
+
+// FileLink names the task and consignment a file belongs to. Either may be empty.
+type FileLink struct {
+	TaskID        string
+	ConsignmentID string
+}
+
+// UploadRequest describes a file a client is about to upload. FormID and Field optionally name
+// the form field the file is for, whose upload policy then applies. UploadedBy is the uploading
+// principal, see PrincipalID.
+type UploadRequest struct {
+	Filename   string
+	Size       int64
+	MimeType   string
+	FormID     string
+	Field      string
+	UploadedBy string
+	Link       FileLink
+}
+
+// StoredFile records who stored a file, what it belongs to and how long it must be kept.
+type StoredFile struct {
+	Key            string `gorm:"type:varchar(255);primaryKey" json:"key"`
+	UploadedBy     string `gorm:"type:varchar(255);not null" json:"uploadedBy"` // See PrincipalID
+	TaskID         string `gorm:"type:varchar(255);not null" json:"taskId,omitempty"`
+	ConsignmentID  string `gorm:"type:varchar(255);not null" json:"consignmentId,omitempty"`
+	Filename       string `gorm:"type:text;not null" json:"filename"`
+	Size           int64  `gorm:"not null" json:"size"`
+	MimeType       string `gorm:"type:varchar(255);not null" json:"mimeType"`
+	Checksum       string `gorm:"type:varchar(64);not null" json:"checksum,omitempty"` // SHA-256 of the content, once it is known
+	RetentionClass string `gorm:"type:varchar(50);not null" json:"retentionClass"`
+	Status         string `gorm:"type:varchar(20);not null" json:"status,omitempty"`
+
+	// A file on legal hold is kept regardless of its retention class and cannot be deleted.
+	LegalHold       bool       `gorm:"not null;default:false" json:"legalHold"`
+	LegalHoldReason string     `gorm:"type:text;not null" json:"legalHoldReason,omitempty"`
+	LegalHoldBy     string     `gorm:"type:varchar(255);not null" json:"legalHoldBy,omitempty"`
+	LegalHoldAt     *time.Time `json:"legalHoldAt,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TableName returns the table name for StoredFile.
+func (StoredFile) TableName() string {
+	return "stored_files"
+}
+
+// Purge records a file deleted by the retention sweeper.
+type Purge struct {
+	ID             string    `gorm:"type:uuid;primaryKey" json:"id"`
+	Key            string    `gorm:"type:varchar(255);not null" json:"key"`
+	Reason         string    `gorm:"type:varchar(20);not null" json:"reason"`
+	RetentionClass string    `gorm:"type:varchar(50);not null" json:"retentionClass,omitempty"` // Empty for orphaned objects
+	UploadedBy     string    `gorm:"type:varchar(255);not null" json:"uploadedBy,omitempty"`
+	TaskID         string    `gorm:"type:varchar(255);not null" json:"taskId,omitempty"`
+	ConsignmentID  string    `gorm:"type:varchar(255);not null" json:"consignmentId,omitempty"`
+	Filename       string    `gorm:"type:text;not null" json:"filename,omitempty"`
+	Size           int64     `gorm:"not null" json:"size"`
+	Checksum       string    `gorm:"type:varchar(64);not null" json:"checksum,omitempty"`
+	StoredAt       time.Time `json:"storedAt"`
+	PurgedAt       time.Time `json:"purgedAt"`
+}
+
+// TableName returns the table name for Purge.
+func (Purge) TableName() string {
+	return "storage_purges"
+}
+
+// Access is the kind of access to a stored file being authorized.
+type Access string
+
+const (
+	AccessRead  Access = "READ"  // Download the file
+	AccessWrite Access = "WRITE" // Finalize or delete the file
+)
+
+// UploadPolicy narrows the size and content types accepted for a form field. Zero values
+// leave the service-wide limits in place.
+type UploadPolicy struct {
+	MaxSizeBytes int64    `json:"maxSizeBytes,omitempty"`
+	ContentTypes []string `json:"contentTypes,omitempty"`
 }
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/OpenNSW/nsw/pkg/storage/drivers"
+)
+
+var (
+	ErrFileTooLarge          = errors.New("file size exceeds the limit")
+	ErrContentTypeNotAllowed = errors.New("content type is not allowed")
+	ErrPolicyNotFound        = errors.New("no upload policy for form field")
+	ErrQuarantined           = errors.New("file is quarantined until it has been validated")
+	ErrNotPending            = errors.New("no uploaded content to finalize")
+	ErrUploadRejected        = errors.New("upload rejected")
+	ErrScanFailed            = errors.New("malware scan failed")
+)
+
+// PolicyResolver returns the upload policy of a form field. It returns ErrPolicyNotFound
+// when the form has no such file field, and a nil policy when the field sets no limits.
+type PolicyResolver interface {
+	UploadPolicy(ctx context.Context, formID, field string) (*UploadPolicy, error)
+}
+
+// pendingSuffix marks the object a quarantined upload is written to. The suffix gives the
+// object a key validStorageKey rejects, so it can never be downloaded directly.
+const pendingSuffix = ".pending"
+
+func pendingKey(key string) string {
+	return key + pendingSuffix
+}
+
+// checkPolicy checks the declared size and type of an upload against its form field's policy.
+func (s *Service) checkPolicy(ctx context.Context, formID, field string, size int64, contentType string) error {
+	if s.policies == nil {
+		return fmt.Errorf("%w: form policies are not configured", ErrPolicyNotFound)
+	}
+	policy, err := s.policies.UploadPolicy(ctx, formID, field)
+	if err != nil {
+		return fmt.Errorf("failed to resolve upload policy: %w", err)
+	}
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxSizeBytes > 0 && size > policy.MaxSizeBytes {
+		return fmt.Errorf("%w: %s accepts files up to %d bytes", ErrFileTooLarge, field, policy.MaxSizeBytes)
+	}
+	if len(policy.ContentTypes) > 0 && !slices.Contains(policy.ContentTypes, contentType) {
+		return fmt.Errorf("%w: %s accepts %s", ErrContentTypeNotAllowed, field, strings.Join(policy.ContentTypes, ", "))
+	}
+	return nil
+}
+
+// checkReleased returns ErrQuarantined when key has an upload that has not been finalized.
+func (s *Service) checkReleased(ctx context.Context, key string) error {
+	if s.scanner == nil {
+		return nil
+	}
+	body, _, err := s.Driver.Get(ctx, pendingKey(key))
+	if errors.Is(err, drivers.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check quarantine: %w", err)
+	}
+	_ = body.Close()
+	return ErrQuarantined
+}
+
+// Finalize validates a quarantined upload and releases it under its key. The content must be
+// non-empty, within the size limit, and sniff as the type it was uploaded with; the scanner
+// must then find it clean. Content that fails is deleted and ErrUploadRejected is returned.
+// A scanner failure leaves the upload in quarantine so that Finalize can be retried.
+// Finalizing a key that has already been released returns its metadata again.
+func (s *Service) Finalize(ctx context.Context, key string) (*FileMetadata, error) {
+	if s.scanner == nil {
+		return nil, fmt.Errorf("%w: uploads are not quarantined", ErrNotPending)
+	}
+
+	body, contentType, err := s.Driver.Get(ctx, pendingKey(key))
+	if errors.Is(err, drivers.ErrNotFound) {
+		return s.released(ctx, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending upload: %w", err)
+	}
+	content, err := io.ReadAll(io.LimitReader(body, maxUploadSize+1))
+	_ = body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending upload: %w", err)
+	}
+
+	if reason := validateContent(content, contentType); reason != "" {
+		return nil, s.reject(ctx, key, reason)
+	}
+	result, err := s.scanner.Scan(ctx, bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScanFailed, err)
+	}
+	if !result.Clean {
+		slog.WarnContext(ctx, "Malware detected in upload", "key", key, "signature", result.Signature)
+		return nil, s.reject(ctx, key, "file failed the malware scan")
+	}
+
+	checksum := sha256Hex(content)
+	if err := s.Driver.Save(ctx, key, bytes.NewReader(content), contentType); err != nil {
+		return nil, fmt.Errorf("failed to release upload: %w", err)
+	}
+	if s.files != nil {
+		if err := s.files.MarkClean(ctx, key, int64(len(content)), checksum); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Driver.Delete(ctx, pendingKey(key)); err != nil {
+		return nil, fmt.Errorf("failed to delete pending upload: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Upload released from quarantine", "key", key)
+	return &FileMetadata{
+		ID:       strings.TrimSuffix(key, filepath.Ext(key)),
+		Key:      key,
+		Size:     int64(len(content)),
+		MimeType: contentType,
+		Status:   FileStatusClean,
+		Checksum: checksum,
+	}, nil
+}
+
+// released returns the metadata of a key whose upload has already been finalized.
+func (s *Service) released(ctx context.Context, key string) (*FileMetadata, error) {
+	body, contentType, err := s.Driver.Get(ctx, key)
+	if errors.Is(err, drivers.ErrNotFound) {
+		return nil, ErrNotPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer func() { _ = body.Close() }()
+	hash := sha256.New()
+	size, err := io.Copy(hash, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return &FileMetadata{
+		ID:       strings.TrimSuffix(key, filepath.Ext(key)),
+		Key:      key,
+		Size:     size,
+		MimeType: contentType,
+		Status:   FileStatusClean,
+		Checksum: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// reject deletes a pending upload that failed validation and returns the error describing why.
+func (s *Service) reject(ctx context.Context, key, reason string) error {
+	if err := s.Driver.Delete(ctx, pendingKey(key)); err != nil {
+		return fmt.Errorf("failed to delete rejected upload: %w", err)
+	}
+	if s.files != nil {
+		if err := s.files.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	slog.WarnContext(ctx, "Upload rejected", "key", key, "reason", reason)
+	return fmt.Errorf("%w: %s", ErrUploadRejected, reason)
+}
+
+// validateContent returns why content may not be released, or "" when it may. The type is
+// sniffed from the content's leading bytes, so a renamed executable is not accepted as a PDF.
+func validateContent(content []byte, declared string) string {
+	if len(content) == 0 {
+		return "file is empty"
+	}
+	if len(content) > maxUploadSize {
+		return "file size exceeds the limit"
+	}
+	if !isAllowedContentType(declared) {
+		return "invalid or prohibited file type"
+	}
+	sniffed, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil || sniffed != declared {
+		return fmt.Sprintf("file content does not match its declared type %s", declared)
+	}
+	return ""
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/pkg/storage/drivers"
+)
+
+// stubScanner reports content containing infected as malware.
+type stubScanner struct {
+	infected []byte
+	err      error
+	scanned  int
+}
+
+func (s *stubScanner) Scan(_ context.Context, content io.Reader) (ScanResult, error) {
+	s.scanned++
+	if s.err != nil {
+		return ScanResult{}, s.err
+	}
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	if s.infected != nil && bytes.Contains(body, s.infected) {
+		return ScanResult{Signature: "Test.Stub"}, nil
+	}
+	return ScanResult{Clean: true}, nil
+}
+
+type stubPolicies map[string]*UploadPolicy
+
+func (p stubPolicies) UploadPolicy(_ context.Context, _, field string) (*UploadPolicy, error) {
+	policy, ok := p[field]
+	if !ok {
+		return nil, ErrPolicyNotFound
+	}
+	return policy, nil
+}
+
+var pdfContent = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n%%EOF\n")
+
+// uploadPending prepares an upload with service and writes content to its upload URL through
+// the local PUT handler, as a browser would.
+func uploadPending(t *testing.T, service *Service, filename, mime string, content []byte) string {
+	t.Helper()
+	metadata, err := service.Upload(context.Background(), filename, int64(len(content)), mime)
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if metadata.Status != FileStatusQuarantined {
+		t.Fatalf("expected status %s, got %q", FileStatusQuarantined, metadata.Status)
+	}
+
+	uploadURL, err := url.Parse(metadata.UploadURL)
+	if err != nil {
+		t.Fatalf("failed to parse upload URL: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPut, uploadURL.RequestURI(), bytes.NewReader(content))
+	req.SetPathValue("key", pendingKey(metadata.Key))
+	req.Header.Set("Content-Type", mime)
+	rec := httptest.NewRecorder()
+	NewHTTPHandler(service).UploadContentLocal(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204 for the upload, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	return metadata.Key
+}
+
+func newQuarantineService(t *testing.T, scanner Scanner) *Service {
+	t.Helper()
+	driver, err := drivers.NewLocalFSDriver(t.TempDir(), "/api/v1/storage", "local-dev-secret", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+	return NewService(driver, WithQuarantine(scanner))
+}
+
+func TestService_Finalize_ReleasesCleanUpload(t *testing.T) {
+	scanner := &stubScanner{}
+	service := newQuarantineService(t, scanner)
+	ctx := context.Background()
+	key := uploadPending(t, service, "invoice.pdf", "application/pdf", pdfContent)
+
+	if _, err := service.GetDownloadURL(ctx, key); !errors.Is(err, ErrQuarantined) {
+		t.Fatalf("expected ErrQuarantined before finalize, got %v", err)
+	}
+
+	metadata, err := service.Finalize(ctx, key)
+	if err != nil {
+		t.Fatalf("Finalize failed: %v", err)
+	}
+	if metadata.Status != FileStatusClean || metadata.Size != int64(len(pdfContent)) || metadata.MimeType != "application/pdf" {
+		t.Errorf("unexpected metadata: %+v", metadata)
+	}
+	if scanner.scanned != 1 {
+		t.Errorf("expected one scan, got %d", scanner.scanned)
+	}
+
+	body, contentType, err := service.Download(ctx, key)
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	defer body.Close()
+	content, _ := io.ReadAll(body)
+	if !bytes.Equal(content, pdfContent) || contentType != "application/pdf" {
+		t.Errorf("released file does not match the upload: %q (%s)", content, contentType)
+	}
+
+	// Finalizing again reports the released file without scanning it again.
+	if _, err := service.Finalize(ctx, key); err != nil {
+		t.Fatalf("second Finalize failed: %v", err)
+	}
+	if scanner.scanned != 1 {
+		t.Errorf("expected no further scan, got %d scans", scanner.scanned)
+	}
+}
+
+func TestService_Finalize_RejectsUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		mime     string
+		content  []byte
+	}{
+		{"content that is not the declared type", "invoice.pdf", "application/pdf", []byte("MZ\x90\x00 not a pdf")},
+		{"malware", "invoice.pdf", "application/pdf", append(append([]byte{}, pdfContent...), "EICAR"...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newQuarantineService(t, &stubScanner{infected: []byte("EICAR")})
+			ctx := context.Background()
+			key := uploadPending(t, service, tt.filename, tt.mime, tt.content)
+
+			if _, err := service.Finalize(ctx, key); !errors.Is(err, ErrUploadRejected) {
+				t.Fatalf("expected ErrUploadRejected, got %v", err)
+			}
+			if _, _, err := service.Driver.Get(ctx, pendingKey(key)); !errors.Is(err, drivers.ErrNotFound) {
+				t.Errorf("expected the rejected upload to be deleted, got %v", err)
+			}
+			if _, _, err := service.Download(ctx, key); !errors.Is(err, drivers.ErrNotFound) {
+				t.Errorf("expected nothing to be released, got %v", err)
+			}
+		})
+	}
+}
+
+func TestService_Finalize_ScanFailureKeepsQuarantine(t *testing.T) {
+	scanner := &stubScanner{err: errors.New("clamd unavailable")}
+	service := newQuarantineService(t, scanner)
+	ctx := context.Background()
+	key := uploadPending(t, service, "invoice.pdf", "application/pdf", pdfContent)
+
+	if _, err := service.Finalize(ctx, key); !errors.Is(err, ErrScanFailed) {
+		t.Fatalf("expected ErrScanFailed, got %v", err)
+	}
+	if _, err := service.GetDownloadURL(ctx, key); !errors.Is(err, ErrQuarantined) {
+		t.Fatalf("expected the upload to stay quarantined, got %v", err)
+	}
+
+	scanner.err = nil
+	if _, err := service.Finalize(ctx, key); err != nil {
+		t.Fatalf("retried Finalize failed: %v", err)
+	}
+}
+
+func TestService_Finalize_NothingUploaded(t *testing.T) {
+	service := newQuarantineService(t, &stubScanner{})
+	metadata, err := service.Upload(context.Background(), "invoice.pdf", 1024, "application/pdf")
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if _, err := service.Finalize(context.Background(), metadata.Key); !errors.Is(err, ErrNotPending) {
+		t.Fatalf("expected ErrNotPending, got %v", err)
+	}
+}
+
+func TestService_PrepareUpload_FormPolicy(t *testing.T) {
+	service := NewService(&MockDriver{}, WithPolicyResolver(stubPolicies{
+		"invoice": {MaxSizeBytes: 1024, ContentTypes: []string{"application/pdf"}},
+		"photo":   nil,
+	}))
+
+	tests := []struct {
+		name    string
+		field   string
+		size    int64
+		mime    string
+		wantErr error
+	}{
+		{"within the policy", "invoice", 1024, "application/pdf", nil},
+		{"too large", "invoice", 1025, "application/pdf", ErrFileTooLarge},
+		{"type not accepted", "invoice", 10, "image/png", ErrContentTypeNotAllowed},
+		{"field without limits", "photo", 10, "image/png", nil},
+		{"unknown field", "vessel", 10, "image/png", ErrPolicyNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.PrepareUpload(context.Background(), UploadRequest{
+				Filename: "file", Size: tt.size, MimeType: tt.mime, FormID: "form-1", Field: tt.field,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFinalize_Handler(t *testing.T) {
+	service := newQuarantineService(t, &stubScanner{infected: []byte("EICAR")})
+	handler := NewHTTPHandler(service)
+	clean := uploadPending(t, service, "invoice.pdf", "application/pdf", pdfContent)
+	infected := uploadPending(t, service, "photo.png", "image/png", []byte("\x89PNG\r\n\x1a\nEICAR"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /storage/{key}/finalize", handler.Finalize)
+	mux.HandleFunc("GET /storage/{key}", handler.Download)
+	send := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req = req.WithContext(withAuthContext(req.Context(), &auth.AuthContext{User: &auth.UserContext{ID: "trader-1"}}))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(http.MethodGet, "/storage/"+clean); rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for a quarantined download, got %d", rec.Code)
+	}
+
+	rec := send(http.MethodPost, "/storage/"+clean+"/finalize")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d. Body: %s", rec.Code, rec.Body.String())
+	}
+	var metadata FileMetadata
+	if err := json.NewDecoder(rec.Body).Decode(&metadata); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if metadata.Status != FileStatusClean {
+		t.Errorf("expected status %s, got %q", FileStatusClean, metadata.Status)
+	}
+	if rec := send(http.MethodGet, "/storage/"+clean); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for a released download, got %d", rec.Code)
+	}
+
+	if rec := send(http.MethodPost, "/storage/"+infected+"/finalize"); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for malware, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/storage/"+infected+"/finalize"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 once the rejected upload is deleted, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/OpenNSW/nsw/pkg/storage/drivers"
+)
+
+// ErrLegalHold is returned when deleting a file that is on legal hold.
+var ErrLegalHold = errors.New("file is on legal hold")
+
+// sweepBatchSize caps how many files of each kind a single sweep purges, so that a large
+// backlog is worked off over several sweeps.
+const sweepBatchSize = 500
+
+// errBatchFull stops listing objects once a sweep has found enough orphans.
+var errBatchFull = errors.New("sweep batch full")
+
+// RetentionPolicy sets how long stored files are kept. A zero period keeps the files it
+// applies to indefinitely.
+type RetentionPolicy struct {
+	Draft  time.Duration // Files of class RetentionDraft, from when the upload was prepared
+	Upload time.Duration // Files of class RetentionUpload, from when the upload was prepared
+	Issued time.Duration // Files of class RetentionIssued, from when they were issued
+
+	// Abandoned is how long an upload may stay in quarantine without being finalized.
+	Abandoned time.Duration
+	// OrphanGrace is how long an object without a metadata record is kept after it was last
+	// written. Files stored before metadata was recorded have no record either, so this should
+	// only be enabled once they have been backfilled. It needs a driver that implements Lister.
+	OrphanGrace time.Duration
+}
+
+// period returns the retention period of class.
+func (p RetentionPolicy) period(class string) time.Duration {
+	switch class {
+	case RetentionDraft:
+		return p.Draft
+	case RetentionUpload:
+		return p.Upload
+	case RetentionIssued:
+		return p.Issued
+	default:
+		return 0
+	}
+}
+
+// RetentionStore is a MetadataStore that also finds the files due to be purged, manages legal
+// holds and keeps the log of purged files.
+type RetentionStore interface {
+	MetadataStore
+	// ListExpired returns up to limit files of class stored before storedBefore that are not on legal hold.
+	ListExpired(ctx context.Context, class string, storedBefore time.Time, limit int) ([]StoredFile, error)
+	// ListAbandoned returns up to limit quarantined files stored before storedBefore that are not on legal hold.
+	ListAbandoned(ctx context.Context, storedBefore time.Time, limit int) ([]StoredFile, error)
+	// PlaceLegalHold places the file stored under key on legal hold.
+	PlaceLegalHold(ctx context.Context, key, by, reason string, at time.Time) error
+	// ReleaseLegalHold releases the file stored under key from legal hold.
+	ReleaseLegalHold(ctx context.Context, key string) error
+	// RecordPurge adds purge to the purge log.
+	RecordPurge(ctx context.Context, purge *Purge) error
+	// ListPurges returns up to limit files purged since since, most recent first.
+	ListPurges(ctx context.Context, since time.Time, limit int) ([]Purge, error)
+}
+
+// checkNotHeld returns ErrLegalHold if the file stored under key is on legal hold.
+func (s *Service) checkNotHeld(ctx context.Context, key string) error {
+	if s.files == nil {
+		return nil
+	}
+	file, err := s.files.Get(ctx, key)
+	if errors.Is(err, ErrFileNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if file.LegalHold {
+		return fmt.Errorf("%w: %s", ErrLegalHold, file.LegalHoldReason)
+	}
+	return nil
+}
+
+// purge removes the object stored under key, any upload pending for it and its record. The
+// record goes last so that a failed purge is retried by the next sweep.
+func (s *Service) purge(ctx context.Context, key string) error {
+	if err := s.Driver.Delete(ctx, pendingKey(key)); err != nil {
+		return fmt.Errorf("failed to delete pending upload: %w", err)
+	}
+	if err := s.Driver.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	if s.files != nil {
+		return s.files.Delete(ctx, key)
+	}
+	return nil
+}
+
+// PurgeReport lists the files purged by a sweep.
+type PurgeReport struct {
+	Purged []Purge `json:"purged"`
+	Failed int     `json:"failed"` // Files that could not be purged and are retried by the next sweep
+}
+
+// count returns how many files were purged for reason.
+func (r *PurgeReport) count(reason string) int {
+	n := 0
+	for _, purge := range r.Purged {
+		if purge.Reason == reason {
+			n++
+		}
+	}
+	return n
+}
+
+// Sweeper periodically purges stored files whose retention period has passed, uploads
+// abandoned in quarantine and, if enabled, objects without a metadata record. Files on legal
+// hold are never purged. Every purge is recorded in the purge log.
+type Sweeper struct {
+	service  *Service
+	store    RetentionStore
+	policy   RetentionPolicy
+	interval time.Duration
+	now      func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewSweeper creates a Sweeper that purges the files of service recorded in store according
+// to policy, sweeping every interval, defaulting to one hour.
+func NewSweeper(service *Service, store RetentionStore, policy RetentionPolicy, interval time.Duration) *Sweeper {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Sweeper{
+		service:  service,
+		store:    store,
+		policy:   policy,
+		interval: interval,
+		now:      func() time.Time { return time.Now().UTC() },
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start sweeps until Stop is called.
+func (s *Sweeper) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if _, err := s.Sweep(context.Background()); err != nil {
+					slog.Error("storage: failed to sweep stored files", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops sweeping and waits for an in-flight sweep to finish. It must only be called after Start.
+func (s *Sweeper) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// Sweep purges the files due to be purged and reports what it purged. Files that fail to
+// purge are logged and counted; an error is only returned when the files due could not be found.
+func (s *Sweeper) Sweep(ctx context.Context) (*PurgeReport, error) {
+	now := s.now()
+	report := &PurgeReport{}
+
+	for _, class := range []string{RetentionDraft, RetentionUpload, RetentionIssued} {
+		period := s.policy.period(class)
+		if period <= 0 {
+			continue
+		}
+		files, err := s.store.ListExpired(ctx, class, now.Add(-period), sweepBatchSize)
+		if err != nil {
+			return report, err
+		}
+		for i := range files {
+			s.purgeFile(ctx, report, &files[i], PurgeExpired, now)
+		}
+	}
+
+	if s.policy.Abandoned > 0 {
+		files, err := s.store.ListAbandoned(ctx, now.Add(-s.policy.Abandoned), sweepBatchSize)
+		if err != nil {
+			return report, err
+		}
+		for i := range files {
+			s.purgeFile(ctx, report, &files[i], PurgeAbandoned, now)
+		}
+	}
+
+	if s.policy.OrphanGrace > 0 {
+		if err := s.purgeOrphans(ctx, report, now); err != nil {
+			return report, err
+		}
+	}
+
+	if len(report.Purged) > 0 || report.Failed > 0 {
+		slog.Info("storage: purged stored files",
+			"expired", report.count(PurgeExpired),
+			"abandoned", report.count(PurgeAbandoned),
+			"orphaned", report.count(PurgeOrphaned),
+			"failed", report.Failed)
+	}
+	return report, nil
+}
+
+// purgeFile purges file unless it has been placed on legal hold since it was listed.
+func (s *Sweeper) purgeFile(ctx context.Context, report *PurgeReport, file *StoredFile, reason string, now time.Time) {
+	current, err := s.store.Get(ctx, file.Key)
+	if errors.Is(err, ErrFileNotFound) || (err == nil && current.LegalHold) {
+		return
+	}
+	if err == nil {
+		err = s.service.purge(ctx, file.Key)
+	}
+	if err != nil {
+		slog.Error("storage: failed to purge file", "key", file.Key, "reason", reason, "error", err)
+		report.Failed++
+		return
+	}
+	s.record(ctx, report, &Purge{
+		Key:            file.Key,
+		Reason:         reason,
+		RetentionClass: file.RetentionClass,
+		UploadedBy:     file.UploadedBy,
+		TaskID:         file.TaskID,
+		ConsignmentID:  file.ConsignmentID,
+		Filename:       file.Filename,
+		Size:           file.Size,
+		Checksum:       file.Checksum,
+		StoredAt:       file.CreatedAt,
+		PurgedAt:       now,
+	})
+}
+
+// purgeOrphans deletes the objects, pending uploads included, that have no metadata record
+// and were last written before the grace period.
+func (s *Sweeper) purgeOrphans(ctx context.Context, report *PurgeReport, now time.Time) error {
+	lister, ok := s.service.Driver.(Lister)
+	if !ok {
+		slog.Warn("storage: driver cannot list objects, orphaned objects are not purged")
+		return nil
+	}
+
+	cutoff := now.Add(-s.policy.OrphanGrace)
+	var orphans []drivers.ObjectInfo
+	err := lister.List(ctx, func(object drivers.ObjectInfo) error {
+		if !object.ModTime.Before(cutoff) {
+			return nil
+		}
+		_, err := s.store.Get(ctx, strings.TrimSuffix(object.Key, pendingSuffix))
+		if !errors.Is(err, ErrFileNotFound) {
+			return err
+		}
+		orphans = append(orphans, object)
+		if len(orphans) == sweepBatchSize {
+			return errBatchFull
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchFull) {
+		return fmt.Errorf("failed to list stored objects: %w", err)
+	}
+
+	for _, object := range orphans {
+		if err := s.service.Driver.Delete(ctx, object.Key); err != nil {
+			slog.Error("storage: failed to purge orphaned object", "key", object.Key, "error", err)
+			report.Failed++
+			continue
+		}
+		s.record(ctx, report, &Purge{
+			Key:      object.Key,
+			Reason:   PurgeOrphaned,
+			Size:     object.Size,
+			StoredAt: object.ModTime,
+			PurgedAt: now,
+		})
+	}
+	return nil
+}
+
+// record adds purge to report and the purge log. The file is already gone, so a failure to
+// log it is only reported.
+func (s *Sweeper) record(ctx context.Context, report *PurgeReport, purge *Purge) {
+	purge.ID = uuid.NewString()
+	report.Purged = append(report.Purged, *purge)
+	if err := s.store.RecordPurge(ctx, purge); err != nil {
+		slog.Error("storage: failed to record purged file", "key", purge.Key, "reason", purge.Reason, "error", err)
+	}
+}
+
+// ListExpired returns up to limit files of class stored before storedBefore that are not on
+// legal hold, oldest first.
+func (s *GormMetadataStore) ListExpired(ctx context.Context, class string, storedBefore time.Time, limit int) ([]StoredFile, error) {
+	var files []StoredFile
+	err := s.db.WithContext(ctx).
+		Where("retention_class = ? AND created_at < ? AND legal_hold = ?", class, storedBefore, false).
+		Order("created_at").Limit(limit).Find(&files).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired files: %w", err)
+	}
+	return files, nil
+}
+
+// ListAbandoned returns up to limit quarantined files stored before storedBefore that are not
+// on legal hold, oldest first.
+func (s *GormMetadataStore) ListAbandoned(ctx context.Context, storedBefore time.Time, limit int) ([]StoredFile, error) {
+	var files []StoredFile
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND created_at < ? AND legal_hold = ?", FileStatusQuarantined, storedBefore, false).
+		Order("created_at").Limit(limit).Find(&files).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list abandoned uploads: %w", err)
+	}
+	return files, nil
+}
+
+// PlaceLegalHold places the file stored under key on legal hold.
+func (s *GormMetadataStore) PlaceLegalHold(ctx context.Context, key, by, reason string, at time.Time) error {
+	return s.updateLegalHold(ctx, key, map[string]any{
+		"legal_hold":        true,
+		"legal_hold_reason": reason,
+		"legal_hold_by":     by,
+		"legal_hold_at":     at,
+	})
+}
+
+// ReleaseLegalHold releases the file stored under key from legal hold.
+func (s *GormMetadataStore) ReleaseLegalHold(ctx context.Context, key string) error {
+	return s.updateLegalHold(ctx, key, map[string]any{
+		"legal_hold":        false,
+		"legal_hold_reason": "",
+		"legal_hold_by":     "",
+		"legal_hold_at":     nil,
+	})
+}
+
+func (s *GormMetadataStore) updateLegalHold(ctx context.Context, key string, updates map[string]any) error {
+	result := s.db.WithContext(ctx).Model(&StoredFile{}).Where("key = ?", key).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update legal hold: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrFileNotFound
+	}
+	return nil
+}
+
+// RecordPurge adds purge to the purge log.
+func (s *GormMetadataStore) RecordPurge(ctx context.Context, purge *Purge) error {
+	if err := s.db.WithContext(ctx).Create(purge).Error; err != nil {
+		return fmt.Errorf("failed to record purge: %w", err)
+	}
+	return nil
+}
+
+// ListPurges returns up to limit files purged since since, most recent first.
+func (s *GormMetadataStore) ListPurges(ctx context.Context, since time.Time, limit int) ([]Purge, error) {
+	var purges []Purge
+	err := s.db.WithContext(ctx).Where("purged_at >= ?", since).
+		Order("purged_at DESC").Limit(limit).Find(&purges).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purges: %w", err)
+	}
+	return purges, nil
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/OpenNSW/nsw/internal/auth"
+	"github.com/OpenNSW/nsw/pkg/storage/drivers"
+)
+
+// memoryRetention is an in-memory RetentionStore.
+type memoryRetention struct {
+	memoryFiles
+	purges []Purge
+}
+
+func (m *memoryRetention) ListExpired(_ context.Context, class string, storedBefore time.Time, _ int) ([]StoredFile, error) {
+	var files []StoredFile
+	for _, file := range m.memoryFiles {
+		if file.RetentionClass == class && file.CreatedAt.Before(storedBefore) && !file.LegalHold {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (m *memoryRetention) ListAbandoned(_ context.Context, storedBefore time.Time, _ int) ([]StoredFile, error) {
+	var files []StoredFile
+	for _, file := range m.memoryFiles {
+		if file.Status == FileStatusQuarantined && file.CreatedAt.Before(storedBefore) && !file.LegalHold {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (m *memoryRetention) PlaceLegalHold(_ context.Context, key, by, reason string, at time.Time) error {
+	file, ok := m.memoryFiles[key]
+	if !ok {
+		return ErrFileNotFound
+	}
+	file.LegalHold, file.LegalHoldBy, file.LegalHoldReason, file.LegalHoldAt = true, by, reason, &at
+	m.memoryFiles[key] = file
+	return nil
+}
+
+func (m *memoryRetention) ReleaseLegalHold(_ context.Context, key string) error {
+	file, ok := m.memoryFiles[key]
+	if !ok {
+		return ErrFileNotFound
+	}
+	file.LegalHold, file.LegalHoldBy, file.LegalHoldReason, file.LegalHoldAt = false, "", "", nil
+	m.memoryFiles[key] = file
+	return nil
+}
+
+func (m *memoryRetention) RecordPurge(_ context.Context, purge *Purge) error {
+	m.purges = append(m.purges, *purge)
+	return nil
+}
+
+func (m *memoryRetention) ListPurges(context.Context, time.Time, int) ([]Purge, error) {
+	return m.purges, nil
+}
+
+func TestSweeper_Sweep(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	driver := drivers.NewMemoryDriver()
+	store := &memoryRetention{memoryFiles: memoryFiles{}}
+	service := NewService(driver, WithQuarantine(&stubScanner{}), WithMetadata(store))
+	ctx := context.Background()
+
+	stored := func(key, class, status string, age time.Duration, hold bool) {
+		t.Helper()
+		object := key
+		if status == FileStatusQuarantined {
+			object = pendingKey(key)
+		}
+		if err := driver.Save(ctx, object, bytes.NewReader(pdfContent), "application/pdf"); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		store.memoryFiles[key] = StoredFile{Key: key, RetentionClass: class, Status: status, CreatedAt: now.Add(-age), LegalHold: hold}
+	}
+	stored("draft-old.pdf", RetentionDraft, FileStatusClean, 40*24*time.Hour, false)
+	stored("draft-new.pdf", RetentionDraft, FileStatusClean, 10*24*time.Hour, false)
+	stored("draft-held.pdf", RetentionDraft, FileStatusClean, 40*24*time.Hour, true)
+	stored("issued-old.pdf", RetentionIssued, FileStatusClean, 400*24*time.Hour, false)
+	stored("upload-abandoned.pdf", RetentionUpload, FileStatusQuarantined, 48*time.Hour, false)
+	stored("upload-pending.pdf", RetentionUpload, FileStatusQuarantined, time.Hour, false)
+
+	// An object without a record, written long ago.
+	if err := driver.Save(ctx, "orphan.pdf", bytes.NewReader(pdfContent), "application/pdf"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	driver.SetModTime("orphan.pdf", now.Add(-72*time.Hour))
+
+	sweeper := NewSweeper(service, store, RetentionPolicy{
+		Draft:       30 * 24 * time.Hour,
+		Abandoned:   24 * time.Hour,
+		OrphanGrace: 24 * time.Hour,
+	}, time.Hour)
+	sweeper.now = func() time.Time { return now }
+
+	report, err := sweeper.Sweep(ctx)
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	purged := map[string]string{}
+	for _, purge := range report.Purged {
+		purged[purge.Key] = purge.Reason
+	}
+	want := map[string]string{
+		"draft-old.pdf":        PurgeExpired,
+		"upload-abandoned.pdf": PurgeAbandoned,
+		"orphan.pdf":           PurgeOrphaned,
+	}
+	if len(purged) != len(want) || report.Failed != 0 {
+		t.Fatalf("expected %v to be purged, got %v (%d failed)", want, purged, report.Failed)
+	}
+	for key, reason := range want {
+		if purged[key] != reason {
+			t.Errorf("expected %s to be purged as %s, got %q", key, reason, purged[key])
+		}
+		if _, ok := store.memoryFiles[key]; ok {
+			t.Errorf("expected the record of %s to be deleted", key)
+		}
+	}
+	if len(store.purges) != len(want) {
+		t.Errorf("expected %d purges to be logged, got %d", len(want), len(store.purges))
+	}
+	for _, key := range []string{"draft-old.pdf", pendingKey("upload-abandoned.pdf"), "orphan.pdf"} {
+		if _, _, err := driver.Get(ctx, key); !errors.Is(err, drivers.ErrNotFound) {
+			t.Errorf("expected %s to be deleted, got %v", key, err)
+		}
+	}
+	for _, key := range []string{"draft-new.pdf", "draft-held.pdf", "issued-old.pdf", pendingKey("upload-pending.pdf")} {
+		body, _, err := driver.Get(ctx, key)
+		if err != nil {
+			t.Errorf("expected %s to be kept, got %v", key, err)
+			continue
+		}
+		body.Close()
+	}
+}
+
+func TestService_Delete_LegalHold(t *testing.T) {
+	const key = "550e8400-e29b-41d4-a716-446655440000.pdf"
+	store := &memoryRetention{memoryFiles: memoryFiles{key: {Key: key, UploadedBy: "user:trader-1"}}}
+	service := NewService(&MockDriver{}, WithMetadata(store))
+	service.RegisterAuthorizer(uploaderOnly{})
+	handler := NewHTTPHandler(service)
+	ctx := context.Background()
+
+	if err := store.PlaceLegalHold(ctx, key, "admin-1", "customs investigation", time.Now()); err != nil {
+		t.Fatalf("PlaceLegalHold failed: %v", err)
+	}
+	if err := service.Delete(ctx, key); !errors.Is(err, ErrLegalHold) {
+		t.Fatalf("expected ErrLegalHold, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/files/"+key, nil)
+	req.SetPathValue("key", key)
+	req = req.WithContext(withAuthContext(req.Context(), &auth.AuthContext{User: &auth.UserContext{ID: "trader-1"}}))
+	rec := httptest.NewRecorder()
+	handler.Delete(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+
+	if err := store.ReleaseLegalHold(ctx, key); err != nil {
+		t.Fatalf("ReleaseLegalHold failed: %v", err)
+	}
+	if err := service.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete after release failed: %v", err)
+	}
+}
+
+func TestService_PrepareUpload_RetentionClass(t *testing.T) {
+	files := memoryFiles{}
+	service := NewService(&MockDriver{}, WithMetadata(files))
+
+	draft, err := service.PrepareUpload(context.Background(), UploadRequest{Filename: "a.pdf", Size: 1, MimeType: "application/pdf"})
+	if err != nil {
+		t.Fatalf("PrepareUpload failed: %v", err)
+	}
+	if class := files[draft.Key].RetentionClass; class != RetentionDraft {
+		t.Errorf("expected an unattached upload to be %s, got %s", RetentionDraft, class)
+	}
+}
+
+func TestGormMetadataStore_ListExpired(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormMetadataStore(db)
+	if err != nil {
+		t.Fatalf("NewGormMetadataStore failed: %v", err)
+	}
+	before := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "stored_files" WHERE retention_class = $1 AND created_at < $2 AND legal_hold = $3 ORDER BY created_at LIMIT $4`)).
+		WithArgs(RetentionDraft, before, false, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "retention_class"}).AddRow("k1", RetentionDraft))
+
+	files, err := store.ListExpired(context.Background(), RetentionDraft, before, 10)
+	if err != nil {
+		t.Fatalf("ListExpired failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Key != "k1" {
+		t.Errorf("unexpected files: %+v", files)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGormMetadataStore_PlaceLegalHold_NotFound(t *testing.T) {
+	db, mock := setupTestDB(t)
+	store, err := NewGormMetadataStore(db)
+	if err != nil {
+		t.Fatalf("NewGormMetadataStore failed: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "stored_files" SET`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = store.PlaceLegalHold(context.Background(), "missing", "admin-1", "investigation", time.Now())
+	if !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
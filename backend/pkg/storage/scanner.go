@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ScanResult is the verdict of a malware scan.
+type ScanResult struct {
+	Clean     bool
+	Signature string // Name of the detected threat when the content is not clean
+}
+
+// Scanner checks content for malware before it is released from quarantine.
+type Scanner interface {
+	Scan(ctx context.Context, content io.Reader) (ScanResult, error)
+}
+
+// NoopScanner reports all content as clean. It is meant for local development, where no
+// scanning daemon is available; uploads are still checked against their declared type and size.
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(context.Context, io.Reader) (ScanResult, error) {
+	return ScanResult{Clean: true}, nil
+}
+
+// clamdChunkSize is the size of the chunks streamed to clamd. It must stay below the
+// daemon's StreamMaxLength, which bounds the whole stream rather than each chunk.
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner scans content with a ClamAV daemon, or any server that speaks its INSTREAM
+// protocol, over TCP or a Unix socket.
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner creates a scanner for the daemon at address, given as tcp://host:port or
+// unix:///path/to/clamd.sock. timeout bounds each scan, including the upload of the content.
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clamd address: %w", err)
+	}
+	s := &ClamdScanner{network: u.Scheme, timeout: timeout}
+	switch u.Scheme {
+	case "tcp":
+		s.address = u.Host
+	case "unix":
+		s.address = u.Path
+	default:
+		return nil, fmt.Errorf("unsupported clamd address scheme %q: use tcp:// or unix://", u.Scheme)
+	}
+	if s.address == "" {
+		return nil, fmt.Errorf("clamd address %q has no host or path", address)
+	}
+	return s, nil
+}
+
+// Scan streams content to clamd with the INSTREAM command and parses its reply, which is
+// "stream: OK" for clean content and "stream: <signature> FOUND" for infected content.
+func (s *ClamdScanner) Scan(ctx context.Context, content io.Reader) (ScanResult, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return ScanResult{}, fmt.Errorf("failed to send scan command: %w", err)
+	}
+	buf := make([]byte, 4+clamdChunkSize)
+	for {
+		n, readErr := io.ReadFull(content, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return ScanResult{}, fmt.Errorf("failed to stream content to clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return ScanResult{}, fmt.Errorf("failed to read content: %w", readErr)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return ScanResult{}, fmt.Errorf("failed to end content stream: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return ScanResult{}, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+func parseClamdReply(reply string) (ScanResult, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case verdict == "OK":
+		return ScanResult{Clean: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return ScanResult{Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return ScanResult{}, fmt.Errorf("clamd scan failed: %q", reply)
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd accepts one INSTREAM connection, reassembles the streamed content and replies
+// with reply(content).
+func fakeClamd(t *testing.T, reply func(content []byte) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		if cmd, err := r.ReadString(0); err != nil || cmd != "zINSTREAM\x00" {
+			return
+		}
+		var content bytes.Buffer
+		for {
+			var size uint32
+			if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err := io.CopyN(&content, r, int64(size)); err != nil {
+				return
+			}
+		}
+		_, _ = conn.Write([]byte(reply(content.Bytes()) + "\x00"))
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestClamdScanner_Scan(t *testing.T) {
+	eicar := "X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"
+	reply := func(content []byte) string {
+		if bytes.Contains(content, []byte("EICAR")) {
+			return "stream: Win.Test.EICAR_HDB-1 FOUND"
+		}
+		return "stream: OK"
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		clean     bool
+		signature string
+	}{
+		{"clean content", "%PDF-1.4 hello", true, ""},
+		{"infected content", eicar, false, "Win.Test.EICAR_HDB-1"},
+		{"content spanning chunks", strings.Repeat("a", 3*clamdChunkSize+7), true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner, err := NewClamdScanner(fakeClamd(t, reply), 5*time.Second)
+			if err != nil {
+				t.Fatalf("NewClamdScanner failed: %v", err)
+			}
+			result, err := scanner.Scan(context.Background(), strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if result.Clean != tt.clean || result.Signature != tt.signature {
+				t.Errorf("expected clean=%v signature=%q, got %+v", tt.clean, tt.signature, result)
+			}
+		})
+	}
+}
+
+func TestClamdScanner_ScanError(t *testing.T) {
+	address := fakeClamd(t, func([]byte) string { return "INSTREAM size limit exceeded. ERROR" })
+	scanner, err := NewClamdScanner(address, 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewClamdScanner failed: %v", err)
+	}
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("content")); err == nil {
+		t.Fatal("expected an error for a clamd error reply")
+	}
+}
+
+func TestNewClamdScanner_InvalidAddress(t *testing.T) {
+	for _, address := range []string{"localhost:3310", "http://localhost:3310", "tcp://", "unix://"} {
+		if _, err := NewClamdScanner(address, time.Second); err == nil {
+			t.Errorf("expected an error for %q", address)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log/slog"
@@ -13,58 +16,215 @@ import (
 
 // Service coordinates file storage operations and manages metadata
 type Service struct {
-	Driver StorageDriver
+	Driver     StorageDriver
+	scanner    Scanner        // Uploads are quarantined until Finalize when set
+	policies   PolicyResolver // Resolves per-form upload limits; optional
+	files      MetadataStore  // Records who stored each file; optional
+	authorizer Authorizer     // Checks access against the records in files; unrestricted when nil
 }
 
-func NewService(driver StorageDriver) *Service {
-	return &Service{Driver: driver}
+// Option configures optional Service behaviour.
+type Option func(*Service)
+
+// WithQuarantine holds every upload in quarantine until Finalize has checked its content
+// against its declared type and size and scanner has found it clean.
+func WithQuarantine(scanner Scanner) Option {
+	return func(s *Service) {
+		s.scanner = scanner
+	}
+}
+
+// WithPolicyResolver lets uploads name the form field they are for, whose limits then apply.
+func WithPolicyResolver(policies PolicyResolver) Option {
+	return func(s *Service) {
+		s.policies = policies
+	}
+}
+
+// WithMetadata records every stored file in files, with its uploader, the task or consignment
+// it belongs to and its retention class.
+func WithMetadata(files MetadataStore) Option {
+	return func(s *Service) {
+		s.files = files
+	}
+}
+
+func NewService(driver StorageDriver, opts ...Option) *Service {
+	s := &Service{Driver: driver}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RegisterAuthorizer registers the authorizer that checks file access against the records
+// kept with WithMetadata.
+func (s *Service) RegisterAuthorizer(authorizer Authorizer) {
+	s.authorizer = authorizer
 }
 
 // Upload handles the preparation of a file upload by generating a unique key
 // and a presigned/upload URL via the storage driver.
 func (s *Service) Upload(ctx context.Context, filename string, size int64, mime string) (*FileMetadata, error) {
+	return s.PrepareUpload(ctx, UploadRequest{Filename: filename, Size: size, MimeType: mime})
+}
+
+// PrepareUpload checks req against the limits of its form field, if it names one, checks that
+// the caller may attach files to the task or consignment it links to, records the file and
+// returns the key and presigned URL for the upload. With quarantine enabled the URL writes to a pending
+// object, which Finalize releases under the key once the content has been validated.
+func (s *Service) PrepareUpload(ctx context.Context, req UploadRequest) (*FileMetadata, error) {
+	mime := req.MimeType
 	if mime == "" {
 		mime = drivers.DefaultMime
 	}
+	if req.FormID != "" {
+		if err := s.checkPolicy(ctx, req.FormID, req.Field, req.Size, mime); err != nil {
+			return nil, err
+		}
+	}
+	if s.authorizer != nil && req.Link != (FileLink{}) {
+		if err := s.authorizer.AuthorizeFileLink(ctx, req.Link); err != nil {
+			return nil, err
+		}
+	}
+
 	id := uuid.NewString()
-	ext := filepath.Ext(filename)
+	ext := filepath.Ext(req.Filename)
 	key := fmt.Sprintf("%s%s", id, ext)
+	uploadKey, status := key, ""
+	if s.scanner != nil {
+		uploadKey, status = pendingKey(key), FileStatusQuarantined
+	}
 
 	// Generate a presigned URL for the upload
-	uploadURL, err := s.Driver.GetUploadURL(ctx, key, mime, size)
+	uploadURL, err := s.Driver.GetUploadURL(ctx, uploadKey, mime, req.Size)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate upload URL: %w", err)
 	}
 
+	if s.files != nil {
+		class := RetentionUpload
+		if req.Link == (FileLink{}) {
+			class = RetentionDraft
+		}
+		err := s.files.Create(ctx, &StoredFile{
+			Key:            key,
+			UploadedBy:     req.UploadedBy,
+			TaskID:         req.Link.TaskID,
+			ConsignmentID:  req.Link.ConsignmentID,
+			Filename:       req.Filename,
+			Size:           req.Size,
+			MimeType:       mime,
+			RetentionClass: class,
+			Status:         status,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	metadata := &FileMetadata{
 		ID:        id,
-		Name:      filename,
+		Name:      req.Filename,
 		Key:       key,
 		UploadURL: uploadURL,
-		Size:      size,
+		Size:      req.Size,
 		MimeType:  mime,
+		Status:    status,
 	}
 
 	slog.InfoContext(ctx, "File upload prepared", "id", id, "key", key)
 	return metadata, nil
 }
 
-// Download retrieves the file content and its MIME type
+// Save stores content generated by the server itself, such as an issued certificate, under a
+// new unique key and returns its metadata. The file is recorded as issued by the system for
+// the task and consignment in link.
+func (s *Service) Save(ctx context.Context, filename string, content []byte, mime string, link FileLink) (*FileMetadata, error) {
+	if mime == "" {
+		mime = drivers.DefaultMime
+	}
+	id := uuid.NewString()
+	key := fmt.Sprintf("%s%s", id, filepath.Ext(filename))
+	checksum := sha256Hex(content)
+
+	if err := s.Driver.Save(ctx, key, bytes.NewReader(content), mime); err != nil {
+		return nil, fmt.Errorf("failed to save file: %w", err)
+	}
+	if s.files != nil {
+		err := s.files.Create(ctx, &StoredFile{
+			Key:            key,
+			UploadedBy:     SystemPrincipal,
+			TaskID:         link.TaskID,
+			ConsignmentID:  link.ConsignmentID,
+			Filename:       filename,
+			Size:           int64(len(content)),
+			MimeType:       mime,
+			Checksum:       checksum,
+			RetentionClass: RetentionIssued,
+			Status:         FileStatusClean,
+		})
+		if err != nil {
+			_ = s.Driver.Delete(ctx, key)
+			return nil, err
+		}
+	}
+
+	slog.InfoContext(ctx, "File saved", "id", id, "key", key)
+	return &FileMetadata{
+		ID:       id,
+		Name:     filename,
+		Key:      key,
+		Size:     int64(len(content)),
+		MimeType: mime,
+		Checksum: checksum,
+	}, nil
+}
+
+// Download retrieves the file content and its MIME type. It returns ErrQuarantined while
+// the key has an upload awaiting Finalize.
 func (s *Service) Download(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	if err := s.checkReleased(ctx, key); err != nil {
+		return nil, "", err
+	}
 	return s.Driver.Get(ctx, key)
 }
 
-// GetDownloadURL generates a time-limited or presigned URL for the given key
+// GetDownloadURL generates a time-limited or presigned URL for the given key. It returns
+// ErrQuarantined while the key has an upload awaiting Finalize.
 func (s *Service) GetDownloadURL(ctx context.Context, key string) (string, error) {
+	if err := s.checkReleased(ctx, key); err != nil {
+		return "", err
+	}
 	return s.Driver.GetDownloadURL(ctx, key)
 }
 
-// Delete removes a file from storage
+// Delete removes a file from storage, along with any upload still pending for its key and
+// the file's record. It returns ErrLegalHold if the file is on legal hold.
 func (s *Service) Delete(ctx context.Context, key string) error {
+	if err := s.checkNotHeld(ctx, key); err != nil {
+		return err
+	}
+	if s.scanner != nil {
+		if err := s.Driver.Delete(ctx, pendingKey(key)); err != nil {
+			return fmt.Errorf("failed to delete pending upload: %w", err)
+		}
+	}
 	err := s.Driver.Delete(ctx, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
+	if s.files != nil {
+		if err := s.files.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
 	slog.InfoContext(ctx, "File deleted successfully", "key", key)
 	return nil
 }
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
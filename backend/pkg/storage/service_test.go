@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 )
 
@@ -130,3 +131,23 @@ func TestUploadService_GetDownloadURL_Error(t *testing.T) {
 		t.Errorf("expected error %v, got %v", expectedErr, err)
 	}
 }
+
+func TestUploadService_Save(t *testing.T) {
+	mock := &MockDriver{}
+	service := NewService(mock)
+
+	metadata, err := service.Save(context.Background(), "receipt.pdf", []byte("%PDF-1.4"), "application/pdf", FileLink{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if mock.SavedKey != metadata.Key {
+		t.Errorf("expected driver to save %s, got %s", metadata.Key, mock.SavedKey)
+	}
+	if !strings.HasSuffix(metadata.Key, ".pdf") {
+		t.Errorf("expected key to keep the .pdf extension, got %s", metadata.Key)
+	}
+	if metadata.Size != 8 || string(mock.SavedBody) != "%PDF-1.4" {
+		t.Errorf("unexpected saved content: size %d, body %q", metadata.Size, mock.SavedBody)
+	}
+}
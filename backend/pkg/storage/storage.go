@@ -3,6 +3,8 @@ package storage
 import (
 	"context"
 	"io"
+
+	"github.com/OpenNSW/nsw/pkg/storage/drivers"
 )
 
 // StorageDriver defines how we interact with the binary storage
@@ -22,3 +24,10 @@ type StorageDriver interface {
 	// GetUploadURL returns a presigned URL for uploading a file directly to storage
 	GetUploadURL(ctx context.Context, key string, contentType string, maxSizeBytes int64) (string, error)
 }
+
+// Lister is implemented by drivers that can enumerate the objects they store. The retention
+// sweeper needs it to find objects that have no metadata record.
+type Lister interface {
+	// List calls fn for every stored object, stopping at the first error fn returns
+	List(ctx context.Context, fn func(drivers.ObjectInfo) error) error
+}
@@ -27,6 +27,7 @@ NPQS_M2M_CLIENT_SECRET="${THUNDER_M2M_NPQS_SECRET:-${M2M_CLIENT_SECRET}}"
 FCAU_M2M_CLIENT_SECRET="${THUNDER_M2M_FCAU_SECRET:-${M2M_CLIENT_SECRET}}"
 IRD_M2M_CLIENT_SECRET="${THUNDER_M2M_IRD_SECRET:-${M2M_CLIENT_SECRET}}"
 CDA_M2M_CLIENT_SECRET="${THUNDER_M2M_CDA_SECRET:-${M2M_CLIENT_SECRET}}"
+NSW_TO_OGA_M2M_CLIENT_SECRET="${THUNDER_M2M_NSW_TO_OGA_SECRET:-${M2M_CLIENT_SECRET}}"
 
 log_info "Creating sample Thunder resources..."
 echo ""
@@ -508,6 +509,89 @@ JSON
     fi
 }
 
+create_role_in_ou() {
+    local ROLE_NAME="$1"
+    local ROLE_DESCRIPTION="$2"
+    local OU_ID="$3"
+    local RESPONSE HTTP_CODE BODY ROLE_ID
+
+    log_info "Creating ${ROLE_NAME} role..."
+
+    read -r -d '' ROLE_PAYLOAD <<JSON || true
+{
+    "name": "${ROLE_NAME}",
+    "description": "${ROLE_DESCRIPTION}",
+    "ouId": "${OU_ID}",
+    "permissions": []
+}
+JSON
+
+    RESPONSE=$(thunder_api_call POST "/roles" "${ROLE_PAYLOAD}")
+    HTTP_CODE="${RESPONSE: -3}"
+    BODY="${RESPONSE%???}"
+
+    if [[ "$HTTP_CODE" == "201" ]] || [[ "$HTTP_CODE" == "200" ]]; then
+        log_success "${ROLE_NAME} role created successfully"
+        ROLE_ID=$(extract_first_id "$BODY")
+    elif [[ "$HTTP_CODE" == "409" ]]; then
+        log_warning "${ROLE_NAME} role already exists, retrieving ID..."
+        ROLE_ID=$(get_role_id_by_name "${ROLE_NAME}" "$OU_ID")
+    else
+        log_error "Failed to create ${ROLE_NAME} role (HTTP $HTTP_CODE)"
+        echo "Response: $BODY"
+        exit 1
+    fi
+
+    if [[ -z "$ROLE_ID" ]]; then
+        log_error "Could not determine ${ROLE_NAME} role ID"
+        exit 1
+    fi
+
+    log_info "${ROLE_NAME} role ID: $ROLE_ID"
+    CREATED_ROLE_ID="$ROLE_ID"
+}
+
+create_group_in_ou() {
+    local GROUP_NAME="$1"
+    local GROUP_DESCRIPTION="$2"
+    local OU_ID="$3"
+    local RESPONSE HTTP_CODE BODY GROUP_ID
+
+    log_info "Creating ${GROUP_NAME} group..."
+
+    read -r -d '' GROUP_PAYLOAD <<JSON || true
+{
+    "name": "${GROUP_NAME}",
+    "description": "${GROUP_DESCRIPTION}",
+    "ouId": "${OU_ID}"
+}
+JSON
+
+    RESPONSE=$(thunder_api_call POST "/groups" "${GROUP_PAYLOAD}")
+    HTTP_CODE="${RESPONSE: -3}"
+    BODY="${RESPONSE%???}"
+
+    if [[ "$HTTP_CODE" == "201" ]] || [[ "$HTTP_CODE" == "200" ]]; then
+        log_success "${GROUP_NAME} group created successfully"
+        GROUP_ID=$(extract_first_id "$BODY")
+    elif [[ "$HTTP_CODE" == "409" ]]; then
+        log_warning "${GROUP_NAME} group already exists, retrieving ID..."
+        GROUP_ID=$(get_group_id_by_name "${GROUP_NAME}" "$OU_ID")
+    else
+        log_error "Failed to create ${GROUP_NAME} group (HTTP $HTTP_CODE)"
+        echo "Response: $BODY"
+        exit 1
+    fi
+
+    if [[ -z "$GROUP_ID" ]]; then
+        log_error "Could not determine ${GROUP_NAME} group ID"
+        exit 1
+    fi
+
+    log_info "${GROUP_NAME} group ID: $GROUP_ID"
+    CREATED_GROUP_ID="$GROUP_ID"
+}
+
 # ============================================================================
 # Create Private Sector Organization Unit
 # ============================================================================
@@ -1175,6 +1259,32 @@ ensure_user_in_group "$TRADERS_GROUP_ID" "$USER_789" "Traders" "trader_only_user
 
 echo ""
 
+# ============================================================================
+# Create OGA Officer Roles (Reviewer, Supervisor, Read-only)
+# ============================================================================
+
+create_role_in_ou "OGA_Reviewer" "Role for OGA officers who review applications" "$GOVERNMENT_ORG_OU_ID"
+OGA_REVIEWER_ROLE_ID="$CREATED_ROLE_ID"
+
+create_role_in_ou "OGA_Supervisor" "Role for OGA officers who supervise reviews" "$GOVERNMENT_ORG_OU_ID"
+
+create_role_in_ou "OGA_ReadOnly" "Role for OGA officers with read-only access" "$GOVERNMENT_ORG_OU_ID"
+
+echo ""
+
+# Each agency's sample user reviews applications through a Reviewers group in its own OU.
+for agency in "NPQS|${NPQS_OU_ID}|${USER_NPQS_ID}|npqs_user" \
+              "FCAU|${FCAU_OU_ID}|${USER_FCAU_ID}|fcau_user" \
+              "IRD|${IRD_OU_ID}|${USER_IRD_ID}|ird_user" \
+              "CDA|${CDA_OU_ID}|${USER_CDA_ID}|cda_user"; do
+    IFS='|' read -r AGENCY AGENCY_OU_ID AGENCY_USER_ID AGENCY_USERNAME <<< "$agency"
+    create_group_in_ou "${AGENCY}_Reviewers" "${AGENCY} reviewing officers group" "$AGENCY_OU_ID"
+    assign_role_to_group "$OGA_REVIEWER_ROLE_ID" "$CREATED_GROUP_ID" "OGA_Reviewer" "${AGENCY}_Reviewers"
+    ensure_user_in_group "$CREATED_GROUP_ID" "$AGENCY_USER_ID" "${AGENCY}_Reviewers" "$AGENCY_USERNAME"
+done
+
+echo ""
+
 # ============================================================================
 # Fetch Theme and Flow IDs (optional)
 # ============================================================================
@@ -1268,6 +1378,10 @@ IRD_TO_NSW_M2M_APP_ID="$CREATED_M2M_APP_ID"
 create_m2m_application "CDA_TO_NSW_M2M" "Machine-to-machine integration for CDA to NSW" "CDA_TO_NSW" "${CDA_M2M_CLIENT_SECRET}" "${DEFAULT_OU_ID_FOR_M2M}"
 CDA_TO_NSW_M2M_APP_ID="$CREATED_M2M_APP_ID"
 
+# The NSW backend injects applications into the OGA services with this client.
+create_m2m_application "NSW_TO_OGA_M2M" "Machine-to-machine integration for NSW to OGA services" "NSW_TO_OGA" "${NSW_TO_OGA_M2M_CLIENT_SECRET}" "${DEFAULT_OU_ID_FOR_M2M}"
+NSW_TO_OGA_M2M_APP_ID="$CREATED_M2M_APP_ID"
+
 echo ""
 
 # ============================================================================
@@ -1283,11 +1397,12 @@ log_info "Private user type: Private_User"
 log_info "Government user type: Government_User"
 log_info "Traders group -> Trader role"
 log_info "CHA group -> CHA role"
+log_info "<Agency>_Reviewers groups -> OGA_Reviewer role"
 log_info "both_roles_user in groups: Traders, CHA"
 log_info "cha_only_user in groups: CHA"
 log_info "trader_only_user in groups: Traders"
 log_info "Government users: npqs_user, fcau_user, ird_user, cda_user"
 log_info "App client IDs: TRADER_PORTAL_APP, OGA_PORTAL_APP_NPQS, OGA_PORTAL_APP_FCAU, OGA_PORTAL_APP_IRD, OGA_PORTAL_APP_CDA"
-log_info "M2M client IDs: NPQS_TO_NSW, FCAU_TO_NSW, IRD_TO_NSW, CDA_TO_NSW"
+log_info "M2M client IDs: NPQS_TO_NSW, FCAU_TO_NSW, IRD_TO_NSW, CDA_TO_NSW, NSW_TO_OGA"
 log_info "M2M auth method: client_secret_basic"
 echo ""
\ No newline at end of file
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/OpenNSW/nsw/oga/internal"
+	"github.com/OpenNSW/nsw/oga/internal/auth"
+	"github.com/OpenNSW/nsw/oga/internal/feedback"
+	"github.com/OpenNSW/nsw/oga/internal/storage"
+	"github.com/OpenNSW/nsw/oga/pkg/httpclient"
+)
+
+func main() {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		log.Fatalf("FATAL: failed to load configuration: %v", err)
+	}
+
+	slog.Info("OGA service configuration",
+		"db_driver", cfg.DB.Driver,
+		"db_path", cfg.DB.Path,
+		"port", cfg.Port,
+		"config_dir", cfg.ConfigDir,
+		"claim_ttl", cfg.ClaimTTL,
+		"callback_max_attempts", cfg.Callback.MaxAttempts,
+		"config_watch_interval", cfg.ConfigWatchInterval,
+	)
+
+	// Initialize database store
+	store, err := internal.NewApplicationStore(cfg)
+	if err != nil {
+		log.Fatalf("failed to create application store: %v", err)
+	}
+	// Load the task configs and forms as the current config version
+	configs, err := internal.NewConfigRegistry(context.Background(), store, cfg.ConfigDir, cfg.DefaultTaskConfigID)
+	if err != nil {
+		log.Fatalf("failed to load task configs and forms: %v", err)
+	}
+
+	// Create OAuth2 Authenticator for NSW API
+	nswOAuth2Client := httpclient.NewOAuth2Authenticator(
+		cfg.NSW.ClientID,
+		cfg.NSW.ClientSecret,
+		cfg.NSW.TokenURL,
+		cfg.NSW.Scopes,
+	)
+
+	// Initialize HTTP client for NSW API integration with optional TLS configuration
+	nswHttpClient := httpclient.NewClientBuilder().
+		WithBaseURL(cfg.NSW.BaseURL).
+		WithTimeout(10 * time.Second).
+		WithAuthenticator(nswOAuth2Client).
+		WithTLS(&httpclient.TLSConfig{InsecureSkipVerify: cfg.NSW.TokenInsecureSkipVerify}).
+		Build()
+
+	// Deliver review decisions back to NSW and watch the config files in the background
+	dispatcher := internal.NewCallbackDispatcher(store, nswHttpClient, cfg.Callback)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	background.Go(func() { dispatcher.Run(backgroundCtx) })
+	if cfg.ConfigWatchInterval > 0 {
+		background.Go(func() { configs.Watch(backgroundCtx, cfg.ConfigWatchInterval) })
+	}
+
+	// Initialize OGA service
+	service := internal.NewOGAService(store, configs, dispatcher, cfg.ClaimTTL)
+	defer func() {
+		if err := service.Close(); err != nil {
+			slog.Error("failed to close service", "error", err)
+		}
+	}()
+
+	// Initialize handlers
+	handler, err := internal.NewOGAHandler(service, cfg.MaxRequestBytes)
+	if err != nil {
+		log.Fatalf("failed to create OGA handler: %v", err)
+	}
+
+	// Initialize storage service and handler
+	storageService := storage.NewService(nswHttpClient)
+	storageHandler := storage.NewHandler(storageService, cfg.MaxRequestBytes)
+
+	feedbackHandler := feedback.NewHandler(service)
+
+	// Initialize token validation for officers and the NSW backend
+	jwksHttpClient := &http.Client{Timeout: 10 * time.Second}
+	if cfg.Auth.InsecureSkipTLSVerify {
+		jwksHttpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	tokenExtractor, err := auth.NewTokenExtractor(
+		cfg.Auth.JWKSURL,
+		cfg.Auth.Issuer,
+		append(append([]string{}, cfg.Auth.PortalClientIDs...), cfg.Auth.NSWClientIDs...),
+		jwksHttpClient,
+	)
+	if err != nil {
+		log.Fatalf("failed to create token extractor: %v", err)
+	}
+	officer := auth.RequireRole(auth.OfficerRoles...)
+	reviewer := auth.RequireRole(auth.ReviewerRoles...)
+	supervisor := auth.RequireRole(auth.RoleSupervisor)
+	nsw := auth.RequireClient(cfg.Auth.NSWClientIDs...)
+
+	// Set up HTTP routes
+	mux := http.NewServeMux()
+	// Health check
+	mux.HandleFunc("GET /health", handler.HandleHealth)
+	// Endpoint for the NSW backend to inject data
+	mux.Handle("POST /api/oga/inject", nsw(http.HandlerFunc(handler.HandleInjectData)))
+	// Endpoints for UI to fetch and manage applications
+	mux.Handle("GET /api/oga/workflows", officer(http.HandlerFunc(handler.HandleGetWorkflows)))
+	mux.Handle("GET /api/oga/applications", officer(http.HandlerFunc(handler.HandleGetApplications)))
+
+	mux.Handle("GET /api/oga/applications/{taskId}", officer(http.HandlerFunc(handler.HandleGetApplication)))
+	mux.Handle("POST /api/oga/applications/{taskId}/review", reviewer(http.HandlerFunc(handler.HandleReviewApplication)))
+	mux.Handle("POST /api/oga/applications/{taskId}/feedback", reviewer(http.HandlerFunc(feedbackHandler.HandleFeedback)))
+	mux.Handle("POST /api/oga/applications/{taskId}/approval", supervisor(http.HandlerFunc(handler.HandleDecideApproval)))
+	mux.Handle("POST /api/oga/applications/{taskId}/claim", reviewer(http.HandlerFunc(handler.HandleClaimApplication)))
+	mux.Handle("DELETE /api/oga/applications/{taskId}/claim", reviewer(http.HandlerFunc(handler.HandleReleaseClaim)))
+	mux.Handle("PUT /api/oga/applications/{taskId}/assignment", supervisor(http.HandlerFunc(handler.HandleAssignApplication)))
+	mux.Handle("POST /api/oga/applications/{taskId}/callbacks/resend", supervisor(http.HandlerFunc(handler.HandleResendCallbacks)))
+
+	// Work queues and the assignment roster
+	mux.Handle("GET /api/oga/queue/mine", reviewer(http.HandlerFunc(handler.HandleGetMyQueue)))
+	mux.Handle("GET /api/oga/queue/unassigned", reviewer(http.HandlerFunc(handler.HandleGetUnassignedQueue)))
+	mux.Handle("GET /api/oga/officers", supervisor(http.HandlerFunc(handler.HandleGetOfficers)))
+	mux.Handle("PUT /api/oga/officers/{officerId}", supervisor(http.HandlerFunc(handler.HandleUpdateOfficer)))
+
+	// Task config and form versions
+	mux.Handle("GET /api/oga/config", supervisor(http.HandlerFunc(handler.HandleGetConfigVersion)))
+	mux.Handle("POST /api/oga/config/reload", supervisor(http.HandlerFunc(handler.HandleReloadConfig)))
+
+	mux.Handle("POST /api/oga/uploads", reviewer(http.HandlerFunc(storageHandler.HandleCreateUpload)))
+	mux.Handle("GET /api/oga/uploads/{key}", officer(http.HandlerFunc(storageHandler.HandleGetUploadURL)))
+	authenticated := auth.Middleware(tokenExtractor)(mux)
+
+	// Set up graceful shutdown
+	serverAddr := fmt.Sprintf(":%s", cfg.Port)
+
+	// CORS middleware
+	allowAll := len(cfg.AllowedOrigins) == 1 && cfg.AllowedOrigins[0] == "*"
+	allowedSet := make(map[string]struct{}, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		allowedSet[o] = struct{}{}
+	}
+
+	corsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if allowAll {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else if _, ok := allowedSet[origin]; ok {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Vary", "Origin")
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+
+	server := &http.Server{
+		Addr:    serverAddr,
+		Handler: corsHandler,
+	}
+
+	// Channel to listen for interrupt signals
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start server in a goroutine
+	go func() {
+		slog.Info("starting OGA service", "port", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("failed to start server", "error", err)
+			quit <- syscall.SIGTERM
+		}
+	}()
+
+	// Wait for interrupt signal
+	<-quit
+	slog.Info("shutting down OGA service...")
+
+	// Create a context with timeout for graceful shutdown
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+
+	// Attempt graceful shutdown of HTTP server
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("server forced to shutdown", "error", err)
+	} else {
+		slog.Info("server gracefully stopped")
+	}
+
+	// Stop the background workers before the deferred Close shuts the store
+	stopBackground()
+	background.Wait()
+
+	slog.Info("OGA service stopped")
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// StatusPendingApproval is the status of an application whose review awaits a supervisor's
+// countersignature, for tasks configured with TaskBehavior.RequireApproval.
+const StatusPendingApproval = "PENDING_APPROVAL"
+
+// Review decision actions recorded in ApplicationRecord.ReviewHistory.
+const (
+	// DecisionSubmit is a reviewer's decision on the application.
+	DecisionSubmit = "submit"
+	// DecisionApprove countersigns the reviewer's decision, which is then sent to NSW.
+	DecisionApprove = "approve"
+	// DecisionReturn sends the application back to the reviewer to decide again.
+	DecisionReturn = "return"
+	// DecisionOverride replaces the reviewer's decision with the supervisor's, which is then
+	// sent to NSW.
+	DecisionOverride = "override"
+)
+
+var (
+	// ErrNotAwaitingApproval is returned when deciding on an application that is not PENDING_APPROVAL.
+	ErrNotAwaitingApproval = errors.New("application is not awaiting approval")
+	// ErrSelfApproval is returned when an officer tries to approve their own review.
+	ErrSelfApproval = errors.New("a review must be approved by a different officer")
+	// ErrSupervisorRequired is returned when an officer who is not a supervisor decides on a review.
+	ErrSupervisorRequired = errors.New("only supervisors may approve reviews")
+	// ErrInvalidDecision is returned for an unknown approval action or an override without a response.
+	ErrInvalidDecision = errors.New("invalid approval decision")
+)
+
+// ReviewDecision is one entry in an application's review history.
+type ReviewDecision struct {
+	OfficerID string         `json:"officerId"`
+	Action    string         `json:"action"`
+	Response  map[string]any `json:"response,omitempty"` // Review form data of submit and override decisions
+	Comment   string         `json:"comment,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// ApprovalRequest is a supervisor's decision on a review awaiting approval.
+type ApprovalRequest struct {
+	Action   string         `json:"action"` // approve, return or override
+	Comment  string         `json:"comment,omitempty"`
+	Response map[string]any `json:"response,omitempty"` // Replacement review form data, for override
+}
+
+// requiresApproval reports whether the task config version requires reviews to be countersigned.
+func (s *ogaService) requiresApproval(ctx context.Context, configVersion, taskCode string) bool {
+	config, err := s.configs.Snapshot(ctx, configVersion).Tasks.GetConfig(taskCode)
+	return err == nil && config.Behavior != nil && config.Behavior.RequireApproval
+}
+
+// DecideApproval approves, returns or overrides a review awaiting approval. Approved and
+// overridden decisions are queued for the service; returned applications go back to PENDING
+// for the reviewer. The supervisor must hold the claim on the application and must not be the
+// officer who reviewed it.
+func (s *ogaService) DecideApproval(ctx context.Context, taskID string, officer Officer, req ApprovalRequest) error {
+	switch req.Action {
+	case DecisionApprove, DecisionReturn:
+	case DecisionOverride:
+		if len(req.Response) == 0 {
+			return fmt.Errorf("%w: override requires a response", ErrInvalidDecision)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidDecision, req.Action)
+	}
+
+	app, err := s.GetApplication(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if app.Status != StatusPendingApproval {
+		return ErrNotAwaitingApproval
+	}
+	if !officer.Supervisor {
+		return ErrSupervisorRequired
+	}
+	if app.ReviewedBy == officer.ID {
+		return ErrSelfApproval
+	}
+	if app.ClaimedBy != officer.ID {
+		return ErrClaimRequired
+	}
+
+	now := s.now()
+	decision := ReviewDecision{
+		OfficerID: officer.ID,
+		Action:    req.Action,
+		Comment:   req.Comment,
+		Timestamp: now.UTC(),
+	}
+
+	if req.Action == DecisionReturn {
+		if err := s.store.RecordDecision(ctx, taskID, "PENDING", decision, nil, now); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "review returned to reviewer", "taskID", taskID, "officerID", officer.ID, "reviewerID", app.ReviewedBy)
+		return nil
+	}
+
+	response := app.OgaActionData
+	if req.Action == DecisionOverride {
+		response = req.Response
+		decision.Response = req.Response
+	}
+	callback, err := newCallback(app.ServiceURL, TaskResponse{
+		TaskID:     app.TaskID,
+		WorkflowID: app.WorkflowID,
+		Payload: map[string]any{
+			"action":  "OGA_VERIFICATION",
+			"content": response,
+		},
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.RecordDecision(ctx, taskID, s.outcomeStatus(ctx, app.ConfigVersion, app.TaskCode, response), decision, callback, now); err != nil {
+		return err
+	}
+	s.dispatcher.Notify()
+	slog.InfoContext(ctx, "review approved", "taskID", taskID, "officerID", officer.ID, "action", req.Action)
+	return nil
+}
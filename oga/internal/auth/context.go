@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// IdP role names carried in UserContext.Roles. Supervisors can do everything reviewers can;
+// read-only officers can view applications but not act on them.
+const (
+	RoleReviewer   = "OGA_Reviewer"
+	RoleSupervisor = "OGA_Supervisor"
+	RoleReadOnly   = "OGA_ReadOnly"
+)
+
+var (
+	// OfficerRoles may view applications.
+	OfficerRoles = []string{RoleReviewer, RoleSupervisor, RoleReadOnly}
+	// ReviewerRoles may review applications, request changes and upload attachments.
+	ReviewerRoles = []string{RoleReviewer, RoleSupervisor}
+)
+
+// UserContext is an officer signed in to the OGA portal.
+type UserContext struct {
+	IDPUserID string   `json:"idpUserId"`
+	Email     string   `json:"email"`
+	OUID      string   `json:"ouId"`
+	ClientID  string   `json:"clientId"`
+	Roles     []string `json:"roles"`
+}
+
+// HasAnyRole reports whether the officer holds at least one of roles.
+func (u *UserContext) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(u.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientContext is a machine client, such as the NSW backend.
+type ClientContext struct {
+	ClientID string `json:"clientId"`
+}
+
+// AuthContext is the authentication context injected into each request by Middleware.
+// Exactly one of User and Client is set.
+type AuthContext struct {
+	User   *UserContext
+	Client *ClientContext
+}
+
+// ContextKey is a custom type for context keys to avoid collisions.
+type ContextKey string
+
+const AuthContextKey ContextKey = "authContext"
+
+// GetAuthContext returns the AuthContext of the request, or nil if the request carried no
+// token or Middleware was not applied.
+func GetAuthContext(ctx context.Context) *AuthContext {
+	authCtx, ok := ctx.Value(AuthContextKey).(*AuthContext)
+	if !ok {
+		return nil
+	}
+	return authCtx
+}
+
+// WithAuthContext returns a copy of ctx carrying authCtx.
+func WithAuthContext(ctx context.Context, authCtx *AuthContext) context.Context {
+	return context.WithValue(ctx, AuthContextKey, authCtx)
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware_Routes(t *testing.T) {
+	extractor, privateKey := newTokenExtractor(t)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	mux := http.NewServeMux()
+	mux.Handle("GET /health", ok)
+	mux.Handle("POST /inject", RequireClient(testNSWClientID)(ok))
+	mux.Handle("GET /applications", RequireRole(OfficerRoles...)(ok))
+	mux.Handle("POST /review", RequireRole(ReviewerRoles...)(ok))
+	handler := Middleware(extractor)(mux)
+
+	reviewer := newOfficerToken(t, privateKey, RoleReviewer)
+	supervisor := newOfficerToken(t, privateKey, RoleSupervisor)
+	readOnly := newOfficerToken(t, privateKey, RoleReadOnly)
+	noRole := newOfficerToken(t, privateKey)
+	nsw := newClientToken(t, privateKey, testNSWClientID)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"health is public", http.MethodGet, "/health", "", http.StatusOK},
+		{"invalid token", http.MethodGet, "/health", "garbage", http.StatusUnauthorized},
+		{"anonymous read", http.MethodGet, "/applications", "", http.StatusUnauthorized},
+		{"read-only officer reads", http.MethodGet, "/applications", readOnly, http.StatusOK},
+		{"officer without role reads", http.MethodGet, "/applications", noRole, http.StatusForbidden},
+		{"client reads", http.MethodGet, "/applications", nsw, http.StatusForbidden},
+		{"reviewer reviews", http.MethodPost, "/review", reviewer, http.StatusOK},
+		{"supervisor reviews", http.MethodPost, "/review", supervisor, http.StatusOK},
+		{"read-only officer reviews", http.MethodPost, "/review", readOnly, http.StatusForbidden},
+		{"anonymous inject", http.MethodPost, "/inject", "", http.StatusUnauthorized},
+		{"officer injects", http.MethodPost, "/inject", supervisor, http.StatusForbidden},
+		{"NSW injects", http.MethodPost, "/inject", nsw, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireClient_RejectsOtherClients(t *testing.T) {
+	extractor, privateKey := newTokenExtractor(t)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	// The portal client ID is trusted by the extractor but is not allowed to inject.
+	handler := Middleware(extractor)(RequireClient(testNSWClientID)(ok))
+
+	req := httptest.NewRequest(http.MethodPost, "/inject", nil)
+	req.Header.Set("Authorization", "Bearer "+newClientToken(t, privateKey, testPortalClientID))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer         = "https://localhost:8090"
+	testPortalClientID = "OGA_PORTAL_APP_NPQS"
+	testNSWClientID    = "NSW_TO_OGA"
+	testKid            = "test-kid"
+)
+
+// newTokenExtractor starts a JWKS server publishing a new signing key and returns an extractor
+// that trusts it, together with the key.
+func newTokenExtractor(t *testing.T) (*TokenExtractor, *rsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{{
+				"kid": testKid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(jwksServer.Close)
+
+	extractor, err := NewTokenExtractor(jwksServer.URL, testIssuer, []string{testPortalClientID, testNSWClientID}, nil)
+	if err != nil {
+		t.Fatalf("failed to create token extractor: %v", err)
+	}
+	return extractor, privateKey
+}
+
+func newBaseClaims(clientID string, grantType AllowedGrantType) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":        testIssuer,
+		"client_id":  clientID,
+		"grant_type": grantType,
+		"iat":        now.Add(-1 * time.Minute).Unix(),
+		"exp":        now.Add(10 * time.Minute).Unix(),
+	}
+}
+
+func newOfficerToken(t *testing.T, privateKey *rsa.PrivateKey, roles ...string) string {
+	t.Helper()
+	claims := newBaseClaims(testPortalClientID, AuthorizationCodeGrant)
+	claims["sub"] = "officer-001"
+	claims["email"] = "npqs_user@government.dev"
+	claims["ouId"] = "OU-NPQS"
+	claims["roles"] = roles
+	return signToken(t, privateKey, claims)
+}
+
+func newClientToken(t *testing.T, privateKey *rsa.PrivateKey, clientID string) string {
+	t.Helper()
+	claims := newBaseClaims(clientID, ClientCredentialsGrant)
+	claims["sub"] = clientID
+	return signToken(t, privateKey, claims)
+}
+
+func signToken(t *testing.T, privateKey *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKid
+	signedToken, err := token.SignedString(privateKey)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signedToken
+}
@@ -0,0 +1,228 @@
+// Package auth authenticates callers of the OGA service API. Officers sign in to the OGA
+// portal and present a user token carrying their roles; the NSW backend injects applications
+// with a client credentials token. Both are JWTs validated against the IdP's JWKS.
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type AllowedGrantType string
+
+const (
+	AuthorizationCodeGrant AllowedGrantType = "authorization_code"
+	ClientCredentialsGrant AllowedGrantType = "client_credentials"
+)
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	ClientID  string           `json:"client_id"`
+	GrantType AllowedGrantType `json:"grant_type"`
+	Email     *string          `json:"email,omitempty"`
+	OUID      *string          `json:"ouId,omitempty"`
+	Roles     []string         `json:"roles,omitempty"`
+}
+
+type jwksResponse struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+const defaultJWKSCacheTTL = 5 * time.Minute
+
+// TokenExtractor validates bearer tokens against the IdP's JWKS and resolves the officer or
+// machine client that presented them.
+type TokenExtractor struct {
+	jwksURL      string
+	expIssuer    string
+	expClientIDs []string
+	httpClient   *http.Client
+
+	cacheMu       sync.RWMutex
+	cachedJWKS    *jwksResponse
+	lastJWKSFetch time.Time
+	jwksCacheTTL  time.Duration
+}
+
+// NewTokenExtractor creates a TokenExtractor that accepts tokens issued by issuer to one of
+// expectedClientIDs. A nil httpClient uses a default client with a 10 second timeout.
+func NewTokenExtractor(jwksURL, issuer string, expectedClientIDs []string, httpClient *http.Client) (*TokenExtractor, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	extractor := &TokenExtractor{
+		jwksURL:      strings.TrimSpace(jwksURL),
+		expIssuer:    strings.TrimSpace(issuer),
+		expClientIDs: expectedClientIDs,
+		jwksCacheTTL: defaultJWKSCacheTTL,
+		httpClient:   httpClient,
+	}
+
+	if extractor.jwksURL == "" {
+		return nil, fmt.Errorf("jwks url is not configured")
+	}
+	if extractor.expIssuer == "" {
+		return nil, fmt.Errorf("issuer is not configured")
+	}
+	if len(extractor.expClientIDs) == 0 {
+		return nil, fmt.Errorf("client ids are not configured")
+	}
+
+	return extractor, nil
+}
+
+// ExtractFromHeader validates the token in an Authorization header of the form
+// "Bearer <jwt_token>" and returns the auth context of its principal.
+func (te *TokenExtractor) ExtractFromHeader(authHeader string) (*AuthContext, error) {
+	if authHeader == "" {
+		return nil, fmt.Errorf("authorization header is empty")
+	}
+	parts := strings.Fields(strings.TrimSpace(authHeader))
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return nil, fmt.Errorf("invalid authorization header format: expected 'Bearer <token>'")
+	}
+
+	claims := &tokenClaims{}
+	parsedToken, err := jwt.ParseWithClaims(parts[1], claims, te.keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(te.expIssuer),
+		// TODO: Validate the audience once Thunder (IdP) supports defining the audience claim.
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt token: %w", err)
+	}
+	if !parsedToken.Valid {
+		return nil, fmt.Errorf("invalid jwt token")
+	}
+
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("jwt missing exp claim")
+	}
+	if claims.ClientID == "" {
+		return nil, fmt.Errorf("jwt missing client_id claim")
+	}
+	if !slices.Contains(te.expClientIDs, claims.ClientID) {
+		return nil, fmt.Errorf("unexpected client_id claim: %q", claims.ClientID)
+	}
+
+	switch claims.GrantType {
+	case AuthorizationCodeGrant:
+		if claims.Subject == "" {
+			return nil, fmt.Errorf("jwt missing sub claim for user principal")
+		}
+		if claims.Email == nil {
+			return nil, fmt.Errorf("jwt missing email claim for user principal")
+		}
+		if claims.OUID == nil {
+			return nil, fmt.Errorf("jwt missing ouId claim for user principal")
+		}
+		return &AuthContext{User: &UserContext{
+			IDPUserID: claims.Subject,
+			Email:     *claims.Email,
+			OUID:      *claims.OUID,
+			ClientID:  claims.ClientID,
+			Roles:     claims.Roles,
+		}}, nil
+	case ClientCredentialsGrant:
+		return &AuthContext{Client: &ClientContext{ClientID: claims.ClientID}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported grant type: %q", claims.GrantType)
+	}
+}
+
+func (te *TokenExtractor) keyFunc(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok || strings.TrimSpace(kid) == "" {
+		return nil, fmt.Errorf("token header has missing or invalid kid")
+	}
+
+	// Key rotation can result in an unknown kid in the cache; force a refresh and retry once.
+	for _, forceRefresh := range []bool{false, true} {
+		keySet, err := te.getJWKS(forceRefresh)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keySet.Keys {
+			if key.Kid == kid {
+				return parseRSAPublicKey(key)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no jwk found for kid: %s", kid)
+}
+
+func (te *TokenExtractor) getJWKS(forceRefresh bool) (*jwksResponse, error) {
+	te.cacheMu.RLock()
+	if !forceRefresh && te.cacheValid(time.Now()) {
+		cached := te.cachedJWKS
+		te.cacheMu.RUnlock()
+		return cached, nil
+	}
+	te.cacheMu.RUnlock()
+
+	te.cacheMu.Lock()
+	defer te.cacheMu.Unlock()
+
+	// Re-check after acquiring the write lock in case another goroutine refreshed it.
+	now := time.Now()
+	if !forceRefresh && te.cacheValid(now) {
+		return te.cachedJWKS, nil
+	}
+
+	jwks, err := te.fetchJWKS()
+	if err != nil {
+		return nil, err
+	}
+	te.cachedJWKS = jwks
+	te.lastJWKSFetch = now
+
+	return te.cachedJWKS, nil
+}
+
+func (te *TokenExtractor) cacheValid(now time.Time) bool {
+	return te.cachedJWKS != nil && te.jwksCacheTTL > 0 && now.Sub(te.lastJWKSFetch) < te.jwksCacheTTL
+}
+
+func (te *TokenExtractor) fetchJWKS() (*jwksResponse, error) {
+	response, err := te.httpClient.Get(te.jwksURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned status %d", response.StatusCode)
+	}
+
+	var jwks jwksResponse
+	if err := json.NewDecoder(response.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("failed to decode jwks response: %w", err)
+	}
+	if len(jwks.Keys) == 0 {
+		return nil, fmt.Errorf("jwks response has no keys")
+	}
+
+	return &jwks, nil
+}
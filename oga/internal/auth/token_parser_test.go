@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractFromHeader_Officer(t *testing.T) {
+	extractor, privateKey := newTokenExtractor(t)
+
+	authCtx, err := extractor.ExtractFromHeader("Bearer " + newOfficerToken(t, privateKey, RoleReviewer))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if authCtx.Client != nil || authCtx.User == nil {
+		t.Fatalf("expected a user context, got %+v", authCtx)
+	}
+	user := authCtx.User
+	if user.IDPUserID != "officer-001" || user.OUID != "OU-NPQS" || user.ClientID != testPortalClientID {
+		t.Errorf("unexpected user context: %+v", user)
+	}
+	if !user.HasAnyRole(RoleSupervisor, RoleReviewer) || user.HasAnyRole(RoleSupervisor) {
+		t.Errorf("unexpected roles: %v", user.Roles)
+	}
+}
+
+func TestExtractFromHeader_Client(t *testing.T) {
+	extractor, privateKey := newTokenExtractor(t)
+
+	authCtx, err := extractor.ExtractFromHeader("bearer " + newClientToken(t, privateKey, testNSWClientID))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if authCtx.User != nil || authCtx.Client == nil || authCtx.Client.ClientID != testNSWClientID {
+		t.Fatalf("expected a client context, got %+v", authCtx)
+	}
+}
+
+func TestExtractFromHeader_Rejects(t *testing.T) {
+	extractor, privateKey := newTokenExtractor(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+
+	withClaims := func(edit func(map[string]any)) string {
+		claims := newBaseClaims(testPortalClientID, AuthorizationCodeGrant)
+		claims["sub"] = "officer-001"
+		claims["email"] = "npqs_user@government.dev"
+		claims["ouId"] = "OU-NPQS"
+		edit(claims)
+		return "Bearer " + signToken(t, privateKey, claims)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"empty header", "", "authorization header is empty"},
+		{"not a bearer token", "Basic dXNlcjpwYXNz", "invalid authorization header format"},
+		{"malformed token", "Bearer not-a-jwt", "invalid jwt token"},
+		{"signed with another key", "Bearer " + newOfficerToken(t, otherKey, RoleReviewer), "invalid jwt token"},
+		{"unexpected issuer", withClaims(func(c map[string]any) { c["iss"] = "https://evil.example" }), "invalid jwt token"},
+		{"expired", withClaims(func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }), "invalid jwt token"},
+		{"missing exp", withClaims(func(c map[string]any) { delete(c, "exp") }), "jwt missing exp claim"},
+		{"unexpected client", withClaims(func(c map[string]any) { c["client_id"] = "TRADER_PORTAL_APP" }), "unexpected client_id claim"},
+		{"unsupported grant type", withClaims(func(c map[string]any) { c["grant_type"] = "password" }), "unsupported grant type"},
+		{"officer without ouId", withClaims(func(c map[string]any) { delete(c, "ouId") }), "jwt missing ouId claim"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := extractor.ExtractFromHeader(tt.header)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewTokenExtractor_Validation(t *testing.T) {
+	if _, err := NewTokenExtractor("", testIssuer, []string{testNSWClientID}, nil); err == nil {
+		t.Error("expected an error without a jwks url")
+	}
+	if _, err := NewTokenExtractor("https://localhost:8090/oauth2/jwks", "", []string{testNSWClientID}, nil); err == nil {
+		t.Error("expected an error without an issuer")
+	}
+	if _, err := NewTokenExtractor("https://localhost:8090/oauth2/jwks", testIssuer, nil, nil); err == nil {
+		t.Error("expected an error without client ids")
+	}
+}
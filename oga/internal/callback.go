@@ -0,0 +1,376 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/OpenNSW/nsw/oga/pkg/httpclient"
+	"gorm.io/gorm"
+)
+
+// Delivery statuses of a CallbackRecord.
+const (
+	CallbackPending   = "PENDING"
+	CallbackDelivered = "DELIVERED"
+	CallbackFailed    = "FAILED"
+)
+
+// IdempotencyKeyHeader carries a callback's idempotency key, which stays the same across
+// redeliveries so that the service can ignore a decision it has already applied.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const (
+	// callbackLease is how long a callback taken for delivery is hidden from other dispatch
+	// passes, so that a dispatcher that dies mid-delivery does not strand it.
+	callbackLease = time.Minute
+	// maxCallbackBackoff caps the delay between delivery attempts.
+	maxCallbackBackoff = time.Hour
+	// callbackBatchSize is the number of callbacks delivered per dispatch pass.
+	callbackBatchSize = 50
+)
+
+var (
+	// ErrNoCallback is returned when resending the callback of an application that never sent one.
+	ErrNoCallback = errors.New("application has no callback to resend")
+	// ErrCallbackDelivered is returned when resending callbacks that were all delivered.
+	ErrCallbackDelivered = errors.New("application callbacks have already been delivered")
+)
+
+// CallbackRecord is a decision waiting to be, or already, sent back to the service that
+// injected the application. It is written in the same transaction as the decision and
+// delivered by the CallbackDispatcher.
+type CallbackRecord struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement"`
+	TaskID         string    `gorm:"type:text;index;not null"`
+	ServiceURL     string    `gorm:"type:varchar(512);not null"`
+	Payload        string    `gorm:"type:text;not null"` // TaskResponse JSON
+	IdempotencyKey string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Status         string    `gorm:"type:varchar(20);index;not null"` // PENDING, DELIVERED, FAILED
+	Attempts       int       `gorm:"not null"`
+	NextAttemptAt  time.Time `gorm:"index"`
+	LastError      string    `gorm:"type:text"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for CallbackRecord
+func (CallbackRecord) TableName() string {
+	return "callbacks"
+}
+
+// CallbackDelivery is the delivery status of an application's callbacks, shown in the UI.
+type CallbackDelivery struct {
+	Status        string     `json:"status"` // PENDING, DELIVERED or FAILED
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"` // Set while the callback is pending
+	DeliveredAt   *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// newCallback creates a pending callback carrying response to serviceURL, due at now.
+func newCallback(serviceURL string, response TaskResponse, now time.Time) (*CallbackRecord, error) {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	return &CallbackRecord{
+		TaskID:         response.TaskID,
+		ServiceURL:     serviceURL,
+		Payload:        string(payload),
+		IdempotencyKey: hex.EncodeToString(key),
+		Status:         CallbackPending,
+		NextAttemptAt:  now,
+	}, nil
+}
+
+// deliveryOf summarises the callbacks of one application, oldest first. A failed callback
+// takes precedence, then the oldest pending one, then the latest delivered one.
+func deliveryOf(callbacks []CallbackRecord) *CallbackDelivery {
+	if len(callbacks) == 0 {
+		return nil
+	}
+	current := &callbacks[len(callbacks)-1]
+	for i := len(callbacks) - 1; i >= 0; i-- {
+		if callbacks[i].Status == CallbackFailed {
+			current = &callbacks[i]
+			break
+		}
+		if callbacks[i].Status == CallbackPending {
+			current = &callbacks[i]
+		}
+	}
+
+	delivery := &CallbackDelivery{
+		Status:      current.Status,
+		Attempts:    current.Attempts,
+		LastError:   current.LastError,
+		DeliveredAt: current.DeliveredAt,
+	}
+	if current.Status == CallbackPending {
+		nextAttemptAt := current.NextAttemptAt
+		delivery.NextAttemptAt = &nextAttemptAt
+	}
+	return delivery
+}
+
+// ListCallbacks retrieves the callbacks of the given applications, oldest first, keyed by task ID.
+func (s *ApplicationStore) ListCallbacks(ctx context.Context, taskIDs []string) (map[string][]CallbackRecord, error) {
+	callbacks := make(map[string][]CallbackRecord, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return callbacks, nil
+	}
+	var records []CallbackRecord
+	if err := s.db.WithContext(ctx).Where("task_id IN ?", taskIDs).Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		callbacks[record.TaskID] = append(callbacks[record.TaskID], record)
+	}
+	return callbacks, nil
+}
+
+// DueCallbacks retrieves up to limit pending callbacks due at now, oldest first. A callback is
+// only due once every earlier callback of the same application has been delivered, so that
+// the service receives an application's decisions in order.
+func (s *ApplicationStore) DueCallbacks(ctx context.Context, now time.Time, limit int) ([]CallbackRecord, error) {
+	var callbacks []CallbackRecord
+	undeliveredEarlier := s.db.Table("callbacks AS earlier").
+		Select("1").
+		Where("earlier.task_id = callbacks.task_id AND earlier.id < callbacks.id AND earlier.status <> ?", CallbackDelivered)
+	if err := s.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", CallbackPending, now).
+		Where("NOT EXISTS (?)", undeliveredEarlier).
+		Order("id").
+		Limit(limit).
+		Find(&callbacks).Error; err != nil {
+		return nil, err
+	}
+	return callbacks, nil
+}
+
+// LeaseCallback takes a due callback for delivery by counting the attempt and hiding it from
+// other dispatch passes until leaseUntil. It reports false when another pass took it first.
+func (s *ApplicationStore) LeaseCallback(ctx context.Context, callback *CallbackRecord, leaseUntil time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&CallbackRecord{}).
+		Where("id = ? AND status = ? AND attempts = ?", callback.ID, CallbackPending, callback.Attempts).
+		Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	callback.Attempts++
+	callback.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+// MarkCallbackDelivered records the successful delivery of a callback.
+func (s *ApplicationStore) MarkCallbackDelivered(ctx context.Context, id uint, now time.Time) error {
+	return s.db.WithContext(ctx).Model(&CallbackRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       CallbackDelivered,
+			"last_error":   "",
+			"delivered_at": now,
+		}).Error
+}
+
+// MarkCallbackAttemptFailed records a failed delivery attempt. The callback is retried at
+// nextAttemptAt, or marked FAILED when giveUp is set.
+func (s *ApplicationStore) MarkCallbackAttemptFailed(ctx context.Context, id uint, lastError string, nextAttemptAt time.Time, giveUp bool) error {
+	updates := map[string]any{
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}
+	if giveUp {
+		updates["status"] = CallbackFailed
+	}
+	return s.db.WithContext(ctx).Model(&CallbackRecord{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ResendCallbacks queues every undelivered callback of an application for immediate
+// delivery with a fresh attempt budget. Callbacks keep their idempotency keys.
+func (s *ApplicationStore) ResendCallbacks(ctx context.Context, taskID string, now time.Time) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&CallbackRecord{}).Where("task_id = ?", taskID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrNoCallback
+		}
+		result := tx.Model(&CallbackRecord{}).
+			Where("task_id = ? AND status <> ?", taskID, CallbackDelivered).
+			Updates(map[string]any{
+				"status":          CallbackPending,
+				"attempts":        0,
+				"next_attempt_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCallbackDelivered
+		}
+		return nil
+	})
+}
+
+// CallbackConfig configures the delivery of callbacks.
+type CallbackConfig struct {
+	// MaxAttempts is the number of delivery attempts before a callback is marked FAILED.
+	MaxAttempts int
+	// RetryBase is the delay after the first failed attempt, doubling with every attempt.
+	RetryBase time.Duration
+	// PollInterval is how often the dispatcher looks for due callbacks when not notified.
+	PollInterval time.Duration
+}
+
+// CallbackDispatcher delivers the callbacks in the outbox, retrying failed deliveries with
+// exponential backoff.
+type CallbackDispatcher struct {
+	store      *ApplicationStore
+	httpClient *httpclient.Client
+	config     CallbackConfig
+	wake       chan struct{}
+	now        func() time.Time
+}
+
+// NewCallbackDispatcher creates a dispatcher delivering the callbacks in store with httpClient.
+func NewCallbackDispatcher(store *ApplicationStore, httpClient *httpclient.Client, config CallbackConfig) *CallbackDispatcher {
+	return &CallbackDispatcher{
+		store:      store,
+		httpClient: httpClient,
+		config:     config,
+		wake:       make(chan struct{}, 1),
+		now:        time.Now,
+	}
+}
+
+// Notify wakes the dispatcher to deliver newly queued callbacks without waiting for the next poll.
+func (d *CallbackDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers due callbacks until ctx is cancelled.
+func (d *CallbackDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		for d.Dispatch(ctx) == callbackBatchSize && ctx.Err() == nil {
+			// A full batch may have left more callbacks due.
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// Dispatch makes one delivery attempt for each due callback and returns the number attempted.
+func (d *CallbackDispatcher) Dispatch(ctx context.Context) int {
+	now := d.now()
+	callbacks, err := d.store.DueCallbacks(ctx, now, callbackBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list due callbacks", "error", err)
+		return 0
+	}
+
+	attempted := 0
+	for i := range callbacks {
+		callback := &callbacks[i]
+		leased, err := d.store.LeaseCallback(ctx, callback, now.Add(callbackLease))
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to lease callback", "callbackID", callback.ID, "error", err)
+			continue
+		}
+		if !leased {
+			continue
+		}
+		attempted++
+		d.attempt(ctx, callback)
+	}
+	return attempted
+}
+
+// attempt delivers a leased callback and records the outcome.
+func (d *CallbackDispatcher) attempt(ctx context.Context, callback *CallbackRecord) {
+	deliveryErr := d.deliver(ctx, callback)
+	now := d.now()
+	if deliveryErr == nil {
+		if err := d.store.MarkCallbackDelivered(ctx, callback.ID, now); err != nil {
+			slog.ErrorContext(ctx, "failed to record callback delivery", "callbackID", callback.ID, "error", err)
+			return
+		}
+		slog.InfoContext(ctx, "callback delivered", "callbackID", callback.ID, "taskID", callback.TaskID, "attempts", callback.Attempts)
+		return
+	}
+
+	giveUp := callback.Attempts >= d.config.MaxAttempts
+	nextAttemptAt := now.Add(d.backoff(callback.Attempts))
+	if err := d.store.MarkCallbackAttemptFailed(ctx, callback.ID, deliveryErr.Error(), nextAttemptAt, giveUp); err != nil {
+		slog.ErrorContext(ctx, "failed to record callback attempt", "callbackID", callback.ID, "error", err)
+		return
+	}
+	if giveUp {
+		slog.ErrorContext(ctx, "callback delivery failed, giving up", "callbackID", callback.ID, "taskID", callback.TaskID, "attempts", callback.Attempts, "error", deliveryErr)
+		return
+	}
+	slog.WarnContext(ctx, "callback delivery failed, will retry", "callbackID", callback.ID, "taskID", callback.TaskID, "attempts", callback.Attempts, "nextAttemptAt", nextAttemptAt, "error", deliveryErr)
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *CallbackDispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.RetryBase
+	for i := 1; i < attempts && delay < maxCallbackBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxCallbackBackoff)
+}
+
+func (d *CallbackDispatcher) deliver(ctx context.Context, callback *CallbackRecord) error {
+	req, err := d.httpClient.NewRequest(ctx, http.MethodPost, callback.ServiceURL, strings.NewReader(callback.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, callback.IdempotencyKey)
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to close response body", "error", err)
+		}
+	}(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
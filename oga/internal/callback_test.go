@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// setDispatcherClock makes the harness dispatcher see now as the current time.
+func (h *serviceHarness) setDispatcherClock(now time.Time) {
+	h.dispatcher.now = func() time.Time { return now }
+}
+
+func TestCallbackDispatcher_RetriesUntilDelivered(t *testing.T) {
+	h := newServiceHarness(t, nil, "")
+	h.seed("t-outbox", "alpha", nil)
+	ctx := context.Background()
+
+	// NSW being down no longer fails the review: the decision is recorded and its callback queued.
+	h.capture.respondWith(http.StatusServiceUnavailable)
+	if err := h.review("t-outbox", map[string]any{"review_outcome": "approve"}); err != nil {
+		t.Fatalf("ReviewApplication failed: %v", err)
+	}
+	if got := h.statusOf("t-outbox"); got != "DONE" {
+		t.Errorf("status: got %q, want DONE", got)
+	}
+
+	start := time.Now()
+	h.setDispatcherClock(start)
+	h.deliver()
+
+	app, err := h.service.GetApplication(ctx, "t-outbox")
+	if err != nil {
+		t.Fatalf("GetApplication failed: %v", err)
+	}
+	if app.Delivery == nil || app.Delivery.Status != CallbackPending || app.Delivery.Attempts != 1 || app.Delivery.LastError == "" {
+		t.Fatalf("expected a pending delivery after one failed attempt, got %+v", app.Delivery)
+	}
+	if app.Delivery.NextAttemptAt == nil || !app.Delivery.NextAttemptAt.Equal(start.Add(testCallbackConfig.RetryBase)) {
+		t.Errorf("expected the next attempt after %s, got %v", testCallbackConfig.RetryBase, app.Delivery.NextAttemptAt)
+	}
+
+	// Not due yet.
+	h.deliver()
+	if got := h.capture.callCount(); got != 1 {
+		t.Fatalf("expected no attempt before the backoff elapsed, got %d calls", got)
+	}
+
+	h.capture.respondWith(http.StatusOK)
+	h.setDispatcherClock(start.Add(testCallbackConfig.RetryBase))
+	h.deliver()
+
+	app, _ = h.service.GetApplication(ctx, "t-outbox")
+	if app.Delivery == nil || app.Delivery.Status != CallbackDelivered || app.Delivery.Attempts != 2 || app.Delivery.DeliveredAt == nil {
+		t.Fatalf("expected the callback to be delivered on the second attempt, got %+v", app.Delivery)
+	}
+	if len(h.capture.keys) != 2 || h.capture.keys[0] == "" || h.capture.keys[0] != h.capture.keys[1] {
+		t.Errorf("expected both attempts to carry the same idempotency key, got %q", h.capture.keys)
+	}
+
+	list, err := h.service.GetApplications(ctx, "", "", "", 1, 20)
+	if err != nil {
+		t.Fatalf("GetApplications failed: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Delivery == nil || list.Items[0].Delivery.Status != CallbackDelivered {
+		t.Errorf("expected the delivery status in the list view, got %+v", list.Items)
+	}
+}
+
+func TestCallbackDispatcher_GivesUpAndResends(t *testing.T) {
+	h := newServiceHarness(t, nil, "")
+	h.seed("t-failing", "alpha", nil)
+	ctx := context.Background()
+
+	if err := h.service.ResendCallbacks(ctx, "t-failing"); !errors.Is(err, ErrNoCallback) {
+		t.Fatalf("expected ErrNoCallback before any decision, got %v", err)
+	}
+
+	h.capture.respondWith(http.StatusInternalServerError)
+	if err := h.review("t-failing", map[string]any{"review_outcome": "approve"}); err != nil {
+		t.Fatalf("ReviewApplication failed: %v", err)
+	}
+	now := time.Now()
+	for range testCallbackConfig.MaxAttempts {
+		h.setDispatcherClock(now)
+		h.deliver()
+		now = now.Add(maxCallbackBackoff)
+	}
+
+	app, _ := h.service.GetApplication(ctx, "t-failing")
+	if app.Delivery == nil || app.Delivery.Status != CallbackFailed || app.Delivery.Attempts != testCallbackConfig.MaxAttempts {
+		t.Fatalf("expected the callback to fail after %d attempts, got %+v", testCallbackConfig.MaxAttempts, app.Delivery)
+	}
+	h.deliver()
+	if got := h.capture.callCount(); got != testCallbackConfig.MaxAttempts {
+		t.Fatalf("expected no attempts after giving up, got %d calls", got)
+	}
+
+	h.capture.respondWith(http.StatusOK)
+	if err := h.service.ResendCallbacks(ctx, "t-failing"); err != nil {
+		t.Fatalf("ResendCallbacks failed: %v", err)
+	}
+	h.setDispatcherClock(time.Now())
+	h.deliver()
+
+	app, _ = h.service.GetApplication(ctx, "t-failing")
+	if app.Delivery == nil || app.Delivery.Status != CallbackDelivered || app.Delivery.Attempts != 1 {
+		t.Fatalf("expected the resent callback to be delivered, got %+v", app.Delivery)
+	}
+	if err := h.service.ResendCallbacks(ctx, "t-failing"); !errors.Is(err, ErrCallbackDelivered) {
+		t.Errorf("expected ErrCallbackDelivered, got %v", err)
+	}
+}
+
+func TestApplicationStore_DueCallbacks_KeepsTaskOrder(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	queue := func(taskID string, due time.Time) *CallbackRecord {
+		t.Helper()
+		callback, err := newCallback("http://test", TaskResponse{TaskID: taskID}, due)
+		if err != nil {
+			t.Fatalf("newCallback failed: %v", err)
+		}
+		if err := store.db.Create(callback).Error; err != nil {
+			t.Fatalf("failed to queue callback: %v", err)
+		}
+		return callback
+	}
+	first := queue("task-a", now.Add(time.Minute)) // Backing off
+	queue("task-a", now)
+	other := queue("task-b", now)
+
+	due, err := store.DueCallbacks(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("DueCallbacks failed: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != other.ID {
+		t.Fatalf("expected only task-b's callback to be due while task-a's first is undelivered, got %+v", due)
+	}
+
+	leased, err := store.LeaseCallback(ctx, &due[0], now.Add(callbackLease))
+	if err != nil || !leased {
+		t.Fatalf("expected to lease the callback, got %v, %v", leased, err)
+	}
+	stale := *other
+	if leased, err := store.LeaseCallback(ctx, &stale, now.Add(callbackLease)); err != nil || leased {
+		t.Errorf("expected a second lease of the same attempt to lose, got %v, %v", leased, err)
+	}
+
+	if err := store.MarkCallbackDelivered(ctx, first.ID, now); err != nil {
+		t.Fatalf("MarkCallbackDelivered failed: %v", err)
+	}
+	due, err = store.DueCallbacks(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("DueCallbacks failed: %v", err)
+	}
+	if len(due) != 1 || due[0].TaskID != "task-a" {
+		t.Errorf("expected task-a's second callback once the first was delivered, got %+v", due)
+	}
+}
+
+func TestCallbackDispatcher_Backoff(t *testing.T) {
+	d := NewCallbackDispatcher(nil, nil, CallbackConfig{RetryBase: 5 * time.Second})
+	for attempts, want := range map[int]time.Duration{
+		1:  5 * time.Second,
+		2:  10 * time.Second,
+		4:  40 * time.Second,
+		30: maxCallbackBackoff,
+	} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d): got %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/OpenNSW/nsw/oga/internal/database"
 )
@@ -18,6 +19,17 @@ type NSWConfig struct {
 	TokenInsecureSkipVerify bool
 }
 
+// AuthConfig configures validation of the tokens presented to the OGA API.
+type AuthConfig struct {
+	JWKSURL string
+	Issuer  string
+	// PortalClientIDs are the client IDs of the OGA portal apps officers sign in through.
+	PortalClientIDs []string
+	// NSWClientIDs are the M2M client IDs the NSW backend injects applications with.
+	NSWClientIDs          []string
+	InsecureSkipTLSVerify bool
+}
+
 type Config struct {
 	Port                string
 	DB                  database.Config
@@ -25,7 +37,16 @@ type Config struct {
 	DefaultTaskConfigID string
 	AllowedOrigins      []string
 	NSW                 NSWConfig
+	Auth                AuthConfig
 	MaxRequestBytes     int64
+	// ClaimTTL is how long an officer's claim on an application lasts before another officer
+	// may claim it.
+	ClaimTTL time.Duration
+	// Callback configures the delivery of review decisions back to NSW.
+	Callback CallbackConfig
+	// ConfigWatchInterval is how often the task config and form files are checked for changes
+	// to reload. Zero disables watching; the admin API can still reload them.
+	ConfigWatchInterval time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
@@ -75,6 +96,12 @@ func LoadConfig() (Config, error) {
 			TokenURL:     os.Getenv("OGA_NSW_TOKEN_URL"),
 			Scopes:       parseCommaSeparated(os.Getenv("OGA_NSW_SCOPES")),
 		},
+		Auth: AuthConfig{
+			JWKSURL:         envOrDefault("OGA_AUTH_JWKS_URL", "https://localhost:8090/oauth2/jwks"),
+			Issuer:          envOrDefault("OGA_AUTH_ISSUER", "https://localhost:8090"),
+			PortalClientIDs: parseCommaSeparated(os.Getenv("OGA_AUTH_PORTAL_CLIENT_IDS")),
+			NSWClientIDs:    parseCommaSeparated(os.Getenv("OGA_AUTH_NSW_CLIENT_IDS")),
+		},
 	}
 
 	maxRequestBytes, err := parseInt64Env("OGA_MAX_REQUEST_BYTES", 32<<20)
@@ -83,15 +110,69 @@ func LoadConfig() (Config, error) {
 	}
 	cfg.MaxRequestBytes = maxRequestBytes
 
+	claimTTL, err := parseDurationEnv("OGA_CLAIM_TTL", 30*time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	if claimTTL <= 0 {
+		return Config{}, fmt.Errorf("OGA_CLAIM_TTL must be positive")
+	}
+	cfg.ClaimTTL = claimTTL
+
+	callbackMaxAttempts, err := parseInt64Env("OGA_CALLBACK_MAX_ATTEMPTS", 10)
+	if err != nil {
+		return Config{}, err
+	}
+	if callbackMaxAttempts < 1 {
+		return Config{}, fmt.Errorf("OGA_CALLBACK_MAX_ATTEMPTS must be at least 1")
+	}
+	cfg.Callback.MaxAttempts = int(callbackMaxAttempts)
+
+	callbackRetryBase, err := parseDurationEnv("OGA_CALLBACK_RETRY_BASE", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	if callbackRetryBase <= 0 {
+		return Config{}, fmt.Errorf("OGA_CALLBACK_RETRY_BASE must be positive")
+	}
+	cfg.Callback.RetryBase = callbackRetryBase
+
+	callbackPollInterval, err := parseDurationEnv("OGA_CALLBACK_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	if callbackPollInterval <= 0 {
+		return Config{}, fmt.Errorf("OGA_CALLBACK_POLL_INTERVAL must be positive")
+	}
+	cfg.Callback.PollInterval = callbackPollInterval
+
+	configWatchInterval, err := parseDurationEnv("OGA_CONFIG_WATCH_INTERVAL", 10*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	if configWatchInterval < 0 {
+		return Config{}, fmt.Errorf("OGA_CONFIG_WATCH_INTERVAL must not be negative")
+	}
+	cfg.ConfigWatchInterval = configWatchInterval
+
 	tokenInsecureSkipVerify, err := parseBoolEnv("OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY", false)
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.NSW.TokenInsecureSkipVerify = tokenInsecureSkipVerify
 
+	jwksInsecureSkipVerify, err := parseBoolEnv("OGA_AUTH_JWKS_INSECURE_SKIP_VERIFY", false)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Auth.InsecureSkipTLSVerify = jwksInsecureSkipVerify
+
 	if err := cfg.validateNSWOAuth2Config(); err != nil {
 		return Config{}, err
 	}
+	if err := cfg.validateAuthConfig(); err != nil {
+		return Config{}, err
+	}
 
 	return cfg, nil
 }
@@ -112,6 +193,16 @@ func (c Config) validateNSWOAuth2Config() error {
 	return nil
 }
 
+func (c Config) validateAuthConfig() error {
+	if len(c.Auth.PortalClientIDs) == 0 {
+		return fmt.Errorf("OGA_AUTH_PORTAL_CLIENT_IDS is required")
+	}
+	if len(c.Auth.NSWClientIDs) == 0 {
+		return fmt.Errorf("OGA_AUTH_NSW_CLIENT_IDS is required")
+	}
+	return nil
+}
+
 func envOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -158,3 +249,17 @@ func parseInt64Env(key string, defaultValue int64) (int64, error) {
 
 	return value, nil
 }
+
+func parseDurationEnv(key string, defaultValue time.Duration) (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return defaultValue, nil
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %q", key, raw)
+	}
+
+	return value, nil
+}
@@ -1,11 +1,16 @@
 package internal
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func setBaseConfigEnv(t *testing.T) {
 	t.Helper()
 	t.Setenv("OGA_DB_DRIVER", "sqlite")
 	t.Setenv("OGA_DB_PATH", "./test.db")
+	t.Setenv("OGA_AUTH_PORTAL_CLIENT_IDS", "OGA_PORTAL_APP_NPQS")
+	t.Setenv("OGA_AUTH_NSW_CLIENT_IDS", "NSW_TO_OGA")
 }
 
 func setRequiredNSWOAuth2Env(t *testing.T) {
@@ -107,3 +112,120 @@ func TestLoadConfig_RejectsInvalidTokenInsecureSkipVerify(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestLoadConfig_RequiresAuthClientIDs(t *testing.T) {
+	setBaseConfigEnv(t)
+	setRequiredNSWOAuth2Env(t)
+
+	for _, missing := range []string{"OGA_AUTH_PORTAL_CLIENT_IDS", "OGA_AUTH_NSW_CLIENT_IDS"} {
+		t.Run(missing, func(t *testing.T) {
+			t.Setenv(missing, " , ")
+			_, err := LoadConfig()
+			if err == nil || err.Error() != missing+" is required" {
+				t.Fatalf("expected %s to be required, got %v", missing, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_ParsesAuthConfig(t *testing.T) {
+	setBaseConfigEnv(t)
+	setRequiredNSWOAuth2Env(t)
+	t.Setenv("OGA_AUTH_ISSUER", "https://idp.example.gov")
+	t.Setenv("OGA_AUTH_NSW_CLIENT_IDS", "NSW_TO_OGA, NSW_TO_OGA_DR")
+	t.Setenv("OGA_AUTH_JWKS_INSECURE_SKIP_VERIFY", "true")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Auth.Issuer != "https://idp.example.gov" || cfg.Auth.JWKSURL != "https://localhost:8090/oauth2/jwks" {
+		t.Fatalf("unexpected issuer or jwks url: %+v", cfg.Auth)
+	}
+	if len(cfg.Auth.NSWClientIDs) != 2 || cfg.Auth.NSWClientIDs[1] != "NSW_TO_OGA_DR" {
+		t.Fatalf("unexpected NSW client ids: %v", cfg.Auth.NSWClientIDs)
+	}
+	if !cfg.Auth.InsecureSkipTLSVerify {
+		t.Fatalf("expected InsecureSkipTLSVerify to be true")
+	}
+}
+
+func TestLoadConfig_ParsesClaimTTL(t *testing.T) {
+	setBaseConfigEnv(t)
+	setRequiredNSWOAuth2Env(t)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.ClaimTTL != 30*time.Minute {
+		t.Fatalf("expected default ClaimTTL of 30m, got %s", cfg.ClaimTTL)
+	}
+
+	for _, invalid := range []string{"soon", "0s", "-5m"} {
+		t.Setenv("OGA_CLAIM_TTL", invalid)
+		if _, err := LoadConfig(); err == nil {
+			t.Errorf("expected OGA_CLAIM_TTL=%q to be rejected", invalid)
+		}
+	}
+}
+
+func TestLoadConfig_ParsesCallbackConfig(t *testing.T) {
+	setBaseConfigEnv(t)
+	setRequiredNSWOAuth2Env(t)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := CallbackConfig{MaxAttempts: 10, RetryBase: 5 * time.Second, PollInterval: 5 * time.Second}
+	if cfg.Callback != want {
+		t.Fatalf("expected default callback config %+v, got %+v", want, cfg.Callback)
+	}
+
+	t.Setenv("OGA_CALLBACK_MAX_ATTEMPTS", "3")
+	t.Setenv("OGA_CALLBACK_RETRY_BASE", "1m")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Callback.MaxAttempts != 3 || cfg.Callback.RetryBase != time.Minute {
+		t.Fatalf("unexpected callback config: %+v", cfg.Callback)
+	}
+
+	for key, invalid := range map[string]string{
+		"OGA_CALLBACK_MAX_ATTEMPTS":  "0",
+		"OGA_CALLBACK_RETRY_BASE":    "-1s",
+		"OGA_CALLBACK_POLL_INTERVAL": "often",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, invalid)
+			if _, err := LoadConfig(); err == nil {
+				t.Errorf("expected %s=%q to be rejected", key, invalid)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_ParsesConfigWatchInterval(t *testing.T) {
+	setBaseConfigEnv(t)
+	setRequiredNSWOAuth2Env(t)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.ConfigWatchInterval != 10*time.Second {
+		t.Fatalf("expected default ConfigWatchInterval of 10s, got %s", cfg.ConfigWatchInterval)
+	}
+
+	t.Setenv("OGA_CONFIG_WATCH_INTERVAL", "0")
+	if cfg, err = LoadConfig(); err != nil || cfg.ConfigWatchInterval != 0 {
+		t.Fatalf("expected OGA_CONFIG_WATCH_INTERVAL=0 to disable watching, got %s, %v", cfg.ConfigWatchInterval, err)
+	}
+
+	t.Setenv("OGA_CONFIG_WATCH_INTERVAL", "-1s")
+	if _, err := LoadConfig(); err == nil {
+		t.Errorf("expected a negative OGA_CONFIG_WATCH_INTERVAL to be rejected")
+	}
+}
@@ -0,0 +1,291 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidConfig is returned when reloading task configs or forms that fail validation. The
+// version in use stays current.
+var ErrInvalidConfig = errors.New("invalid task configuration")
+
+// ConfigVersionRecord is a saved version of the task configs and forms, kept so that
+// applications render with the forms they were created under after the files change.
+type ConfigVersionRecord struct {
+	Version     string                     `gorm:"type:varchar(64);primaryKey"` // Content hash of the files
+	TaskConfigs map[string]json.RawMessage `gorm:"type:text;serializer:json"`   // Task config files keyed by ID
+	Forms       map[string]json.RawMessage `gorm:"type:text;serializer:json"`   // Form files keyed by ID
+	CreatedAt   time.Time                  `gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for ConfigVersionRecord
+func (ConfigVersionRecord) TableName() string {
+	return "config_versions"
+}
+
+// SaveConfigVersion saves a version of the task config and form files. Saving a version that
+// already exists is a no-op.
+func (s *ApplicationStore) SaveConfigVersion(ctx context.Context, version string, taskConfigs, forms map[string][]byte) error {
+	record := ConfigVersionRecord{
+		Version:     version,
+		TaskConfigs: make(map[string]json.RawMessage, len(taskConfigs)),
+		Forms:       make(map[string]json.RawMessage, len(forms)),
+	}
+	for id, data := range taskConfigs {
+		record.TaskConfigs[id] = data
+	}
+	for id, data := range forms {
+		record.Forms[id] = data
+	}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error
+}
+
+// GetConfigVersion retrieves a saved version of the task config and form files.
+func (s *ApplicationStore) GetConfigVersion(ctx context.Context, version string) (*ConfigVersionRecord, error) {
+	var record ConfigVersionRecord
+	if err := s.db.WithContext(ctx).First(&record, "version = ?", version).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ConfigSnapshot is one version of the task configs and the forms they reference.
+type ConfigSnapshot struct {
+	Version  string
+	Tasks    *TaskConfigStore
+	Forms    *FormStore
+	LoadedAt time.Time
+}
+
+// ConfigVersionInfo describes a configuration version for the admin API.
+type ConfigVersionInfo struct {
+	Version     string    `json:"version"`
+	LoadedAt    time.Time `json:"loadedAt"`
+	TaskConfigs []string  `json:"taskConfigs"`
+	Forms       []string  `json:"forms"`
+}
+
+// Info describes the snapshot.
+func (cs *ConfigSnapshot) Info() ConfigVersionInfo {
+	return ConfigVersionInfo{
+		Version:     cs.Version,
+		LoadedAt:    cs.LoadedAt,
+		TaskConfigs: cs.Tasks.IDs(),
+		Forms:       cs.Forms.IDs(),
+	}
+}
+
+// newConfigSnapshot parses and validates task config and form file contents keyed by ID.
+// Task configs referencing a form that does not exist are only logged, as the application
+// is still shown without that form.
+func newConfigSnapshot(version string, taskConfigs, forms map[string][]byte, defaultConfigID string, loadedAt time.Time) (*ConfigSnapshot, error) {
+	tasks, err := parseTaskConfigs(taskConfigs, defaultConfigID)
+	if err != nil {
+		return nil, err
+	}
+	formStore, err := parseForms(forms)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range tasks.IDs() {
+		config := tasks.configs[id]
+		for _, formID := range []string{config.Forms.View, config.Forms.Review} {
+			if _, ok := formStore.GetForm(formID); formID != "" && !ok {
+				slog.Warn("task config references a missing form", "version", version, "taskConfig", id, "formID", formID)
+			}
+		}
+	}
+
+	return &ConfigSnapshot{Version: version, Tasks: tasks, Forms: formStore, LoadedAt: loadedAt}, nil
+}
+
+// configVersion derives a version from the content of the task config and form files, so
+// that unchanged files always map to the same version.
+func configVersion(taskConfigs, forms map[string][]byte) string {
+	hash := sha256.New()
+	for _, set := range []struct {
+		subdir string
+		files  map[string][]byte
+	}{{TaskConfigsSubdir, taskConfigs}, {FormsSubdir, forms}} {
+		for _, id := range slices.Sorted(maps.Keys(set.files)) {
+			fmt.Fprintf(hash, "%s/%s %d\n", set.subdir, id, len(set.files[id]))
+			hash.Write(set.files[id])
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// ConfigRegistry holds the current version of the task configs and forms in the config
+// directory, reloading it on demand, and the older versions applications are pinned to.
+type ConfigRegistry struct {
+	store           *ApplicationStore
+	configDir       string
+	defaultConfigID string
+	now             func() time.Time
+
+	reloadMu sync.Mutex // Serialises reloads
+	mu       sync.RWMutex
+	current  *ConfigSnapshot
+	versions map[string]*ConfigSnapshot
+}
+
+// NewConfigRegistry loads the task configs and forms under configDir and saves them as the
+// current version. Unlike a reload, invalid files fail startup.
+func NewConfigRegistry(ctx context.Context, store *ApplicationStore, configDir, defaultConfigID string) (*ConfigRegistry, error) {
+	r := &ConfigRegistry{
+		store:           store,
+		configDir:       configDir,
+		defaultConfigID: defaultConfigID,
+		now:             time.Now,
+		versions:        make(map[string]*ConfigSnapshot),
+	}
+	if _, _, err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Current returns the version new applications are pinned to.
+func (r *ConfigRegistry) Current() *ConfigSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// Snapshot returns the given version, loading it from the store if it is not in memory. It
+// falls back to the current version for an empty version, which applications created before
+// versioning have, or one that cannot be loaded.
+func (r *ConfigRegistry) Snapshot(ctx context.Context, version string) *ConfigSnapshot {
+	if version == "" {
+		return r.Current()
+	}
+	r.mu.RLock()
+	snapshot, ok := r.versions[version]
+	r.mu.RUnlock()
+	if ok {
+		return snapshot
+	}
+
+	record, err := r.store.GetConfigVersion(ctx, version)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load config version, using current", "version", version, "error", err)
+		return r.Current()
+	}
+	taskConfigs := make(map[string][]byte, len(record.TaskConfigs))
+	for id, data := range record.TaskConfigs {
+		taskConfigs[id] = data
+	}
+	forms := make(map[string][]byte, len(record.Forms))
+	for id, data := range record.Forms {
+		forms[id] = data
+	}
+	snapshot, err = newConfigSnapshot(version, taskConfigs, forms, r.defaultConfigID, record.CreatedAt)
+	if err != nil {
+		slog.WarnContext(ctx, "saved config version is invalid, using current", "version", version, "error", err)
+		return r.Current()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions[version] = snapshot
+	return snapshot
+}
+
+// Reload re-reads the config directory and, if the files changed, validates them, saves them
+// as a new version and makes it current. Invalid files are rejected with ErrInvalidConfig and
+// the current version stays in use. It reports whether the current version changed.
+func (r *ConfigRegistry) Reload(ctx context.Context) (*ConfigSnapshot, bool, error) {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	taskConfigs, forms, version, err := r.readFiles()
+	if err != nil {
+		return nil, false, err
+	}
+	return r.apply(ctx, taskConfigs, forms, version)
+}
+
+// Watch reloads the configuration whenever the files change, checking every interval, until
+// ctx is cancelled. A broken change is logged once and the current version stays in use
+// until the files are fixed.
+func (r *ConfigRegistry) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var rejected string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.reloadMu.Lock()
+		taskConfigs, forms, version, err := r.readFiles()
+		switch {
+		case err != nil:
+			if rejected != err.Error() {
+				slog.ErrorContext(ctx, "failed to read task configuration, keeping current version", "error", err)
+				rejected = err.Error()
+			}
+		case version == r.Current().Version, version == rejected:
+		default:
+			if _, _, err := r.apply(ctx, taskConfigs, forms, version); err != nil {
+				slog.ErrorContext(ctx, "rejected task configuration change, keeping current version", "version", version, "error", err)
+				rejected = version
+			} else {
+				rejected = ""
+			}
+		}
+		r.reloadMu.Unlock()
+	}
+}
+
+// readFiles reads the task config and form files and derives their version.
+func (r *ConfigRegistry) readFiles() (taskConfigs, forms map[string][]byte, version string, err error) {
+	taskConfigs, err = readJSONFiles(filepath.Join(r.configDir, TaskConfigsSubdir), "task config")
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	forms, err = readJSONFiles(filepath.Join(r.configDir, FormsSubdir), "form")
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return taskConfigs, forms, configVersion(taskConfigs, forms), nil
+}
+
+// apply validates and saves a version read from the config directory and makes it current.
+func (r *ConfigRegistry) apply(ctx context.Context, taskConfigs, forms map[string][]byte, version string) (*ConfigSnapshot, bool, error) {
+	if current := r.Current(); current != nil && current.Version == version {
+		return current, false, nil
+	}
+
+	snapshot, err := newConfigSnapshot(version, taskConfigs, forms, r.defaultConfigID, r.now())
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	if err := r.store.SaveConfigVersion(ctx, version, taskConfigs, forms); err != nil {
+		return nil, false, fmt.Errorf("failed to save config version: %w", err)
+	}
+
+	r.mu.Lock()
+	r.current = snapshot
+	r.versions[version] = snapshot
+	r.mu.Unlock()
+
+	slog.InfoContext(ctx, "task configuration version loaded", "version", version, "taskConfigs", len(taskConfigs), "forms", len(forms))
+	return snapshot, true, nil
+}
@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	reviewFormV1 = `{"schema": {"type": "object", "properties": {"review_outcome": {"enum": ["approve"]}}}}`
+	reviewFormV2 = `{"schema": {"type": "object", "properties": {"review_outcome": {"enum": ["accept"]}}}}`
+)
+
+// newVersionedHarness returns a harness whose labs task reviews with the labs-review form and
+// maps approve to APPROVED.
+func newVersionedHarness(t *testing.T) *serviceHarness {
+	t.Helper()
+	return newServiceHarness(t, func(root string) {
+		writeTaskConfigFile(t, root, "labs.json", `{
+			"meta": {"title": "Lab Testing"},
+			"forms": {"review": "labs-review"},
+			"behavior": {"statusMap": {"approve": "APPROVED"}}
+		}`)
+		writeFormFile(t, root, "labs-review.json", reviewFormV1)
+	}, "")
+}
+
+// inject creates an application through the service, pinning the current config version.
+func (h *serviceHarness) inject(taskID, taskCode string) {
+	h.t.Helper()
+	err := h.service.CreateApplication(context.Background(), &InjectRequest{
+		TaskID:     taskID,
+		TaskCode:   taskCode,
+		WorkflowID: "wf-test",
+		ServiceURL: h.callbackURL,
+		Data:       map[string]any{"field": "value"},
+	})
+	if err != nil {
+		h.t.Fatalf("CreateApplication(%s) failed: %v", taskID, err)
+	}
+}
+
+func reviewOutcomes(t *testing.T, form json.RawMessage) string {
+	t.Helper()
+	var parsed struct {
+		Schema struct {
+			Properties struct {
+				ReviewOutcome struct {
+					Enum []string `json:"enum"`
+				} `json:"review_outcome"`
+			} `json:"properties"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(form, &parsed); err != nil || len(parsed.Schema.Properties.ReviewOutcome.Enum) != 1 {
+		t.Fatalf("unexpected review form %s: %v", form, err)
+	}
+	return parsed.Schema.Properties.ReviewOutcome.Enum[0]
+}
+
+func TestConfigRegistry_PinsApplicationsToVersion(t *testing.T) {
+	h := newVersionedHarness(t)
+	ctx := context.Background()
+	h.inject("t-old", "labs")
+
+	// The agency renames the outcome and remaps the status.
+	writeTaskConfigFile(t, h.root, "labs.json", `{
+		"meta": {"title": "Lab Testing"},
+		"forms": {"review": "labs-review"},
+		"behavior": {"statusMap": {"accept": "ACCEPTED"}}
+	}`)
+	writeFormFile(t, h.root, "labs-review.json", reviewFormV2)
+	info, changed, err := h.service.ReloadConfig(ctx)
+	if err != nil || !changed {
+		t.Fatalf("expected the reload to change the version, got %v, %v", changed, err)
+	}
+	h.inject("t-new", "labs")
+
+	old, err := h.service.GetApplication(ctx, "t-old")
+	if err != nil {
+		t.Fatalf("GetApplication failed: %v", err)
+	}
+	current, err := h.service.GetApplication(ctx, "t-new")
+	if err != nil {
+		t.Fatalf("GetApplication failed: %v", err)
+	}
+	if old.ConfigVersion == "" || old.ConfigVersion == info.Version || current.ConfigVersion != info.Version {
+		t.Fatalf("expected t-old on the first version and t-new on %s, got %q and %q", info.Version, old.ConfigVersion, current.ConfigVersion)
+	}
+	if got := reviewOutcomes(t, old.OgaForm); got != "approve" {
+		t.Errorf("expected t-old to render the form it was created under, got outcome %q", got)
+	}
+	if got := reviewOutcomes(t, current.OgaForm); got != "accept" {
+		t.Errorf("expected t-new to render the new form, got outcome %q", got)
+	}
+
+	// The status map of the pinned version applies too.
+	if err := h.review("t-old", map[string]any{"review_outcome": "approve"}); err != nil {
+		t.Fatalf("ReviewApplication failed: %v", err)
+	}
+	if got := h.statusOf("t-old"); got != "APPROVED" {
+		t.Errorf("status: got %q, want APPROVED", got)
+	}
+
+	if _, changed, err := h.service.ReloadConfig(ctx); err != nil || changed {
+		t.Errorf("expected reloading unchanged files to keep the version, got %v, %v", changed, err)
+	}
+}
+
+func TestConfigRegistry_RejectsInvalidReload(t *testing.T) {
+	h := newVersionedHarness(t)
+	ctx := context.Background()
+	before := h.service.GetConfigVersion(ctx)
+
+	for name, content := range map[string]string{
+		"broken json":      `{"meta": {"title": "Lab Testing"`,
+		"unknown strategy": `{"meta": {"title": "Lab Testing"}, "assignment": {"strategy": "random"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			writeTaskConfigFile(t, h.root, "labs.json", content)
+			if _, _, err := h.service.ReloadConfig(ctx); !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("expected ErrInvalidConfig, got %v", err)
+			}
+			if after := h.service.GetConfigVersion(ctx); after.Version != before.Version {
+				t.Errorf("expected version %s to stay current, got %s", before.Version, after.Version)
+			}
+		})
+	}
+
+	writeFormFile(t, h.root, "labs-review.json", `{"schema": `)
+	if _, _, err := h.service.ReloadConfig(ctx); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a broken form, got %v", err)
+	}
+}
+
+func TestConfigRegistry_LoadsSavedVersions(t *testing.T) {
+	h := newVersionedHarness(t)
+	ctx := context.Background()
+	first := h.configs.Current().Version
+
+	writeFormFile(t, h.root, "labs-review.json", reviewFormV2)
+
+	// A restarted instance only has the new files on disk, yet still serves the first version.
+	restarted, err := NewConfigRegistry(ctx, h.store, h.root, "")
+	if err != nil {
+		t.Fatalf("NewConfigRegistry failed: %v", err)
+	}
+	if restarted.Current().Version == first {
+		t.Fatalf("expected the changed files to be a new version")
+	}
+	snapshot := restarted.Snapshot(ctx, first)
+	if snapshot.Version != first {
+		t.Fatalf("expected version %s to be loaded from the store, got %s", first, snapshot.Version)
+	}
+	form, ok := snapshot.Forms.GetForm("labs-review")
+	if !ok || reviewOutcomes(t, form) != "approve" {
+		t.Errorf("expected the first version's form, got %s", form)
+	}
+
+	if got := restarted.Snapshot(ctx, "unknown").Version; got != restarted.Current().Version {
+		t.Errorf("expected an unknown version to fall back to the current one, got %s", got)
+	}
+}
+
+func TestConfigRegistry_WatchSkipsBrokenChanges(t *testing.T) {
+	h := newVersionedHarness(t)
+	first := h.configs.Current().Version
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.configs.Watch(ctx, 10*time.Millisecond)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	writeFormFile(t, h.root, "labs-review.json", `{"schema": `)
+	time.Sleep(50 * time.Millisecond)
+	if got := h.configs.Current().Version; got != first {
+		t.Fatalf("expected the broken form to be skipped, got version %s", got)
+	}
+
+	writeFormFile(t, h.root, "labs-review.json", reviewFormV2)
+	deadline := time.Now().Add(2 * time.Second)
+	for h.configs.Current().Version == first {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the fixed form to be picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	form, _ := h.configs.Current().Forms.GetForm("labs-review")
+	if got := reviewOutcomes(t, form); got != "accept" {
+		t.Errorf("expected the fixed form, got outcome %q", got)
+	}
+}
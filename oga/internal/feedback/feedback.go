@@ -1,9 +1,16 @@
 package feedback
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type Entry struct {
 	Content   map[string]any `json:"content"`
 	Timestamp time.Time      `json:"timestamp"`
 	Round     int            `json:"round"`
 }
+
+// ErrClaimRequired is returned by Service when the officer sending feedback does not hold the
+// claim on the application.
+var ErrClaimRequired = errors.New("application must be claimed by the officer before acting on it")
@@ -3,14 +3,17 @@ package feedback
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+
+	"github.com/OpenNSW/nsw/oga/internal/auth"
 )
 
 // Service is a narrow interface for feedback operations, avoiding a circular
 // import with the parent internal package.
 type Service interface {
-	FeedbackApplication(ctx context.Context, taskID string, content map[string]any) error
+	FeedbackApplication(ctx context.Context, taskID string, officerID string, content map[string]any) error
 }
 
 type Handler struct {
@@ -28,6 +31,12 @@ func (h *Handler) HandleFeedback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	authCtx := auth.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.User == nil {
+		writeJSONError(w, http.StatusUnauthorized, "officer authentication required")
+		return
+	}
+
 	var body map[string]any
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		writeJSONError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
@@ -41,7 +50,11 @@ func (h *Handler) HandleFeedback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.FeedbackApplication(r.Context(), taskIDStr, body); err != nil {
+	if err := h.service.FeedbackApplication(r.Context(), taskIDStr, authCtx.User.IDPUserID, body); err != nil {
+		if errors.Is(err, ErrClaimRequired) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
 		writeJSONError(w, http.StatusInternalServerError, "failed to send feedback: "+err.Error())
 		return
 	}
@@ -4,9 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os"
+	"maps"
 	"path/filepath"
-	"strings"
+	"slices"
 )
 
 // FormsSubdir is the subdirectory under the config root where form files live.
@@ -20,28 +20,21 @@ type FormStore struct {
 
 // NewFormStore reads all .json files from <configDir>/forms into memory.
 func NewFormStore(configDir string) (*FormStore, error) {
-	dir := filepath.Join(configDir, FormsSubdir)
-	entries, err := os.ReadDir(dir)
+	files, err := readJSONFiles(filepath.Join(configDir, FormsSubdir), "form")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read forms directory %q: %w", dir, err)
+		return nil, err
 	}
+	return parseForms(files)
+}
 
+// parseForms builds a FormStore from form file contents keyed by ID.
+func parseForms(files map[string][]byte) (*FormStore, error) {
 	forms := make(map[string]json.RawMessage)
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
-		if err != nil {
-			return nil, fmt.Errorf("failed to read form file %q: %w", entry.Name(), err)
-		}
-		if !json.Valid(data) {
-			return nil, fmt.Errorf("form file %q contains invalid JSON", entry.Name())
+	for _, id := range slices.Sorted(maps.Keys(files)) {
+		if !json.Valid(files[id]) {
+			return nil, fmt.Errorf("form file %q contains invalid JSON", id+".json")
 		}
-
-		id := strings.TrimSuffix(entry.Name(), ".json")
-		forms[id] = data
+		forms[id] = files[id]
 		slog.Info("loaded form", "id", id)
 	}
 
@@ -54,3 +47,8 @@ func (fs *FormStore) GetForm(id string) (json.RawMessage, bool) {
 	form, ok := fs.forms[id]
 	return form, ok
 }
+
+// IDs returns the IDs of the loaded forms in order.
+func (fs *FormStore) IDs() []string {
+	return slices.Sorted(maps.Keys(fs.forms))
+}
@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+
+	"github.com/OpenNSW/nsw/oga/internal/auth"
 )
 
 // OGAHandler handles HTTP requests for OGA portal operations
@@ -36,6 +38,67 @@ func (h *OGAHandler) parseTaskID(w http.ResponseWriter, r *http.Request) (string
 	return taskIDStr, nil
 }
 
+// officerFromRequest returns the signed-in officer making the request, writing a 401 response
+// if there is none.
+func (h *OGAHandler) officerFromRequest(w http.ResponseWriter, r *http.Request) (Officer, bool) {
+	authCtx := auth.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.User == nil {
+		WriteJSONError(w, http.StatusUnauthorized, "officer authentication required")
+		return Officer{}, false
+	}
+	return Officer{
+		ID:         authCtx.User.IDPUserID,
+		Email:      authCtx.User.Email,
+		Supervisor: authCtx.User.HasAnyRole(auth.RoleSupervisor),
+	}, true
+}
+
+// parsePagination reads the optional page and pageSize query parameters, writing a 400
+// response if either is malformed.
+func parsePagination(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil && r.URL.Query().Get("page") != "" {
+		WriteJSONError(w, http.StatusBadRequest, "Invalid page number")
+		return 0, 0, false
+	}
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil && r.URL.Query().Get("pageSize") != "" {
+		WriteJSONError(w, http.StatusBadRequest, "Invalid page size")
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// writeClaimError maps the errors of claim, assignment, review and approval operations to
+// responses.
+func writeClaimError(w http.ResponseWriter, r *http.Request, action, taskID string, err error) {
+	switch {
+	case errors.Is(err, ErrApplicationNotFound):
+		WriteJSONError(w, http.StatusNotFound, "Application not found")
+	case errors.Is(err, ErrNoCallback):
+		WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrOfficerNotFound):
+		WriteJSONError(w, http.StatusBadRequest, "Officer not found")
+	case errors.Is(err, ErrInvalidDecision):
+		WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrSupervisorRequired):
+		WriteJSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrClaimRequired),
+		errors.Is(err, ErrClaimConflict),
+		errors.Is(err, ErrNotClaimable),
+		errors.Is(err, ErrAssignedToAnotherOfficer),
+		errors.Is(err, ErrNotAwaitingApproval),
+		errors.Is(err, ErrSelfApproval),
+		errors.Is(err, ErrCallbackDelivered):
+		WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "failed to "+action+" application",
+			"taskID", taskID,
+			"error", err)
+		WriteJSONError(w, http.StatusInternalServerError, "Failed to "+action+" application: "+err.Error())
+	}
+}
+
 // HandleInjectData handles POST /api/oga/inject
 // This is the endpoint that external services use to inject data into OGA portal
 func (h *OGAHandler) HandleInjectData(w http.ResponseWriter, r *http.Request) {
@@ -188,6 +251,10 @@ func (h *OGAHandler) HandleReviewApplication(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		return
 	}
+	officer, ok := h.officerFromRequest(w, r)
+	if !ok {
+		return
+	}
 
 	ctx := r.Context()
 
@@ -199,21 +266,15 @@ func (h *OGAHandler) HandleReviewApplication(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Process review and send response to service
-	if err := h.service.ReviewApplication(ctx, taskID, requestBody); err != nil {
-		if errors.Is(err, ErrApplicationNotFound) {
-			WriteJSONError(w, http.StatusNotFound, "Application not found")
-		} else {
-			slog.ErrorContext(ctx, "failed to review application",
-				"taskID", taskID,
-				"error", err)
-			WriteJSONError(w, http.StatusInternalServerError, "Failed to review application: "+err.Error())
-		}
+	// Record the review and queue the response to the service
+	if err := h.service.ReviewApplication(ctx, taskID, officer.ID, requestBody); err != nil {
+		writeClaimError(w, r, "review", taskID, err)
 		return
 	}
 
 	slog.InfoContext(ctx, "application reviewed",
 		"taskID", taskID,
+		"officerID", officer.ID,
 	)
 
 	WriteJSONResponse(w, http.StatusOK, map[string]any{
@@ -221,3 +282,247 @@ func (h *OGAHandler) HandleReviewApplication(w http.ResponseWriter, r *http.Requ
 		"message": "Application reviewed successfully",
 	})
 }
+
+// HandleDecideApproval handles POST /api/oga/applications/{taskId}/approval
+// Called when a supervisor approves, returns or overrides a review awaiting approval
+func (h *OGAHandler) HandleDecideApproval(w http.ResponseWriter, r *http.Request) {
+	taskID, err := h.parseTaskID(w, r)
+	if err != nil {
+		return
+	}
+	officer, ok := h.officerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req ApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.DecideApproval(r.Context(), taskID, officer, req); err != nil {
+		writeClaimError(w, r, req.Action, taskID, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "approval decided",
+		"taskID", taskID,
+		"officerID", officer.ID,
+		"action", req.Action,
+	)
+
+	WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"success": true,
+		"message": "Approval decision recorded",
+	})
+}
+
+// HandleClaimApplication handles POST /api/oga/applications/{taskId}/claim
+// Locks the application for review by the calling officer until the claim expires
+func (h *OGAHandler) HandleClaimApplication(w http.ResponseWriter, r *http.Request) {
+	taskID, err := h.parseTaskID(w, r)
+	if err != nil {
+		return
+	}
+	officer, ok := h.officerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	application, err := h.service.ClaimApplication(r.Context(), taskID, officer)
+	if err != nil {
+		writeClaimError(w, r, "claim", taskID, err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, application)
+}
+
+// HandleReleaseClaim handles DELETE /api/oga/applications/{taskId}/claim
+// Releases the calling officer's claim; supervisors may release anyone's
+func (h *OGAHandler) HandleReleaseClaim(w http.ResponseWriter, r *http.Request) {
+	taskID, err := h.parseTaskID(w, r)
+	if err != nil {
+		return
+	}
+	officer, ok := h.officerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReleaseClaim(r.Context(), taskID, officer); err != nil {
+		writeClaimError(w, r, "release", taskID, err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"success": true,
+		"message": "Claim released",
+	})
+}
+
+// HandleAssignApplication handles PUT /api/oga/applications/{taskId}/assignment
+// Assigns the application to an officer, or unassigns it when officerId is empty
+func (h *OGAHandler) HandleAssignApplication(w http.ResponseWriter, r *http.Request) {
+	taskID, err := h.parseTaskID(w, r)
+	if err != nil {
+		return
+	}
+
+	var req struct {
+		OfficerID string `json:"officerId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := h.service.AssignApplication(r.Context(), taskID, req.OfficerID); err != nil {
+		writeClaimError(w, r, "assign", taskID, err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"success": true,
+		"message": "Assignment updated",
+	})
+}
+
+// HandleGetConfigVersion handles GET /api/oga/config
+// Returns the task configuration version new applications are pinned to
+func (h *OGAHandler) HandleGetConfigVersion(w http.ResponseWriter, r *http.Request) {
+	WriteJSONResponse(w, http.StatusOK, h.service.GetConfigVersion(r.Context()))
+}
+
+// HandleReloadConfig handles POST /api/oga/config/reload
+// Re-reads the task configs and forms; invalid files are rejected and the current version stays in use
+func (h *OGAHandler) HandleReloadConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	info, changed, err := h.service.ReloadConfig(ctx)
+	if err != nil {
+		if errors.Is(err, ErrInvalidConfig) {
+			WriteJSONError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		slog.ErrorContext(ctx, "failed to reload task configuration", "error", err)
+		WriteJSONError(w, http.StatusInternalServerError, "Failed to reload task configuration")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, struct {
+		ConfigVersionInfo
+		Changed bool `json:"changed"`
+	}{info, changed})
+}
+
+// HandleResendCallbacks handles POST /api/oga/applications/{taskId}/callbacks/resend
+// Queues the application's undelivered callbacks to NSW for immediate redelivery
+func (h *OGAHandler) HandleResendCallbacks(w http.ResponseWriter, r *http.Request) {
+	taskID, err := h.parseTaskID(w, r)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.ResendCallbacks(r.Context(), taskID); err != nil {
+		writeClaimError(w, r, "resend callbacks for", taskID, err)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusAccepted, map[string]any{
+		"success": true,
+		"message": "Callbacks queued for redelivery",
+	})
+}
+
+// HandleGetMyQueue handles GET /api/oga/queue/mine
+// Returns the applications assigned to or claimed by the calling officer, PENDING ones unless a
+// status query parameter is given
+func (h *OGAHandler) HandleGetMyQueue(w http.ResponseWriter, r *http.Request) {
+	officer, ok := h.officerFromRequest(w, r)
+	if !ok {
+		return
+	}
+	page, pageSize, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	result, err := h.service.GetMyQueue(ctx, officer, queueStatus(r), page, pageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get officer queue", "officerID", officer.ID, "error", err)
+		WriteJSONError(w, http.StatusInternalServerError, "Failed to get queue")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// HandleGetUnassignedQueue handles GET /api/oga/queue/unassigned
+// Returns the applications not assigned to any officer, PENDING ones unless a status query
+// parameter is given
+func (h *OGAHandler) HandleGetUnassignedQueue(w http.ResponseWriter, r *http.Request) {
+	page, pageSize, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	result, err := h.service.GetUnassignedQueue(ctx, queueStatus(r), page, pageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get unassigned queue", "error", err)
+		WriteJSONError(w, http.StatusInternalServerError, "Failed to get queue")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, result)
+}
+
+// queueStatus returns the status filter of a queue request, defaulting to PENDING.
+func queueStatus(r *http.Request) string {
+	if status := r.URL.Query().Get("status"); status != "" {
+		return status
+	}
+	return "PENDING"
+}
+
+// HandleGetOfficers handles GET /api/oga/officers
+// Returns the assignment roster
+func (h *OGAHandler) HandleGetOfficers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	officers, err := h.service.GetOfficers(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get officers", "error", err)
+		WriteJSONError(w, http.StatusInternalServerError, "Failed to get officers")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, map[string]any{"items": officers})
+}
+
+// HandleUpdateOfficer handles PUT /api/oga/officers/{officerId}
+// Adds the officer to the roster or updates their skills and availability
+func (h *OGAHandler) HandleUpdateOfficer(w http.ResponseWriter, r *http.Request) {
+	officerID := r.PathValue("officerId")
+	if officerID == "" {
+		WriteJSONError(w, http.StatusBadRequest, "officerId is required")
+		return
+	}
+
+	var req OfficerUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	officer, err := h.service.UpdateOfficer(ctx, officerID, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update officer", "officerID", officerID, "error", err)
+		WriteJSONError(w, http.StatusInternalServerError, "Failed to update officer")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, officer)
+}
@@ -1,13 +1,27 @@
 package internal
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/OpenNSW/nsw/oga/internal/auth"
 )
 
 // mockOGAService is a mock implementation of OGAService for testing
 type mockOGAService struct {
 	// embed the interface so we don't have to implement everything
 	OGAService
+
+	reviewErr      error
+	reviewOfficers []string
+}
+
+func (m *mockOGAService) ReviewApplication(_ context.Context, _ string, officerID string, _ map[string]any) error {
+	m.reviewOfficers = append(m.reviewOfficers, officerID)
+	return m.reviewErr
 }
 
 func TestNewOGAHandler(t *testing.T) {
@@ -38,3 +52,43 @@ func TestNewOGAHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleReviewApplication_Claims(t *testing.T) {
+	officer := &auth.AuthContext{User: &auth.UserContext{IDPUserID: "officer-001", Roles: []string{auth.RoleReviewer}}}
+
+	tests := []struct {
+		name    string
+		authCtx *auth.AuthContext
+		err     error
+		want    int
+	}{
+		{"reviewed", officer, nil, http.StatusOK},
+		{"claim required", officer, ErrClaimRequired, http.StatusConflict},
+		{"not found", officer, ErrApplicationNotFound, http.StatusNotFound},
+		{"no officer", nil, nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockOGAService{reviewErr: tt.err}
+			handler, err := NewOGAHandler(service, 32<<20)
+			if err != nil {
+				t.Fatalf("NewOGAHandler failed: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/oga/applications/t-1/review", strings.NewReader(`{"review_outcome":"approve"}`))
+			req.SetPathValue("taskId", "t-1")
+			if tt.authCtx != nil {
+				req = req.WithContext(auth.WithAuthContext(req.Context(), tt.authCtx))
+			}
+			rec := httptest.NewRecorder()
+			handler.HandleReviewApplication(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+			if tt.authCtx != nil && (len(service.reviewOfficers) != 1 || service.reviewOfficers[0] != "officer-001") {
+				t.Errorf("expected review on behalf of officer-001, got %v", service.reviewOfficers)
+			}
+		})
+	}
+}
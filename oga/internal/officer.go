@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OfficerRecord is an officer on the assignment roster. Officers are added the first time
+// they use their queue or claim an application, and supervisors maintain their skills and
+// availability.
+type OfficerRecord struct {
+	ID             string     `gorm:"type:varchar(255);primaryKey"` // IdP subject
+	Email          string     `gorm:"type:varchar(255)"`
+	Skills         []string   `gorm:"type:text;serializer:json"` // Task codes and categories the officer handles
+	Active         bool       `gorm:"not null"`                  // Inactive officers receive no new assignments
+	LastAssignedAt *time.Time // When the officer was last auto-assigned an application
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for OfficerRecord
+func (OfficerRecord) TableName() string {
+	return "officers"
+}
+
+// EnsureOfficer adds the officer to the roster if they are not on it yet.
+func (s *ApplicationStore) EnsureOfficer(ctx context.Context, id, email string) error {
+	officer := OfficerRecord{ID: id, Email: email, Active: true}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&officer).Error
+}
+
+// GetOfficer retrieves an officer by ID
+func (s *ApplicationStore) GetOfficer(ctx context.Context, id string) (*OfficerRecord, error) {
+	var officer OfficerRecord
+	if err := s.db.WithContext(ctx).First(&officer, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &officer, nil
+}
+
+// ListOfficers returns the whole roster ordered by ID.
+func (s *ApplicationStore) ListOfficers(ctx context.Context) ([]OfficerRecord, error) {
+	var officers []OfficerRecord
+	if err := s.db.WithContext(ctx).Order("id").Find(&officers).Error; err != nil {
+		return nil, err
+	}
+	return officers, nil
+}
+
+// UpsertOfficer creates the officer or replaces their email, skills and availability.
+func (s *ApplicationStore) UpsertOfficer(ctx context.Context, officer *OfficerRecord) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "skills", "active", "updated_at"}),
+	}).Create(officer).Error
+}
+
+// NextAssignee picks the active officer who was auto-assigned least recently, restricted to
+// officers holding one of skills when skills is non-empty, and marks them as assigned at now.
+// It returns gorm.ErrRecordNotFound when no officer is eligible.
+func (s *ApplicationStore) NextAssignee(ctx context.Context, skills []string, now time.Time) (*OfficerRecord, error) {
+	var picked *OfficerRecord
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var officers []OfficerRecord
+		if err := tx.Where("active = ?", true).
+			Order("last_assigned_at IS NOT NULL, last_assigned_at, id").
+			Find(&officers).Error; err != nil {
+			return err
+		}
+
+		for i := range officers {
+			if len(skills) > 0 && !slices.ContainsFunc(skills, func(skill string) bool {
+				return skill != "" && slices.Contains(officers[i].Skills, skill)
+			}) {
+				continue
+			}
+			picked = &officers[i]
+			break
+		}
+		if picked == nil {
+			return gorm.ErrRecordNotFound
+		}
+
+		picked.LastAssignedAt = &now
+		return tx.Model(&OfficerRecord{}).
+			Where("id = ?", picked.ID).
+			Update("last_assigned_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return picked, nil
+}
+
+// Officer is the signed-in officer acting on an application.
+type Officer struct {
+	ID    string
+	Email string
+	// Supervisor officers may claim applications assigned to others and break other officers'
+	// claims.
+	Supervisor bool
+}
+
+// OfficerProfile represents a roster entry for display in the UI
+type OfficerProfile struct {
+	ID             string     `json:"id"`
+	Email          string     `json:"email"`
+	Skills         []string   `json:"skills"`
+	Active         bool       `json:"active"`
+	LastAssignedAt *time.Time `json:"lastAssignedAt,omitempty"`
+}
+
+// OfficerUpdate is the body of a roster update by a supervisor.
+type OfficerUpdate struct {
+	Email  string   `json:"email"`
+	Skills []string `json:"skills"`
+	Active *bool    `json:"active"`
+}
+
+func toOfficerProfile(record OfficerRecord) OfficerProfile {
+	skills := record.Skills
+	if skills == nil {
+		skills = []string{}
+	}
+	return OfficerProfile{
+		ID:             record.ID,
+		Email:          record.Email,
+		Skills:         skills,
+		Active:         record.Active,
+		LastAssignedAt: record.LastAssignedAt,
+	}
+}
+
+// GetOfficers returns the assignment roster
+func (s *ogaService) GetOfficers(ctx context.Context) ([]OfficerProfile, error) {
+	records, err := s.store.ListOfficers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list officers: %w", err)
+	}
+
+	profiles := make([]OfficerProfile, len(records))
+	for i, record := range records {
+		profiles[i] = toOfficerProfile(record)
+	}
+	return profiles, nil
+}
+
+// UpdateOfficer adds an officer to the roster or updates their skills and availability
+func (s *ogaService) UpdateOfficer(ctx context.Context, officerID string, update OfficerUpdate) (*OfficerProfile, error) {
+	if officerID == "" {
+		return nil, fmt.Errorf("officerId is required")
+	}
+
+	record := &OfficerRecord{ID: officerID, Email: update.Email, Skills: update.Skills, Active: true}
+	if update.Active != nil {
+		record.Active = *update.Active
+	}
+	if err := s.store.UpsertOfficer(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to update officer: %w", err)
+	}
+
+	updated, err := s.store.GetOfficer(ctx, officerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load officer: %w", err)
+	}
+	profile := toOfficerProfile(*updated)
+	return &profile, nil
+}
+
+// autoAssign picks the officer for a new application according to the task's assignment
+// strategy. It returns "" when the task has no strategy or no officer is eligible, leaving the
+// application in the unassigned queue.
+func (s *ogaService) autoAssign(ctx context.Context, taskCode string) (string, error) {
+	config, err := s.configs.Current().Tasks.GetConfig(taskCode)
+	if err != nil || config.Assignment == nil {
+		return "", nil
+	}
+
+	var skills []string
+	if config.Assignment.Strategy == AssignBySkill {
+		skills = []string{taskCode, config.Meta.Category}
+	}
+
+	officer, err := s.store.NextAssignee(ctx, skills, s.now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to pick assignee: %w", err)
+	}
+	return officer.ID, nil
+}
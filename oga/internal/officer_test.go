@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestApplicationStore_EnsureOfficer_KeepsRosterChanges(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.EnsureOfficer(ctx, "alice", "alice@government.dev"); err != nil {
+		t.Fatalf("EnsureOfficer failed: %v", err)
+	}
+	if err := store.UpsertOfficer(ctx, &OfficerRecord{ID: "alice", Email: "alice@government.dev", Skills: []string{"labs"}, Active: false}); err != nil {
+		t.Fatalf("UpsertOfficer failed: %v", err)
+	}
+	// Signing in again must not reactivate the officer or reset their skills.
+	if err := store.EnsureOfficer(ctx, "alice", "alice@government.dev"); err != nil {
+		t.Fatalf("EnsureOfficer failed: %v", err)
+	}
+
+	officer, err := store.GetOfficer(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetOfficer failed: %v", err)
+	}
+	if officer.Active || len(officer.Skills) != 1 || officer.Skills[0] != "labs" {
+		t.Errorf("expected inactive officer with skill labs, got %+v", officer)
+	}
+}
+
+func TestApplicationStore_NextAssignee_RoundRobin(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for _, id := range []string{"carol", "alice", "bob"} {
+		if err := store.EnsureOfficer(ctx, id, id+"@government.dev"); err != nil {
+			t.Fatalf("EnsureOfficer failed: %v", err)
+		}
+	}
+	if err := store.UpsertOfficer(ctx, &OfficerRecord{ID: "bob", Active: false}); err != nil {
+		t.Fatalf("UpsertOfficer failed: %v", err)
+	}
+
+	now := time.Now()
+	var got []string
+	for i := range 4 {
+		officer, err := store.NextAssignee(ctx, nil, now.Add(time.Duration(i)*time.Second))
+		if err != nil {
+			t.Fatalf("NextAssignee failed: %v", err)
+		}
+		got = append(got, officer.ID)
+	}
+
+	want := []string{"alice", "carol", "alice", "carol"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected assignment order %v, got %v", want, got)
+		}
+	}
+}
+
+func TestApplicationStore_NextAssignee_Skills(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	officers := []OfficerRecord{
+		{ID: "alice", Skills: []string{"Plant Health"}, Active: true},
+		{ID: "bob", Skills: []string{"labs"}, Active: true},
+		{ID: "carol", Active: true},
+	}
+	for i := range officers {
+		if err := store.UpsertOfficer(ctx, &officers[i]); err != nil {
+			t.Fatalf("UpsertOfficer failed: %v", err)
+		}
+	}
+
+	officer, err := store.NextAssignee(ctx, []string{"labs", "Lab Testing"}, time.Now())
+	if err != nil {
+		t.Fatalf("NextAssignee failed: %v", err)
+	}
+	if officer.ID != "bob" {
+		t.Errorf("expected bob to be picked by task code, got %s", officer.ID)
+	}
+
+	officer, err = store.NextAssignee(ctx, []string{"phyto", "Plant Health"}, time.Now())
+	if err != nil {
+		t.Fatalf("NextAssignee failed: %v", err)
+	}
+	if officer.ID != "alice" {
+		t.Errorf("expected alice to be picked by category, got %s", officer.ID)
+	}
+
+	if _, err := store.NextAssignee(ctx, []string{"fisheries", ""}, time.Now()); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected gorm.ErrRecordNotFound without a skilled officer, got %v", err)
+	}
+}
@@ -5,18 +5,32 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"time"
 
 	"github.com/OpenNSW/nsw/oga/internal/feedback"
-	"github.com/OpenNSW/nsw/oga/pkg/httpclient"
 	"gorm.io/gorm"
 )
 
 // ErrApplicationNotFound is returned when an application is not found
 var ErrApplicationNotFound = errors.New("application not found")
 
+var (
+	// ErrClaimRequired is returned when an officer acts on an application without holding an
+	// unexpired claim on it.
+	ErrClaimRequired = feedback.ErrClaimRequired
+	// ErrClaimConflict is returned when another officer holds an unexpired claim on the application.
+	ErrClaimConflict = errors.New("application is claimed by another officer")
+	// ErrNotClaimable is returned when claiming an application that is not awaiting review.
+	ErrNotClaimable = errors.New("application is not awaiting review")
+	// ErrAssignedToAnotherOfficer is returned when a reviewer claims an application assigned to
+	// someone else.
+	ErrAssignedToAnotherOfficer = errors.New("application is assigned to another officer")
+	// ErrOfficerNotFound is returned when assigning an application to an officer who is not on
+	// the roster.
+	ErrOfficerNotFound = errors.New("officer not found")
+)
+
 // OGAService handles OGA portal operations
 type OGAService interface {
 	// CreateApplication creates a new application from injected data
@@ -31,12 +45,49 @@ type OGAService interface {
 	// GetApplication returns a specific application by task ID
 	GetApplication(ctx context.Context, taskID string) (*Application, error)
 
-	// ReviewApplication approves or rejects an application and sends response back to service
-	ReviewApplication(ctx context.Context, taskID string, reviewerData map[string]any) error
+	// ReviewApplication approves or rejects an application and queues the response to the service,
+	// or, for tasks that require approval, holds the decision for a supervisor to countersign.
+	// The officer must hold the claim on the application.
+	ReviewApplication(ctx context.Context, taskID string, officerID string, reviewerData map[string]any) error
+
+	// FeedbackApplication queues a change-request feedback to the trader via the NSW task API
+	// and updates the application status to FEEDBACK_REQUESTED. The officer must hold the claim
+	// on the application.
+	FeedbackApplication(ctx context.Context, taskID string, officerID string, content map[string]any) error
+
+	// DecideApproval approves, returns or overrides a review awaiting a supervisor's approval
+	DecideApproval(ctx context.Context, taskID string, officer Officer, req ApprovalRequest) error
+
+	// ClaimApplication locks an application for review by the officer for the claim TTL
+	ClaimApplication(ctx context.Context, taskID string, officer Officer) (*Application, error)
+
+	// ReleaseClaim gives up the officer's claim on an application (or anyone's, for supervisors)
+	ReleaseClaim(ctx context.Context, taskID string, officer Officer) error
+
+	// AssignApplication assigns an application to an officer, or unassigns it when officerID is empty
+	AssignApplication(ctx context.Context, taskID string, officerID string) error
 
-	// FeedbackApplication sends a change-request feedback to the trader via the NSW task API
-	// and updates the application status to FEEDBACK_REQUESTED.
-	FeedbackApplication(ctx context.Context, taskID string, content map[string]any) error
+	// GetMyQueue returns the applications assigned to or claimed by the officer
+	GetMyQueue(ctx context.Context, officer Officer, status string, page, pageSize int) (*PagedResponse[Application], error)
+
+	// GetUnassignedQueue returns the applications not assigned to any officer
+	GetUnassignedQueue(ctx context.Context, status string, page, pageSize int) (*PagedResponse[Application], error)
+
+	// ResendCallbacks queues the undelivered callbacks of an application for immediate redelivery
+	ResendCallbacks(ctx context.Context, taskID string) error
+
+	// GetConfigVersion describes the task configuration version new applications are pinned to
+	GetConfigVersion(ctx context.Context) ConfigVersionInfo
+
+	// ReloadConfig re-reads the task configs and forms, making them the current version if they
+	// changed and are valid, and reports whether the version changed
+	ReloadConfig(ctx context.Context) (ConfigVersionInfo, bool, error)
+
+	// GetOfficers returns the assignment roster
+	GetOfficers(ctx context.Context) ([]OfficerProfile, error)
+
+	// UpdateOfficer adds an officer to the roster or updates their skills and availability
+	UpdateOfficer(ctx context.Context, officerID string, update OfficerUpdate) (*OfficerProfile, error)
 
 	// Close closes the service and releases resources
 	Close() error
@@ -67,13 +118,23 @@ type Application struct {
 	Icon        string `json:"icon,omitempty"`
 	Category    string `json:"category,omitempty"`
 
-	DataForm        json.RawMessage  `json:"dataForm,omitempty"` // Schema for rendering the data in Read Only mode in the UI
-	OgaForm         json.RawMessage  `json:"ogaForm,omitempty"`  // Schema for rendering the OGA Action form in the UI
-	Status          string           `json:"status"`
-	FeedbackHistory []feedback.Entry `json:"feedbackHistory,omitempty"`
-	ReviewedAt      *time.Time       `json:"reviewedAt,omitempty"`
-	CreatedAt       time.Time        `json:"createdAt"`
-	UpdatedAt       time.Time        `json:"updatedAt"`
+	DataForm        json.RawMessage   `json:"dataForm,omitempty"` // Schema for rendering the data in Read Only mode in the UI
+	OgaForm         json.RawMessage   `json:"ogaForm,omitempty"`  // Schema for rendering the OGA Action form in the UI
+	Status          string            `json:"status"`
+	FeedbackHistory []feedback.Entry  `json:"feedbackHistory,omitempty"`
+	ReviewedAt      *time.Time        `json:"reviewedAt,omitempty"`
+	ReviewedBy      string            `json:"reviewedBy,omitempty"`
+	ApprovedBy      string            `json:"approvedBy,omitempty"`
+	ApprovedAt      *time.Time        `json:"approvedAt,omitempty"`
+	ReviewHistory   []ReviewDecision  `json:"reviewHistory,omitempty"`
+	AssignedTo      string            `json:"assignedTo,omitempty"`
+	AssignedAt      *time.Time        `json:"assignedAt,omitempty"`
+	ClaimedBy       string            `json:"claimedBy,omitempty"` // Set only while the claim is unexpired
+	ClaimExpiresAt  *time.Time        `json:"claimExpiresAt,omitempty"`
+	Delivery        *CallbackDelivery `json:"delivery,omitempty"`      // Delivery of the decisions sent back to the service
+	ConfigVersion   string            `json:"configVersion,omitempty"` // Version of the task config and forms the application is pinned to
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
 }
 
 // PagedResponse is a generic paginated response wrapper.
@@ -92,19 +153,23 @@ type TaskResponse struct {
 }
 
 type ogaService struct {
-	store       *ApplicationStore
-	configStore *TaskConfigStore
-	formStore   *FormStore
-	httpClient  *httpclient.Client
+	store      *ApplicationStore
+	configs    *ConfigRegistry
+	dispatcher *CallbackDispatcher
+	claimTTL   time.Duration
+	now        func() time.Time
 }
 
-// NewOGAService creates a new OGA service instance with database storage
-func NewOGAService(store *ApplicationStore, configStore *TaskConfigStore, formStore *FormStore, httpClient *httpclient.Client) OGAService {
+// NewOGAService creates a new OGA service instance with database storage.
+// Applications are pinned to the current version of configs, responses to services are
+// queued for dispatcher to deliver, and claims taken by officers lapse after claimTTL.
+func NewOGAService(store *ApplicationStore, configs *ConfigRegistry, dispatcher *CallbackDispatcher, claimTTL time.Duration) OGAService {
 	return &ogaService{
-		store:       store,
-		configStore: configStore,
-		formStore:   formStore,
-		httpClient:  httpClient,
+		store:      store,
+		configs:    configs,
+		dispatcher: dispatcher,
+		claimTTL:   claimTTL,
+		now:        time.Now,
 	}
 }
 
@@ -126,12 +191,32 @@ func (s *ogaService) CreateApplication(ctx context.Context, req *InjectRequest)
 	}
 
 	appRecord := &ApplicationRecord{
-		TaskID:     req.TaskID,
-		TaskCode:   req.TaskCode,
-		WorkflowID: req.WorkflowID,
-		ServiceURL: req.ServiceURL,
-		Data:       req.Data,
-		Status:     "PENDING",
+		TaskID:        req.TaskID,
+		TaskCode:      req.TaskCode,
+		WorkflowID:    req.WorkflowID,
+		ServiceURL:    req.ServiceURL,
+		Data:          req.Data,
+		Status:        "PENDING",
+		ConfigVersion: s.configs.Current().Version,
+	}
+
+	if existing != nil {
+		// A re-injected application stays with the officer handling it.
+		appRecord.AssignedTo = existing.AssignedTo
+		appRecord.AssignedAt = existing.AssignedAt
+		appRecord.ClaimedBy = existing.ClaimedBy
+		appRecord.ClaimExpiresAt = existing.ClaimExpiresAt
+	} else {
+		assignee, err := s.autoAssign(ctx, req.TaskCode)
+		if err != nil {
+			return err
+		}
+		if assignee != "" {
+			now := s.now()
+			appRecord.AssignedTo = &assignee
+			appRecord.AssignedAt = &now
+			slog.InfoContext(ctx, "application assigned", "taskID", req.TaskID, "officerID", assignee)
+		}
 	}
 
 	return s.store.CreateOrUpdate(appRecord)
@@ -152,6 +237,50 @@ func (s *ogaService) GetApplications(ctx context.Context, status string, workflo
 		return nil, err
 	}
 
+	return s.toPage(ctx, records, total, page, pageSize)
+}
+
+// GetMyQueue returns the applications assigned to or claimed by the officer, oldest first
+func (s *ogaService) GetMyQueue(ctx context.Context, officer Officer, status string, page, pageSize int) (*PagedResponse[Application], error) {
+	if err := s.store.EnsureOfficer(ctx, officer.ID, officer.Email); err != nil {
+		return nil, fmt.Errorf("failed to register officer: %w", err)
+	}
+	return s.getQueue(ctx, officer.ID, status, page, pageSize)
+}
+
+// GetUnassignedQueue returns the applications not assigned to any officer, oldest first
+func (s *ogaService) GetUnassignedQueue(ctx context.Context, status string, page, pageSize int) (*PagedResponse[Application], error) {
+	return s.getQueue(ctx, "", status, page, pageSize)
+}
+
+func (s *ogaService) getQueue(ctx context.Context, officerID string, status string, page, pageSize int) (*PagedResponse[Application], error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	records, total, err := s.store.ListQueue(ctx, officerID, status, s.now(), offset, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toPage(ctx, records, total, page, pageSize)
+}
+
+// toPage converts records into a page of list view applications.
+func (s *ogaService) toPage(ctx context.Context, records []ApplicationRecord, total int64, page, pageSize int) (*PagedResponse[Application], error) {
+	taskIDs := make([]string, len(records))
+	for i, record := range records {
+		taskIDs[i] = record.TaskID
+	}
+	callbacks, err := s.store.ListCallbacks(ctx, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list callbacks: %w", err)
+	}
+
 	applications := make([]Application, len(records))
 	for i, record := range records {
 		app := Application{
@@ -162,12 +291,16 @@ func (s *ogaService) GetApplications(ctx context.Context, status string, workflo
 			Data:       record.Data,
 			Status:     record.Status,
 			ReviewedAt: record.ReviewedAt,
+			Delivery:   deliveryOf(callbacks[record.TaskID]),
 			CreatedAt:  record.CreatedAt,
 			UpdatedAt:  record.UpdatedAt,
 		}
+		s.attachAssignment(&app, &record)
 
 		// Attach basic metadata for the list view
-		if config, err := s.configStore.GetConfig(record.TaskCode); err == nil {
+		snapshot := s.configs.Snapshot(ctx, record.ConfigVersion)
+		app.ConfigVersion = snapshot.Version
+		if config, err := snapshot.Tasks.GetConfig(record.TaskCode); err == nil {
 			app.Title = config.Meta.Title
 			app.Category = config.Meta.Category
 			app.Icon = config.Meta.Icon
@@ -184,6 +317,26 @@ func (s *ogaService) GetApplications(ctx context.Context, status string, workflo
 	}, nil
 }
 
+// attachAssignment copies the reviewer, approver, assignment and unexpired claim of record
+// onto app.
+func (s *ogaService) attachAssignment(app *Application, record *ApplicationRecord) {
+	if record.ReviewedBy != nil {
+		app.ReviewedBy = *record.ReviewedBy
+	}
+	if record.ApprovedBy != nil {
+		app.ApprovedBy = *record.ApprovedBy
+		app.ApprovedAt = record.ApprovedAt
+	}
+	if record.AssignedTo != nil {
+		app.AssignedTo = *record.AssignedTo
+		app.AssignedAt = record.AssignedAt
+	}
+	if record.ClaimedBy != nil && record.ClaimExpiresAt != nil && record.ClaimExpiresAt.After(s.now()) {
+		app.ClaimedBy = *record.ClaimedBy
+		app.ClaimExpiresAt = record.ClaimExpiresAt
+	}
+}
+
 // GetWorkflows returns a paginated list of unique workflows
 func (s *ogaService) GetWorkflows(ctx context.Context, search string, page, pageSize int) (*PagedResponse[WorkflowSummary], error) {
 	if page < 1 {
@@ -226,13 +379,23 @@ func (s *ogaService) GetApplication(ctx context.Context, taskID string) (*Applic
 		OgaActionData:   record.ReviewerResponse,
 		Status:          record.Status,
 		FeedbackHistory: record.OGAFeedbackHistory,
+		ReviewHistory:   record.ReviewHistory,
 		ReviewedAt:      record.ReviewedAt,
 		CreatedAt:       record.CreatedAt,
 		UpdatedAt:       record.UpdatedAt,
 	}
+	s.attachAssignment(app, record)
 
-	// Attach task configuration
-	config, err := s.configStore.GetConfig(record.TaskCode)
+	callbacks, err := s.store.ListCallbacks(ctx, []string{taskID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list callbacks: %w", err)
+	}
+	app.Delivery = deliveryOf(callbacks[taskID])
+
+	// Attach task configuration from the version the application is pinned to
+	snapshot := s.configs.Snapshot(ctx, record.ConfigVersion)
+	app.ConfigVersion = snapshot.Version
+	config, err := snapshot.Tasks.GetConfig(record.TaskCode)
 	if err != nil {
 		slog.WarnContext(ctx, "task config not found for application", "taskID", taskID, "taskCode", record.TaskCode)
 	} else {
@@ -242,14 +405,14 @@ func (s *ogaService) GetApplication(ctx context.Context, taskID string) (*Applic
 		app.Category = config.Meta.Category
 
 		if config.Forms.View != "" {
-			if form, ok := s.formStore.GetForm(config.Forms.View); ok {
+			if form, ok := snapshot.Forms.GetForm(config.Forms.View); ok {
 				app.DataForm = form
 			} else {
 				slog.WarnContext(ctx, "view form not found", "taskCode", record.TaskCode, "formID", config.Forms.View)
 			}
 		}
 		if config.Forms.Review != "" {
-			if form, ok := s.formStore.GetForm(config.Forms.Review); ok {
+			if form, ok := snapshot.Forms.GetForm(config.Forms.Review); ok {
 				app.OgaForm = form
 			} else {
 				slog.WarnContext(ctx, "review form not found", "taskCode", record.TaskCode, "formID", config.Forms.Review)
@@ -261,27 +424,56 @@ func (s *ogaService) GetApplication(ctx context.Context, taskID string) (*Applic
 }
 
 // ReviewApplication approves or rejects an application
-func (s *ogaService) ReviewApplication(ctx context.Context, taskID string, reviewerResponse map[string]any) error {
+func (s *ogaService) ReviewApplication(ctx context.Context, taskID string, officerID string, reviewerResponse map[string]any) error {
 	app, err := s.GetApplication(ctx, taskID)
 	if err != nil {
 		return err
 	}
+	if app.ClaimedBy != officerID {
+		return ErrClaimRequired
+	}
+
+	now := s.now()
+	decision := ReviewDecision{
+		OfficerID: officerID,
+		Action:    DecisionSubmit,
+		Response:  reviewerResponse,
+		Timestamp: now.UTC(),
+	}
+
+	if s.requiresApproval(ctx, app.ConfigVersion, app.TaskCode) {
+		// The decision is sent to the service once a supervisor approves it.
+		if err := s.store.RecordDecision(ctx, taskID, StatusPendingApproval, decision, nil, now); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "review awaiting approval", "taskID", taskID, "officerID", officerID)
+		return nil
+	}
 
-	response := TaskResponse{
+	callback, err := newCallback(app.ServiceURL, TaskResponse{
 		TaskID:     app.TaskID,
 		WorkflowID: app.WorkflowID,
 		Payload: map[string]any{
 			"action":  "OGA_VERIFICATION",
 			"content": reviewerResponse,
 		},
+	}, now)
+	if err != nil {
+		return err
 	}
 
-	if err := s.sendToService(ctx, app.ServiceURL, response); err != nil {
-		return fmt.Errorf("failed to send response to service: %w", err)
+	if err := s.store.RecordDecision(ctx, taskID, s.outcomeStatus(ctx, app.ConfigVersion, app.TaskCode, reviewerResponse), decision, callback, now); err != nil {
+		return err
 	}
+	s.dispatcher.Notify()
+	return nil
+}
 
+// outcomeStatus derives the application status for a review response from the status map of
+// the task config version, defaulting to DONE.
+func (s *ogaService) outcomeStatus(ctx context.Context, configVersion, taskCode string, reviewerResponse map[string]any) string {
 	status := "DONE"
-	if config, err := s.configStore.GetConfig(app.TaskCode); err == nil && config.Behavior != nil && config.Behavior.StatusMap != nil {
+	if config, err := s.configs.Snapshot(ctx, configVersion).Tasks.GetConfig(taskCode); err == nil && config.Behavior != nil && config.Behavior.StatusMap != nil {
 		outcomeField := config.Behavior.OutcomeField
 		if outcomeField == "" {
 			outcomeField = DefaultOutcomeField
@@ -292,16 +484,18 @@ func (s *ogaService) ReviewApplication(ctx context.Context, taskID string, revie
 			}
 		}
 	}
-
-	return s.store.UpdateStatus(taskID, status, reviewerResponse)
+	return status
 }
 
 // FeedbackApplication sends OGA feedback to the trader
-func (s *ogaService) FeedbackApplication(ctx context.Context, taskID string, content map[string]any) error {
+func (s *ogaService) FeedbackApplication(ctx context.Context, taskID string, officerID string, content map[string]any) error {
 	app, err := s.GetApplication(ctx, taskID)
 	if err != nil {
 		return err
 	}
+	if app.ClaimedBy != officerID {
+		return ErrClaimRequired
+	}
 
 	entry := feedback.Entry{
 		Content:   content,
@@ -309,44 +503,107 @@ func (s *ogaService) FeedbackApplication(ctx context.Context, taskID string, con
 		Round:     len(app.FeedbackHistory) + 1,
 	}
 
-	response := TaskResponse{
+	callback, err := newCallback(app.ServiceURL, TaskResponse{
 		TaskID:     app.TaskID,
 		WorkflowID: app.WorkflowID,
 		Payload: map[string]any{
 			"action":  "OGA_VERIFICATION_FEEDBACK",
 			"content": content,
 		},
+	}, s.now())
+	if err != nil {
+		return err
 	}
 
-	if err := s.sendToService(ctx, app.ServiceURL, response); err != nil {
-		return fmt.Errorf("failed to send feedback to service: %w", err)
+	if err := s.store.AppendFeedback(taskID, entry, callback); err != nil {
+		return err
 	}
-
-	return s.store.AppendFeedback(taskID, entry)
+	s.dispatcher.Notify()
+	return nil
 }
 
-func (s *ogaService) sendToService(ctx context.Context, serviceURL string, response TaskResponse) error {
-	jsonData, err := json.Marshal(response)
+// ClaimApplication locks an application for review by the officer
+func (s *ogaService) ClaimApplication(ctx context.Context, taskID string, officer Officer) (*Application, error) {
+	if err := s.store.EnsureOfficer(ctx, officer.ID, officer.Email); err != nil {
+		return nil, fmt.Errorf("failed to register officer: %w", err)
+	}
+	record, err := s.store.GetByTaskID(taskID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal response: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, fmt.Errorf("failed to get application: %w", err)
 	}
 
-	resp, err := s.httpClient.Post(serviceURL, "application/json", jsonData)
-	if err != nil {
+	// Reviews awaiting approval are claimed by the supervisor who countersigns them.
+	claimable := []string{"PENDING"}
+	if record.Status == StatusPendingApproval {
+		if !officer.Supervisor {
+			return nil, ErrSupervisorRequired
+		}
+		if record.ReviewedBy != nil && *record.ReviewedBy == officer.ID {
+			return nil, ErrSelfApproval
+		}
+		claimable = []string{StatusPendingApproval}
+	}
+
+	if _, err := s.store.Claim(ctx, taskID, officer.ID, claimable, officer.Supervisor, s.now(), s.claimTTL); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "application claimed", "taskID", taskID, "officerID", officer.ID)
+	return s.GetApplication(ctx, taskID)
+}
+
+// ReleaseClaim gives up the officer's claim on an application
+func (s *ogaService) ReleaseClaim(ctx context.Context, taskID string, officer Officer) error {
+	if err := s.store.ReleaseClaim(ctx, taskID, officer.ID, officer.Supervisor, s.now()); err != nil {
 		return err
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			slog.ErrorContext(ctx, "failed to close response body", "error", err)
+	slog.InfoContext(ctx, "application claim released", "taskID", taskID, "officerID", officer.ID)
+	return nil
+}
+
+// AssignApplication assigns an application to an officer on the roster
+func (s *ogaService) AssignApplication(ctx context.Context, taskID string, officerID string) error {
+	if officerID != "" {
+		if _, err := s.store.GetOfficer(ctx, officerID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOfficerNotFound
+			}
+			return fmt.Errorf("failed to get officer: %w", err)
 		}
-	}(resp.Body)
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("service returned status %d", resp.StatusCode)
 	}
+	if err := s.store.Assign(ctx, taskID, officerID, s.now()); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "application assignment changed", "taskID", taskID, "officerID", officerID)
+	return nil
+}
+
+// ResendCallbacks queues the undelivered callbacks of an application for immediate redelivery
+func (s *ogaService) ResendCallbacks(ctx context.Context, taskID string) error {
+	if err := s.store.ResendCallbacks(ctx, taskID, s.now()); err != nil {
+		return err
+	}
+	s.dispatcher.Notify()
+	slog.InfoContext(ctx, "application callbacks queued for redelivery", "taskID", taskID)
 	return nil
 }
 
+// GetConfigVersion describes the current task configuration version
+func (s *ogaService) GetConfigVersion(_ context.Context) ConfigVersionInfo {
+	return s.configs.Current().Info()
+}
+
+// ReloadConfig re-reads the task configs and forms from the config directory
+func (s *ogaService) ReloadConfig(ctx context.Context) (ConfigVersionInfo, bool, error) {
+	snapshot, changed, err := s.configs.Reload(ctx)
+	if err != nil {
+		return ConfigVersionInfo{}, false, err
+	}
+	return snapshot.Info(), changed, nil
+}
+
 func (s *ogaService) Close() error {
 	if s.store != nil {
 		return s.store.Close()
@@ -3,6 +3,8 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -10,22 +12,50 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/OpenNSW/nsw/oga/pkg/httpclient"
 )
 
 // ---------- service test harness ----------
 
-// callbackCapture records the body of POSTs made to the test callback server.
+const testClaimTTL = 30 * time.Minute
+
+var testCallbackConfig = CallbackConfig{MaxAttempts: 3, RetryBase: time.Second, PollInterval: time.Second}
+
+var testOfficer = Officer{ID: "officer-001", Email: "npqs_user@government.dev"}
+
+// callbackCapture records the body and idempotency key of POSTs made to the test callback
+// server, and sets the status it responds with.
 type callbackCapture struct {
-	mu    sync.Mutex
-	calls [][]byte
+	mu     sync.Mutex
+	calls  [][]byte
+	keys   []string
+	status int
 }
 
-func (c *callbackCapture) record(body []byte) {
+func (c *callbackCapture) record(body []byte, key string) int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.calls = append(c.calls, body)
+	c.keys = append(c.keys, key)
+	if c.status != 0 {
+		return c.status
+	}
+	return http.StatusOK
+}
+
+// respondWith makes the callback server answer subsequent calls with status.
+func (c *callbackCapture) respondWith(status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = status
+}
+
+func (c *callbackCapture) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
 }
 
 func (c *callbackCapture) lastCall() map[string]any {
@@ -39,15 +69,14 @@ func (c *callbackCapture) lastCall() map[string]any {
 	return got
 }
 
-// newCallbackServer returns an httptest server that responds 200 OK to any POST
-// and captures the request body for assertions.
+// newCallbackServer returns an httptest server that responds 200 OK to any POST, unless told
+// otherwise, and captures the request body for assertions.
 func newCallbackServer(t *testing.T) (*httptest.Server, *callbackCapture) {
 	t.Helper()
 	capture := &callbackCapture{}
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, _ := io.ReadAll(r.Body)
-		capture.record(body)
-		w.WriteHeader(http.StatusOK)
+		w.WriteHeader(capture.record(body, r.Header.Get(IdempotencyKeyHeader)))
 	}))
 	t.Cleanup(srv.Close)
 	return srv, capture
@@ -58,9 +87,10 @@ func newCallbackServer(t *testing.T) (*httptest.Server, *callbackCapture) {
 type serviceHarness struct {
 	t           *testing.T
 	store       *ApplicationStore
-	configStore *TaskConfigStore
-	formStore   *FormStore
+	root        string
+	configs     *ConfigRegistry
 	httpClient  *httpclient.Client
+	dispatcher  *CallbackDispatcher
 	callbackURL string
 	capture     *callbackCapture
 	service     OGAService
@@ -86,28 +116,25 @@ func newServiceHarness(t *testing.T, writeFn func(root string), defaultConfigID
 
 	store := newTestStore(t)
 
-	configStore, err := NewTaskConfigStore(root, defaultConfigID)
-	if err != nil {
-		t.Fatalf("NewTaskConfigStore failed: %v", err)
-	}
-
-	formStore, err := NewFormStore(root)
+	configs, err := NewConfigRegistry(context.Background(), store, root, defaultConfigID)
 	if err != nil {
-		t.Fatalf("NewFormStore failed: %v", err)
+		t.Fatalf("NewConfigRegistry failed: %v", err)
 	}
 
 	srv, capture := newCallbackServer(t)
 	hc := httpclient.NewClientBuilder().Build()
+	dispatcher := NewCallbackDispatcher(store, hc, testCallbackConfig)
 
-	svc := NewOGAService(store, configStore, formStore, hc)
+	svc := NewOGAService(store, configs, dispatcher, testClaimTTL)
 	t.Cleanup(func() { _ = svc.Close() })
 
 	return &serviceHarness{
 		t:           t,
 		store:       store,
-		configStore: configStore,
-		formStore:   formStore,
+		root:        root,
+		configs:     configs,
 		httpClient:  hc,
+		dispatcher:  dispatcher,
 		callbackURL: srv.URL,
 		capture:     capture,
 		service:     svc,
@@ -133,6 +160,21 @@ func (h *serviceHarness) seed(taskID, taskCode string, data JSONB) {
 	}
 }
 
+// review claims the application as testOfficer and submits the review.
+func (h *serviceHarness) review(taskID string, body map[string]any) error {
+	h.t.Helper()
+	if _, err := h.service.ClaimApplication(context.Background(), taskID, testOfficer); err != nil {
+		h.t.Fatalf("ClaimApplication(%s) failed: %v", taskID, err)
+	}
+	return h.service.ReviewApplication(context.Background(), taskID, testOfficer.ID, body)
+}
+
+// deliver runs one dispatch pass, delivering the callbacks queued so far.
+func (h *serviceHarness) deliver() {
+	h.t.Helper()
+	h.dispatcher.Dispatch(context.Background())
+}
+
 // statusOf reads the latest status of the record from the database.
 func (h *serviceHarness) statusOf(taskID string) string {
 	h.t.Helper()
@@ -173,7 +215,7 @@ func TestReviewApplication_StatusFromStatusMap(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.outcome, func(t *testing.T) {
-			err := h.service.ReviewApplication(context.Background(), tc.taskID, map[string]any{
+			err := h.review(tc.taskID, map[string]any{
 				"review_outcome": tc.outcome,
 			})
 			if err != nil {
@@ -195,7 +237,7 @@ func TestReviewApplication_DefaultsToDONE_OutcomeNotInMap(t *testing.T) {
 	}, "")
 	h.seed("t-unknown", "alpha", nil)
 
-	err := h.service.ReviewApplication(context.Background(), "t-unknown", map[string]any{
+	err := h.review("t-unknown", map[string]any{
 		"review_outcome": "totally_made_up",
 	})
 	if err != nil {
@@ -213,7 +255,7 @@ func TestReviewApplication_DefaultsToDONE_NoStatusMap(t *testing.T) {
 	}, "")
 	h.seed("t-no-map", "alpha", nil)
 
-	err := h.service.ReviewApplication(context.Background(), "t-no-map", map[string]any{
+	err := h.review("t-no-map", map[string]any{
 		"review_outcome": "approve",
 	})
 	if err != nil {
@@ -228,7 +270,7 @@ func TestReviewApplication_DefaultsToDONE_NoConfig(t *testing.T) {
 	h := newServiceHarness(t, nil, "")
 	h.seed("t-no-config", "no-such-task", nil)
 
-	err := h.service.ReviewApplication(context.Background(), "t-no-config", map[string]any{
+	err := h.review("t-no-config", map[string]any{
 		"review_outcome": "approve",
 	})
 	if err != nil {
@@ -254,7 +296,7 @@ func TestReviewApplication_OutcomeFieldOverride(t *testing.T) {
 
 	t.Run("custom field hit", func(t *testing.T) {
 		h.seed("t-pass", "labs", nil)
-		err := h.service.ReviewApplication(context.Background(), "t-pass", map[string]any{
+		err := h.review("t-pass", map[string]any{
 			"decision": "pass",
 		})
 		if err != nil {
@@ -269,7 +311,7 @@ func TestReviewApplication_OutcomeFieldOverride(t *testing.T) {
 		h.seed("t-defaultignored", "labs", nil)
 		// review_outcome is the default name but the config asked for "decision",
 		// so the default name should NOT be honored.
-		err := h.service.ReviewApplication(context.Background(), "t-defaultignored", map[string]any{
+		err := h.review("t-defaultignored", map[string]any{
 			"review_outcome": "pass",
 		})
 		if err != nil {
@@ -292,13 +334,14 @@ func TestReviewApplication_CallsServiceURL(t *testing.T) {
 	}, "")
 	h.seed("t-callback", "alpha", nil)
 
-	err := h.service.ReviewApplication(context.Background(), "t-callback", map[string]any{
+	err := h.review("t-callback", map[string]any{
 		"review_outcome": "approve",
 		"comment":        "lgtm",
 	})
 	if err != nil {
 		t.Fatalf("ReviewApplication failed: %v", err)
 	}
+	h.deliver()
 
 	body := h.capture.lastCall()
 	if body == nil {
@@ -430,3 +473,309 @@ func TestGetApplication_NotFound(t *testing.T) {
 		t.Errorf("expected ErrApplicationNotFound, got %v", err)
 	}
 }
+
+// ---------- Claims & assignment ----------
+
+func TestReviewApplication_RequiresClaim(t *testing.T) {
+	h := newServiceHarness(t, nil, "")
+	h.seed("t-unclaimed", "alpha", nil)
+	ctx := context.Background()
+
+	err := h.service.ReviewApplication(ctx, "t-unclaimed", testOfficer.ID, map[string]any{"review_outcome": "approve"})
+	if !errors.Is(err, ErrClaimRequired) {
+		t.Fatalf("expected ErrClaimRequired, got %v", err)
+	}
+
+	other := Officer{ID: "officer-002", Email: "other@government.dev", Supervisor: true}
+	if _, err := h.service.ClaimApplication(ctx, "t-unclaimed", other); err != nil {
+		t.Fatalf("ClaimApplication failed: %v", err)
+	}
+	err = h.service.ReviewApplication(ctx, "t-unclaimed", testOfficer.ID, map[string]any{"review_outcome": "approve"})
+	if !errors.Is(err, ErrClaimRequired) {
+		t.Fatalf("expected ErrClaimRequired while another officer holds the claim, got %v", err)
+	}
+	err = h.service.FeedbackApplication(ctx, "t-unclaimed", testOfficer.ID, map[string]any{"feedback": "fix it"})
+	if !errors.Is(err, ErrClaimRequired) {
+		t.Fatalf("expected ErrClaimRequired for feedback, got %v", err)
+	}
+
+	h.deliver()
+	if h.capture.lastCall() != nil {
+		t.Errorf("expected no callback without a claim")
+	}
+	if got := h.statusOf("t-unclaimed"); got != "PENDING" {
+		t.Errorf("status: got %q, want PENDING", got)
+	}
+}
+
+func TestClaimApplication_ConflictAndExpiry(t *testing.T) {
+	h := newServiceHarness(t, nil, "")
+	h.seed("t-contested", "alpha", nil)
+	ctx := context.Background()
+	svc := h.service.(*ogaService)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	app, err := h.service.ClaimApplication(ctx, "t-contested", testOfficer)
+	if err != nil {
+		t.Fatalf("ClaimApplication failed: %v", err)
+	}
+	if app.ClaimedBy != testOfficer.ID || app.AssignedTo != testOfficer.ID || app.ClaimExpiresAt == nil {
+		t.Errorf("expected claim and assignment for %s, got %+v", testOfficer.ID, app)
+	}
+
+	supervisor := Officer{ID: "officer-002", Supervisor: true}
+	if _, err := h.service.ClaimApplication(ctx, "t-contested", supervisor); !errors.Is(err, ErrClaimConflict) {
+		t.Fatalf("expected ErrClaimConflict, got %v", err)
+	}
+
+	// Once the claim lapses it is no longer reported and the supervisor can take over.
+	now = now.Add(testClaimTTL + time.Second)
+	app, err = h.service.GetApplication(ctx, "t-contested")
+	if err != nil {
+		t.Fatalf("GetApplication failed: %v", err)
+	}
+	if app.ClaimedBy != "" || app.ClaimExpiresAt != nil {
+		t.Errorf("expected lapsed claim to be hidden, got %q until %v", app.ClaimedBy, app.ClaimExpiresAt)
+	}
+	if _, err := h.service.ClaimApplication(ctx, "t-contested", supervisor); err != nil {
+		t.Fatalf("expected supervisor to claim after expiry, got %v", err)
+	}
+	err = h.service.ReviewApplication(ctx, "t-contested", testOfficer.ID, map[string]any{"review_outcome": "approve"})
+	if !errors.Is(err, ErrClaimRequired) {
+		t.Fatalf("expected ErrClaimRequired for the former holder, got %v", err)
+	}
+	if err := h.service.ReviewApplication(ctx, "t-contested", supervisor.ID, map[string]any{"review_outcome": "approve"}); err != nil {
+		t.Fatalf("ReviewApplication failed: %v", err)
+	}
+
+	app, _ = h.service.GetApplication(ctx, "t-contested")
+	if app.ReviewedBy != supervisor.ID {
+		t.Errorf("ReviewedBy: got %q, want %q", app.ReviewedBy, supervisor.ID)
+	}
+}
+
+func TestCreateApplication_AutoAssignment(t *testing.T) {
+	h := newServiceHarness(t, func(root string) {
+		writeTaskConfigFile(t, root, "alpha.json", `{"meta": {"title": "Alpha"}, "assignment": {"strategy": "round_robin"}}`)
+		writeTaskConfigFile(t, root, "labs.json", `{"meta": {"title": "Labs", "category": "Lab Testing"}, "assignment": {"strategy": "skill"}}`)
+		writeTaskConfigFile(t, root, "manual.json", `{"meta": {"title": "Manual"}}`)
+	}, "")
+	ctx := context.Background()
+	for _, officer := range []struct {
+		id     string
+		skills []string
+	}{{"alice", nil}, {"bob", []string{"Lab Testing"}}} {
+		if _, err := h.service.UpdateOfficer(ctx, officer.id, OfficerUpdate{Skills: officer.skills}); err != nil {
+			t.Fatalf("UpdateOfficer failed: %v", err)
+		}
+	}
+
+	inject := func(taskID, taskCode string) string {
+		t.Helper()
+		err := h.service.CreateApplication(ctx, &InjectRequest{
+			TaskID: taskID, TaskCode: taskCode, WorkflowID: "wf-assign", ServiceURL: h.callbackURL,
+		})
+		if err != nil {
+			t.Fatalf("CreateApplication(%s) failed: %v", taskID, err)
+		}
+		app, err := h.service.GetApplication(ctx, taskID)
+		if err != nil {
+			t.Fatalf("GetApplication(%s) failed: %v", taskID, err)
+		}
+		return app.AssignedTo
+	}
+
+	if got := []string{inject("t-rr-1", "alpha"), inject("t-rr-2", "alpha"), inject("t-rr-3", "alpha")}; fmt.Sprint(got) != "[alice bob alice]" {
+		t.Errorf("round robin assignment: got %v", got)
+	}
+	if got := inject("t-skill", "labs"); got != "bob" {
+		t.Errorf("skill assignment: got %q, want bob", got)
+	}
+	if got := inject("t-manual", "manual"); got != "" {
+		t.Errorf("expected no assignment without a strategy, got %q", got)
+	}
+	// Re-injecting an application keeps it with its officer.
+	if got := inject("t-rr-1", "alpha"); got != "alice" {
+		t.Errorf("re-injected assignment: got %q, want alice", got)
+	}
+
+	unassigned, err := h.service.GetUnassignedQueue(ctx, "PENDING", 1, 20)
+	if err != nil {
+		t.Fatalf("GetUnassignedQueue failed: %v", err)
+	}
+	if unassigned.Total != 1 || unassigned.Items[0].TaskID != "t-manual" {
+		t.Errorf("expected only t-manual to be unassigned, got %+v", unassigned.Items)
+	}
+	mine, err := h.service.GetMyQueue(ctx, Officer{ID: "bob"}, "PENDING", 1, 20)
+	if err != nil {
+		t.Fatalf("GetMyQueue failed: %v", err)
+	}
+	if mine.Total != 2 {
+		t.Errorf("expected 2 applications in bob's queue, got %d", mine.Total)
+	}
+}
+
+func TestClaimApplication_AssignedToAnotherOfficer(t *testing.T) {
+	h := newServiceHarness(t, nil, "")
+	h.seed("t-theirs", "alpha", nil)
+	ctx := context.Background()
+	if _, err := h.service.UpdateOfficer(ctx, "officer-002", OfficerUpdate{}); err != nil {
+		t.Fatalf("UpdateOfficer failed: %v", err)
+	}
+
+	if err := h.service.AssignApplication(ctx, "t-theirs", "no-such-officer"); !errors.Is(err, ErrOfficerNotFound) {
+		t.Fatalf("expected ErrOfficerNotFound, got %v", err)
+	}
+	if err := h.service.AssignApplication(ctx, "t-theirs", "officer-002"); err != nil {
+		t.Fatalf("AssignApplication failed: %v", err)
+	}
+
+	if _, err := h.service.ClaimApplication(ctx, "t-theirs", testOfficer); !errors.Is(err, ErrAssignedToAnotherOfficer) {
+		t.Fatalf("expected ErrAssignedToAnotherOfficer, got %v", err)
+	}
+	supervisor := testOfficer
+	supervisor.Supervisor = true
+	if _, err := h.service.ClaimApplication(ctx, "t-theirs", supervisor); err != nil {
+		t.Fatalf("expected a supervisor to claim another officer's application, got %v", err)
+	}
+}
+
+// ---------- Maker-checker approval ----------
+
+func newApprovalHarness(t *testing.T) *serviceHarness {
+	t.Helper()
+	h := newServiceHarness(t, func(root string) {
+		writeTaskConfigFile(t, root, "licence.json", `{
+			"meta": {"title": "Licence"},
+			"behavior": {
+				"requireApproval": true,
+				"statusMap": {"approve": "APPROVED", "reject": "REJECTED"}
+			}
+		}`)
+	}, "")
+	h.seed("t-licence", "licence", nil)
+	if err := h.review("t-licence", map[string]any{"review_outcome": "approve", "remarks": "ok"}); err != nil {
+		t.Fatalf("ReviewApplication failed: %v", err)
+	}
+	return h
+}
+
+var testSupervisor = Officer{ID: "supervisor-001", Email: "supervisor@government.dev", Supervisor: true}
+
+func TestReviewApplication_RequireApproval_HoldsDecision(t *testing.T) {
+	h := newApprovalHarness(t)
+
+	if got := h.statusOf("t-licence"); got != StatusPendingApproval {
+		t.Errorf("status: got %q, want %q", got, StatusPendingApproval)
+	}
+	h.deliver()
+	if h.capture.lastCall() != nil {
+		t.Errorf("expected no callback before approval")
+	}
+
+	// The reviewer cannot countersign their own review, even as a supervisor.
+	self := testOfficer
+	self.Supervisor = true
+	if _, err := h.service.ClaimApplication(context.Background(), "t-licence", self); !errors.Is(err, ErrSelfApproval) {
+		t.Errorf("expected ErrSelfApproval, got %v", err)
+	}
+	if _, err := h.service.ClaimApplication(context.Background(), "t-licence", Officer{ID: "reviewer-002"}); !errors.Is(err, ErrSupervisorRequired) {
+		t.Errorf("expected ErrSupervisorRequired, got %v", err)
+	}
+}
+
+func TestDecideApproval_Approve(t *testing.T) {
+	h := newApprovalHarness(t)
+	ctx := context.Background()
+
+	err := h.service.DecideApproval(ctx, "t-licence", testSupervisor, ApprovalRequest{Action: DecisionApprove})
+	if !errors.Is(err, ErrClaimRequired) {
+		t.Fatalf("expected ErrClaimRequired, got %v", err)
+	}
+	if _, err := h.service.ClaimApplication(ctx, "t-licence", testSupervisor); err != nil {
+		t.Fatalf("ClaimApplication failed: %v", err)
+	}
+	if err := h.service.DecideApproval(ctx, "t-licence", testSupervisor, ApprovalRequest{Action: DecisionApprove, Comment: "countersigned"}); err != nil {
+		t.Fatalf("DecideApproval failed: %v", err)
+	}
+
+	if got := h.statusOf("t-licence"); got != "APPROVED" {
+		t.Errorf("status: got %q, want APPROVED", got)
+	}
+	h.deliver()
+	payload, _ := h.capture.lastCall()["payload"].(map[string]any)
+	content, _ := payload["content"].(map[string]any)
+	if content["review_outcome"] != "approve" || content["remarks"] != "ok" {
+		t.Errorf("expected the reviewer's decision in the callback, got %v", payload)
+	}
+
+	app, _ := h.service.GetApplication(ctx, "t-licence")
+	if app.ReviewedBy != testOfficer.ID || app.ApprovedBy != testSupervisor.ID {
+		t.Errorf("expected reviewed by %s and approved by %s, got %q and %q", testOfficer.ID, testSupervisor.ID, app.ReviewedBy, app.ApprovedBy)
+	}
+	if len(app.ReviewHistory) != 2 || app.ReviewHistory[1].Comment != "countersigned" {
+		t.Errorf("expected both decisions in the history, got %+v", app.ReviewHistory)
+	}
+}
+
+func TestDecideApproval_Override(t *testing.T) {
+	h := newApprovalHarness(t)
+	ctx := context.Background()
+	if _, err := h.service.ClaimApplication(ctx, "t-licence", testSupervisor); err != nil {
+		t.Fatalf("ClaimApplication failed: %v", err)
+	}
+
+	err := h.service.DecideApproval(ctx, "t-licence", testSupervisor, ApprovalRequest{Action: DecisionOverride})
+	if !errors.Is(err, ErrInvalidDecision) {
+		t.Fatalf("expected ErrInvalidDecision for an override without a response, got %v", err)
+	}
+	err = h.service.DecideApproval(ctx, "t-licence", testSupervisor, ApprovalRequest{
+		Action:   DecisionOverride,
+		Response: map[string]any{"review_outcome": "reject", "remarks": "expired certificate"},
+	})
+	if err != nil {
+		t.Fatalf("DecideApproval failed: %v", err)
+	}
+
+	if got := h.statusOf("t-licence"); got != "REJECTED" {
+		t.Errorf("status: got %q, want REJECTED", got)
+	}
+	h.deliver()
+	payload, _ := h.capture.lastCall()["payload"].(map[string]any)
+	content, _ := payload["content"].(map[string]any)
+	if content["review_outcome"] != "reject" {
+		t.Errorf("expected the supervisor's decision in the callback, got %v", payload)
+	}
+}
+
+func TestDecideApproval_Return(t *testing.T) {
+	h := newApprovalHarness(t)
+	ctx := context.Background()
+	if _, err := h.service.ClaimApplication(ctx, "t-licence", testSupervisor); err != nil {
+		t.Fatalf("ClaimApplication failed: %v", err)
+	}
+	if err := h.service.DecideApproval(ctx, "t-licence", testSupervisor, ApprovalRequest{Action: DecisionReturn, Comment: "check the dates"}); err != nil {
+		t.Fatalf("DecideApproval failed: %v", err)
+	}
+
+	if got := h.statusOf("t-licence"); got != "PENDING" {
+		t.Errorf("status: got %q, want PENDING", got)
+	}
+	h.deliver()
+	if h.capture.lastCall() != nil {
+		t.Errorf("expected no callback when returning a review")
+	}
+	if err := h.service.DecideApproval(ctx, "t-licence", testSupervisor, ApprovalRequest{Action: DecisionApprove}); !errors.Is(err, ErrNotAwaitingApproval) {
+		t.Errorf("expected ErrNotAwaitingApproval, got %v", err)
+	}
+
+	// The reviewer decides again, and the application awaits approval once more.
+	if err := h.review("t-licence", map[string]any{"review_outcome": "reject"}); err != nil {
+		t.Fatalf("ReviewApplication failed: %v", err)
+	}
+	app, _ := h.service.GetApplication(ctx, "t-licence")
+	if app.Status != StatusPendingApproval || len(app.ReviewHistory) != 3 {
+		t.Errorf("expected PENDING_APPROVAL with three decisions, got %q with %+v", app.Status, app.ReviewHistory)
+	}
+}
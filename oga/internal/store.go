@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/OpenNSW/nsw/oga/internal/database"
@@ -54,8 +56,17 @@ type ApplicationRecord struct {
 	Status             string           `gorm:"type:varchar(50);not null;default:'PENDING'"` // PENDING, FEEDBACK_REQUESTED, DONE
 	OGAFeedbackHistory []feedback.Entry `gorm:"type:text;serializer:json"`
 	ReviewedAt         *time.Time       // When it was reviewed
-	CreatedAt          time.Time        `gorm:"autoCreateTime"`
-	UpdatedAt          time.Time        `gorm:"autoUpdateTime"`
+	ReviewedBy         *string          `gorm:"type:varchar(255)"` // Officer who reviewed it
+	ApprovedBy         *string          `gorm:"type:varchar(255)"` // Officer who countersigned the review
+	ApprovedAt         *time.Time
+	ReviewHistory      []ReviewDecision `gorm:"type:text;serializer:json"` // Every review and approval decision
+	AssignedTo         *string          `gorm:"type:varchar(255);index"`   // Officer responsible for the application
+	AssignedAt         *time.Time
+	ClaimedBy          *string    `gorm:"type:varchar(255);index"` // Officer currently working on the application
+	ClaimExpiresAt     *time.Time // When the claim lapses and others may claim it
+	ConfigVersion      string     `gorm:"type:varchar(64)"` // Version of the task config and forms the application was created under
+	CreatedAt          time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt          time.Time  `gorm:"autoUpdateTime"`
 }
 
 // TableName returns the table name for ApplicationRecord
@@ -81,7 +92,7 @@ func NewApplicationStore(cfg Config) (*ApplicationStore, error) {
 	}
 
 	// Auto-migrate the schema
-	if err := db.AutoMigrate(&ApplicationRecord{}); err != nil {
+	if err := db.AutoMigrate(&ApplicationRecord{}, &OfficerRecord{}, &CallbackRecord{}, &ConfigVersionRecord{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
@@ -203,9 +214,10 @@ func (s *ApplicationStore) UpdateStatus(taskID string, status string, reviewerRe
 	return nil
 }
 
-// AppendFeedback appends a feedback entry to the application's history and sets
-// the status to FEEDBACK_REQUESTED.
-func (s *ApplicationStore) AppendFeedback(taskID string, entry feedback.Entry) error {
+// AppendFeedback appends a feedback entry to the application's history, sets
+// the status to FEEDBACK_REQUESTED and releases any claim on it. A non-nil callback
+// is queued in the same transaction.
+func (s *ApplicationStore) AppendFeedback(taskID string, entry feedback.Entry, callback *CallbackRecord) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		var app ApplicationRecord
 		if err := tx.First(&app, "task_id = ?", taskID).Error; err != nil {
@@ -216,13 +228,21 @@ func (s *ApplicationStore) AppendFeedback(taskID string, entry feedback.Entry) e
 		if err != nil {
 			return fmt.Errorf("failed to marshal feedback history: %w", err)
 		}
-		return tx.Model(&ApplicationRecord{}).
+		if err := tx.Model(&ApplicationRecord{}).
 			Where("task_id = ?", taskID).
 			Updates(map[string]any{
 				"oga_feedback_history": string(updatedJSON),
 				"status":               "FEEDBACK_REQUESTED",
+				"claimed_by":           nil,
+				"claim_expires_at":     nil,
 				"updated_at":           time.Now(),
-			}).Error
+			}).Error; err != nil {
+			return err
+		}
+		if callback != nil {
+			return tx.Create(callback).Error
+		}
+		return nil
 	})
 }
 
@@ -242,6 +262,200 @@ func (s *ApplicationStore) UpdateDataAndResetStatus(taskID string, data map[stri
 		}).Error
 }
 
+// ListQueue retrieves the applications with the given status that are assigned to, or under
+// an unexpired claim by, officerID. With an empty officerID it retrieves the unassigned ones.
+func (s *ApplicationStore) ListQueue(ctx context.Context, officerID string, status string, now time.Time, offset, limit int) ([]ApplicationRecord, int64, error) {
+	var apps []ApplicationRecord
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&ApplicationRecord{})
+	if officerID == "" {
+		query = query.Where("assigned_to IS NULL")
+	} else {
+		query = query.Where("assigned_to = ? OR (claimed_by = ? AND claim_expires_at > ?)", officerID, officerID, now)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at ASC").Offset(offset).Limit(limit).Find(&apps).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return apps, total, nil
+}
+
+// Claim locks an application in one of the claimable statuses for officerID until now+ttl.
+// Claiming an application the officer already holds extends the claim, and claiming an
+// unassigned one also assigns it to the officer. Unless allowReassigned is set, applications
+// assigned to another officer cannot be claimed.
+func (s *ApplicationStore) Claim(ctx context.Context, taskID, officerID string, claimable []string, allowReassigned bool, now time.Time, ttl time.Duration) (*ApplicationRecord, error) {
+	var app ApplicationRecord
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&app, "task_id = ?", taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrApplicationNotFound
+			}
+			return err
+		}
+		if !slices.Contains(claimable, app.Status) {
+			return ErrNotClaimable
+		}
+		if app.AssignedTo != nil && *app.AssignedTo != officerID && !allowReassigned {
+			return ErrAssignedToAnotherOfficer
+		}
+
+		expiresAt := now.Add(ttl)
+		// The conditions are repeated in the update so that a concurrent claim cannot slip in
+		// between the read above and the write.
+		result := tx.Model(&ApplicationRecord{}).
+			Where("task_id = ? AND status IN ?", taskID, claimable).
+			Where("claimed_by IS NULL OR claimed_by = ? OR claim_expires_at <= ?", officerID, now).
+			Updates(map[string]any{
+				"claimed_by":       officerID,
+				"claim_expires_at": expiresAt,
+				"assigned_to":      gorm.Expr("COALESCE(assigned_to, ?)", officerID),
+				"assigned_at":      gorm.Expr("COALESCE(assigned_at, ?)", now),
+				"updated_at":       now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrClaimConflict
+		}
+		return tx.First(&app, "task_id = ?", taskID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// ReleaseClaim drops the claim on an application. Only the holder may release an unexpired
+// claim unless force is set; releasing an application nobody holds is a no-op.
+func (s *ApplicationStore) ReleaseClaim(ctx context.Context, taskID, officerID string, force bool, now time.Time) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var app ApplicationRecord
+		if err := tx.First(&app, "task_id = ?", taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrApplicationNotFound
+			}
+			return err
+		}
+		if app.ClaimedBy == nil {
+			return nil
+		}
+		if *app.ClaimedBy != officerID && !force && app.ClaimExpiresAt != nil && app.ClaimExpiresAt.After(now) {
+			return ErrClaimConflict
+		}
+		return tx.Model(&ApplicationRecord{}).
+			Where("task_id = ?", taskID).
+			Updates(map[string]any{
+				"claimed_by":       nil,
+				"claim_expires_at": nil,
+				"updated_at":       now,
+			}).Error
+	})
+}
+
+// Assign makes officerID responsible for an application, or returns it to the unassigned queue
+// when officerID is empty. A claim held by anyone other than the new assignee is dropped.
+func (s *ApplicationStore) Assign(ctx context.Context, taskID, officerID string, now time.Time) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var app ApplicationRecord
+		if err := tx.First(&app, "task_id = ?", taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrApplicationNotFound
+			}
+			return err
+		}
+
+		updates := map[string]any{
+			"assigned_to": nil,
+			"assigned_at": nil,
+			"updated_at":  now,
+		}
+		if officerID != "" {
+			updates["assigned_to"] = officerID
+			updates["assigned_at"] = now
+		}
+		if app.ClaimedBy != nil && (officerID == "" || *app.ClaimedBy != officerID) {
+			updates["claimed_by"] = nil
+			updates["claim_expires_at"] = nil
+		}
+		return tx.Model(&ApplicationRecord{}).Where("task_id = ?", taskID).Updates(updates).Error
+	})
+}
+
+// RecordDecision moves an application to status and appends decision to its review history,
+// provided decision.OfficerID still holds an unexpired claim on it, and releases the claim.
+// A reviewer's submission also records the reviewer and their response; an approval or
+// override records the approving officer, and an override replaces the response. A non-nil
+// callback is queued in the same transaction, so the decision and its callback to the service
+// are recorded together or not at all.
+func (s *ApplicationStore) RecordDecision(ctx context.Context, taskID, status string, decision ReviewDecision, callback *CallbackRecord, now time.Time) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var app ApplicationRecord
+		if err := tx.First(&app, "task_id = ?", taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrApplicationNotFound
+			}
+			return err
+		}
+
+		history, err := json.Marshal(append(app.ReviewHistory, decision))
+		if err != nil {
+			return fmt.Errorf("failed to marshal review history: %w", err)
+		}
+		updates := map[string]any{
+			"status":           status,
+			"review_history":   string(history),
+			"claimed_by":       nil,
+			"claim_expires_at": nil,
+			"updated_at":       now,
+		}
+
+		switch decision.Action {
+		case DecisionSubmit, DecisionOverride:
+			response, err := json.Marshal(decision.Response)
+			if err != nil {
+				return fmt.Errorf("failed to marshal reviewer response: %w", err)
+			}
+			updates["reviewer_response"] = response
+		}
+		switch decision.Action {
+		case DecisionSubmit:
+			updates["reviewed_by"] = decision.OfficerID
+			updates["reviewed_at"] = now
+			updates["approved_by"] = nil
+			updates["approved_at"] = nil
+		case DecisionApprove, DecisionOverride:
+			updates["approved_by"] = decision.OfficerID
+			updates["approved_at"] = now
+		}
+
+		// Conditioned on the claim so that a claim lost since the read above is not overridden.
+		result := tx.Model(&ApplicationRecord{}).
+			Where("task_id = ? AND claimed_by = ? AND claim_expires_at > ?", taskID, decision.OfficerID, now).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrClaimRequired
+		}
+		if callback != nil {
+			return tx.Create(callback).Error
+		}
+		return nil
+	})
+}
+
 // Delete removes an application by task ID
 func (s *ApplicationStore) Delete(taskID string) error {
 	return s.db.Delete(&ApplicationRecord{}, "task_id = ?", taskID).Error
@@ -2,10 +2,13 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/OpenNSW/nsw/oga/internal/database"
 	"github.com/OpenNSW/nsw/oga/internal/feedback"
@@ -36,8 +39,8 @@ func newTestStore(t *testing.T) *ApplicationStore {
 
 	// For persistent backends, clean the table before each test.
 	if cfg.DB.Driver != "sqlite" || cfg.DB.Path != ":memory:" {
-		if err := store.db.Exec("TRUNCATE TABLE applications").Error; err != nil {
-			t.Fatalf("failed to truncate applications table: %v", err)
+		if err := store.db.Exec("TRUNCATE TABLE applications, officers, callbacks, config_versions").Error; err != nil {
+			t.Fatalf("failed to truncate tables: %v", err)
 		}
 	}
 
@@ -395,7 +398,7 @@ func TestApplicationStore_AppendFeedback(t *testing.T) {
 	seedRecord(t, store, "task-fb-1", nil)
 
 	feedback1 := feedback.Entry{Content: map[string]any{"comment": "needs revision"}, Round: 1}
-	if err := store.AppendFeedback("task-fb-1", feedback1); err != nil {
+	if err := store.AppendFeedback("task-fb-1", feedback1, nil); err != nil {
 		t.Fatalf("AppendFeedback round 1 failed: %v", err)
 	}
 
@@ -409,7 +412,7 @@ func TestApplicationStore_AppendFeedback(t *testing.T) {
 
 	// Append a second round
 	feedback2 := feedback.Entry{Content: map[string]any{"comment": "still needs work"}, Round: 2}
-	if err := store.AppendFeedback("task-fb-1", feedback2); err != nil {
+	if err := store.AppendFeedback("task-fb-1", feedback2, nil); err != nil {
 		t.Fatalf("AppendFeedback round 2 failed: %v", err)
 	}
 
@@ -425,7 +428,7 @@ func TestApplicationStore_AppendFeedback(t *testing.T) {
 func TestApplicationStore_AppendFeedback_NonExistent(t *testing.T) {
 	store := newTestStore(t)
 
-	err := store.AppendFeedback("nonexistent", feedback.Entry{Content: map[string]any{"comment": "nope"}})
+	err := store.AppendFeedback("nonexistent", feedback.Entry{Content: map[string]any{"comment": "nope"}}, nil)
 	if err == nil {
 		t.Error("expected error for feedback on non-existent task")
 	}
@@ -438,7 +441,7 @@ func TestApplicationStore_UpdateDataAndResetStatus(t *testing.T) {
 	seedRecord(t, store, "task-resub-1", JSONB{"old": "data"})
 
 	// Simulate OGA requesting feedback
-	_ = store.AppendFeedback("task-resub-1", feedback.Entry{Content: map[string]any{"comment": "fix it"}})
+	_ = store.AppendFeedback("task-resub-1", feedback.Entry{Content: map[string]any{"comment": "fix it"}}, nil)
 
 	app, _ := store.GetByTaskID("task-resub-1")
 	if app.Status != "FEEDBACK_REQUESTED" {
@@ -459,3 +462,239 @@ func TestApplicationStore_UpdateDataAndResetStatus(t *testing.T) {
 		t.Errorf("expected updated data, got %v", app.Data)
 	}
 }
+
+// ---------- 7. Functional Testing: Claims & Queues ----------
+
+var pending = []string{"PENDING"}
+
+func TestApplicationStore_Claim(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedRecord(t, store, "task-claim-1", nil)
+	now := time.Now()
+
+	app, err := store.Claim(ctx, "task-claim-1", "alice", pending, false, now, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if app.ClaimedBy == nil || *app.ClaimedBy != "alice" {
+		t.Errorf("expected claim by alice, got %v", app.ClaimedBy)
+	}
+	if app.AssignedTo == nil || *app.AssignedTo != "alice" {
+		t.Errorf("expected claiming an unassigned application to assign it, got %v", app.AssignedTo)
+	}
+
+	// Another officer may neither claim it while the claim is live, even as a supervisor...
+	if _, err := store.Claim(ctx, "task-claim-1", "bob", pending, true, now.Add(30*time.Second), time.Minute); !errors.Is(err, ErrClaimConflict) {
+		t.Errorf("expected ErrClaimConflict, got %v", err)
+	}
+	// ...nor, without supervisor rights, once it lapses, since it is now assigned to alice.
+	if _, err := store.Claim(ctx, "task-claim-1", "bob", pending, false, now.Add(2*time.Minute), time.Minute); !errors.Is(err, ErrAssignedToAnotherOfficer) {
+		t.Errorf("expected ErrAssignedToAnotherOfficer, got %v", err)
+	}
+
+	// The holder can extend the claim, and a supervisor can take over a lapsed one.
+	if _, err := store.Claim(ctx, "task-claim-1", "alice", pending, false, now.Add(30*time.Second), time.Minute); err != nil {
+		t.Errorf("expected holder to extend the claim, got %v", err)
+	}
+	app, err = store.Claim(ctx, "task-claim-1", "bob", pending, true, now.Add(5*time.Minute), time.Minute)
+	if err != nil {
+		t.Fatalf("expected supervisor to claim a lapsed claim, got %v", err)
+	}
+	if *app.ClaimedBy != "bob" || *app.AssignedTo != "alice" {
+		t.Errorf("expected claim by bob with assignment kept for alice, got claimedBy=%v assignedTo=%v", *app.ClaimedBy, *app.AssignedTo)
+	}
+}
+
+func TestApplicationStore_Claim_NotClaimable(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedRecord(t, store, "task-claim-done", nil)
+	if err := store.UpdateStatus("task-claim-done", "DONE", map[string]any{}); err != nil {
+		t.Fatalf("UpdateStatus failed: %v", err)
+	}
+
+	if _, err := store.Claim(ctx, "task-claim-done", "alice", pending, false, time.Now(), time.Minute); !errors.Is(err, ErrNotClaimable) {
+		t.Errorf("expected ErrNotClaimable, got %v", err)
+	}
+	if _, err := store.Claim(ctx, "nonexistent", "alice", pending, false, time.Now(), time.Minute); !errors.Is(err, ErrApplicationNotFound) {
+		t.Errorf("expected ErrApplicationNotFound, got %v", err)
+	}
+}
+
+func TestApplicationStore_ReleaseClaim(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedRecord(t, store, "task-release-1", nil)
+	now := time.Now()
+
+	if err := store.ReleaseClaim(ctx, "task-release-1", "alice", false, now); err != nil {
+		t.Errorf("expected releasing an unclaimed application to be a no-op, got %v", err)
+	}
+	if _, err := store.Claim(ctx, "task-release-1", "alice", pending, false, now, time.Minute); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if err := store.ReleaseClaim(ctx, "task-release-1", "bob", false, now); !errors.Is(err, ErrClaimConflict) {
+		t.Errorf("expected ErrClaimConflict releasing another officer's claim, got %v", err)
+	}
+	if err := store.ReleaseClaim(ctx, "task-release-1", "bob", true, now); err != nil {
+		t.Fatalf("expected forced release to succeed, got %v", err)
+	}
+
+	app, _ := store.GetByTaskID("task-release-1")
+	if app.ClaimedBy != nil || app.ClaimExpiresAt != nil {
+		t.Errorf("expected claim to be cleared, got %v until %v", app.ClaimedBy, app.ClaimExpiresAt)
+	}
+}
+
+func TestApplicationStore_RecordDecision(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedRecord(t, store, "task-decide-1", nil)
+	now := time.Now()
+	submit := ReviewDecision{OfficerID: "alice", Action: DecisionSubmit, Response: map[string]any{"review_outcome": "approve"}}
+
+	if err := store.RecordDecision(ctx, "task-decide-1", StatusPendingApproval, submit, nil, now); !errors.Is(err, ErrClaimRequired) {
+		t.Errorf("expected ErrClaimRequired without a claim, got %v", err)
+	}
+	if _, err := store.Claim(ctx, "task-decide-1", "alice", pending, false, now, time.Minute); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if err := store.RecordDecision(ctx, "task-decide-1", StatusPendingApproval, submit, nil, now.Add(2*time.Minute)); !errors.Is(err, ErrClaimRequired) {
+		t.Errorf("expected ErrClaimRequired with a lapsed claim, got %v", err)
+	}
+	if err := store.RecordDecision(ctx, "task-decide-1", StatusPendingApproval, submit, nil, now.Add(30*time.Second)); err != nil {
+		t.Fatalf("RecordDecision(submit) failed: %v", err)
+	}
+
+	app, _ := store.GetByTaskID("task-decide-1")
+	if app.Status != StatusPendingApproval || app.ReviewedBy == nil || *app.ReviewedBy != "alice" {
+		t.Errorf("expected PENDING_APPROVAL reviewed by alice, got status=%q reviewedBy=%v", app.Status, app.ReviewedBy)
+	}
+	if app.ClaimedBy != nil {
+		t.Errorf("expected claim to be released after review, got %v", *app.ClaimedBy)
+	}
+
+	// A supervisor overrides the decision.
+	if _, err := store.Claim(ctx, "task-decide-1", "bob", []string{StatusPendingApproval}, true, now, time.Minute); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	override := ReviewDecision{OfficerID: "bob", Action: DecisionOverride, Response: map[string]any{"review_outcome": "reject"}}
+	if err := store.RecordDecision(ctx, "task-decide-1", "REJECTED", override, nil, now); err != nil {
+		t.Fatalf("RecordDecision(override) failed: %v", err)
+	}
+
+	app, _ = store.GetByTaskID("task-decide-1")
+	if app.Status != "REJECTED" || *app.ReviewedBy != "alice" || app.ApprovedBy == nil || *app.ApprovedBy != "bob" {
+		t.Errorf("expected REJECTED reviewed by alice and approved by bob, got status=%q approvedBy=%v", app.Status, app.ApprovedBy)
+	}
+	if app.ReviewerResponse["review_outcome"] != "reject" {
+		t.Errorf("expected the override to replace the response, got %v", app.ReviewerResponse)
+	}
+	if len(app.ReviewHistory) != 2 || app.ReviewHistory[0].Action != DecisionSubmit || app.ReviewHistory[1].Action != DecisionOverride {
+		t.Errorf("expected submit and override in the history, got %+v", app.ReviewHistory)
+	}
+}
+
+func TestApplicationStore_AppendFeedback_ReleasesClaim(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedRecord(t, store, "task-fb-claim", nil)
+	if _, err := store.Claim(ctx, "task-fb-claim", "alice", pending, false, time.Now(), time.Minute); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+
+	if err := store.AppendFeedback("task-fb-claim", feedback.Entry{Content: map[string]any{"comment": "fix it"}}, nil); err != nil {
+		t.Fatalf("AppendFeedback failed: %v", err)
+	}
+
+	app, _ := store.GetByTaskID("task-fb-claim")
+	if app.ClaimedBy != nil {
+		t.Errorf("expected claim to be released, got %v", *app.ClaimedBy)
+	}
+	if app.AssignedTo == nil || *app.AssignedTo != "alice" {
+		t.Errorf("expected assignment to be kept, got %v", app.AssignedTo)
+	}
+}
+
+func TestApplicationStore_Assign(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	seedRecord(t, store, "task-assign-1", nil)
+	now := time.Now()
+	if _, err := store.Claim(ctx, "task-assign-1", "alice", pending, false, now, time.Minute); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+
+	if err := store.Assign(ctx, "task-assign-1", "bob", now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	app, _ := store.GetByTaskID("task-assign-1")
+	if app.AssignedTo == nil || *app.AssignedTo != "bob" || app.ClaimedBy != nil {
+		t.Errorf("expected assignment to bob and alice's claim dropped, got assignedTo=%v claimedBy=%v", app.AssignedTo, app.ClaimedBy)
+	}
+
+	if err := store.Assign(ctx, "task-assign-1", "", now); err != nil {
+		t.Fatalf("Assign (unassign) failed: %v", err)
+	}
+	app, _ = store.GetByTaskID("task-assign-1")
+	if app.AssignedTo != nil || app.AssignedAt != nil {
+		t.Errorf("expected application to be unassigned, got %v", *app.AssignedTo)
+	}
+
+	if err := store.Assign(ctx, "nonexistent", "bob", now); !errors.Is(err, ErrApplicationNotFound) {
+		t.Errorf("expected ErrApplicationNotFound, got %v", err)
+	}
+}
+
+func TestApplicationStore_ListQueue(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	for _, id := range []string{"q-alice", "q-bob", "q-free", "q-claimed"} {
+		seedRecord(t, store, id, nil)
+	}
+	if err := store.Assign(ctx, "q-alice", "alice", now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if err := store.Assign(ctx, "q-bob", "bob", now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	// A supervisor claim on bob's application puts it in alice's queue too while it lasts.
+	if err := store.Assign(ctx, "q-claimed", "bob", now); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if _, err := store.Claim(ctx, "q-claimed", "alice", pending, true, now, time.Minute); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+
+	queueIDs := func(officerID string, at time.Time) []string {
+		t.Helper()
+		apps, total, err := store.ListQueue(ctx, officerID, "PENDING", at, 0, 10)
+		if err != nil {
+			t.Fatalf("ListQueue(%q) failed: %v", officerID, err)
+		}
+		if int(total) != len(apps) {
+			t.Errorf("ListQueue(%q): total %d does not match %d items", officerID, total, len(apps))
+		}
+		ids := make([]string, len(apps))
+		for i, app := range apps {
+			ids[i] = app.TaskID
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if got := queueIDs("alice", now); fmt.Sprint(got) != "[q-alice q-claimed]" {
+		t.Errorf("alice's queue: got %v", got)
+	}
+	if got := queueIDs("alice", now.Add(2*time.Minute)); fmt.Sprint(got) != "[q-alice]" {
+		t.Errorf("alice's queue after the claim lapsed: got %v", got)
+	}
+	if got := queueIDs("bob", now); fmt.Sprint(got) != "[q-bob q-claimed]" {
+		t.Errorf("bob's queue: got %v", got)
+	}
+	if got := queueIDs("", now); fmt.Sprint(got) != "[q-free]" {
+		t.Errorf("unassigned queue: got %v", got)
+	}
+}
@@ -4,18 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 // TaskConfig is the per-taskCode configuration: UI metadata, references to
 // forms in the FormStore, and outcome-to-status behavior.
 type TaskConfig struct {
-	TaskCode string        `json:"taskCode"`
-	Meta     TaskMeta      `json:"meta"`
-	Forms    TaskForms     `json:"forms"`
-	Behavior *TaskBehavior `json:"behavior,omitempty"`
+	TaskCode   string          `json:"taskCode"`
+	Meta       TaskMeta        `json:"meta"`
+	Forms      TaskForms       `json:"forms"`
+	Behavior   *TaskBehavior   `json:"behavior,omitempty"`
+	Assignment *TaskAssignment `json:"assignment,omitempty"`
 }
 
 // TaskMeta contains UI metadata for the task.
@@ -42,6 +45,26 @@ type TaskBehavior struct {
 	// is looked up in StatusMap. Defaults to "review_outcome" when empty.
 	OutcomeField string            `json:"outcomeField,omitempty"`
 	StatusMap    map[string]string `json:"statusMap,omitempty"`
+	// RequireApproval holds each review as PENDING_APPROVAL until a second officer, a
+	// supervisor, approves, returns or overrides it. The decision is only sent to NSW once
+	// approved.
+	RequireApproval bool `json:"requireApproval,omitempty"`
+}
+
+// Assignment strategies for TaskAssignment.Strategy.
+const (
+	// AssignRoundRobin assigns each new application to the active officer who was assigned
+	// one least recently.
+	AssignRoundRobin = "round_robin"
+	// AssignBySkill is AssignRoundRobin restricted to officers whose skills include the task
+	// code or the task category.
+	AssignBySkill = "skill"
+)
+
+// TaskAssignment controls how new applications for the task are assigned to officers.
+// Without it, applications land in the unassigned queue.
+type TaskAssignment struct {
+	Strategy string `json:"strategy"`
 }
 
 // TaskConfigStore holds loaded task configurations keyed by task code.
@@ -56,29 +79,30 @@ const TaskConfigsSubdir = "task-configs"
 // NewTaskConfigStore reads all .json files from <configDir>/task-configs into memory.
 // The task code is the filename without the .json extension.
 func NewTaskConfigStore(configDir string, defaultConfigID string) (*TaskConfigStore, error) {
-	dir := filepath.Join(configDir, TaskConfigsSubdir)
-	entries, err := os.ReadDir(dir)
+	files, err := readJSONFiles(filepath.Join(configDir, TaskConfigsSubdir), "task config")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read task configs directory %q: %w", dir, err)
+		return nil, err
 	}
+	return parseTaskConfigs(files, defaultConfigID)
+}
 
+// parseTaskConfigs builds a TaskConfigStore from task config file contents keyed by ID.
+func parseTaskConfigs(files map[string][]byte, defaultConfigID string) (*TaskConfigStore, error) {
 	configs := make(map[string]*TaskConfig)
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
-		if err != nil {
-			return nil, fmt.Errorf("failed to read task config file %q: %w", entry.Name(), err)
+	for _, id := range slices.Sorted(maps.Keys(files)) {
+		var config TaskConfig
+		if err := json.Unmarshal(files[id], &config); err != nil {
+			return nil, fmt.Errorf("task config file %q is invalid: %w", id+".json", err)
 		}
 
-		var config TaskConfig
-		if err := json.Unmarshal(data, &config); err != nil {
-			return nil, fmt.Errorf("task config file %q is invalid: %w", entry.Name(), err)
+		if config.Assignment != nil {
+			switch config.Assignment.Strategy {
+			case AssignRoundRobin, AssignBySkill:
+			default:
+				return nil, fmt.Errorf("task config file %q has unknown assignment strategy %q", id+".json", config.Assignment.Strategy)
+			}
 		}
 
-		id := strings.TrimSuffix(entry.Name(), ".json")
 		if config.TaskCode == "" {
 			config.TaskCode = id
 		}
@@ -90,6 +114,29 @@ func NewTaskConfigStore(configDir string, defaultConfigID string) (*TaskConfigSt
 	return &TaskConfigStore{configs: configs, defaultConfigID: defaultConfigID}, nil
 }
 
+// readJSONFiles reads the .json files directly under dir, keyed by filename without the
+// extension. kind names the files in errors.
+func readJSONFiles(dir, kind string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %ss directory %q: %w", kind, dir, err)
+	}
+
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s file %q: %w", kind, entry.Name(), err)
+		}
+		files[strings.TrimSuffix(entry.Name(), ".json")] = data
+	}
+	return files, nil
+}
+
 // GetConfig returns the configuration for the given task code, falling back to
 // the configured default if one is set, or returning an error otherwise.
 func (ts *TaskConfigStore) GetConfig(taskCode string) (*TaskConfig, error) {
@@ -103,3 +150,8 @@ func (ts *TaskConfigStore) GetConfig(taskCode string) (*TaskConfig, error) {
 	}
 	return nil, fmt.Errorf("task config %q not found", taskCode)
 }
+
+// IDs returns the IDs of the loaded task configs in order.
+func (ts *TaskConfigStore) IDs() []string {
+	return slices.Sorted(maps.Keys(ts.configs))
+}
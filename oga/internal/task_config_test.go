@@ -198,6 +198,31 @@ func TestTaskConfigStore_OutcomeFieldUnmarshals(t *testing.T) {
 	}
 }
 
+func TestTaskConfigStore_AssignmentStrategy(t *testing.T) {
+	root := newTaskConfigsDir(t)
+	writeTaskConfigFile(t, root, "labs.json", `{
+		"meta": {"title": "Lab Results"},
+		"assignment": {"strategy": "skill"}
+	}`)
+
+	store, err := NewTaskConfigStore(root, "")
+	if err != nil {
+		t.Fatalf("NewTaskConfigStore failed: %v", err)
+	}
+	cfg, err := store.GetConfig("labs")
+	if err != nil {
+		t.Fatalf("GetConfig(labs) failed: %v", err)
+	}
+	if cfg.Assignment == nil || cfg.Assignment.Strategy != AssignBySkill {
+		t.Errorf("expected skill assignment, got %+v", cfg.Assignment)
+	}
+
+	writeTaskConfigFile(t, root, "other.json", `{"assignment": {"strategy": "random"}}`)
+	if _, err := NewTaskConfigStore(root, ""); err == nil {
+		t.Error("expected error for unknown assignment strategy, got nil")
+	}
+}
+
 func TestDefaultOutcomeFieldConstant(t *testing.T) {
 	// Guard against accidental rename: the constant is part of the public
 	// contract documented in task-configs.md and the .env.example.
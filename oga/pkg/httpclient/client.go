@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -185,13 +186,19 @@ func (c *Client) resolveURL(path string) (string, error) {
 	return base.ResolveReference(rel).String(), nil
 }
 
-// Get performs a GET request relative to the BaseURL.
-func (c *Client) Get(path string) (*http.Response, error) {
+// NewRequest creates a request relative to the BaseURL, for callers that need to set their
+// own headers before passing it to Do.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	fullURL, err := c.resolveURL(path)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	return http.NewRequestWithContext(ctx, method, fullURL, body)
+}
+
+// Get performs a GET request relative to the BaseURL.
+func (c *Client) Get(path string) (*http.Response, error) {
+	req, err := c.NewRequest(context.Background(), http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -200,11 +207,7 @@ func (c *Client) Get(path string) (*http.Response, error) {
 
 // Post performs a POST request relative to the BaseURL.
 func (c *Client) Post(path string, contentType string, body []byte) (*http.Response, error) {
-	fullURL, err := c.resolveURL(path)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequest(http.MethodPost, fullURL, bytes.NewReader(body))
+	req, err := c.NewRequest(context.Background(), http.MethodPost, path, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
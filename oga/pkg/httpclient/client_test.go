@@ -343,3 +343,19 @@ func TestDoInjectsHTTPClientIntoContext(t *testing.T) {
 		t.Error("injected HTTP client does not match the client's internal httpClient")
 	}
 }
+
+func TestNewRequestResolvesBaseURL(t *testing.T) {
+	client := NewClientBuilder().
+		WithBaseURL("http://example.com/api/").
+		Build()
+	req, err := client.NewRequest(context.Background(), http.MethodPost, "/v1/tasks", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := req.URL.String(); got != "http://example.com/api/v1/tasks" {
+		t.Errorf("expected resolved URL http://example.com/api/v1/tasks, got %s", got)
+	}
+	if req.Method != http.MethodPost {
+		t.Errorf("expected POST method, got %v", req.Method)
+	}
+}
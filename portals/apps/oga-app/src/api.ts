@@ -111,6 +111,23 @@ export interface FormDefinition {
   uiSchema: UISchemaElement
 }
 
+export interface ReviewDecision {
+  officerId: string
+  action: 'submit' | 'approve' | 'return' | 'override'
+  response?: Record<string, unknown>
+  comment?: string
+  timestamp: string
+}
+
+// Delivery of the decisions sent back to NSW
+export interface CallbackDelivery {
+  status: 'PENDING' | 'DELIVERED' | 'FAILED'
+  attempts: number
+  lastError?: string
+  nextAttemptAt?: string
+  deliveredAt?: string
+}
+
 export interface OGAApplication {
   taskId: string
   workflowId: string
@@ -132,6 +149,22 @@ export interface OGAApplication {
   feedbackHistory?: FeedbackEntry[]
   reviewerNotes?: string
   reviewedAt?: string
+  reviewedBy?: string
+  approvedBy?: string
+  approvedAt?: string
+  reviewHistory?: ReviewDecision[]
+
+  // Officer assignment and the active claim, if any
+  assignedTo?: string
+  assignedAt?: string
+  claimedBy?: string
+  claimExpiresAt?: string
+
+  delivery?: CallbackDelivery
+
+  // Version of the task config and forms the application is rendered with
+  configVersion?: string
+
   createdAt: string
   updatedAt: string
 }
@@ -193,6 +226,20 @@ export async function fetchApplicationDetail(
   return apiClient.get<OGAApplication>(`/api/oga/applications/${taskId}`, {}, signal)
 }
 
+// Claim a task for review via OGA Service. The claim must be held to review or send feedback;
+// claiming a task already held by the caller extends the claim.
+export async function claimApplication(
+  apiClient: ApiClient,
+  taskId: string,
+  signal?: AbortSignal,
+): Promise<OGAApplication> {
+  return apiClient.post<Record<string, unknown>, OGAApplication>(
+    `/api/oga/applications/${taskId}/claim`,
+    {},
+    signal,
+  )
+}
+
 // Submit review for a task via OGA Service
 export async function submitReview(
   apiClient: ApiClient,
@@ -220,3 +267,17 @@ export async function submitFeedback(
     signal,
   )
 }
+
+// Approve or return a review awaiting a supervisor's countersignature via OGA Service
+export async function submitApprovalDecision(
+  apiClient: ApiClient,
+  taskId: string,
+  decision: { action: 'approve' | 'return' | 'override'; comment?: string; response?: Record<string, unknown> },
+  signal?: AbortSignal,
+): Promise<ReviewResponse> {
+  return apiClient.post<Record<string, unknown>, ReviewResponse>(
+    `/api/oga/applications/${taskId}/approval`,
+    decision,
+    signal,
+  )
+}
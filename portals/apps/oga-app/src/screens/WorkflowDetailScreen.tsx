@@ -8,7 +8,14 @@ import {
   InfoCircledIcon,
   ChatBubbleIcon,
 } from '@radix-ui/react-icons'
-import { fetchApplicationDetail, submitReview, submitFeedback, type OGAApplication } from '../api'
+import {
+  fetchApplicationDetail,
+  claimApplication,
+  submitReview,
+  submitFeedback,
+  submitApprovalDecision,
+  type OGAApplication,
+} from '../api'
 import { JsonForms } from '@jsonforms/react'
 import { radixRenderers } from '@opennsw/jsonforms-renderers'
 import type { JsonSchema, UISchemaElement } from '@jsonforms/core'
@@ -41,6 +48,7 @@ export function WorkflowDetailScreen() {
     setIsSendingFeedback(true)
     setError(null)
     try {
+      await claimApplication(apiClient, taskId)
       await submitFeedback(apiClient, taskId, { feedback: feedbackText.trim() })
       setSuccess(true)
       setTimeout(() => navigate('/workflows'), 2000)
@@ -51,6 +59,22 @@ export function WorkflowDetailScreen() {
     }
   }
 
+  const handleApprovalDecision = async (action: 'approve' | 'return') => {
+    if (!taskId) return
+    setIsSubmitting(true)
+    setError(null)
+    try {
+      await claimApplication(apiClient, taskId)
+      await submitApprovalDecision(apiClient, taskId, { action })
+      setSuccess(true)
+      setTimeout(() => navigate('/workflows'), 2000)
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to record approval decision')
+    } finally {
+      setIsSubmitting(false)
+    }
+  }
+
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
     if (!taskId || !application) {
@@ -64,6 +88,7 @@ export function WorkflowDetailScreen() {
     setIsSubmitting(true)
     setError(null)
     try {
+      await claimApplication(apiClient, taskId)
       await submitReview(apiClient, taskId, ogaFormData)
       setSuccess(true)
       setTimeout(() => navigate('/workflows'), 2000)
@@ -165,7 +190,9 @@ export function WorkflowDetailScreen() {
         ? 'red'
         : application.status === 'FEEDBACK_REQUESTED'
           ? 'amber'
-          : 'blue'
+          : application.status === 'PENDING_APPROVAL'
+            ? 'violet'
+            : 'blue'
 
   return (
     <div className="animate-fade-in max-w-5xl mx-auto">
@@ -286,6 +313,30 @@ export function WorkflowDetailScreen() {
                   </Text>
                 </Box>
               )}
+              {application.delivery && (
+                <Box>
+                  <Text size="1" color="gray" as="div" mb="1">
+                    Sent to NSW
+                  </Text>
+                  <Badge
+                    size="2"
+                    color={
+                      application.delivery.status === 'DELIVERED'
+                        ? 'green'
+                        : application.delivery.status === 'FAILED'
+                          ? 'red'
+                          : 'amber'
+                    }
+                  >
+                    {application.delivery.status}
+                  </Badge>
+                  {application.delivery.status !== 'DELIVERED' && application.delivery.lastError && (
+                    <Text size="1" color="gray" as="div" mt="1">
+                      {application.delivery.attempts} attempt(s), last error: {application.delivery.lastError}
+                    </Text>
+                  )}
+                </Box>
+              )}
             </div>
           </Card>
 
@@ -440,17 +491,44 @@ export function WorkflowDetailScreen() {
                       </Flex>
                     </form>
                   ) : ogaFormConfig ? (
-                    <JsonForms
-                      schema={ogaFormConfig.schema}
-                      uischema={ogaFormConfig.uiSchema}
-                      data={ogaFormData}
-                      renderers={radixRenderers}
-                      readonly
-                      onChange={({ data, errors }: { data: Record<string, unknown>; errors?: unknown[] }) => {
-                        setOgaFormData(data)
-                        setFormErrors(errors || [])
-                      }}
-                    />
+                    <>
+                      <JsonForms
+                        schema={ogaFormConfig.schema}
+                        uischema={ogaFormConfig.uiSchema}
+                        data={ogaFormData}
+                        renderers={radixRenderers}
+                        readonly
+                        onChange={({ data, errors }: { data: Record<string, unknown>; errors?: unknown[] }) => {
+                          setOgaFormData(data)
+                          setFormErrors(errors || [])
+                        }}
+                      />
+                      {application.status === 'PENDING_APPROVAL' && (
+                        <Flex justify="end" gap="3" mt="6">
+                          <Button
+                            variant="soft"
+                            color="amber"
+                            type="button"
+                            disabled={isSubmitting}
+                            onClick={() => {
+                              void handleApprovalDecision('return')
+                            }}
+                          >
+                            Return to Reviewer
+                          </Button>
+                          <Button
+                            type="button"
+                            disabled={isSubmitting}
+                            onClick={() => {
+                              void handleApprovalDecision('approve')
+                            }}
+                          >
+                            {isSubmitting ? <Spinner size="1" /> : null}
+                            Approve
+                          </Button>
+                        </Flex>
+                      )}
+                    </>
                   ) : null}
                 </Tabs.Content>
 
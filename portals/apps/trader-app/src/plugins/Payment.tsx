@@ -4,6 +4,7 @@ import { Box, Button, Dialog, Flex, IconButton, Text } from '@radix-ui/themes'
 import { Cross2Icon } from '@radix-ui/react-icons'
 import { useApi } from '../services/ApiContext'
 import { getTaskInfo, sendTaskAction, type TaskCommandResponse } from '../services/task'
+import { completeMockCheckout, type MockPaymentStatus } from '../services/payment'
 
 type BreakDown = {
   description: string
@@ -19,8 +20,18 @@ export type PaymentConfigs = {
   totalAmount: number
   currency: string
   breakdown: BreakDown[]
+  referenceNumber?: string
+  amountPaid?: number | string
+  amountRefunded?: number | string
+  netAmount?: number | string
 }
 
+// Plugin states in which money has been received and the trader has nothing left to start.
+const PAID_STATES = ['PARTIALLY_PAID', 'COMPLETED', 'REFUND_REQUESTED', 'REFUNDED']
+
+const formatAmount = (value: number | string) =>
+  Number(value).toLocaleString(undefined, { minimumFractionDigits: 2 })
+
 export default function Payment(props: {
   configs: PaymentConfigs
   pluginState: string
@@ -36,10 +47,12 @@ export default function Payment(props: {
   const [isProcessingResult, setIsProcessingResult] = useState(false)
   const [isPopupOpen, setIsPopupOpen] = useState(false)
   const [submitError, setSubmitError] = useState<string | null>(null)
+  // Reference issued when payment was initiated in this view; the render info may predate it.
+  const [initiatedReference, setInitiatedReference] = useState<string | null>(null)
   const api = useApi()
 
   const workflowId = preConsignmentId || consignmentId
-  const isCompleted = props.pluginState === 'COMPLETED'
+  const isPaid = PAID_STATES.includes(props.pluginState)
   const gatewayUrl = props.configs?.gatewayUrl ?? ''
 
   const refreshGatewaySession = async () => {
@@ -90,6 +103,10 @@ export default function Payment(props: {
       try {
         const response = await sendTaskAction(taskId, workflowId, 'INITIATE_PAYMENT')
         if (response.success) {
+          const referenceNumber = response.data?.referenceNumber
+          if (typeof referenceNumber === 'string') {
+            setInitiatedReference(referenceNumber)
+          }
           return true
         }
 
@@ -127,9 +144,12 @@ export default function Payment(props: {
     }
   }
 
-  const handleMockGatewayResult = async (action: 'PAYMENT_SUCCESS' | 'PAYMENT_FAILED') => {
-    if (!workflowId || !taskId) {
-      setSubmitError('Workflow ID or Task ID is missing.')
+  // The mock gateway reports its result to the backend, which settles it through the payment
+  // webhook like a real gateway, rather than executing PAYMENT_SUCCESS or PAYMENT_FAILED directly.
+  const handleMockGatewayResult = async (status: MockPaymentStatus) => {
+    const referenceNumber = initiatedReference ?? props.configs.referenceNumber
+    if (!referenceNumber) {
+      setSubmitError('Payment reference is missing.')
       return
     }
 
@@ -137,11 +157,7 @@ export default function Payment(props: {
     setSubmitError(null)
 
     try {
-      const response = await sendTaskAction(taskId, workflowId, action)
-      if (!response.success) {
-        setSubmitError(response.error?.message ?? 'Failed to process payment result.')
-        return
-      }
+      await completeMockCheckout(referenceNumber, status, api)
 
       setIsPopupOpen(false)
 
@@ -150,7 +166,7 @@ export default function Payment(props: {
       }
     } catch (err) {
       console.error('Error processing payment result:', err)
-      setSubmitError('Failed to process payment result. Please try again.')
+      setSubmitError(err instanceof Error ? err.message : 'Failed to process payment result. Please try again.')
     } finally {
       setIsProcessingResult(false)
     }
@@ -178,6 +194,8 @@ export default function Payment(props: {
                       <>
                         {item.quantity} × {item.unitPrice.toLocaleString(undefined, { minimumFractionDigits: 2 })}
                       </>
+                    ) : item.type === 'SCHEDULE' ? (
+                      <span className="italic">Per fee schedule</span>
                     ) : (
                       <span className="italic">Calculated based on running total</span>
                     )}
@@ -205,7 +223,43 @@ export default function Payment(props: {
           </table>
         </div>
       </Box>
-      {!isCompleted && (
+      {props.configs.amountPaid !== undefined && (
+        <Box className="border border-gray-200 rounded-lg p-4 space-y-1 text-sm">
+          <Flex justify="between">
+            <Text color="gray">Paid</Text>
+            <Text className="font-mono">
+              {formatAmount(props.configs.amountPaid)} {props.configs.currency}
+            </Text>
+          </Flex>
+          {Number(props.configs.amountRefunded ?? 0) > 0 && (
+            <Flex justify="between">
+              <Text color="gray">Refunded</Text>
+              <Text className="font-mono">
+                {formatAmount(props.configs.amountRefunded ?? 0)} {props.configs.currency}
+              </Text>
+            </Flex>
+          )}
+          {props.configs.netAmount !== undefined && (
+            <Flex justify="between">
+              <Text weight="bold">Net</Text>
+              <Text weight="bold" className="font-mono">
+                {formatAmount(props.configs.netAmount)} {props.configs.currency}
+              </Text>
+            </Flex>
+          )}
+          {props.pluginState === 'PARTIALLY_PAID' && props.configs.referenceNumber && (
+            <Text as="p" size="2" color="gray">
+              Pay the balance using reference {props.configs.referenceNumber}.
+            </Text>
+          )}
+          {props.pluginState === 'REFUND_REQUESTED' && (
+            <Text as="p" size="2" color="gray">
+              A refund is being processed.
+            </Text>
+          )}
+        </Box>
+      )}
+      {!isPaid && (
         <Flex justify="end">
           <Button
             onClick={() => {
@@ -249,7 +303,7 @@ export default function Payment(props: {
               size="2"
               disabled={isProcessingResult}
               onClick={() => {
-                void handleMockGatewayResult('PAYMENT_FAILED')
+                void handleMockGatewayResult('FAILED')
               }}
             >
               {isProcessingResult ? 'Processing...' : 'Mock Fail'}
@@ -259,7 +313,7 @@ export default function Payment(props: {
               size="2"
               disabled={isProcessingResult}
               onClick={() => {
-                void handleMockGatewayResult('PAYMENT_SUCCESS')
+                void handleMockGatewayResult('SUCCESS')
               }}
             >
               {isProcessingResult ? 'Processing...' : 'Mock Success'}
@@ -0,0 +1,28 @@
+import { defaultApiClient, type ApiClient } from './api'
+
+export type MockPaymentStatus = 'SUCCESS' | 'FAILED'
+
+interface MockCheckoutResult {
+  status: MockPaymentStatus
+}
+
+interface MockCheckoutResponse {
+  status: string
+}
+
+/**
+ * Reports the result picked on the mock gateway page. The backend signs it as a mock gateway
+ * webhook and processes it like a real one, which then advances the PAYMENT task. The endpoint
+ * only exists when the backend runs with PAYMENT_MOCK_ENABLED, in development and sandbox
+ * environments.
+ */
+export async function completeMockCheckout(
+  referenceNumber: string,
+  status: MockPaymentStatus,
+  apiClient: ApiClient = defaultApiClient,
+): Promise<void> {
+  await apiClient.post<MockCheckoutResult, MockCheckoutResponse>(
+    `/payments/mock/checkout/${encodeURIComponent(referenceNumber)}/complete`,
+    { status },
+  )
+}
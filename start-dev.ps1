@@ -125,6 +125,7 @@ $OGA_NSW_CDA_CLIENT_SECRET = if ($env:OGA_NSW_CDA_CLIENT_SECRET) { $env:OGA_NSW_
 $OGA_NSW_TOKEN_URL = if ($env:OGA_NSW_TOKEN_URL) { $env:OGA_NSW_TOKEN_URL } else { "https://localhost:$IDP_PORT/oauth2/token" }
 $OGA_NSW_SCOPES = if ($env:OGA_NSW_SCOPES) { $env:OGA_NSW_SCOPES } else { "" }
 $OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY = if ($env:OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY) { $env:OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY } else { "true" }
+$OGA_AUTH_NSW_CLIENT_IDS = if ($env:OGA_AUTH_NSW_CLIENT_IDS) { $env:OGA_AUTH_NSW_CLIENT_IDS } else { "NSW_TO_OGA" }
 
 # OGA Registry
 $OGA_INSTANCES = @(
@@ -297,6 +298,11 @@ foreach ($Instance in $OGA_INSTANCES) {
         OGA_NSW_TOKEN_URL = $OGA_NSW_TOKEN_URL
         OGA_NSW_SCOPES = $OGA_NSW_SCOPES
         OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY = $OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY
+        OGA_AUTH_JWKS_URL = $AUTH_JWKS_URL
+        OGA_AUTH_ISSUER = $AUTH_ISSUER
+        OGA_AUTH_PORTAL_CLIENT_IDS = $Instance.IdpClientId
+        OGA_AUTH_NSW_CLIENT_IDS = $OGA_AUTH_NSW_CLIENT_IDS
+        OGA_AUTH_JWKS_INSECURE_SKIP_VERIFY = $AUTH_JWKS_INSECURE_SKIP_VERIFY
     }
     Start-ServiceJob -Name "oga-$($Instance.Name)" -Dir (Join-Path $ROOT_DIR "oga") -EnvVars $OgaEnv -ScriptBlock {
         param($Name, $Dir, $EnvVars)
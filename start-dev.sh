@@ -129,6 +129,7 @@ OGA_NSW_CDA_CLIENT_SECRET="${OGA_NSW_CDA_CLIENT_SECRET:-1234}"
 OGA_NSW_TOKEN_URL="${OGA_NSW_TOKEN_URL:-https://localhost:${IDP_PORT}/oauth2/token}"
 OGA_NSW_SCOPES="${OGA_NSW_SCOPES:-}"
 OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY="${OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY:-true}"
+OGA_AUTH_NSW_CLIENT_IDS="${OGA_AUTH_NSW_CLIENT_IDS:-NSW_TO_OGA}"
 
 # OGA instance registry
 # Each row: name | backend_port | db_path | nsw_client_id | nsw_client_secret | app_port | branding_name | idp_client_id | app_name
@@ -362,6 +363,11 @@ for entry in "${OGA_INSTANCES[@]}"; do
     OGA_NSW_TOKEN_URL="$OGA_NSW_TOKEN_URL" \
     OGA_NSW_SCOPES="$OGA_NSW_SCOPES" \
     OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY="$OGA_NSW_TOKEN_INSECURE_SKIP_VERIFY" \
+    OGA_AUTH_JWKS_URL="$AUTH_JWKS_URL" \
+    OGA_AUTH_ISSUER="$AUTH_ISSUER" \
+    OGA_AUTH_PORTAL_CLIENT_IDS="$idp_client_id" \
+    OGA_AUTH_NSW_CLIENT_IDS="$OGA_AUTH_NSW_CLIENT_IDS" \
+    OGA_AUTH_JWKS_INSECURE_SKIP_VERIFY="$AUTH_JWKS_INSECURE_SKIP_VERIFY" \
     go run ./cmd/server
 
   ensure_branding_file "${branding_name}" "${app_name}"